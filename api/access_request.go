@@ -0,0 +1,607 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/satori/go.uuid"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// TYPE DEFINITIONS
+
+// Access request domain. It asks for a temporary membership in a group or,
+// when PolicyName is set, a temporary attachment of a policy to a group.
+type AccessRequest struct {
+	ID            string     `json:"id, omitempty"`
+	Type          string     `json:"type, omitempty"`
+	Requester     string     `json:"requester, omitempty"`
+	Org           string     `json:"org, omitempty"`
+	GroupName     string     `json:"groupName, omitempty"`
+	PolicyName    string     `json:"policyName, omitempty"`
+	Justification string     `json:"justification, omitempty"`
+	Duration      string     `json:"duration, omitempty"`
+	Status        string     `json:"status, omitempty"`
+	Reviewer      string     `json:"reviewer, omitempty"`
+	ReviewComment string     `json:"reviewComment, omitempty"`
+	CreateAt      time.Time  `json:"createAt, omitempty"`
+	ReviewAt      *time.Time `json:"reviewAt, omitempty"`
+	ExpiresAt     *time.Time `json:"expiresAt, omitempty"`
+	// Urn of the group affected by this request
+	GroupUrn string `json:"groupUrn, omitempty"`
+}
+
+func (a AccessRequest) String() string {
+	return fmt.Sprintf("[id: %v, type: %v, requester: %v, org: %v, groupName: %v, policyName: %v, duration: %v, status: %v, reviewer: %v]",
+		a.ID, a.Type, a.Requester, a.Org, a.GroupName, a.PolicyName, a.Duration, a.Status, a.Reviewer)
+}
+
+// Access requests are authorized against the group they affect
+func (a AccessRequest) GetUrn() string {
+	return a.GroupUrn
+}
+
+// Access requests don't have tags, so conditions that need resource tags never match them
+func (a AccessRequest) GetTags() map[string]string {
+	return nil
+}
+
+// ACCESS REQUEST API IMPLEMENTATION
+
+func (api AuthAPI) AddAccessRequest(requestInfo RequestInfo, org string, groupName string, policyName string,
+	justification string, duration time.Duration) (*AccessRequest, error) {
+	// Validate fields
+	if !IsValidOrg(org) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: org %v", org),
+		}
+	}
+	if !IsValidName(groupName) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: group name %v", groupName),
+		}
+	}
+	if len(policyName) > 0 && !IsValidName(policyName) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: policy name %v", policyName),
+		}
+	}
+	if !IsValidJustification(justification) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: justification %v", justification),
+		}
+	}
+	if duration <= 0 || duration > MAX_ACCESS_REQUEST_DURATION {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: duration %v. It must be positive and not greater than %v", duration, MAX_ACCESS_REQUEST_DURATION),
+		}
+	}
+
+	// Only existing users can request access
+	requester, err := api.getRequester(requestInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check that requested group and policy exist
+	group, err := api.getGroupForAccessRequest(org, groupName)
+	if err != nil {
+		return nil, err
+	}
+
+	requestType := ACCESS_REQUEST_TYPE_MEMBERSHIP
+	if len(policyName) > 0 {
+		requestType = ACCESS_REQUEST_TYPE_POLICY_ATTACHMENT
+		if _, err := api.getPolicyForAccessRequest(org, policyName); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := checkGroupWithoutMembershipRule(group); err != nil {
+			return nil, err
+		}
+		isMember, err := api.GroupRepo.IsMemberOfGroup(requester.ID, group.ID)
+		if err != nil {
+			//Transform to DB error
+			dbError := err.(*database.Error)
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+		if isMember {
+			return nil, &Error{
+				Code:    USER_IS_ALREADY_A_MEMBER_OF_GROUP,
+				Message: fmt.Sprintf("User: %v is already a member of Group: %v", requester.ExternalID, group.Name),
+			}
+		}
+	}
+
+	accessRequest := AccessRequest{
+		ID:            uuid.NewV4().String(),
+		Type:          requestType,
+		Requester:     requester.ExternalID,
+		Org:           org,
+		GroupName:     groupName,
+		PolicyName:    policyName,
+		Justification: justification,
+		Duration:      duration.String(),
+		Status:        ACCESS_REQUEST_STATUS_PENDING,
+		CreateAt:      time.Now().UTC(),
+		GroupUrn:      group.Urn,
+	}
+
+	var createdRequest *AccessRequest
+	err = api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		var err error
+		createdRequest, err = repo.AddAccessRequest(accessRequest)
+		if err != nil {
+			return err
+		}
+		return api.auditOperation(repo, requestInfo, AUDIT_ACTION_CREATE_ACCESS_REQUEST, createdRequest.GroupUrn, nil, createdRequest)
+	})
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Access request created %+v", createdRequest))
+	return createdRequest, nil
+}
+
+func (api AuthAPI) GetAccessRequestByID(requestInfo RequestInfo, org string, id string) (*AccessRequest, error) {
+	// Call repo to retrieve the access request
+	accessRequest, err := api.getAccessRequest(org, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Requesters can always see their own requests
+	if !requestInfo.Admin && accessRequest.Requester == requestInfo.Identifier {
+		return accessRequest, nil
+	}
+
+	// Check restrictions
+	filtered, err := api.getAuthorizedAccessRequests(requestInfo, accessRequest.GroupUrn, []AccessRequest{*accessRequest})
+	if err != nil {
+		return nil, err
+	}
+	if len(filtered) < 1 {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, accessRequest.GroupUrn),
+		}
+	}
+
+	return &filtered[0], nil
+}
+
+func (api AuthAPI) ListAccessRequests(requestInfo RequestInfo, org string, status string) ([]AccessRequest, error) {
+	// Validate fields
+	if !IsValidOrg(org) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: org %v", org),
+		}
+	}
+	if len(status) > 0 && !IsValidAccessRequestStatus(status) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: status %v", status),
+		}
+	}
+
+	// Call repo to retrieve the access requests
+	accessRequests, err := api.AccessRequestRepo.GetAccessRequestsFiltered(org, status)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	if requestInfo.Admin {
+		return accessRequests, nil
+	}
+
+	// Split own requests from requests to review
+	ownRequests := []AccessRequest{}
+	otherRequests := []AccessRequest{}
+	for _, a := range accessRequests {
+		if a.Requester == requestInfo.Identifier {
+			ownRequests = append(ownRequests, a)
+		} else {
+			otherRequests = append(otherRequests, a)
+		}
+	}
+
+	if len(otherRequests) < 1 {
+		return ownRequests, nil
+	}
+
+	// Check restrictions to list requests from other users
+	filtered, err := api.getAuthorizedAccessRequests(requestInfo, GetUrnPrefix(org, RESOURCE_GROUP, "/"), otherRequests)
+	if err != nil {
+		if apiError := err.(*Error); apiError.Code == UNAUTHORIZED_RESOURCES_ERROR {
+			return ownRequests, nil
+		}
+		return nil, err
+	}
+
+	return append(ownRequests, filtered...), nil
+}
+
+func (api AuthAPI) ApproveAccessRequest(requestInfo RequestInfo, org string, id string, comment string) (*AccessRequest, error) {
+	accessRequest, err := api.getAccessRequestToReview(requestInfo, org, id, comment)
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := time.ParseDuration(accessRequest.Duration)
+	if err != nil {
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: fmt.Sprintf("Invalid stored duration %v in access request %v", accessRequest.Duration, accessRequest.ID),
+		}
+	}
+	now := time.Now().UTC()
+	expiresAt := now.Add(duration)
+
+	group, err := api.getGroupToGrantAccessRequest(requestInfo, *accessRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	pendingRequest := *accessRequest
+	accessRequest.Status = ACCESS_REQUEST_STATUS_APPROVED
+	accessRequest.Reviewer = requestInfo.Identifier
+	accessRequest.ReviewComment = comment
+	accessRequest.ReviewAt = &now
+	accessRequest.ExpiresAt = &expiresAt
+
+	// Review and grant are stored together. The review is stored first so concurrent reviewers
+	// of the same request wait for it, and only the first one grants access
+	var updatedRequest *AccessRequest
+	err = api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		var err error
+		updatedRequest, err = updateAccessRequestReview(repo, *accessRequest)
+		if err != nil {
+			return err
+		}
+		if err := api.grantAccessRequest(repo, *accessRequest, group, &expiresAt); err != nil {
+			return err
+		}
+		return api.auditOperation(repo, requestInfo, ACCESS_REQUEST_ACTION_APPROVE, updatedRequest.GroupUrn, pendingRequest, updatedRequest)
+	})
+
+	// Error handling
+	if err != nil {
+		return nil, toUnknownAPIError(err)
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Access request approved %+v", updatedRequest))
+	return updatedRequest, nil
+}
+
+func (api AuthAPI) RejectAccessRequest(requestInfo RequestInfo, org string, id string, comment string) (*AccessRequest, error) {
+	accessRequest, err := api.getAccessRequestToReview(requestInfo, org, id, comment)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	pendingRequest := *accessRequest
+	accessRequest.Status = ACCESS_REQUEST_STATUS_REJECTED
+	accessRequest.Reviewer = requestInfo.Identifier
+	accessRequest.ReviewComment = comment
+	accessRequest.ReviewAt = &now
+
+	var updatedRequest *AccessRequest
+	err = api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		var err error
+		updatedRequest, err = updateAccessRequestReview(repo, *accessRequest)
+		if err != nil {
+			return err
+		}
+		return api.auditOperation(repo, requestInfo, AUDIT_ACTION_REJECT_ACCESS_REQUEST, updatedRequest.GroupUrn, pendingRequest, updatedRequest)
+	})
+
+	// Error handling
+	if err != nil {
+		return nil, toUnknownAPIError(err)
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Access request rejected %+v", updatedRequest))
+	return updatedRequest, nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Retrieve a pending access request checking that the authenticated user is allowed to review it
+func (api AuthAPI) getAccessRequestToReview(requestInfo RequestInfo, org string, id string, comment string) (*AccessRequest, error) {
+	if !IsValidJustification(comment) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: comment %v", comment),
+		}
+	}
+
+	accessRequest, err := api.getAccessRequest(org, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Nobody can approve or reject their own requests
+	if !requestInfo.Admin && accessRequest.Requester == requestInfo.Identifier {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to review its own access request %v",
+				requestInfo.Identifier, accessRequest.ID),
+		}
+	}
+
+	// Check restrictions
+	filtered, err := api.getAuthorizedAccessRequests(requestInfo, accessRequest.GroupUrn, []AccessRequest{*accessRequest})
+	if err != nil {
+		return nil, err
+	}
+	if len(filtered) < 1 {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, accessRequest.GroupUrn),
+		}
+	}
+
+	if accessRequest.Status != ACCESS_REQUEST_STATUS_PENDING {
+		return nil, &Error{
+			Code:    ACCESS_REQUEST_ALREADY_REVIEWED,
+			Message: fmt.Sprintf("Access request %v has already been %v", accessRequest.ID, accessRequest.Status),
+		}
+	}
+
+	return accessRequest, nil
+}
+
+// Retrieve access request without authorization checks. Requests of other organizations aren't found
+func (api AuthAPI) getAccessRequest(org string, id string) (*AccessRequest, error) {
+	accessRequest, err := api.AccessRequestRepo.GetAccessRequestByID(id)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.ACCESS_REQUEST_NOT_FOUND:
+			return nil, &Error{
+				Code:    ACCESS_REQUEST_NOT_FOUND,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	if accessRequest.Org != org {
+		return nil, &Error{
+			Code:    ACCESS_REQUEST_NOT_FOUND,
+			Message: fmt.Sprintf("Access request with id %v not found in organization %v", id, org),
+		}
+	}
+
+	return accessRequest, nil
+}
+
+// Retrieve the group of an access request to approve, if the reviewer is allowed to grant it like attaching
+// the policy to the group or adding the requester to it
+func (api AuthAPI) getGroupToGrantAccessRequest(requestInfo RequestInfo, accessRequest AccessRequest) (*Group, error) {
+	if accessRequest.Type != ACCESS_REQUEST_TYPE_POLICY_ATTACHMENT {
+		group, isOwner, err := api.getGroupToManageMembers(requestInfo, accessRequest.Org, accessRequest.GroupName, GROUP_ACTION_ADD_MEMBER)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := api.getMemberToManage(requestInfo, accessRequest.Requester, isOwner); err != nil {
+			return nil, err
+		}
+		return group, nil
+	}
+
+	group, err := api.GetGroupByName(requestInfo, accessRequest.Org, accessRequest.GroupName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check restrictions
+	groupsFiltered, err := api.GetAuthorizedGroups(requestInfo, group.Urn, GROUP_ACTION_ATTACH_GROUP_POLICY, []Group{*group})
+	if err != nil {
+		return nil, err
+	}
+	if len(groupsFiltered) < 1 {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, group.Urn),
+		}
+	}
+
+	// Check if policy exists and the reviewer is allowed to get it
+	if _, err := api.GetPolicyByName(requestInfo, accessRequest.Org, accessRequest.PolicyName); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// Store the review of a pending access request. Throw error if it was reviewed in the meantime
+func updateAccessRequestReview(repo AccessRequestRepo, accessRequest AccessRequest) (*AccessRequest, error) {
+	updatedRequest, err := repo.UpdateAccessRequest(accessRequest)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.ACCESS_REQUEST_ALREADY_REVIEWED:
+			return nil, &Error{
+				Code:    ACCESS_REQUEST_ALREADY_REVIEWED,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+	return updatedRequest, nil
+}
+
+// Grant the membership or policy attachment of an approved access request until it expires
+func (api AuthAPI) grantAccessRequest(repo Repo, accessRequest AccessRequest, group *Group, expiresAt *time.Time) error {
+	switch accessRequest.Type {
+	case ACCESS_REQUEST_TYPE_POLICY_ATTACHMENT:
+		policy, err := api.getPolicyForAccessRequest(accessRequest.Org, accessRequest.PolicyName)
+		if err != nil {
+			return err
+		}
+		isAttached, err := repo.IsAttachedToGroup(group.ID, policy.ID)
+		if err != nil {
+			return err
+		}
+		if isAttached {
+			return &Error{
+				Code:    POLICY_IS_ALREADY_ATTACHED_TO_GROUP,
+				Message: fmt.Sprintf("Policy: %v is already attached to Group: %v", policy.Name, group.Name),
+			}
+		}
+		return repo.AttachPolicy(group.ID, policy.ID, nil, expiresAt)
+	default:
+		requester, err := repo.GetUserByExternalID(accessRequest.Requester)
+		if err != nil {
+			dbError := err.(*database.Error)
+			switch dbError.Code {
+			case database.USER_NOT_FOUND:
+				return &Error{
+					Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+					Message: dbError.Message,
+				}
+			default:
+				return err
+			}
+		}
+		if err := checkGroupWithoutMembershipRule(group); err != nil {
+			return err
+		}
+		isMember, err := repo.IsMemberOfGroup(requester.ID, group.ID)
+		if err != nil {
+			return err
+		}
+		if isMember {
+			return &Error{
+				Code:    USER_IS_ALREADY_A_MEMBER_OF_GROUP,
+				Message: fmt.Sprintf("User: %v is already a member of Group: %v", requester.ExternalID, group.Name),
+			}
+		}
+		return repo.AddMember(requester.ID, group.ID, expiresAt)
+	}
+}
+
+// Return access requests whose group allows the approve action to the authenticated user
+func (api AuthAPI) getAuthorizedAccessRequests(requestInfo RequestInfo, resourceUrn string, accessRequests []AccessRequest) ([]AccessRequest, error) {
+	resourcesToAuthorize := []Resource{}
+	for _, a := range accessRequests {
+		resourcesToAuthorize = append(resourcesToAuthorize, a)
+	}
+	resources, err := api.getAuthorizedResources(requestInfo, resourceUrn, ACCESS_REQUEST_ACTION_APPROVE, resourcesToAuthorize)
+	if err != nil {
+		return nil, err
+	}
+	filtered := []AccessRequest{}
+	for _, res := range resources {
+		filtered = append(filtered, res.(AccessRequest))
+	}
+	return filtered, nil
+}
+
+// Retrieve the user that is making the request
+func (api AuthAPI) getRequester(requestInfo RequestInfo) (*User, error) {
+	user, err := api.UserRepo.GetUserByExternalID(requestInfo.Identifier)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.USER_NOT_FOUND:
+			return nil, &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: fmt.Sprintf("Authenticated user with externalId %v not found. Unable to request access.", requestInfo.Identifier),
+			}
+		default:
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	// Suspended users can't request access
+	if user.Status == USER_STATUS_SUSPENDED {
+		return nil, &Error{
+			Code:    UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("Authenticated user with externalId %v is suspended. Unable to request access.", requestInfo.Identifier),
+		}
+	}
+	return user, nil
+}
+
+// Retrieve group without authorization checks, requesters don't need to be able to read it
+func (api AuthAPI) getGroupForAccessRequest(org string, name string) (*Group, error) {
+	group, err := api.GroupRepo.GetGroupByName(org, name)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.GROUP_NOT_FOUND:
+			return nil, &Error{
+				Code:    GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: dbError.Message,
+			}
+		default:
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+	return group, nil
+}
+
+// Retrieve policy without authorization checks, requesters don't need to be able to read it
+func (api AuthAPI) getPolicyForAccessRequest(org string, name string) (*Policy, error) {
+	policy, err := api.PolicyRepo.GetPolicyByName(org, name)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.POLICY_NOT_FOUND:
+			return nil, &Error{
+				Code:    POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: dbError.Message,
+			}
+		default:
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+	return policy, nil
+}
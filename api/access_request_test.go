@@ -0,0 +1,1130 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestAuthAPI_AddAccessRequest(t *testing.T) {
+	now := time.Date(2016, 9, 1, 10, 0, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo   RequestInfo
+		org           string
+		groupName     string
+		policyName    string
+		justification string
+		duration      time.Duration
+		// Expected results
+		expectedAccessRequest *AccessRequest
+		expectedType          string
+		wantError             error
+		// Manager Results
+		getUserByExternalIDResult *User
+		getGroupByNameResult      *Group
+		getPolicyByNameResult     *Policy
+		isMemberOfGroupResult     bool
+		// Manager Errors
+		getUserByExternalIDMethodErr error
+		getGroupByNameMethodErr      error
+		getPolicyByNameMethodErr     error
+		addAccessRequestMethodErr    error
+	}{
+		"OKCaseMembership": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			org:           "org1",
+			groupName:     "group1",
+			justification: "Incident 42",
+			duration:      time.Hour,
+			expectedAccessRequest: &AccessRequest{
+				ID:            "AR-ID",
+				Type:          ACCESS_REQUEST_TYPE_MEMBERSHIP,
+				Requester:     "123456",
+				Org:           "org1",
+				GroupName:     "group1",
+				Justification: "Incident 42",
+				Duration:      "1h0m0s",
+				Status:        ACCESS_REQUEST_STATUS_PENDING,
+				CreateAt:      now,
+				GroupUrn:      CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			expectedType: ACCESS_REQUEST_TYPE_MEMBERSHIP,
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "123456",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+		},
+		"OKCasePolicyAttachment": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			org:           "org1",
+			groupName:     "group1",
+			policyName:    "policy1",
+			justification: "Incident 42",
+			duration:      time.Hour,
+			expectedAccessRequest: &AccessRequest{
+				ID:            "AR-ID",
+				Type:          ACCESS_REQUEST_TYPE_POLICY_ATTACHMENT,
+				Requester:     "123456",
+				Org:           "org1",
+				GroupName:     "group1",
+				PolicyName:    "policy1",
+				Justification: "Incident 42",
+				Duration:      "1h0m0s",
+				Status:        ACCESS_REQUEST_STATUS_PENDING,
+				CreateAt:      now,
+				GroupUrn:      CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			expectedType: ACCESS_REQUEST_TYPE_POLICY_ATTACHMENT,
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "123456",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			getPolicyByNameResult: &Policy{
+				ID:   "POLICY-ID",
+				Name: "policy1",
+				Org:  "org1",
+			},
+		},
+		"ErrorCaseInvalidJustification": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			org:       "org1",
+			groupName: "group1",
+			duration:  time.Hour,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: justification ",
+			},
+		},
+		"ErrorCaseInvalidDuration": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			org:           "org1",
+			groupName:     "group1",
+			justification: "Incident 42",
+			duration:      MAX_ACCESS_REQUEST_DURATION + time.Hour,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: duration 721h0m0s. It must be positive and not greater than 720h0m0s",
+			},
+		},
+		"ErrorCaseRequesterNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			org:           "org1",
+			groupName:     "group1",
+			justification: "Incident 42",
+			duration:      time.Hour,
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Authenticated user with externalId 123456 not found. Unable to request access.",
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code: database.USER_NOT_FOUND,
+			},
+		},
+		"ErrorCaseRequesterSuspended": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			org:           "org1",
+			groupName:     "group1",
+			justification: "Incident 42",
+			duration:      time.Hour,
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Authenticated user with externalId 123456 is suspended. Unable to request access.",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "123456",
+				Status:     USER_STATUS_SUSPENDED,
+			},
+		},
+		"ErrorCaseGroupNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			org:           "org1",
+			groupName:     "group1",
+			justification: "Incident 42",
+			duration:      time.Hour,
+			wantError: &Error{
+				Code:    GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group not found",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "123456",
+			},
+			getGroupByNameMethodErr: &database.Error{
+				Code:    database.GROUP_NOT_FOUND,
+				Message: "Group not found",
+			},
+		},
+		"ErrorCasePolicyNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			org:           "org1",
+			groupName:     "group1",
+			policyName:    "policy1",
+			justification: "Incident 42",
+			duration:      time.Hour,
+			wantError: &Error{
+				Code:    POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Policy not found",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "123456",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+			},
+			getPolicyByNameMethodErr: &database.Error{
+				Code:    database.POLICY_NOT_FOUND,
+				Message: "Policy not found",
+			},
+		},
+		"ErrorCaseAlreadyMember": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			org:           "org1",
+			groupName:     "group1",
+			justification: "Incident 42",
+			duration:      time.Hour,
+			wantError: &Error{
+				Code:    USER_IS_ALREADY_A_MEMBER_OF_GROUP,
+				Message: "User: 123456 is already a member of Group: group1",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "123456",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+			},
+			isMemberOfGroupResult: true,
+		},
+		"ErrorCaseGroupHasMembershipRule": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			org:           "org1",
+			groupName:     "group1",
+			justification: "Incident 42",
+			duration:      time.Hour,
+			wantError: &Error{
+				Code:    GROUP_HAS_MEMBERSHIP_RULE,
+				Message: "Members of group with org org1 and name group1 are defined by its membership rule",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "123456",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+				MembershipRule: &MembershipRule{
+					PathPrefix: "/engineering/",
+				},
+			},
+		},
+		"ErrorCaseAddAccessRequestDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			org:           "org1",
+			groupName:     "group1",
+			justification: "Incident 42",
+			duration:      time.Hour,
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "123456",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+			},
+			addAccessRequestMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = test.getUserByExternalIDResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = test.getUserByExternalIDMethodErr
+		testRepo.ArgsOut[GetGroupByNameMethod][0] = test.getGroupByNameResult
+		testRepo.ArgsOut[GetGroupByNameMethod][1] = test.getGroupByNameMethodErr
+		testRepo.ArgsOut[GetPolicyByNameMethod][0] = test.getPolicyByNameResult
+		testRepo.ArgsOut[GetPolicyByNameMethod][1] = test.getPolicyByNameMethodErr
+		testRepo.ArgsOut[IsMemberOfGroupMethod][0] = test.isMemberOfGroupResult
+		testRepo.ArgsOut[AddAccessRequestMethod][0] = test.expectedAccessRequest
+		testRepo.ArgsOut[AddAccessRequestMethod][1] = test.addAccessRequestMethodErr
+
+		accessRequest, err := testAPI.AddAccessRequest(test.requestInfo, test.org, test.groupName, test.policyName,
+			test.justification, test.duration)
+		checkMethodResponse(t, n, test.wantError, err, test.expectedAccessRequest, accessRequest)
+
+		// Check access request sent to repo
+		if test.wantError == nil {
+			stored := testRepo.ArgsIn[AddAccessRequestMethod][0].(AccessRequest)
+			if stored.Type != test.expectedType || stored.Status != ACCESS_REQUEST_STATUS_PENDING {
+				t.Errorf("Test %v failed. Received different type/status %v/%v", n, stored.Type, stored.Status)
+			}
+		}
+	}
+}
+
+func TestAuthAPI_GetAccessRequestByID(t *testing.T) {
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		id          string
+		// Expected results
+		expectedAccessRequest *AccessRequest
+		wantError             error
+		// Manager Results
+		getAccessRequestByIDResult *AccessRequest
+		getUserByExternalIDResult  *User
+		getGroupsByUserIDResult    []Group
+		getAttachedPoliciesResult  []Policy
+		// Manager Errors
+		getAccessRequestByIDMethodErr error
+	}{
+		"OKCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id: "AR-ID",
+			expectedAccessRequest: &AccessRequest{
+				ID:        "AR-ID",
+				Org:       "org1",
+				Requester: "123456",
+				GroupUrn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			getAccessRequestByIDResult: &AccessRequest{
+				ID:        "AR-ID",
+				Org:       "org1",
+				Requester: "123456",
+				GroupUrn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+		},
+		"OKCaseRequester": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			id: "AR-ID",
+			expectedAccessRequest: &AccessRequest{
+				ID:        "AR-ID",
+				Org:       "org1",
+				Requester: "123456",
+				GroupUrn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			getAccessRequestByIDResult: &AccessRequest{
+				ID:        "AR-ID",
+				Org:       "org1",
+				Requester: "123456",
+				GroupUrn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+		},
+		"OKCaseApprover": {
+			requestInfo: RequestInfo{
+				Identifier: "approver",
+			},
+			id: "AR-ID",
+			expectedAccessRequest: &AccessRequest{
+				ID:        "AR-ID",
+				Org:       "org1",
+				Requester: "123456",
+				GroupUrn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			getAccessRequestByIDResult: &AccessRequest{
+				ID:        "AR-ID",
+				Org:       "org1",
+				Requester: "123456",
+				GroupUrn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "APPROVER-ID",
+				ExternalID: "approver",
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-APPROVER-ID",
+					Name: "approvers",
+				},
+			},
+			getAttachedPoliciesResult: []Policy{
+				{
+					ID:   "POLICY-APPROVER-ID",
+					Name: "approverPolicy",
+					Statements: &[]Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								ACCESS_REQUEST_ACTION_APPROVE,
+							},
+							Resources: []string{
+								GetUrnPrefix("org1", RESOURCE_GROUP, "/"),
+							},
+						},
+					},
+				},
+			},
+		},
+		"ErrorCaseNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			id: "AR-ID",
+			wantError: &Error{
+				Code:    ACCESS_REQUEST_NOT_FOUND,
+				Message: "Access request with id AR-ID not found",
+			},
+			getAccessRequestByIDMethodErr: &database.Error{
+				Code:    database.ACCESS_REQUEST_NOT_FOUND,
+				Message: "Access request with id AR-ID not found",
+			},
+		},
+		"ErrorCaseOtherOrg": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			id: "AR-ID",
+			wantError: &Error{
+				Code:    ACCESS_REQUEST_NOT_FOUND,
+				Message: "Access request with id AR-ID not found in organization org1",
+			},
+			getAccessRequestByIDResult: &AccessRequest{
+				ID:        "AR-ID",
+				Org:       "org2",
+				Requester: "123456",
+			},
+		},
+		"ErrorCaseUnauthorized": {
+			requestInfo: RequestInfo{
+				Identifier: "other",
+			},
+			id: "AR-ID",
+			wantError: &Error{
+				Code: UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId other is not allowed to access to resource " +
+					CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			getAccessRequestByIDResult: &AccessRequest{
+				ID:        "AR-ID",
+				Org:       "org1",
+				Requester: "123456",
+				GroupUrn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "OTHER-ID",
+				ExternalID: "other",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetAccessRequestByIDMethod][0] = test.getAccessRequestByIDResult
+		testRepo.ArgsOut[GetAccessRequestByIDMethod][1] = test.getAccessRequestByIDMethodErr
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = test.getUserByExternalIDResult
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = test.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = test.getAttachedPoliciesResult
+
+		accessRequest, err := testAPI.GetAccessRequestByID(test.requestInfo, "org1", test.id)
+		checkMethodResponse(t, n, test.wantError, err, test.expectedAccessRequest, accessRequest)
+	}
+}
+
+func TestAuthAPI_ListAccessRequests(t *testing.T) {
+	own := AccessRequest{
+		ID:        "AR-OWN",
+		Requester: "123456",
+		GroupUrn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+	}
+	other := AccessRequest{
+		ID:        "AR-OTHER",
+		Requester: "other",
+		GroupUrn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+	}
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		org         string
+		status      string
+		// Expected results
+		expectedAccessRequests []AccessRequest
+		wantError              error
+		// Manager Results
+		getAccessRequestsFilteredResult []AccessRequest
+		getUserByExternalIDResult       *User
+		getGroupsByUserIDResult         []Group
+		getAttachedPoliciesResult       []Policy
+		// Manager Errors
+		getAccessRequestsFilteredMethodErr error
+	}{
+		"OKCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			org:                             "org1",
+			status:                          ACCESS_REQUEST_STATUS_PENDING,
+			expectedAccessRequests:          []AccessRequest{own, other},
+			getAccessRequestsFilteredResult: []AccessRequest{own, other},
+		},
+		"OKCaseOnlyOwnRequests": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			org:                             "org1",
+			expectedAccessRequests:          []AccessRequest{own},
+			getAccessRequestsFilteredResult: []AccessRequest{own, other},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "123456",
+			},
+		},
+		"OKCaseApprover": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			org:                             "org1",
+			expectedAccessRequests:          []AccessRequest{own, other},
+			getAccessRequestsFilteredResult: []AccessRequest{own, other},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "123456",
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-APPROVER-ID",
+					Name: "approvers",
+				},
+			},
+			getAttachedPoliciesResult: []Policy{
+				{
+					ID:   "POLICY-APPROVER-ID",
+					Name: "approverPolicy",
+					Statements: &[]Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								ACCESS_REQUEST_ACTION_APPROVE,
+							},
+							Resources: []string{
+								GetUrnPrefix("org1", RESOURCE_GROUP, "/"),
+							},
+						},
+					},
+				},
+			},
+		},
+		"ErrorCaseInvalidStatus": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			org:    "org1",
+			status: "unknown",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: status unknown",
+			},
+		},
+		"ErrorCaseDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			org: "org1",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getAccessRequestsFilteredMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetAccessRequestsFilteredMethod][0] = test.getAccessRequestsFilteredResult
+		testRepo.ArgsOut[GetAccessRequestsFilteredMethod][1] = test.getAccessRequestsFilteredMethodErr
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = test.getUserByExternalIDResult
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = test.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = test.getAttachedPoliciesResult
+
+		accessRequests, err := testAPI.ListAccessRequests(test.requestInfo, test.org, test.status)
+		checkMethodResponse(t, n, test.wantError, err, test.expectedAccessRequests, accessRequests)
+	}
+}
+
+func TestAuthAPI_ApproveAccessRequest(t *testing.T) {
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		id          string
+		comment     string
+		// Expected results
+		expectedAccessRequest *AccessRequest
+		wantAttach            bool
+		wantError             error
+		// Manager Results
+		getAccessRequestByIDResult *AccessRequest
+		getGroupByNameResult       *Group
+		getPolicyByNameResult      *Policy
+		getUserByExternalIDResult  *User
+		getGroupsByUserIDResult    []Group
+		getAttachedPoliciesResult  []Policy
+		isMemberOfGroupResult      bool
+		isAttachedToGroupResult    bool
+		// Manager Errors
+		getAccessRequestByIDMethodErr error
+		updateAccessRequestMethodErr  error
+		addMemberMethodErr            error
+	}{
+		"OKCaseMembership": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id:      "AR-ID",
+			comment: "Approved",
+			expectedAccessRequest: &AccessRequest{
+				ID:     "AR-ID",
+				Org:    "org1",
+				Status: ACCESS_REQUEST_STATUS_APPROVED,
+			},
+			getAccessRequestByIDResult: &AccessRequest{
+				ID:        "AR-ID",
+				Type:      ACCESS_REQUEST_TYPE_MEMBERSHIP,
+				Requester: "123456",
+				Org:       "org1",
+				GroupName: "group1",
+				Duration:  "1h0m0s",
+				Status:    ACCESS_REQUEST_STATUS_PENDING,
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "123456",
+			},
+		},
+		"OKCasePolicyAttachment": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id:      "AR-ID",
+			comment: "Approved",
+			expectedAccessRequest: &AccessRequest{
+				ID:     "AR-ID",
+				Org:    "org1",
+				Status: ACCESS_REQUEST_STATUS_APPROVED,
+			},
+			wantAttach: true,
+			getAccessRequestByIDResult: &AccessRequest{
+				ID:         "AR-ID",
+				Type:       ACCESS_REQUEST_TYPE_POLICY_ATTACHMENT,
+				Requester:  "123456",
+				Org:        "org1",
+				GroupName:  "group1",
+				PolicyName: "policy1",
+				Duration:   "1h0m0s",
+				Status:     ACCESS_REQUEST_STATUS_PENDING,
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+			},
+			getPolicyByNameResult: &Policy{
+				ID:   "POLICY-ID",
+				Name: "policy1",
+			},
+		},
+		"ErrorCaseInvalidComment": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id: "AR-ID",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: comment ",
+			},
+		},
+		"ErrorCaseNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id:      "AR-ID",
+			comment: "Approved",
+			wantError: &Error{
+				Code:    ACCESS_REQUEST_NOT_FOUND,
+				Message: "Access request with id AR-ID not found",
+			},
+			getAccessRequestByIDMethodErr: &database.Error{
+				Code:    database.ACCESS_REQUEST_NOT_FOUND,
+				Message: "Access request with id AR-ID not found",
+			},
+		},
+		"ErrorCaseOtherOrg": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id:      "AR-ID",
+			comment: "Approved",
+			wantError: &Error{
+				Code:    ACCESS_REQUEST_NOT_FOUND,
+				Message: "Access request with id AR-ID not found in organization org1",
+			},
+			getAccessRequestByIDResult: &AccessRequest{
+				ID:        "AR-ID",
+				Org:       "org2",
+				Requester: "123456",
+				Status:    ACCESS_REQUEST_STATUS_PENDING,
+			},
+		},
+		"ErrorCaseSelfApproval": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			id:      "AR-ID",
+			comment: "Approved",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to review its own access request AR-ID",
+			},
+			getAccessRequestByIDResult: &AccessRequest{
+				ID:        "AR-ID",
+				Org:       "org1",
+				Requester: "123456",
+				Status:    ACCESS_REQUEST_STATUS_PENDING,
+			},
+		},
+		"ErrorCaseAlreadyReviewed": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id:      "AR-ID",
+			comment: "Approved",
+			wantError: &Error{
+				Code:    ACCESS_REQUEST_ALREADY_REVIEWED,
+				Message: "Access request AR-ID has already been rejected",
+			},
+			getAccessRequestByIDResult: &AccessRequest{
+				ID:        "AR-ID",
+				Org:       "org1",
+				Requester: "123456",
+				Status:    ACCESS_REQUEST_STATUS_REJECTED,
+			},
+		},
+		"ErrorCaseConcurrentlyReviewed": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id:      "AR-ID",
+			comment: "Approved",
+			wantError: &Error{
+				Code:    ACCESS_REQUEST_ALREADY_REVIEWED,
+				Message: "Access request AR-ID has already been reviewed",
+			},
+			getAccessRequestByIDResult: &AccessRequest{
+				ID:        "AR-ID",
+				Type:      ACCESS_REQUEST_TYPE_MEMBERSHIP,
+				Requester: "123456",
+				Org:       "org1",
+				GroupName: "group1",
+				Duration:  "1h0m0s",
+				Status:    ACCESS_REQUEST_STATUS_PENDING,
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "123456",
+			},
+			updateAccessRequestMethodErr: &database.Error{
+				Code:    database.ACCESS_REQUEST_ALREADY_REVIEWED,
+				Message: "Access request AR-ID has already been reviewed",
+			},
+		},
+		"ErrorCaseUnauthorizedAttachPolicy": {
+			requestInfo: RequestInfo{
+				Identifier: "approver",
+			},
+			id:      "AR-ID",
+			comment: "Approved",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId approver is not allowed to access to resource urn:iws:iam:org1:group/path/group1",
+			},
+			getAccessRequestByIDResult: &AccessRequest{
+				ID:         "AR-ID",
+				Type:       ACCESS_REQUEST_TYPE_POLICY_ATTACHMENT,
+				Requester:  "123456",
+				Org:        "org1",
+				GroupName:  "group1",
+				GroupUrn:   CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+				PolicyName: "policy1",
+				Duration:   "1h0m0s",
+				Status:     ACCESS_REQUEST_STATUS_PENDING,
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "APPROVER-ID",
+				ExternalID: "approver",
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-APPROVER-ID",
+					Name: "approvers",
+					Org:  "org1",
+				},
+			},
+			getAttachedPoliciesResult: []Policy{
+				{
+					ID:   "POLICY-APPROVER-ID",
+					Name: "approvers",
+					Org:  "org1",
+					Statements: &[]Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								ACCESS_REQUEST_ACTION_APPROVE,
+								GROUP_ACTION_GET_GROUP,
+								GROUP_ACTION_ADD_MEMBER,
+							},
+							Resources: []string{
+								GetUrnPrefix("org1", RESOURCE_GROUP, "/"),
+							},
+						},
+					},
+				},
+			},
+		},
+		"ErrorCaseUnauthorizedAddMember": {
+			requestInfo: RequestInfo{
+				Identifier: "approver",
+			},
+			id:      "AR-ID",
+			comment: "Approved",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId approver is not allowed to access to resource urn:iws:iam:org1:group/path/group1",
+			},
+			getAccessRequestByIDResult: &AccessRequest{
+				ID:        "AR-ID",
+				Type:      ACCESS_REQUEST_TYPE_MEMBERSHIP,
+				Requester: "123456",
+				Org:       "org1",
+				GroupName: "group1",
+				GroupUrn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+				Duration:  "1h0m0s",
+				Status:    ACCESS_REQUEST_STATUS_PENDING,
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "APPROVER-ID",
+				ExternalID: "approver",
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-APPROVER-ID",
+					Name: "approvers",
+					Org:  "org1",
+				},
+			},
+			getAttachedPoliciesResult: []Policy{
+				{
+					ID:   "POLICY-APPROVER-ID",
+					Name: "approvers",
+					Org:  "org1",
+					Statements: &[]Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								ACCESS_REQUEST_ACTION_APPROVE,
+								GROUP_ACTION_GET_GROUP,
+								GROUP_ACTION_ATTACH_GROUP_POLICY,
+							},
+							Resources: []string{
+								GetUrnPrefix("org1", RESOURCE_GROUP, "/"),
+							},
+						},
+					},
+				},
+			},
+		},
+		"ErrorCaseAlreadyMember": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id:      "AR-ID",
+			comment: "Approved",
+			wantError: &Error{
+				Code:    USER_IS_ALREADY_A_MEMBER_OF_GROUP,
+				Message: "User: 123456 is already a member of Group: group1",
+			},
+			getAccessRequestByIDResult: &AccessRequest{
+				ID:        "AR-ID",
+				Type:      ACCESS_REQUEST_TYPE_MEMBERSHIP,
+				Requester: "123456",
+				Org:       "org1",
+				GroupName: "group1",
+				Duration:  "1h0m0s",
+				Status:    ACCESS_REQUEST_STATUS_PENDING,
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "123456",
+			},
+			isMemberOfGroupResult: true,
+		},
+		"ErrorCaseAddMemberDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id:      "AR-ID",
+			comment: "Approved",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getAccessRequestByIDResult: &AccessRequest{
+				ID:        "AR-ID",
+				Type:      ACCESS_REQUEST_TYPE_MEMBERSHIP,
+				Requester: "123456",
+				Org:       "org1",
+				GroupName: "group1",
+				Duration:  "1h0m0s",
+				Status:    ACCESS_REQUEST_STATUS_PENDING,
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "123456",
+			},
+			addMemberMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetAccessRequestByIDMethod][0] = test.getAccessRequestByIDResult
+		testRepo.ArgsOut[GetAccessRequestByIDMethod][1] = test.getAccessRequestByIDMethodErr
+		testRepo.ArgsOut[GetGroupByNameMethod][0] = test.getGroupByNameResult
+		testRepo.ArgsOut[GetPolicyByNameMethod][0] = test.getPolicyByNameResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = test.getUserByExternalIDResult
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = test.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = test.getAttachedPoliciesResult
+		testRepo.ArgsOut[IsMemberOfGroupMethod][0] = test.isMemberOfGroupResult
+		testRepo.ArgsOut[IsAttachedToGroupMethod][0] = test.isAttachedToGroupResult
+		testRepo.ArgsOut[AddMemberMethod][0] = test.addMemberMethodErr
+		testRepo.ArgsOut[UpdateAccessRequestMethod][0] = test.expectedAccessRequest
+		testRepo.ArgsOut[UpdateAccessRequestMethod][1] = test.updateAccessRequestMethodErr
+
+		accessRequest, err := testAPI.ApproveAccessRequest(test.requestInfo, "org1", test.id, test.comment)
+		checkMethodResponse(t, n, test.wantError, err, test.expectedAccessRequest, accessRequest)
+
+		if test.wantError != nil {
+			continue
+		}
+
+		// Check that access was granted with an expiration
+		if test.wantAttach {
+			if notAfter, ok := testRepo.ArgsIn[AttachPolicyMethod][3].(*time.Time); !ok || notAfter == nil {
+				t.Errorf("Test %v failed. Policy attached without expiration", n)
+			}
+		} else {
+			if expiresAt, ok := testRepo.ArgsIn[AddMemberMethod][2].(*time.Time); !ok || expiresAt == nil {
+				t.Errorf("Test %v failed. Member added without expiration", n)
+			}
+		}
+		updated := testRepo.ArgsIn[UpdateAccessRequestMethod][0].(AccessRequest)
+		if updated.Status != ACCESS_REQUEST_STATUS_APPROVED || updated.Reviewer != test.requestInfo.Identifier {
+			t.Errorf("Test %v failed. Received different status/reviewer %v/%v", n, updated.Status, updated.Reviewer)
+		}
+	}
+}
+
+func TestAuthAPI_RejectAccessRequest(t *testing.T) {
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		id          string
+		comment     string
+		// Expected results
+		expectedAccessRequest *AccessRequest
+		wantError             error
+		// Manager Results
+		getAccessRequestByIDResult *AccessRequest
+		// Manager Errors
+		updateAccessRequestMethodErr error
+	}{
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id:      "AR-ID",
+			comment: "Not needed",
+			expectedAccessRequest: &AccessRequest{
+				ID:            "AR-ID",
+				Org:           "org1",
+				Requester:     "123456",
+				Status:        ACCESS_REQUEST_STATUS_REJECTED,
+				Reviewer:      "admin",
+				ReviewComment: "Not needed",
+			},
+			getAccessRequestByIDResult: &AccessRequest{
+				ID:        "AR-ID",
+				Org:       "org1",
+				Requester: "123456",
+				Status:    ACCESS_REQUEST_STATUS_PENDING,
+			},
+		},
+		"ErrorCaseAlreadyReviewed": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id:      "AR-ID",
+			comment: "Not needed",
+			wantError: &Error{
+				Code:    ACCESS_REQUEST_ALREADY_REVIEWED,
+				Message: "Access request AR-ID has already been approved",
+			},
+			getAccessRequestByIDResult: &AccessRequest{
+				ID:        "AR-ID",
+				Org:       "org1",
+				Requester: "123456",
+				Status:    ACCESS_REQUEST_STATUS_APPROVED,
+			},
+		},
+		"ErrorCaseUpdateDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id:      "AR-ID",
+			comment: "Not needed",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getAccessRequestByIDResult: &AccessRequest{
+				ID:        "AR-ID",
+				Org:       "org1",
+				Requester: "123456",
+				Status:    ACCESS_REQUEST_STATUS_PENDING,
+			},
+			updateAccessRequestMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetAccessRequestByIDMethod][0] = test.getAccessRequestByIDResult
+		testRepo.ArgsOut[UpdateAccessRequestMethod][0] = test.expectedAccessRequest
+		testRepo.ArgsOut[UpdateAccessRequestMethod][1] = test.updateAccessRequestMethodErr
+
+		accessRequest, err := testAPI.RejectAccessRequest(test.requestInfo, "org1", test.id, test.comment)
+		checkMethodResponse(t, n, test.wantError, err, test.expectedAccessRequest, accessRequest)
+
+		if test.wantError == nil {
+			updated := testRepo.ArgsIn[UpdateAccessRequestMethod][0].(AccessRequest)
+			updated.ReviewAt = nil
+			if diff := pretty.Compare(updated, *test.expectedAccessRequest); diff != "" {
+				t.Errorf("Test %v failed. Received different access requests (received/wanted) %v", n, diff)
+			}
+		}
+	}
+}
@@ -0,0 +1,574 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/satori/go.uuid"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// TYPE DEFINITIONS
+
+// Namespace domain. It groups the actions of a service, e.g. billing for billing:GetInvoice
+type Namespace struct {
+	Name        string    `json:"name, omitempty"`
+	Description string    `json:"description, omitempty"`
+	CreateAt    time.Time `json:"createAt, omitempty"`
+	// Built-in namespaces can't be modified
+	BuiltIn bool `json:"builtIn, omitempty"`
+}
+
+func (n Namespace) String() string {
+	return fmt.Sprintf("[name: %v, description: %v, builtIn: %v]", n.Name, n.Description, n.BuiltIn)
+}
+
+// Action domain
+type Action struct {
+	ID          string `json:"id, omitempty"`
+	Namespace   string `json:"namespace, omitempty"`
+	Name        string `json:"name, omitempty"`
+	Description string `json:"description, omitempty"`
+	// Resource URN pattern the action applies to
+	ResourceUrnPattern string    `json:"resourceUrnPattern, omitempty"`
+	CreateAt           time.Time `json:"createAt, omitempty"`
+	BuiltIn            bool      `json:"builtIn, omitempty"`
+}
+
+func (a Action) String() string {
+	return fmt.Sprintf("[id: %v, namespace: %v, name: %v, description: %v, resourceUrnPattern: %v, builtIn: %v]",
+		a.ID, a.Namespace, a.Name, a.Description, a.ResourceUrnPattern, a.BuiltIn)
+}
+
+// Full action name used in policy statements
+func (a Action) FullName() string {
+	return fmt.Sprintf("%v:%v", a.Namespace, a.Name)
+}
+
+// Built-in IAM namespace with the actions used by foulkon itself
+var iamNamespace = Namespace{
+	Name:        IAM_NAMESPACE,
+	Description: "Foulkon identity and access management",
+	BuiltIn:     true,
+}
+
+var iamActions = []Action{
+	createBuiltInAction(USER_ACTION_CREATE_USER, "Create a user", "urn:iws:iam::user/*"),
+	createBuiltInAction(USER_ACTION_DELETE_USER, "Delete a user", "urn:iws:iam::user/*"),
+	createBuiltInAction(USER_ACTION_GET_USER, "Retrieve a user", "urn:iws:iam::user/*"),
+	createBuiltInAction(USER_ACTION_LIST_USERS, "List users", "urn:iws:iam::user/*"),
+	createBuiltInAction(USER_ACTION_UPDATE_USER, "Update a user", "urn:iws:iam::user/*"),
+	createBuiltInAction(USER_ACTION_LIST_GROUPS_FOR_USER, "List groups of a user", "urn:iws:iam::user/*"),
+	createBuiltInAction(USER_ACTION_TAG_USER, "Add or update a tag of a user", "urn:iws:iam::user/*"),
+	createBuiltInAction(USER_ACTION_UNTAG_USER, "Remove a tag from a user", "urn:iws:iam::user/*"),
+	createBuiltInAction(USER_ACTION_SUSPEND_USER, "Suspend a user, denying every action to it", "urn:iws:iam::user/*"),
+	createBuiltInAction(USER_ACTION_REACTIVATE_USER, "Reactivate a suspended user", "urn:iws:iam::user/*"),
+	createBuiltInAction(USER_ACTION_RENAME_USER, "Change the externalId of a user", "urn:iws:iam::user/*"),
+	createBuiltInAction(GROUP_ACTION_CREATE_GROUP, "Create a group", "urn:iws:iam:*:group/*"),
+	createBuiltInAction(GROUP_ACTION_DELETE_GROUP, "Delete a group", "urn:iws:iam:*:group/*"),
+	createBuiltInAction(GROUP_ACTION_GET_GROUP, "Retrieve a group", "urn:iws:iam:*:group/*"),
+	createBuiltInAction(GROUP_ACTION_LIST_GROUPS, "List groups", "urn:iws:iam:*:group/*"),
+	createBuiltInAction(GROUP_ACTION_UPDATE_GROUP, "Update a group", "urn:iws:iam:*:group/*"),
+	createBuiltInAction(GROUP_ACTION_LIST_MEMBERS, "List members of a group", "urn:iws:iam:*:group/*"),
+	createBuiltInAction(GROUP_ACTION_ADD_MEMBER, "Add a member to a group", "urn:iws:iam:*:group/*"),
+	createBuiltInAction(GROUP_ACTION_REMOVE_MEMBER, "Remove a member from a group", "urn:iws:iam:*:group/*"),
+	createBuiltInAction(GROUP_ACTION_ATTACH_GROUP_POLICY, "Attach a policy to a group", "urn:iws:iam:*:group/*"),
+	createBuiltInAction(GROUP_ACTION_DETACH_GROUP_POLICY, "Detach a policy from a group", "urn:iws:iam:*:group/*"),
+	createBuiltInAction(GROUP_ACTION_LIST_ATTACHED_GROUP_POLICIES, "List policies attached to a group", "urn:iws:iam:*:group/*"),
+	createBuiltInAction(GROUP_ACTION_TAG_GROUP, "Add or update a tag of a group", "urn:iws:iam:*:group/*"),
+	createBuiltInAction(GROUP_ACTION_UNTAG_GROUP, "Remove a tag from a group", "urn:iws:iam:*:group/*"),
+	createBuiltInAction(GROUP_ACTION_ADD_GROUP_OWNER, "Add an owner to a group", "urn:iws:iam:*:group/*"),
+	createBuiltInAction(GROUP_ACTION_REMOVE_GROUP_OWNER, "Remove an owner from a group", "urn:iws:iam:*:group/*"),
+	createBuiltInAction(POLICY_ACTION_CREATE_POLICY, "Create a policy", "urn:iws:iam:*:policy/*"),
+	createBuiltInAction(POLICY_ACTION_DELETE_POLICY, "Delete a policy", "urn:iws:iam:*:policy/*"),
+	createBuiltInAction(POLICY_ACTION_UPDATE_POLICY, "Update a policy", "urn:iws:iam:*:policy/*"),
+	createBuiltInAction(POLICY_ACTION_GET_POLICY, "Retrieve a policy", "urn:iws:iam:*:policy/*"),
+	createBuiltInAction(POLICY_ACTION_LIST_ATTACHED_GROUPS, "List groups attached to a policy", "urn:iws:iam:*:policy/*"),
+	createBuiltInAction(POLICY_ACTION_LIST_POLICIES, "List policies", "urn:iws:iam:*:policy/*"),
+	createBuiltInAction(POLICY_ACTION_TAG_POLICY, "Add or update a tag of a policy", "urn:iws:iam:*:policy/*"),
+	createBuiltInAction(POLICY_ACTION_UNTAG_POLICY, "Remove a tag from a policy", "urn:iws:iam:*:policy/*"),
+	createBuiltInAction(ORGANIZATION_ACTION_CREATE_ORGANIZATION, "Create an organization", "urn:iws:iam::organization/*"),
+	createBuiltInAction(ORGANIZATION_ACTION_DELETE_ORGANIZATION, "Delete an organization with its groups and policies", "urn:iws:iam::organization/*"),
+	createBuiltInAction(ORGANIZATION_ACTION_GET_ORGANIZATION, "Retrieve an organization", "urn:iws:iam::organization/*"),
+	createBuiltInAction(ORGANIZATION_ACTION_LIST_ORGANIZATIONS, "List organizations", "urn:iws:iam::organization/*"),
+	createBuiltInAction(ORGANIZATION_ACTION_UPDATE_ORGANIZATION, "Update an organization", "urn:iws:iam::organization/*"),
+	createBuiltInAction(ACCESS_REQUEST_ACTION_APPROVE, "Approve or reject access requests for a group", "urn:iws:iam:*:group/*"),
+}
+
+// ACTION API IMPLEMENTATION
+
+func (api AuthAPI) AddNamespace(requestInfo RequestInfo, name string, description string) (*Namespace, error) {
+	// Validate fields
+	if !IsValidName(name) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: name %v", name),
+		}
+	}
+	if len(description) > MAX_DESCRIPTION_LENGTH {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: description %v", description),
+		}
+	}
+
+	// Check restrictions
+	if err := checkActionRegistryAdmin(requestInfo); err != nil {
+		return nil, err
+	}
+
+	// Check if namespace already exists
+	if name == IAM_NAMESPACE {
+		return nil, &Error{
+			Code:    NAMESPACE_ALREADY_EXIST,
+			Message: fmt.Sprintf("Unable to create namespace, namespace %v already exist", name),
+		}
+	}
+	_, err := api.ActionRepo.GetNamespaceByName(name)
+
+	// Check if namespace could be retrieved
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		// Namespace doesn't exist in DB
+		case database.NAMESPACE_NOT_FOUND:
+			namespace := Namespace{
+				Name:        name,
+				Description: description,
+				CreateAt:    time.Now().UTC(),
+			}
+			var createdNamespace *Namespace
+			err := api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+				var err error
+				createdNamespace, err = repo.AddNamespace(namespace)
+				if err != nil {
+					return err
+				}
+				return api.auditOperation(repo, requestInfo, AUDIT_ACTION_CREATE_NAMESPACE,
+					getRegistryUrn(AUDIT_ENTITY_NAMESPACE, "", createdNamespace.Name), nil, createdNamespace)
+			})
+
+			// Check if there is an unexpected error in DB
+			if err != nil {
+				//Transform to DB error
+				dbError := err.(*database.Error)
+				return nil, &Error{
+					Code:    UNKNOWN_API_ERROR,
+					Message: dbError.Message,
+				}
+			}
+
+			LogOperation(api.Logger, requestInfo, fmt.Sprintf("Namespace created %+v", createdNamespace))
+			return createdNamespace, nil
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	} else { // Fail if namespace exists
+		return nil, &Error{
+			Code:    NAMESPACE_ALREADY_EXIST,
+			Message: fmt.Sprintf("Unable to create namespace, namespace %v already exist", name),
+		}
+	}
+}
+
+func (api AuthAPI) ListNamespaces(requestInfo RequestInfo) ([]Namespace, error) {
+	// Call repo to retrieve the namespaces
+	namespaces, err := api.ActionRepo.GetNamespaces()
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	return append([]Namespace{iamNamespace}, namespaces...), nil
+}
+
+func (api AuthAPI) RemoveNamespace(requestInfo RequestInfo, name string) error {
+	// Validate fields
+	if !IsValidName(name) {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: name %v", name),
+		}
+	}
+	if name == IAM_NAMESPACE {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: namespace %v is built-in and can't be modified", name),
+		}
+	}
+
+	// Check restrictions
+	if err := checkActionRegistryAdmin(requestInfo); err != nil {
+		return err
+	}
+
+	// Retrieve namespace
+	namespace, err := api.getNamespace(name)
+	if err != nil {
+		return err
+	}
+
+	// Remove namespace with its actions
+	err = api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		if err := repo.RemoveNamespace(name); err != nil {
+			return err
+		}
+		return api.auditOperation(repo, requestInfo, AUDIT_ACTION_DELETE_NAMESPACE, getRegistryUrn(AUDIT_ENTITY_NAMESPACE, "", name), namespace, nil)
+	})
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Namespace deleted %v", name))
+	return nil
+}
+
+func (api AuthAPI) AddAction(requestInfo RequestInfo, namespace string, name string, description string,
+	resourceUrnPattern string) (*Action, error) {
+	// Validate fields
+	if !IsValidName(namespace) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: namespace %v", namespace),
+		}
+	}
+	if namespace == IAM_NAMESPACE {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: namespace %v is built-in and can't be modified", namespace),
+		}
+	}
+	if !IsValidName(name) || AreValidActions([]string{fmt.Sprintf("%v:%v", namespace, name)}) != nil {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: name %v", name),
+		}
+	}
+	if len(description) > MAX_DESCRIPTION_LENGTH {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: description %v", description),
+		}
+	}
+	if len(resourceUrnPattern) > 0 && AreValidResources([]string{resourceUrnPattern}) != nil {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: resourceUrnPattern %v", resourceUrnPattern),
+		}
+	}
+
+	// Check restrictions
+	if err := checkActionRegistryAdmin(requestInfo); err != nil {
+		return nil, err
+	}
+
+	// Namespace must be registered
+	if _, err := api.getNamespace(namespace); err != nil {
+		return nil, err
+	}
+
+	// Check if action already exists
+	_, err := api.ActionRepo.GetActionByName(namespace, name)
+
+	// Check if action could be retrieved
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		// Action doesn't exist in DB
+		case database.ACTION_NOT_FOUND:
+			action := Action{
+				ID:                 uuid.NewV4().String(),
+				Namespace:          namespace,
+				Name:               name,
+				Description:        description,
+				ResourceUrnPattern: resourceUrnPattern,
+				CreateAt:           time.Now().UTC(),
+			}
+			var createdAction *Action
+			err := api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+				var err error
+				createdAction, err = repo.AddAction(action)
+				if err != nil {
+					return err
+				}
+				return api.auditOperation(repo, requestInfo, AUDIT_ACTION_CREATE_ACTION,
+					getRegistryUrn(AUDIT_ENTITY_ACTION, createdAction.Namespace, createdAction.Name), nil, createdAction)
+			})
+
+			// Check if there is an unexpected error in DB
+			if err != nil {
+				//Transform to DB error
+				dbError := err.(*database.Error)
+				return nil, &Error{
+					Code:    UNKNOWN_API_ERROR,
+					Message: dbError.Message,
+				}
+			}
+
+			LogOperation(api.Logger, requestInfo, fmt.Sprintf("Action created %+v", createdAction))
+			return createdAction, nil
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	} else { // Fail if action exists
+		return nil, &Error{
+			Code:    ACTION_ALREADY_EXIST,
+			Message: fmt.Sprintf("Unable to create action, action %v:%v already exist", namespace, name),
+		}
+	}
+}
+
+func (api AuthAPI) GetAction(requestInfo RequestInfo, namespace string, name string) (*Action, error) {
+	// Validate fields
+	if !IsValidName(namespace) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: namespace %v", namespace),
+		}
+	}
+	if !IsValidName(name) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: name %v", name),
+		}
+	}
+
+	if namespace == IAM_NAMESPACE {
+		for _, action := range iamActions {
+			if action.Name == name {
+				builtInAction := action
+				return &builtInAction, nil
+			}
+		}
+		return nil, &Error{
+			Code:    ACTION_NOT_FOUND,
+			Message: fmt.Sprintf("Action %v:%v not found", namespace, name),
+		}
+	}
+
+	// Call repo to retrieve the action
+	action, err := api.ActionRepo.GetActionByName(namespace, name)
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.ACTION_NOT_FOUND:
+			return nil, &Error{
+				Code:    ACTION_NOT_FOUND,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	return action, nil
+}
+
+func (api AuthAPI) ListActions(requestInfo RequestInfo, namespace string) ([]Action, error) {
+	// Validate fields
+	if !IsValidName(namespace) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: namespace %v", namespace),
+		}
+	}
+
+	// Namespace must be registered
+	if _, err := api.getNamespace(namespace); err != nil {
+		return nil, err
+	}
+
+	return api.getActionsByNamespace(namespace)
+}
+
+func (api AuthAPI) RemoveAction(requestInfo RequestInfo, namespace string, name string) error {
+	// Validate fields
+	if namespace == IAM_NAMESPACE {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: namespace %v is built-in and can't be modified", namespace),
+		}
+	}
+
+	// Check restrictions
+	if err := checkActionRegistryAdmin(requestInfo); err != nil {
+		return err
+	}
+
+	// Retrieve action
+	action, err := api.GetAction(requestInfo, namespace, name)
+	if err != nil {
+		return err
+	}
+
+	// Remove action
+	err = api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		if err := repo.RemoveAction(action.ID); err != nil {
+			return err
+		}
+		return api.auditOperation(repo, requestInfo, AUDIT_ACTION_DELETE_ACTION, getRegistryUrn(AUDIT_ENTITY_ACTION, action.Namespace, action.Name), action, nil)
+	})
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Action deleted %+v", action))
+	return nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Check that every action in statements is registered. Wildcard actions must match at least
+// one registered action of its namespace, and "*" is always accepted.
+func (api AuthAPI) areRegisteredActions(statements []Statement) error {
+	registered := map[string][]Action{}
+	for _, statement := range statements {
+		for _, statementAction := range statement.Actions {
+			if statementAction == "*" {
+				continue
+			}
+			blocks := strings.SplitN(statementAction, ":", 2)
+			if len(blocks) < 2 {
+				return &Error{
+					Code:    INVALID_PARAMETER_ERROR,
+					Message: fmt.Sprintf("Unknown action: %v - Actions must be prefixed with a registered namespace", statementAction),
+				}
+			}
+			namespace := blocks[0]
+			actions, ok := registered[namespace]
+			if !ok {
+				if _, err := api.getNamespace(namespace); err != nil {
+					if apiError := err.(*Error); apiError.Code == NAMESPACE_NOT_FOUND {
+						return &Error{
+							Code:    INVALID_PARAMETER_ERROR,
+							Message: fmt.Sprintf("Unknown action: %v - Namespace %v is not registered", statementAction, namespace),
+						}
+					}
+					return err
+				}
+				var err error
+				actions, err = api.getActionsByNamespace(namespace)
+				if err != nil {
+					return err
+				}
+				registered[namespace] = actions
+			}
+			if !matchesRegisteredAction(statementAction, actions) {
+				return &Error{
+					Code:    INVALID_PARAMETER_ERROR,
+					Message: fmt.Sprintf("Unknown action: %v", statementAction),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Retrieve namespace, taking into account built-in ones
+func (api AuthAPI) getNamespace(name string) (*Namespace, error) {
+	if name == IAM_NAMESPACE {
+		namespace := iamNamespace
+		return &namespace, nil
+	}
+	namespace, err := api.ActionRepo.GetNamespaceByName(name)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.NAMESPACE_NOT_FOUND:
+			return nil, &Error{
+				Code:    NAMESPACE_NOT_FOUND,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+	return namespace, nil
+}
+
+// Retrieve actions of a namespace, taking into account built-in ones
+func (api AuthAPI) getActionsByNamespace(namespace string) ([]Action, error) {
+	if namespace == IAM_NAMESPACE {
+		return iamActions, nil
+	}
+	actions, err := api.ActionRepo.GetActionsFiltered(namespace)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+	return actions, nil
+}
+
+// Only admin can modify the action registry because it is shared by all organizations
+func checkActionRegistryAdmin(requestInfo RequestInfo) error {
+	if !requestInfo.Admin {
+		return &Error{
+			Code:    UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to modify the action registry", requestInfo.Identifier),
+		}
+	}
+	return nil
+}
+
+// Check if action, which may end with a wildcard, matches any of the registered actions
+func matchesRegisteredAction(statementAction string, actions []Action) bool {
+	prefix := strings.TrimSuffix(statementAction, "*")
+	isPrefix := prefix != statementAction
+	for _, action := range actions {
+		fullName := action.FullName()
+		if fullName == statementAction || (isPrefix && strings.HasPrefix(fullName, prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+func createBuiltInAction(fullName string, description string, resourceUrnPattern string) Action {
+	blocks := strings.SplitN(fullName, ":", 2)
+	return Action{
+		Namespace:          blocks[0],
+		Name:               blocks[1],
+		Description:        description,
+		ResourceUrnPattern: resourceUrnPattern,
+		BuiltIn:            true,
+	}
+}
@@ -0,0 +1,679 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestAuthAPI_AddNamespace(t *testing.T) {
+	now := time.Date(2016, 9, 1, 10, 0, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		name        string
+		description string
+		// Expected results
+		expectedNamespace *Namespace
+		wantError         error
+		// Manager Errors
+		getNamespaceByNameMethodErr error
+		addNamespaceMethodErr       error
+	}{
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			name:        "billing",
+			description: "Billing service",
+			expectedNamespace: &Namespace{
+				Name:        "billing",
+				Description: "Billing service",
+				CreateAt:    now,
+			},
+			getNamespaceByNameMethodErr: &database.Error{
+				Code: database.NAMESPACE_NOT_FOUND,
+			},
+		},
+		"ErrorCaseInvalidName": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			name: "*%~#@|",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: name *%~#@|",
+			},
+		},
+		"ErrorCaseNotAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			name: "billing",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to modify the action registry",
+			},
+		},
+		"ErrorCaseBuiltInNamespace": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			name: IAM_NAMESPACE,
+			wantError: &Error{
+				Code:    NAMESPACE_ALREADY_EXIST,
+				Message: "Unable to create namespace, namespace iam already exist",
+			},
+		},
+		"ErrorCaseNamespaceAlreadyExist": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			name: "billing",
+			wantError: &Error{
+				Code:    NAMESPACE_ALREADY_EXIST,
+				Message: "Unable to create namespace, namespace billing already exist",
+			},
+		},
+		"ErrorCaseGetNamespaceDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			name: "billing",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getNamespaceByNameMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+		"ErrorCaseAddNamespaceDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			name: "billing",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getNamespaceByNameMethodErr: &database.Error{
+				Code: database.NAMESPACE_NOT_FOUND,
+			},
+			addNamespaceMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetNamespaceByNameMethod][1] = test.getNamespaceByNameMethodErr
+		testRepo.ArgsOut[AddNamespaceMethod][0] = test.expectedNamespace
+		testRepo.ArgsOut[AddNamespaceMethod][1] = test.addNamespaceMethodErr
+
+		namespace, err := testAPI.AddNamespace(test.requestInfo, test.name, test.description)
+		checkMethodResponse(t, n, test.wantError, err, test.expectedNamespace, namespace)
+	}
+}
+
+func TestAuthAPI_ListNamespaces(t *testing.T) {
+	testcases := map[string]struct {
+		// Expected results
+		expectedNamespaces []Namespace
+		wantError          error
+		// Manager Results
+		getNamespacesResult []Namespace
+		// Manager Errors
+		getNamespacesMethodErr error
+	}{
+		"OKCase": {
+			expectedNamespaces: []Namespace{
+				iamNamespace,
+				{
+					Name: "billing",
+				},
+			},
+			getNamespacesResult: []Namespace{
+				{
+					Name: "billing",
+				},
+			},
+		},
+		"ErrorCaseDBErr": {
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getNamespacesMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetNamespacesMethod][0] = test.getNamespacesResult
+		testRepo.ArgsOut[GetNamespacesMethod][1] = test.getNamespacesMethodErr
+
+		namespaces, err := testAPI.ListNamespaces(RequestInfo{Identifier: "123456"})
+		checkMethodResponse(t, n, test.wantError, err, test.expectedNamespaces, namespaces)
+	}
+}
+
+func TestAuthAPI_RemoveNamespace(t *testing.T) {
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		name        string
+		// Expected results
+		wantError error
+		// Manager Errors
+		getNamespaceByNameMethodErr error
+		removeNamespaceMethodErr    error
+	}{
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			name: "billing",
+		},
+		"ErrorCaseBuiltInNamespace": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			name: IAM_NAMESPACE,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: namespace iam is built-in and can't be modified",
+			},
+		},
+		"ErrorCaseNotAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			name: "billing",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to modify the action registry",
+			},
+		},
+		"ErrorCaseNamespaceNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			name: "billing",
+			wantError: &Error{
+				Code:    NAMESPACE_NOT_FOUND,
+				Message: "Namespace with name billing not found",
+			},
+			getNamespaceByNameMethodErr: &database.Error{
+				Code:    database.NAMESPACE_NOT_FOUND,
+				Message: "Namespace with name billing not found",
+			},
+		},
+		"ErrorCaseRemoveNamespaceDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			name: "billing",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			removeNamespaceMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetNamespaceByNameMethod][0] = &Namespace{Name: test.name}
+		testRepo.ArgsOut[GetNamespaceByNameMethod][1] = test.getNamespaceByNameMethodErr
+		testRepo.ArgsOut[RemoveNamespaceMethod][0] = test.removeNamespaceMethodErr
+
+		err := testAPI.RemoveNamespace(test.requestInfo, test.name)
+		checkMethodResponse(t, n, test.wantError, err, nil, nil)
+	}
+}
+
+func TestAuthAPI_AddAction(t *testing.T) {
+	now := time.Date(2016, 9, 1, 10, 0, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo        RequestInfo
+		namespace          string
+		name               string
+		description        string
+		resourceUrnPattern string
+		// Expected results
+		expectedAction *Action
+		wantError      error
+		// Manager Errors
+		getNamespaceByNameMethodErr error
+		getActionByNameMethodErr    error
+		addActionMethodErr          error
+	}{
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			namespace:          "billing",
+			name:               "GetInvoice",
+			description:        "Retrieve an invoice",
+			resourceUrnPattern: "urn:ews:billing:instance:invoice/*",
+			expectedAction: &Action{
+				ID:                 "ACTION-ID",
+				Namespace:          "billing",
+				Name:               "GetInvoice",
+				Description:        "Retrieve an invoice",
+				ResourceUrnPattern: "urn:ews:billing:instance:invoice/*",
+				CreateAt:           now,
+			},
+			getActionByNameMethodErr: &database.Error{
+				Code: database.ACTION_NOT_FOUND,
+			},
+		},
+		"ErrorCaseBuiltInNamespace": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			namespace: IAM_NAMESPACE,
+			name:      "CustomAction",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: namespace iam is built-in and can't be modified",
+			},
+		},
+		"ErrorCaseInvalidName": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			namespace: "billing",
+			name:      "Get*",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: name Get*",
+			},
+		},
+		"ErrorCaseInvalidResourceUrnPattern": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			namespace:          "billing",
+			name:               "GetInvoice",
+			resourceUrnPattern: "invalid",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: resourceUrnPattern invalid",
+			},
+		},
+		"ErrorCaseNotAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			namespace: "billing",
+			name:      "GetInvoice",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to modify the action registry",
+			},
+		},
+		"ErrorCaseNamespaceNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			namespace: "billing",
+			name:      "GetInvoice",
+			wantError: &Error{
+				Code:    NAMESPACE_NOT_FOUND,
+				Message: "Namespace with name billing not found",
+			},
+			getNamespaceByNameMethodErr: &database.Error{
+				Code:    database.NAMESPACE_NOT_FOUND,
+				Message: "Namespace with name billing not found",
+			},
+		},
+		"ErrorCaseActionAlreadyExist": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			namespace: "billing",
+			name:      "GetInvoice",
+			wantError: &Error{
+				Code:    ACTION_ALREADY_EXIST,
+				Message: "Unable to create action, action billing:GetInvoice already exist",
+			},
+		},
+		"ErrorCaseAddActionDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			namespace: "billing",
+			name:      "GetInvoice",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getActionByNameMethodErr: &database.Error{
+				Code: database.ACTION_NOT_FOUND,
+			},
+			addActionMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetNamespaceByNameMethod][0] = &Namespace{Name: test.namespace}
+		testRepo.ArgsOut[GetNamespaceByNameMethod][1] = test.getNamespaceByNameMethodErr
+		testRepo.ArgsOut[GetActionByNameMethod][1] = test.getActionByNameMethodErr
+		testRepo.ArgsOut[AddActionMethod][0] = test.expectedAction
+		testRepo.ArgsOut[AddActionMethod][1] = test.addActionMethodErr
+
+		action, err := testAPI.AddAction(test.requestInfo, test.namespace, test.name, test.description, test.resourceUrnPattern)
+		checkMethodResponse(t, n, test.wantError, err, test.expectedAction, action)
+	}
+}
+
+func TestAuthAPI_GetAction(t *testing.T) {
+	testcases := map[string]struct {
+		// API Method args
+		namespace string
+		name      string
+		// Expected results
+		expectedAction *Action
+		wantError      error
+		// Manager Results
+		getActionByNameResult *Action
+		// Manager Errors
+		getActionByNameMethodErr error
+	}{
+		"OKCase": {
+			namespace: "billing",
+			name:      "GetInvoice",
+			expectedAction: &Action{
+				ID:        "ACTION-ID",
+				Namespace: "billing",
+				Name:      "GetInvoice",
+			},
+			getActionByNameResult: &Action{
+				ID:        "ACTION-ID",
+				Namespace: "billing",
+				Name:      "GetInvoice",
+			},
+		},
+		"OKCaseBuiltIn": {
+			namespace: IAM_NAMESPACE,
+			name:      "CreateUser",
+			expectedAction: &Action{
+				Namespace:          IAM_NAMESPACE,
+				Name:               "CreateUser",
+				Description:        "Create a user",
+				ResourceUrnPattern: "urn:iws:iam::user/*",
+				BuiltIn:            true,
+			},
+		},
+		"ErrorCaseBuiltInNotFound": {
+			namespace: IAM_NAMESPACE,
+			name:      "Unknown",
+			wantError: &Error{
+				Code:    ACTION_NOT_FOUND,
+				Message: "Action iam:Unknown not found",
+			},
+		},
+		"ErrorCaseActionNotFound": {
+			namespace: "billing",
+			name:      "GetInvoice",
+			wantError: &Error{
+				Code:    ACTION_NOT_FOUND,
+				Message: "Action billing:GetInvoice not found",
+			},
+			getActionByNameMethodErr: &database.Error{
+				Code:    database.ACTION_NOT_FOUND,
+				Message: "Action billing:GetInvoice not found",
+			},
+		},
+		"ErrorCaseDBErr": {
+			namespace: "billing",
+			name:      "GetInvoice",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getActionByNameMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetActionByNameMethod][0] = test.getActionByNameResult
+		testRepo.ArgsOut[GetActionByNameMethod][1] = test.getActionByNameMethodErr
+
+		action, err := testAPI.GetAction(RequestInfo{Identifier: "123456"}, test.namespace, test.name)
+		checkMethodResponse(t, n, test.wantError, err, test.expectedAction, action)
+	}
+}
+
+func TestAuthAPI_RemoveAction(t *testing.T) {
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		namespace   string
+		name        string
+		// Expected results
+		wantError error
+		// Manager Errors
+		getActionByNameMethodErr error
+		removeActionMethodErr    error
+	}{
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			namespace: "billing",
+			name:      "GetInvoice",
+		},
+		"ErrorCaseBuiltIn": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			namespace: IAM_NAMESPACE,
+			name:      "CreateUser",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: namespace iam is built-in and can't be modified",
+			},
+		},
+		"ErrorCaseNotAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			namespace: "billing",
+			name:      "GetInvoice",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to modify the action registry",
+			},
+		},
+		"ErrorCaseActionNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			namespace: "billing",
+			name:      "GetInvoice",
+			wantError: &Error{
+				Code:    ACTION_NOT_FOUND,
+				Message: "Action billing:GetInvoice not found",
+			},
+			getActionByNameMethodErr: &database.Error{
+				Code:    database.ACTION_NOT_FOUND,
+				Message: "Action billing:GetInvoice not found",
+			},
+		},
+		"ErrorCaseRemoveActionDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			namespace: "billing",
+			name:      "GetInvoice",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			removeActionMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetActionByNameMethod][0] = &Action{
+			ID:        "ACTION-ID",
+			Namespace: test.namespace,
+			Name:      test.name,
+		}
+		testRepo.ArgsOut[GetActionByNameMethod][1] = test.getActionByNameMethodErr
+		testRepo.ArgsOut[RemoveActionMethod][0] = test.removeActionMethodErr
+
+		err := testAPI.RemoveAction(test.requestInfo, test.namespace, test.name)
+		checkMethodResponse(t, n, test.wantError, err, nil, nil)
+		if test.wantError == nil && testRepo.ArgsIn[RemoveActionMethod][0] != "ACTION-ID" {
+			t.Errorf("Test %v failed. Received different action id %v", n, testRepo.ArgsIn[RemoveActionMethod][0])
+		}
+	}
+}
+
+func TestAuthAPI_areRegisteredActions(t *testing.T) {
+	testcases := map[string]struct {
+		statements []Statement
+		// Expected results
+		wantError error
+		// Manager Results
+		getActionsFilteredResult []Action
+		// Manager Errors
+		getNamespaceByNameMethodErr error
+	}{
+		"OKCase": {
+			statements: []Statement{
+				{
+					Effect:    "allow",
+					Actions:   []string{"*", "iam:CreateUser", "iam:Get*", "billing:GetInvoice", "billing:Get*"},
+					Resources: []string{"urn:everything:*"},
+				},
+			},
+			getActionsFilteredResult: []Action{
+				{
+					Namespace: "billing",
+					Name:      "GetInvoice",
+				},
+			},
+		},
+		"ErrorCaseUnknownBuiltInAction": {
+			statements: []Statement{
+				{
+					Effect:    "allow",
+					Actions:   []string{"iam:CreateUnicorn"},
+					Resources: []string{"urn:everything:*"},
+				},
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Unknown action: iam:CreateUnicorn",
+			},
+		},
+		"ErrorCaseWildcardWithoutMatches": {
+			statements: []Statement{
+				{
+					Effect:    "allow",
+					Actions:   []string{"billing:Delete*"},
+					Resources: []string{"urn:everything:*"},
+				},
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Unknown action: billing:Delete*",
+			},
+			getActionsFilteredResult: []Action{
+				{
+					Namespace: "billing",
+					Name:      "GetInvoice",
+				},
+			},
+		},
+		"ErrorCaseNamespaceNotRegistered": {
+			statements: []Statement{
+				{
+					Effect:    "allow",
+					Actions:   []string{"billing:GetInvoice"},
+					Resources: []string{"urn:everything:*"},
+				},
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Unknown action: billing:GetInvoice - Namespace billing is not registered",
+			},
+			getNamespaceByNameMethodErr: &database.Error{
+				Code: database.NAMESPACE_NOT_FOUND,
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetNamespaceByNameMethod][0] = &Namespace{Name: "billing"}
+		testRepo.ArgsOut[GetNamespaceByNameMethod][1] = test.getNamespaceByNameMethodErr
+		testRepo.ArgsOut[GetActionsFilteredMethod][0] = test.getActionsFilteredResult
+
+		err := testAPI.areRegisteredActions(test.statements)
+		checkMethodResponse(t, n, test.wantError, err, nil, nil)
+	}
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/satori/go.uuid"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// Actions of the operations without their own IAM action, used only to identify them in the audit log
+const (
+	AUDIT_ACTION_SET_GROUP_MEMBERSHIP_RULE = "iam:SetGroupMembershipRule"
+	AUDIT_ACTION_IMPORT_ORGANIZATION       = "iam:ImportOrganization"
+	AUDIT_ACTION_APPLY_ORGANIZATION        = "iam:ApplyOrganization"
+	AUDIT_ACTION_CREATE_ACCESS_REQUEST     = "iam:CreateAccessRequest"
+	AUDIT_ACTION_REJECT_ACCESS_REQUEST     = "iam:RejectAccessRequest"
+	AUDIT_ACTION_CREATE_NAMESPACE          = "iam:CreateNamespace"
+	AUDIT_ACTION_DELETE_NAMESPACE          = "iam:DeleteNamespace"
+	AUDIT_ACTION_CREATE_ACTION             = "iam:CreateAction"
+	AUDIT_ACTION_DELETE_ACTION             = "iam:DeleteAction"
+	AUDIT_ACTION_CREATE_RESOURCE_TYPE      = "iam:CreateResourceType"
+	AUDIT_ACTION_DELETE_RESOURCE_TYPE      = "iam:DeleteResourceType"
+	AUDIT_ACTION_RESTORE_DELETED_RESOURCE  = "iam:RestoreDeletedResource"
+	AUDIT_ACTION_PURGE_DELETED_RESOURCES   = "iam:PurgeDeletedResources"
+	AUDIT_ACTION_CREATE_WEBHOOK            = "iam:CreateWebhook"
+	AUDIT_ACTION_UPDATE_WEBHOOK            = "iam:UpdateWebhook"
+	AUDIT_ACTION_DELETE_WEBHOOK            = "iam:DeleteWebhook"
+)
+
+// Kinds of the registry entries and webhooks in their audit log URNs, they aren't resources with their own URN
+const (
+	AUDIT_ENTITY_NAMESPACE     = "namespace"
+	AUDIT_ENTITY_ACTION        = "action"
+	AUDIT_ENTITY_RESOURCE_TYPE = "resourcetype"
+	AUDIT_ENTITY_WEBHOOK       = "webhook"
+)
+
+// TYPE DEFINITIONS
+
+// Create, update or delete operation performed by a user. Before and after are the JSON documents of
+// the entity identified by the URN, before is null for creations and after is null for removals
+type AuditEvent struct {
+	ID        string          `json:"id, omitempty"`
+	RequestID string          `json:"requestId, omitempty"`
+	Actor     string          `json:"actor, omitempty"`
+	Action    string          `json:"action, omitempty"`
+	Urn       string          `json:"urn, omitempty"`
+	Before    json.RawMessage `json:"before, omitempty"`
+	After     json.RawMessage `json:"after, omitempty"`
+	CreateAt  time.Time       `json:"createAt, omitempty"`
+}
+
+func (e AuditEvent) String() string {
+	return fmt.Sprintf("[id: %v, requestId: %v, actor: %v, action: %v, urn: %v, createAt: %v]",
+		e.ID, e.RequestID, e.Actor, e.Action, e.Urn, e.CreateAt.Format("2006-01-02 15:04:05 MST"))
+}
+
+// AUDIT API IMPLEMENTATION
+
+func (api AuthAPI) ListAuditEvents(requestInfo RequestInfo, actor string, urn string, action string, filter *Filter) ([]AuditEvent, int, error) {
+	// Only admin can do it, because the audit log spans every organization
+	if !requestInfo.Admin {
+		return nil, 0, &Error{
+			Code:    UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to the audit log", requestInfo.Identifier),
+		}
+	}
+
+	// Validate fields
+	if len(actor) > 0 && !IsValidUserExternalID(actor) {
+		return nil, 0, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: actor %v", actor),
+		}
+	}
+	if err := validateFilter(filter); err != nil {
+		return nil, 0, err
+	}
+
+	// Call repo to retrieve the audit events
+	events, total, err := api.AuditRepo.GetAuditEventsFiltered(actor, urn, action, filter)
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, 0, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	return events, total, nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Store the operation in the audit log with the entity documents before and after it, and queue it for the
+// webhooks subscribed to its action. It runs with the repository of the operation transaction, so an error
+// storing them fails the request and rolls back the operation
+func (api AuthAPI) auditOperation(repo Repo, requestInfo RequestInfo, action string, urn string, before interface{}, after interface{}) error {
+	event := AuditEvent{
+		ID:        uuid.NewV4().String(),
+		RequestID: requestInfo.RequestID,
+		Actor:     requestInfo.Identifier,
+		Action:    action,
+		Urn:       urn,
+		Before:    toAuditDocument(before),
+		After:     toAuditDocument(after),
+		CreateAt:  time.Now().UTC(),
+	}
+
+	if _, err := repo.AddAuditEvent(event); err != nil {
+		return err
+	}
+
+	return enqueueWebhookDeliveries(repo, event)
+}
+
+// URN of a namespace, action or resource type in the audit log, e.g. urn:iws:iam::action/iam/GetUser
+func getRegistryUrn(entity string, namespace string, name string) string {
+	if namespace == "" {
+		return CreateUrn("", entity, "/", name)
+	}
+	return CreateUrn("", entity, "/"+namespace+"/", name)
+}
+
+// Marshal entity to JSON, nil entities are null documents
+func toAuditDocument(entity interface{}) json.RawMessage {
+	if entity == nil {
+		return nil
+	}
+	document, err := json.Marshal(entity)
+	if err != nil {
+		return nil
+	}
+	return document
+}
@@ -0,0 +1,269 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestAuthAPI_ListAuditEvents(t *testing.T) {
+	now := time.Now().UTC()
+	yesterday := now.Add(-24 * time.Hour)
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		actor       string
+		urn         string
+		action      string
+		filter      *Filter
+		// Expected results
+		expectedResponse []AuditEvent
+		expectedTotal    int
+		wantError        error
+		// Manager Results
+		getAuditEventsFilteredMethodResult []AuditEvent
+		getAuditEventsFilteredMethodTotal  int
+		// Manager Errors
+		getAuditEventsFilteredMethodErr error
+	}{
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			actor:  "123456",
+			urn:    CreateUrn("example", RESOURCE_GROUP, "/path/", "group"),
+			action: GROUP_ACTION_CREATE_GROUP,
+			filter: &Filter{
+				CreatedAfter: &yesterday,
+			},
+			expectedResponse: []AuditEvent{
+				{
+					ID:        "EVENT-ID",
+					RequestID: "REQUEST-ID",
+					Actor:     "123456",
+					Action:    GROUP_ACTION_CREATE_GROUP,
+					Urn:       CreateUrn("example", RESOURCE_GROUP, "/path/", "group"),
+					After:     json.RawMessage(`{"name":"group"}`),
+					CreateAt:  now,
+				},
+			},
+			expectedTotal: 1,
+			getAuditEventsFilteredMethodResult: []AuditEvent{
+				{
+					ID:        "EVENT-ID",
+					RequestID: "REQUEST-ID",
+					Actor:     "123456",
+					Action:    GROUP_ACTION_CREATE_GROUP,
+					Urn:       CreateUrn("example", RESOURCE_GROUP, "/path/", "group"),
+					After:     json.RawMessage(`{"name":"group"}`),
+					CreateAt:  now,
+				},
+			},
+			getAuditEventsFilteredMethodTotal: 1,
+		},
+		"ErrorCaseNotAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			filter: &Filter{},
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to access to the audit log",
+			},
+		},
+		"ErrorCaseInvalidActor": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			actor:  "*%~#@|",
+			filter: &Filter{},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: actor *%~#@|",
+			},
+		},
+		"ErrorCaseInvalidFilter": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter: &Filter{
+				Limit: -1,
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Limit -1, max limit allowed: 1000",
+			},
+		},
+		"ErrorCaseInternalError": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter: &Filter{},
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getAuditEventsFilteredMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetAuditEventsFilteredMethod][0] = testcase.getAuditEventsFilteredMethodResult
+		testRepo.ArgsOut[GetAuditEventsFilteredMethod][1] = testcase.getAuditEventsFilteredMethodTotal
+		testRepo.ArgsOut[GetAuditEventsFilteredMethod][2] = testcase.getAuditEventsFilteredMethodErr
+
+		events, total, err := testAPI.ListAuditEvents(testcase.requestInfo, testcase.actor, testcase.urn, testcase.action, testcase.filter)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, events)
+		if testcase.wantError == nil && total != testcase.expectedTotal {
+			t.Errorf("Test %v failed. Received different total (wanted:%v / received:%v)", x, testcase.expectedTotal, total)
+		}
+	}
+}
+
+func TestAuthAPI_auditOperation(t *testing.T) {
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		action      string
+		urn         string
+		before      interface{}
+		after       interface{}
+		// Expected results
+		expectedEvent AuditEvent
+		wantError     error
+		// Manager Errors
+		addAuditEventMethodErr error
+	}{
+		"OKCaseCreation": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				RequestID:  "REQUEST-ID",
+			},
+			action: USER_ACTION_CREATE_USER,
+			urn:    CreateUrn("", RESOURCE_USER, "/path/", "user"),
+			after: &User{
+				ExternalID: "user",
+			},
+			expectedEvent: AuditEvent{
+				RequestID: "REQUEST-ID",
+				Actor:     "123456",
+				Action:    USER_ACTION_CREATE_USER,
+				Urn:       CreateUrn("", RESOURCE_USER, "/path/", "user"),
+			},
+		},
+		"OKCaseRemoval": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				RequestID:  "REQUEST-ID",
+			},
+			action: GROUP_ACTION_REMOVE_MEMBER,
+			urn:    CreateUrn("example", RESOURCE_GROUP, "/path/", "group"),
+			before: &User{
+				ExternalID: "user",
+			},
+			expectedEvent: AuditEvent{
+				RequestID: "REQUEST-ID",
+				Actor:     "123456",
+				Action:    GROUP_ACTION_REMOVE_MEMBER,
+				Urn:       CreateUrn("example", RESOURCE_GROUP, "/path/", "group"),
+			},
+		},
+		"ErrorCaseStoreError": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				RequestID:  "REQUEST-ID",
+			},
+			action: POLICY_ACTION_DELETE_POLICY,
+			urn:    CreateUrn("example", RESOURCE_POLICY, "/path/", "policy"),
+			before: &Policy{
+				Name: "policy",
+			},
+			expectedEvent: AuditEvent{
+				RequestID: "REQUEST-ID",
+				Actor:     "123456",
+				Action:    POLICY_ACTION_DELETE_POLICY,
+				Urn:       CreateUrn("example", RESOURCE_POLICY, "/path/", "policy"),
+			},
+			wantError: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+			addAuditEventMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[AddAuditEventMethod][1] = testcase.addAuditEventMethodErr
+
+		err := testAPI.auditOperation(testRepo, testcase.requestInfo, testcase.action, testcase.urn, testcase.before, testcase.after)
+		if diff := pretty.Compare(err, testcase.wantError); diff != "" {
+			t.Errorf("Test %v failed. Received different errors (received/wanted) %v", x, diff)
+			continue
+		}
+
+		event, ok := testRepo.ArgsIn[AddAuditEventMethod][0].(AuditEvent)
+		if !ok {
+			t.Errorf("Test %v failed. Audit event wasn't stored", x)
+			continue
+		}
+		if event.ID == "" || event.CreateAt.IsZero() {
+			t.Errorf("Test %v failed. Audit event without id or creation date %v", x, event)
+			continue
+		}
+		// Check documents
+		if diff := pretty.Compare(event.Before, toAuditDocument(testcase.before)); diff != "" {
+			t.Errorf("Test %v failed. Received different before documents (received/wanted) %v", x, diff)
+			continue
+		}
+		if diff := pretty.Compare(event.After, toAuditDocument(testcase.after)); diff != "" {
+			t.Errorf("Test %v failed. Received different after documents (received/wanted) %v", x, diff)
+			continue
+		}
+		event.ID = ""
+		event.CreateAt = time.Time{}
+		event.Before = nil
+		event.After = nil
+		if diff := pretty.Compare(event, testcase.expectedEvent); diff != "" {
+			t.Errorf("Test %v failed. Received different audit events (received/wanted) %v", x, diff)
+			continue
+		}
+	}
+}
+
+func TestAuditEvent_MarshalJSON(t *testing.T) {
+	event := AuditEvent{
+		ID:       "EVENT-ID",
+		CreateAt: time.Date(2015, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fields["createAt"] != "2015-01-01T12:00:00Z" {
+		t.Errorf("Test failed. Received different createAt field in %v", string(data))
+	}
+}
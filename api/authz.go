@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/tecsisa/foulkon/database"
 )
@@ -14,6 +15,10 @@ type RequestInfo struct {
 	Identifier string
 	Admin      bool
 	RequestID  string
+	// Entity tags the resource must match to be updated or removed, empty for unconditional requests
+	IfMatch string
+	// Rewrite policy statements that reference the old URN of a renamed or moved resource
+	RewriteReferences bool
 }
 
 type EffectRestriction struct {
@@ -29,13 +34,18 @@ type Restrictions struct {
 }
 
 type ExternalResource struct {
-	Urn string `json:"urn, omitempty"`
+	Urn  string            `json:"urn, omitempty"`
+	Tags map[string]string `json:"tags, omitempty"`
 }
 
 func (e ExternalResource) GetUrn() string {
 	return e.Urn
 }
 
+func (e ExternalResource) GetTags() map[string]string {
+	return e.Tags
+}
+
 // AUTHZ API IMPLEMENTATION
 
 // Return authorized users for specified resource+action
@@ -89,8 +99,28 @@ func (api AuthAPI) GetAuthorizedPolicies(requestInfo RequestInfo, resourceUrn st
 	return policiesFiltered, nil
 }
 
-// Get the resources where the specified user has the action granted
-func (api AuthAPI) GetAuthorizedExternalResources(requestInfo RequestInfo, action string, resources []string) ([]string, error) {
+// Return authorized organizations for specified user combined with resource+action
+func (api AuthAPI) GetAuthorizedOrganizations(requestInfo RequestInfo, resourceUrn string, action string,
+	organizations []Organization) ([]Organization, error) {
+	resourcesToAuthorize := []Resource{}
+	for _, organization := range organizations {
+		resourcesToAuthorize = append(resourcesToAuthorize, organization)
+	}
+	resources, err := api.getAuthorizedResources(requestInfo, resourceUrn, action, resourcesToAuthorize)
+	if err != nil {
+		return nil, err
+	}
+	organizationsFiltered := []Organization{}
+	for _, res := range resources {
+		organizationsFiltered = append(organizationsFiltered, res.(Organization))
+	}
+	return organizationsFiltered, nil
+}
+
+// Get the resources where the specified user has the action granted. Resource tags (optional parameter)
+// apply to all resources and they are compared with user tags in statement conditions
+func (api AuthAPI) GetAuthorizedExternalResources(requestInfo RequestInfo, action string, resources []string,
+	resourceTags map[string]string) ([]string, error) {
 	// Validate parameters
 	if err := AreValidActions([]string{action}); err != nil {
 		// Transform to API error
@@ -106,6 +136,9 @@ func (api AuthAPI) GetAuthorizedExternalResources(requestInfo RequestInfo, actio
 			Message: "Invalid parameter Resources %v. Resources can't be empty",
 		}
 	}
+	if err := AreValidTags(resourceTags); err != nil {
+		return nil, err
+	}
 	externalResources := []Resource{}
 	for _, res := range resources {
 		if !isFullUrn(res) {
@@ -122,7 +155,7 @@ func (api AuthAPI) GetAuthorizedExternalResources(requestInfo RequestInfo, actio
 				Message: apiError.Message,
 			}
 		}
-		externalResources = append(externalResources, ExternalResource{Urn: res})
+		externalResources = append(externalResources, ExternalResource{Urn: res, Tags: resourceTags})
 	}
 	if strings.Contains(action, "*") {
 		return nil, &Error{
@@ -130,6 +163,11 @@ func (api AuthAPI) GetAuthorizedExternalResources(requestInfo RequestInfo, actio
 			Message: fmt.Sprintf("Invalid parameter action %v. Action parameter can't be a prefix", action),
 		}
 	}
+	if api.ValidateResources {
+		if err := api.checkRegisteredResources(resources); err != nil {
+			return nil, err
+		}
+	}
 
 	allowedUrns, err := api.getAuthorizedResources(requestInfo, "urn:*", action, externalResources)
 	if err != nil {
@@ -146,18 +184,44 @@ func (api AuthAPI) GetAuthorizedExternalResources(requestInfo RequestInfo, actio
 
 // PRIVATE HELPER METHODS
 
-// This method retrieves filtered resources where the authenticated user has permissions
+// This method retrieves filtered resources where the authenticated user has permissions, recording the decision
 func (api AuthAPI) getAuthorizedResources(requestInfo RequestInfo, resourceUrn string, action string, resources []Resource) ([]Resource, error) {
+	return api.getAuthorizedResourcesWithGrants(requestInfo, resourceUrn, action, resources, nil)
+}
+
+// Return authorized resources with the statements of the user extended by the grants of its ownerships.
+// Grants are evaluated with the rest of statements, so deny statements apply to them and suspended users lose them
+func (api AuthAPI) getAuthorizedResourcesWithGrants(requestInfo RequestInfo, resourceUrn string, action string, resources []Resource,
+	grants []Statement) ([]Resource, error) {
+	start := time.Now()
+	allowed, err := api.evaluateAuthorizedResources(requestInfo, resourceUrn, action, resources, grants)
+	api.logDecision(requestInfo, resourceUrn, action, resources, allowed, err, start)
+	return allowed, err
+}
+
+func (api AuthAPI) evaluateAuthorizedResources(requestInfo RequestInfo, resourceUrn string, action string, resources []Resource,
+	grants []Statement) ([]Resource, error) {
 	// If user is an admin return all resources without restriction
 	if requestInfo.Admin {
 		return resources, nil
 	}
 
-	// Check authorization for this user
-	restrictions, err := api.getRestrictions(requestInfo.Identifier, action, resourceUrn)
+	// Retrieve statements for this action attached to this user
+	user, statements, err := api.getStatementsByUser(requestInfo.Identifier, action)
 	if err != nil {
 		return nil, err
 	}
+	if user.Status != USER_STATUS_SUSPENDED {
+		for _, grant := range grants {
+			if isActionContained(action, grant.Actions) {
+				statements = append(statements, grant)
+			}
+		}
+	}
+
+	// Check authorization for this user. Conditions depend on each resource, so they are left
+	// out in the most permissive way to know if there is any chance of access to this urn resource
+	restrictions := getRestrictions(getStatementsWithoutConditions(statements), resourceUrn, isFullUrn(resourceUrn))
 
 	api.Logger.Debugf("Restrictions: %v", *restrictions)
 
@@ -170,13 +234,43 @@ func (api AuthAPI) getAuthorizedResources(requestInfo RequestInfo, resourceUrn s
 	}
 
 	// Filter resources
-	resourcesFiltered := filterResources(resources, restrictions)
+	if !hasConditions(statements) {
+		return filterResources(resources, restrictions), nil
+	}
+
+	// Statement conditions compare user tags with the tags of each resource, so restrictions are
+	// retrieved per resource
+	resourcesFiltered := []Resource{}
+	for _, r := range resources {
+		resourceStatements := getStatementsByConditions(statements, user.Tags, r.GetTags())
+		if isAllowedResource(r, *getRestrictions(resourceStatements, r.GetUrn(), true)) {
+			resourcesFiltered = append(resourcesFiltered, r)
+		}
+	}
 
 	return resourcesFiltered, nil
 }
 
-// Get restrictions for this action and full resource or prefix resource, attached to this authenticated user
-func (api AuthAPI) getRestrictions(externalID string, action string, resource string) (*Restrictions, error) {
+// Get the authenticated user with the statements for this action attached to it through its groups
+func (api AuthAPI) getStatementsByUser(externalID string, action string) (*User, []Statement, error) {
+	user, policies, err := api.getPoliciesByUser(externalID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Retrieve valid statements
+	return user, getStatementsByRequestedAction(policies, action), nil
+}
+
+// Get the authenticated user with the policies attached to it through its groups. Inside a batch
+// they are loaded once and reused by the rest of its operations
+func (api AuthAPI) getPoliciesByUser(externalID string) (*User, []Policy, error) {
+	if api.batchPermissions != nil {
+		if user, ok := api.batchPermissions.users[externalID]; ok {
+			return user, api.batchPermissions.policies[externalID], nil
+		}
+	}
+
 	// Get user if exists
 	user, err := api.UserRepo.GetUserByExternalID(externalID)
 
@@ -186,40 +280,41 @@ func (api AuthAPI) getRestrictions(externalID string, action string, resource st
 		dbError := err.(*database.Error)
 		switch dbError.Code {
 		case database.USER_NOT_FOUND:
-			return nil, &Error{
+			return nil, nil, &Error{
 				Code:    UNAUTHORIZED_RESOURCES_ERROR,
 				Message: fmt.Sprintf("Authenticated user with externalId %v not found. Unable to retrieve permissions.", externalID),
 			}
 		default:
-			return nil, &Error{
+			return nil, nil, &Error{
 				Code:    UNKNOWN_API_ERROR,
 				Message: dbError.Message,
 			}
 		}
 	}
 
-	groups, err := api.getGroupsByUser(user.ID)
-	if err != nil {
-		return nil, err
-	}
+	// Every action is denied to suspended users
+	var policies []Policy
+	if user.Status != USER_STATUS_SUSPENDED {
+		groups, err := api.getGroupsByUser(user.ID)
+		if err != nil {
+			return nil, nil, err
+		}
 
-	policies, err := api.getPoliciesByGroups(groups)
-	if err != nil {
-		return nil, err
+		policies, err = api.getPoliciesByGroups(groups)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
-	// Retrieve valid statements
-	statements := getStatementsByRequestedAction(policies, action)
-
-	// Retrieve restrictions
-	var authResources *Restrictions
-	authResources = getRestrictions(statements, resource, isFullUrn(resource))
-
-	return authResources, nil
+	if api.batchPermissions != nil {
+		api.batchPermissions.users[externalID] = user
+		api.batchPermissions.policies[externalID] = policies
+	}
+	return user, policies, nil
 }
 
 func (api AuthAPI) getGroupsByUser(userID string) ([]Group, error) {
-	groups, err := api.UserRepo.GetGroupsByUserID(userID)
+	groups, _, err := api.UserRepo.GetGroupsByUserID(userID, &Filter{})
 	if err != nil {
 		//Transform to DB error
 		dbError := err.(*database.Error)
@@ -243,8 +338,8 @@ func (api AuthAPI) getPoliciesByGroups(groups []Group) ([]Policy, error) {
 
 	// Retrieve per each group its attached policies
 	for _, group := range groups {
-		// Retrieve policies for this group
-		policiesAttached, err := api.GroupRepo.GetAttachedPolicies(group.ID)
+		// Retrieve policies for this group, only those in their activation window
+		policiesAttached, _, err := api.GroupRepo.GetAttachedPolicies(group.ID, &Filter{})
 		if err != nil {
 			//Transform to DB error
 			dbError := err.(*database.Error)
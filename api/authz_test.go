@@ -276,6 +276,8 @@ func TestGetAuthorizedExternalResources(t *testing.T) {
 		requestInfo RequestInfo
 		// Resource urns that user wants to access
 		resourceUrns []string
+		// Tags of the resources
+		resourceTags map[string]string
 		// Action to do
 		action string
 		// Expected allowed resources
@@ -459,6 +461,185 @@ func TestGetAuthorizedExternalResources(t *testing.T) {
 				},
 			},
 		},
+		"OktestCaseWithConditions": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			resourceUrns: []string{
+				"urn:ews:product:instance:resource/path1/resource",
+				"urn:ews:product:instance:resource/path2/resource",
+			},
+			resourceTags: map[string]string{"team": "payments", "env": "prod"},
+			action:       "product:DoAction",
+			expectedResources: []string{
+				"urn:ews:product:instance:resource/path1/resource",
+			},
+			getUserByExternalIDResult: &User{
+				ID:   "123456",
+				Urn:  CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+				Tags: map[string]string{"team": "payments"},
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:  "GROUP-USER-ID",
+					Urn: CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesResult: []Policy{
+				{
+					ID:  "POLICY-USER-ID",
+					Urn: CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+					Statements: &[]Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								"product:DoAction",
+							},
+							Resources: []string{
+								"urn:ews:product:instance:resource/*",
+							},
+							Conditions: []string{
+								"team == resource.team",
+							},
+						},
+						{
+							Effect: "deny",
+							Actions: []string{
+								"product:DoAction",
+							},
+							Resources: []string{
+								"urn:ews:product:instance:resource/path2*",
+							},
+							Conditions: []string{
+								"resource.env == 'prod'",
+							},
+						},
+					},
+				},
+			},
+		},
+		"OktestCaseConditionsNotMet": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			resourceUrns: []string{
+				"urn:ews:product:instance:resource/path1/resource",
+			},
+			resourceTags:      map[string]string{"team": "billing"},
+			action:            "product:DoAction",
+			expectedResources: []string{},
+			getUserByExternalIDResult: &User{
+				ID:   "123456",
+				Urn:  CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+				Tags: map[string]string{"team": "payments"},
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:  "GROUP-USER-ID",
+					Urn: CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesResult: []Policy{
+				{
+					ID:  "POLICY-USER-ID",
+					Urn: CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+					Statements: &[]Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								"product:DoAction",
+							},
+							Resources: []string{
+								"urn:ews:product:instance:resource/*",
+							},
+							Conditions: []string{
+								"team == resource.team",
+							},
+						},
+						{
+							Effect: "deny",
+							Actions: []string{
+								"product:DoAction",
+							},
+							Resources: []string{
+								"urn:ews:product:instance:resource/path2*",
+							},
+							Conditions: []string{
+								"resource.env == 'prod'",
+							},
+						},
+					},
+				},
+			},
+		},
+		"OktestCaseDenyWithMissingTag": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			resourceUrns: []string{
+				"urn:ews:product:instance:resource/path1/resource",
+			},
+			resourceTags:      map[string]string{"team": "payments"},
+			action:            "product:DoAction",
+			expectedResources: []string{},
+			getUserByExternalIDResult: &User{
+				ID:   "123456",
+				Urn:  CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+				Tags: map[string]string{"team": "payments"},
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:  "GROUP-USER-ID",
+					Urn: CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesResult: []Policy{
+				{
+					ID:  "POLICY-USER-ID",
+					Urn: CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+					Statements: &[]Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								"product:DoAction",
+							},
+							Resources: []string{
+								"urn:ews:product:instance:resource/*",
+							},
+						},
+						{
+							Effect: "deny",
+							Actions: []string{
+								"product:DoAction",
+							},
+							Resources: []string{
+								"urn:ews:product:instance:resource/*",
+							},
+							Conditions: []string{
+								"resource.env != 'dev'",
+							},
+						},
+					},
+				},
+			},
+		},
+		"ErrortestCaseInvalidResourceTags": {
+			requestInfo: RequestInfo{
+				Admin: true,
+			},
+			resourceUrns: []string{
+				"urn:ews:product:instance:resource/path1/resource",
+			},
+			resourceTags: map[string]string{"team.name": "payments"},
+			action:       "product:DoAction",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: tag key team.name",
+			},
+		},
 		"OktestCaseWithRestrictions": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
@@ -529,12 +710,12 @@ func TestGetAuthorizedExternalResources(t *testing.T) {
 		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = test.getUserByExternalIDError
 
 		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = test.getGroupsByUserIDResult
-		testRepo.ArgsOut[GetGroupsByUserIDMethod][1] = test.getGroupsByUserIDError
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][2] = test.getGroupsByUserIDError
 
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = test.getAttachedPoliciesResult
-		testRepo.ArgsOut[GetAttachedPoliciesMethod][1] = test.getAttachedPoliciesError
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][2] = test.getAttachedPoliciesError
 
-		resources, err := testAPI.GetAuthorizedExternalResources(test.requestInfo, test.action, test.resourceUrns)
+		resources, err := testAPI.GetAuthorizedExternalResources(test.requestInfo, test.action, test.resourceUrns, test.resourceTags)
 		checkMethodResponse(t, n, test.wantError, err, test.expectedResources, resources)
 		if !test.requestInfo.Admin {
 			// Check received authenticated user in method GetUserByExternalID
@@ -759,10 +940,10 @@ func TestGetAuthorizedResources(t *testing.T) {
 		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = test.getUserByExternalIDError
 
 		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = test.getGroupsByUserIDResult
-		testRepo.ArgsOut[GetGroupsByUserIDMethod][1] = test.getGroupsByUserIDError
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][2] = test.getGroupsByUserIDError
 
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = test.getAttachedPoliciesResult
-		testRepo.ArgsOut[GetAttachedPoliciesMethod][1] = test.getAttachedPoliciesError
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][2] = test.getAttachedPoliciesError
 
 		authorizedResources, err := testAPI.getAuthorizedResources(test.requestInfo, test.resourceUrn, test.action, test.resourcesToAuthorize)
 		checkMethodResponse(t, n, test.wantError, err, test.resourcesAuthorized, authorizedResources)
@@ -1004,12 +1185,16 @@ func TestGetRestrictions(t *testing.T) {
 		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = test.getUserByExternalIDError
 
 		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = test.getGroupsByUserIDResult
-		testRepo.ArgsOut[GetGroupsByUserIDMethod][1] = test.getGroupsByUserIDError
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][2] = test.getGroupsByUserIDError
 
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = test.getAttachedPoliciesResult
-		testRepo.ArgsOut[GetAttachedPoliciesMethod][1] = test.getAttachedPoliciesError
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][2] = test.getAttachedPoliciesError
 
-		restrictions, err := testAPI.getRestrictions(test.authUserID, test.action, test.resourceUrn)
+		var restrictions *Restrictions
+		_, statements, err := testAPI.getStatementsByUser(test.authUserID, test.action)
+		if err == nil {
+			restrictions = getRestrictions(getStatementsWithoutConditions(statements), test.resourceUrn, isFullUrn(test.resourceUrn))
+		}
 		checkMethodResponse(t, n, test.wantError, err, test.expectedRestrictions, restrictions)
 		if test.wantError == nil && testRepo.ArgsIn[GetUserByExternalIDMethod][0] != test.authUserID {
 			t.Errorf("Test %v failed. Received different user identifiers (wanted:%v / received:%v)",
@@ -1080,7 +1265,7 @@ func TestGetGroupsByUser(t *testing.T) {
 		testAPI := makeTestAPI(testRepo)
 
 		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = test.getGroupsByUserIDResult
-		testRepo.ArgsOut[GetGroupsByUserIDMethod][1] = test.getGroupsByUserIDError
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][2] = test.getGroupsByUserIDError
 
 		groups, err := testAPI.getGroupsByUser(test.userID)
 		checkMethodResponse(t, n, test.wantError, err, test.expectedGroups, groups)
@@ -1165,7 +1350,7 @@ func TestGetPoliciesByGroups(t *testing.T) {
 		testAPI := makeTestAPI(testRepo)
 
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = test.getAttachedPoliciesResult
-		testRepo.ArgsOut[GetAttachedPoliciesMethod][1] = test.getAttachedPoliciesError
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][2] = test.getAttachedPoliciesError
 
 		policies, err := testAPI.getPoliciesByGroups(test.groups)
 		checkMethodResponse(t, n, test.wantError, err, test.expectedPolicies, policies)
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/tecsisa/foulkon/database"
+)
+
+// TYPE DEFINITIONS
+
+const (
+	// Batch operations
+	BATCH_OPERATION_ADD_USER      = "addUser"
+	BATCH_OPERATION_REMOVE_USER   = "removeUser"
+	BATCH_OPERATION_ADD_GROUP     = "addGroup"
+	BATCH_OPERATION_ADD_MEMBER    = "addMember"
+	BATCH_OPERATION_REMOVE_MEMBER = "removeMember"
+	BATCH_OPERATION_ATTACH_POLICY = "attachPolicy"
+	BATCH_OPERATION_DETACH_POLICY = "detachPolicy"
+
+	// Batch operation status
+	BATCH_STATUS_OK          = "ok"
+	BATCH_STATUS_FAILED      = "failed"
+	BATCH_STATUS_ROLLED_BACK = "rolledBack"
+	BATCH_STATUS_SKIPPED     = "skipped"
+
+	// Maximum number of operations of a batch
+	MAX_BATCH_SIZE = 1000
+)
+
+// Operation of a batch. Fields used depend on the operation:
+// addUser and removeUser use externalId and path, addGroup uses org, groupName and path,
+// addMember and removeMember use externalId, org and groupName, and attachPolicy and
+// detachPolicy use org, groupName and policyName
+type BatchOperation struct {
+	Operation  string `json:"operation, omitempty"`
+	ExternalID string `json:"externalId, omitempty"`
+	Org        string `json:"org, omitempty"`
+	GroupName  string `json:"groupName, omitempty"`
+	PolicyName string `json:"policyName, omitempty"`
+	Path       string `json:"path, omitempty"`
+}
+
+// Result of a batch operation, with the error that made it fail
+type BatchResult struct {
+	Operation string `json:"operation, omitempty"`
+	Status    string `json:"status, omitempty"`
+	Error     *Error `json:"error, omitempty"`
+}
+
+// Users and their attached policies by external id, loaded once per batch. Operations of a batch are
+// authorized with the permissions the authenticated user had when the batch started
+type permissionCache struct {
+	users    map[string]*User
+	policies map[string][]Policy
+}
+
+// BATCH API IMPLEMENTATION
+
+func (api AuthAPI) Batch(requestInfo RequestInfo, operations []BatchOperation, atomic bool) ([]BatchResult, error) {
+	// Validate fields
+	if len(operations) < 1 || len(operations) > MAX_BATCH_SIZE {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: operations size %v, it must be between 1 and %v", len(operations), MAX_BATCH_SIZE),
+		}
+	}
+	for _, operation := range operations {
+		if !isValidBatchOperation(operation.Operation) {
+			return nil, &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: operation %v", operation.Operation),
+			}
+		}
+	}
+
+	// Permissions of the authenticated user are retrieved only by the first operation
+	api.batchPermissions = &permissionCache{
+		users:    map[string]*User{},
+		policies: map[string][]Policy{},
+	}
+
+	// Without atomic mode every operation is executed on its own
+	if !atomic {
+		results := make([]BatchResult, len(operations))
+		for i, operation := range operations {
+			results[i] = api.runBatchOperation(requestInfo, operation)
+		}
+		LogOperation(api.Logger, requestInfo, fmt.Sprintf("Batch executed with %v operations", len(operations)))
+		return results, nil
+	}
+
+	// Atomic mode runs operations inside one transaction, stopping at the first failure
+	results := make([]BatchResult, len(operations))
+	err := api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		transactionAPI := api
+		transactionAPI.UserRepo = repo
+		transactionAPI.GroupRepo = repo
+		transactionAPI.PolicyRepo = repo
+		transactionAPI.OrganizationRepo = repo
+		transactionAPI.AccessRequestRepo = repo
+		transactionAPI.ActionRepo = repo
+		transactionAPI.ResourceTypeRepo = repo
+		transactionAPI.TagRepo = repo
+		// Audit events of the operations and their webhook deliveries are rolled back with them
+		transactionAPI.AuditRepo = repo
+		transactionAPI.WebhookRepo = repo
+		// Operations with their own transaction join this one
+		transactionAPI.TransactionRepo = repo
+
+		for i, operation := range operations {
+			results[i] = transactionAPI.runBatchOperation(requestInfo, operation)
+			if results[i].Error != nil {
+				// Mark operations already executed as rolled back and the rest as skipped
+				for j := range results {
+					switch {
+					case j < i:
+						results[j].Status = BATCH_STATUS_ROLLED_BACK
+					case j > i:
+						results[j] = BatchResult{
+							Operation: operations[j].Operation,
+							Status:    BATCH_STATUS_SKIPPED,
+						}
+					}
+				}
+				return results[i].Error
+			}
+		}
+		return nil
+	})
+
+	// Error handling
+	if err != nil {
+		switch err.(type) {
+		case *Error: // Operation failed, transaction was rolled back
+			LogOperation(api.Logger, requestInfo, fmt.Sprintf("Atomic batch with %v operations rolled back", len(operations)))
+			return results, nil
+		default: // Unexpected DB error
+			//Transform to DB error
+			dbError := err.(*database.Error)
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Atomic batch executed with %v operations", len(operations)))
+	return results, nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Execute a batch operation with the API method that implements it
+func (api AuthAPI) runBatchOperation(requestInfo RequestInfo, operation BatchOperation) BatchResult {
+	var err error
+	switch operation.Operation {
+	case BATCH_OPERATION_ADD_USER:
+		_, err = api.AddUser(requestInfo, operation.ExternalID, operation.Path, UserProfile{})
+	case BATCH_OPERATION_REMOVE_USER:
+		err = api.RemoveUser(requestInfo, operation.ExternalID)
+	case BATCH_OPERATION_ADD_GROUP:
+		_, err = api.AddGroup(requestInfo, operation.Org, operation.GroupName, operation.Path)
+	case BATCH_OPERATION_ADD_MEMBER:
+		err = api.AddMember(requestInfo, operation.ExternalID, operation.GroupName, operation.Org)
+	case BATCH_OPERATION_REMOVE_MEMBER:
+		err = api.RemoveMember(requestInfo, operation.ExternalID, operation.GroupName, operation.Org)
+	case BATCH_OPERATION_ATTACH_POLICY:
+		err = api.AttachPolicyToGroup(requestInfo, operation.Org, operation.GroupName, operation.PolicyName, nil, nil)
+	case BATCH_OPERATION_DETACH_POLICY:
+		err = api.DetachPolicyToGroup(requestInfo, operation.Org, operation.GroupName, operation.PolicyName)
+	}
+
+	if err != nil {
+		return BatchResult{
+			Operation: operation.Operation,
+			Status:    BATCH_STATUS_FAILED,
+			Error:     err.(*Error),
+		}
+	}
+
+	return BatchResult{
+		Operation: operation.Operation,
+		Status:    BATCH_STATUS_OK,
+	}
+}
+
+func isValidBatchOperation(operation string) bool {
+	switch operation {
+	case BATCH_OPERATION_ADD_USER, BATCH_OPERATION_REMOVE_USER, BATCH_OPERATION_ADD_GROUP,
+		BATCH_OPERATION_ADD_MEMBER, BATCH_OPERATION_REMOVE_MEMBER,
+		BATCH_OPERATION_ATTACH_POLICY, BATCH_OPERATION_DETACH_POLICY:
+		return true
+	default:
+		return false
+	}
+}
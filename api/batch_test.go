@@ -0,0 +1,301 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestAuthAPI_Batch(t *testing.T) {
+	// Users "existing1" and "existing2" already exist
+	getUserByExternalID := func(id string) (*User, error) {
+		if id == "existing1" || id == "existing2" {
+			return &User{
+				ID:         id,
+				ExternalID: id,
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", id),
+			}, nil
+		}
+		return nil, &database.Error{
+			Code: database.USER_NOT_FOUND,
+		}
+	}
+	tooManyOperations := make([]BatchOperation, MAX_BATCH_SIZE+1)
+	for i := range tooManyOperations {
+		tooManyOperations[i] = BatchOperation{
+			Operation:  BATCH_OPERATION_ADD_USER,
+			ExternalID: "new",
+			Path:       "/path/",
+		}
+	}
+
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		operations  []BatchOperation
+		atomic      bool
+		// Expected result
+		expectedResults []BatchResult
+		wantError       error
+		// Manager Errors
+		runInTransactionErr error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			operations: []BatchOperation{
+				{
+					Operation:  BATCH_OPERATION_ADD_USER,
+					ExternalID: "existing1",
+					Path:       "/path/",
+				},
+				{
+					Operation:  BATCH_OPERATION_ADD_USER,
+					ExternalID: "new",
+					Path:       "/path/",
+				},
+			},
+			expectedResults: []BatchResult{
+				{
+					Operation: BATCH_OPERATION_ADD_USER,
+					Status:    BATCH_STATUS_FAILED,
+					Error: &Error{
+						Code:    USER_ALREADY_EXIST,
+						Message: "Unable to create user, user with externalId existing1 already exist",
+					},
+				},
+				{
+					Operation: BATCH_OPERATION_ADD_USER,
+					Status:    BATCH_STATUS_OK,
+				},
+			},
+		},
+		"OkCaseAtomic": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			operations: []BatchOperation{
+				{
+					Operation:  BATCH_OPERATION_ADD_USER,
+					ExternalID: "new",
+					Path:       "/path/",
+				},
+				{
+					Operation:  BATCH_OPERATION_REMOVE_USER,
+					ExternalID: "existing1",
+				},
+			},
+			atomic: true,
+			expectedResults: []BatchResult{
+				{
+					Operation: BATCH_OPERATION_ADD_USER,
+					Status:    BATCH_STATUS_OK,
+				},
+				{
+					Operation: BATCH_OPERATION_REMOVE_USER,
+					Status:    BATCH_STATUS_OK,
+				},
+			},
+		},
+		"OkCaseAtomicRolledBack": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			operations: []BatchOperation{
+				{
+					Operation:  BATCH_OPERATION_ADD_USER,
+					ExternalID: "new",
+					Path:       "/path/",
+				},
+				{
+					Operation:  BATCH_OPERATION_ADD_USER,
+					ExternalID: "existing2",
+					Path:       "/path/",
+				},
+				{
+					Operation:  BATCH_OPERATION_REMOVE_USER,
+					ExternalID: "existing1",
+				},
+			},
+			atomic: true,
+			expectedResults: []BatchResult{
+				{
+					Operation: BATCH_OPERATION_ADD_USER,
+					Status:    BATCH_STATUS_ROLLED_BACK,
+				},
+				{
+					Operation: BATCH_OPERATION_ADD_USER,
+					Status:    BATCH_STATUS_FAILED,
+					Error: &Error{
+						Code:    USER_ALREADY_EXIST,
+						Message: "Unable to create user, user with externalId existing2 already exist",
+					},
+				},
+				{
+					Operation: BATCH_OPERATION_REMOVE_USER,
+					Status:    BATCH_STATUS_SKIPPED,
+				},
+			},
+		},
+		"ErrorCaseEmptyOperations": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: operations size 0, it must be between 1 and 1000",
+			},
+		},
+		"ErrorCaseTooManyOperations": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			operations: tooManyOperations,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: operations size 1001, it must be between 1 and 1000",
+			},
+		},
+		"ErrorCaseInvalidOperation": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			operations: []BatchOperation{
+				{
+					Operation:  BATCH_OPERATION_ADD_USER,
+					ExternalID: "new",
+					Path:       "/path/",
+				},
+				{
+					Operation: "addEverything",
+				},
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: operation addEverything",
+			},
+		},
+		"ErrorCaseTransactionError": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			operations: []BatchOperation{
+				{
+					Operation:  BATCH_OPERATION_ADD_USER,
+					ExternalID: "new",
+					Path:       "/path/",
+				},
+			},
+			atomic: true,
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			runInTransactionErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.SpecialFuncs[GetUserByExternalIDMethod] = getUserByExternalID
+		testRepo.ArgsOut[AddUserMethod][0] = &User{
+			ID:         "new",
+			ExternalID: "new",
+			Path:       "/path/",
+		}
+		testRepo.ArgsOut[RunInTransactionMethod][0] = testcase.runInTransactionErr
+		results, err := testAPI.Batch(testcase.requestInfo, testcase.operations, testcase.atomic)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResults, results)
+	}
+}
+
+func TestAuthAPI_BatchPermissionsLoadedOnce(t *testing.T) {
+	requesterLookups := 0
+	testRepo := makeTestRepo()
+	testAPI := makeTestAPI(testRepo)
+
+	testRepo.SpecialFuncs[GetUserByExternalIDMethod] = func(id string) (*User, error) {
+		if id == "123456" {
+			requesterLookups++
+			return &User{
+				ID:         "REQUESTER-ID",
+				ExternalID: "123456",
+			}, nil
+		}
+		return nil, &database.Error{
+			Code: database.USER_NOT_FOUND,
+		}
+	}
+	testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = []Group{
+		{
+			ID:   "GROUP-USER-ID",
+			Name: "groupUser",
+		},
+	}
+	testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = []Policy{
+		{
+			ID:   "POLICY-USER-ID",
+			Name: "policyUser",
+			Statements: &[]Statement{
+				{
+					Effect: "allow",
+					Actions: []string{
+						USER_ACTION_CREATE_USER,
+					},
+					Resources: []string{
+						GetUrnPrefix("", RESOURCE_USER, "/path/"),
+					},
+				},
+			},
+		},
+	}
+	testRepo.ArgsOut[AddUserMethod][0] = &User{
+		ID:         "new",
+		ExternalID: "new",
+		Path:       "/path/",
+	}
+
+	operations := []BatchOperation{
+		{
+			Operation:  BATCH_OPERATION_ADD_USER,
+			ExternalID: "new1",
+			Path:       "/path/",
+		},
+		{
+			Operation:  BATCH_OPERATION_ADD_USER,
+			ExternalID: "new2",
+			Path:       "/path/",
+		},
+		{
+			Operation:  BATCH_OPERATION_ADD_USER,
+			ExternalID: "new3",
+			Path:       "/path/",
+		},
+	}
+	results, err := testAPI.Batch(RequestInfo{Identifier: "123456"}, operations, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i, result := range results {
+		if result.Status != BATCH_STATUS_OK {
+			t.Errorf("Operation %v failed: %v", i, result.Error)
+		}
+	}
+	if requesterLookups != 1 {
+		t.Errorf("Permissions of the authenticated user retrieved %v times, expected once", requesterLookups)
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/tecsisa/foulkon/database"
+)
+
+// TYPE DEFINITIONS
+
+// Report of the inconsistencies in stored data. The database doesn't enforce the relationships between
+// statements, relations and the users, groups and policies they refer to, so drift isn't noticed otherwise
+type ConsistencyReport struct {
+	// Statements with user, group or policy URNs in their resources that don't exist
+	DanglingReferences []DanglingReference `json:"danglingReferences, omitempty"`
+	// Group memberships whose group or user doesn't exist
+	DanglingMemberships []DanglingRelation `json:"danglingMemberships, omitempty"`
+	// Group policy attachments whose group or policy doesn't exist
+	DanglingAttachments []DanglingRelation `json:"danglingAttachments, omitempty"`
+	// Group ownerships whose group or user doesn't exist
+	DanglingOwnerships []DanglingRelation `json:"danglingOwnerships, omitempty"`
+	// Tags of users, groups or policies that don't exist
+	DanglingTags []DanglingTag `json:"danglingTags, omitempty"`
+	// Policies without statements
+	EmptyPolicies []PolicyIdentity `json:"emptyPolicies, omitempty"`
+	// Groups in organizations without policies, so they can't grant any permission
+	GroupsWithoutOrgPolicies []GroupIdentity `json:"groupsWithoutOrgPolicies, omitempty"`
+}
+
+// Number of inconsistencies in the report
+func (c ConsistencyReport) Total() int {
+	return len(c.DanglingReferences) + len(c.DanglingMemberships) + len(c.DanglingAttachments) +
+		len(c.DanglingOwnerships) + len(c.DanglingTags) + len(c.EmptyPolicies) + len(c.GroupsWithoutOrgPolicies)
+}
+
+// Policy statement that references a user, group or policy URN that doesn't exist
+type DanglingReference struct {
+	Org        string `json:"org, omitempty"`
+	PolicyName string `json:"policyName, omitempty"`
+	Sid        string `json:"sid, omitempty"`
+	Urn        string `json:"urn, omitempty"`
+}
+
+// Relationship of a group with a user or policy where some of them doesn't exist
+type DanglingRelation struct {
+	GroupID  string `json:"groupId, omitempty"`
+	UserID   string `json:"userId, omitempty"`
+	PolicyID string `json:"policyId, omitempty"`
+}
+
+// Tag of a user, group or policy that doesn't exist
+type DanglingTag struct {
+	ResourceID string `json:"resourceId, omitempty"`
+	Key        string `json:"key, omitempty"`
+}
+
+// CONSISTENCY API IMPLEMENTATION
+
+func (api AuthAPI) CheckConsistency(requestInfo RequestInfo) (*ConsistencyReport, error) {
+	// Only admin can do it, because the report spans every organization
+	if !requestInfo.Admin {
+		return nil, &Error{
+			Code:    UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to check consistency", requestInfo.Identifier),
+		}
+	}
+
+	// Call repo to retrieve the inconsistencies
+	report, err := api.ConsistencyRepo.GetConsistencyReport()
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestAuthAPI_CheckConsistency(t *testing.T) {
+	report := &ConsistencyReport{
+		DanglingReferences: []DanglingReference{
+			{
+				Org:        "example",
+				PolicyName: "policy",
+				Sid:        "members",
+				Urn:        CreateUrn("example", RESOURCE_GROUP, "/path/", "group"),
+			},
+		},
+		DanglingMemberships: []DanglingRelation{},
+		DanglingAttachments: []DanglingRelation{
+			{
+				GroupID:  "GROUP-ID",
+				PolicyID: "POLICY-ID",
+			},
+		},
+		EmptyPolicies:            []PolicyIdentity{},
+		GroupsWithoutOrgPolicies: []GroupIdentity{},
+	}
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		// Expected results
+		expectedResponse *ConsistencyReport
+		wantError        error
+		// Manager Results
+		getConsistencyReportMethodResult *ConsistencyReport
+		// Manager Errors
+		getConsistencyReportMethodErr error
+	}{
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			expectedResponse:                 report,
+			getConsistencyReportMethodResult: report,
+		},
+		"ErrorCaseNotAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to check consistency",
+			},
+		},
+		"ErrorCaseInternalError": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getConsistencyReportMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetConsistencyReportMethod][0] = testcase.getConsistencyReportMethodResult
+		testRepo.ArgsOut[GetConsistencyReportMethod][1] = testcase.getConsistencyReportMethodErr
+
+		response, err := testAPI.CheckConsistency(testcase.requestInfo)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, response)
+	}
+}
+
+func TestConsistencyReport_Total(t *testing.T) {
+	report := ConsistencyReport{
+		DanglingReferences: []DanglingReference{{Urn: "urn"}},
+		DanglingAttachments: []DanglingRelation{
+			{GroupID: "GROUP-ID"},
+			{PolicyID: "POLICY-ID"},
+		},
+		DanglingOwnerships:       []DanglingRelation{{UserID: "USER-ID"}},
+		DanglingTags:             []DanglingTag{{ResourceID: "USER-ID", Key: "team"}},
+		GroupsWithoutOrgPolicies: []GroupIdentity{{Name: "group"}},
+	}
+	if total := report.Total(); total != 6 {
+		t.Errorf("Test failed. Received different total (wanted:%v / received:%v)", 6, total)
+	}
+}
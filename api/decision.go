@@ -0,0 +1,68 @@
+package api
+
+import (
+	"fmt"
+	"time"
+)
+
+// TYPE DEFINITIONS
+
+// Result of an authorization evaluation: the resources of the requested URN or prefix that the user
+// asked for with the action, and those allowed. Error is the code of the error that denied the whole
+// evaluation, and it's empty if the evaluation succeeded
+type AuthzDecision struct {
+	RequestID     string    `json:"requestId"`
+	User          string    `json:"user"`
+	Admin         bool      `json:"admin"`
+	Action        string    `json:"action"`
+	Resource      string    `json:"resource"`
+	RequestedUrns []string  `json:"requestedUrns"`
+	AllowedUrns   []string  `json:"allowedUrns"`
+	Error         string    `json:"error,omitempty"`
+	LatencyMs     float64   `json:"latencyMs"`
+	CreateAt      time.Time `json:"createdAt"`
+}
+
+func (d AuthzDecision) String() string {
+	return fmt.Sprintf("[requestId: %v, user: %v, action: %v, resource: %v, requested: %v, allowed: %v, error: %v]",
+		d.RequestID, d.User, d.Action, d.Resource, len(d.RequestedUrns), len(d.AllowedUrns), d.Error)
+}
+
+// PRIVATE HELPER METHODS
+
+// Send the decision of an authorization evaluation to the decision logger, if there is one
+func (api AuthAPI) logDecision(requestInfo RequestInfo, resourceUrn string, action string, resources []Resource,
+	allowed []Resource, err error, start time.Time) {
+	if api.DecisionLogger == nil {
+		return
+	}
+
+	decision := AuthzDecision{
+		RequestID:     requestInfo.RequestID,
+		User:          requestInfo.Identifier,
+		Admin:         requestInfo.Admin,
+		Action:        action,
+		Resource:      resourceUrn,
+		RequestedUrns: getResourceUrns(resources),
+		AllowedUrns:   getResourceUrns(allowed),
+		LatencyMs:     float64(time.Since(start)) / float64(time.Millisecond),
+		CreateAt:      start.UTC(),
+	}
+	if err != nil {
+		if apiError, ok := err.(*Error); ok {
+			decision.Error = apiError.Code
+		} else {
+			decision.Error = UNKNOWN_API_ERROR
+		}
+	}
+
+	api.DecisionLogger.LogDecision(decision)
+}
+
+func getResourceUrns(resources []Resource) []string {
+	urns := []string{}
+	for _, r := range resources {
+		urns = append(urns, r.GetUrn())
+	}
+	return urns
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// TestDecisionLogger that keeps the recorded decisions
+type TestDecisionLogger struct {
+	Decisions []AuthzDecision
+}
+
+func (l *TestDecisionLogger) LogDecision(decision AuthzDecision) {
+	l.Decisions = append(l.Decisions, decision)
+}
+
+func TestAuthAPI_logDecision(t *testing.T) {
+	testcases := map[string]struct {
+		// Authenticated user
+		requestInfo RequestInfo
+		// Resource urn that user wants to access
+		resourceUrn string
+		// Action to do
+		action string
+		// Resources that system has to authorize
+		resourcesToAuthorize []Resource
+		// Expected result
+		expectedDecision AuthzDecision
+		// GetUserByExternalID Method Out Arguments
+		getUserByExternalIDResult *User
+		getUserByExternalIDError  error
+		// GetGroupsByUserID Method Out Arguments
+		getGroupsByUserIDResult []Group
+		// GetAttachedPolicies Method Out Arguments
+		getAttachedPoliciesResult []Policy
+	}{
+		"OKCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+				RequestID:  "REQUEST-ID",
+			},
+			resourceUrn: CreateUrn("example", RESOURCE_GROUP, "/path/", "group1"),
+			action:      GROUP_ACTION_GET_GROUP,
+			resourcesToAuthorize: []Resource{
+				Group{
+					ID:  "654321",
+					Urn: CreateUrn("example", RESOURCE_GROUP, "/path/", "group1"),
+				},
+			},
+			expectedDecision: AuthzDecision{
+				RequestID:     "REQUEST-ID",
+				User:          "admin",
+				Admin:         true,
+				Action:        GROUP_ACTION_GET_GROUP,
+				Resource:      CreateUrn("example", RESOURCE_GROUP, "/path/", "group1"),
+				RequestedUrns: []string{CreateUrn("example", RESOURCE_GROUP, "/path/", "group1")},
+				AllowedUrns:   []string{CreateUrn("example", RESOURCE_GROUP, "/path/", "group1")},
+			},
+		},
+		"OKCaseResourcesFiltered": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				RequestID:  "REQUEST-ID",
+			},
+			resourceUrn: GetUrnPrefix("example", RESOURCE_GROUP, "/path"),
+			action:      GROUP_ACTION_GET_GROUP,
+			resourcesToAuthorize: []Resource{
+				Group{
+					ID:  "654321",
+					Urn: CreateUrn("example", RESOURCE_GROUP, "/path/", "group1"),
+				},
+				Group{
+					ID:  "UNAUTHORIZED-GROUP-ID",
+					Urn: CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUnauthorized"),
+				},
+			},
+			expectedDecision: AuthzDecision{
+				RequestID: "REQUEST-ID",
+				User:      "123456",
+				Action:    GROUP_ACTION_GET_GROUP,
+				Resource:  GetUrnPrefix("example", RESOURCE_GROUP, "/path"),
+				RequestedUrns: []string{
+					CreateUrn("example", RESOURCE_GROUP, "/path/", "group1"),
+					CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUnauthorized"),
+				},
+				AllowedUrns: []string{CreateUrn("example", RESOURCE_GROUP, "/path/", "group1")},
+			},
+			getUserByExternalIDResult: &User{
+				ID:  "123456",
+				Urn: CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:  "GROUP-USER-ID",
+					Urn: CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesResult: []Policy{
+				{
+					ID:  "POLICY-USER-ID",
+					Urn: CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+					Statements: &[]Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								GROUP_ACTION_GET_GROUP,
+							},
+							Resources: []string{
+								CreateUrn("example", RESOURCE_GROUP, "/path/", "group1"),
+							},
+						},
+					},
+				},
+			},
+		},
+		"OKCaseDenied": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				RequestID:  "REQUEST-ID",
+			},
+			resourceUrn: CreateUrn("example", RESOURCE_GROUP, "/path/", "group1"),
+			action:      GROUP_ACTION_GET_GROUP,
+			resourcesToAuthorize: []Resource{
+				Group{
+					ID:  "654321",
+					Urn: CreateUrn("example", RESOURCE_GROUP, "/path/", "group1"),
+				},
+			},
+			expectedDecision: AuthzDecision{
+				RequestID:     "REQUEST-ID",
+				User:          "123456",
+				Action:        GROUP_ACTION_GET_GROUP,
+				Resource:      CreateUrn("example", RESOURCE_GROUP, "/path/", "group1"),
+				RequestedUrns: []string{CreateUrn("example", RESOURCE_GROUP, "/path/", "group1")},
+				AllowedUrns:   []string{},
+				Error:         UNAUTHORIZED_RESOURCES_ERROR,
+			},
+			getUserByExternalIDResult: &User{
+				ID:  "123456",
+				Urn: CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+			},
+		},
+		"OKCaseInternalError": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				RequestID:  "REQUEST-ID",
+			},
+			resourceUrn: CreateUrn("example", RESOURCE_GROUP, "/path/", "group1"),
+			action:      GROUP_ACTION_GET_GROUP,
+			resourcesToAuthorize: []Resource{
+				Group{
+					ID:  "654321",
+					Urn: CreateUrn("example", RESOURCE_GROUP, "/path/", "group1"),
+				},
+			},
+			expectedDecision: AuthzDecision{
+				RequestID:     "REQUEST-ID",
+				User:          "123456",
+				Action:        GROUP_ACTION_GET_GROUP,
+				Resource:      CreateUrn("example", RESOURCE_GROUP, "/path/", "group1"),
+				RequestedUrns: []string{CreateUrn("example", RESOURCE_GROUP, "/path/", "group1")},
+				AllowedUrns:   []string{},
+				Error:         UNKNOWN_API_ERROR,
+			},
+			getUserByExternalIDError: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+		decisionLogger := &TestDecisionLogger{}
+		testAPI.DecisionLogger = decisionLogger
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = test.getUserByExternalIDResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = test.getUserByExternalIDError
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = test.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = test.getAttachedPoliciesResult
+
+		testAPI.getAuthorizedResources(test.requestInfo, test.resourceUrn, test.action, test.resourcesToAuthorize)
+
+		if len(decisionLogger.Decisions) != 1 {
+			t.Errorf("Test %v failed. Received different decision number: %v", n, len(decisionLogger.Decisions))
+			continue
+		}
+		decision := decisionLogger.Decisions[0]
+		if decision.CreateAt.IsZero() || decision.LatencyMs < 0 {
+			t.Errorf("Test %v failed. Decision without creation date or latency %v", n, decision)
+			continue
+		}
+		decision.CreateAt = test.expectedDecision.CreateAt
+		decision.LatencyMs = test.expectedDecision.LatencyMs
+		if diff := pretty.Compare(decision, test.expectedDecision); diff != "" {
+			t.Errorf("Test %v failed. Received different decisions (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
@@ -11,6 +11,8 @@ const (
 	UNKNOWN_API_ERROR            = "UnknownApiError"
 	INVALID_PARAMETER_ERROR      = "InvalidParameterError"
 	UNAUTHORIZED_RESOURCES_ERROR = "UnauthorizedResourcesError"
+	PRECONDITION_FAILED_ERROR    = "PreconditionFailedError"
+	PRECONDITION_REQUIRED_ERROR  = "PreconditionRequiredError"
 
 	// User API error codes
 	USER_BY_EXTERNAL_ID_NOT_FOUND = "UserWithExternalIDNotFound"
@@ -19,11 +21,16 @@ const (
 	// Group API error codes
 	GROUP_BY_ORG_AND_NAME_NOT_FOUND = "GroupWithOrgAndNameNotFound"
 	GROUP_ALREADY_EXIST             = "GroupAlreadyExist"
+	GROUP_HAS_MEMBERSHIP_RULE       = "GroupHasMembershipRule"
 
 	// GroupMembers error codes
 	USER_IS_ALREADY_A_MEMBER_OF_GROUP = "UserIsAlreadyAMemberOfGroup"
 	USER_IS_NOT_A_MEMBER_OF_GROUP     = "UserIsNotAMemberOfGroup"
 
+	// GroupOwners error codes
+	USER_IS_ALREADY_AN_OWNER_OF_GROUP = "UserIsAlreadyAnOwnerOfGroup"
+	USER_IS_NOT_AN_OWNER_OF_GROUP     = "UserIsNotAnOwnerOfGroup"
+
 	// GroupPolicies error codes
 	POLICY_IS_ALREADY_ATTACHED_TO_GROUP = "PolicyIsAlreadyAttachedToGroup"
 	POLICY_IS_NOT_ATTACHED_TO_GROUP     = "PolicyIsNotAttachedToGroup"
@@ -31,6 +38,39 @@ const (
 	// Policy API error codes
 	POLICY_ALREADY_EXIST             = "PolicyAlreadyExist"
 	POLICY_BY_ORG_AND_NAME_NOT_FOUND = "PolicyWithOrgAndNameNotFound"
+	PATCH_TEST_FAILED                = "PatchTestFailed"
+
+	// Statement API error codes
+	STATEMENT_NOT_FOUND     = "StatementNotFound"
+	STATEMENT_ALREADY_EXIST = "StatementAlreadyExist"
+
+	// Access request API error codes
+	ACCESS_REQUEST_NOT_FOUND        = "AccessRequestNotFound"
+	ACCESS_REQUEST_ALREADY_REVIEWED = "AccessRequestAlreadyReviewed"
+
+	// Action registry API error codes
+	NAMESPACE_NOT_FOUND     = "NamespaceNotFound"
+	NAMESPACE_ALREADY_EXIST = "NamespaceAlreadyExist"
+	ACTION_NOT_FOUND        = "ActionNotFound"
+	ACTION_ALREADY_EXIST    = "ActionAlreadyExist"
+
+	// Resource type registry error codes
+	RESOURCE_TYPE_NOT_FOUND     = "ResourceTypeNotFound"
+	RESOURCE_TYPE_ALREADY_EXIST = "ResourceTypeAlreadyExist"
+
+	// Tag API error codes
+	TAG_NOT_FOUND = "TagNotFound"
+
+	// Organization API error codes
+	ORGANIZATION_BY_NAME_NOT_FOUND = "OrganizationWithNameNotFound"
+	ORGANIZATION_ALREADY_EXIST     = "OrganizationAlreadyExist"
+	PLAN_OUTDATED                  = "PlanOutdated"
+
+	// Trash API error codes
+	DELETED_RESOURCE_NOT_FOUND = "DeletedResourceNotFound"
+
+	// Webhook API error codes
+	WEBHOOK_NOT_FOUND = "WebhookNotFound"
 
 	// Regex error
 	REGEX_NO_MATCH = "RegexNoMatch"
@@ -2,6 +2,7 @@ package api
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/satori/go.uuid"
@@ -10,14 +11,21 @@ import (
 
 // TYPE DEFINITIONS
 
-// Group domain
+// Group domain. Groups with a membership rule have no explicit members, their members are
+// the users that match the rule. Owners are the external ids of the users allowed to manage
+// members of the group without policies
 type Group struct {
-	ID       string    `json:"id, omitempty"`
-	Name     string    `json:"name, omitempty"`
-	Path     string    `json:"path, omitempty"`
-	Org      string    `json:"org, omitempty"`
-	Urn      string    `json:"urn, omitempty"`
-	CreateAt time.Time `json:"createAt, omitempty"`
+	ID             string            `json:"id, omitempty"`
+	Name           string            `json:"name, omitempty"`
+	Path           string            `json:"path, omitempty"`
+	Org            string            `json:"org, omitempty"`
+	Urn            string            `json:"urn, omitempty"`
+	CreateAt       time.Time         `json:"createAt, omitempty"`
+	MembershipRule *MembershipRule   `json:"membershipRule, omitempty"`
+	Owners         []string          `json:"owners, omitempty"`
+	Tags           map[string]string `json:"tags, omitempty"`
+	// Revision of the stored group, updates fail if it changed after the group was retrieved
+	Revision int64 `json:"-"`
 }
 
 func (g Group) String() string {
@@ -29,16 +37,73 @@ func (g Group) GetUrn() string {
 	return g.Urn
 }
 
+func (g Group) GetTags() map[string]string {
+	return g.Tags
+}
+
 // Group identifier to retrieve them from DB
 type GroupIdentity struct {
 	Org  string `json:"org, omitempty"`
 	Name string `json:"name, omitempty"`
 }
 
+// Rule that defines the members of a group. A user matches when its path starts with PathPrefix
+// and its attribute AttributeKey has the value AttributeValue. Empty conditions aren't checked
+type MembershipRule struct {
+	PathPrefix     string `json:"pathPrefix, omitempty"`
+	AttributeKey   string `json:"attributeKey, omitempty"`
+	AttributeValue string `json:"attributeValue, omitempty"`
+}
+
+// Check if user is a member of a group with this rule
+func (r MembershipRule) Matches(user User) bool {
+	if !strings.HasPrefix(user.Path, r.PathPrefix) {
+		return false
+	}
+	if r.AttributeKey != "" && user.Attributes[r.AttributeKey] != r.AttributeValue {
+		return false
+	}
+	return true
+}
+
 type GroupMembers struct {
 	Users []User `json:"users, omitempty"`
 }
 
+// Group user relation with its expiration date. A nil ExpiresAt means that
+// membership never expires.
+type GroupUserRelation struct {
+	User      *User
+	ExpiresAt *time.Time
+}
+
+// Group policy relation with its activation window. A nil NotBefore or NotAfter
+// means that the window is not bounded on that side.
+type GroupPolicyRelation struct {
+	Policy    *Policy
+	NotBefore *time.Time
+	NotAfter  *time.Time
+}
+
+// Check if relation is active at the given time
+func (r GroupPolicyRelation) IsActiveAt(t time.Time) bool {
+	if r.NotBefore != nil && t.Before(*r.NotBefore) {
+		return false
+	}
+	if r.NotAfter != nil && !t.Before(*r.NotAfter) {
+		return false
+	}
+	return true
+}
+
+// Attached policy identifier with its activation window
+type AttachedPolicy struct {
+	Name      string     `json:"name, omitempty"`
+	NotBefore *time.Time `json:"notBefore, omitempty"`
+	NotAfter  *time.Time `json:"notAfter, omitempty"`
+	Active    bool       `json:"active, omitempty"`
+}
+
 // GROUP API IMPLEMENTATION
 
 func (api AuthAPI) AddGroup(requestInfo RequestInfo, org string, name string, path string) (*Group, error) {
@@ -77,6 +142,11 @@ func (api AuthAPI) AddGroup(requestInfo RequestInfo, org string, name string, pa
 		}
 	}
 
+	// Check if organization exists
+	if err := api.checkOrganizationExists(org); err != nil {
+		return nil, err
+	}
+
 	// Check if group already exists
 	_, err = api.GroupRepo.GetGroupByName(org, name)
 
@@ -88,7 +158,15 @@ func (api AuthAPI) AddGroup(requestInfo RequestInfo, org string, name string, pa
 		// Group doesn't exist in DB, so we can create it
 		case database.GROUP_NOT_FOUND:
 			// Create group
-			createdGroup, err := api.GroupRepo.AddGroup(group)
+			var createdGroup *Group
+			err := api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+				var err error
+				createdGroup, err = repo.AddGroup(group)
+				if err != nil {
+					return err
+				}
+				return api.auditOperation(repo, requestInfo, GROUP_ACTION_CREATE_GROUP, createdGroup.Urn, nil, createdGroup)
+			})
 
 			// Check if there is an unexpected error in DB
 			if err != nil {
@@ -173,33 +251,20 @@ func (api AuthAPI) GetGroupByName(requestInfo RequestInfo, org string, name stri
 
 }
 
-func (api AuthAPI) ListGroups(requestInfo RequestInfo, org string, pathPrefix string) ([]GroupIdentity, error) {
+func (api AuthAPI) ListGroups(requestInfo RequestInfo, filter *Filter) ([]Group, int, error) {
 	// Validate fields
-	if len(org) > 0 && !IsValidOrg(org) {
-		return nil, &Error{
-			Code:    INVALID_PARAMETER_ERROR,
-			Message: fmt.Sprintf("Invalid parameter: org %v", org),
-		}
-	}
-	if len(pathPrefix) > 0 && !IsValidPath(pathPrefix) {
-		return nil, &Error{
-			Code:    INVALID_PARAMETER_ERROR,
-			Message: fmt.Sprintf("Invalid parameter: PathPrefix %v", pathPrefix),
-		}
-	}
-
-	if len(pathPrefix) == 0 {
-		pathPrefix = "/"
+	if err := validateFilter(filter); err != nil {
+		return nil, 0, err
 	}
 
 	// Call repo to retrieve the groups
-	groups, err := api.GroupRepo.GetGroupsFiltered(org, pathPrefix)
+	groups, total, err := api.GroupRepo.GetGroupsFiltered(filter)
 
 	// Error handling
 	if err != nil {
 		//Transform to DB error
 		dbError := err.(*database.Error)
-		return nil, &Error{
+		return nil, 0, &Error{
 			Code:    UNKNOWN_API_ERROR,
 			Message: dbError.Message,
 		}
@@ -207,38 +272,29 @@ func (api AuthAPI) ListGroups(requestInfo RequestInfo, org string, pathPrefix st
 
 	// Check restrictions to list
 	var urnPrefix string
-	if len(org) == 0 {
+	if len(filter.Org) == 0 {
 		urnPrefix = "*"
 	} else {
-		urnPrefix = GetUrnPrefix(org, RESOURCE_GROUP, pathPrefix)
+		urnPrefix = GetUrnPrefix(filter.Org, RESOURCE_GROUP, filter.PathPrefix)
 	}
 	filteredGroups, err := api.GetAuthorizedGroups(requestInfo, urnPrefix, GROUP_ACTION_LIST_GROUPS, groups)
 	if err != nil {
-		return nil, err
-	}
-
-	// Transform to identifiers
-	groupIDs := []GroupIdentity{}
-	for _, g := range filteredGroups {
-		groupIDs = append(groupIDs, GroupIdentity{
-			Org:  g.Org,
-			Name: g.Name,
-		})
+		return nil, 0, err
 	}
 
-	return groupIDs, nil
+	return filteredGroups, total, nil
 }
 
-func (api AuthAPI) UpdateGroup(requestInfo RequestInfo, org string, name string, newName string, newPath string) (*Group, error) {
+func (api AuthAPI) UpdateGroup(requestInfo RequestInfo, org string, name string, newName string, newPath string) (*Group, []StatementReference, error) {
 	// Validate fields
 	if !IsValidName(newName) {
-		return nil, &Error{
+		return nil, nil, &Error{
 			Code:    INVALID_PARAMETER_ERROR,
 			Message: fmt.Sprintf("Invalid parameter: new name %v", newName),
 		}
 	}
 	if !IsValidPath(newPath) {
-		return nil, &Error{
+		return nil, nil, &Error{
 			Code:    INVALID_PARAMETER_ERROR,
 			Message: fmt.Sprintf("Invalid parameter: new path %v", newPath),
 		}
@@ -247,29 +303,34 @@ func (api AuthAPI) UpdateGroup(requestInfo RequestInfo, org string, name string,
 	// Call repo to retrieve the group
 	group, err := api.GetGroupByName(requestInfo, org, name)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	oldGroup := group
 
 	// Check restrictions
 	groupsFiltered, err := api.GetAuthorizedGroups(requestInfo, group.Urn, GROUP_ACTION_UPDATE_GROUP, []Group{*group})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if len(groupsFiltered) < 1 {
-		return nil, &Error{
+		return nil, nil, &Error{
 			Code: UNAUTHORIZED_RESOURCES_ERROR,
 			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
 				requestInfo.Identifier, group.Urn),
 		}
 	}
 
+	// Check that the group hasn't been modified since the client retrieved it
+	if err := checkIfMatch(requestInfo, *group); err != nil {
+		return nil, nil, err
+	}
+
 	// Check if a group with "newName" already exists
 	newGroup, err := api.GetGroupByName(requestInfo, org, newName)
 
 	if err == nil && group.ID != newGroup.ID {
 		// Group already exists
-		return nil, &Error{
+		return nil, nil, &Error{
 			Code:    GROUP_ALREADY_EXIST,
 			Message: fmt.Sprintf("Group name: %v already exists", newName),
 		}
@@ -277,7 +338,7 @@ func (api AuthAPI) UpdateGroup(requestInfo RequestInfo, org string, name string,
 
 	if err != nil {
 		if apiError := err.(*Error); apiError.Code == UNAUTHORIZED_RESOURCES_ERROR || apiError.Code == UNKNOWN_API_ERROR {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
@@ -287,31 +348,51 @@ func (api AuthAPI) UpdateGroup(requestInfo RequestInfo, org string, name string,
 	// Check restrictions
 	groupsFiltered, err = api.GetAuthorizedGroups(requestInfo, groupToUpdate.Urn, GROUP_ACTION_UPDATE_GROUP, []Group{groupToUpdate})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if len(groupsFiltered) < 1 {
-		return nil, &Error{
+		return nil, nil, &Error{
 			Code: UNAUTHORIZED_RESOURCES_ERROR,
 			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
 				requestInfo.Identifier, groupToUpdate.Urn),
 		}
 	}
 
+	// Retrieve policies with statements that reference the group when its URN changes
+	policies, err := api.getReferencingPolicies(requestInfo, oldGroup.Urn, groupToUpdate.Urn, requestInfo.RewriteReferences)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Update group
-	group, err = api.GroupRepo.UpdateGroup(*group, newName, newPath, groupToUpdate.Urn)
+	err = api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		var err error
+		group, err = repo.UpdateGroup(*oldGroup, newName, newPath, groupToUpdate.Urn)
+		if err != nil {
+			return err
+		}
+		if err := api.auditOperation(repo, requestInfo, GROUP_ACTION_UPDATE_GROUP, group.Urn, oldGroup, group); err != nil {
+			return err
+		}
+		if !requestInfo.RewriteReferences {
+			return nil
+		}
+		return api.rewriteReferencingPolicies(repo, requestInfo, policies, oldGroup.Urn, groupToUpdate.Urn)
+	})
 
 	// Check unexpected DB error
 	if err != nil {
-		//Transform to DB error
-		dbError := err.(*database.Error)
-		return nil, &Error{
-			Code:    UNKNOWN_API_ERROR,
-			Message: dbError.Message,
-		}
+		return nil, nil, toRevisionAPIError(err)
 	}
 
-	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Group updated from %+v to %+v", oldGroup, group))
-	return group, nil
+	references := []StatementReference{}
+	if !requestInfo.RewriteReferences {
+		references = getStatementReferences(policies, oldGroup.Urn)
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Group updated from %+v to %+v with %v referencing policies, rewritten: %v",
+		oldGroup, group, len(policies), requestInfo.RewriteReferences))
+	return group, references, nil
 
 }
 
@@ -336,46 +417,95 @@ func (api AuthAPI) RemoveGroup(requestInfo RequestInfo, org string, name string)
 		}
 	}
 
+	// Check that the group hasn't been modified since the client retrieved it
+	if err := checkIfMatch(requestInfo, *group); err != nil {
+		return err
+	}
+
 	// Remove group with given org and name
-	err = api.GroupRepo.RemoveGroup(group.ID)
+	err = api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		if err := repo.RemoveGroup(*group); err != nil {
+			return err
+		}
+		return api.auditOperation(repo, requestInfo, GROUP_ACTION_DELETE_GROUP, group.Urn, group, nil)
+	})
 
 	// Error handling
 	if err != nil {
-		//Transform to DB error
-		dbError := err.(*database.Error)
-		return &Error{
-			Code:    UNKNOWN_API_ERROR,
-			Message: dbError.Message,
-		}
+		return toRevisionAPIError(err)
 	}
 
 	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Group deleted %+v", group))
 	return nil
 }
 
-func (api AuthAPI) AddMember(requestInfo RequestInfo, externalId string, name string, org string) error {
+func (api AuthAPI) SetGroupMembershipRule(requestInfo RequestInfo, org string, name string, rule *MembershipRule) (*Group, error) {
+	// Validate fields
+	if rule != nil {
+		if err := validateMembershipRule(*rule); err != nil {
+			return nil, err
+		}
+	}
 
 	// Call repo to retrieve the group
-	groupDB, err := api.GetGroupByName(requestInfo, org, name)
+	group, err := api.GetGroupByName(requestInfo, org, name)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Check restrictions
-	groupsFiltered, err := api.GetAuthorizedGroups(requestInfo, groupDB.Urn, GROUP_ACTION_ADD_MEMBER, []Group{*groupDB})
+	groupsFiltered, err := api.GetAuthorizedGroups(requestInfo, group.Urn, GROUP_ACTION_UPDATE_GROUP, []Group{*group})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if len(groupsFiltered) < 1 {
-		return &Error{
+		return nil, &Error{
 			Code: UNAUTHORIZED_RESOURCES_ERROR,
 			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
-				requestInfo.Identifier, groupDB.Urn),
+				requestInfo.Identifier, group.Urn),
+		}
+	}
+
+	// Check that the group hasn't been modified since the client retrieved it
+	if err := checkIfMatch(requestInfo, *group); err != nil {
+		return nil, err
+	}
+
+	// Store rule
+	var updatedGroup *Group
+	err = api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		var err error
+		updatedGroup, err = repo.SetGroupMembershipRule(*group, rule)
+		if err != nil {
+			return err
 		}
+		return api.auditOperation(repo, requestInfo, AUDIT_ACTION_SET_GROUP_MEMBERSHIP_RULE, group.Urn, group, updatedGroup)
+	})
+
+	// Check unexpected DB error
+	if err != nil {
+		return nil, toRevisionAPIError(err)
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Membership rule of group %+v set to %+v", group, rule))
+	return updatedGroup, nil
+}
+
+func (api AuthAPI) AddMember(requestInfo RequestInfo, externalId string, name string, org string) error {
+
+	// Call repo to retrieve the group, checking restrictions for users that don't own it
+	groupDB, isOwner, err := api.getGroupToManageMembers(requestInfo, org, name, GROUP_ACTION_ADD_MEMBER)
+	if err != nil {
+		return err
+	}
+
+	// Members of groups with a membership rule can't be managed
+	if err := checkGroupWithoutMembershipRule(groupDB); err != nil {
+		return err
 	}
 
 	// Call repo to retrieve the user
-	userDB, err := api.GetUserByExternalID(requestInfo, externalId)
+	userDB, err := api.getMemberToManage(requestInfo, externalId, isOwner)
 	if err != nil {
 		return err
 	}
@@ -400,7 +530,12 @@ func (api AuthAPI) AddMember(requestInfo RequestInfo, externalId string, name st
 	}
 
 	// Add Member
-	err = api.GroupRepo.AddMember(userDB.ID, groupDB.ID)
+	err = api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		if err := repo.AddMember(userDB.ID, groupDB.ID, nil); err != nil {
+			return err
+		}
+		return api.auditOperation(repo, requestInfo, GROUP_ACTION_ADD_MEMBER, groupDB.Urn, nil, userDB)
+	})
 
 	// Check if there is an unexpected error in DB
 	if err != nil {
@@ -417,27 +552,19 @@ func (api AuthAPI) AddMember(requestInfo RequestInfo, externalId string, name st
 
 func (api AuthAPI) RemoveMember(requestInfo RequestInfo, externalId string, name string, org string) error {
 
-	// Call repo to retrieve the group
-	groupDB, err := api.GetGroupByName(requestInfo, org, name)
+	// Call repo to retrieve the group, checking restrictions for users that don't own it
+	groupDB, isOwner, err := api.getGroupToManageMembers(requestInfo, org, name, GROUP_ACTION_REMOVE_MEMBER)
 	if err != nil {
 		return err
 	}
 
-	// Check restrictions
-	groupsFiltered, err := api.GetAuthorizedGroups(requestInfo, groupDB.Urn, GROUP_ACTION_REMOVE_MEMBER, []Group{*groupDB})
-	if err != nil {
+	// Members of groups with a membership rule can't be managed
+	if err := checkGroupWithoutMembershipRule(groupDB); err != nil {
 		return err
 	}
-	if len(groupsFiltered) < 1 {
-		return &Error{
-			Code: UNAUTHORIZED_RESOURCES_ERROR,
-			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
-				requestInfo.Identifier, groupDB.Urn),
-		}
-	}
 
 	// Call repo to retrieve the user
-	userDB, err := api.GetUserByExternalID(requestInfo, externalId)
+	userDB, err := api.getMemberToManage(requestInfo, externalId, isOwner)
 	if err != nil {
 		return err
 	}
@@ -462,7 +589,12 @@ func (api AuthAPI) RemoveMember(requestInfo RequestInfo, externalId string, name
 	}
 
 	// Remove Member
-	err = api.GroupRepo.RemoveMember(userDB.ID, groupDB.ID)
+	err = api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		if err := repo.RemoveMember(userDB.ID, groupDB.ID); err != nil {
+			return err
+		}
+		return api.auditOperation(repo, requestInfo, GROUP_ACTION_REMOVE_MEMBER, groupDB.Urn, userDB, nil)
+	})
 
 	// Check if there is an unexpected error in DB
 	if err != nil {
@@ -478,35 +610,26 @@ func (api AuthAPI) RemoveMember(requestInfo RequestInfo, externalId string, name
 	return nil
 }
 
-func (api AuthAPI) ListMembers(requestInfo RequestInfo, org string, name string) ([]string, error) {
-
-	// Call repo to retrieve the group
-	group, err := api.GetGroupByName(requestInfo, org, name)
-	if err != nil {
-		return nil, err
+func (api AuthAPI) ListMembers(requestInfo RequestInfo, org string, name string, filter *Filter) ([]string, int, error) {
+	// Validate filter
+	if err := validateFilter(filter); err != nil {
+		return nil, 0, err
 	}
 
-	// Check restrictions
-	groupsFiltered, err := api.GetAuthorizedGroups(requestInfo, group.Urn, GROUP_ACTION_LIST_MEMBERS, []Group{*group})
+	// Call repo to retrieve the group, checking restrictions for users that don't own it
+	group, _, err := api.getGroupToManageMembers(requestInfo, org, name, GROUP_ACTION_LIST_MEMBERS)
 	if err != nil {
-		return nil, err
-	}
-	if len(groupsFiltered) < 1 {
-		return nil, &Error{
-			Code: UNAUTHORIZED_RESOURCES_ERROR,
-			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
-				requestInfo.Identifier, group.Urn),
-		}
+		return nil, 0, err
 	}
 
 	// Get Members
-	members, err := api.GroupRepo.GetGroupMembers(group.ID)
+	members, total, err := api.GroupRepo.GetGroupMembers(group.ID, filter)
 
 	// Error handling
 	if err != nil {
 		//Transform to DB error
 		dbError := err.(*database.Error)
-		return nil, &Error{
+		return nil, 0, &Error{
 			Code:    UNKNOWN_API_ERROR,
 			Message: dbError.Message,
 		}
@@ -517,10 +640,103 @@ func (api AuthAPI) ListMembers(requestInfo RequestInfo, org string, name string)
 		externalIDs = append(externalIDs, m.ExternalID)
 	}
 
-	return externalIDs, nil
+	return externalIDs, total, nil
+}
+
+func (api AuthAPI) ListGroupOwners(requestInfo RequestInfo, org string, name string) ([]string, error) {
+	group, err := api.GetGroupByName(requestInfo, org, name)
+	if err != nil {
+		return nil, err
+	}
+
+	owners := []string{}
+	return append(owners, group.Owners...), nil
+}
+
+func (api AuthAPI) AddGroupOwner(requestInfo RequestInfo, org string, name string, externalId string) error {
+	group, err := api.getGroupToManageOwners(requestInfo, org, name, GROUP_ACTION_ADD_GROUP_OWNER)
+	if err != nil {
+		return err
+	}
+
+	// Call repo to retrieve the user
+	user, err := api.GetUserByExternalID(requestInfo, externalId)
+	if err != nil {
+		return err
+	}
+
+	if isGroupOwner(group, user.ExternalID) {
+		return &Error{
+			Code:    USER_IS_ALREADY_AN_OWNER_OF_GROUP,
+			Message: fmt.Sprintf("User: %v is already an owner of Group: %v", externalId, name),
+		}
+	}
+
+	// Add owner
+	err = api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		if err := repo.AddOwner(user.ID, group.ID); err != nil {
+			return err
+		}
+		return api.auditOperation(repo, requestInfo, GROUP_ACTION_ADD_GROUP_OWNER, group.Urn, nil, user)
+	})
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Owner %+v added to group %+v", user, group))
+	return nil
+}
+
+func (api AuthAPI) RemoveGroupOwner(requestInfo RequestInfo, org string, name string, externalId string) error {
+	group, err := api.getGroupToManageOwners(requestInfo, org, name, GROUP_ACTION_REMOVE_GROUP_OWNER)
+	if err != nil {
+		return err
+	}
+
+	// Call repo to retrieve the user
+	user, err := api.GetUserByExternalID(requestInfo, externalId)
+	if err != nil {
+		return err
+	}
+
+	if !isGroupOwner(group, user.ExternalID) {
+		return &Error{
+			Code:    USER_IS_NOT_AN_OWNER_OF_GROUP,
+			Message: fmt.Sprintf("User: %v is not an owner of Group: %v", externalId, name),
+		}
+	}
+
+	// Remove owner
+	err = api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		if err := repo.RemoveOwner(user.ID, group.ID); err != nil {
+			return err
+		}
+		return api.auditOperation(repo, requestInfo, GROUP_ACTION_REMOVE_GROUP_OWNER, group.Urn, user, nil)
+	})
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Owner %+v removed from group %+v", user, group))
+	return nil
 }
 
-func (api AuthAPI) AttachPolicyToGroup(requestInfo RequestInfo, org string, name string, policyName string) error {
+func (api AuthAPI) AttachPolicyToGroup(requestInfo RequestInfo, org string, name string, policyName string,
+	notBefore *time.Time, notAfter *time.Time) error {
+	// Validate fields
+	if err := isValidActivationWindow(notBefore, notAfter); err != nil {
+		return err
+	}
 
 	// Check if group exists
 	group, err := api.GetGroupByName(requestInfo, org, name)
@@ -566,7 +782,12 @@ func (api AuthAPI) AttachPolicyToGroup(requestInfo RequestInfo, org string, name
 	}
 
 	// Attach Policy to Group
-	err = api.GroupRepo.AttachPolicy(group.ID, policy.ID)
+	err = api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		if err := repo.AttachPolicy(group.ID, policy.ID, notBefore, notAfter); err != nil {
+			return err
+		}
+		return api.auditOperation(repo, requestInfo, GROUP_ACTION_ATTACH_GROUP_POLICY, group.Urn, nil, policy)
+	})
 
 	if err != nil {
 		dbError := err.(*database.Error)
@@ -627,7 +848,12 @@ func (api AuthAPI) DetachPolicyToGroup(requestInfo RequestInfo, org string, name
 	}
 
 	// Detach Policy to Group
-	err = api.GroupRepo.DetachPolicy(group.ID, policy.ID)
+	err = api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		if err := repo.DetachPolicy(group.ID, policy.ID); err != nil {
+			return err
+		}
+		return api.auditOperation(repo, requestInfo, GROUP_ACTION_DETACH_GROUP_POLICY, group.Urn, policy, nil)
+	})
 
 	if err != nil {
 		dbError := err.(*database.Error)
@@ -641,21 +867,25 @@ func (api AuthAPI) DetachPolicyToGroup(requestInfo RequestInfo, org string, name
 	return nil
 }
 
-func (api AuthAPI) ListAttachedGroupPolicies(requestInfo RequestInfo, org string, name string) ([]string, error) {
+func (api AuthAPI) ListAttachedGroupPolicies(requestInfo RequestInfo, org string, name string, filter *Filter) ([]AttachedPolicy, int, error) {
+	// Validate filter
+	if err := validateFilter(filter); err != nil {
+		return nil, 0, err
+	}
 
 	// Check if group exists
 	group, err := api.GetGroupByName(requestInfo, org, name)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	// Check restrictions
 	groupsFiltered, err := api.GetAuthorizedGroups(requestInfo, group.Urn, GROUP_ACTION_LIST_ATTACHED_GROUP_POLICIES, []Group{*group})
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	if len(groupsFiltered) < 1 {
-		return nil, &Error{
+		return nil, 0, &Error{
 			Code: UNAUTHORIZED_RESOURCES_ERROR,
 			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
 				requestInfo.Identifier, group.Urn),
@@ -663,23 +893,29 @@ func (api AuthAPI) ListAttachedGroupPolicies(requestInfo RequestInfo, org string
 	}
 
 	// Call repo to retrieve the GroupPolicyRelations
-	attachedPolicies, err := api.GroupRepo.GetAttachedPolicies(group.ID)
+	relations, total, err := api.GroupRepo.GetGroupPolicyRelations(group.ID, filter)
 
 	// Error handling
 	if err != nil {
 		//Transform to DB error
 		dbError := err.(*database.Error)
-		return nil, &Error{
+		return nil, 0, &Error{
 			Code:    UNKNOWN_API_ERROR,
 			Message: dbError.Message,
 		}
 	}
 
-	policyIDs := []string{}
-	for _, p := range attachedPolicies {
-		policyIDs = append(policyIDs, p.Name)
+	now := time.Now().UTC()
+	attachedPolicies := []AttachedPolicy{}
+	for _, r := range relations {
+		attachedPolicies = append(attachedPolicies, AttachedPolicy{
+			Name:      r.Policy.Name,
+			NotBefore: r.NotBefore,
+			NotAfter:  r.NotAfter,
+			Active:    r.IsActiveAt(now),
+		})
 	}
-	return policyIDs, nil
+	return attachedPolicies, total, nil
 }
 
 // PRIVATE HELPER METHODS
@@ -697,3 +933,187 @@ func createGroup(org string, name string, path string) Group {
 
 	return group
 }
+
+// Check that activation window bounds are consistent. Both bounds are optional.
+func isValidActivationWindow(notBefore *time.Time, notAfter *time.Time) error {
+	if notAfter != nil && !notAfter.After(time.Now().UTC()) {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: notAfter %v is in the past", notAfter.UTC()),
+		}
+	}
+	if notBefore != nil && notAfter != nil && !notAfter.After(*notBefore) {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: notAfter %v must be later than notBefore %v", notAfter.UTC(), notBefore.UTC()),
+		}
+	}
+	return nil
+}
+
+func validateMembershipRule(rule MembershipRule) error {
+	if rule.PathPrefix == "" && rule.AttributeKey == "" {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: "Invalid parameter: membership rule needs a path prefix or an attribute",
+		}
+	}
+	if rule.PathPrefix != "" && !IsValidPath(rule.PathPrefix) {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: pathPrefix %v", rule.PathPrefix),
+		}
+	}
+	if rule.AttributeKey != "" && !IsValidTagKey(rule.AttributeKey) {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: attributeKey %v", rule.AttributeKey),
+		}
+	}
+	if (rule.AttributeKey == "") != (rule.AttributeValue == "") || len(rule.AttributeValue) > MAX_TAG_VALUE_LENGTH {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: attributeValue %v", rule.AttributeValue),
+		}
+	}
+	return nil
+}
+
+func checkGroupWithoutMembershipRule(group *Group) error {
+	if group.MembershipRule != nil {
+		return &Error{
+			Code:    GROUP_HAS_MEMBERSHIP_RULE,
+			Message: fmt.Sprintf("Members of group with org %v and name %v are defined by its membership rule", group.Org, group.Name),
+		}
+	}
+	return nil
+}
+
+// Retrieve the group whose members are managed with action, if the user is allowed to get the group and to do
+// the action. Owners of the group are granted both actions, unless they are suspended or a policy denies them
+func (api AuthAPI) getGroupToManageMembers(requestInfo RequestInfo, org string, name string, action string) (*Group, bool, error) {
+	if !IsValidName(name) {
+		return nil, false, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: name %v", name),
+		}
+	}
+	if !IsValidOrg(org) {
+		return nil, false, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: org %v", org),
+		}
+	}
+
+	group, err := api.getGroupForAccessRequest(org, name)
+	if err != nil {
+		return nil, false, err
+	}
+	isOwner := isGroupOwner(group, requestInfo.Identifier)
+
+	// Check restrictions
+	for _, groupAction := range []string{GROUP_ACTION_GET_GROUP, action} {
+		groupsFiltered, err := api.getAuthorizedResourcesWithGrants(requestInfo, group.Urn, groupAction, []Resource{*group},
+			getOwnerGrants(isOwner, groupAction, group.Urn))
+		if err != nil {
+			return nil, false, err
+		}
+		if len(groupsFiltered) < 1 {
+			return nil, false, &Error{
+				Code: UNAUTHORIZED_RESOURCES_ERROR,
+				Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+					requestInfo.Identifier, group.Urn),
+			}
+		}
+	}
+
+	return group, isOwner, nil
+}
+
+// Retrieve the user whose membership is managed, if the user is allowed to get it. Owners of the group are
+// granted to get it, unless they are suspended or a policy denies it
+func (api AuthAPI) getMemberToManage(requestInfo RequestInfo, externalId string, isOwner bool) (*User, error) {
+	if !IsValidUserExternalID(externalId) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: externalId %v", externalId),
+		}
+	}
+
+	user, err := api.UserRepo.GetUserByExternalID(externalId)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		if dbError.Code == database.USER_NOT_FOUND {
+			return nil, &Error{
+				Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: dbError.Message,
+			}
+		}
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	// Check restrictions
+	usersFiltered, err := api.getAuthorizedResourcesWithGrants(requestInfo, user.Urn, USER_ACTION_GET_USER, []Resource{*user},
+		getOwnerGrants(isOwner, USER_ACTION_GET_USER, user.Urn))
+	if err != nil {
+		return nil, err
+	}
+	if len(usersFiltered) < 1 {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, user.Urn),
+		}
+	}
+	return user, nil
+}
+
+// Statements granted to group owners to do the action over the resource, none for other users
+func getOwnerGrants(isOwner bool, action string, resourceUrn string) []Statement {
+	if !isOwner {
+		return nil
+	}
+	return []Statement{
+		{
+			Effect:    "allow",
+			Actions:   []string{action},
+			Resources: []string{resourceUrn},
+		},
+	}
+}
+
+// Retrieve the group whose owners are managed with action, if the user is allowed to get it and to do the action
+func (api AuthAPI) getGroupToManageOwners(requestInfo RequestInfo, org string, name string, action string) (*Group, error) {
+	group, err := api.GetGroupByName(requestInfo, org, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check restrictions
+	groupsFiltered, err := api.GetAuthorizedGroups(requestInfo, group.Urn, action, []Group{*group})
+	if err != nil {
+		return nil, err
+	}
+	if len(groupsFiltered) < 1 {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, group.Urn),
+		}
+	}
+
+	return group, nil
+}
+
+func isGroupOwner(group *Group, externalId string) bool {
+	for _, owner := range group.Owners {
+		if owner == externalId {
+			return true
+		}
+	}
+	return false
+}
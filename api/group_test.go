@@ -1,8 +1,11 @@
 package api
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/kylelemons/godebug/pretty"
 	"github.com/tecsisa/foulkon/database"
 )
 
@@ -23,9 +26,10 @@ func TestAuthAPI_AddGroup(t *testing.T) {
 		getGroupByName            *Group
 		addMemberMethodResult     *Group
 		// Manager Errors
-		getGroupByNameMethodErr      error
-		getUserByExternalIDMethodErr error
-		addGroupMethodErr            error
+		getGroupByNameMethodErr        error
+		getUserByExternalIDMethodErr   error
+		addGroupMethodErr              error
+		getOrganizationByNameMethodErr error
 	}{
 		"OKCaseAdmin": {
 			requestInfo: RequestInfo{
@@ -260,6 +264,23 @@ func TestAuthAPI_AddGroup(t *testing.T) {
 				Code: database.GROUP_NOT_FOUND,
 			},
 		},
+		"ErrorCaseOrganizationNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name: "group1",
+			org:  "org1",
+			path: "/example/",
+			wantError: &Error{
+				Code:    ORGANIZATION_BY_NAME_NOT_FOUND,
+				Message: "Organization with name org1 not found",
+			},
+			getOrganizationByNameMethodErr: &database.Error{
+				Code:    database.ORGANIZATION_NOT_FOUND,
+				Message: "Organization with name org1 not found",
+			},
+		},
 		"ErrorCaseAddGroupDBErr": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
@@ -307,6 +328,7 @@ func TestAuthAPI_AddGroup(t *testing.T) {
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
 		testRepo.ArgsOut[AddGroupMethod][0] = testcase.expectedGroup
 		testRepo.ArgsOut[AddGroupMethod][1] = testcase.addGroupMethodErr
+		testRepo.ArgsOut[GetOrganizationByNameMethod][1] = testcase.getOrganizationByNameMethodErr
 
 		group, err := testAPI.AddGroup(testcase.requestInfo, testcase.org, testcase.name, testcase.path)
 		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedGroup, group)
@@ -612,12 +634,14 @@ func TestAuthAPI_ListGroups(t *testing.T) {
 	testcases := map[string]struct {
 		// API Method args
 		requestInfo RequestInfo
-		org         string
-		pathPrefix  string
+		filter      *Filter
 		// Expected result
-		expectedGroups []GroupIdentity
+		expectedTotal  int
+		expectedFilter *Filter
+		expectedGroups []Group
 		wantError      error
 		// Manager Results
+		getGroupsFilteredMethodTotal  int
 		getGroupsFilteredMethodResult []Group
 		getGroupsByUserIDResult       []Group
 		getAttachedPoliciesResult     []Policy
@@ -631,12 +655,42 @@ func TestAuthAPI_ListGroups(t *testing.T) {
 				Identifier: "123456",
 				Admin:      true,
 			},
-			org:        "org1",
-			pathPrefix: "/",
-			expectedGroups: []GroupIdentity{
+			filter:                       &Filter{Org: "org1", PathPrefix: "/"},
+			expectedTotal:                2,
+			getGroupsFilteredMethodTotal: 2,
+			expectedGroups: []Group{
+				{
+					Name: "group1",
+					Org:  "org1",
+					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+				},
+			},
+			getGroupsFilteredMethodResult: []Group{
 				{
+					Name: "group1",
 					Org:  "org1",
+					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+				},
+			},
+		},
+		"OKCaseAdminFilterByTags": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter:                       &Filter{Org: "org1", PathPrefix: "/", Tags: map[string]string{"team": "payments"}},
+			expectedTotal:                1,
+			expectedFilter:               &Filter{Org: "org1", PathPrefix: "/", Tags: map[string]string{"team": "payments"}, Limit: DEFAULT_LIMIT_SIZE},
+			getGroupsFilteredMethodTotal: 1,
+			expectedGroups: []Group{
+				{
 					Name: "group1",
+					Org:  "org1",
+					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+					Tags: map[string]string{"team": "payments"},
 				},
 			},
 			getGroupsFilteredMethodResult: []Group{
@@ -645,6 +699,7 @@ func TestAuthAPI_ListGroups(t *testing.T) {
 					Org:  "org1",
 					Path: "/path/",
 					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+					Tags: map[string]string{"team": "payments"},
 				},
 			},
 		},
@@ -653,15 +708,19 @@ func TestAuthAPI_ListGroups(t *testing.T) {
 				Identifier: "123456",
 				Admin:      true,
 			},
-			pathPrefix: "/",
-			expectedGroups: []GroupIdentity{
+			filter: &Filter{PathPrefix: "/"},
+			expectedGroups: []Group{
 				{
-					Org:  "org1",
 					Name: "group1",
+					Org:  "org1",
+					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
 				},
 				{
-					Org:  "org2",
 					Name: "group2",
+					Org:  "org2",
+					Path: "/path2/",
+					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path2/", "group2"),
 				},
 			},
 			getGroupsFilteredMethodResult: []Group{
@@ -684,11 +743,13 @@ func TestAuthAPI_ListGroups(t *testing.T) {
 				Identifier: "123456",
 				Admin:      false,
 			},
-			org: "org1",
-			expectedGroups: []GroupIdentity{
+			filter: &Filter{Org: "org1"},
+			expectedGroups: []Group{
 				{
-					Org:  "org1",
 					Name: "group1",
+					Org:  "org1",
+					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
 				},
 			},
 			getGroupsFilteredMethodResult: []Group{
@@ -741,24 +802,21 @@ func TestAuthAPI_ListGroups(t *testing.T) {
 			},
 		},
 		"ErrorCaseInvalidOrg": {
-			org:        "%org1",
-			pathPrefix: "/example/das/",
+			filter: &Filter{Org: "%org1", PathPrefix: "/example/das/"},
 			wantError: &Error{
 				Code:    INVALID_PARAMETER_ERROR,
 				Message: "Invalid parameter: org %org1",
 			},
 		},
 		"ErrorCaseInvalidPath": {
-			org:        "org1",
-			pathPrefix: "/example/das",
+			filter: &Filter{Org: "org1", PathPrefix: "/example/das"},
 			wantError: &Error{
 				Code:    INVALID_PARAMETER_ERROR,
 				Message: "Invalid parameter: PathPrefix /example/das",
 			},
 		},
 		"ErrorCaseInternalErrorGetGroupsFiltered": {
-			org:        "org1",
-			pathPrefix: "/path/",
+			filter: &Filter{Org: "org1", PathPrefix: "/path/"},
 			wantError: &Error{
 				Code: UNKNOWN_API_ERROR,
 			},
@@ -771,8 +829,7 @@ func TestAuthAPI_ListGroups(t *testing.T) {
 				Identifier: "123456",
 				Admin:      false,
 			},
-			org:        "org1",
-			pathPrefix: "/path/",
+			filter: &Filter{Org: "org1", PathPrefix: "/path/"},
 			wantError: &Error{
 				Code:    UNAUTHORIZED_RESOURCES_ERROR,
 				Message: "Authenticated user with externalId 123456 not found. Unable to retrieve permissions.",
@@ -794,7 +851,7 @@ func TestAuthAPI_ListGroups(t *testing.T) {
 				Identifier: "123456",
 				Admin:      false,
 			},
-			org: "org1",
+			filter: &Filter{Org: "org1"},
 			wantError: &Error{
 				Code:    UNAUTHORIZED_RESOURCES_ERROR,
 				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:org1:group/*",
@@ -862,7 +919,7 @@ func TestAuthAPI_ListGroups(t *testing.T) {
 				Identifier: "123456",
 				Admin:      false,
 			},
-			org: "org1",
+			filter: &Filter{Org: "org1"},
 			wantError: &Error{
 				Code:    UNAUTHORIZED_RESOURCES_ERROR,
 				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:org1:group/*",
@@ -906,6 +963,17 @@ func TestAuthAPI_ListGroups(t *testing.T) {
 				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
 			},
 		},
+		"ErrorCaseInvalidOffset": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter: &Filter{Offset: -1},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Offset -1",
+			},
+		},
 	}
 
 	for x, testcase := range testcases {
@@ -913,18 +981,53 @@ func TestAuthAPI_ListGroups(t *testing.T) {
 		testAPI := makeTestAPI(testRepo)
 
 		testRepo.ArgsOut[GetGroupsFilteredMethod][0] = testcase.getGroupsFilteredMethodResult
-		testRepo.ArgsOut[GetGroupsFilteredMethod][1] = testcase.getGroupsFilteredMethodErr
+		testRepo.ArgsOut[GetGroupsFilteredMethod][2] = testcase.getGroupsFilteredMethodErr
 		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
 		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
 		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
+		testRepo.ArgsOut[GetGroupsFilteredMethod][1] = testcase.getGroupsFilteredMethodTotal
 
-		groups, err := testAPI.ListGroups(testcase.requestInfo, testcase.org, testcase.pathPrefix)
+		filter := testcase.filter
+		if filter == nil {
+			filter = &Filter{}
+		}
+		groups, total, err := testAPI.ListGroups(testcase.requestInfo, filter)
 		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedGroups, groups)
+		if testcase.wantError == nil {
+			if total != testcase.expectedTotal {
+				t.Errorf("Test %v failed. Received different total (wanted:%v / received:%v)", x, testcase.expectedTotal, total)
+			}
+			// Check filter received by repo
+			if testcase.expectedFilter != nil {
+				if diff := pretty.Compare(testRepo.ArgsIn[GetGroupsFilteredMethod][0], testcase.expectedFilter); diff != "" {
+					t.Errorf("Test %v failed. Received different filter (received/wanted) %v", x, diff)
+				}
+			}
+		}
 	}
 }
 
 func TestAuthAPI_UpdateGroup(t *testing.T) {
+	referencingPolicy := Policy{
+		ID:   "POLICY-ID",
+		Name: "policy",
+		Org:  "123",
+		Path: "/path/",
+		Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policy"),
+		Statements: &[]Statement{
+			{
+				Sid:    "members",
+				Effect: "allow",
+				Actions: []string{
+					GROUP_ACTION_ADD_MEMBER,
+				},
+				Resources: []string{
+					CreateUrn("123", RESOURCE_GROUP, "/path/", "group1"),
+				},
+			},
+		},
+	}
 	testcases := map[string]struct {
 		requestInfo  RequestInfo
 		org          string
@@ -932,8 +1035,10 @@ func TestAuthAPI_UpdateGroup(t *testing.T) {
 		newGroupName string
 		newPath      string
 		// Expected result
-		expectedGroup *Group
-		wantError     error
+		expectedGroup      *Group
+		expectedReferences []StatementReference
+		expectedStatements []Statement
+		wantError          error
 		// Manager Results
 		getGroupByNameResult            *Group
 		getGroupMembersResult           []User
@@ -942,6 +1047,8 @@ func TestAuthAPI_UpdateGroup(t *testing.T) {
 		getUserByExternalIDResult       *User
 		updateGroupResult               *Group
 		getGroupByNameMethodSpecialFunc func(string, string) (*Group, error)
+		getPoliciesReferencingUrnResult []Policy
+		updatePolicyMethodResult        *Policy
 		// API Errors
 		getGroupByNameMethodErr      error
 		getUserByExternalIDMethodErr error
@@ -978,6 +1085,96 @@ func TestAuthAPI_UpdateGroup(t *testing.T) {
 				Urn:  CreateUrn("123", RESOURCE_GROUP, "/new/", "test"),
 			},
 		},
+		"OKCaseDanglingReferences": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:          "123",
+			groupName:    "group1",
+			newGroupName: "group1",
+			newPath:      "/new/",
+			expectedGroup: &Group{
+				ID:   "12345",
+				Name: "group1",
+				Org:  "123",
+				Path: "/new/",
+				Urn:  CreateUrn("123", RESOURCE_GROUP, "/new/", "group1"),
+			},
+			expectedReferences: []StatementReference{
+				{
+					Org:        "123",
+					PolicyName: "policy",
+					Sid:        "members",
+				},
+			},
+			getGroupByNameMethodSpecialFunc: func(org string, name string) (*Group, error) {
+				return &Group{
+					ID:   "12345",
+					Name: "group1",
+					Org:  "123",
+					Path: "/path/",
+					Urn:  CreateUrn("123", RESOURCE_GROUP, "/path/", "group1"),
+				}, nil
+			},
+			updateGroupResult: &Group{
+				ID:   "12345",
+				Name: "group1",
+				Org:  "123",
+				Path: "/new/",
+				Urn:  CreateUrn("123", RESOURCE_GROUP, "/new/", "group1"),
+			},
+			getPoliciesReferencingUrnResult: []Policy{referencingPolicy},
+		},
+		"OKCaseRewriteReferences": {
+			requestInfo: RequestInfo{
+				Identifier:        "123456",
+				Admin:             true,
+				RewriteReferences: true,
+			},
+			org:          "123",
+			groupName:    "group1",
+			newGroupName: "group1",
+			newPath:      "/new/",
+			expectedGroup: &Group{
+				ID:   "12345",
+				Name: "group1",
+				Org:  "123",
+				Path: "/new/",
+				Urn:  CreateUrn("123", RESOURCE_GROUP, "/new/", "group1"),
+			},
+			expectedReferences: []StatementReference{},
+			expectedStatements: []Statement{
+				{
+					Sid:    "members",
+					Effect: "allow",
+					Actions: []string{
+						GROUP_ACTION_ADD_MEMBER,
+					},
+					Resources: []string{
+						CreateUrn("123", RESOURCE_GROUP, "/new/", "group1"),
+					},
+				},
+			},
+			getGroupByNameMethodSpecialFunc: func(org string, name string) (*Group, error) {
+				return &Group{
+					ID:   "12345",
+					Name: "group1",
+					Org:  "123",
+					Path: "/path/",
+					Urn:  CreateUrn("123", RESOURCE_GROUP, "/path/", "group1"),
+				}, nil
+			},
+			updateGroupResult: &Group{
+				ID:   "12345",
+				Name: "group1",
+				Org:  "123",
+				Path: "/new/",
+				Urn:  CreateUrn("123", RESOURCE_GROUP, "/new/", "group1"),
+			},
+			getPoliciesReferencingUrnResult: []Policy{referencingPolicy},
+			updatePolicyMethodResult:        &referencingPolicy,
+		},
 		"OKCase": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
@@ -1509,8 +1706,26 @@ func TestAuthAPI_UpdateGroup(t *testing.T) {
 		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
 
-		group, err := testAPI.UpdateGroup(testcase.requestInfo, testcase.org, testcase.groupName, testcase.newGroupName, testcase.newPath)
+		testRepo.ArgsOut[GetPoliciesReferencingUrnMethod][0] = testcase.getPoliciesReferencingUrnResult
+		testRepo.ArgsOut[UpdatePolicyMethod][0] = testcase.updatePolicyMethodResult
+
+		group, references, err := testAPI.UpdateGroup(testcase.requestInfo, testcase.org, testcase.groupName, testcase.newGroupName, testcase.newPath)
 		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedGroup, group)
+		if testcase.expectedReferences == nil {
+			continue
+		}
+		if diff := pretty.Compare(references, testcase.expectedReferences); diff != "" {
+			t.Errorf("Test %v failed. Received different references (received/wanted) %v", x, diff)
+		}
+		if diff := pretty.Compare(testRepo.ArgsIn[UpdatePolicyMethod][4], testcase.expectedStatements); testcase.expectedStatements != nil && diff != "" {
+			t.Errorf("Test %v failed. Received different statements (received/wanted) %v", x, diff)
+		}
+		if testcase.updatePolicyMethodResult != nil {
+			event := testRepo.ArgsIn[AddAuditEventMethod][0].(AuditEvent)
+			if event.Action != POLICY_ACTION_UPDATE_POLICY || event.Urn != testcase.updatePolicyMethodResult.Urn {
+				t.Errorf("Test %v failed. Rewritten policy not audited, last audit event %v", x, event)
+			}
+		}
 	}
 }
 
@@ -1851,7 +2066,7 @@ func TestAuthAPI_RemoveGroup(t *testing.T) {
 		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
 		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
 		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
-		testRepo.ArgsOut[GetGroupsByUserIDMethod][1] = testcase.getGroupsByUserIDError
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][2] = testcase.getGroupsByUserIDError
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
 		testRepo.ArgsOut[RemoveGroupMethod][0] = testcase.removeGroupMethodErr
 
@@ -1860,146 +2075,1057 @@ func TestAuthAPI_RemoveGroup(t *testing.T) {
 	}
 }
 
-func TestAuthAPI_AddMember(t *testing.T) {
+func TestAuthAPI_SetGroupMembershipRule(t *testing.T) {
+	group := &Group{
+		ID:   "GROUP-ID",
+		Name: "group1",
+		Org:  "org1",
+		Path: "/path/",
+		Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+	}
 	testcases := map[string]struct {
-		// API Method args
+		// API method args
 		requestInfo RequestInfo
-		userID      string
 		org         string
-		groupName   string
+		name        string
+		rule        *MembershipRule
 		// Expected result
-		wantError error
+		expectedResponse *Group
+		wantError        error
 		// Manager Results
-		getGroupsByUserIDResult   []Group
-		getAttachedPoliciesResult []Policy
-		getUserByExternalIDResult *User
-		getGroupByNameResult      *Group
-		isMemberOfGroupResult     bool
+		getGroupByNameResult         *Group
+		getUserByExternalIDResult    *User
+		setGroupMembershipRuleResult *Group
 		// Manager Errors
-		getUserByExternalIDMethodErr error
-		getGroupByNameMethodErr      error
-		addMemberMethodErr           error
-		isMemberOfGroupMethodErr     error
+		getGroupByNameMethodErr         error
+		setGroupMembershipRuleMethodErr error
 	}{
-		"OkCaseAdmin": {
+		"OkCase": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      true,
 			},
-			userID:    "12345",
-			org:       "org1",
-			groupName: "group1",
-			getUserByExternalIDResult: &User{
-				ID:         "543210",
-				ExternalID: "12345",
-				Path:       "/test/asd/",
+			org:  "org1",
+			name: "group1",
+			rule: &MembershipRule{
+				PathPrefix:     "/engineering/",
+				AttributeKey:   "team",
+				AttributeValue: "backend",
 			},
-			getGroupByNameResult: &Group{
-				ID:   "543210",
+			expectedResponse: &Group{
+				ID:   "GROUP-ID",
 				Name: "group1",
 				Org:  "org1",
-				Path: "/test/asd/",
-			},
-			isMemberOfGroupResult: false,
-		},
-		"OKCase": {
-			requestInfo: RequestInfo{
-				Identifier: "123456",
-				Admin:      false,
-			},
-			userID:    "12345",
-			org:       "org1",
-			groupName: "group1",
-			getGroupsByUserIDResult: []Group{
-				{
-					ID:   "GROUP-USER-ID",
-					Name: "groupUser",
-					Org:  "org1",
-					Path: "/path/",
-					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
-				},
-			},
-			getAttachedPoliciesResult: []Policy{
-				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "org1",
-					Path: "/path/",
-					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								"iam:*",
-							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
-								GetUrnPrefix("", RESOURCE_USER, ""),
-							},
-						},
-					},
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+				MembershipRule: &MembershipRule{
+					PathPrefix:     "/engineering/",
+					AttributeKey:   "team",
+					AttributeValue: "backend",
 				},
 			},
-			getUserByExternalIDResult: &User{
-				ID:         "543210",
-				ExternalID: "1234",
-				Path:       "/path/",
-				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
-			},
-			getGroupByNameResult: &Group{
-				ID:   "GROUP-USER-ID",
+			getGroupByNameResult: group,
+			setGroupMembershipRuleResult: &Group{
+				ID:   "GROUP-ID",
 				Name: "group1",
 				Org:  "org1",
 				Path: "/path/",
 				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+				MembershipRule: &MembershipRule{
+					PathPrefix:     "/engineering/",
+					AttributeKey:   "team",
+					AttributeValue: "backend",
+				},
 			},
-			isMemberOfGroupResult: false,
 		},
-		"ErrorCaseInvalidExternalID": {
+		"OkCaseRemoveRule": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      true,
 			},
-			userID:    "d*%$",
-			groupName: "group",
-			org:       "org1",
+			org:                          "org1",
+			name:                         "group1",
+			expectedResponse:             group,
+			getGroupByNameResult:         group,
+			setGroupMembershipRuleResult: group,
+		},
+		"ErrorCaseEmptyRule": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:  "org1",
+			name: "group1",
+			rule: &MembershipRule{},
 			wantError: &Error{
 				Code:    INVALID_PARAMETER_ERROR,
-				Message: "Invalid parameter: externalId d*%$",
-			},
-			getGroupByNameResult: &Group{
-				ID:   "GROUP-USER-ID",
-				Name: "group1",
-				Org:  "org1",
-				Path: "/path/1/",
-				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/1/", "group1"),
+				Message: "Invalid parameter: membership rule needs a path prefix or an attribute",
 			},
 		},
-		"ErrorCaseInvalidOrg": {
-			userID:    "12345",
-			groupName: "group1",
-			org:       "!^**$%&",
+		"ErrorCaseInvalidPathPrefix": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:  "org1",
+			name: "group1",
+			rule: &MembershipRule{
+				PathPrefix: "engineering",
+			},
 			wantError: &Error{
 				Code:    INVALID_PARAMETER_ERROR,
-				Message: "Invalid parameter: org !^**$%&",
+				Message: "Invalid parameter: pathPrefix engineering",
 			},
 		},
-		"ErrorCaseInvalidGroupName": {
-			userID:    "12345",
-			org:       "org1",
-			groupName: "d*%$",
+		"ErrorCaseInvalidAttributeKey": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:  "org1",
+			name: "group1",
+			rule: &MembershipRule{
+				AttributeKey:   "*team",
+				AttributeValue: "backend",
+			},
 			wantError: &Error{
 				Code:    INVALID_PARAMETER_ERROR,
-				Message: "Invalid parameter: name d*%$",
+				Message: "Invalid parameter: attributeKey *team",
 			},
 		},
-		"ErrorCaseGroupNotFound": {
-			userID:    "12345",
-			org:       "org1",
-			groupName: "group1",
-			wantError: &Error{
-				Code: GROUP_BY_ORG_AND_NAME_NOT_FOUND,
-			},
+		"ErrorCaseAttributeKeyWithoutValue": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:  "org1",
+			name: "group1",
+			rule: &MembershipRule{
+				AttributeKey: "team",
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: attributeValue ",
+			},
+		},
+		"ErrorCaseGroupNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:  "org1",
+			name: "group1",
+			rule: &MembershipRule{
+				PathPrefix: "/engineering/",
+			},
+			wantError: &Error{
+				Code: GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+			},
+			getGroupByNameMethodErr: &database.Error{
+				Code: database.GROUP_NOT_FOUND,
+			},
+		},
+		"ErrorCaseNotAllowed": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			org:  "org1",
+			name: "group1",
+			rule: &MembershipRule{
+				PathPrefix: "/engineering/",
+			},
+			wantError: &Error{
+				Code: UNAUTHORIZED_RESOURCES_ERROR,
+				Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+					"123456", CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1")),
+			},
+			getGroupByNameResult: group,
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "123456",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "123456"),
+			},
+		},
+		"ErrorCasePreconditionFailed": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+				IfMatch:    `"outdated"`,
+			},
+			org:  "org1",
+			name: "group1",
+			rule: &MembershipRule{
+				PathPrefix: "/engineering/",
+			},
+			wantError: &Error{
+				Code: PRECONDITION_FAILED_ERROR,
+				Message: fmt.Sprintf("Resource %v has been modified, entity tag %v doesn't match current entity tag %v",
+					group.Urn, `"outdated"`, ETag(*group)),
+			},
+			getGroupByNameResult: group,
+		},
+		"ErrorCaseSetGroupMembershipRuleDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:  "org1",
+			name: "group1",
+			rule: &MembershipRule{
+				PathPrefix: "/engineering/",
+			},
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getGroupByNameResult: group,
+			setGroupMembershipRuleMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetGroupByNameMethod][0] = test.getGroupByNameResult
+		testRepo.ArgsOut[GetGroupByNameMethod][1] = test.getGroupByNameMethodErr
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = test.getUserByExternalIDResult
+		testRepo.ArgsOut[SetGroupMembershipRuleMethod][0] = test.setGroupMembershipRuleResult
+		testRepo.ArgsOut[SetGroupMembershipRuleMethod][1] = test.setGroupMembershipRuleMethodErr
+
+		group, err := testAPI.SetGroupMembershipRule(test.requestInfo, test.org, test.name, test.rule)
+		checkMethodResponse(t, n, test.wantError, err, test.expectedResponse, group)
+		if test.wantError == nil {
+			if diff := pretty.Compare(testRepo.ArgsIn[SetGroupMembershipRuleMethod][1], test.rule); diff != "" {
+				t.Errorf("Test %v failed. Received different rules (received/wanted) %v", n, diff)
+			}
+		}
+	}
+}
+
+func TestMembershipRule_Matches(t *testing.T) {
+	testcases := map[string]struct {
+		rule     MembershipRule
+		user     User
+		expected bool
+	}{
+		"OkCasePathPrefix": {
+			rule: MembershipRule{
+				PathPrefix: "/engineering/",
+			},
+			user: User{
+				Path: "/engineering/backend/",
+			},
+			expected: true,
+		},
+		"OkCaseAttribute": {
+			rule: MembershipRule{
+				AttributeKey:   "team",
+				AttributeValue: "backend",
+			},
+			user: User{
+				Path: "/engineering/",
+				Attributes: map[string]string{
+					"team": "backend",
+				},
+			},
+			expected: true,
+		},
+		"OkCasePathPrefixMismatch": {
+			rule: MembershipRule{
+				PathPrefix:     "/sales/",
+				AttributeKey:   "team",
+				AttributeValue: "backend",
+			},
+			user: User{
+				Path: "/engineering/",
+				Attributes: map[string]string{
+					"team": "backend",
+				},
+			},
+			expected: false,
+		},
+		"OkCaseAttributeMismatch": {
+			rule: MembershipRule{
+				PathPrefix:     "/engineering/",
+				AttributeKey:   "team",
+				AttributeValue: "backend",
+			},
+			user: User{
+				Path: "/engineering/",
+			},
+			expected: false,
+		},
+	}
+
+	for n, test := range testcases {
+		if matches := test.rule.Matches(test.user); matches != test.expected {
+			t.Errorf("Test %v failed. Received %v, wanted %v", n, matches, test.expected)
+		}
+	}
+}
+
+func TestAuthAPI_AddMember(t *testing.T) {
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		userID      string
+		org         string
+		groupName   string
+		// Expected result
+		wantError error
+		// Manager Results
+		getGroupsByUserIDResult   []Group
+		getAttachedPoliciesResult []Policy
+		getUserByExternalIDResult *User
+		getGroupByNameResult      *Group
+		isMemberOfGroupResult     bool
+		// Manager Errors
+		getUserByExternalIDMethodErr error
+		getGroupByNameMethodErr      error
+		addMemberMethodErr           error
+		isMemberOfGroupMethodErr     error
+	}{
+		"OkCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			userID:    "12345",
+			org:       "org1",
+			groupName: "group1",
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "12345",
+				Path:       "/test/asd/",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "543210",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/test/asd/",
+			},
+			isMemberOfGroupResult: false,
+		},
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			userID:    "12345",
+			org:       "org1",
+			groupName: "group1",
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Org:  "org1",
+					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesResult: []Policy{
+				{
+					ID:   "POLICY-USER-ID",
+					Name: "policyUser",
+					Org:  "org1",
+					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+					Statements: &[]Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								"iam:*",
+							},
+							Resources: []string{
+								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+								GetUrnPrefix("", RESOURCE_USER, ""),
+							},
+						},
+					},
+				},
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-USER-ID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			isMemberOfGroupResult: false,
+		},
+		"OkCaseOwner": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			userID:    "12345",
+			org:       "org1",
+			groupName: "group1",
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "12345",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "12345"),
+			},
+			getGroupByNameResult: &Group{
+				ID:     "GROUP-USER-ID",
+				Name:   "group1",
+				Org:    "org1",
+				Path:   "/path/",
+				Urn:    CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+				Owners: []string{"123456"},
+			},
+			isMemberOfGroupResult: false,
+		},
+		"ErrorCaseOwnerSuspended": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			userID:    "12345",
+			org:       "org1",
+			groupName: "group1",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:org1:group/path/group1",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "12345",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "12345"),
+				Status:     USER_STATUS_SUSPENDED,
+			},
+			getGroupByNameResult: &Group{
+				ID:     "GROUP-USER-ID",
+				Name:   "group1",
+				Org:    "org1",
+				Path:   "/path/",
+				Urn:    CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+				Owners: []string{"123456"},
+			},
+		},
+		"ErrorCaseOwnerDenied": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			userID:    "12345",
+			org:       "org1",
+			groupName: "group1",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam::user/path/12345",
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Org:  "org1",
+					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesResult: []Policy{
+				{
+					ID:   "POLICY-USER-ID",
+					Name: "policyUser",
+					Org:  "org1",
+					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+					Statements: &[]Statement{
+						{
+							Effect: "deny",
+							Actions: []string{
+								USER_ACTION_GET_USER,
+							},
+							Resources: []string{
+								GetUrnPrefix("", RESOURCE_USER, "/path/"),
+							},
+						},
+					},
+				},
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "12345",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "12345"),
+			},
+			getGroupByNameResult: &Group{
+				ID:     "GROUP-USER-ID",
+				Name:   "group1",
+				Org:    "org1",
+				Path:   "/path/",
+				Urn:    CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+				Owners: []string{"123456"},
+			},
+		},
+		"ErrorCaseInvalidExternalID": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			userID:    "d*%$",
+			groupName: "group",
+			org:       "org1",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: externalId d*%$",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-USER-ID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/1/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/1/", "group1"),
+			},
+		},
+		"ErrorCaseInvalidOrg": {
+			userID:    "12345",
+			groupName: "group1",
+			org:       "!^**$%&",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: org !^**$%&",
+			},
+		},
+		"ErrorCaseInvalidGroupName": {
+			userID:    "12345",
+			org:       "org1",
+			groupName: "d*%$",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: name d*%$",
+			},
+		},
+		"ErrorCaseGroupNotFound": {
+			userID:    "12345",
+			org:       "org1",
+			groupName: "group1",
+			wantError: &Error{
+				Code: GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+			},
+			getGroupByNameMethodErr: &database.Error{
+				Code: database.GROUP_NOT_FOUND,
+			},
+		},
+		"ErrorCaseUnauthorizedUser": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			userID:    "12345",
+			org:       "org1",
+			groupName: "group1",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Authenticated user with externalId 123456 not found. Unable to retrieve permissions.",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-USER-ID",
+				Name: "groupUser",
+				Org:  "org1",
+				Path: "/path/1/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code: database.USER_NOT_FOUND,
+			},
+		},
+		"ErrorCaseUnauthorizedResource": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			userID:    "12345",
+			org:       "org1",
+			groupName: "group1",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:org1:group/path/1/group1",
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Org:  "org1",
+					Path: "/path/1/",
+				},
+			},
+			getAttachedPoliciesResult: []Policy{
+				{
+					ID:   "POLICY-USER-ID",
+					Name: "policyUser",
+					Org:  "org1",
+					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+					Statements: &[]Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								GROUP_ACTION_GET_GROUP,
+							},
+							Resources: []string{
+								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+							},
+						},
+					},
+				},
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-USER-ID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/1/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/1/", "group1"),
+			},
+		},
+		"ErrorCaseDenyAddMember": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			userID:    "12345",
+			org:       "org1",
+			groupName: "group1",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:org1:group/path/group1",
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Org:  "org1",
+					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesResult: []Policy{
+				{
+					ID:   "POLICY-USER-ID",
+					Name: "policyUser",
+					Org:  "org1",
+					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+					Statements: &[]Statement{
+						{
+							Effect: "deny",
+							Actions: []string{
+								GROUP_ACTION_ADD_MEMBER,
+							},
+							Resources: []string{
+								GetUrnPrefix("org1", RESOURCE_GROUP, "/path/"),
+							},
+						},
+						{
+							Effect: "allow",
+							Actions: []string{
+								"iam:*",
+							},
+							Resources: []string{
+								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+								GetUrnPrefix("", RESOURCE_USER, ""),
+							},
+						},
+					},
+				},
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-USER-ID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+		},
+		"ErrorCaseNoPermissions": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			userID:    "12345",
+			org:       "org1",
+			groupName: "group1",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:org1:group/path/group1",
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Org:  "org1",
+					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesResult: []Policy{
+				{
+					ID:         "POLICY-USER-ID",
+					Name:       "policyUser",
+					Org:        "org1",
+					Path:       "/path/",
+					Urn:        CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+					Statements: &[]Statement{},
+				},
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-USER-ID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			userID:    "12345",
+			org:       "org1",
+			groupName: "group1",
+			wantError: &Error{
+				Code: USER_BY_EXTERNAL_ID_NOT_FOUND,
+			},
+			getGroupByNameResult: &Group{
+				ID:   "543210",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/test/asd/",
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code: database.USER_NOT_FOUND,
+			},
+		},
+		"ErrorCaseIsAlreadyMember": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			userID:    "12345",
+			org:       "org1",
+			groupName: "group1",
+			wantError: &Error{
+				Code:    USER_IS_ALREADY_A_MEMBER_OF_GROUP,
+				Message: "User: 12345 is already a member of Group: group1",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "12345",
+				Path:       "/test/asd/",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "543210",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/test/asd/",
+			},
+			isMemberOfGroupResult: true,
+		},
+		"ErrorCaseIsMemberDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			userID:    "12345",
+			org:       "org1",
+			groupName: "group1",
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "12345",
+				Path:       "/test/asd/",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "543210",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/test/asd/",
+			},
+			isMemberOfGroupMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+		"ErrorCaseGroupHasMembershipRule": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			userID:    "12345",
+			org:       "org1",
+			groupName: "group1",
+			wantError: &Error{
+				Code:    GROUP_HAS_MEMBERSHIP_RULE,
+				Message: "Members of group with org org1 and name group1 are defined by its membership rule",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "12345",
+				Path:       "/test/asd/",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "543210",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/test/asd/",
+				MembershipRule: &MembershipRule{
+					PathPrefix: "/test/",
+				},
+			},
+			isMemberOfGroupResult: false,
+		},
+		"ErrorCaseAddMemberDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			userID:    "12345",
+			org:       "org1",
+			groupName: "group1",
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "12345",
+				Path:       "/test/asd/",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "543210",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/test/asd/",
+			},
+			isMemberOfGroupResult: false,
+			addMemberMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[AddMemberMethod][0] = testcase.addMemberMethodErr
+		testRepo.ArgsOut[GetGroupByNameMethod][0] = testcase.getGroupByNameResult
+		testRepo.ArgsOut[GetGroupByNameMethod][1] = testcase.getGroupByNameMethodErr
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
+		testRepo.ArgsOut[IsMemberOfGroupMethod][0] = testcase.isMemberOfGroupResult
+		testRepo.ArgsOut[IsMemberOfGroupMethod][1] = testcase.isMemberOfGroupMethodErr
+
+		err := testAPI.AddMember(testcase.requestInfo, testcase.userID, testcase.groupName, testcase.org)
+		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+	}
+}
+
+func TestAuthAPI_RemoveMember(t *testing.T) {
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		userID      string
+		groupName   string
+		org         string
+		// Expected result
+		wantError error
+		// Manager Results
+		getGroupByNameResult      *Group
+		getUserByExternalIDResult *User
+		getGroupsByUserIDResult   []Group
+		getAttachedPoliciesResult []Policy
+		isMemberOfGroupResult     bool
+		// Manager Errors
+		getGroupByNameMethodErr      error
+		getUserByExternalIDMethodErr error
+		isMemberOfGroupMethodErr     error
+		removeMemberMethodErr        error
+	}{
+		"OkCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			userID:    "12345",
+			groupName: "group1",
+			org:       "org1",
+			getGroupByNameResult: &Group{
+				ID:   "543210",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/test/",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "123456",
+				ExternalID: "12345",
+				Path:       "/test/",
+			},
+			isMemberOfGroupResult: true,
+		},
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			userID:    "12345",
+			groupName: "group1",
+			org:       "org1",
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-USER-ID",
+				Name: "groupUser",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Org:  "org1",
+					Path: "/path/1/",
+				},
+			},
+			getAttachedPoliciesResult: []Policy{
+				{
+					ID:   "POLICY-USER-ID",
+					Name: "policyUser",
+					Org:  "org1",
+					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+					Statements: &[]Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								GROUP_ACTION_REMOVE_MEMBER,
+								GROUP_ACTION_GET_GROUP,
+							},
+							Resources: []string{
+								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+							},
+						},
+						{
+							Effect: "allow",
+							Actions: []string{
+								USER_ACTION_GET_USER,
+							},
+							Resources: []string{
+								GetUrnPrefix("", RESOURCE_USER, ""),
+							},
+						},
+					},
+				},
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			isMemberOfGroupResult: true,
+		},
+		"OkCaseOwner": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			userID:    "12345",
+			org:       "org1",
+			groupName: "group1",
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "12345",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "12345"),
+			},
+			getGroupByNameResult: &Group{
+				ID:     "GROUP-USER-ID",
+				Name:   "group1",
+				Org:    "org1",
+				Path:   "/path/",
+				Urn:    CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+				Owners: []string{"123456"},
+			},
+			isMemberOfGroupResult: true,
+		},
+		"ErrorCaseInvalidExternalID": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			userID:    "$%&",
+			org:       "org1",
+			groupName: "group1",
+			getGroupByNameResult: &Group{
+				ID:   "543210",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/test/",
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: externalId $%&",
+			},
+		},
+		"ErrorCaseInvalidOrg": {
+			userID:    "12345",
+			org:       "$**^%&!",
+			groupName: "group1",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: org $**^%&!",
+			},
+		},
+		"ErrorCaseInvalidName": {
+			userID:    "12345",
+			org:       "org1",
+			groupName: "$%&",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: name $%&",
+			},
+		},
+		"ErrorCaseGroupNotFound": {
+			userID:    "12345",
+			org:       "org1",
+			groupName: "group1",
+			wantError: &Error{
+				Code: GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+			},
 			getGroupByNameMethodErr: &database.Error{
 				Code: database.GROUP_NOT_FOUND,
 			},
@@ -2027,17 +3153,78 @@ func TestAuthAPI_AddMember(t *testing.T) {
 				Code: database.USER_NOT_FOUND,
 			},
 		},
-		"ErrorCaseUnauthorizedResource": {
+		"ErrorCaseUnauthorizedRemoveMember": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			userID:    "12345",
+			groupName: "group1",
+			org:       "org1",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:org1:group/path/group1",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-USER-ID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Org:  "org1",
+					Path: "/path/1/",
+				},
+			},
+			getAttachedPoliciesResult: []Policy{
+				{
+					ID:   "POLICY-USER-ID",
+					Name: "policyUser",
+					Org:  "org1",
+					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+					Statements: &[]Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								GROUP_ACTION_GET_GROUP,
+							},
+							Resources: []string{
+								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+							},
+						},
+					},
+				},
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+		},
+		"ErrorCaseUnauthorizedGetUser": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      false,
 			},
-			userID:    "12345",
-			org:       "org1",
+			userID:    "1234",
 			groupName: "group1",
+			org:       "org1",
 			wantError: &Error{
 				Code:    UNAUTHORIZED_RESOURCES_ERROR,
-				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:org1:group/path/1/group1",
+				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam::user/path/1234",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-USER-ID",
+				Name: "groupUser",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
 			},
 			getGroupsByUserIDResult: []Group{
 				{
@@ -2059,11 +3246,21 @@ func TestAuthAPI_AddMember(t *testing.T) {
 							Effect: "allow",
 							Actions: []string{
 								GROUP_ACTION_GET_GROUP,
+								GROUP_ACTION_REMOVE_MEMBER,
 							},
 							Resources: []string{
 								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
 							},
 						},
+						{
+							Effect: "deny",
+							Actions: []string{
+								USER_ACTION_GET_USER,
+							},
+							Resources: []string{
+								GetUrnPrefix("", RESOURCE_USER, ""),
+							},
+						},
 					},
 				},
 			},
@@ -2073,33 +3270,32 @@ func TestAuthAPI_AddMember(t *testing.T) {
 				Path:       "/path/",
 				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
 			},
-			getGroupByNameResult: &Group{
-				ID:   "GROUP-USER-ID",
-				Name: "group1",
-				Org:  "org1",
-				Path: "/path/1/",
-				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/1/", "group1"),
-			},
 		},
-		"ErrorCaseDenyAddMember": {
+		"ErrorCaseDenyRemoveMember": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      false,
 			},
 			userID:    "12345",
-			org:       "org1",
 			groupName: "group1",
+			org:       "org1",
 			wantError: &Error{
 				Code:    UNAUTHORIZED_RESOURCES_ERROR,
-				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:org1:group/path/group1",
+				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:org1:group/path/groupUser",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-USER-ID",
+				Name: "groupUser",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
 			},
 			getGroupsByUserIDResult: []Group{
 				{
 					ID:   "GROUP-USER-ID",
 					Name: "groupUser",
 					Org:  "org1",
-					Path: "/path/",
-					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
+					Path: "/path/1/",
 				},
 			},
 			getAttachedPoliciesResult: []Policy{
@@ -2111,22 +3307,22 @@ func TestAuthAPI_AddMember(t *testing.T) {
 					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
 					Statements: &[]Statement{
 						{
-							Effect: "deny",
+							Effect: "allow",
 							Actions: []string{
-								GROUP_ACTION_ADD_MEMBER,
+								GROUP_ACTION_GET_GROUP,
+								GROUP_ACTION_REMOVE_MEMBER,
 							},
 							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, "/path/"),
+								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
 							},
 						},
 						{
-							Effect: "allow",
+							Effect: "deny",
 							Actions: []string{
-								"iam:*",
+								GROUP_ACTION_REMOVE_MEMBER,
 							},
 							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
-								GetUrnPrefix("", RESOURCE_USER, ""),
+								GetUrnPrefix("org1", RESOURCE_GROUP, "/path/"),
 							},
 						},
 					},
@@ -2138,13 +3334,6 @@ func TestAuthAPI_AddMember(t *testing.T) {
 				Path:       "/path/",
 				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
 			},
-			getGroupByNameResult: &Group{
-				ID:   "GROUP-USER-ID",
-				Name: "group1",
-				Org:  "org1",
-				Path: "/path/",
-				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
-			},
 		},
 		"ErrorCaseNoPermissions": {
 			requestInfo: RequestInfo{
@@ -2152,19 +3341,25 @@ func TestAuthAPI_AddMember(t *testing.T) {
 				Admin:      false,
 			},
 			userID:    "12345",
-			org:       "org1",
 			groupName: "group1",
+			org:       "org1",
 			wantError: &Error{
 				Code:    UNAUTHORIZED_RESOURCES_ERROR,
-				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:org1:group/path/group1",
+				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:org1:group/path/groupUser",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-USER-ID",
+				Name: "groupUser",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
 			},
 			getGroupsByUserIDResult: []Group{
 				{
 					ID:   "GROUP-USER-ID",
 					Name: "groupUser",
 					Org:  "org1",
-					Path: "/path/",
-					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
+					Path: "/path/1/",
 				},
 			},
 			getAttachedPoliciesResult: []Policy{
@@ -2183,13 +3378,6 @@ func TestAuthAPI_AddMember(t *testing.T) {
 				Path:       "/path/",
 				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
 			},
-			getGroupByNameResult: &Group{
-				ID:   "GROUP-USER-ID",
-				Name: "group1",
-				Org:  "org1",
-				Path: "/path/",
-				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
-			},
 		},
 		"ErrorCaseUserNotFound": {
 			requestInfo: RequestInfo{
@@ -2197,47 +3385,78 @@ func TestAuthAPI_AddMember(t *testing.T) {
 				Admin:      true,
 			},
 			userID:    "12345",
-			org:       "org1",
 			groupName: "group1",
+			org:       "org1",
 			wantError: &Error{
 				Code: USER_BY_EXTERNAL_ID_NOT_FOUND,
 			},
 			getGroupByNameResult: &Group{
-				ID:   "543210",
-				Name: "group1",
+				ID:   "GROUP-USER-ID",
+				Name: "groupUser",
 				Org:  "org1",
-				Path: "/test/asd/",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
 			},
 			getUserByExternalIDMethodErr: &database.Error{
 				Code: database.USER_NOT_FOUND,
 			},
 		},
-		"ErrorCaseIsAlreadyMember": {
+		"ErrorCaseIsMemberDBErr": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      true,
 			},
 			userID:    "12345",
-			org:       "org1",
 			groupName: "group1",
+			org:       "org1",
 			wantError: &Error{
-				Code:    USER_IS_ALREADY_A_MEMBER_OF_GROUP,
-				Message: "User: 12345 is already a member of Group: group1",
+				Code: UNKNOWN_API_ERROR,
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-USER-ID",
+				Name: "groupUser",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
 			},
 			getUserByExternalIDResult: &User{
 				ID:         "543210",
-				ExternalID: "12345",
-				Path:       "/test/asd/",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			isMemberOfGroupMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+		"ErrorCaseIsNotMember": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			userID:    "1234",
+			groupName: "group1",
+			org:       "org1",
+			wantError: &Error{
+				Code:    USER_IS_NOT_A_MEMBER_OF_GROUP,
+				Message: "User with externalId 1234 is not a member of group with org org1 and name groupUser",
 			},
 			getGroupByNameResult: &Group{
-				ID:   "543210",
-				Name: "group1",
+				ID:   "GROUP-USER-ID",
+				Name: "groupUser",
 				Org:  "org1",
-				Path: "/test/asd/",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
 			},
-			isMemberOfGroupResult: true,
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			isMemberOfGroupResult: false,
 		},
-		"ErrorCaseIsMemberDBErr": {
+		"ErrorCaseGroupHasMembershipRule": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      true,
@@ -2246,7 +3465,8 @@ func TestAuthAPI_AddMember(t *testing.T) {
 			org:       "org1",
 			groupName: "group1",
 			wantError: &Error{
-				Code: UNKNOWN_API_ERROR,
+				Code:    GROUP_HAS_MEMBERSHIP_RULE,
+				Message: "Members of group with org org1 and name group1 are defined by its membership rule",
 			},
 			getUserByExternalIDResult: &User{
 				ID:         "543210",
@@ -2258,35 +3478,38 @@ func TestAuthAPI_AddMember(t *testing.T) {
 				Name: "group1",
 				Org:  "org1",
 				Path: "/test/asd/",
+				MembershipRule: &MembershipRule{
+					PathPrefix: "/test/",
+				},
 			},
-			isMemberOfGroupMethodErr: &database.Error{
-				Code: database.INTERNAL_ERROR,
-			},
+			isMemberOfGroupResult: true,
 		},
-		"ErrorCaseAddMemberDBErr": {
+		"ErrorCaseRemoveMemberDBErr": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      true,
 			},
 			userID:    "12345",
-			org:       "org1",
 			groupName: "group1",
+			org:       "org1",
 			wantError: &Error{
 				Code: UNKNOWN_API_ERROR,
 			},
-			getUserByExternalIDResult: &User{
-				ID:         "543210",
-				ExternalID: "12345",
-				Path:       "/test/asd/",
-			},
 			getGroupByNameResult: &Group{
-				ID:   "543210",
-				Name: "group1",
+				ID:   "GROUP-USER-ID",
+				Name: "groupUser",
 				Org:  "org1",
-				Path: "/test/asd/",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
 			},
-			isMemberOfGroupResult: false,
-			addMemberMethodErr: &database.Error{
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			isMemberOfGroupResult: true,
+			removeMemberMethodErr: &database.Error{
 				Code: database.INTERNAL_ERROR,
 			},
 		},
@@ -2296,78 +3519,106 @@ func TestAuthAPI_AddMember(t *testing.T) {
 		testRepo := makeTestRepo()
 		testAPI := makeTestAPI(testRepo)
 
-		testRepo.ArgsOut[AddMemberMethod][0] = testcase.addMemberMethodErr
 		testRepo.ArgsOut[GetGroupByNameMethod][0] = testcase.getGroupByNameResult
 		testRepo.ArgsOut[GetGroupByNameMethod][1] = testcase.getGroupByNameMethodErr
+		testRepo.ArgsOut[IsMemberOfGroupMethod][0] = testcase.isMemberOfGroupResult
+		testRepo.ArgsOut[IsMemberOfGroupMethod][1] = testcase.isMemberOfGroupMethodErr
+		testRepo.ArgsOut[RemoveMemberMethod][0] = testcase.removeMemberMethodErr
 		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
 		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
 		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
-		testRepo.ArgsOut[IsMemberOfGroupMethod][0] = testcase.isMemberOfGroupResult
-		testRepo.ArgsOut[IsMemberOfGroupMethod][1] = testcase.isMemberOfGroupMethodErr
 
-		err := testAPI.AddMember(testcase.requestInfo, testcase.userID, testcase.groupName, testcase.org)
+		err := testAPI.RemoveMember(testcase.requestInfo, testcase.userID, testcase.groupName, testcase.org)
 		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
 	}
 }
 
-func TestAuthAPI_RemoveMember(t *testing.T) {
+func TestAuthAPI_ListMembers(t *testing.T) {
 	testcases := map[string]struct {
 		// API Method args
 		requestInfo RequestInfo
-		userID      string
-		groupName   string
+		filter      *Filter
 		org         string
+		groupName   string
 		// Expected result
-		wantError error
+		expectedTotal   int
+		expectedMembers []string
+		wantError       error
 		// Manager Results
+		getGroupMembersTotal      int
 		getGroupByNameResult      *Group
-		getUserByExternalIDResult *User
+		getGroupMembersResult     []User
 		getGroupsByUserIDResult   []Group
 		getAttachedPoliciesResult []Policy
-		isMemberOfGroupResult     bool
-		// Manager Errors
+		getUserByExternalIDResult *User
+		// API Errors
 		getGroupByNameMethodErr      error
 		getUserByExternalIDMethodErr error
-		isMemberOfGroupMethodErr     error
-		removeMemberMethodErr        error
+		getGroupMembersMethodErr     error
 	}{
 		"OkCaseAdmin": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      true,
 			},
-			userID:    "12345",
-			groupName: "group1",
-			org:       "org1",
+			expectedTotal:        2,
+			getGroupMembersTotal: 2,
+			org:                  "org1",
+			groupName:            "group1",
+			expectedMembers: []string{
+				"member1",
+				"member2",
+			},
 			getGroupByNameResult: &Group{
 				ID:   "543210",
 				Name: "group1",
 				Org:  "org1",
 				Path: "/test/",
 			},
-			getUserByExternalIDResult: &User{
-				ID:         "123456",
-				ExternalID: "12345",
-				Path:       "/test/",
+			getGroupMembersResult: []User{
+				{
+					ID:         "12345",
+					ExternalID: "member1",
+					Path:       "/test/",
+				},
+				{
+					ID:         "123456",
+					ExternalID: "member2",
+					Path:       "/test/",
+				},
 			},
-			isMemberOfGroupResult: true,
 		},
 		"OKCase": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      false,
 			},
-			userID:    "12345",
-			groupName: "group1",
 			org:       "org1",
+			groupName: "group1",
+			expectedMembers: []string{
+				"member1",
+				"member2",
+			},
 			getGroupByNameResult: &Group{
 				ID:   "GROUP-USER-ID",
 				Name: "groupUser",
 				Org:  "org1",
-				Path: "/path/",
+				Path: "/path/1/",
 				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
 			},
+			getGroupMembersResult: []User{
+				{
+					ID:         "12345",
+					ExternalID: "member1",
+					Path:       "/test/",
+				},
+				{
+					ID:         "123456",
+					ExternalID: "member2",
+					Path:       "/test/",
+				},
+			},
 			getGroupsByUserIDResult: []Group{
 				{
 					ID:   "GROUP-USER-ID",
@@ -2387,20 +3638,11 @@ func TestAuthAPI_RemoveMember(t *testing.T) {
 						{
 							Effect: "allow",
 							Actions: []string{
-								GROUP_ACTION_REMOVE_MEMBER,
+								GROUP_ACTION_LIST_MEMBERS,
 								GROUP_ACTION_GET_GROUP,
 							},
 							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
-							},
-						},
-						{
-							Effect: "allow",
-							Actions: []string{
-								USER_ACTION_GET_USER,
-							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, ""),
+								GetUrnPrefix("org1", RESOURCE_GROUP, "/path/"),
 							},
 						},
 					},
@@ -2412,144 +3654,98 @@ func TestAuthAPI_RemoveMember(t *testing.T) {
 				Path:       "/path/",
 				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
 			},
-			isMemberOfGroupResult: true,
 		},
-		"ErrorCaseInvalidExternalID": {
+		"ErrorCaseInvalidName": {
+			org:       "org1",
+			groupName: "*%$",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: name *%$",
+			},
+		},
+		"OkCaseOwner": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
-				Admin:      true,
+				Admin:      false,
+			},
+			expectedTotal:        1,
+			getGroupMembersTotal: 1,
+			org:                  "org1",
+			groupName:            "group1",
+			expectedMembers: []string{
+				"member1",
 			},
-			userID:    "$%&",
-			org:       "org1",
-			groupName: "group1",
 			getGroupByNameResult: &Group{
-				ID:   "543210",
-				Name: "group1",
-				Org:  "org1",
-				Path: "/test/",
+				ID:     "543210",
+				Name:   "group1",
+				Org:    "org1",
+				Path:   "/test/",
+				Urn:    CreateUrn("org1", RESOURCE_GROUP, "/test/", "group1"),
+				Owners: []string{"123456"},
 			},
-			wantError: &Error{
-				Code:    INVALID_PARAMETER_ERROR,
-				Message: "Invalid parameter: externalId $%&",
+			getGroupMembersResult: []User{
+				{
+					ID:         "12345",
+					ExternalID: "member1",
+					Path:       "/test/",
+				},
 			},
-		},
-		"ErrorCaseInvalidOrg": {
-			userID:    "12345",
-			org:       "$**^%&!",
-			groupName: "group1",
-			wantError: &Error{
-				Code:    INVALID_PARAMETER_ERROR,
-				Message: "Invalid parameter: org $**^%&!",
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "123456",
+				Path:       "/test/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/test/", "123456"),
 			},
 		},
-		"ErrorCaseInvalidName": {
-			userID:    "12345",
-			org:       "org1",
-			groupName: "$%&",
+		"ErrorCaseInvalidOrg": {
+			org:       "!^**$%&",
+			groupName: "g1",
 			wantError: &Error{
 				Code:    INVALID_PARAMETER_ERROR,
-				Message: "Invalid parameter: name $%&",
+				Message: "Invalid parameter: org !^**$%&",
 			},
 		},
 		"ErrorCaseGroupNotFound": {
-			userID:    "12345",
 			org:       "org1",
 			groupName: "group1",
-			wantError: &Error{
-				Code: GROUP_BY_ORG_AND_NAME_NOT_FOUND,
-			},
 			getGroupByNameMethodErr: &database.Error{
 				Code: database.GROUP_NOT_FOUND,
 			},
-		},
-		"ErrorCaseUnauthorizedUser": {
-			requestInfo: RequestInfo{
-				Identifier: "123456",
-				Admin:      false,
-			},
-			userID:    "12345",
-			org:       "org1",
-			groupName: "group1",
 			wantError: &Error{
-				Code:    UNAUTHORIZED_RESOURCES_ERROR,
-				Message: "Authenticated user with externalId 123456 not found. Unable to retrieve permissions.",
-			},
-			getGroupByNameResult: &Group{
-				ID:   "GROUP-USER-ID",
-				Name: "groupUser",
-				Org:  "org1",
-				Path: "/path/1/",
-				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
-			},
-			getUserByExternalIDMethodErr: &database.Error{
-				Code: database.USER_NOT_FOUND,
+				Code: GROUP_BY_ORG_AND_NAME_NOT_FOUND,
 			},
 		},
-		"ErrorCaseUnauthorizedRemoveMember": {
+		"ErrorCaseNotAuthenticatedUser": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      false,
 			},
-			userID:    "12345",
-			groupName: "group1",
 			org:       "org1",
+			groupName: "group1",
 			wantError: &Error{
 				Code:    UNAUTHORIZED_RESOURCES_ERROR,
-				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:org1:group/path/group1",
+				Message: "Authenticated user with externalId 123456 not found. Unable to retrieve permissions.",
 			},
 			getGroupByNameResult: &Group{
-				ID:   "GROUP-USER-ID",
 				Name: "group1",
 				Org:  "org1",
 				Path: "/path/",
 				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
 			},
-			getGroupsByUserIDResult: []Group{
-				{
-					ID:   "GROUP-USER-ID",
-					Name: "groupUser",
-					Org:  "org1",
-					Path: "/path/1/",
-				},
-			},
-			getAttachedPoliciesResult: []Policy{
-				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "org1",
-					Path: "/path/",
-					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
-							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
-							},
-						},
-					},
-				},
-			},
-			getUserByExternalIDResult: &User{
-				ID:         "543210",
-				ExternalID: "1234",
-				Path:       "/path/",
-				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			getUserByExternalIDMethodErr: &database.Error{
+				Code: database.USER_NOT_FOUND,
 			},
 		},
-		"ErrorCaseUnauthorizedGetUser": {
+		"ErrorCaseUnauthorizedResource": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      false,
 			},
-			userID:    "1234",
-			groupName: "group1",
 			org:       "org1",
+			groupName: "group1",
 			wantError: &Error{
 				Code:    UNAUTHORIZED_RESOURCES_ERROR,
-				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam::user/path/1234",
+				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:org1:group/path/groupUser",
 			},
 			getGroupByNameResult: &Group{
 				ID:   "GROUP-USER-ID",
@@ -2578,21 +3774,11 @@ func TestAuthAPI_RemoveMember(t *testing.T) {
 							Effect: "allow",
 							Actions: []string{
 								GROUP_ACTION_GET_GROUP,
-								GROUP_ACTION_REMOVE_MEMBER,
 							},
 							Resources: []string{
 								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
 							},
 						},
-						{
-							Effect: "deny",
-							Actions: []string{
-								USER_ACTION_GET_USER,
-							},
-							Resources: []string{
-								GetUrnPrefix("", RESOURCE_USER, ""),
-							},
-						},
 					},
 				},
 			},
@@ -2603,14 +3789,13 @@ func TestAuthAPI_RemoveMember(t *testing.T) {
 				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
 			},
 		},
-		"ErrorCaseDenyRemoveMember": {
+		"ErrorCaseDenyListMembers": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      false,
 			},
-			userID:    "12345",
-			groupName: "group1",
 			org:       "org1",
+			groupName: "group1",
 			wantError: &Error{
 				Code:    UNAUTHORIZED_RESOURCES_ERROR,
 				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:org1:group/path/groupUser",
@@ -2619,7 +3804,7 @@ func TestAuthAPI_RemoveMember(t *testing.T) {
 				ID:   "GROUP-USER-ID",
 				Name: "groupUser",
 				Org:  "org1",
-				Path: "/path/",
+				Path: "/path/1/",
 				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
 			},
 			getGroupsByUserIDResult: []Group{
@@ -2639,22 +3824,22 @@ func TestAuthAPI_RemoveMember(t *testing.T) {
 					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
 					Statements: &[]Statement{
 						{
-							Effect: "allow",
+							Effect: "deny",
 							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
-								GROUP_ACTION_REMOVE_MEMBER,
+								GROUP_ACTION_LIST_MEMBERS,
 							},
 							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
+								GetUrnPrefix("org1", RESOURCE_GROUP, "/path/"),
 							},
 						},
 						{
-							Effect: "deny",
+							Effect: "allow",
 							Actions: []string{
-								GROUP_ACTION_REMOVE_MEMBER,
+								GROUP_ACTION_LIST_MEMBERS,
+								GROUP_ACTION_GET_GROUP,
 							},
 							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, "/path/"),
+								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
 							},
 						},
 					},
@@ -2672,9 +3857,8 @@ func TestAuthAPI_RemoveMember(t *testing.T) {
 				Identifier: "123456",
 				Admin:      false,
 			},
-			userID:    "12345",
-			groupName: "group1",
 			org:       "org1",
+			groupName: "group1",
 			wantError: &Error{
 				Code:    UNAUTHORIZED_RESOURCES_ERROR,
 				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:org1:group/path/groupUser",
@@ -2698,87 +3882,11 @@ func TestAuthAPI_RemoveMember(t *testing.T) {
 				{
 					ID:         "POLICY-USER-ID",
 					Name:       "policyUser",
-					Org:        "org1",
-					Path:       "/path/",
-					Urn:        CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{},
-				},
-			},
-			getUserByExternalIDResult: &User{
-				ID:         "543210",
-				ExternalID: "1234",
-				Path:       "/path/",
-				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
-			},
-		},
-		"ErrorCaseUserNotFound": {
-			requestInfo: RequestInfo{
-				Identifier: "123456",
-				Admin:      true,
-			},
-			userID:    "12345",
-			groupName: "group1",
-			org:       "org1",
-			wantError: &Error{
-				Code: USER_BY_EXTERNAL_ID_NOT_FOUND,
-			},
-			getGroupByNameResult: &Group{
-				ID:   "GROUP-USER-ID",
-				Name: "groupUser",
-				Org:  "org1",
-				Path: "/path/",
-				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
-			},
-			getUserByExternalIDMethodErr: &database.Error{
-				Code: database.USER_NOT_FOUND,
-			},
-		},
-		"ErrorCaseIsMemberDBErr": {
-			requestInfo: RequestInfo{
-				Identifier: "123456",
-				Admin:      true,
-			},
-			userID:    "12345",
-			groupName: "group1",
-			org:       "org1",
-			wantError: &Error{
-				Code: UNKNOWN_API_ERROR,
-			},
-			getGroupByNameResult: &Group{
-				ID:   "GROUP-USER-ID",
-				Name: "groupUser",
-				Org:  "org1",
-				Path: "/path/",
-				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
-			},
-			getUserByExternalIDResult: &User{
-				ID:         "543210",
-				ExternalID: "1234",
-				Path:       "/path/",
-				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
-			},
-			isMemberOfGroupMethodErr: &database.Error{
-				Code: database.INTERNAL_ERROR,
-			},
-		},
-		"ErrorCaseIsNotMember": {
-			requestInfo: RequestInfo{
-				Identifier: "123456",
-				Admin:      true,
-			},
-			userID:    "1234",
-			groupName: "group1",
-			org:       "org1",
-			wantError: &Error{
-				Code:    USER_IS_NOT_A_MEMBER_OF_GROUP,
-				Message: "User with externalId 1234 is not a member of group with org org1 and name groupUser",
-			},
-			getGroupByNameResult: &Group{
-				ID:   "GROUP-USER-ID",
-				Name: "groupUser",
-				Org:  "org1",
-				Path: "/path/",
-				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
+					Org:        "org1",
+					Path:       "/path/",
+					Urn:        CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+					Statements: &[]Statement{},
+				},
 			},
 			getUserByExternalIDResult: &User{
 				ID:         "543210",
@@ -2786,37 +3894,38 @@ func TestAuthAPI_RemoveMember(t *testing.T) {
 				Path:       "/path/",
 				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
 			},
-			isMemberOfGroupResult: false,
 		},
-		"ErrorCaseRemoveMemberDBErr": {
+		"ErrorCaseListMembersDBErr": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      true,
 			},
-			userID:    "12345",
-			groupName: "group1",
 			org:       "org1",
+			groupName: "group1",
 			wantError: &Error{
 				Code: UNKNOWN_API_ERROR,
 			},
 			getGroupByNameResult: &Group{
-				ID:   "GROUP-USER-ID",
-				Name: "groupUser",
+				ID:   "543210",
+				Name: "group1",
 				Org:  "org1",
-				Path: "/path/",
-				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
-			},
-			getUserByExternalIDResult: &User{
-				ID:         "543210",
-				ExternalID: "1234",
-				Path:       "/path/",
-				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+				Path: "/test/",
 			},
-			isMemberOfGroupResult: true,
-			removeMemberMethodErr: &database.Error{
+			getGroupMembersMethodErr: &database.Error{
 				Code: database.INTERNAL_ERROR,
 			},
 		},
+		"ErrorCaseInvalidFilter": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter: &Filter{Limit: -1},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Limit -1, max limit allowed: 1000",
+			},
+		},
 	}
 
 	for x, testcase := range testcases {
@@ -2825,105 +3934,157 @@ func TestAuthAPI_RemoveMember(t *testing.T) {
 
 		testRepo.ArgsOut[GetGroupByNameMethod][0] = testcase.getGroupByNameResult
 		testRepo.ArgsOut[GetGroupByNameMethod][1] = testcase.getGroupByNameMethodErr
-		testRepo.ArgsOut[IsMemberOfGroupMethod][0] = testcase.isMemberOfGroupResult
-		testRepo.ArgsOut[IsMemberOfGroupMethod][1] = testcase.isMemberOfGroupMethodErr
-		testRepo.ArgsOut[RemoveMemberMethod][0] = testcase.removeMemberMethodErr
+		testRepo.ArgsOut[GetGroupMembersMethod][0] = testcase.getGroupMembersResult
+		testRepo.ArgsOut[GetGroupMembersMethod][2] = testcase.getGroupMembersMethodErr
 		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
 		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
 		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
 
-		err := testAPI.RemoveMember(testcase.requestInfo, testcase.userID, testcase.groupName, testcase.org)
-		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+		testRepo.ArgsOut[GetGroupMembersMethod][1] = testcase.getGroupMembersTotal
+		filter := testcase.filter
+		if filter == nil {
+			filter = &Filter{}
+		}
+		members, total, err := testAPI.ListMembers(testcase.requestInfo, testcase.org, testcase.groupName, filter)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedMembers, members)
+		if testcase.wantError == nil && total != testcase.expectedTotal {
+			t.Errorf("Test %v failed. Received different total (wanted:%v / received:%v)", x, testcase.expectedTotal, total)
+		}
 	}
 }
 
-func TestAuthAPI_ListMembers(t *testing.T) {
+func TestAuthAPI_ListGroupOwners(t *testing.T) {
 	testcases := map[string]struct {
 		// API Method args
 		requestInfo RequestInfo
 		org         string
 		groupName   string
 		// Expected result
-		expectedMembers []string
-		wantError       error
+		expectedOwners []string
+		wantError      error
+		// Manager Results
+		getGroupByNameResult *Group
+		// Manager Errors
+		getGroupByNameMethodErr error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:            "org1",
+			groupName:      "group1",
+			expectedOwners: []string{"owner1", "owner2"},
+			getGroupByNameResult: &Group{
+				ID:     "543210",
+				Name:   "group1",
+				Org:    "org1",
+				Path:   "/test/",
+				Owners: []string{"owner1", "owner2"},
+			},
+		},
+		"OkCaseWithoutOwners": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:            "org1",
+			groupName:      "group1",
+			expectedOwners: []string{},
+			getGroupByNameResult: &Group{
+				ID:   "543210",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/test/",
+			},
+		},
+		"ErrorCaseGroupNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:       "org1",
+			groupName: "group1",
+			wantError: &Error{
+				Code: GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+			},
+			getGroupByNameMethodErr: &database.Error{
+				Code: database.GROUP_NOT_FOUND,
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetGroupByNameMethod][0] = testcase.getGroupByNameResult
+		testRepo.ArgsOut[GetGroupByNameMethod][1] = testcase.getGroupByNameMethodErr
+
+		owners, err := testAPI.ListGroupOwners(testcase.requestInfo, testcase.org, testcase.groupName)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedOwners, owners)
+	}
+}
+
+func TestAuthAPI_AddGroupOwner(t *testing.T) {
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		userID      string
+		org         string
+		groupName   string
+		// Expected result
+		wantError error
 		// Manager Results
-		getGroupByNameResult      *Group
-		getGroupMembersResult     []User
 		getGroupsByUserIDResult   []Group
 		getAttachedPoliciesResult []Policy
 		getUserByExternalIDResult *User
-		// API Errors
-		getGroupByNameMethodErr      error
+		getGroupByNameResult      *Group
+		// Manager Errors
 		getUserByExternalIDMethodErr error
-		getGroupMembersMethodErr     error
+		getGroupByNameMethodErr      error
+		addOwnerMethodErr            error
 	}{
 		"OkCaseAdmin": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      true,
 			},
+			userID:    "12345",
 			org:       "org1",
 			groupName: "group1",
-			expectedMembers: []string{
-				"member1",
-				"member2",
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "12345",
+				Path:       "/test/asd/",
 			},
 			getGroupByNameResult: &Group{
 				ID:   "543210",
 				Name: "group1",
 				Org:  "org1",
-				Path: "/test/",
-			},
-			getGroupMembersResult: []User{
-				{
-					ID:         "12345",
-					ExternalID: "member1",
-					Path:       "/test/",
-				},
-				{
-					ID:         "123456",
-					ExternalID: "member2",
-					Path:       "/test/",
-				},
+				Path: "/test/asd/",
 			},
 		},
-		"OKCase": {
+		"ErrorCaseNoPermissions": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      false,
 			},
+			userID:    "12345",
 			org:       "org1",
 			groupName: "group1",
-			expectedMembers: []string{
-				"member1",
-				"member2",
-			},
-			getGroupByNameResult: &Group{
-				ID:   "GROUP-USER-ID",
-				Name: "groupUser",
-				Org:  "org1",
-				Path: "/path/1/",
-				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
-			},
-			getGroupMembersResult: []User{
-				{
-					ID:         "12345",
-					ExternalID: "member1",
-					Path:       "/test/",
-				},
-				{
-					ID:         "123456",
-					ExternalID: "member2",
-					Path:       "/test/",
-				},
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:org1:group/path/group1",
 			},
 			getGroupsByUserIDResult: []Group{
 				{
 					ID:   "GROUP-USER-ID",
 					Name: "groupUser",
 					Org:  "org1",
-					Path: "/path/1/",
+					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
 			getAttachedPoliciesResult: []Policy{
@@ -2937,11 +4098,11 @@ func TestAuthAPI_ListMembers(t *testing.T) {
 						{
 							Effect: "allow",
 							Actions: []string{
-								GROUP_ACTION_LIST_MEMBERS,
 								GROUP_ACTION_GET_GROUP,
+								GROUP_ACTION_ADD_MEMBER,
 							},
 							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, "/path/"),
+								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
 							},
 						},
 					},
@@ -2949,234 +4110,225 @@ func TestAuthAPI_ListMembers(t *testing.T) {
 			},
 			getUserByExternalIDResult: &User{
 				ID:         "543210",
-				ExternalID: "1234",
+				ExternalID: "123456",
 				Path:       "/path/",
-				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
-			},
-		},
-		"ErrorCaseInvalidName": {
-			org:       "org1",
-			groupName: "*%$",
-			wantError: &Error{
-				Code:    INVALID_PARAMETER_ERROR,
-				Message: "Invalid parameter: name *%$",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "123456"),
 			},
-		},
-		"ErrorCaseInvalidOrg": {
-			org:       "!^**$%&",
-			groupName: "g1",
-			wantError: &Error{
-				Code:    INVALID_PARAMETER_ERROR,
-				Message: "Invalid parameter: org !^**$%&",
+			getGroupByNameResult: &Group{
+				ID:   "GROUP-USER-ID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
 			},
 		},
 		"ErrorCaseGroupNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			userID:    "12345",
 			org:       "org1",
 			groupName: "group1",
-			getGroupByNameMethodErr: &database.Error{
-				Code: database.GROUP_NOT_FOUND,
-			},
 			wantError: &Error{
 				Code: GROUP_BY_ORG_AND_NAME_NOT_FOUND,
 			},
+			getGroupByNameMethodErr: &database.Error{
+				Code: database.GROUP_NOT_FOUND,
+			},
 		},
-		"ErrorCaseNotAuthenticatedUser": {
+		"ErrorCaseUserNotFound": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
-				Admin:      false,
+				Admin:      true,
 			},
+			userID:    "12345",
 			org:       "org1",
 			groupName: "group1",
 			wantError: &Error{
-				Code:    UNAUTHORIZED_RESOURCES_ERROR,
-				Message: "Authenticated user with externalId 123456 not found. Unable to retrieve permissions.",
+				Code: USER_BY_EXTERNAL_ID_NOT_FOUND,
 			},
 			getGroupByNameResult: &Group{
+				ID:   "543210",
 				Name: "group1",
 				Org:  "org1",
-				Path: "/path/",
-				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+				Path: "/test/asd/",
 			},
 			getUserByExternalIDMethodErr: &database.Error{
 				Code: database.USER_NOT_FOUND,
 			},
 		},
-		"ErrorCaseUnauthorizedResource": {
+		"ErrorCaseIsAlreadyOwner": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
-				Admin:      false,
+				Admin:      true,
 			},
+			userID:    "12345",
 			org:       "org1",
 			groupName: "group1",
 			wantError: &Error{
-				Code:    UNAUTHORIZED_RESOURCES_ERROR,
-				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:org1:group/path/groupUser",
-			},
-			getGroupByNameResult: &Group{
-				ID:   "GROUP-USER-ID",
-				Name: "groupUser",
-				Org:  "org1",
-				Path: "/path/",
-				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
-			},
-			getGroupsByUserIDResult: []Group{
-				{
-					ID:   "GROUP-USER-ID",
-					Name: "groupUser",
-					Org:  "org1",
-					Path: "/path/1/",
-				},
-			},
-			getAttachedPoliciesResult: []Policy{
-				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "org1",
-					Path: "/path/",
-					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_GET_GROUP,
-							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
-							},
-						},
-					},
-				},
+				Code:    USER_IS_ALREADY_AN_OWNER_OF_GROUP,
+				Message: "User: 12345 is already an owner of Group: group1",
 			},
 			getUserByExternalIDResult: &User{
 				ID:         "543210",
-				ExternalID: "1234",
-				Path:       "/path/",
-				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+				ExternalID: "12345",
+				Path:       "/test/asd/",
+			},
+			getGroupByNameResult: &Group{
+				ID:     "543210",
+				Name:   "group1",
+				Org:    "org1",
+				Path:   "/test/asd/",
+				Owners: []string{"12345"},
 			},
 		},
-		"ErrorCaseDenyListMembers": {
+		"ErrorCaseAddOwnerDBErr": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
-				Admin:      false,
+				Admin:      true,
 			},
+			userID:    "12345",
 			org:       "org1",
 			groupName: "group1",
 			wantError: &Error{
-				Code:    UNAUTHORIZED_RESOURCES_ERROR,
-				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:org1:group/path/groupUser",
+				Code: UNKNOWN_API_ERROR,
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "12345",
+				Path:       "/test/asd/",
 			},
 			getGroupByNameResult: &Group{
-				ID:   "GROUP-USER-ID",
-				Name: "groupUser",
+				ID:   "543210",
+				Name: "group1",
 				Org:  "org1",
-				Path: "/path/1/",
-				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
+				Path: "/test/asd/",
 			},
-			getGroupsByUserIDResult: []Group{
-				{
-					ID:   "GROUP-USER-ID",
-					Name: "groupUser",
-					Org:  "org1",
-					Path: "/path/1/",
-				},
+			addOwnerMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
 			},
-			getAttachedPoliciesResult: []Policy{
-				{
-					ID:   "POLICY-USER-ID",
-					Name: "policyUser",
-					Org:  "org1",
-					Path: "/path/",
-					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{
-						{
-							Effect: "deny",
-							Actions: []string{
-								GROUP_ACTION_LIST_MEMBERS,
-							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, "/path/"),
-							},
-						},
-						{
-							Effect: "allow",
-							Actions: []string{
-								GROUP_ACTION_LIST_MEMBERS,
-								GROUP_ACTION_GET_GROUP,
-							},
-							Resources: []string{
-								GetUrnPrefix("org1", RESOURCE_GROUP, ""),
-							},
-						},
-					},
-				},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[AddOwnerMethod][0] = testcase.addOwnerMethodErr
+		testRepo.ArgsOut[GetGroupByNameMethod][0] = testcase.getGroupByNameResult
+		testRepo.ArgsOut[GetGroupByNameMethod][1] = testcase.getGroupByNameMethodErr
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
+
+		err := testAPI.AddGroupOwner(testcase.requestInfo, testcase.org, testcase.groupName, testcase.userID)
+		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+	}
+}
+
+func TestAuthAPI_RemoveGroupOwner(t *testing.T) {
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		userID      string
+		org         string
+		groupName   string
+		// Expected result
+		wantError error
+		// Manager Results
+		getUserByExternalIDResult *User
+		getGroupByNameResult      *Group
+		// Manager Errors
+		getGroupByNameMethodErr error
+		removeOwnerMethodErr    error
+	}{
+		"OkCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
 			},
+			userID:    "12345",
+			org:       "org1",
+			groupName: "group1",
 			getUserByExternalIDResult: &User{
 				ID:         "543210",
-				ExternalID: "1234",
-				Path:       "/path/",
-				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+				ExternalID: "12345",
+				Path:       "/test/asd/",
+			},
+			getGroupByNameResult: &Group{
+				ID:     "543210",
+				Name:   "group1",
+				Org:    "org1",
+				Path:   "/test/asd/",
+				Owners: []string{"12345"},
 			},
 		},
-		"ErrorCaseNoPermissions": {
+		"ErrorCaseGroupNotFound": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
-				Admin:      false,
+				Admin:      true,
 			},
+			userID:    "12345",
 			org:       "org1",
 			groupName: "group1",
 			wantError: &Error{
-				Code:    UNAUTHORIZED_RESOURCES_ERROR,
-				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:org1:group/path/groupUser",
+				Code: GROUP_BY_ORG_AND_NAME_NOT_FOUND,
 			},
-			getGroupByNameResult: &Group{
-				ID:   "GROUP-USER-ID",
-				Name: "groupUser",
-				Org:  "org1",
-				Path: "/path/",
-				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "groupUser"),
+			getGroupByNameMethodErr: &database.Error{
+				Code: database.GROUP_NOT_FOUND,
 			},
-			getGroupsByUserIDResult: []Group{
-				{
-					ID:   "GROUP-USER-ID",
-					Name: "groupUser",
-					Org:  "org1",
-					Path: "/path/1/",
-				},
+		},
+		"ErrorCaseIsNotOwner": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
 			},
-			getAttachedPoliciesResult: []Policy{
-				{
-					ID:         "POLICY-USER-ID",
-					Name:       "policyUser",
-					Org:        "org1",
-					Path:       "/path/",
-					Urn:        CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
-					Statements: &[]Statement{},
-				},
+			userID:    "12345",
+			org:       "org1",
+			groupName: "group1",
+			wantError: &Error{
+				Code:    USER_IS_NOT_AN_OWNER_OF_GROUP,
+				Message: "User: 12345 is not an owner of Group: group1",
 			},
 			getUserByExternalIDResult: &User{
 				ID:         "543210",
-				ExternalID: "1234",
-				Path:       "/path/",
-				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+				ExternalID: "12345",
+				Path:       "/test/asd/",
+			},
+			getGroupByNameResult: &Group{
+				ID:     "543210",
+				Name:   "group1",
+				Org:    "org1",
+				Path:   "/test/asd/",
+				Owners: []string{"54321"},
 			},
 		},
-		"ErrorCaseListMembersDBErr": {
+		"ErrorCaseRemoveOwnerDBErr": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      true,
 			},
+			userID:    "12345",
 			org:       "org1",
 			groupName: "group1",
 			wantError: &Error{
 				Code: UNKNOWN_API_ERROR,
 			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "12345",
+				Path:       "/test/asd/",
+			},
 			getGroupByNameResult: &Group{
-				ID:   "543210",
-				Name: "group1",
-				Org:  "org1",
-				Path: "/test/",
+				ID:     "543210",
+				Name:   "group1",
+				Org:    "org1",
+				Path:   "/test/asd/",
+				Owners: []string{"12345"},
 			},
-			getGroupMembersMethodErr: &database.Error{
+			removeOwnerMethodErr: &database.Error{
 				Code: database.INTERNAL_ERROR,
 			},
 		},
@@ -3186,26 +4338,28 @@ func TestAuthAPI_ListMembers(t *testing.T) {
 		testRepo := makeTestRepo()
 		testAPI := makeTestAPI(testRepo)
 
+		testRepo.ArgsOut[RemoveOwnerMethod][0] = testcase.removeOwnerMethodErr
 		testRepo.ArgsOut[GetGroupByNameMethod][0] = testcase.getGroupByNameResult
 		testRepo.ArgsOut[GetGroupByNameMethod][1] = testcase.getGroupByNameMethodErr
-		testRepo.ArgsOut[GetGroupMembersMethod][0] = testcase.getGroupMembersResult
-		testRepo.ArgsOut[GetGroupMembersMethod][1] = testcase.getGroupMembersMethodErr
 		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
-		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
-		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
-		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
 
-		members, err := testAPI.ListMembers(testcase.requestInfo, testcase.org, testcase.groupName)
-		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedMembers, members)
+		err := testAPI.RemoveGroupOwner(testcase.requestInfo, testcase.org, testcase.groupName, testcase.userID)
+		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
 	}
 }
 
 func TestAuthAPI_AttachPolicyToGroup(t *testing.T) {
+	now := time.Now().UTC()
+	nextDay := now.Add(24 * time.Hour)
+	nextWeek := now.Add(7 * 24 * time.Hour)
+	yesterday := now.Add(-24 * time.Hour)
 	testcases := map[string]struct {
 		requestInfo RequestInfo
 		org         string
 		groupName   string
 		policyName  string
+		notBefore   *time.Time
+		notAfter    *time.Time
 		// Expected result
 		wantError error
 		// Manager Results
@@ -3257,6 +4411,63 @@ func TestAuthAPI_AttachPolicyToGroup(t *testing.T) {
 			},
 			isAttachedToGroupResult: false,
 		},
+		"OkCaseAdminWithActivationWindow": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:        "123",
+			groupName:  "group1",
+			policyName: "policy1",
+			notBefore:  &nextDay,
+			notAfter:   &nextWeek,
+			getGroupByNameResult: &Group{
+				ID:   "12345",
+				Name: "group1",
+				Org:  "123",
+				Path: "/path/",
+				Urn:  CreateUrn("123", RESOURCE_GROUP, "/path/", "test"),
+			},
+			getPolicyByNameResult: &Policy{
+				ID:         "test1",
+				Name:       "test",
+				Org:        "123",
+				Path:       "/path/",
+				Urn:        CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
+				Statements: &[]Statement{},
+			},
+			isAttachedToGroupResult: false,
+		},
+		"ErrorCaseNotAfterInThePast": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:        "123",
+			groupName:  "group1",
+			policyName: "policy1",
+			notAfter:   &yesterday,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: notAfter %v is in the past", yesterday),
+			},
+		},
+		"ErrorCaseNotAfterBeforeNotBefore": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:        "123",
+			groupName:  "group1",
+			policyName: "policy1",
+			notBefore:  &nextWeek,
+			notAfter:   &nextDay,
+			wantError: &Error{
+				Code: INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: notAfter %v must be later than notBefore %v",
+					nextDay, nextWeek),
+			},
+		},
 		"OKCase": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
@@ -3735,8 +4946,17 @@ func TestAuthAPI_AttachPolicyToGroup(t *testing.T) {
 		testRepo.ArgsOut[IsAttachedToGroupMethod][1] = testcase.isAttachedToGroupMethodErr
 		testRepo.ArgsOut[AttachPolicyMethod][0] = testcase.attachPolicyMethodErr
 
-		err := testAPI.AttachPolicyToGroup(testcase.requestInfo, testcase.org, testcase.groupName, testcase.policyName)
+		err := testAPI.AttachPolicyToGroup(testcase.requestInfo, testcase.org, testcase.groupName, testcase.policyName,
+			testcase.notBefore, testcase.notAfter)
 		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+		if testcase.wantError == nil {
+			if diff := pretty.Compare(testRepo.ArgsIn[AttachPolicyMethod][2], testcase.notBefore); diff != "" {
+				t.Errorf("Test %v failed. Received different notBefore (received/wanted) %v", x, diff)
+			}
+			if diff := pretty.Compare(testRepo.ArgsIn[AttachPolicyMethod][3], testcase.notAfter); diff != "" {
+				t.Errorf("Test %v failed. Received different notAfter (received/wanted) %v", x, diff)
+			}
+		}
 	}
 }
 
@@ -4273,22 +5493,27 @@ func TestAuthAPI_DetachPolicyToGroup(t *testing.T) {
 }
 
 func TestAuthAPI_ListAttachedGroupPolicies(t *testing.T) {
+	nextDay := time.Now().UTC().Add(24 * time.Hour)
 	testcases := map[string]struct {
 		//API method args
 		requestInfo RequestInfo
+		filter      *Filter
 		name        string
 		org         string
 		// Expected result
-		expectedPolicies []string
+		expectedTotal    int
+		expectedPolicies []AttachedPolicy
 		wantError        error
 		// Manager Results
-		getUserByExternalIDResult  *User
-		getGroupsByUserIDResult    []Group
-		getAttachedPoliciesResult  []Policy
-		getGroupByNameMethodResult *Group
+		getGroupPolicyRelationsTotal  int
+		getUserByExternalIDResult     *User
+		getGroupsByUserIDResult       []Group
+		getAttachedPoliciesResult     []Policy
+		getGroupPolicyRelationsResult []GroupPolicyRelation
+		getGroupByNameMethodResult    *Group
 		// API Errors
 		getUserByExternalIDMethodErr error
-		getAttachedPoliciesErr       error
+		getGroupPolicyRelationsErr   error
 		getGroupByNameMethodErr      error
 	}{
 		"OKCaseAdminUser": {
@@ -4310,7 +5535,41 @@ func TestAuthAPI_ListAttachedGroupPolicies(t *testing.T) {
 				Path:       "/path/",
 				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "123456"),
 			},
-			expectedPolicies: []string{},
+			expectedPolicies: []AttachedPolicy{},
+		},
+		"OKCaseAdminUserScheduledPolicy": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			expectedTotal:                1,
+			getGroupPolicyRelationsTotal: 1,
+			name:                         "group1",
+			org:                          "org1",
+			getGroupByNameMethodResult: &Group{
+				ID:   "543210",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/example/",
+			},
+			getGroupPolicyRelationsResult: []GroupPolicyRelation{
+				{
+					Policy: &Policy{
+						ID:   "POLICY-ID",
+						Name: "releaseDay",
+						Org:  "org1",
+						Path: "/example/",
+					},
+					NotBefore: &nextDay,
+				},
+			},
+			expectedPolicies: []AttachedPolicy{
+				{
+					Name:      "releaseDay",
+					NotBefore: &nextDay,
+					Active:    false,
+				},
+			},
 		},
 		"OKCase": {
 			requestInfo: RequestInfo{
@@ -4362,7 +5621,22 @@ func TestAuthAPI_ListAttachedGroupPolicies(t *testing.T) {
 					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/example/", "groupUser"),
 				},
 			},
-			expectedPolicies: []string{"policyUser"},
+			getGroupPolicyRelationsResult: []GroupPolicyRelation{
+				{
+					Policy: &Policy{
+						ID:   "POLICY-USER-ID",
+						Name: "policyUser",
+						Org:  "org1",
+						Path: "/example/",
+					},
+				},
+			},
+			expectedPolicies: []AttachedPolicy{
+				{
+					Name:   "policyUser",
+					Active: true,
+				},
+			},
 		},
 		"ErrorCaseInvalidName": {
 			name: "invalid*",
@@ -4602,14 +5876,26 @@ func TestAuthAPI_ListAttachedGroupPolicies(t *testing.T) {
 					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/example/", "group1"),
 				},
 			},
-			getAttachedPoliciesErr: &database.Error{
+			getGroupPolicyRelationsErr: &database.Error{
 				Code: database.INTERNAL_ERROR,
 			},
 			wantError: &Error{
 				Code: UNKNOWN_API_ERROR,
 			},
 		},
+		"ErrorCaseInvalidFilter": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter: &Filter{Limit: -1},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Limit -1, max limit allowed: 1000",
+			},
+		},
 	}
+
 	for x, testcase := range testcases {
 		testRepo := makeTestRepo()
 		testAPI := makeTestAPI(testRepo)
@@ -4620,9 +5906,18 @@ func TestAuthAPI_ListAttachedGroupPolicies(t *testing.T) {
 		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
 		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
-		testRepo.ArgsOut[GetAttachedPoliciesMethod][1] = testcase.getAttachedPoliciesErr
+		testRepo.ArgsOut[GetGroupPolicyRelationsMethod][0] = testcase.getGroupPolicyRelationsResult
+		testRepo.ArgsOut[GetGroupPolicyRelationsMethod][2] = testcase.getGroupPolicyRelationsErr
 
-		policies, err := testAPI.ListAttachedGroupPolicies(testcase.requestInfo, testcase.org, testcase.name)
+		testRepo.ArgsOut[GetGroupPolicyRelationsMethod][1] = testcase.getGroupPolicyRelationsTotal
+		filter := testcase.filter
+		if filter == nil {
+			filter = &Filter{}
+		}
+		policies, total, err := testAPI.ListAttachedGroupPolicies(testcase.requestInfo, testcase.org, testcase.name, filter)
 		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedPolicies, policies)
+		if testcase.wantError == nil && total != testcase.expectedTotal {
+			t.Errorf("Test %v failed. Received different total (wanted:%v / received:%v)", x, testcase.expectedTotal, total)
+		}
 	}
 }
@@ -1,6 +1,11 @@
 package api
 
-import log "github.com/Sirupsen/logrus"
+import (
+	"net/http"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
 
 // TYPE DEFINITIONS
 
@@ -8,42 +13,114 @@ import log "github.com/Sirupsen/logrus"
 type Resource interface {
 	// This method must return resource URN
 	GetUrn() string
+
+	// This method must return resource tags, used to evaluate statement conditions
+	GetTags() map[string]string
 }
 
 // Foulkon API that implements API interfaces using repositories
 type AuthAPI struct {
-	UserRepo   UserRepo
-	GroupRepo  GroupRepo
-	PolicyRepo PolicyRepo
-	Logger     *log.Logger
+	UserRepo          UserRepo
+	GroupRepo         GroupRepo
+	PolicyRepo        PolicyRepo
+	OrganizationRepo  OrganizationRepo
+	AccessRequestRepo AccessRequestRepo
+	ActionRepo        ActionRepo
+	ResourceTypeRepo  ResourceTypeRepo
+	TagRepo           TagRepo
+	TransactionRepo   TransactionRepo
+	TrashRepo         TrashRepo
+	ConsistencyRepo   ConsistencyRepo
+	AuditRepo         AuditRepo
+	WebhookRepo       WebhookRepo
+	Logger            *log.Logger
+
+	// Destination of the authorization decisions, they aren't recorded if it's nil
+	DecisionLogger DecisionLogger
+
+	// Reject policy statements with actions that aren't registered
+	ValidateActions bool
+	// Reject policy statements and authorization requests with resources
+	// that don't conform to a registered resource type
+	ValidateResources bool
+	// Time that removed users, groups and policies are kept in the trash before they are purged
+	TrashRetention time.Duration
+	// Client used to deliver events to webhooks, http.DefaultClient if it's nil
+	WebhookClient *http.Client
+
+	// Permissions of authenticated users shared by the operations of a batch, nil outside batches
+	batchPermissions *permissionCache
 }
 
 // API INTERFACES WITH AUTHORIZATION
 
 type UserAPI interface {
-	// Store user in database. Throw error when parameters are invalid,
+	// Store active user with its profile in database. Throw error when parameters are invalid,
 	// user already exists or unexpected error happen.
-	AddUser(requestInfo RequestInfo, externalId string, path string) (*User, error)
+	AddUser(requestInfo RequestInfo, externalId string, path string, profile UserProfile) (*User, error)
 
 	// Retrieve user from database. Throw error when parameter is invalid,
 	// user doesn't exist or unexpected error happen.
 	GetUserByExternalID(requestInfo RequestInfo, externalId string) (*User, error)
 
-	// Retrieve user identifiers from database filtered by pathPrefix (optional parameter). Throw error
-	// if pathPrefix is invalid or unexpected error happen.
-	ListUsers(requestInfo RequestInfo, pathPrefix string) ([]string, error)
+	// Retrieve a page of users from database filtered by pathPrefix and tags filter fields (optional),
+	// and the total number of users that match the filter. Throw error if filter is invalid or unexpected error happen.
+	ListUsers(requestInfo RequestInfo, filter *Filter) ([]User, int, error)
+
+	// Update user stored in database with new pathPrefix and profile. If the URN changes, policy statements that
+	// referenced the old one are rewritten when requestInfo.RewriteReferences is true, otherwise they are returned
+	// as dangling references. Throw error if the input parameters are invalid, user doesn't exist or unexpected
+	// error happen.
+	UpdateUser(requestInfo RequestInfo, externalId string, newPath string, profileUpdate UserProfileUpdate) (*User, []StatementReference, error)
+
+	// Suspend user, denying every action to it without removing its group memberships. Throw error
+	// if externalId parameter is invalid, user doesn't exist or unexpected error happen.
+	SuspendUser(requestInfo RequestInfo, externalId string) (*User, error)
+
+	// Reactivate suspended user. Throw error if externalId parameter is invalid, user doesn't exist
+	// or unexpected error happen.
+	ReactivateUser(requestInfo RequestInfo, externalId string) (*User, error)
 
-	// Update user stored in database with new pathPrefix. Throw error if the input parameters
-	// are invalid, user doesn't exist or unexpected error happen.
-	UpdateUser(requestInfo RequestInfo, externalId string, newPath string) (*User, error)
+	// Change externalId and URN of the user keeping its id, so group memberships and tags are preserved.
+	// Policy statements with the old user URN in their resources are returned, and they are changed to the
+	// new URN inside the same transaction if rewritePolicies is true. Throw error if the input parameters are
+	// invalid, user doesn't exist, new externalId is already taken or unexpected error happen.
+	RenameUser(requestInfo RequestInfo, externalId string, newExternalId string, rewritePolicies bool) (*UserRename, error)
 
-	// Remove user stored in database with its group relationships.
+	// Remove user stored in database with its group relationships, moving them to the trash.
 	// Throw error if externalId parameter is invalid, user doesn't exist or unexpected error happen.
 	RemoveUser(requestInfo RequestInfo, externalId string) error
 
-	// Retrieve groups that belongs to the user. Throw error if externalId parameter is invalid, user
+	// Retrieve a page of groups that belongs to the user and the total number of groups, using
+	// offset and limit filter fields. It includes the groups whose membership rule matches the user. Throw error if externalId parameter or filter are invalid,
+	// user doesn't exist or unexpected error happen.
+	ListGroupsByUser(requestInfo RequestInfo, externalId string, filter *Filter) ([]GroupIdentity, int, error)
+
+	// Retrieve the user that is making the request. It doesn't need any permission. Throw error if the
+	// authenticated user doesn't exist or unexpected error happen.
+	GetAuthenticatedUser(requestInfo RequestInfo) (*User, error)
+
+	// Retrieve a page of groups that the user making the request belongs to and the total number of groups,
+	// using offset and limit filter fields. It doesn't need any permission. Throw error if filter is invalid,
+	// the authenticated user doesn't exist or unexpected error happen.
+	ListAuthenticatedUserGroups(requestInfo RequestInfo, filter *Filter) ([]GroupIdentity, int, error)
+
+	// Retrieve the statements granted to the user making the request by the active policies attached to
+	// its groups. Suspended users don't have any. It doesn't need any permission. Throw error if the
+	// authenticated user doesn't exist or unexpected error happen.
+	ListAuthenticatedUserPermissions(requestInfo RequestInfo) ([]Permission, error)
+
+	// Retrieve tags of the user. Throw error if externalId parameter is invalid, user
 	// doesn't exist or unexpected error happen.
-	ListGroupsByUser(requestInfo RequestInfo, externalId string) ([]GroupIdentity, error)
+	ListUserTags(requestInfo RequestInfo, externalId string) (map[string]string, error)
+
+	// Add tag to the user or replace its value if the user already has it, returning all user tags.
+	// Throw error if the input parameters are invalid, user doesn't exist or unexpected error happen.
+	SetUserTag(requestInfo RequestInfo, externalId string, key string, value string) (map[string]string, error)
+
+	// Remove tag from the user. Throw error if the input parameters are invalid, user doesn't exist,
+	// user doesn't have the tag or unexpected error happen.
+	RemoveUserTag(requestInfo RequestInfo, externalId string, key string) error
 }
 
 type GroupAPI interface {
@@ -55,42 +132,78 @@ type GroupAPI interface {
 	// group doesn't exist or unexpected error happen.
 	GetGroupByName(requestInfo RequestInfo, org string, name string) (*Group, error)
 
-	// Retrieve group identifiers from database filtered by org and pathPrefix parameters. These input parameters are optional.
-	// Throw error if the input parameters are invalid or unexpected error happen.
-	ListGroups(requestInfo RequestInfo, org string, pathPrefix string) ([]GroupIdentity, error)
+	// Retrieve a page of groups from database filtered by org, pathPrefix and tags filter fields (optional),
+	// and the total number of groups that match the filter. Throw error if filter is invalid or unexpected error happen.
+	ListGroups(requestInfo RequestInfo, filter *Filter) ([]Group, int, error)
 
-	// Update group stored in database with new name and pathPrefix.
-	// Throw error if the input parameters are invalid, group to update doesn't exist,
+	// Update group stored in database with new name and pathPrefix. If the URN changes, policy statements that
+	// referenced the old one are rewritten when requestInfo.RewriteReferences is true, otherwise they are returned
+	// as dangling references. Throw error if the input parameters are invalid, group to update doesn't exist,
 	// target group already exist or unexpected error happen.
-	UpdateGroup(requestInfo RequestInfo, org string, groupName string, newName string, newPath string) (*Group, error)
+	UpdateGroup(requestInfo RequestInfo, org string, groupName string, newName string, newPath string) (*Group, []StatementReference, error)
 
-	// Remove group stored in database with its user and policy relationships.
+	// Remove group stored in database with its user and policy relationships, moving them to the trash.
 	// Throw error if the input parameters are invalid, the group doesn't exist or unexpected error happen.
 	RemoveGroup(requestInfo RequestInfo, org string, name string) error
 
-	// Add new member to group. Throw error if the input parameters are invalid, user doesn't exist,
-	// group doesn't exist, user is already a member of the group or unexpected error happen.
+	// Set the membership rule of the group, or remove it if rule is nil. Explicit members are removed when the
+	// group gets a rule, and a group without rule has no members until they are added. Throw error if the input
+	// parameters are invalid, group doesn't exist or unexpected error happen.
+	SetGroupMembershipRule(requestInfo RequestInfo, org string, groupName string, rule *MembershipRule) (*Group, error)
+
+	// Add new member to group. Owners of the group are allowed without policies. Throw error if the input parameters are invalid, user doesn't exist,
+	// group doesn't exist, group has a membership rule, user is already a member of the group or unexpected error happen.
 	AddMember(requestInfo RequestInfo, externalId string, groupName string, org string) error
 
-	// Remove member from group. Throw error if the input parameters are invalid, user doesn't exist,
-	// group doesn't exist, user isn't a member of the group or unexpected error happen.
+	// Remove member from group. Owners of the group are allowed without policies. Throw error if the input parameters are invalid, user doesn't exist,
+	// group doesn't exist, group has a membership rule, user isn't a member of the group or unexpected error happen.
 	RemoveMember(requestInfo RequestInfo, externalId string, groupName string, org string) error
 
-	// List user identifiers that belong to the group. Throw error if the input parameters are invalid,
+	// List a page of user identifiers that belong to the group and the total number of members, using
+	// offset and limit filter fields. Members of groups with a membership rule are the users that match it, and owners
+	// of the group are allowed without policies. Throw error if the input parameters are invalid,
 	// group doesn't exist or unexpected error happen.
-	ListMembers(requestInfo RequestInfo, org string, groupName string) ([]string, error)
+	ListMembers(requestInfo RequestInfo, org string, groupName string, filter *Filter) ([]string, int, error)
+
+	// Retrieve external ids of the owners of the group. Throw error if the input parameters are invalid,
+	// group doesn't exist or unexpected error happen.
+	ListGroupOwners(requestInfo RequestInfo, org string, groupName string) ([]string, error)
+
+	// Add owner to group, who is allowed to add, remove and list members of the group without policies. Throw error
+	// if the input parameters are invalid, user doesn't exist, group doesn't exist, user is already an owner of the
+	// group or unexpected error happen.
+	AddGroupOwner(requestInfo RequestInfo, org string, groupName string, externalId string) error
+
+	// Remove owner from group. Throw error if the input parameters are invalid, user doesn't exist,
+	// group doesn't exist, user isn't an owner of the group or unexpected error happen.
+	RemoveGroupOwner(requestInfo RequestInfo, org string, groupName string, externalId string) error
 
-	// Attach policy to group. Throw error if the input parameters are invalid, policy doesn't exist,
+	// Attach policy to group. Optional notBefore and notAfter parameters restrict the period when the
+	// attached policy is taken into account. Throw error if the input parameters are invalid, policy doesn't exist,
 	// group doesn't exist, policy is already attached to the group or unexpected error happen.
-	AttachPolicyToGroup(requestInfo RequestInfo, org string, groupName string, policyName string) error
+	AttachPolicyToGroup(requestInfo RequestInfo, org string, groupName string, policyName string,
+		notBefore *time.Time, notAfter *time.Time) error
 
 	// Detach policy from group. Throw error if the input parameters are invalid, policy doesn't exist,
 	// group doesn't exist, policy isn't attached to the group or unexpected error happen.
 	DetachPolicyToGroup(requestInfo RequestInfo, org string, groupName string, policyName string) error
 
-	// Retrieve name of policies that are attached to the group. Throw error if the input parameters are invalid,
-	// group doesn't exist or unexpected error happen.
-	ListAttachedGroupPolicies(requestInfo RequestInfo, org string, groupName string) ([]string, error)
+	// Retrieve a page of policies that are attached to the group with their activation windows and the total
+	// number of attached policies, using offset and limit filter fields. Throw error if the input parameters
+	// are invalid, group doesn't exist or unexpected error happen.
+	ListAttachedGroupPolicies(requestInfo RequestInfo, org string, groupName string, filter *Filter) ([]AttachedPolicy, int, error)
+
+	// Retrieve tags of the group. Throw error if the input parameters are invalid, group
+	// doesn't exist or unexpected error happen.
+	ListGroupTags(requestInfo RequestInfo, org string, groupName string) (map[string]string, error)
+
+	// Add tag to the group or replace its value if the group already has it, returning all group tags.
+	// Throw error if the input parameters are invalid, group doesn't exist or unexpected error happen.
+	SetGroupTag(requestInfo RequestInfo, org string, groupName string, key string, value string) (map[string]string, error)
+
+	// Remove tag from the group. Throw error if the input parameters are invalid, group doesn't exist,
+	// group doesn't have the tag or unexpected error happen.
+	RemoveGroupTag(requestInfo RequestInfo, org string, groupName string, key string) error
 }
 
 type PolicyAPI interface {
@@ -102,23 +215,256 @@ type PolicyAPI interface {
 	// policy doesn't exist or unexpected error happen.
 	GetPolicyByName(requestInfo RequestInfo, org string, name string) (*Policy, error)
 
-	// Retrieve policy identifiers from database filtered by org and pathPrefix parameters. These input parameters are optional.
-	// Throw error if the input parameters are invalid or unexpected error happen.
-	ListPolicies(requestInfo RequestInfo, org string, pathPrefix string) ([]PolicyIdentity, error)
+	// Retrieve a page of policies, with their statements, from database filtered by org, pathPrefix and tags filter fields (optional),
+	// and the total number of policies that match the filter. Throw error if filter is invalid or unexpected error happen.
+	ListPolicies(requestInfo RequestInfo, filter *Filter) ([]Policy, int, error)
 
 	// Update policy stored in database with new name, new pathPrefix and new statements.
-	// It overrides older statements. Throw error if the input parameters are invalid,
-	// policy to update doesn't exist, target policy already exist or unexpected error happen.
+	// It overrides older statements. If the URN changes, policy statements that referenced the old one are
+	// rewritten when requestInfo.RewriteReferences is true, otherwise they are returned as dangling references.
+	// Throw error if the input parameters are invalid, policy to update doesn't exist, target policy already
+	// exist or unexpected error happen.
 	UpdatePolicy(requestInfo RequestInfo, org string, name string, newName string, newPath string,
-		newStatements []Statement) (*Policy, error)
+		newStatements []Statement) (*Policy, []StatementReference, error)
 
-	// Remove policy stored in database with its groups relationships.
+	// Remove policy stored in database with its groups relationships, moving them to the trash.
 	// Throw error if the input parameters are invalid, the policy doesn't exist or unexpected error happen.
 	RemovePolicy(requestInfo RequestInfo, org string, name string) error
 
-	// Retrieve name of groups that are attached to the policy. Throw error if the input parameters are invalid,
+	// Retrieve a page of group names that are attached to the policy and the total number of attached groups,
+	// using offset and limit filter fields. Throw error if the input parameters are invalid,
 	// policy doesn't exist or unexpected error happen.
-	ListAttachedGroups(requestInfo RequestInfo, org string, name string) ([]string, error)
+	ListAttachedGroups(requestInfo RequestInfo, org string, name string, filter *Filter) ([]string, int, error)
+
+	// Retrieve tags of the policy. Throw error if the input parameters are invalid, policy
+	// doesn't exist or unexpected error happen.
+	ListPolicyTags(requestInfo RequestInfo, org string, name string) (map[string]string, error)
+
+	// Add tag to the policy or replace its value if the policy already has it, returning all policy tags.
+	// Throw error if the input parameters are invalid, policy doesn't exist or unexpected error happen.
+	SetPolicyTag(requestInfo RequestInfo, org string, name string, key string, value string) (map[string]string, error)
+
+	// Remove tag from the policy. Throw error if the input parameters are invalid, policy doesn't exist,
+	// policy doesn't have the tag or unexpected error happen.
+	RemovePolicyTag(requestInfo RequestInfo, org string, name string, key string) error
+
+	// Retrieve statement of the policy by its sid. Throw error if the input parameters are invalid,
+	// policy or statement doesn't exist or unexpected error happen.
+	GetPolicyStatement(requestInfo RequestInfo, org string, policyName string, sid string) (*Statement, error)
+
+	// Add statement at the end of the policy statements, with a generated sid if it doesn't have one.
+	// Throw error if the input parameters are invalid, policy doesn't exist, a statement with same sid
+	// already exist, the policy changed concurrently or unexpected error happen.
+	AddPolicyStatement(requestInfo RequestInfo, org string, policyName string, statement Statement) (*Statement, error)
+
+	// Replace statement of the policy keeping its sid and position. Throw error if the input parameters are invalid,
+	// policy or statement doesn't exist, the policy changed concurrently or unexpected error happen.
+	UpdatePolicyStatement(requestInfo RequestInfo, org string, policyName string, sid string, statement Statement) (*Statement, error)
+
+	// Remove statement from the policy. Throw error if the input parameters are invalid, policy or statement
+	// doesn't exist, the policy changed concurrently or unexpected error happen.
+	RemovePolicyStatement(requestInfo RequestInfo, org string, policyName string, sid string) error
+
+	// Update name, path and statements of the policy applying a JSON Patch or a JSON Merge Patch, according to
+	// patch type. Throw error if the input parameters are invalid, the patch can't be applied or its result
+	// isn't a valid policy, a patch test fails, policy doesn't exist, the policy changed concurrently or
+	// unexpected error happen.
+	PatchPolicy(requestInfo RequestInfo, org string, policyName string, patchType string, patch []byte) (*Policy, error)
+}
+
+type AccessRequestAPI interface {
+	// Store a request from the authenticated user asking for membership in a group, or for a policy
+	// attachment to a group if policyName is not empty, during the given duration. Throw error when
+	// the input parameters are invalid, group or policy don't exist or unexpected error happen.
+	AddAccessRequest(requestInfo RequestInfo, org string, groupName string, policyName string,
+		justification string, duration time.Duration) (*AccessRequest, error)
+
+	// Retrieve access request of an organization from database. Requesters can retrieve their own requests,
+	// other users need approve permission over the group. Throw error when access request doesn't exist
+	// in the organization, user isn't allowed to see it or unexpected error happen.
+	GetAccessRequestByID(requestInfo RequestInfo, org string, id string) (*AccessRequest, error)
+
+	// Retrieve access requests of an organization filtered by status (optional parameter). It returns
+	// own requests and requests that the authenticated user is allowed to review.
+	// Throw error if the input parameters are invalid or unexpected error happen.
+	ListAccessRequests(requestInfo RequestInfo, org string, status string) ([]AccessRequest, error)
+
+	// Approve a pending access request of an organization granting the requested membership or policy
+	// attachment until the requested duration expires. Throw error if access request doesn't exist in the
+	// organization, it was already reviewed, user isn't allowed to approve it or unexpected error happen.
+	ApproveAccessRequest(requestInfo RequestInfo, org string, id string, comment string) (*AccessRequest, error)
+
+	// Reject a pending access request of an organization. Throw error if access request doesn't exist in the
+	// organization, it was already reviewed, user isn't allowed to reject it or unexpected error happen.
+	RejectAccessRequest(requestInfo RequestInfo, org string, id string, comment string) (*AccessRequest, error)
+}
+
+type ActionAPI interface {
+	// Register a service namespace. Only admin can do it. Throw error when the input parameters
+	// are invalid, the namespace already exist or unexpected error happen.
+	AddNamespace(requestInfo RequestInfo, name string, description string) (*Namespace, error)
+
+	// Retrieve all namespaces, built-in ones included. Throw error if unexpected error happen.
+	ListNamespaces(requestInfo RequestInfo) ([]Namespace, error)
+
+	// Remove namespace with its actions. Only admin can do it. Throw error if the input parameters are
+	// invalid, namespace is built-in, namespace doesn't exist or unexpected error happen.
+	RemoveNamespace(requestInfo RequestInfo, name string) error
+
+	// Register an action in a namespace with the resource URN pattern it applies to (optional parameter).
+	// Only admin can do it. Throw error when the input parameters are invalid, namespace doesn't exist
+	// or is built-in, the action already exist or unexpected error happen.
+	AddAction(requestInfo RequestInfo, namespace string, name string, description string, resourceUrnPattern string) (*Action, error)
+
+	// Retrieve action from namespace. Throw error when the input parameters are invalid,
+	// action doesn't exist or unexpected error happen.
+	GetAction(requestInfo RequestInfo, namespace string, name string) (*Action, error)
+
+	// Retrieve actions of a namespace. Throw error when the input parameters are invalid,
+	// namespace doesn't exist or unexpected error happen.
+	ListActions(requestInfo RequestInfo, namespace string) ([]Action, error)
+
+	// Remove action from namespace. Only admin can do it. Throw error when the input parameters
+	// are invalid, namespace is built-in, action doesn't exist or unexpected error happen.
+	RemoveAction(requestInfo RequestInfo, namespace string, name string) error
+}
+
+type ResourceTypeAPI interface {
+	// Register a resource type in a namespace with the URN template its resources must conform to.
+	// Only admin can do it. Throw error when the input parameters are invalid, namespace doesn't exist
+	// or is built-in, the resource type already exist or unexpected error happen.
+	AddResourceType(requestInfo RequestInfo, namespace string, name string, description string, urnTemplate string) (*ResourceType, error)
+
+	// Retrieve resource type from namespace. Throw error when the input parameters are invalid,
+	// resource type doesn't exist or unexpected error happen.
+	GetResourceType(requestInfo RequestInfo, namespace string, name string) (*ResourceType, error)
+
+	// Retrieve resource types of a namespace. Throw error when the input parameters are invalid,
+	// namespace doesn't exist or unexpected error happen.
+	ListResourceTypes(requestInfo RequestInfo, namespace string) ([]ResourceType, error)
+
+	// Remove resource type from namespace. Only admin can do it. Throw error when the input parameters
+	// are invalid, namespace is built-in, resource type doesn't exist or unexpected error happen.
+	RemoveResourceType(requestInfo RequestInfo, namespace string, name string) error
+}
+
+type BatchAPI interface {
+	// Execute a list of user, group membership and policy attachment operations in order, returning the
+	// result of each one. In atomic mode operations run inside one transaction that is rolled back when
+	// one of them fails. Throw error if the input parameters are invalid or unexpected error happen.
+	Batch(requestInfo RequestInfo, operations []BatchOperation, atomic bool) ([]BatchResult, error)
+}
+
+type OrganizationAPI interface {
+	// Store organization in database. Throw error when parameters are invalid,
+	// organization already exists or unexpected error happen.
+	AddOrganization(requestInfo RequestInfo, name string, description string) (*Organization, error)
+
+	// Retrieve organization from database. Throw error when parameter is invalid,
+	// organization doesn't exist or unexpected error happen.
+	GetOrganizationByName(requestInfo RequestInfo, name string) (*Organization, error)
+
+	// Retrieve a page of organizations from database filtered by name and creation date filter fields (optional),
+	// and the total number of organizations that match the filter. Throw error if filter is invalid or unexpected
+	// error happen.
+	ListOrganizations(requestInfo RequestInfo, filter *Filter) ([]Organization, int, error)
+
+	// Update organization stored in database with new description. Throw error if the input parameters
+	// are invalid, organization doesn't exist or unexpected error happen.
+	UpdateOrganization(requestInfo RequestInfo, name string, newDescription string) (*Organization, error)
+
+	// Remove organization stored in database with all its groups and policies, which are moved to the trash,
+	// inside one transaction. Throw error if the input parameter is invalid, organization doesn't exist or
+	// unexpected error happen.
+	RemoveOrganization(requestInfo RequestInfo, name string) error
+
+	// Retrieve a portable document with all groups and policies of an organization, including group
+	// memberships and policy attachments. Only admin can do it. Throw error if the input parameters
+	// are invalid or unexpected error happen.
+	ExportOrganization(requestInfo RequestInfo, org string) (*OrganizationDocument, error)
+
+	// Recreate groups and policies of a document in an organization inside one transaction. Resources that
+	// already exist are skipped, overwritten or make the import fail depending on the conflict mode. Only
+	// admin can do it. Throw error if the input parameters are invalid, a referenced user or policy doesn't
+	// exist, a resource already exists in fail mode or unexpected error happen.
+	ImportOrganization(requestInfo RequestInfo, org string, document *OrganizationDocument, conflictMode string) (*ImportResult, error)
+
+	// Compute the changes needed to make the organization match the desired state of a document: groups,
+	// policies, members and attachments to create, update or delete. Only admin can do it. Throw error if
+	// the input parameters are invalid or unexpected error happen.
+	PlanOrganization(requestInfo RequestInfo, org string, document *OrganizationDocument) (*Plan, error)
+
+	// Apply the changes needed to make the organization match the desired state of a document inside one
+	// transaction, returning the applied plan. Changes are applied only if they are the confirmed plan.
+	// Only admin can do it. Throw error if the input parameters are invalid, organization changed since the
+	// plan was confirmed, a referenced user doesn't exist or unexpected error happen.
+	ApplyOrganization(requestInfo RequestInfo, org string, document *OrganizationDocument, confirmedPlan *Plan) (*Plan, error)
+}
+
+type TrashAPI interface {
+	// Retrieve a page of removed users, groups and policies filtered by type (optional), org and name filter
+	// fields, and the total number of them. Only admin can do it. Throw error if the input parameters are
+	// invalid or unexpected error happen.
+	ListDeletedResources(requestInfo RequestInfo, resourceType string, filter *Filter) ([]DeletedResource, int, error)
+
+	// Retrieve removed user, group or policy from the trash. Only admin can do it. Throw error if it
+	// doesn't exist or unexpected error happen.
+	GetDeletedResource(requestInfo RequestInfo, id string) (*DeletedResource, error)
+
+	// Store removed user, group or policy again with its relationships to users, groups and policies that
+	// still exist. Only admin can do it. Throw error if it doesn't exist in the trash, a resource with the
+	// same name has been created since it was removed or unexpected error happen.
+	RestoreDeletedResource(requestInfo RequestInfo, id string) (*DeletedResource, error)
+
+	// Permanently remove users, groups and policies whose retention period in the trash is over, returning
+	// the number of them. Only admin can do it. Throw error if unexpected error happen.
+	PurgeDeletedResources(requestInfo RequestInfo) (int, error)
+}
+
+type ConsistencyAPI interface {
+	// Retrieve the inconsistencies between statements, relations and the users, groups and policies
+	// they refer to. Only admin can do it. Throw error if unexpected error happen.
+	CheckConsistency(requestInfo RequestInfo) (*ConsistencyReport, error)
+}
+
+type AuditAPI interface {
+	// Retrieve a page of audit events filtered by actor, entity URN and action (all optional) and the
+	// created after and before filter fields, last stored first, and the total number of them. Only admin
+	// can do it. Throw error if the input parameters are invalid or unexpected error happen.
+	ListAuditEvents(requestInfo RequestInfo, actor string, urn string, action string, filter *Filter) ([]AuditEvent, int, error)
+}
+
+type WebhookAPI interface {
+	// Store webhook subscribed to the actions of events, prefixes allowed. Only admin can do it. Throw error
+	// if the input parameters are invalid or unexpected error happen.
+	AddWebhook(requestInfo RequestInfo, url string, secret string, events []string) (*Webhook, error)
+
+	// Retrieve webhook from database. Only admin can do it. Throw error if webhook doesn't exist
+	// or unexpected error happen.
+	GetWebhookByID(requestInfo RequestInfo, id string) (*Webhook, error)
+
+	// Retrieve a page of webhooks using offset and limit filter fields, and the total number of them.
+	// Only admin can do it. Throw error if filter is invalid or unexpected error happen.
+	ListWebhooks(requestInfo RequestInfo, filter *Filter) ([]Webhook, int, error)
+
+	// Update webhook with new url, secret and events. Current secret is kept if the new one is empty.
+	// Only admin can do it. Throw error if the input parameters are invalid, webhook doesn't exist or
+	// unexpected error happen.
+	UpdateWebhook(requestInfo RequestInfo, id string, newUrl string, newSecret string, newEvents []string) (*Webhook, error)
+
+	// Remove webhook with its deliveries. Only admin can do it. Throw error if webhook doesn't exist
+	// or unexpected error happen.
+	RemoveWebhook(requestInfo RequestInfo, id string) error
+
+	// Retrieve a page of webhook deliveries filtered by status (optional), last created first, and the
+	// total number of them. Only admin can do it. Throw error if the input parameters are invalid,
+	// webhook doesn't exist or unexpected error happen.
+	ListWebhookDeliveries(requestInfo RequestInfo, id string, status string, filter *Filter) ([]WebhookDelivery, int, error)
+
+	// Send the pending deliveries whose next attempt is due, returning the number of them delivered. Failed
+	// attempts are retried later until the maximum attempts are reached. Only admin can do it. Throw error
+	// if unexpected error happen.
+	DeliverWebhookEvents(requestInfo RequestInfo) (int, error)
 }
 
 type AuthzAPI interface {
@@ -134,9 +480,11 @@ type AuthzAPI interface {
 	// if requestInfo doesn't exist, requestInfo doesn't have access to any resources or unexpected error happen.
 	GetAuthorizedPolicies(requestInfo RequestInfo, resourceUrn string, action string, policies []Policy) ([]Policy, error)
 
-	// Retrieve list of authorized external resources filtered according to the input parameters. Throw error
-	// if requestInfo doesn't exist, requestInfo doesn't have access to any resources or unexpected error happen.
-	GetAuthorizedExternalResources(requestInfo RequestInfo, action string, resources []string) ([]string, error)
+	// Retrieve list of authorized external resources filtered according to the input parameters. Resource tags
+	// (optional parameter) are used to evaluate statement conditions. Throw error if requestInfo doesn't exist,
+	// requestInfo doesn't have access to any resources or unexpected error happen.
+	GetAuthorizedExternalResources(requestInfo RequestInfo, action string, resources []string,
+		resourceTags map[string]string) ([]string, error)
 }
 
 // REPOSITORY INTERFACES
@@ -149,21 +497,30 @@ type UserRepo interface {
 	// Retrieve user from database if it exists. Otherwise it throws an error.
 	GetUserByExternalID(id string) (*User, error)
 
-	// Retrieve user list from database filtered by pathPrefix optional parameter. Throw error
-	// if there are problems with database.
-	GetUsersFiltered(pathPrefix string) ([]User, error)
+	// Retrieve a page of users from database filtered by pathPrefix and tags filter fields, and the total
+	// number of users that match the filter. Throw error if there are problems with database.
+	GetUsersFiltered(filter *Filter) ([]User, int, error)
 
-	// Update user stored in database with new pathPrefix. Throw error if the database restrictions
-	// are not satisfied or unexpected error happen.
-	UpdateUser(user User, newPath string, newUrn string) (*User, error)
+	// Update user stored in database with new pathPrefix and profile. Throw error if the database restrictions
+	// are not satisfied, user revision changed or unexpected error happen.
+	UpdateUser(user User, newPath string, newUrn string, newProfile UserProfile) (*User, error)
 
-	// Remove user stored in database with its group relationships.
-	// Throw error if there are problems during transactions.
-	RemoveUser(id string) error
+	// Update status of user stored in database. Throw error if user revision changed or there are
+	// problems with database.
+	UpdateUserStatus(user User, status string) (*User, error)
 
-	// Retrieve groups that belong to the user. Throw error
-	// if there are problems with database.
-	GetGroupsByUserID(id string) ([]Group, error)
+	// Update externalId and URN of user stored in database, and the access requests that it requested or
+	// reviewed. Throw error if user revision changed or there are problems with database.
+	UpdateUserExternalID(user User, newExternalId string, newUrn string) (*User, error)
+
+	// Remove user stored in database with its group relationships and tags, storing them in the trash.
+	// Throw error if user revision changed or there are problems during transactions.
+	RemoveUser(user User) error
+
+	// Retrieve a page of groups that belong to the user, skipping expired memberships, and the total
+	// number of groups. It includes the groups whose membership rule matches the user.
+	// Throw error if there are problems with database.
+	GetGroupsByUserID(id string, filter *Filter) ([]Group, int, error)
 }
 
 // Group repository that contains all database operations
@@ -174,36 +531,55 @@ type GroupRepo interface {
 	// Retrieve group from database if it exists. Otherwise it throws an error.
 	GetGroupByName(org string, name string) (*Group, error)
 
-	// Retrieve groups from database filtered by org and pathPrefix optional parameters. Throw error
-	// if there are problems with database.
-	GetGroupsFiltered(org string, pathPrefix string) ([]Group, error)
+	// Retrieve a page of groups from database filtered by org, pathPrefix and tags filter fields, and the total
+	// number of groups that match the filter. Throw error if there are problems with database.
+	GetGroupsFiltered(filter *Filter) ([]Group, int, error)
 
 	// Update group stored in database with new name and pathPrefix.
-	// Throw error if there are problems with database.
+	// Throw error if group revision changed or there are problems with database.
 	UpdateGroup(group Group, newName string, newPath string, newUrn string) (*Group, error)
 
-	// Remove group stored in database with its user and policy relationships.
-	// Throw error if there are problems during transactions.
-	RemoveGroup(groupID string) error
+	// Remove group stored in database with its user, owner and policy relationships and tags, storing them in
+	// the trash. Throw error if group revision changed or there are problems during transactions.
+	RemoveGroup(group Group) error
 
-	// Add new member to group. It doesn't check restrictions about existence of group or user. It throws
-	// errors if there are problems with database.
-	AddMember(userID string, groupID string) error
+	// Update the membership rule of the group, removing its explicit members if rule isn't nil.
+	// Throw error if group revision changed or there are problems with database.
+	SetGroupMembershipRule(group Group, rule *MembershipRule) (*Group, error)
+
+	// Add new member to group with an optional expiration date. It doesn't check restrictions about
+	// existence of group or user. It throws errors if there are problems with database.
+	AddMember(userID string, groupID string, expiresAt *time.Time) error
 
 	// Remove member from group. It doesn't check restrictions about existence of group or user. It throws
 	// errors if there are problems with database.
 	RemoveMember(userID string, groupID string) error
 
-	// Check if user is member of group. It returns true if at least one relation exists. It throws
+	// Add owner to group. It doesn't check restrictions about existence of group or user. It throws
+	// errors if there are problems with database.
+	AddOwner(userID string, groupID string) error
+
+	// Remove owner from group. It doesn't check restrictions about existence of group or user. It throws
 	// errors if there are problems with database.
+	RemoveOwner(userID string, groupID string) error
+
+	// Check if user is member of group. It returns true if at least one relation that
+	// hasn't expired exists, or if user matches the group membership rule. It throws errors
+	// if there are problems with database.
 	IsMemberOfGroup(userID string, groupID string) (bool, error)
 
-	// Retrieve users that belong to the group. Throw error if there are problems with database.
-	GetGroupMembers(groupID string) ([]User, error)
+	// Retrieve a page of users that belong to the group, skipping expired memberships, and the total
+	// number of members. Members of groups with a membership rule are the users that match it.
+	// Throw error if there are problems with database.
+	GetGroupMembers(groupID string, filter *Filter) ([]User, int, error)
 
-	// Attach policy to group. It doesn't check restrictions about existence of group or policy. It throws
-	// errors if there are problems with database.
-	AttachPolicy(groupID string, policyID string) error
+	// Retrieve a page of group user relations with their expiration dates, skipping expired memberships,
+	// and the total number of relations. Throw error if there are problems with database.
+	GetGroupUserRelations(groupID string, filter *Filter) ([]GroupUserRelation, int, error)
+
+	// Attach policy to group with an optional activation window. It doesn't check restrictions about
+	// existence of group or policy. It throws errors if there are problems with database.
+	AttachPolicy(groupID string, policyID string, notBefore *time.Time, notAfter *time.Time) error
 
 	// Detach policy from group. It doesn't check restrictions about existence of group or policy. It throws
 	// errors if there are problems with database.
@@ -213,8 +589,13 @@ type GroupRepo interface {
 	// errors if there are problems with database.
 	IsAttachedToGroup(groupID string, policyID string) (bool, error)
 
-	// Retrieve policies that are attached to the group. Throw error if there are problems with database.
-	GetAttachedPolicies(groupID string) ([]Policy, error)
+	// Retrieve a page of policies that are attached to the group and whose activation window includes
+	// current time, and the total number of them. Throw error if there are problems with database.
+	GetAttachedPolicies(groupID string, filter *Filter) ([]Policy, int, error)
+
+	// Retrieve a page of group policy relations with their activation windows, active or not, and the
+	// total number of relations. Throw error if there are problems with database.
+	GetGroupPolicyRelations(groupID string, filter *Filter) ([]GroupPolicyRelation, int, error)
 }
 
 // Policy repository that contains all database operations
@@ -225,18 +606,223 @@ type PolicyRepo interface {
 	// Retrieve policy from database if it exists. Otherwise it throws an error.
 	GetPolicyByName(org string, name string) (*Policy, error)
 
-	// Retrieve policies from database filtered by org and pathPrefix optional parameters. Throw error
-	// if there are problems with database.
-	GetPoliciesFiltered(org string, pathPrefix string) ([]Policy, error)
+	// Retrieve a page of policies from database filtered by org, pathPrefix and tags filter fields, and the total
+	// number of policies that match the filter. Throw error if there are problems with database.
+	GetPoliciesFiltered(filter *Filter) ([]Policy, int, error)
 
 	// Update policy stored in database with new name and pathPrefix. Also it overrides statements.
-	// Throw error if there are problems with database.
+	// Throw error if policy revision changed or there are problems with database.
 	UpdatePolicy(policy Policy, newName string, newPath string, newUrn string, newStatements []Statement) (*Policy, error)
 
-	// Remove policy stored in database with its groups relationships.
+	// Remove policy stored in database with its statements, groups relationships and tags, storing them
+	// in the trash. Throw error if policy revision changed or there are problems during transactions.
+	RemovePolicy(policy Policy) error
+
+	// Retrieve a page of groups that are attached to the policy and the total number of them.
+	// Throw error if there are problems with database.
+	GetAttachedGroups(policyID string, filter *Filter) ([]Group, int, error)
+
+	// Retrieve policies with a statement that has the urn in its resources, ordered by org and name.
+	// Throw error if there are problems with database.
+	GetPoliciesReferencingUrn(urn string) ([]Policy, error)
+}
+
+// Organization repository that contains all database operations
+type OrganizationRepo interface {
+	// Store organization in database if there aren't errors.
+	AddOrganization(organization Organization) (*Organization, error)
+
+	// Retrieve organization from database if it exists. Otherwise it throws an error.
+	GetOrganizationByName(name string) (*Organization, error)
+
+	// Retrieve a page of organizations from database filtered by name and creation date filter fields, and the
+	// total number of organizations that match the filter. Throw error if there are problems with database.
+	GetOrganizationsFiltered(filter *Filter) ([]Organization, int, error)
+
+	// Update organization stored in database with new description. Throw error if there are problems with database.
+	UpdateOrganization(organization Organization, newDescription string) (*Organization, error)
+
+	// Remove organization stored in database. Its groups and policies aren't removed.
+	// Throw error if there are problems with database.
+	RemoveOrganization(id string) error
+}
+
+// Access request repository that contains all database operations
+type AccessRequestRepo interface {
+	// Store access request in database if there aren't errors.
+	AddAccessRequest(accessRequest AccessRequest) (*AccessRequest, error)
+
+	// Retrieve access request from database if it exists. Otherwise it throws an error.
+	GetAccessRequestByID(id string) (*AccessRequest, error)
+
+	// Retrieve access requests from database filtered by org and status optional parameters. Throw error
+	// if there are problems with database.
+	GetAccessRequestsFiltered(org string, status string) ([]AccessRequest, error)
+
+	// Update review fields of access request stored in database while it's pending. Throw error if it isn't
+	// pending anymore or there are problems with database.
+	UpdateAccessRequest(accessRequest AccessRequest) (*AccessRequest, error)
+
+	// Update requester of pending access requests stored in database, used when the requester is renamed.
+	// Throw error if there are problems with database.
+	UpdateAccessRequestsRequester(requester string, newRequester string) error
+}
+
+// Action registry repository that contains all database operations
+type ActionRepo interface {
+	// Store namespace in database if there aren't errors.
+	AddNamespace(namespace Namespace) (*Namespace, error)
+
+	// Retrieve namespace from database if it exists. Otherwise it throws an error.
+	GetNamespaceByName(name string) (*Namespace, error)
+
+	// Retrieve all namespaces from database. Throw error if there are problems with database.
+	GetNamespaces() ([]Namespace, error)
+
+	// Remove namespace stored in database with its actions.
 	// Throw error if there are problems during transactions.
-	RemovePolicy(id string) error
+	RemoveNamespace(name string) error
+
+	// Store action in database if there aren't errors.
+	AddAction(action Action) (*Action, error)
+
+	// Retrieve action from database if it exists. Otherwise it throws an error.
+	GetActionByName(namespace string, name string) (*Action, error)
+
+	// Retrieve actions of a namespace from database. Throw error if there are problems with database.
+	GetActionsFiltered(namespace string) ([]Action, error)
+
+	// Remove action stored in database. Throw error if there are problems with database.
+	RemoveAction(id string) error
+}
 
-	// Retrieve groups that are attached to the policy. Throw error if there are problems with database.
-	GetAttachedGroups(policyID string) ([]Group, error)
+// Resource type registry repository that contains all database operations
+type ResourceTypeRepo interface {
+	// Store resource type in database if there aren't errors.
+	AddResourceType(resourceType ResourceType) (*ResourceType, error)
+
+	// Retrieve resource type from database if it exists. Otherwise it throws an error.
+	GetResourceTypeByName(namespace string, name string) (*ResourceType, error)
+
+	// Retrieve resource types of a namespace from database, or all of them if namespace is empty.
+	// Throw error if there are problems with database.
+	GetResourceTypesFiltered(namespace string) ([]ResourceType, error)
+
+	// Remove resource type stored in database. Throw error if there are problems with database.
+	RemoveResourceType(id string) error
+}
+
+// Tag repository that contains all database operations. Tags are shared by users, groups and
+// policies, which are identified by their ids.
+type TagRepo interface {
+	// Store tag of a resource, replacing its value if the resource already has it.
+	// Throw error if there are problems with database.
+	SetTag(resourceID string, key string, value string) error
+
+	// Remove tag of a resource. Throw error if there are problems with database.
+	RemoveTag(resourceID string, key string) error
+}
+
+// Trash repository with the users, groups and policies removed by their repositories
+type TrashRepo interface {
+	// Retrieve a page of deleted resources filtered by type (optional), org and name filter fields, last
+	// deleted first, and the total number of them. Throw error if there are problems with database.
+	GetDeletedResourcesFiltered(resourceType string, filter *Filter) ([]DeletedResource, int, error)
+
+	// Retrieve deleted resource from database if it exists. Otherwise it throws an error.
+	GetDeletedResourceByID(id string) (*DeletedResource, error)
+
+	// Store deleted resource again with its tags and its relationships to users, groups and policies that
+	// still exist, and remove it from the trash. Throw error if there are problems during transactions.
+	RestoreDeletedResource(id string) error
+
+	// Permanently remove resources deleted before the date and return the number of them.
+	// Throw error if there are problems with database.
+	PurgeDeletedResources(deletedBefore time.Time) (int, error)
+}
+
+// Consistency repository with checks across all tables
+type ConsistencyRepo interface {
+	// Retrieve statements that reference users, groups and policies that don't exist, relations with
+	// groups, users and policies that don't exist, policies without statements and groups in organizations
+	// without policies. Throw error if there are problems with database.
+	GetConsistencyReport() (*ConsistencyReport, error)
+}
+
+// Audit repository with the create, update and delete operations performed by users
+type AuditRepo interface {
+	// Store audit event in database. Throw error if there are problems with database.
+	AddAuditEvent(event AuditEvent) (*AuditEvent, error)
+
+	// Retrieve a page of audit events filtered by actor, entity URN and action (all optional) and the
+	// created after and before filter fields, last stored first, and the total number of them.
+	// Throw error if there are problems with database.
+	GetAuditEventsFiltered(actor string, urn string, action string, filter *Filter) ([]AuditEvent, int, error)
+}
+
+// Webhook repository with the webhooks and the deliveries of the audit events to them
+type WebhookRepo interface {
+	// Store webhook in database. Throw error if there are problems with database.
+	AddWebhook(webhook Webhook) (*Webhook, error)
+
+	// Retrieve webhook from database. Throw error if webhook doesn't exist or there are problems with database.
+	GetWebhookByID(id string) (*Webhook, error)
+
+	// Retrieve a page of webhooks using offset and limit filter fields, first created first, and the total
+	// number of them. Throw error if there are problems with database.
+	GetWebhooksFiltered(filter *Filter) ([]Webhook, int, error)
+
+	// Update url, secret and events of the webhook. Throw error if there are problems with database.
+	UpdateWebhook(webhook Webhook) (*Webhook, error)
+
+	// Remove webhook with its deliveries. Throw error if there are problems with database.
+	RemoveWebhook(id string) error
+
+	// Store webhook delivery in database. Throw error if there are problems with database.
+	AddWebhookDelivery(delivery WebhookDelivery) (*WebhookDelivery, error)
+
+	// Update status, attempts and last attempt result of the webhook delivery. Throw error if there are
+	// problems with database.
+	UpdateWebhookDelivery(delivery WebhookDelivery) (*WebhookDelivery, error)
+
+	// Retrieve a page of deliveries of the webhook filtered by status (optional), last created first, and
+	// the total number of them. Throw error if there are problems with database.
+	GetWebhookDeliveriesFiltered(webhookID string, status string, filter *Filter) ([]WebhookDelivery, int, error)
+
+	// Claim up to limit pending deliveries whose next attempt is before the date, oldest first, postponing
+	// their next attempt until the lease ends. Deliveries claimed by a concurrent call are skipped.
+	// Throw error if there are problems with database.
+	ClaimPendingWebhookDeliveries(before time.Time, leaseUntil time.Time, limit int) ([]WebhookDelivery, error)
+}
+
+// Destination of the authorization decisions, like a log file or a webhook
+type DecisionLogger interface {
+	// Record the decision. It mustn't block the authorization, so delivery errors are handled
+	// by the implementation.
+	LogDecision(decision AuthzDecision)
+}
+
+// Repository with all database operations
+type Repo interface {
+	UserRepo
+	GroupRepo
+	PolicyRepo
+	OrganizationRepo
+	AccessRequestRepo
+	ActionRepo
+	ResourceTypeRepo
+	TagRepo
+	TrashRepo
+	AuditRepo
+	WebhookRepo
+	// Operations that run their own transaction join the transaction the repository is bound to
+	TransactionRepo
+}
+
+// Transaction repository to run several database operations atomically
+type TransactionRepo interface {
+	// Call the function with a repository bound to a new transaction. The transaction is committed if
+	// the function doesn't return an error, otherwise it is rolled back and the error is returned.
+	// Throw error if there are problems with database.
+	RunInTransaction(fn func(repo Repo) error) error
 }
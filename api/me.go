@@ -0,0 +1,124 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/tecsisa/foulkon/database"
+)
+
+// TYPE DEFINITIONS
+
+// Statement granted to the authenticated user by a policy attached to one of its groups
+type Permission struct {
+	Org       string    `json:"org, omitempty"`
+	Group     string    `json:"group, omitempty"`
+	Policy    string    `json:"policy, omitempty"`
+	Statement Statement `json:"statement, omitempty"`
+}
+
+// AUTHENTICATED USER API IMPLEMENTATION
+
+func (api AuthAPI) GetAuthenticatedUser(requestInfo RequestInfo) (*User, error) {
+	return api.getAuthenticatedUser(requestInfo)
+}
+
+func (api AuthAPI) ListAuthenticatedUserGroups(requestInfo RequestInfo, filter *Filter) ([]GroupIdentity, int, error) {
+	// Validate filter
+	if err := validateFilter(filter); err != nil {
+		return nil, 0, err
+	}
+
+	user, err := api.getAuthenticatedUser(requestInfo)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Call group repo to retrieve groups associated to user
+	groups, total, err := api.UserRepo.GetGroupsByUserID(user.ID, filter)
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, 0, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	// Transform to identifiers
+	groupIDs := []GroupIdentity{}
+	for _, g := range groups {
+		groupIDs = append(groupIDs, GroupIdentity{
+			Org:  g.Org,
+			Name: g.Name,
+		})
+	}
+
+	return groupIDs, total, nil
+}
+
+func (api AuthAPI) ListAuthenticatedUserPermissions(requestInfo RequestInfo) ([]Permission, error) {
+	user, err := api.getAuthenticatedUser(requestInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every action is denied to suspended users
+	permissions := []Permission{}
+	if user.Status == USER_STATUS_SUSPENDED {
+		return permissions, nil
+	}
+
+	groups, err := api.getGroupsByUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Retrieve statements of the policies attached to each group, only those in their activation window
+	for _, group := range groups {
+		policies, err := api.getPoliciesByGroups([]Group{group})
+		if err != nil {
+			return nil, err
+		}
+		for _, policy := range policies {
+			if policy.Statements == nil {
+				continue
+			}
+			for _, statement := range *policy.Statements {
+				permissions = append(permissions, Permission{
+					Org:       group.Org,
+					Group:     group.Name,
+					Policy:    policy.Name,
+					Statement: statement,
+				})
+			}
+		}
+	}
+
+	return permissions, nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Retrieve the user that is making the request without authorization checks, users are always allowed to see themselves
+func (api AuthAPI) getAuthenticatedUser(requestInfo RequestInfo) (*User, error) {
+	user, err := api.UserRepo.GetUserByExternalID(requestInfo.Identifier)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.USER_NOT_FOUND:
+			return nil, &Error{
+				Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: fmt.Sprintf("Authenticated user with externalId %v not found", requestInfo.Identifier),
+			}
+		default:
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+	return user, nil
+}
@@ -0,0 +1,410 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestAuthAPI_GetAuthenticatedUser(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		// Expected result
+		expectedResponse *User
+		wantError        error
+		// Manager Results
+		getUserByExternalIDMethodResult *User
+		// Manager Errors
+		getUserByExternalIDMethodErr error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+				Admin:      false,
+			},
+			expectedResponse: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+				Admin:      false,
+			},
+			wantError: &Error{
+				Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "Authenticated user with externalId 1234 not found",
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code: database.USER_NOT_FOUND,
+			},
+		},
+		"ErrorCaseInternalError": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+				Admin:      false,
+			},
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDMethodResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
+
+		user, err := testAPI.GetAuthenticatedUser(testcase.requestInfo)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, user)
+	}
+}
+
+func TestAuthAPI_ListAuthenticatedUserGroups(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		filter      *Filter
+		// Expected result
+		expectedResponse []GroupIdentity
+		expectedTotal    int
+		wantError        error
+		// Manager Results
+		getUserByExternalIDMethodResult *User
+		getGroupsByUserIDMethodResult   []Group
+		getGroupsByUserIDMethodTotal    int
+		// Manager Errors
+		getUserByExternalIDMethodErr error
+		getGroupsByUserIDMethodErr   error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+				Admin:      false,
+			},
+			expectedResponse: []GroupIdentity{
+				{
+					Org:  "org1",
+					Name: "group1",
+				},
+				{
+					Org:  "org2",
+					Name: "group2",
+				},
+			},
+			expectedTotal: 2,
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getGroupsByUserIDMethodResult: []Group{
+				{
+					ID:   "GROUP-USER-ID-1",
+					Name: "group1",
+					Path: "/path/1/",
+					Org:  "org1",
+					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/1/", "group1"),
+				},
+				{
+					ID:   "GROUP-USER-ID-2",
+					Name: "group2",
+					Path: "/path/2/",
+					Org:  "org2",
+					Urn:  CreateUrn("org2", RESOURCE_GROUP, "/path/2/", "group2"),
+				},
+			},
+			getGroupsByUserIDMethodTotal: 2,
+		},
+		"ErrorCaseInvalidFilter": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+				Admin:      false,
+			},
+			filter: &Filter{Limit: -1},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Limit -1, max limit allowed: 1000",
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+				Admin:      false,
+			},
+			wantError: &Error{
+				Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "Authenticated user with externalId 1234 not found",
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code: database.USER_NOT_FOUND,
+			},
+		},
+		"ErrorCaseGetGroupsDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+				Admin:      false,
+			},
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+			},
+			getGroupsByUserIDMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDMethodResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDMethodResult
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][1] = testcase.getGroupsByUserIDMethodTotal
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][2] = testcase.getGroupsByUserIDMethodErr
+		filter := testcase.filter
+		if filter == nil {
+			filter = &Filter{}
+		}
+		groups, total, err := testAPI.ListAuthenticatedUserGroups(testcase.requestInfo, filter)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, groups)
+		if testcase.wantError == nil && total != testcase.expectedTotal {
+			t.Errorf("Test %v failed. Received different total (wanted:%v / received:%v)", x, testcase.expectedTotal, total)
+		}
+	}
+}
+
+func TestAuthAPI_ListAuthenticatedUserPermissions(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		// Expected result
+		expectedResponse []Permission
+		wantError        error
+		// Manager Results
+		getUserByExternalIDMethodResult *User
+		getGroupsByUserIDMethodResult   []Group
+		getAttachedPoliciesMethodResult []Policy
+		// Manager Errors
+		getUserByExternalIDMethodErr error
+		getGroupsByUserIDMethodErr   error
+		getAttachedPoliciesMethodErr error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+				Admin:      false,
+			},
+			expectedResponse: []Permission{
+				{
+					Org:    "org1",
+					Group:  "group1",
+					Policy: "policyUser",
+					Statement: Statement{
+						Effect:    "allow",
+						Actions:   []string{USER_ACTION_GET_USER},
+						Resources: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+					},
+				},
+				{
+					Org:    "org1",
+					Group:  "group1",
+					Policy: "policyUser",
+					Statement: Statement{
+						Effect:    "deny",
+						Actions:   []string{USER_ACTION_LIST_GROUPS_FOR_USER},
+						Resources: []string{CreateUrn("", RESOURCE_USER, "/path/", "1234")},
+					},
+				},
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+				Status:     USER_STATUS_ACTIVE,
+			},
+			getGroupsByUserIDMethodResult: []Group{
+				{
+					ID:   "GROUP-USER-ID-1",
+					Name: "group1",
+					Path: "/path/1/",
+					Org:  "org1",
+					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/1/", "group1"),
+				},
+			},
+			getAttachedPoliciesMethodResult: []Policy{
+				{
+					ID:   "POLICY-USER-ID",
+					Name: "policyUser",
+					Path: "/path/",
+					Org:  "org1",
+					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+					Statements: &[]Statement{
+						{
+							Effect:    "allow",
+							Actions:   []string{USER_ACTION_GET_USER},
+							Resources: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+						},
+						{
+							Effect:    "deny",
+							Actions:   []string{USER_ACTION_LIST_GROUPS_FOR_USER},
+							Resources: []string{CreateUrn("", RESOURCE_USER, "/path/", "1234")},
+						},
+					},
+				},
+			},
+		},
+		"OkCaseWithoutGroups": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+				Admin:      false,
+			},
+			expectedResponse: []Permission{},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Status:     USER_STATUS_ACTIVE,
+			},
+		},
+		"OkCaseSuspendedUser": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+				Admin:      false,
+			},
+			expectedResponse: []Permission{},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Status:     USER_STATUS_SUSPENDED,
+			},
+			getGroupsByUserIDMethodResult: []Group{
+				{
+					ID:   "GROUP-USER-ID-1",
+					Name: "group1",
+					Path: "/path/1/",
+					Org:  "org1",
+				},
+			},
+			getAttachedPoliciesMethodResult: []Policy{
+				{
+					ID:   "POLICY-USER-ID",
+					Name: "policyUser",
+					Path: "/path/",
+					Org:  "org1",
+					Statements: &[]Statement{
+						{
+							Effect:    "allow",
+							Actions:   []string{USER_ACTION_GET_USER},
+							Resources: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+						},
+					},
+				},
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+				Admin:      false,
+			},
+			wantError: &Error{
+				Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "Authenticated user with externalId 1234 not found",
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code: database.USER_NOT_FOUND,
+			},
+		},
+		"ErrorCaseGetGroupsDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+				Admin:      false,
+			},
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Status:     USER_STATUS_ACTIVE,
+			},
+			getGroupsByUserIDMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+		"ErrorCaseGetAttachedPoliciesDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+				Admin:      false,
+			},
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Status:     USER_STATUS_ACTIVE,
+			},
+			getGroupsByUserIDMethodResult: []Group{
+				{
+					ID:   "GROUP-USER-ID-1",
+					Name: "group1",
+					Path: "/path/1/",
+					Org:  "org1",
+				},
+			},
+			getAttachedPoliciesMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDMethodResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDMethodResult
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][2] = testcase.getGroupsByUserIDMethodErr
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesMethodResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][2] = testcase.getAttachedPoliciesMethodErr
+
+		permissions, err := testAPI.ListAuthenticatedUserPermissions(testcase.requestInfo)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, permissions)
+	}
+}
@@ -0,0 +1,980 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/satori/go.uuid"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// TYPE DEFINITIONS
+
+const (
+	// Version of the organization document format
+	ORGANIZATION_DOCUMENT_VERSION = "1"
+	// Encoding of the organization document, the only one supported
+	ORGANIZATION_DOCUMENT_FORMAT = "json"
+
+	// Conflict modes to import resources that already exist
+	IMPORT_CONFLICT_SKIP      = "skip"
+	IMPORT_CONFLICT_OVERWRITE = "overwrite"
+	IMPORT_CONFLICT_FAIL      = "fail"
+)
+
+// Organization domain. Groups and policies can only be created in existing organizations
+type Organization struct {
+	ID          string    `json:"id, omitempty"`
+	Name        string    `json:"name, omitempty"`
+	Description string    `json:"description, omitempty"`
+	Urn         string    `json:"urn, omitempty"`
+	CreateAt    time.Time `json:"createAt, omitempty"`
+	UpdateAt    time.Time `json:"updateAt, omitempty"`
+}
+
+func (o Organization) String() string {
+	return fmt.Sprintf("[id: %v, name: %v, description: %v, urn: %v, createAt: %v, updateAt: %v]",
+		o.ID, o.Name, o.Description, o.Urn, o.CreateAt.Format("2006-01-02 15:04:05 MST"),
+		o.UpdateAt.Format("2006-01-02 15:04:05 MST"))
+}
+
+func (o Organization) GetUrn() string {
+	return o.Urn
+}
+
+// Organizations don't have tags
+func (o Organization) GetTags() map[string]string {
+	return nil
+}
+
+// Portable document with all groups and policies of an organization. Org is informational,
+// resources are imported into the organization given to the import.
+type OrganizationDocument struct {
+	Version  string           `json:"version, omitempty"`
+	Org      string           `json:"org, omitempty"`
+	Policies []DocumentPolicy `json:"policies, omitempty"`
+	Groups   []DocumentGroup  `json:"groups, omitempty"`
+}
+
+// Policy of an organization document
+type DocumentPolicy struct {
+	Name       string            `json:"name, omitempty"`
+	Path       string            `json:"path, omitempty"`
+	Statements []Statement       `json:"statements, omitempty"`
+	Tags       map[string]string `json:"tags, omitempty"`
+}
+
+// Group of an organization document with its members, owners and attached policies. Members of
+// groups with membership rule are the users that match it, so they aren't listed. Owners are
+// referenced by externalId and must exist before the import.
+type DocumentGroup struct {
+	Name           string               `json:"name, omitempty"`
+	Path           string               `json:"path, omitempty"`
+	MembershipRule *MembershipRule      `json:"membershipRule, omitempty"`
+	Tags           map[string]string    `json:"tags, omitempty"`
+	Members        []DocumentMember     `json:"members, omitempty"`
+	Owners         []string             `json:"owners, omitempty"`
+	Policies       []DocumentAttachment `json:"policies, omitempty"`
+}
+
+// Group member of an organization document. Users are referenced by externalId
+// and must exist before the import.
+type DocumentMember struct {
+	ExternalID string     `json:"externalId, omitempty"`
+	ExpiresAt  *time.Time `json:"expiresAt, omitempty"`
+}
+
+// Policy attachment of an organization document with its activation window
+type DocumentAttachment struct {
+	Policy    string     `json:"policy, omitempty"`
+	NotBefore *time.Time `json:"notBefore, omitempty"`
+	NotAfter  *time.Time `json:"notAfter, omitempty"`
+}
+
+// Names of the resources created, updated and skipped by an import
+type ImportResult struct {
+	CreatedPolicies []string `json:"createdPolicies, omitempty"`
+	UpdatedPolicies []string `json:"updatedPolicies, omitempty"`
+	SkippedPolicies []string `json:"skippedPolicies, omitempty"`
+	CreatedGroups   []string `json:"createdGroups, omitempty"`
+	UpdatedGroups   []string `json:"updatedGroups, omitempty"`
+	SkippedGroups   []string `json:"skippedGroups, omitempty"`
+}
+
+// ORGANIZATION API IMPLEMENTATION
+
+func (api AuthAPI) AddOrganization(requestInfo RequestInfo, name string, description string) (*Organization, error) {
+	// Validate fields
+	if !IsValidOrg(name) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: name %v", name),
+		}
+	}
+	if len(description) > MAX_DESCRIPTION_LENGTH {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: description %v", description),
+		}
+	}
+
+	organization := createOrganization(name, description)
+
+	// Check restrictions
+	organizationsFiltered, err := api.GetAuthorizedOrganizations(requestInfo, organization.Urn,
+		ORGANIZATION_ACTION_CREATE_ORGANIZATION, []Organization{organization})
+	if err != nil {
+		return nil, err
+	}
+	if len(organizationsFiltered) < 1 {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, organization.Urn),
+		}
+	}
+
+	// Check if organization already exists
+	_, err = api.OrganizationRepo.GetOrganizationByName(name)
+
+	if err != nil {
+		// Transform to DB error
+		dbError := err.(*database.Error)
+		// Organization doesn't exist in DB
+		switch dbError.Code {
+		case database.ORGANIZATION_NOT_FOUND:
+			// Create organization
+			var createdOrganization *Organization
+			err := api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+				var err error
+				createdOrganization, err = repo.AddOrganization(organization)
+				if err != nil {
+					return err
+				}
+				return api.auditOperation(repo, requestInfo, ORGANIZATION_ACTION_CREATE_ORGANIZATION, createdOrganization.Urn, nil, createdOrganization)
+			})
+
+			// Check unexpected DB error
+			if err != nil {
+				//Transform to DB error
+				dbError := err.(*database.Error)
+				return nil, &Error{
+					Code:    UNKNOWN_API_ERROR,
+					Message: dbError.Message,
+				}
+			}
+			LogOperation(api.Logger, requestInfo, fmt.Sprintf("Organization created %+v", createdOrganization))
+			return createdOrganization, nil
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	} else {
+		return nil, &Error{
+			Code:    ORGANIZATION_ALREADY_EXIST,
+			Message: fmt.Sprintf("Unable to create organization, organization with name %v already exist", name),
+		}
+	}
+}
+
+func (api AuthAPI) GetOrganizationByName(requestInfo RequestInfo, name string) (*Organization, error) {
+	if !IsValidOrg(name) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: name %v", name),
+		}
+	}
+	// Retrieve organization from DB
+	organization, err := api.OrganizationRepo.GetOrganizationByName(name)
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		// Organization doesn't exist in DB
+		if dbError.Code == database.ORGANIZATION_NOT_FOUND {
+			return nil, &Error{
+				Code:    ORGANIZATION_BY_NAME_NOT_FOUND,
+				Message: dbError.Message,
+			}
+		} else { // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	// Check restrictions
+	organizationsFiltered, err := api.GetAuthorizedOrganizations(requestInfo, organization.Urn,
+		ORGANIZATION_ACTION_GET_ORGANIZATION, []Organization{*organization})
+	if err != nil {
+		return nil, err
+	}
+	if len(organizationsFiltered) < 1 {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, organization.Urn),
+		}
+	}
+
+	return &organizationsFiltered[0], nil
+}
+
+func (api AuthAPI) ListOrganizations(requestInfo RequestInfo, filter *Filter) ([]Organization, int, error) {
+	// Check parameters
+	if err := validateFilter(filter); err != nil {
+		return nil, 0, err
+	}
+
+	// Retrieve organizations with specified name and creation dates
+	organizations, total, err := api.OrganizationRepo.GetOrganizationsFiltered(filter)
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, 0, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	// Check restrictions
+	urnPrefix := GetUrnPrefix("", RESOURCE_ORGANIZATION, "/")
+	organizationsFiltered, err := api.GetAuthorizedOrganizations(requestInfo, urnPrefix,
+		ORGANIZATION_ACTION_LIST_ORGANIZATIONS, organizations)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return organizationsFiltered, total, nil
+}
+
+func (api AuthAPI) UpdateOrganization(requestInfo RequestInfo, name string, newDescription string) (*Organization, error) {
+	if len(newDescription) > MAX_DESCRIPTION_LENGTH {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: description %v", newDescription),
+		}
+	}
+
+	// Call repo to retrieve the organization
+	organizationDB, err := api.GetOrganizationByName(requestInfo, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check restrictions
+	organizationsFiltered, err := api.GetAuthorizedOrganizations(requestInfo, organizationDB.Urn,
+		ORGANIZATION_ACTION_UPDATE_ORGANIZATION, []Organization{*organizationDB})
+	if err != nil {
+		return nil, err
+	}
+	if len(organizationsFiltered) < 1 {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, organizationDB.Urn),
+		}
+	}
+
+	var organization *Organization
+	err = api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		var err error
+		organization, err = repo.UpdateOrganization(*organizationDB, newDescription)
+		if err != nil {
+			return err
+		}
+		return api.auditOperation(repo, requestInfo, ORGANIZATION_ACTION_UPDATE_ORGANIZATION, organization.Urn, organizationDB, organization)
+	})
+
+	// Check unexpected DB error
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Organization updated from %+v to %+v", organizationDB, organization))
+	return organization, nil
+}
+
+func (api AuthAPI) RemoveOrganization(requestInfo RequestInfo, name string) error {
+	// Call repo to retrieve the organization
+	organization, err := api.GetOrganizationByName(requestInfo, name)
+	if err != nil {
+		return err
+	}
+
+	// Check restrictions
+	organizationsFiltered, err := api.GetAuthorizedOrganizations(requestInfo, organization.Urn,
+		ORGANIZATION_ACTION_DELETE_ORGANIZATION, []Organization{*organization})
+	if err != nil {
+		return err
+	}
+	if len(organizationsFiltered) < 1 {
+		return &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, organization.Urn),
+		}
+	}
+
+	// Groups and policies are moved to the trash in the same transaction, so nothing is removed if it fails
+	err = api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		groups, _, err := repo.GetGroupsFiltered(&Filter{Org: organization.Name})
+		if err != nil {
+			return err
+		}
+		for _, group := range groups {
+			if err := repo.RemoveGroup(group); err != nil {
+				return err
+			}
+		}
+		policies, _, err := repo.GetPoliciesFiltered(&Filter{Org: organization.Name})
+		if err != nil {
+			return err
+		}
+		for _, policy := range policies {
+			if err := repo.RemovePolicy(policy); err != nil {
+				return err
+			}
+		}
+		if err := repo.RemoveOrganization(organization.ID); err != nil {
+			return err
+		}
+		return api.auditOperation(repo, requestInfo, ORGANIZATION_ACTION_DELETE_ORGANIZATION, organization.Urn, organization, nil)
+	})
+
+	// Error handling
+	if err != nil {
+		return toUnknownAPIError(err)
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Organization deleted %+v", organization))
+	return nil
+}
+
+func (api AuthAPI) ExportOrganization(requestInfo RequestInfo, org string) (*OrganizationDocument, error) {
+	if err := checkOrganizationDocumentAdmin(requestInfo); err != nil {
+		return nil, err
+	}
+
+	// Validate fields
+	if !IsValidOrg(org) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: org %v", org),
+		}
+	}
+
+	// Retrieve groups and policies of the organization
+	document, err := api.getOrganizationDocument(org)
+	if err != nil {
+		return nil, toUnknownAPIError(err)
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Organization %v exported with %v policies and %v groups",
+		org, len(document.Policies), len(document.Groups)))
+	return document, nil
+}
+
+func (api AuthAPI) ImportOrganization(requestInfo RequestInfo, org string, document *OrganizationDocument,
+	conflictMode string) (*ImportResult, error) {
+	if err := checkOrganizationDocumentAdmin(requestInfo); err != nil {
+		return nil, err
+	}
+
+	// Validate fields
+	if !IsValidOrg(org) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: org %v", org),
+		}
+	}
+	if !isValidImportConflictMode(conflictMode) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: conflict %v", conflictMode),
+		}
+	}
+	if err := api.validateOrganizationDocument(document); err != nil {
+		return nil, err
+	}
+	if err := api.checkOrganizationExists(org); err != nil {
+		return nil, err
+	}
+
+	// Import runs inside one transaction, so nothing is stored if it fails
+	result := &ImportResult{}
+	err := api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		transactionAPI := api
+		transactionAPI.GroupRepo = repo
+		transactionAPI.PolicyRepo = repo
+		transactionAPI.OrganizationRepo = repo
+		transactionAPI.UserRepo = repo
+		transactionAPI.TagRepo = repo
+
+		if err := transactionAPI.importOrganization(org, document, conflictMode, result); err != nil {
+			return err
+		}
+		return api.auditOperation(repo, requestInfo, AUDIT_ACTION_IMPORT_ORGANIZATION, CreateUrn("", RESOURCE_ORGANIZATION, "/", org), nil, document)
+	})
+
+	// Error handling
+	if err != nil {
+		return nil, toUnknownAPIError(err)
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Organization %v imported with result %+v", org, result))
+	return result, nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Import policies first, so group attachments can reference them
+func (api AuthAPI) importOrganization(org string, document *OrganizationDocument, conflictMode string, result *ImportResult) error {
+	for _, documentPolicy := range document.Policies {
+		if err := api.importPolicy(org, documentPolicy, conflictMode, result); err != nil {
+			return err
+		}
+	}
+	for _, documentGroup := range document.Groups {
+		if err := api.importGroup(org, documentGroup, conflictMode, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (api AuthAPI) importPolicy(org string, documentPolicy DocumentPolicy, conflictMode string, result *ImportResult) error {
+	policy, err := api.PolicyRepo.GetPolicyByName(org, documentPolicy.Name)
+
+	// Check if policy could be retrieved
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		if dbError.Code != database.POLICY_NOT_FOUND {
+			return err
+		}
+
+		// Policy doesn't exist in DB, so we create it
+		if err := api.createDocumentPolicy(org, documentPolicy); err != nil {
+			return err
+		}
+		result.CreatedPolicies = append(result.CreatedPolicies, documentPolicy.Name)
+		return nil
+	}
+
+	switch conflictMode {
+	case IMPORT_CONFLICT_SKIP:
+		result.SkippedPolicies = append(result.SkippedPolicies, documentPolicy.Name)
+		return nil
+	case IMPORT_CONFLICT_OVERWRITE:
+		if err := api.updateDocumentPolicy(org, policy, documentPolicy); err != nil {
+			return err
+		}
+		result.UpdatedPolicies = append(result.UpdatedPolicies, documentPolicy.Name)
+		return nil
+	default:
+		return &Error{
+			Code:    POLICY_ALREADY_EXIST,
+			Message: fmt.Sprintf("Unable to import policy, policy with org %v and name %v already exist", org, documentPolicy.Name),
+		}
+	}
+}
+
+func (api AuthAPI) importGroup(org string, documentGroup DocumentGroup, conflictMode string, result *ImportResult) error {
+	group, err := api.GroupRepo.GetGroupByName(org, documentGroup.Name)
+
+	// Check if group could be retrieved
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		if dbError.Code != database.GROUP_NOT_FOUND {
+			return err
+		}
+
+		// Group doesn't exist in DB, so we create it
+		createdGroup, err := api.createDocumentGroup(org, documentGroup)
+		if err != nil {
+			return err
+		}
+		if err := api.importGroupRelations(org, createdGroup, documentGroup); err != nil {
+			return err
+		}
+		result.CreatedGroups = append(result.CreatedGroups, documentGroup.Name)
+		return nil
+	}
+
+	switch conflictMode {
+	case IMPORT_CONFLICT_SKIP:
+		result.SkippedGroups = append(result.SkippedGroups, documentGroup.Name)
+		return nil
+	case IMPORT_CONFLICT_OVERWRITE:
+		updatedGroup, err := api.updateDocumentGroup(org, group, documentGroup)
+		if err != nil {
+			return err
+		}
+
+		// Remove members, owners and attachments that aren't in the document
+		userRelations, _, err := api.GroupRepo.GetGroupUserRelations(group.ID, &Filter{})
+		if err != nil {
+			return err
+		}
+		for _, relation := range userRelations {
+			if err := api.GroupRepo.RemoveMember(relation.User.ID, group.ID); err != nil {
+				return err
+			}
+		}
+		for _, owner := range group.Owners {
+			user, err := api.getDocumentUser(documentGroup.Name, "owner", owner)
+			if err != nil {
+				return err
+			}
+			if err := api.GroupRepo.RemoveOwner(user.ID, group.ID); err != nil {
+				return err
+			}
+		}
+		policyRelations, _, err := api.GroupRepo.GetGroupPolicyRelations(group.ID, &Filter{})
+		if err != nil {
+			return err
+		}
+		for _, relation := range policyRelations {
+			if err := api.GroupRepo.DetachPolicy(group.ID, relation.Policy.ID); err != nil {
+				return err
+			}
+		}
+
+		if err := api.importGroupRelations(org, updatedGroup, documentGroup); err != nil {
+			return err
+		}
+		result.UpdatedGroups = append(result.UpdatedGroups, documentGroup.Name)
+		return nil
+	default:
+		return &Error{
+			Code:    GROUP_ALREADY_EXIST,
+			Message: fmt.Sprintf("Unable to import group, group with org %v and name %v already exist", org, documentGroup.Name),
+		}
+	}
+}
+
+// Add group members and owners and attach group policies. Users and policies must exist.
+func (api AuthAPI) importGroupRelations(org string, group *Group, documentGroup DocumentGroup) error {
+	for _, member := range documentGroup.Members {
+		if err := checkGroupWithoutMembershipRule(group); err != nil {
+			return err
+		}
+		user, err := api.getDocumentUser(documentGroup.Name, "member", member.ExternalID)
+		if err != nil {
+			return err
+		}
+		if err := api.GroupRepo.AddMember(user.ID, group.ID, member.ExpiresAt); err != nil {
+			return err
+		}
+	}
+
+	for _, owner := range documentGroup.Owners {
+		user, err := api.getDocumentUser(documentGroup.Name, "owner", owner)
+		if err != nil {
+			return err
+		}
+		if err := api.GroupRepo.AddOwner(user.ID, group.ID); err != nil {
+			return err
+		}
+	}
+
+	for _, attachment := range documentGroup.Policies {
+		policy, err := api.getDocumentAttachedPolicy(org, documentGroup.Name, attachment.Policy)
+		if err != nil {
+			return err
+		}
+		if err := api.GroupRepo.AttachPolicy(group.ID, policy.ID, attachment.NotBefore, attachment.NotAfter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Retrieve all groups and policies of an organization as a document
+func (api AuthAPI) getOrganizationDocument(org string) (*OrganizationDocument, error) {
+	document := &OrganizationDocument{
+		Version:  ORGANIZATION_DOCUMENT_VERSION,
+		Org:      org,
+		Policies: []DocumentPolicy{},
+		Groups:   []DocumentGroup{},
+	}
+
+	// Retrieve policies
+	policies, _, err := api.PolicyRepo.GetPoliciesFiltered(&Filter{Org: org})
+	if err != nil {
+		return nil, err
+	}
+	for _, policy := range policies {
+		statements := []Statement{}
+		if policy.Statements != nil {
+			statements = *policy.Statements
+		}
+		document.Policies = append(document.Policies, DocumentPolicy{
+			Name:       policy.Name,
+			Path:       policy.Path,
+			Statements: statements,
+			Tags:       policy.Tags,
+		})
+	}
+
+	// Retrieve groups with their members and attached policies
+	groups, _, err := api.GroupRepo.GetGroupsFiltered(&Filter{Org: org})
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	for _, group := range groups {
+		documentGroup := DocumentGroup{
+			Name:           group.Name,
+			Path:           group.Path,
+			MembershipRule: group.MembershipRule,
+			Tags:           group.Tags,
+			Members:        []DocumentMember{},
+			Owners:         group.Owners,
+			Policies:       []DocumentAttachment{},
+		}
+
+		if group.MembershipRule == nil {
+			userRelations, _, err := api.GroupRepo.GetGroupUserRelations(group.ID, &Filter{})
+			if err != nil {
+				return nil, err
+			}
+			for _, relation := range userRelations {
+				documentGroup.Members = append(documentGroup.Members, DocumentMember{
+					ExternalID: relation.User.ExternalID,
+					ExpiresAt:  relation.ExpiresAt,
+				})
+			}
+		}
+
+		policyRelations, _, err := api.GroupRepo.GetGroupPolicyRelations(group.ID, &Filter{})
+		if err != nil {
+			return nil, err
+		}
+		for _, relation := range policyRelations {
+			// Attachments whose window already ended have no effect, so they aren't exported
+			if relation.NotAfter != nil && !relation.NotAfter.After(now) {
+				continue
+			}
+			documentGroup.Policies = append(documentGroup.Policies, DocumentAttachment{
+				Policy:    relation.Policy.Name,
+				NotBefore: relation.NotBefore,
+				NotAfter:  relation.NotAfter,
+			})
+		}
+
+		document.Groups = append(document.Groups, documentGroup)
+	}
+
+	return document, nil
+}
+
+func (api AuthAPI) createDocumentPolicy(org string, documentPolicy DocumentPolicy) error {
+	statements := append([]Statement(nil), documentPolicy.Statements...)
+	createdPolicy, err := api.PolicyRepo.AddPolicy(createPolicy(documentPolicy.Name, documentPolicy.Path, org, &statements))
+	if err != nil {
+		return err
+	}
+	return api.replaceTags(createdPolicy.ID, nil, documentPolicy.Tags)
+}
+
+func (api AuthAPI) updateDocumentPolicy(org string, policy *Policy, documentPolicy DocumentPolicy) error {
+	urn := CreateUrn(org, RESOURCE_POLICY, documentPolicy.Path, documentPolicy.Name)
+	statements := append([]Statement(nil), documentPolicy.Statements...)
+	assignStatementSids(statements)
+	if _, err := api.PolicyRepo.UpdatePolicy(*policy, documentPolicy.Name, documentPolicy.Path, urn, statements); err != nil {
+		return err
+	}
+	return api.replaceTags(policy.ID, policy.Tags, documentPolicy.Tags)
+}
+
+func (api AuthAPI) createDocumentGroup(org string, documentGroup DocumentGroup) (*Group, error) {
+	createdGroup, err := api.GroupRepo.AddGroup(createGroup(org, documentGroup.Name, documentGroup.Path))
+	if err != nil {
+		return nil, err
+	}
+	if documentGroup.MembershipRule != nil {
+		if createdGroup, err = api.GroupRepo.SetGroupMembershipRule(*createdGroup, documentGroup.MembershipRule); err != nil {
+			return nil, err
+		}
+	}
+	if err := api.replaceTags(createdGroup.ID, nil, documentGroup.Tags); err != nil {
+		return nil, err
+	}
+	return createdGroup, nil
+}
+
+func (api AuthAPI) updateDocumentGroup(org string, group *Group, documentGroup DocumentGroup) (*Group, error) {
+	urn := CreateUrn(org, RESOURCE_GROUP, documentGroup.Path, documentGroup.Name)
+	updatedGroup, err := api.GroupRepo.UpdateGroup(*group, documentGroup.Name, documentGroup.Path, urn)
+	if err != nil {
+		return nil, err
+	}
+	if !equalMembershipRules(group.MembershipRule, documentGroup.MembershipRule) {
+		if updatedGroup, err = api.GroupRepo.SetGroupMembershipRule(*updatedGroup, documentGroup.MembershipRule); err != nil {
+			return nil, err
+		}
+	}
+	if err := api.replaceTags(group.ID, group.Tags, documentGroup.Tags); err != nil {
+		return nil, err
+	}
+	return updatedGroup, nil
+}
+
+// Retrieve user referenced by a group member or owner of a document
+func (api AuthAPI) getDocumentUser(groupName string, relation string, externalID string) (*User, error) {
+	user, err := api.UserRepo.GetUserByExternalID(externalID)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		if dbError.Code == database.USER_NOT_FOUND {
+			return nil, &Error{
+				Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: fmt.Sprintf("Unable to add %v to group %v, user with externalId %v not found", relation, groupName, externalID),
+			}
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// Retrieve policy referenced by a group attachment of a document
+func (api AuthAPI) getDocumentAttachedPolicy(org string, groupName string, policyName string) (*Policy, error) {
+	policy, err := api.PolicyRepo.GetPolicyByName(org, policyName)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		if dbError.Code == database.POLICY_NOT_FOUND {
+			return nil, &Error{
+				Code: POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: fmt.Sprintf("Unable to attach policy to group %v, policy with org %v and name %v not found",
+					groupName, org, policyName),
+			}
+		}
+		return nil, err
+	}
+	return policy, nil
+}
+
+// Replace current tags of a resource with new tags
+func (api AuthAPI) replaceTags(resourceID string, currentTags map[string]string, newTags map[string]string) error {
+	for key := range currentTags {
+		if _, ok := newTags[key]; !ok {
+			if err := api.TagRepo.RemoveTag(resourceID, key); err != nil {
+				return err
+			}
+		}
+	}
+	for key, value := range newTags {
+		if err := api.TagRepo.SetTag(resourceID, key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate the whole document before importing anything
+func (api AuthAPI) validateOrganizationDocument(document *OrganizationDocument) error {
+	if document == nil || document.Version != ORGANIZATION_DOCUMENT_VERSION {
+		version := ""
+		if document != nil {
+			version = document.Version
+		}
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: version %v", version),
+		}
+	}
+
+	policyNames := map[string]bool{}
+	for _, policy := range document.Policies {
+		if !IsValidName(policy.Name) || policyNames[policy.Name] {
+			return &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: policy name %v", policy.Name),
+			}
+		}
+		policyNames[policy.Name] = true
+		if !IsValidPath(policy.Path) {
+			return &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: path %v", policy.Path),
+			}
+		}
+		statements := policy.Statements
+		if err := AreValidStatements(&statements); err != nil {
+			apiError := err.(*Error)
+			return &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: apiError.Message,
+			}
+		}
+		if api.ValidateActions {
+			if err := api.areRegisteredActions(statements); err != nil {
+				return err
+			}
+		}
+		if api.ValidateResources {
+			if err := api.areRegisteredResources(statements); err != nil {
+				return err
+			}
+		}
+		if err := AreValidTags(policy.Tags); err != nil {
+			return err
+		}
+	}
+
+	groupNames := map[string]bool{}
+	for _, group := range document.Groups {
+		if !IsValidName(group.Name) || groupNames[group.Name] {
+			return &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: group name %v", group.Name),
+			}
+		}
+		groupNames[group.Name] = true
+		if !IsValidPath(group.Path) {
+			return &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: path %v", group.Path),
+			}
+		}
+		if err := AreValidTags(group.Tags); err != nil {
+			return err
+		}
+		if group.MembershipRule != nil {
+			if err := validateMembershipRule(*group.MembershipRule); err != nil {
+				return err
+			}
+			if len(group.Members) > 0 {
+				return &Error{
+					Code:    INVALID_PARAMETER_ERROR,
+					Message: fmt.Sprintf("Invalid parameter: members of group %v are defined by its membership rule", group.Name),
+				}
+			}
+		}
+		for _, member := range group.Members {
+			if !IsValidUserExternalID(member.ExternalID) {
+				return &Error{
+					Code:    INVALID_PARAMETER_ERROR,
+					Message: fmt.Sprintf("Invalid parameter: externalId %v", member.ExternalID),
+				}
+			}
+			if member.ExpiresAt != nil && !member.ExpiresAt.After(time.Now().UTC()) {
+				return &Error{
+					Code:    INVALID_PARAMETER_ERROR,
+					Message: fmt.Sprintf("Invalid parameter: expiresAt %v is in the past", member.ExpiresAt.UTC()),
+				}
+			}
+		}
+		owners := map[string]bool{}
+		for _, owner := range group.Owners {
+			if !IsValidUserExternalID(owner) || owners[owner] {
+				return &Error{
+					Code:    INVALID_PARAMETER_ERROR,
+					Message: fmt.Sprintf("Invalid parameter: owner %v", owner),
+				}
+			}
+			owners[owner] = true
+		}
+		for _, attachment := range group.Policies {
+			if !IsValidName(attachment.Policy) {
+				return &Error{
+					Code:    INVALID_PARAMETER_ERROR,
+					Message: fmt.Sprintf("Invalid parameter: policy name %v", attachment.Policy),
+				}
+			}
+			if err := isValidActivationWindow(attachment.NotBefore, attachment.NotAfter); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func isValidImportConflictMode(conflictMode string) bool {
+	switch conflictMode {
+	case IMPORT_CONFLICT_SKIP, IMPORT_CONFLICT_OVERWRITE, IMPORT_CONFLICT_FAIL:
+		return true
+	default:
+		return false
+	}
+}
+
+// Check that the organization where groups and policies are created exists
+func (api AuthAPI) checkOrganizationExists(org string) error {
+	_, err := api.OrganizationRepo.GetOrganizationByName(org)
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.ORGANIZATION_NOT_FOUND:
+			return &Error{
+				Code:    ORGANIZATION_BY_NAME_NOT_FOUND,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+	return nil
+}
+
+func createOrganization(name string, description string) Organization {
+	now := time.Now().UTC()
+	organization := Organization{
+		ID:          uuid.NewV4().String(),
+		Name:        name,
+		Description: description,
+		Urn:         CreateUrn("", RESOURCE_ORGANIZATION, "/", name),
+		CreateAt:    now,
+		UpdateAt:    now,
+	}
+
+	return organization
+}
+
+// Only admin can export, import or apply organization documents, because they replace every group,
+// policy and relation of the organization
+func checkOrganizationDocumentAdmin(requestInfo RequestInfo) error {
+	if !requestInfo.Admin {
+		return &Error{
+			Code:    UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to manage organization documents", requestInfo.Identifier),
+		}
+	}
+	return nil
+}
+
+// Keep API errors and transform DB errors into unknown API errors
+func toUnknownAPIError(err error) error {
+	if apiError, ok := err.(*Error); ok {
+		return apiError
+	}
+	//Transform to DB error
+	dbError := err.(*database.Error)
+	return &Error{
+		Code:    UNKNOWN_API_ERROR,
+		Message: dbError.Message,
+	}
+}
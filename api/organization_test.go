@@ -0,0 +1,1323 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestAuthAPI_ExportOrganization(t *testing.T) {
+	now := time.Now().UTC()
+	expiresAt := now.Add(time.Hour)
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		org         string
+		// Expected result
+		expectedResponse *OrganizationDocument
+		wantError        error
+		// Manager Results
+		getPoliciesFilteredResult     []Policy
+		getGroupsFilteredResult       []Group
+		getGroupUserRelationsResult   []GroupUserRelation
+		getGroupPolicyRelationsResult []GroupPolicyRelation
+		// Manager Errors
+		getPoliciesFilteredErr error
+		getGroupsFilteredErr   error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org: "org1",
+			expectedResponse: &OrganizationDocument{
+				Version: ORGANIZATION_DOCUMENT_VERSION,
+				Org:     "org1",
+				Policies: []DocumentPolicy{
+					{
+						Name: "policy1",
+						Path: "/path/",
+						Statements: []Statement{
+							{
+								Effect:    "allow",
+								Actions:   []string{USER_ACTION_GET_USER},
+								Resources: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+							},
+						},
+						Tags: map[string]string{
+							"team": "blue",
+						},
+					},
+				},
+				Groups: []DocumentGroup{
+					{
+						Name: "group1",
+						Path: "/path/",
+						Members: []DocumentMember{
+							{
+								ExternalID: "user1",
+								ExpiresAt:  &expiresAt,
+							},
+						},
+						Owners: []string{"owner1"},
+						Policies: []DocumentAttachment{
+							{
+								Policy:   "policy1",
+								NotAfter: &expiresAt,
+							},
+						},
+					},
+				},
+			},
+			getPoliciesFilteredResult: []Policy{
+				{
+					ID:   "PolicyID",
+					Name: "policy1",
+					Org:  "org1",
+					Path: "/path/",
+					Statements: &[]Statement{
+						{
+							Effect:    "allow",
+							Actions:   []string{USER_ACTION_GET_USER},
+							Resources: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+						},
+					},
+					Tags: map[string]string{
+						"team": "blue",
+					},
+				},
+			},
+			getGroupsFilteredResult: []Group{
+				{
+					ID:     "GroupID",
+					Name:   "group1",
+					Org:    "org1",
+					Path:   "/path/",
+					Owners: []string{"owner1"},
+				},
+			},
+			getGroupUserRelationsResult: []GroupUserRelation{
+				{
+					User: &User{
+						ID:         "UserID",
+						ExternalID: "user1",
+					},
+					ExpiresAt: &expiresAt,
+				},
+			},
+			getGroupPolicyRelationsResult: []GroupPolicyRelation{
+				{
+					Policy: &Policy{
+						ID:   "PolicyID",
+						Name: "policy1",
+					},
+					NotAfter: &expiresAt,
+				},
+				{
+					Policy: &Policy{
+						ID:   "PolicyID2",
+						Name: "policy2",
+					},
+					NotAfter: &now,
+				},
+			},
+		},
+		"OkCaseMembershipRule": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org: "org1",
+			expectedResponse: &OrganizationDocument{
+				Version:  ORGANIZATION_DOCUMENT_VERSION,
+				Org:      "org1",
+				Policies: []DocumentPolicy{},
+				Groups: []DocumentGroup{
+					{
+						Name: "group1",
+						Path: "/path/",
+						MembershipRule: &MembershipRule{
+							PathPrefix: "/path/",
+						},
+						Members:  []DocumentMember{},
+						Policies: []DocumentAttachment{},
+					},
+				},
+			},
+			getGroupsFilteredResult: []Group{
+				{
+					ID:   "GroupID",
+					Name: "group1",
+					Org:  "org1",
+					Path: "/path/",
+					MembershipRule: &MembershipRule{
+						PathPrefix: "/path/",
+					},
+				},
+			},
+			getGroupUserRelationsResult: []GroupUserRelation{
+				{
+					User: &User{
+						ID:         "UserID",
+						ExternalID: "user1",
+					},
+				},
+			},
+		},
+		"ErrorCaseNotAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			org: "org1",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to manage organization documents",
+			},
+		},
+		"ErrorCaseInvalidOrg": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org: "!*^**~$%&/()",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: org !*^**~$%&/()",
+			},
+		},
+		"ErrorCaseGetPoliciesDBError": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org: "org1",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getPoliciesFilteredErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+		"ErrorCaseGetGroupsDBError": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org: "org1",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getGroupsFilteredErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetPoliciesFilteredMethod][0] = testcase.getPoliciesFilteredResult
+		testRepo.ArgsOut[GetPoliciesFilteredMethod][2] = testcase.getPoliciesFilteredErr
+		testRepo.ArgsOut[GetGroupsFilteredMethod][0] = testcase.getGroupsFilteredResult
+		testRepo.ArgsOut[GetGroupsFilteredMethod][2] = testcase.getGroupsFilteredErr
+		testRepo.ArgsOut[GetGroupUserRelationsMethod][0] = testcase.getGroupUserRelationsResult
+		testRepo.ArgsOut[GetGroupPolicyRelationsMethod][0] = testcase.getGroupPolicyRelationsResult
+		document, err := testAPI.ExportOrganization(testcase.requestInfo, testcase.org)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, document)
+	}
+}
+
+func TestAuthAPI_ImportOrganization(t *testing.T) {
+	expiresAt := time.Now().UTC().Add(time.Hour)
+	past := time.Now().UTC().Add(-time.Hour)
+	document := &OrganizationDocument{
+		Version: ORGANIZATION_DOCUMENT_VERSION,
+		Org:     "org1",
+		Policies: []DocumentPolicy{
+			{
+				Name: "policy1",
+				Path: "/path/",
+				Statements: []Statement{
+					{
+						Effect:    "allow",
+						Actions:   []string{USER_ACTION_GET_USER},
+						Resources: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+					},
+				},
+				Tags: map[string]string{
+					"team": "blue",
+				},
+			},
+		},
+		Groups: []DocumentGroup{
+			{
+				Name: "group1",
+				Path: "/path/",
+				Members: []DocumentMember{
+					{
+						ExternalID: "user1",
+						ExpiresAt:  &expiresAt,
+					},
+				},
+				Policies: []DocumentAttachment{
+					{
+						Policy: "policy1",
+					},
+				},
+			},
+		},
+	}
+	// Policy "policy1" and group "group1" exist in org1
+	existingPolicy := func(org string, name string) (*Policy, error) {
+		if org == "org1" && name == "policy1" {
+			return &Policy{
+				ID:   "PolicyID",
+				Name: name,
+				Org:  org,
+				Path: "/old/",
+				Tags: map[string]string{
+					"old": "tag",
+				},
+			}, nil
+		}
+		return nil, &database.Error{
+			Code: database.POLICY_NOT_FOUND,
+		}
+	}
+	existingGroup := func(org string, name string) (*Group, error) {
+		if org == "org1" && name == "group1" {
+			return &Group{
+				ID:   "GroupID",
+				Name: name,
+				Org:  org,
+				Path: "/old/",
+			}, nil
+		}
+		return nil, &database.Error{
+			Code: database.GROUP_NOT_FOUND,
+		}
+	}
+	// Policies are created before groups, so the import finds the policy only after creating it
+	createdPolicies := map[string]bool{}
+	createdPolicy := func(org string, name string) (*Policy, error) {
+		if createdPolicies[name] {
+			return &Policy{
+				ID:   "PolicyID",
+				Name: name,
+				Org:  org,
+			}, nil
+		}
+		createdPolicies[name] = true
+		return nil, &database.Error{
+			Code: database.POLICY_NOT_FOUND,
+		}
+	}
+	notFoundGroup := func(org string, name string) (*Group, error) {
+		return nil, &database.Error{
+			Code: database.GROUP_NOT_FOUND,
+		}
+	}
+
+	testcases := map[string]struct {
+		// API method args
+		requestInfo  RequestInfo
+		org          string
+		document     *OrganizationDocument
+		conflictMode string
+		// Expected result
+		expectedResponse       *ImportResult
+		expectedMembershipRule *MembershipRule
+		expectedOwnerID        string
+		wantError              error
+		// Manager Results
+		getPolicyByNameFunc func(org string, name string) (*Policy, error)
+		getGroupByNameFunc  func(org string, name string) (*Group, error)
+		// Manager Errors
+		getUserByExternalIDErr   error
+		runInTransactionErr      error
+		getOrganizationByNameErr error
+	}{
+		"OkCaseCreate": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:          "org2",
+			document:     document,
+			conflictMode: IMPORT_CONFLICT_FAIL,
+			expectedResponse: &ImportResult{
+				CreatedPolicies: []string{"policy1"},
+				CreatedGroups:   []string{"group1"},
+			},
+			getPolicyByNameFunc: createdPolicy,
+			getGroupByNameFunc:  notFoundGroup,
+		},
+		"OkCaseSkip": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:          "org1",
+			document:     document,
+			conflictMode: IMPORT_CONFLICT_SKIP,
+			expectedResponse: &ImportResult{
+				SkippedPolicies: []string{"policy1"},
+				SkippedGroups:   []string{"group1"},
+			},
+			getPolicyByNameFunc: existingPolicy,
+			getGroupByNameFunc:  existingGroup,
+		},
+		"OkCaseOverwrite": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:          "org1",
+			document:     document,
+			conflictMode: IMPORT_CONFLICT_OVERWRITE,
+			expectedResponse: &ImportResult{
+				UpdatedPolicies: []string{"policy1"},
+				UpdatedGroups:   []string{"group1"},
+			},
+			getPolicyByNameFunc: existingPolicy,
+			getGroupByNameFunc:  existingGroup,
+		},
+		"OkCaseCreateWithMembershipRule": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org: "org2",
+			document: &OrganizationDocument{
+				Version: ORGANIZATION_DOCUMENT_VERSION,
+				Groups: []DocumentGroup{
+					{
+						Name: "group1",
+						Path: "/path/",
+						MembershipRule: &MembershipRule{
+							PathPrefix: "/path/",
+						},
+					},
+				},
+			},
+			conflictMode: IMPORT_CONFLICT_FAIL,
+			expectedResponse: &ImportResult{
+				CreatedGroups: []string{"group1"},
+			},
+			expectedMembershipRule: &MembershipRule{
+				PathPrefix: "/path/",
+			},
+			getGroupByNameFunc: notFoundGroup,
+		},
+		"OkCaseCreateWithOwners": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org: "org2",
+			document: &OrganizationDocument{
+				Version: ORGANIZATION_DOCUMENT_VERSION,
+				Groups: []DocumentGroup{
+					{
+						Name:   "group1",
+						Path:   "/path/",
+						Owners: []string{"user1"},
+					},
+				},
+			},
+			conflictMode: IMPORT_CONFLICT_FAIL,
+			expectedResponse: &ImportResult{
+				CreatedGroups: []string{"group1"},
+			},
+			expectedOwnerID:    "UserID",
+			getGroupByNameFunc: notFoundGroup,
+		},
+		"ErrorCaseDuplicatedOwner": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org: "org1",
+			document: &OrganizationDocument{
+				Version: ORGANIZATION_DOCUMENT_VERSION,
+				Groups: []DocumentGroup{
+					{
+						Name:   "group1",
+						Path:   "/path/",
+						Owners: []string{"user1", "user1"},
+					},
+				},
+			},
+			conflictMode: IMPORT_CONFLICT_FAIL,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: owner user1",
+			},
+		},
+		"ErrorCaseMembersWithMembershipRule": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org: "org1",
+			document: &OrganizationDocument{
+				Version: ORGANIZATION_DOCUMENT_VERSION,
+				Groups: []DocumentGroup{
+					{
+						Name: "group1",
+						Path: "/path/",
+						MembershipRule: &MembershipRule{
+							PathPrefix: "/path/",
+						},
+						Members: []DocumentMember{
+							{
+								ExternalID: "user1",
+							},
+						},
+					},
+				},
+			},
+			conflictMode: IMPORT_CONFLICT_FAIL,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: members of group group1 are defined by its membership rule",
+			},
+		},
+		"ErrorCaseNotAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			org:          "org1",
+			document:     document,
+			conflictMode: IMPORT_CONFLICT_FAIL,
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to manage organization documents",
+			},
+		},
+		"ErrorCaseInvalidConflictMode": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:          "org1",
+			document:     document,
+			conflictMode: "merge",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: conflict merge",
+			},
+		},
+		"ErrorCaseInvalidVersion": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org: "org1",
+			document: &OrganizationDocument{
+				Version: "2",
+			},
+			conflictMode: IMPORT_CONFLICT_FAIL,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: version 2",
+			},
+		},
+		"ErrorCaseDuplicatedGroup": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org: "org1",
+			document: &OrganizationDocument{
+				Version: ORGANIZATION_DOCUMENT_VERSION,
+				Groups: []DocumentGroup{
+					{
+						Name: "group1",
+						Path: "/path/",
+					},
+					{
+						Name: "group1",
+						Path: "/path2/",
+					},
+				},
+			},
+			conflictMode: IMPORT_CONFLICT_FAIL,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: group name group1",
+			},
+		},
+		"ErrorCaseInvalidStatements": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org: "org1",
+			document: &OrganizationDocument{
+				Version: ORGANIZATION_DOCUMENT_VERSION,
+				Policies: []DocumentPolicy{
+					{
+						Name: "policy1",
+						Path: "/path/",
+						Statements: []Statement{
+							{
+								Effect:    "idk",
+								Actions:   []string{USER_ACTION_GET_USER},
+								Resources: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+							},
+						},
+					},
+				},
+			},
+			conflictMode: IMPORT_CONFLICT_FAIL,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid effect: idk - Only 'allow' and 'deny' accepted",
+			},
+		},
+		"ErrorCaseExpiredMember": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org: "org1",
+			document: &OrganizationDocument{
+				Version: ORGANIZATION_DOCUMENT_VERSION,
+				Groups: []DocumentGroup{
+					{
+						Name: "group1",
+						Path: "/path/",
+						Members: []DocumentMember{
+							{
+								ExternalID: "user1",
+								ExpiresAt:  &past,
+							},
+						},
+					},
+				},
+			},
+			conflictMode: IMPORT_CONFLICT_FAIL,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: expiresAt " + past.String() + " is in the past",
+			},
+		},
+		"ErrorCasePolicyAlreadyExist": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:          "org1",
+			document:     document,
+			conflictMode: IMPORT_CONFLICT_FAIL,
+			wantError: &Error{
+				Code:    POLICY_ALREADY_EXIST,
+				Message: "Unable to import policy, policy with org org1 and name policy1 already exist",
+			},
+			getPolicyByNameFunc: existingPolicy,
+			getGroupByNameFunc:  existingGroup,
+		},
+		"ErrorCaseGroupAlreadyExist": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org: "org1",
+			document: &OrganizationDocument{
+				Version: ORGANIZATION_DOCUMENT_VERSION,
+				Groups: []DocumentGroup{
+					{
+						Name: "group1",
+						Path: "/path/",
+					},
+				},
+			},
+			conflictMode: IMPORT_CONFLICT_FAIL,
+			wantError: &Error{
+				Code:    GROUP_ALREADY_EXIST,
+				Message: "Unable to import group, group with org org1 and name group1 already exist",
+			},
+			getGroupByNameFunc: existingGroup,
+		},
+		"ErrorCaseUserNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:          "org2",
+			document:     document,
+			conflictMode: IMPORT_CONFLICT_FAIL,
+			wantError: &Error{
+				Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "Unable to add member to group group1, user with externalId user1 not found",
+			},
+			getPolicyByNameFunc: createdPolicy,
+			getGroupByNameFunc:  notFoundGroup,
+			getUserByExternalIDErr: &database.Error{
+				Code: database.USER_NOT_FOUND,
+			},
+		},
+		"ErrorCasePolicyNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org: "org2",
+			document: &OrganizationDocument{
+				Version: ORGANIZATION_DOCUMENT_VERSION,
+				Groups: []DocumentGroup{
+					{
+						Name: "group1",
+						Path: "/path/",
+						Policies: []DocumentAttachment{
+							{
+								Policy: "policy1",
+							},
+						},
+					},
+				},
+			},
+			conflictMode: IMPORT_CONFLICT_FAIL,
+			wantError: &Error{
+				Code:    POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Unable to attach policy to group group1, policy with org org2 and name policy1 not found",
+			},
+			getPolicyByNameFunc: existingPolicy,
+			getGroupByNameFunc:  notFoundGroup,
+		},
+		"ErrorCaseOrganizationNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:          "org2",
+			document:     document,
+			conflictMode: IMPORT_CONFLICT_FAIL,
+			wantError: &Error{
+				Code:    ORGANIZATION_BY_NAME_NOT_FOUND,
+				Message: "Organization with name org2 not found",
+			},
+			getOrganizationByNameErr: &database.Error{
+				Code:    database.ORGANIZATION_NOT_FOUND,
+				Message: "Organization with name org2 not found",
+			},
+		},
+		"ErrorCaseTransactionError": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:          "org1",
+			document:     document,
+			conflictMode: IMPORT_CONFLICT_FAIL,
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			runInTransactionErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		for name := range createdPolicies {
+			delete(createdPolicies, name)
+		}
+		testRepo.SpecialFuncs[GetPolicyByNameMethod] = testcase.getPolicyByNameFunc
+		testRepo.SpecialFuncs[GetGroupByNameMethod] = testcase.getGroupByNameFunc
+		testRepo.ArgsOut[AddPolicyMethod][0] = &Policy{
+			ID:   "PolicyID",
+			Name: "policy1",
+		}
+		testRepo.ArgsOut[AddGroupMethod][0] = &Group{
+			ID:   "GroupID",
+			Name: "group1",
+		}
+		testRepo.ArgsOut[UpdateGroupMethod][0] = &Group{
+			ID:   "GroupID",
+			Name: "group1",
+		}
+		testRepo.ArgsOut[SetGroupMembershipRuleMethod][0] = &Group{
+			ID:             "GroupID",
+			Name:           "group1",
+			MembershipRule: testcase.expectedMembershipRule,
+		}
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = &User{
+			ID:         "UserID",
+			ExternalID: "user1",
+		}
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDErr
+		testRepo.ArgsOut[GetGroupUserRelationsMethod][0] = []GroupUserRelation{
+			{
+				User: &User{
+					ID: "UserID2",
+				},
+			},
+		}
+		testRepo.ArgsOut[GetGroupPolicyRelationsMethod][0] = []GroupPolicyRelation{
+			{
+				Policy: &Policy{
+					ID: "PolicyID2",
+				},
+			},
+		}
+		testRepo.ArgsOut[RunInTransactionMethod][0] = testcase.runInTransactionErr
+		testRepo.ArgsOut[GetOrganizationByNameMethod][1] = testcase.getOrganizationByNameErr
+		result, err := testAPI.ImportOrganization(testcase.requestInfo, testcase.org, testcase.document, testcase.conflictMode)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, result)
+		if testcase.expectedMembershipRule != nil {
+			if diff := pretty.Compare(testRepo.ArgsIn[SetGroupMembershipRuleMethod][1], testcase.expectedMembershipRule); diff != "" {
+				t.Errorf("Test %v failed. Received different membership rule (received/wanted) %v", x, diff)
+			}
+		}
+		if testcase.expectedOwnerID != "" && testRepo.ArgsIn[AddOwnerMethod][0] != testcase.expectedOwnerID {
+			t.Errorf("Test %v failed. Received different owner %v", x, testRepo.ArgsIn[AddOwnerMethod][0])
+		}
+	}
+}
+
+func TestAuthAPI_AddOrganization(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		name        string
+		description string
+		// Expected result
+		expectedResponse *Organization
+		wantError        error
+		// Manager Results
+		getUserByExternalIDResult *User
+		getGroupsByUserIDResult   []Group
+		getAttachedPoliciesResult []Policy
+		getOrganizationByName     *Organization
+		// Manager Errors
+		getOrganizationByNameErr error
+		addOrganizationErr       error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name:        "org1",
+			description: "Organization 1",
+			expectedResponse: &Organization{
+				ID:          "OrgID",
+				Name:        "org1",
+				Description: "Organization 1",
+				Urn:         CreateUrn("", RESOURCE_ORGANIZATION, "/", "org1"),
+			},
+			getOrganizationByNameErr: &database.Error{
+				Code: database.ORGANIZATION_NOT_FOUND,
+			},
+		},
+		"OkCaseGranted": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			name: "org1",
+			expectedResponse: &Organization{
+				ID:   "OrgID",
+				Name: "org1",
+				Urn:  CreateUrn("", RESOURCE_ORGANIZATION, "/", "org1"),
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "123456",
+				ExternalID: "123456",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "123456"),
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesResult: []Policy{
+				{
+					ID:   "POLICY-USER-ID",
+					Name: "policyUser",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+					Statements: &[]Statement{
+						{
+							Effect:    "allow",
+							Actions:   []string{ORGANIZATION_ACTION_CREATE_ORGANIZATION},
+							Resources: []string{GetUrnPrefix("", RESOURCE_ORGANIZATION, "/")},
+						},
+					},
+				},
+			},
+			getOrganizationByNameErr: &database.Error{
+				Code: database.ORGANIZATION_NOT_FOUND,
+			},
+		},
+		"ErrorCaseInvalidName": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name: "*%~#@|",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: name *%~#@|",
+			},
+		},
+		"ErrorCaseNoPermissions": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			name: "org1",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam::organization/org1",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "123456",
+				ExternalID: "123456",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "123456"),
+			},
+		},
+		"ErrorCaseAlreadyExist": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name: "org1",
+			wantError: &Error{
+				Code:    ORGANIZATION_ALREADY_EXIST,
+				Message: "Unable to create organization, organization with name org1 already exist",
+			},
+			getOrganizationByName: &Organization{
+				ID:   "OrgID",
+				Name: "org1",
+			},
+		},
+		"ErrorCaseAddOrganizationDBError": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name: "org1",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getOrganizationByNameErr: &database.Error{
+				Code: database.ORGANIZATION_NOT_FOUND,
+			},
+			addOrganizationErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
+		testRepo.ArgsOut[GetOrganizationByNameMethod][0] = testcase.getOrganizationByName
+		testRepo.ArgsOut[GetOrganizationByNameMethod][1] = testcase.getOrganizationByNameErr
+		testRepo.ArgsOut[AddOrganizationMethod][0] = testcase.expectedResponse
+		testRepo.ArgsOut[AddOrganizationMethod][1] = testcase.addOrganizationErr
+		organization, err := testAPI.AddOrganization(testcase.requestInfo, testcase.name, testcase.description)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, organization)
+	}
+}
+
+func TestAuthAPI_GetOrganizationByName(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		name        string
+		// Expected result
+		expectedResponse *Organization
+		wantError        error
+		// Manager Results
+		getUserByExternalIDResult *User
+		getOrganizationByName     *Organization
+		// Manager Errors
+		getOrganizationByNameErr error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name: "org1",
+			expectedResponse: &Organization{
+				ID:   "OrgID",
+				Name: "org1",
+				Urn:  CreateUrn("", RESOURCE_ORGANIZATION, "/", "org1"),
+			},
+			getOrganizationByName: &Organization{
+				ID:   "OrgID",
+				Name: "org1",
+				Urn:  CreateUrn("", RESOURCE_ORGANIZATION, "/", "org1"),
+			},
+		},
+		"ErrorCaseNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name: "org1",
+			wantError: &Error{
+				Code:    ORGANIZATION_BY_NAME_NOT_FOUND,
+				Message: "Organization with name org1 not found",
+			},
+			getOrganizationByNameErr: &database.Error{
+				Code:    database.ORGANIZATION_NOT_FOUND,
+				Message: "Organization with name org1 not found",
+			},
+		},
+		"ErrorCaseNoPermissions": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			name: "org1",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam::organization/org1",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "123456",
+				ExternalID: "123456",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "123456"),
+			},
+			getOrganizationByName: &Organization{
+				ID:   "OrgID",
+				Name: "org1",
+				Urn:  CreateUrn("", RESOURCE_ORGANIZATION, "/", "org1"),
+			},
+		},
+		"ErrorCaseDBError": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name: "org1",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getOrganizationByNameErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetOrganizationByNameMethod][0] = testcase.getOrganizationByName
+		testRepo.ArgsOut[GetOrganizationByNameMethod][1] = testcase.getOrganizationByNameErr
+		organization, err := testAPI.GetOrganizationByName(testcase.requestInfo, testcase.name)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, organization)
+	}
+}
+
+func TestAuthAPI_ListOrganizations(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		filter      *Filter
+		// Expected result
+		expectedResponse []Organization
+		expectedTotal    int
+		wantError        error
+		// Manager Results
+		getOrganizationsFiltered []Organization
+		// Manager Errors
+		getOrganizationsFilteredErr error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter: &Filter{},
+			expectedResponse: []Organization{
+				{
+					ID:   "OrgID",
+					Name: "org1",
+					Urn:  CreateUrn("", RESOURCE_ORGANIZATION, "/", "org1"),
+				},
+			},
+			expectedTotal: 1,
+			getOrganizationsFiltered: []Organization{
+				{
+					ID:   "OrgID",
+					Name: "org1",
+					Urn:  CreateUrn("", RESOURCE_ORGANIZATION, "/", "org1"),
+				},
+			},
+		},
+		"ErrorCaseInvalidFilter": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter: &Filter{
+				Limit: 10000,
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Limit 10000, max limit allowed: 1000",
+			},
+		},
+		"ErrorCaseDBError": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter: &Filter{},
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getOrganizationsFilteredErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetOrganizationsFilteredMethod][0] = testcase.getOrganizationsFiltered
+		testRepo.ArgsOut[GetOrganizationsFilteredMethod][1] = len(testcase.getOrganizationsFiltered)
+		testRepo.ArgsOut[GetOrganizationsFilteredMethod][2] = testcase.getOrganizationsFilteredErr
+		organizations, total, err := testAPI.ListOrganizations(testcase.requestInfo, testcase.filter)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, organizations)
+		if err == nil && total != testcase.expectedTotal {
+			t.Errorf("Test %v failed. Received different total: %v", x, total)
+		}
+	}
+}
+
+func TestAuthAPI_UpdateOrganization(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo    RequestInfo
+		name           string
+		newDescription string
+		// Expected result
+		expectedResponse *Organization
+		wantError        error
+		// Manager Results
+		getOrganizationByName *Organization
+		// Manager Errors
+		getOrganizationByNameErr error
+		updateOrganizationErr    error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name:           "org1",
+			newDescription: "New description",
+			expectedResponse: &Organization{
+				ID:          "OrgID",
+				Name:        "org1",
+				Description: "New description",
+			},
+			getOrganizationByName: &Organization{
+				ID:   "OrgID",
+				Name: "org1",
+			},
+		},
+		"ErrorCaseInvalidDescription": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name:           "org1",
+			newDescription: GetRandomString([]rune("d"), MAX_DESCRIPTION_LENGTH+1),
+			wantError: &Error{
+				Code: INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: description " +
+					GetRandomString([]rune("d"), MAX_DESCRIPTION_LENGTH+1),
+			},
+		},
+		"ErrorCaseNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name: "org1",
+			wantError: &Error{
+				Code:    ORGANIZATION_BY_NAME_NOT_FOUND,
+				Message: "Organization with name org1 not found",
+			},
+			getOrganizationByNameErr: &database.Error{
+				Code:    database.ORGANIZATION_NOT_FOUND,
+				Message: "Organization with name org1 not found",
+			},
+		},
+		"ErrorCaseUpdateDBError": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name: "org1",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getOrganizationByName: &Organization{
+				ID:   "OrgID",
+				Name: "org1",
+			},
+			updateOrganizationErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetOrganizationByNameMethod][0] = testcase.getOrganizationByName
+		testRepo.ArgsOut[GetOrganizationByNameMethod][1] = testcase.getOrganizationByNameErr
+		testRepo.ArgsOut[UpdateOrganizationMethod][0] = testcase.expectedResponse
+		testRepo.ArgsOut[UpdateOrganizationMethod][1] = testcase.updateOrganizationErr
+		organization, err := testAPI.UpdateOrganization(testcase.requestInfo, testcase.name, testcase.newDescription)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, organization)
+	}
+}
+
+func TestAuthAPI_RemoveOrganization(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		name        string
+		// Expected result
+		expectedRemovedGroup  string
+		expectedRemovedPolicy string
+		wantError             error
+		// Manager Results
+		getOrganizationByName *Organization
+		getGroupsFiltered     []Group
+		getPoliciesFiltered   []Policy
+		// Manager Errors
+		getOrganizationByNameErr error
+		removeOrganizationErr    error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name:                  "org1",
+			expectedRemovedGroup:  "GroupID",
+			expectedRemovedPolicy: "PolicyID",
+			getOrganizationByName: &Organization{
+				ID:   "OrgID",
+				Name: "org1",
+			},
+			getGroupsFiltered: []Group{
+				{
+					ID:   "GroupID",
+					Name: "group1",
+					Org:  "org1",
+				},
+			},
+			getPoliciesFiltered: []Policy{
+				{
+					ID:   "PolicyID",
+					Name: "policy1",
+					Org:  "org1",
+				},
+			},
+		},
+		"ErrorCaseNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name: "org1",
+			wantError: &Error{
+				Code:    ORGANIZATION_BY_NAME_NOT_FOUND,
+				Message: "Organization with name org1 not found",
+			},
+			getOrganizationByNameErr: &database.Error{
+				Code:    database.ORGANIZATION_NOT_FOUND,
+				Message: "Organization with name org1 not found",
+			},
+		},
+		"ErrorCaseRemoveDBError": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name: "org1",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getOrganizationByName: &Organization{
+				ID:   "OrgID",
+				Name: "org1",
+			},
+			removeOrganizationErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetOrganizationByNameMethod][0] = testcase.getOrganizationByName
+		testRepo.ArgsOut[GetOrganizationByNameMethod][1] = testcase.getOrganizationByNameErr
+		testRepo.ArgsOut[GetGroupsFilteredMethod][0] = testcase.getGroupsFiltered
+		testRepo.ArgsOut[GetPoliciesFilteredMethod][0] = testcase.getPoliciesFiltered
+		testRepo.ArgsOut[RemoveOrganizationMethod][0] = testcase.removeOrganizationErr
+		err := testAPI.RemoveOrganization(testcase.requestInfo, testcase.name)
+		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+		if err == nil {
+			if removed, _ := testRepo.ArgsIn[RemoveGroupMethod][0].(Group); removed.ID != testcase.expectedRemovedGroup {
+				t.Errorf("Test %v failed. Received different removed group: %v", x, removed.ID)
+			}
+			if removed, _ := testRepo.ArgsIn[RemovePolicyMethod][0].(Policy); removed.ID != testcase.expectedRemovedPolicy {
+				t.Errorf("Test %v failed. Received different removed policy: %v", x, removed.ID)
+			}
+		}
+	}
+}
@@ -0,0 +1,412 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// TYPE DEFINITIONS
+
+const (
+	// Policy patch formats
+	PATCH_TYPE_JSON_PATCH  = "application/json-patch+json"
+	PATCH_TYPE_MERGE_PATCH = "application/merge-patch+json"
+
+	// JSON Patch operations
+	PATCH_OPERATION_ADD     = "add"
+	PATCH_OPERATION_REMOVE  = "remove"
+	PATCH_OPERATION_REPLACE = "replace"
+	PATCH_OPERATION_MOVE    = "move"
+	PATCH_OPERATION_COPY    = "copy"
+	PATCH_OPERATION_TEST    = "test"
+)
+
+// Path of a patch operation that doesn't exist in the document
+var errPatchPathNotFound = errors.New("path not found")
+
+// Operation of a JSON Patch (RFC 6902)
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Fields of a policy that can be patched
+type policyPatchDocument struct {
+	Name       string      `json:"name"`
+	Path       string      `json:"path"`
+	Statements []Statement `json:"statements"`
+}
+
+// PATCH API IMPLEMENTATION
+
+func (api AuthAPI) PatchPolicy(requestInfo RequestInfo, org string, policyName string, patchType string, patch []byte) (*Policy, error) {
+	if patchType != PATCH_TYPE_JSON_PATCH && patchType != PATCH_TYPE_MERGE_PATCH {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: patch type %v", patchType),
+		}
+	}
+
+	// Call repo to retrieve the policy
+	policy, err := api.GetPolicyByName(requestInfo, org, policyName)
+	if err != nil {
+		return nil, err
+	}
+
+	document := policyPatchDocument{
+		Name: policy.Name,
+		Path: policy.Path,
+	}
+	if policy.Statements != nil {
+		document.Statements = *policy.Statements
+	}
+	patchedDocument, err := applyPolicyPatch(document, patchType, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	return api.updateRetrievedPolicy(requestInfo, policy, patchedDocument.Name, patchedDocument.Path, patchedDocument.Statements)
+}
+
+// PRIVATE HELPER METHODS
+
+// Apply patch to the policy document, returning the patched document
+func applyPolicyPatch(document policyPatchDocument, patchType string, patch []byte) (*policyPatchDocument, error) {
+	// Work with the generic JSON representation of the document
+	var target interface{}
+	content, err := json.Marshal(document)
+	if err != nil {
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: err.Error(),
+		}
+	}
+	if err := json.Unmarshal(content, &target); err != nil {
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	switch patchType {
+	case PATCH_TYPE_JSON_PATCH:
+		operations := []patchOperation{}
+		if err := json.Unmarshal(patch, &operations); err != nil {
+			return nil, &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: patch %v", err.Error()),
+			}
+		}
+		target, err = applyJSONPatch(target, operations)
+		if err != nil {
+			return nil, err
+		}
+	case PATCH_TYPE_MERGE_PATCH:
+		var mergePatch interface{}
+		if err := json.Unmarshal(patch, &mergePatch); err != nil {
+			return nil, &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: patch %v", err.Error()),
+			}
+		}
+		target = applyMergePatch(target, mergePatch)
+	}
+
+	// Patched document can only have policy fields
+	content, err = json.Marshal(target)
+	if err != nil {
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: err.Error(),
+		}
+	}
+	patchedDocument := &policyPatchDocument{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(patchedDocument); err != nil {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: patched policy %v", err.Error()),
+		}
+	}
+
+	return patchedDocument, nil
+}
+
+// Apply JSON Patch (RFC 6902) operations to the document in order. The whole patch fails if any operation fails
+func applyJSONPatch(document interface{}, operations []patchOperation) (interface{}, error) {
+	for _, operation := range operations {
+		path, err := parseJSONPointer(operation.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		switch operation.Op {
+		case PATCH_OPERATION_ADD, PATCH_OPERATION_REPLACE, PATCH_OPERATION_TEST:
+			if len(operation.Value) == 0 {
+				return nil, &Error{
+					Code:    INVALID_PARAMETER_ERROR,
+					Message: fmt.Sprintf("Invalid parameter: patch operation %v without value", operation.Op),
+				}
+			}
+			var value interface{}
+			if err := json.Unmarshal(operation.Value, &value); err != nil {
+				return nil, &Error{
+					Code:    INVALID_PARAMETER_ERROR,
+					Message: fmt.Sprintf("Invalid parameter: patch value %v", err.Error()),
+				}
+			}
+			switch operation.Op {
+			case PATCH_OPERATION_ADD:
+				document, err = addJSONValue(document, path, value)
+			case PATCH_OPERATION_REPLACE:
+				document, err = replaceJSONValue(document, path, value)
+			case PATCH_OPERATION_TEST:
+				var current interface{}
+				current, err = getJSONValue(document, path)
+				if err == nil && !reflect.DeepEqual(current, value) {
+					return nil, &Error{
+						Code:    PATCH_TEST_FAILED,
+						Message: fmt.Sprintf("Patch test failed, value at path %v doesn't match", operation.Path),
+					}
+				}
+			}
+		case PATCH_OPERATION_REMOVE:
+			document, err = removeJSONValue(document, path)
+		case PATCH_OPERATION_MOVE, PATCH_OPERATION_COPY:
+			from, err := parseJSONPointer(operation.From)
+			if err != nil {
+				return nil, err
+			}
+			if operation.Op == PATCH_OPERATION_MOVE && strings.HasPrefix(operation.Path, operation.From+"/") {
+				return nil, &Error{
+					Code:    INVALID_PARAMETER_ERROR,
+					Message: fmt.Sprintf("Invalid parameter: patch can't move path %v to its child %v", operation.From, operation.Path),
+				}
+			}
+			value, err := getJSONValue(document, from)
+			if err != nil {
+				return nil, patchPathError(operation.From, err)
+			}
+			if operation.Op == PATCH_OPERATION_MOVE {
+				if document, err = removeJSONValue(document, from); err != nil {
+					return nil, patchPathError(operation.From, err)
+				}
+			} else if value, err = copyJSONValue(value); err != nil {
+				return nil, err
+			}
+			document, err = addJSONValue(document, path, value)
+			if err != nil {
+				return nil, patchPathError(operation.Path, err)
+			}
+		default:
+			return nil, &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: patch operation %v", operation.Op),
+			}
+		}
+		if err != nil {
+			return nil, patchPathError(operation.Path, err)
+		}
+	}
+	return document, nil
+}
+
+// Apply JSON Merge Patch (RFC 7396) to the document. Null values remove members and arrays are replaced
+func applyMergePatch(document interface{}, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	documentObject, ok := document.(map[string]interface{})
+	if !ok {
+		documentObject = map[string]interface{}{}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(documentObject, key)
+		} else {
+			documentObject[key] = applyMergePatch(documentObject[key], value)
+		}
+	}
+	return documentObject
+}
+
+// Split JSON Pointer (RFC 6901) into its unescaped reference tokens. Empty pointer references the whole document
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: patch path %v", pointer),
+		}
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+func getJSONValue(document interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		child, err := getJSONChild(document, token)
+		if err != nil {
+			return nil, err
+		}
+		document = child
+	}
+	return document, nil
+}
+
+func addJSONValue(document interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return updateJSONParent(document, path, func(parent interface{}, token string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			p[token] = value
+			return p, nil
+		case []interface{}:
+			if token == "-" {
+				return append(p, value), nil
+			}
+			i, ok := jsonArrayIndex(token, len(p)+1)
+			if !ok {
+				return nil, errPatchPathNotFound
+			}
+			p = append(p, nil)
+			copy(p[i+1:], p[i:])
+			p[i] = value
+			return p, nil
+		default:
+			return nil, errPatchPathNotFound
+		}
+	})
+}
+
+func replaceJSONValue(document interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return updateJSONParent(document, path, func(parent interface{}, token string) (interface{}, error) {
+		if _, err := getJSONChild(parent, token); err != nil {
+			return nil, err
+		}
+		return setJSONChild(parent, token, value), nil
+	})
+}
+
+func removeJSONValue(document interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, errPatchPathNotFound
+	}
+	return updateJSONParent(document, path, func(parent interface{}, token string) (interface{}, error) {
+		if _, err := getJSONChild(parent, token); err != nil {
+			return nil, err
+		}
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			delete(p, token)
+			return p, nil
+		case []interface{}:
+			i, _ := jsonArrayIndex(token, len(p))
+			return append(append([]interface{}{}, p[:i]...), p[i+1:]...), nil
+		}
+		return nil, errPatchPathNotFound
+	})
+}
+
+// Apply change to the parent of the value referenced by path, returning the updated document
+func updateJSONParent(document interface{}, path []string,
+	change func(parent interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return change(document, path[0])
+	}
+	child, err := getJSONChild(document, path[0])
+	if err != nil {
+		return nil, err
+	}
+	updatedChild, err := updateJSONParent(child, path[1:], change)
+	if err != nil {
+		return nil, err
+	}
+	return setJSONChild(document, path[0], updatedChild), nil
+}
+
+func getJSONChild(node interface{}, token string) (interface{}, error) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if child, ok := n[token]; ok {
+			return child, nil
+		}
+	case []interface{}:
+		if i, ok := jsonArrayIndex(token, len(n)); ok {
+			return n[i], nil
+		}
+	}
+	return nil, errPatchPathNotFound
+}
+
+// Replace existing child of node
+func setJSONChild(node interface{}, token string, value interface{}) interface{} {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		n[token] = value
+	case []interface{}:
+		i, _ := jsonArrayIndex(token, len(n))
+		n[i] = value
+	}
+	return node
+}
+
+// Parse array index token, that must be lower than size and can't have leading zeros
+func jsonArrayIndex(token string, size int) (int, bool) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, false
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i >= size {
+		return 0, false
+	}
+	return i, true
+}
+
+func copyJSONValue(value interface{}) (interface{}, error) {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: err.Error(),
+		}
+	}
+	var copied interface{}
+	if err := json.Unmarshal(content, &copied); err != nil {
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: err.Error(),
+		}
+	}
+	return copied, nil
+}
+
+// Transform path errors into API errors
+func patchPathError(path string, err error) error {
+	if err == errPatchPathNotFound {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: patch path %v not found", path),
+		}
+	}
+	return err
+}
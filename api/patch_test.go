@@ -0,0 +1,206 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestAuthAPI_PatchPolicy(t *testing.T) {
+	first := (*makeStatementsTestPolicy().Statements)[0]
+	second := (*makeStatementsTestPolicy().Statements)[1]
+	allowedSecond := second
+	allowedSecond.Effect = "allow"
+	third := Statement{
+		Sid:    "third",
+		Effect: "allow",
+		Actions: []string{
+			USER_ACTION_LIST_USERS,
+		},
+		Resources: []string{
+			GetUrnPrefix("", RESOURCE_USER, "/path/"),
+		},
+	}
+	copiedFirst := first
+	copiedFirst.Sid = "copy"
+
+	testcases := map[string]struct {
+		requestInfo RequestInfo
+		patchType   string
+		patch       string
+
+		expectedName       string
+		expectedPath       string
+		expectedStatements []Statement
+		wantError          error
+	}{
+		"OkCaseJSONPatchReplace": {
+			patchType:          PATCH_TYPE_JSON_PATCH,
+			patch:              `[{"op": "replace", "path": "/statements/1/effect", "value": "allow"}]`,
+			expectedName:       "test",
+			expectedPath:       "/path/",
+			expectedStatements: []Statement{first, allowedSecond},
+		},
+		"OkCaseJSONPatchAdd": {
+			patchType: PATCH_TYPE_JSON_PATCH,
+			patch: `[{"op": "add", "path": "/statements/-", "value": {"sid": "third", "effect": "allow",
+				"actions": ["iam:ListUsers"], "resources": ["urn:iws:iam::user/path/*"]}}]`,
+			expectedName:       "test",
+			expectedPath:       "/path/",
+			expectedStatements: []Statement{first, second, third},
+		},
+		"OkCaseJSONPatchInsert": {
+			patchType: PATCH_TYPE_JSON_PATCH,
+			patch: `[{"op": "add", "path": "/statements/0", "value": {"sid": "third", "effect": "allow",
+				"actions": ["iam:ListUsers"], "resources": ["urn:iws:iam::user/path/*"]}}]`,
+			expectedName:       "test",
+			expectedPath:       "/path/",
+			expectedStatements: []Statement{third, first, second},
+		},
+		"OkCaseJSONPatchRemove": {
+			patchType:          PATCH_TYPE_JSON_PATCH,
+			patch:              `[{"op": "remove", "path": "/statements/0"}]`,
+			expectedName:       "test",
+			expectedPath:       "/path/",
+			expectedStatements: []Statement{second},
+		},
+		"OkCaseJSONPatchMove": {
+			patchType:          PATCH_TYPE_JSON_PATCH,
+			patch:              `[{"op": "move", "from": "/statements/1", "path": "/statements/0"}]`,
+			expectedName:       "test",
+			expectedPath:       "/path/",
+			expectedStatements: []Statement{second, first},
+		},
+		"OkCaseJSONPatchCopy": {
+			patchType: PATCH_TYPE_JSON_PATCH,
+			patch: `[{"op": "copy", "from": "/statements/0", "path": "/statements/-"},
+				{"op": "replace", "path": "/statements/2/sid", "value": "copy"}]`,
+			expectedName:       "test",
+			expectedPath:       "/path/",
+			expectedStatements: []Statement{first, second, copiedFirst},
+		},
+		"OkCaseJSONPatchTestAndRename": {
+			patchType: PATCH_TYPE_JSON_PATCH,
+			patch: `[{"op": "test", "path": "/statements/0/sid", "value": "first"},
+				{"op": "replace", "path": "/name", "value": "test2"}]`,
+			expectedName:       "test2",
+			expectedPath:       "/path/",
+			expectedStatements: []Statement{first, second},
+		},
+		"OkCaseMergePatch": {
+			patchType:          PATCH_TYPE_MERGE_PATCH,
+			patch:              `{"path": "/path2/"}`,
+			expectedName:       "test",
+			expectedPath:       "/path2/",
+			expectedStatements: []Statement{first, second},
+		},
+		"OkCaseMergePatchStatements": {
+			patchType: PATCH_TYPE_MERGE_PATCH,
+			patch: `{"statements": [{"sid": "third", "effect": "allow", "actions": ["iam:ListUsers"],
+				"resources": ["urn:iws:iam::user/path/*"]}]}`,
+			expectedName:       "test",
+			expectedPath:       "/path/",
+			expectedStatements: []Statement{third},
+		},
+		"ErrorCaseInvalidPatchType": {
+			patchType: "application/json",
+			patch:     `{"path": "/path2/"}`,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: patch type application/json",
+			},
+		},
+		"ErrorCaseInvalidJSONPatch": {
+			patchType: PATCH_TYPE_JSON_PATCH,
+			patch:     `{"op": "remove", "path": "/statements/0"}`,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: patch json: cannot unmarshal object into Go value of type []api.patchOperation",
+			},
+		},
+		"ErrorCaseInvalidOperation": {
+			patchType: PATCH_TYPE_JSON_PATCH,
+			patch:     `[{"op": "delete", "path": "/statements/0"}]`,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: patch operation delete",
+			},
+		},
+		"ErrorCaseOperationWithoutValue": {
+			patchType: PATCH_TYPE_JSON_PATCH,
+			patch:     `[{"op": "add", "path": "/statements/-"}]`,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: patch operation add without value",
+			},
+		},
+		"ErrorCaseInvalidPath": {
+			patchType: PATCH_TYPE_JSON_PATCH,
+			patch:     `[{"op": "remove", "path": "statements"}]`,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: patch path statements",
+			},
+		},
+		"ErrorCasePathNotFound": {
+			patchType: PATCH_TYPE_JSON_PATCH,
+			patch:     `[{"op": "remove", "path": "/statements/2"}]`,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: patch path /statements/2 not found",
+			},
+		},
+		"ErrorCaseMoveToChild": {
+			patchType: PATCH_TYPE_JSON_PATCH,
+			patch:     `[{"op": "move", "from": "/statements", "path": "/statements/0"}]`,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: patch can't move path /statements to its child /statements/0",
+			},
+		},
+		"ErrorCaseTestFailed": {
+			patchType: PATCH_TYPE_JSON_PATCH,
+			patch: `[{"op": "test", "path": "/statements/0/effect", "value": "deny"},
+				{"op": "remove", "path": "/statements/0"}]`,
+			wantError: &Error{
+				Code:    PATCH_TEST_FAILED,
+				Message: "Patch test failed, value at path /statements/0/effect doesn't match",
+			},
+		},
+		"ErrorCaseReadOnlyField": {
+			patchType: PATCH_TYPE_JSON_PATCH,
+			patch:     `[{"op": "add", "path": "/id", "value": "other"}]`,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: patched policy json: unknown field \"id\"",
+			},
+		},
+		"ErrorCaseInvalidPatchedPolicy": {
+			patchType: PATCH_TYPE_MERGE_PATCH,
+			patch:     `{"name": null}`,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: new name ",
+			},
+		},
+	}
+
+	testRepo := makeTestRepo()
+	testAPI := makeTestAPI(testRepo)
+
+	for x, testcase := range testcases {
+		testRepo.ArgsIn[UpdatePolicyMethod] = make([]interface{}, 5)
+		testRepo.ArgsOut[GetPolicyByNameMethod][0] = makeStatementsTestPolicy()
+		testRepo.ArgsOut[UpdatePolicyMethod][0] = makeStatementsTestPolicy()
+		_, err := testAPI.PatchPolicy(RequestInfo{Identifier: "123456", Admin: true}, "123", "test", testcase.patchType, []byte(testcase.patch))
+		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+		if testcase.wantError == nil {
+			received := []interface{}{testRepo.ArgsIn[UpdatePolicyMethod][1], testRepo.ArgsIn[UpdatePolicyMethod][2],
+				testRepo.ArgsIn[UpdatePolicyMethod][4]}
+			expected := []interface{}{testcase.expectedName, testcase.expectedPath, testcase.expectedStatements}
+			if diff := pretty.Compare(received, expected); diff != "" {
+				t.Errorf("Test %v failed. Received different policy update (received/wanted) %v", x, diff)
+			}
+		}
+	}
+}
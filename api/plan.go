@@ -0,0 +1,464 @@
+package api
+
+import (
+	"fmt"
+	"time"
+)
+
+// TYPE DEFINITIONS
+
+const (
+	// Plan change actions
+	PLAN_ACTION_CREATE = "create"
+	PLAN_ACTION_UPDATE = "update"
+	PLAN_ACTION_DELETE = "delete"
+
+	// Plan change resources
+	PLAN_RESOURCE_POLICY     = "policy"
+	PLAN_RESOURCE_GROUP      = "group"
+	PLAN_RESOURCE_MEMBER     = "member"
+	PLAN_RESOURCE_OWNER      = "owner"
+	PLAN_RESOURCE_ATTACHMENT = "attachment"
+)
+
+// Change needed to reach the desired state of an organization. Name is the name of the policy or
+// group, the externalId of the member or owner or the name of the attached policy. Group is the
+// group of members, owners and attachments.
+type PlanChange struct {
+	Action   string `json:"action, omitempty"`
+	Resource string `json:"resource, omitempty"`
+	Name     string `json:"name, omitempty"`
+	Group    string `json:"group, omitempty"`
+}
+
+func (c PlanChange) String() string {
+	if c.Group != "" {
+		return fmt.Sprintf("%v %v %v of group %v", c.Action, c.Resource, c.Name, c.Group)
+	}
+	return fmt.Sprintf("%v %v %v", c.Action, c.Resource, c.Name)
+}
+
+// Ordered list of changes to apply to an organization
+type Plan struct {
+	Org     string       `json:"org, omitempty"`
+	Changes []PlanChange `json:"changes, omitempty"`
+}
+
+// PLAN API IMPLEMENTATION
+
+func (api AuthAPI) PlanOrganization(requestInfo RequestInfo, org string, document *OrganizationDocument) (*Plan, error) {
+	if err := api.validateDesiredState(requestInfo, org, document); err != nil {
+		return nil, err
+	}
+
+	// Compare desired state with current state
+	current, err := api.getOrganizationDocument(org)
+	if err != nil {
+		return nil, toUnknownAPIError(err)
+	}
+	plan := &Plan{
+		Org:     org,
+		Changes: diffOrganizationDocuments(current, document),
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Organization %v planned with %v changes", org, len(plan.Changes)))
+	return plan, nil
+}
+
+func (api AuthAPI) ApplyOrganization(requestInfo RequestInfo, org string, document *OrganizationDocument,
+	confirmedPlan *Plan) (*Plan, error) {
+	if err := api.validateDesiredState(requestInfo, org, document); err != nil {
+		return nil, err
+	}
+	if confirmedPlan == nil {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: "Invalid parameter: plan is required",
+		}
+	}
+	if err := api.checkOrganizationExists(org); err != nil {
+		return nil, err
+	}
+
+	// Plan is computed again inside the transaction, and it is applied only if it is the confirmed one
+	plan := &Plan{
+		Org: org,
+	}
+	err := api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		transactionAPI := api
+		transactionAPI.GroupRepo = repo
+		transactionAPI.PolicyRepo = repo
+		transactionAPI.OrganizationRepo = repo
+		transactionAPI.UserRepo = repo
+		transactionAPI.TagRepo = repo
+
+		current, err := transactionAPI.getOrganizationDocument(org)
+		if err != nil {
+			return err
+		}
+		plan.Changes = diffOrganizationDocuments(current, document)
+		if !equalPlanChanges(plan.Changes, confirmedPlan.Changes) {
+			return &Error{
+				Code:    PLAN_OUTDATED,
+				Message: fmt.Sprintf("Unable to apply plan, organization %v changed since it was planned", org),
+			}
+		}
+
+		for _, change := range plan.Changes {
+			if err := transactionAPI.applyPlanChange(org, document, change); err != nil {
+				return err
+			}
+		}
+		return api.auditOperation(repo, requestInfo, AUDIT_ACTION_APPLY_ORGANIZATION, CreateUrn("", RESOURCE_ORGANIZATION, "/", org), nil, plan)
+	})
+
+	// Error handling
+	if err != nil {
+		return nil, toUnknownAPIError(err)
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Organization %v applied with %v changes", org, len(plan.Changes)))
+	return plan, nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Desired state must be a valid document whose attachments only reference policies of the document,
+// because the rest of the policies of the organization are deleted
+func (api AuthAPI) validateDesiredState(requestInfo RequestInfo, org string, document *OrganizationDocument) error {
+	if err := checkOrganizationDocumentAdmin(requestInfo); err != nil {
+		return err
+	}
+	if !IsValidOrg(org) {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: org %v", org),
+		}
+	}
+	if err := api.validateOrganizationDocument(document); err != nil {
+		return err
+	}
+
+	policies := map[string]bool{}
+	for _, policy := range document.Policies {
+		policies[policy.Name] = true
+	}
+	for _, group := range document.Groups {
+		for _, attachment := range group.Policies {
+			if !policies[attachment.Policy] {
+				return &Error{
+					Code:    INVALID_PARAMETER_ERROR,
+					Message: fmt.Sprintf("Invalid parameter: policy %v attached to group %v isn't in the document", attachment.Policy, group.Name),
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Apply a plan change taking the desired values from the document
+func (api AuthAPI) applyPlanChange(org string, document *OrganizationDocument, change PlanChange) error {
+	switch change.Resource {
+	case PLAN_RESOURCE_POLICY:
+		if change.Action == PLAN_ACTION_CREATE {
+			return api.createDocumentPolicy(org, findDocumentPolicy(document, change.Name))
+		}
+		policy, err := api.PolicyRepo.GetPolicyByName(org, change.Name)
+		if err != nil {
+			return err
+		}
+		if change.Action == PLAN_ACTION_UPDATE {
+			return api.updateDocumentPolicy(org, policy, findDocumentPolicy(document, change.Name))
+		}
+		return api.PolicyRepo.RemovePolicy(*policy)
+	case PLAN_RESOURCE_GROUP:
+		if change.Action == PLAN_ACTION_CREATE {
+			_, err := api.createDocumentGroup(org, findDocumentGroup(document, change.Name))
+			return err
+		}
+		group, err := api.GroupRepo.GetGroupByName(org, change.Name)
+		if err != nil {
+			return err
+		}
+		if change.Action == PLAN_ACTION_UPDATE {
+			_, err := api.updateDocumentGroup(org, group, findDocumentGroup(document, change.Name))
+			return err
+		}
+		return api.GroupRepo.RemoveGroup(*group)
+	case PLAN_RESOURCE_MEMBER:
+		group, err := api.GroupRepo.GetGroupByName(org, change.Group)
+		if err != nil {
+			return err
+		}
+		user, err := api.getDocumentUser(change.Group, "member", change.Name)
+		if err != nil {
+			return err
+		}
+		if change.Action == PLAN_ACTION_DELETE {
+			return api.GroupRepo.RemoveMember(user.ID, group.ID)
+		}
+		if err := checkGroupWithoutMembershipRule(group); err != nil {
+			return err
+		}
+		// Adding a member replaces its current expiration date
+		for _, member := range findDocumentGroup(document, change.Group).Members {
+			if member.ExternalID == change.Name {
+				return api.GroupRepo.AddMember(user.ID, group.ID, member.ExpiresAt)
+			}
+		}
+	case PLAN_RESOURCE_OWNER:
+		group, err := api.GroupRepo.GetGroupByName(org, change.Group)
+		if err != nil {
+			return err
+		}
+		user, err := api.getDocumentUser(change.Group, "owner", change.Name)
+		if err != nil {
+			return err
+		}
+		if change.Action == PLAN_ACTION_DELETE {
+			return api.GroupRepo.RemoveOwner(user.ID, group.ID)
+		}
+		return api.GroupRepo.AddOwner(user.ID, group.ID)
+	case PLAN_RESOURCE_ATTACHMENT:
+		group, err := api.GroupRepo.GetGroupByName(org, change.Group)
+		if err != nil {
+			return err
+		}
+		policy, err := api.getDocumentAttachedPolicy(org, change.Group, change.Name)
+		if err != nil {
+			return err
+		}
+		if change.Action != PLAN_ACTION_CREATE {
+			if err := api.GroupRepo.DetachPolicy(group.ID, policy.ID); err != nil {
+				return err
+			}
+		}
+		if change.Action == PLAN_ACTION_DELETE {
+			return nil
+		}
+		for _, attachment := range findDocumentGroup(document, change.Group).Policies {
+			if attachment.Policy == change.Name {
+				return api.GroupRepo.AttachPolicy(group.ID, policy.ID, attachment.NotBefore, attachment.NotAfter)
+			}
+		}
+	}
+	return nil
+}
+
+// Compute changes to go from current to desired state. Policies and groups are created and updated
+// first, then group members, owners and attachments are synchronized, and finally groups and policies
+// that aren't desired are deleted with their relations.
+func diffOrganizationDocuments(current *OrganizationDocument, desired *OrganizationDocument) []PlanChange {
+	changes := []PlanChange{}
+
+	currentPolicies := map[string]DocumentPolicy{}
+	for _, policy := range current.Policies {
+		currentPolicies[policy.Name] = policy
+	}
+	desiredPolicies := map[string]bool{}
+	for _, policy := range desired.Policies {
+		desiredPolicies[policy.Name] = true
+		currentPolicy, ok := currentPolicies[policy.Name]
+		switch {
+		case !ok:
+			changes = append(changes, PlanChange{Action: PLAN_ACTION_CREATE, Resource: PLAN_RESOURCE_POLICY, Name: policy.Name})
+		case currentPolicy.Path != policy.Path || !equalTags(currentPolicy.Tags, policy.Tags) ||
+			!equalStatements(policy.Statements, currentPolicy.Statements):
+			changes = append(changes, PlanChange{Action: PLAN_ACTION_UPDATE, Resource: PLAN_RESOURCE_POLICY, Name: policy.Name})
+		}
+	}
+
+	currentGroups := map[string]DocumentGroup{}
+	for _, group := range current.Groups {
+		currentGroups[group.Name] = group
+	}
+	desiredGroups := map[string]bool{}
+	for _, group := range desired.Groups {
+		desiredGroups[group.Name] = true
+		currentGroup, ok := currentGroups[group.Name]
+		switch {
+		case !ok:
+			changes = append(changes, PlanChange{Action: PLAN_ACTION_CREATE, Resource: PLAN_RESOURCE_GROUP, Name: group.Name})
+		case currentGroup.Path != group.Path || !equalTags(currentGroup.Tags, group.Tags) ||
+			!equalMembershipRules(currentGroup.MembershipRule, group.MembershipRule):
+			changes = append(changes, PlanChange{Action: PLAN_ACTION_UPDATE, Resource: PLAN_RESOURCE_GROUP, Name: group.Name})
+		}
+	}
+
+	for _, group := range desired.Groups {
+		changes = append(changes, diffGroupRelations(currentGroups[group.Name], group)...)
+	}
+
+	for _, group := range current.Groups {
+		if !desiredGroups[group.Name] {
+			changes = append(changes, PlanChange{Action: PLAN_ACTION_DELETE, Resource: PLAN_RESOURCE_GROUP, Name: group.Name})
+		}
+	}
+	for _, policy := range current.Policies {
+		if !desiredPolicies[policy.Name] {
+			changes = append(changes, PlanChange{Action: PLAN_ACTION_DELETE, Resource: PLAN_RESOURCE_POLICY, Name: policy.Name})
+		}
+	}
+
+	return changes
+}
+
+// Compute member, owner and attachment changes of a group. Current group is empty if it doesn't exist.
+func diffGroupRelations(current DocumentGroup, desired DocumentGroup) []PlanChange {
+	changes := []PlanChange{}
+
+	desiredMembers := map[string]DocumentMember{}
+	for _, member := range desired.Members {
+		desiredMembers[member.ExternalID] = member
+	}
+	currentMembers := map[string]DocumentMember{}
+	for _, member := range current.Members {
+		currentMembers[member.ExternalID] = member
+		if _, ok := desiredMembers[member.ExternalID]; !ok {
+			changes = append(changes, PlanChange{Action: PLAN_ACTION_DELETE, Resource: PLAN_RESOURCE_MEMBER,
+				Name: member.ExternalID, Group: desired.Name})
+		}
+	}
+	for _, member := range desired.Members {
+		currentMember, ok := currentMembers[member.ExternalID]
+		switch {
+		case !ok:
+			changes = append(changes, PlanChange{Action: PLAN_ACTION_CREATE, Resource: PLAN_RESOURCE_MEMBER,
+				Name: member.ExternalID, Group: desired.Name})
+		case !equalTimes(currentMember.ExpiresAt, member.ExpiresAt):
+			changes = append(changes, PlanChange{Action: PLAN_ACTION_UPDATE, Resource: PLAN_RESOURCE_MEMBER,
+				Name: member.ExternalID, Group: desired.Name})
+		}
+	}
+
+	desiredOwners := map[string]bool{}
+	for _, owner := range desired.Owners {
+		desiredOwners[owner] = true
+	}
+	currentOwners := map[string]bool{}
+	for _, owner := range current.Owners {
+		currentOwners[owner] = true
+		if !desiredOwners[owner] {
+			changes = append(changes, PlanChange{Action: PLAN_ACTION_DELETE, Resource: PLAN_RESOURCE_OWNER,
+				Name: owner, Group: desired.Name})
+		}
+	}
+	for _, owner := range desired.Owners {
+		if !currentOwners[owner] {
+			changes = append(changes, PlanChange{Action: PLAN_ACTION_CREATE, Resource: PLAN_RESOURCE_OWNER,
+				Name: owner, Group: desired.Name})
+		}
+	}
+
+	desiredAttachments := map[string]DocumentAttachment{}
+	for _, attachment := range desired.Policies {
+		desiredAttachments[attachment.Policy] = attachment
+	}
+	currentAttachments := map[string]DocumentAttachment{}
+	for _, attachment := range current.Policies {
+		currentAttachments[attachment.Policy] = attachment
+		if _, ok := desiredAttachments[attachment.Policy]; !ok {
+			changes = append(changes, PlanChange{Action: PLAN_ACTION_DELETE, Resource: PLAN_RESOURCE_ATTACHMENT,
+				Name: attachment.Policy, Group: desired.Name})
+		}
+	}
+	for _, attachment := range desired.Policies {
+		currentAttachment, ok := currentAttachments[attachment.Policy]
+		switch {
+		case !ok:
+			changes = append(changes, PlanChange{Action: PLAN_ACTION_CREATE, Resource: PLAN_RESOURCE_ATTACHMENT,
+				Name: attachment.Policy, Group: desired.Name})
+		case !equalTimes(currentAttachment.NotBefore, attachment.NotBefore) ||
+			!equalTimes(currentAttachment.NotAfter, attachment.NotAfter):
+			changes = append(changes, PlanChange{Action: PLAN_ACTION_UPDATE, Resource: PLAN_RESOURCE_ATTACHMENT,
+				Name: attachment.Policy, Group: desired.Name})
+		}
+	}
+
+	return changes
+}
+
+func findDocumentPolicy(document *OrganizationDocument, name string) DocumentPolicy {
+	for _, policy := range document.Policies {
+		if policy.Name == name {
+			return policy
+		}
+	}
+	return DocumentPolicy{}
+}
+
+func findDocumentGroup(document *OrganizationDocument, name string) DocumentGroup {
+	for _, group := range document.Groups {
+		if group.Name == name {
+			return group
+		}
+	}
+	return DocumentGroup{}
+}
+
+func equalPlanChanges(a []PlanChange, b []PlanChange) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Empty and nil tags are equal
+func equalTags(a map[string]string, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		if otherValue, ok := b[key]; !ok || otherValue != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Compare desired statements with current ones. Desired statements without sid match any sid
+func equalStatements(desired []Statement, current []Statement) bool {
+	if len(desired) != len(current) {
+		return false
+	}
+	for i := range desired {
+		if (desired[i].Sid != "" && desired[i].Sid != current[i].Sid) || desired[i].Effect != current[i].Effect ||
+			!equalStrings(desired[i].Actions, current[i].Actions) || !equalStrings(desired[i].Resources, current[i].Resources) ||
+			!equalStrings(desired[i].Conditions, current[i].Conditions) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func equalMembershipRules(a *MembershipRule, b *MembershipRule) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func equalTimes(a *time.Time, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
@@ -0,0 +1,600 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tecsisa/foulkon/database"
+)
+
+// Current state of org1 returned by the repository mocks: policies policy1 and policy2, and groups
+// group1 and group2 with members user1 and user2 and policy1 attached
+func setCurrentOrganizationState(testRepo *TestRepo) {
+	testRepo.ArgsOut[GetPoliciesFilteredMethod][0] = []Policy{
+		{
+			ID:   "PolicyID1",
+			Name: "policy1",
+			Org:  "org1",
+			Path: "/path/",
+			Statements: &[]Statement{
+				{
+					Effect:    "allow",
+					Actions:   []string{USER_ACTION_GET_USER},
+					Resources: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+				},
+			},
+		},
+		{
+			ID:         "PolicyID2",
+			Name:       "policy2",
+			Org:        "org1",
+			Path:       "/path/",
+			Statements: &[]Statement{},
+		},
+	}
+	testRepo.ArgsOut[GetGroupsFilteredMethod][0] = []Group{
+		{
+			ID:   "GroupID1",
+			Name: "group1",
+			Org:  "org1",
+			Path: "/path/",
+		},
+		{
+			ID:   "GroupID2",
+			Name: "group2",
+			Org:  "org1",
+			Path: "/path/",
+		},
+	}
+	testRepo.ArgsOut[GetGroupUserRelationsMethod][0] = []GroupUserRelation{
+		{
+			User: &User{
+				ID:         "UserID1",
+				ExternalID: "user1",
+			},
+		},
+		{
+			User: &User{
+				ID:         "UserID2",
+				ExternalID: "user2",
+			},
+		},
+	}
+	testRepo.ArgsOut[GetGroupPolicyRelationsMethod][0] = []GroupPolicyRelation{
+		{
+			Policy: &Policy{
+				ID:   "PolicyID1",
+				Name: "policy1",
+			},
+		},
+	}
+}
+
+func TestAuthAPI_PlanOrganization(t *testing.T) {
+	expiresAt := time.Now().UTC().Add(time.Hour)
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		org         string
+		document    *OrganizationDocument
+		// Expected result
+		expectedResponse *Plan
+		wantError        error
+		// Manager Errors
+		getPoliciesFilteredErr error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org: "org1",
+			document: &OrganizationDocument{
+				Version: ORGANIZATION_DOCUMENT_VERSION,
+				Policies: []DocumentPolicy{
+					{
+						Name: "policy1",
+						Path: "/new/",
+						Statements: []Statement{
+							{
+								Effect:    "allow",
+								Actions:   []string{USER_ACTION_GET_USER},
+								Resources: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+							},
+						},
+					},
+					{
+						Name: "policy3",
+						Path: "/path/",
+						Statements: []Statement{
+							{
+								Effect:    "allow",
+								Actions:   []string{USER_ACTION_GET_USER},
+								Resources: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+							},
+						},
+					},
+				},
+				Groups: []DocumentGroup{
+					{
+						Name: "group1",
+						Path: "/path/",
+						Members: []DocumentMember{
+							{
+								ExternalID: "user1",
+								ExpiresAt:  &expiresAt,
+							},
+							{
+								ExternalID: "user3",
+							},
+						},
+						Policies: []DocumentAttachment{
+							{
+								Policy: "policy3",
+							},
+						},
+					},
+					{
+						Name: "group3",
+						Path: "/path/",
+						Members: []DocumentMember{
+							{
+								ExternalID: "user1",
+							},
+						},
+						Policies: []DocumentAttachment{
+							{
+								Policy: "policy1",
+							},
+						},
+					},
+				},
+			},
+			expectedResponse: &Plan{
+				Org: "org1",
+				Changes: []PlanChange{
+					{Action: PLAN_ACTION_UPDATE, Resource: PLAN_RESOURCE_POLICY, Name: "policy1"},
+					{Action: PLAN_ACTION_CREATE, Resource: PLAN_RESOURCE_POLICY, Name: "policy3"},
+					{Action: PLAN_ACTION_CREATE, Resource: PLAN_RESOURCE_GROUP, Name: "group3"},
+					{Action: PLAN_ACTION_DELETE, Resource: PLAN_RESOURCE_MEMBER, Name: "user2", Group: "group1"},
+					{Action: PLAN_ACTION_UPDATE, Resource: PLAN_RESOURCE_MEMBER, Name: "user1", Group: "group1"},
+					{Action: PLAN_ACTION_CREATE, Resource: PLAN_RESOURCE_MEMBER, Name: "user3", Group: "group1"},
+					{Action: PLAN_ACTION_DELETE, Resource: PLAN_RESOURCE_ATTACHMENT, Name: "policy1", Group: "group1"},
+					{Action: PLAN_ACTION_CREATE, Resource: PLAN_RESOURCE_ATTACHMENT, Name: "policy3", Group: "group1"},
+					{Action: PLAN_ACTION_CREATE, Resource: PLAN_RESOURCE_MEMBER, Name: "user1", Group: "group3"},
+					{Action: PLAN_ACTION_CREATE, Resource: PLAN_RESOURCE_ATTACHMENT, Name: "policy1", Group: "group3"},
+					{Action: PLAN_ACTION_DELETE, Resource: PLAN_RESOURCE_GROUP, Name: "group2"},
+					{Action: PLAN_ACTION_DELETE, Resource: PLAN_RESOURCE_POLICY, Name: "policy2"},
+				},
+			},
+		},
+		"OkCaseNoChanges": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org: "org1",
+			document: &OrganizationDocument{
+				Version: ORGANIZATION_DOCUMENT_VERSION,
+				Policies: []DocumentPolicy{
+					{
+						Name: "policy1",
+						Path: "/path/",
+						Statements: []Statement{
+							{
+								Effect:    "allow",
+								Actions:   []string{USER_ACTION_GET_USER},
+								Resources: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+							},
+						},
+						Tags: map[string]string{},
+					},
+					{
+						Name:       "policy2",
+						Path:       "/path/",
+						Statements: []Statement{},
+					},
+				},
+				Groups: []DocumentGroup{
+					{
+						Name: "group1",
+						Path: "/path/",
+						Members: []DocumentMember{
+							{
+								ExternalID: "user2",
+							},
+							{
+								ExternalID: "user1",
+							},
+						},
+						Policies: []DocumentAttachment{
+							{
+								Policy: "policy1",
+							},
+						},
+					},
+					{
+						Name: "group2",
+						Path: "/path/",
+						Members: []DocumentMember{
+							{
+								ExternalID: "user1",
+							},
+							{
+								ExternalID: "user2",
+							},
+						},
+						Policies: []DocumentAttachment{
+							{
+								Policy: "policy1",
+							},
+						},
+					},
+				},
+			},
+			expectedResponse: &Plan{
+				Org:     "org1",
+				Changes: []PlanChange{},
+			},
+		},
+		"OkCaseOwners": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org: "org1",
+			document: &OrganizationDocument{
+				Version: ORGANIZATION_DOCUMENT_VERSION,
+				Policies: []DocumentPolicy{
+					{
+						Name: "policy1",
+						Path: "/path/",
+						Statements: []Statement{
+							{
+								Effect:    "allow",
+								Actions:   []string{USER_ACTION_GET_USER},
+								Resources: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+							},
+						},
+					},
+				},
+				Groups: []DocumentGroup{
+					{
+						Name: "group1",
+						Path: "/path/",
+						Members: []DocumentMember{
+							{
+								ExternalID: "user1",
+							},
+							{
+								ExternalID: "user2",
+							},
+						},
+						Owners: []string{"user3"},
+						Policies: []DocumentAttachment{
+							{
+								Policy: "policy1",
+							},
+						},
+					},
+				},
+			},
+			expectedResponse: &Plan{
+				Org: "org1",
+				Changes: []PlanChange{
+					{Action: PLAN_ACTION_CREATE, Resource: PLAN_RESOURCE_OWNER, Name: "user3", Group: "group1"},
+					{Action: PLAN_ACTION_DELETE, Resource: PLAN_RESOURCE_GROUP, Name: "group2"},
+					{Action: PLAN_ACTION_DELETE, Resource: PLAN_RESOURCE_POLICY, Name: "policy2"},
+				},
+			},
+		},
+		"OkCaseMembershipRule": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org: "org1",
+			document: &OrganizationDocument{
+				Version: ORGANIZATION_DOCUMENT_VERSION,
+				Policies: []DocumentPolicy{
+					{
+						Name: "policy1",
+						Path: "/path/",
+						Statements: []Statement{
+							{
+								Effect:    "allow",
+								Actions:   []string{USER_ACTION_GET_USER},
+								Resources: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+							},
+						},
+					},
+				},
+				Groups: []DocumentGroup{
+					{
+						Name: "group1",
+						Path: "/path/",
+						MembershipRule: &MembershipRule{
+							PathPrefix: "/path/",
+						},
+						Policies: []DocumentAttachment{
+							{
+								Policy: "policy1",
+							},
+						},
+					},
+				},
+			},
+			expectedResponse: &Plan{
+				Org: "org1",
+				Changes: []PlanChange{
+					{Action: PLAN_ACTION_UPDATE, Resource: PLAN_RESOURCE_GROUP, Name: "group1"},
+					{Action: PLAN_ACTION_DELETE, Resource: PLAN_RESOURCE_MEMBER, Name: "user1", Group: "group1"},
+					{Action: PLAN_ACTION_DELETE, Resource: PLAN_RESOURCE_MEMBER, Name: "user2", Group: "group1"},
+					{Action: PLAN_ACTION_DELETE, Resource: PLAN_RESOURCE_GROUP, Name: "group2"},
+					{Action: PLAN_ACTION_DELETE, Resource: PLAN_RESOURCE_POLICY, Name: "policy2"},
+				},
+			},
+		},
+		"ErrorCaseNotAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			org: "org1",
+			document: &OrganizationDocument{
+				Version: ORGANIZATION_DOCUMENT_VERSION,
+			},
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to manage organization documents",
+			},
+		},
+		"ErrorCaseAttachedPolicyNotInDocument": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org: "org1",
+			document: &OrganizationDocument{
+				Version: ORGANIZATION_DOCUMENT_VERSION,
+				Groups: []DocumentGroup{
+					{
+						Name: "group1",
+						Path: "/path/",
+						Policies: []DocumentAttachment{
+							{
+								Policy: "policy1",
+							},
+						},
+					},
+				},
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: policy policy1 attached to group group1 isn't in the document",
+			},
+		},
+		"ErrorCaseGetPoliciesDBError": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org: "org1",
+			document: &OrganizationDocument{
+				Version: ORGANIZATION_DOCUMENT_VERSION,
+			},
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getPoliciesFilteredErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		setCurrentOrganizationState(testRepo)
+		testRepo.ArgsOut[GetPoliciesFilteredMethod][2] = testcase.getPoliciesFilteredErr
+		plan, err := testAPI.PlanOrganization(testcase.requestInfo, testcase.org, testcase.document)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, plan)
+	}
+}
+
+func TestAuthAPI_ApplyOrganization(t *testing.T) {
+	document := &OrganizationDocument{
+		Version: ORGANIZATION_DOCUMENT_VERSION,
+		Policies: []DocumentPolicy{
+			{
+				Name: "policy1",
+				Path: "/new/",
+				Statements: []Statement{
+					{
+						Effect:    "allow",
+						Actions:   []string{USER_ACTION_GET_USER},
+						Resources: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+					},
+				},
+			},
+		},
+		Groups: []DocumentGroup{
+			{
+				Name: "group1",
+				Path: "/path/",
+				Members: []DocumentMember{
+					{
+						ExternalID: "user1",
+					},
+					{
+						ExternalID: "user3",
+					},
+				},
+				Policies: []DocumentAttachment{
+					{
+						Policy: "policy1",
+					},
+				},
+			},
+		},
+	}
+	changes := []PlanChange{
+		{Action: PLAN_ACTION_UPDATE, Resource: PLAN_RESOURCE_POLICY, Name: "policy1"},
+		{Action: PLAN_ACTION_DELETE, Resource: PLAN_RESOURCE_MEMBER, Name: "user2", Group: "group1"},
+		{Action: PLAN_ACTION_CREATE, Resource: PLAN_RESOURCE_MEMBER, Name: "user3", Group: "group1"},
+		{Action: PLAN_ACTION_DELETE, Resource: PLAN_RESOURCE_GROUP, Name: "group2"},
+		{Action: PLAN_ACTION_DELETE, Resource: PLAN_RESOURCE_POLICY, Name: "policy2"},
+	}
+
+	testcases := map[string]struct {
+		// API method args
+		requestInfo   RequestInfo
+		org           string
+		document      *OrganizationDocument
+		confirmedPlan *Plan
+		// Expected result
+		expectedResponse *Plan
+		wantError        error
+		// Manager Results
+		getGroupByNameResult *Group
+		// Manager Errors
+		getUserByExternalIDErr error
+		runInTransactionErr    error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:      "org1",
+			document: document,
+			confirmedPlan: &Plan{
+				Org:     "org1",
+				Changes: changes,
+			},
+			expectedResponse: &Plan{
+				Org:     "org1",
+				Changes: changes,
+			},
+		},
+		"ErrorCaseNoPlan": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:      "org1",
+			document: document,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: plan is required",
+			},
+		},
+		"ErrorCasePlanOutdated": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:      "org1",
+			document: document,
+			confirmedPlan: &Plan{
+				Org:     "org1",
+				Changes: changes[:1],
+			},
+			wantError: &Error{
+				Code:    PLAN_OUTDATED,
+				Message: "Unable to apply plan, organization org1 changed since it was planned",
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:      "org1",
+			document: document,
+			confirmedPlan: &Plan{
+				Org:     "org1",
+				Changes: changes,
+			},
+			wantError: &Error{
+				Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "Unable to add member to group group1, user with externalId user2 not found",
+			},
+			getUserByExternalIDErr: &database.Error{
+				Code: database.USER_NOT_FOUND,
+			},
+		},
+		"ErrorCaseAddMemberToGroupWithMembershipRule": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:      "org1",
+			document: document,
+			confirmedPlan: &Plan{
+				Org:     "org1",
+				Changes: changes,
+			},
+			wantError: &Error{
+				Code:    GROUP_HAS_MEMBERSHIP_RULE,
+				Message: "Members of group with org org1 and name group1 are defined by its membership rule",
+			},
+			getGroupByNameResult: &Group{
+				ID:   "GroupID1",
+				Name: "group1",
+				Org:  "org1",
+				MembershipRule: &MembershipRule{
+					PathPrefix: "/path/",
+				},
+			},
+		},
+		"ErrorCaseTransactionError": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:      "org1",
+			document: document,
+			confirmedPlan: &Plan{
+				Org:     "org1",
+				Changes: changes,
+			},
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			runInTransactionErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		setCurrentOrganizationState(testRepo)
+		testRepo.ArgsOut[GetPolicyByNameMethod][0] = &Policy{
+			ID:   "PolicyID1",
+			Name: "policy1",
+		}
+		testRepo.ArgsOut[GetGroupByNameMethod][0] = &Group{
+			ID:   "GroupID1",
+			Name: "group1",
+		}
+		if testcase.getGroupByNameResult != nil {
+			testRepo.ArgsOut[GetGroupByNameMethod][0] = testcase.getGroupByNameResult
+		}
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = &User{
+			ID:         "UserID",
+			ExternalID: "user",
+		}
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDErr
+		testRepo.ArgsOut[RunInTransactionMethod][0] = testcase.runInTransactionErr
+		plan, err := testAPI.ApplyOrganization(testcase.requestInfo, testcase.org, testcase.document, testcase.confirmedPlan)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, plan)
+	}
+}
@@ -12,13 +12,16 @@ import (
 
 // Policy domain
 type Policy struct {
-	ID         string       `json:"id, omitempty"`
-	Name       string       `json:"name, omitempty"`
-	Path       string       `json:"path, omitempty"`
-	Org        string       `json:"org, omitempty"`
-	Urn        string       `json:"urn, omitempty"`
-	CreateAt   time.Time    `json:"createAt, omitempty"`
-	Statements *[]Statement `json:"statements, omitempty"`
+	ID         string            `json:"id, omitempty"`
+	Name       string            `json:"name, omitempty"`
+	Path       string            `json:"path, omitempty"`
+	Org        string            `json:"org, omitempty"`
+	Urn        string            `json:"urn, omitempty"`
+	CreateAt   time.Time         `json:"createAt, omitempty"`
+	Statements *[]Statement      `json:"statements, omitempty"`
+	Tags       map[string]string `json:"tags, omitempty"`
+	// Revision of the stored policy, updates fail if it changed after the policy was retrieved
+	Revision int64 `json:"-"`
 }
 
 func (p Policy) String() string {
@@ -30,6 +33,10 @@ func (p Policy) GetUrn() string {
 	return p.Urn
 }
 
+func (p Policy) GetTags() map[string]string {
+	return p.Tags
+}
+
 // Policy identifier to retrieve them from DB
 type PolicyIdentity struct {
 	Org  string `json:"org, omitempty"`
@@ -37,15 +44,26 @@ type PolicyIdentity struct {
 }
 
 type Statement struct {
+	// Statement identifier, unique inside the policy and kept across policy updates
+	Sid       string   `json:"sid, omitempty"`
 	Effect    string   `json:"effect, omitempty"`
 	Actions   []string `json:"actions, omitempty"`
 	Resources []string `json:"resources, omitempty"`
+	// Optional tag comparisons that must be true for the statement to apply, e.g. "team == resource.team"
+	Conditions []string `json:"conditions, omitempty"`
 }
 
 func (s Statement) String() string {
 	return fmt.Sprintf("[effect: %v, actions: %v, resources: %v]", s.Effect, s.Actions, s.Resources)
 }
 
+// Policy statement whose resources reference a user, group or policy URN
+type StatementReference struct {
+	Org        string `json:"org, omitempty"`
+	PolicyName string `json:"policyName, omitempty"`
+	Sid        string `json:"sid, omitempty"`
+}
+
 // POLICY API IMPLEMENTATION
 
 func (api AuthAPI) AddPolicy(requestInfo RequestInfo, name string, path string, org string, statements []Statement) (*Policy, error) {
@@ -78,6 +96,16 @@ func (api AuthAPI) AddPolicy(requestInfo RequestInfo, name string, path string,
 		}
 
 	}
+	if api.ValidateActions {
+		if err := api.areRegisteredActions(statements); err != nil {
+			return nil, err
+		}
+	}
+	if api.ValidateResources {
+		if err := api.areRegisteredResources(statements); err != nil {
+			return nil, err
+		}
+	}
 
 	policy := createPolicy(name, path, org, &statements)
 
@@ -94,6 +122,11 @@ func (api AuthAPI) AddPolicy(requestInfo RequestInfo, name string, path string,
 		}
 	}
 
+	// Check if organization exists
+	if err := api.checkOrganizationExists(org); err != nil {
+		return nil, err
+	}
+
 	// Check if policy already exists
 	_, err = api.PolicyRepo.GetPolicyByName(org, name)
 
@@ -105,7 +138,15 @@ func (api AuthAPI) AddPolicy(requestInfo RequestInfo, name string, path string,
 		// Policy doesn't exist in DB
 		case database.POLICY_NOT_FOUND:
 			// Create policy
-			createdPolicy, err := api.PolicyRepo.AddPolicy(policy)
+			var createdPolicy *Policy
+			err := api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+				var err error
+				createdPolicy, err = repo.AddPolicy(policy)
+				if err != nil {
+					return err
+				}
+				return api.auditOperation(repo, requestInfo, POLICY_ACTION_CREATE_POLICY, createdPolicy.Urn, nil, createdPolicy)
+			})
 
 			// Check if there is an unexpected error in DB
 			if err != nil {
@@ -187,32 +228,20 @@ func (api AuthAPI) GetPolicyByName(requestInfo RequestInfo, org string, policyNa
 	}
 }
 
-func (api AuthAPI) ListPolicies(requestInfo RequestInfo, org string, pathPrefix string) ([]PolicyIdentity, error) {
+func (api AuthAPI) ListPolicies(requestInfo RequestInfo, filter *Filter) ([]Policy, int, error) {
 	// Validate fields
-	if len(org) > 0 && !IsValidOrg(org) {
-		return nil, &Error{
-			Code:    INVALID_PARAMETER_ERROR,
-			Message: fmt.Sprintf("Invalid parameter: org %v", org),
-		}
-	}
-	if len(pathPrefix) > 0 && !IsValidPath(pathPrefix) {
-		return nil, &Error{
-			Code:    INVALID_PARAMETER_ERROR,
-			Message: fmt.Sprintf("Invalid parameter: PathPrefix %v", pathPrefix),
-		}
-	}
-	if len(pathPrefix) == 0 {
-		pathPrefix = "/"
+	if err := validateFilter(filter); err != nil {
+		return nil, 0, err
 	}
 
 	// Call repo to retrieve the policies
-	policies, err := api.PolicyRepo.GetPoliciesFiltered(org, pathPrefix)
+	policies, total, err := api.PolicyRepo.GetPoliciesFiltered(filter)
 
 	// Error handling
 	if err != nil {
 		//Transform to DB error
 		dbError := err.(*database.Error)
-		return nil, &Error{
+		return nil, 0, &Error{
 			Code:    UNKNOWN_API_ERROR,
 			Message: dbError.Message,
 		}
@@ -220,38 +249,30 @@ func (api AuthAPI) ListPolicies(requestInfo RequestInfo, org string, pathPrefix
 
 	// Check restrictions to list
 	var urnPrefix string
-	if len(org) == 0 {
+	if len(filter.Org) == 0 {
 		urnPrefix = "*"
 	} else {
-		urnPrefix = GetUrnPrefix(org, RESOURCE_POLICY, pathPrefix)
+		urnPrefix = GetUrnPrefix(filter.Org, RESOURCE_POLICY, filter.PathPrefix)
 	}
 	policiesFiltered, err := api.GetAuthorizedPolicies(requestInfo, urnPrefix, POLICY_ACTION_LIST_POLICIES, policies)
 	if err != nil {
-		return nil, err
-	}
-
-	policyIDs := []PolicyIdentity{}
-	for _, p := range policiesFiltered {
-		policyIDs = append(policyIDs, PolicyIdentity{
-			Org:  p.Org,
-			Name: p.Name,
-		})
+		return nil, 0, err
 	}
 
-	return policyIDs, nil
+	return policiesFiltered, total, nil
 }
 
 func (api AuthAPI) UpdatePolicy(requestInfo RequestInfo, org string, policyName string, newName string, newPath string,
-	newStatements []Statement) (*Policy, error) {
+	newStatements []Statement) (*Policy, []StatementReference, error) {
 	// Validate fields
 	if !IsValidName(newName) {
-		return nil, &Error{
+		return nil, nil, &Error{
 			Code:    INVALID_PARAMETER_ERROR,
 			Message: fmt.Sprintf("Invalid parameter: new name %v", newName),
 		}
 	}
 	if !IsValidPath(newPath) {
-		return nil, &Error{
+		return nil, nil, &Error{
 			Code:    INVALID_PARAMETER_ERROR,
 			Message: fmt.Sprintf("Invalid parameter: new path %v", newPath),
 		}
@@ -260,45 +281,60 @@ func (api AuthAPI) UpdatePolicy(requestInfo RequestInfo, org string, policyName
 	err := AreValidStatements(&newStatements)
 	if err != nil {
 		apiError := err.(*Error)
-		return nil, &Error{
+		return nil, nil, &Error{
 			Code:    INVALID_PARAMETER_ERROR,
 			Message: apiError.Message,
 		}
 
 	}
+	if api.ValidateActions {
+		if err := api.areRegisteredActions(newStatements); err != nil {
+			return nil, nil, err
+		}
+	}
+	if api.ValidateResources {
+		if err := api.areRegisteredResources(newStatements); err != nil {
+			return nil, nil, err
+		}
+	}
 
 	// Call repo to retrieve the policy
 	policyDB, err := api.GetPolicyByName(requestInfo, org, policyName)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Check restrictions
 	policiesFiltered, err := api.GetAuthorizedPolicies(requestInfo, policyDB.Urn, POLICY_ACTION_UPDATE_POLICY, []Policy{*policyDB})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if len(policiesFiltered) < 1 {
-		return nil, &Error{
+		return nil, nil, &Error{
 			Code: UNAUTHORIZED_RESOURCES_ERROR,
 			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
 				requestInfo.Identifier, policyDB.Urn),
 		}
 	}
 
+	// Check that the policy hasn't been modified since the client retrieved it
+	if err := checkIfMatch(requestInfo, *policyDB); err != nil {
+		return nil, nil, err
+	}
+
 	// Check if policy with "newName" exists
 	targetPolicy, err := api.GetPolicyByName(requestInfo, org, newName)
 
 	if err == nil && targetPolicy.ID != policyDB.ID {
 		// Policy already exists
-		return nil, &Error{
+		return nil, nil, &Error{
 			Code:    POLICY_ALREADY_EXIST,
 			Message: fmt.Sprintf("Policy name: %v already exists", newName),
 		}
 	}
 	if err != nil {
 		if apiError := err.(*Error); apiError.Code == UNAUTHORIZED_RESOURCES_ERROR || apiError.Code == UNKNOWN_API_ERROR {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
@@ -308,31 +344,62 @@ func (api AuthAPI) UpdatePolicy(requestInfo RequestInfo, org string, policyName
 	// Check restrictions
 	policiesFiltered, err = api.GetAuthorizedPolicies(requestInfo, policyToUpdate.Urn, POLICY_ACTION_UPDATE_POLICY, []Policy{policyToUpdate})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if len(policiesFiltered) < 1 {
-		return nil, &Error{
+		return nil, nil, &Error{
 			Code: UNAUTHORIZED_RESOURCES_ERROR,
 			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
 				requestInfo.Identifier, policyToUpdate.Urn),
 		}
 	}
 
+	// Retrieve other policies with statements that reference the policy when its URN changes. References
+	// in the policy itself are in the new statements
+	referencingPolicies, err := api.getReferencingPolicies(requestInfo, policyDB.Urn, policyToUpdate.Urn, requestInfo.RewriteReferences)
+	if err != nil {
+		return nil, nil, err
+	}
+	policies := []Policy{}
+	for _, referencingPolicy := range referencingPolicies {
+		if referencingPolicy.ID != policyDB.ID {
+			policies = append(policies, referencingPolicy)
+		}
+	}
+	if requestInfo.RewriteReferences && policyDB.Urn != policyToUpdate.Urn {
+		newStatements = replaceStatementsResource(newStatements, policyDB.Urn, policyToUpdate.Urn)
+	}
+
 	// Update policy
-	policy, err := api.PolicyRepo.UpdatePolicy(*policyDB, newName, newPath, policyToUpdate.Urn, newStatements)
+	var policy *Policy
+	err = api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		var err error
+		policy, err = repo.UpdatePolicy(*policyDB, newName, newPath, policyToUpdate.Urn, newStatements)
+		if err != nil {
+			return err
+		}
+		if err := api.auditOperation(repo, requestInfo, POLICY_ACTION_UPDATE_POLICY, policy.Urn, policyDB, policy); err != nil {
+			return err
+		}
+		if !requestInfo.RewriteReferences {
+			return nil
+		}
+		return api.rewriteReferencingPolicies(repo, requestInfo, policies, policyDB.Urn, policyToUpdate.Urn)
+	})
 
 	// Check unexpected DB error
 	if err != nil {
-		//Transform to DB error
-		dbError := err.(*database.Error)
-		return nil, &Error{
-			Code:    UNKNOWN_API_ERROR,
-			Message: dbError.Message,
-		}
+		return nil, nil, toRevisionAPIError(err)
 	}
 
-	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Policy updated from %+v to %+v", policyDB, policy))
-	return policy, nil
+	references := []StatementReference{}
+	if !requestInfo.RewriteReferences && policyDB.Urn != policyToUpdate.Urn {
+		references = getStatementReferences(append(policies, *policy), policyDB.Urn)
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Policy updated from %+v to %+v with %v referencing policies, rewritten: %v",
+		policyDB, policy, len(policies), requestInfo.RewriteReferences))
+	return policy, references, nil
 }
 
 func (api AuthAPI) RemovePolicy(requestInfo RequestInfo, org string, name string) error {
@@ -356,35 +423,44 @@ func (api AuthAPI) RemovePolicy(requestInfo RequestInfo, org string, name string
 		}
 	}
 
-	err = api.PolicyRepo.RemovePolicy(policy.ID)
-	if err != nil {
-		//Transform to DB error
-		dbError := err.(*database.Error)
-		return &Error{
-			Code:    UNKNOWN_API_ERROR,
-			Message: dbError.Message,
+	// Check that the policy hasn't been modified since the client retrieved it
+	if err := checkIfMatch(requestInfo, *policy); err != nil {
+		return err
+	}
+
+	err = api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		if err := repo.RemovePolicy(*policy); err != nil {
+			return err
 		}
+		return api.auditOperation(repo, requestInfo, POLICY_ACTION_DELETE_POLICY, policy.Urn, policy, nil)
+	})
+	if err != nil {
+		return toRevisionAPIError(err)
 	}
 
 	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Policy deleted %+v", policy))
 	return nil
 }
 
-func (api AuthAPI) ListAttachedGroups(requestInfo RequestInfo, org string, name string) ([]string, error) {
+func (api AuthAPI) ListAttachedGroups(requestInfo RequestInfo, org string, name string, filter *Filter) ([]string, int, error) {
+	// Validate filter
+	if err := validateFilter(filter); err != nil {
+		return nil, 0, err
+	}
 
 	// Call repo to retrieve the policy
 	policy, err := api.GetPolicyByName(requestInfo, org, name)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	// Check restrictions
 	policiesFiltered, err := api.GetAuthorizedPolicies(requestInfo, policy.Urn, POLICY_ACTION_LIST_ATTACHED_GROUPS, []Policy{*policy})
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	if len(policiesFiltered) < 1 {
-		return nil, &Error{
+		return nil, 0, &Error{
 			Code: UNAUTHORIZED_RESOURCES_ERROR,
 			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
 				requestInfo.Identifier, policy.Urn),
@@ -392,13 +468,13 @@ func (api AuthAPI) ListAttachedGroups(requestInfo RequestInfo, org string, name
 	}
 
 	// Call repo to retrieve the attached groups
-	groups, err := api.PolicyRepo.GetAttachedGroups(policy.ID)
+	groups, total, err := api.PolicyRepo.GetAttachedGroups(policy.ID, filter)
 
 	// Error handling
 	if err != nil {
 		//Transform to DB error
 		dbError := err.(*database.Error)
-		return nil, &Error{
+		return nil, 0, &Error{
 			Code:    UNKNOWN_API_ERROR,
 			Message: dbError.Message,
 		}
@@ -409,13 +485,14 @@ func (api AuthAPI) ListAttachedGroups(requestInfo RequestInfo, org string, name
 		groupNames = append(groupNames, g.Name)
 	}
 
-	return groupNames, nil
+	return groupNames, total, nil
 }
 
 // PRIVATE HELPER METHODS
 
 func createPolicy(name string, path string, org string, statements *[]Statement) Policy {
 	urn := CreateUrn(org, RESOURCE_POLICY, path, name)
+	assignStatementSids(*statements)
 	policy := Policy{
 		ID:         uuid.NewV4().String(),
 		Name:       name,
@@ -428,3 +505,135 @@ func createPolicy(name string, path string, org string, statements *[]Statement)
 
 	return policy
 }
+
+// Update a policy previously retrieved by the request. When the request isn't conditional, the policy
+// must not change since it was retrieved so concurrent changes aren't overwritten
+func (api AuthAPI) updateRetrievedPolicy(requestInfo RequestInfo, policy *Policy, newName string, newPath string,
+	newStatements []Statement) (*Policy, error) {
+	if requestInfo.IfMatch == "" {
+		requestInfo.IfMatch = ETag(*policy)
+	}
+	updated, _, err := api.UpdatePolicy(requestInfo, policy.Org, policy.Name, newName, newPath, newStatements)
+	return updated, err
+}
+
+// Give an identifier to the statements that don't have one
+func assignStatementSids(statements []Statement) {
+	for i := range statements {
+		if statements[i].Sid == "" {
+			statements[i].Sid = uuid.NewV4().String()
+		}
+	}
+}
+
+// Retrieve policies with statements that reference the old urn of a renamed or moved resource. There aren't
+// any when the urn doesn't change
+func (api AuthAPI) getReferencingPolicies(requestInfo RequestInfo, urn string, newUrn string, rewrite bool) ([]Policy, error) {
+	if urn == newUrn {
+		return []Policy{}, nil
+	}
+	policies, err := api.PolicyRepo.GetPoliciesReferencingUrn(urn)
+	if err != nil {
+		return nil, toUnknownAPIError(err)
+	}
+	return api.getAuthorizedReferencingPolicies(requestInfo, policies, rewrite)
+}
+
+// Filter policies that reference a renamed or moved resource. Rewriting needs permission to update all of them,
+// otherwise only policies that the user is allowed to get are reported
+func (api AuthAPI) getAuthorizedReferencingPolicies(requestInfo RequestInfo, policies []Policy, rewrite bool) ([]Policy, error) {
+	if len(policies) == 0 {
+		return policies, nil
+	}
+	if !rewrite {
+		policiesFiltered, err := api.GetAuthorizedPolicies(requestInfo, "*", POLICY_ACTION_GET_POLICY, policies)
+		if err != nil {
+			if apiError := err.(*Error); apiError.Code == UNAUTHORIZED_RESOURCES_ERROR {
+				return []Policy{}, nil
+			}
+			return nil, err
+		}
+		return policiesFiltered, nil
+	}
+	policiesFiltered, err := api.GetAuthorizedPolicies(requestInfo, "*", POLICY_ACTION_UPDATE_POLICY, policies)
+	if err != nil {
+		return nil, err
+	}
+	allowed := make(map[string]bool, len(policiesFiltered))
+	for _, policy := range policiesFiltered {
+		allowed[policy.ID] = true
+	}
+	for _, policy := range policies {
+		if !allowed[policy.ID] {
+			return nil, &Error{
+				Code: UNAUTHORIZED_RESOURCES_ERROR,
+				Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+					requestInfo.Identifier, policy.Urn),
+			}
+		}
+	}
+	return policies, nil
+}
+
+// Change the urn by the new one in the statements of the policies, inside the transaction of the resource update.
+// Each rewritten policy is audited as a policy update
+func (api AuthAPI) rewriteReferencingPolicies(repo Repo, requestInfo RequestInfo, policies []Policy, urn string, newUrn string) error {
+	for i := range policies {
+		statements := replaceStatementsResource(*policies[i].Statements, urn, newUrn)
+		updated, err := repo.UpdatePolicy(policies[i], policies[i].Name, policies[i].Path, policies[i].Urn, statements)
+		if err != nil {
+			return err
+		}
+		if err := api.auditOperation(repo, requestInfo, POLICY_ACTION_UPDATE_POLICY, updated.Urn, &policies[i], updated); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Statements of the policies that reference the urn
+func getStatementReferences(policies []Policy, urn string) []StatementReference {
+	references := []StatementReference{}
+	for _, policy := range policies {
+		if policy.Statements == nil {
+			continue
+		}
+		for _, statement := range *policy.Statements {
+			if isResourceReferenced(statement, urn) {
+				references = append(references, StatementReference{
+					Org:        policy.Org,
+					PolicyName: policy.Name,
+					Sid:        statement.Sid,
+				})
+			}
+		}
+	}
+	return references
+}
+
+// Check if the urn is one of the statement resources. Prefixes that match it aren't references
+func isResourceReferenced(statement Statement, urn string) bool {
+	for _, resource := range statement.Resources {
+		if resource == urn {
+			return true
+		}
+	}
+	return false
+}
+
+// Copy of statements with the urn replaced by the new one in their resources
+func replaceStatementsResource(statements []Statement, urn string, newUrn string) []Statement {
+	replaced := make([]Statement, 0, len(statements))
+	for _, statement := range statements {
+		resources := make([]string, 0, len(statement.Resources))
+		for _, resource := range statement.Resources {
+			if resource == urn {
+				resource = newUrn
+			}
+			resources = append(resources, resource)
+		}
+		statement.Resources = resources
+		replaced = append(replaced, statement)
+	}
+	return replaced
+}
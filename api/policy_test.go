@@ -1,8 +1,10 @@
 package api
 
 import (
+	"fmt"
 	"testing"
 
+	"github.com/kylelemons/godebug/pretty"
 	"github.com/tecsisa/foulkon/database"
 )
 
@@ -22,9 +24,38 @@ func TestAuthAPI_AddPolicy(t *testing.T) {
 		getPolicyByNameMethodResult *Policy
 		wantError                   error
 
-		getPolicyByNameMethodErr error
-		addPolicyMethodErr       error
+		getPolicyByNameMethodErr       error
+		addPolicyMethodErr             error
+		getOrganizationByNameMethodErr error
 	}{
+		"ErrorCaseOrganizationNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:        "123",
+			policyName: "test",
+			path:       "/path/",
+			statements: []Statement{
+				{
+					Effect: "allow",
+					Actions: []string{
+						USER_ACTION_GET_USER,
+					},
+					Resources: []string{
+						GetUrnPrefix("", RESOURCE_USER, "/path/"),
+					},
+				},
+			},
+			getOrganizationByNameMethodErr: &database.Error{
+				Code:    database.ORGANIZATION_NOT_FOUND,
+				Message: "Organization with name 123 not found",
+			},
+			wantError: &Error{
+				Code:    ORGANIZATION_BY_NAME_NOT_FOUND,
+				Message: "Organization with name 123 not found",
+			},
+		},
 		"OKCase": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
@@ -433,6 +464,7 @@ func TestAuthAPI_AddPolicy(t *testing.T) {
 		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
 		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
+		testRepo.ArgsOut[GetOrganizationByNameMethod][1] = testcase.getOrganizationByNameMethodErr
 		policy, err := testAPI.AddPolicy(testcase.requestInfo, testcase.policyName, testcase.path, testcase.org, testcase.statements)
 		checkMethodResponse(t, x, testcase.wantError, err, testcase.addPolicyMethodResult, policy)
 	}
@@ -668,10 +700,11 @@ func TestAuthAPI_GetPolicyByName(t *testing.T) {
 func TestAuthAPI_ListPolicies(t *testing.T) {
 	testcases := map[string]struct {
 		requestInfo RequestInfo
-		org         string
-		pathPrefix  string
+		filter      *Filter
 
-		expectedPolicies []PolicyIdentity
+		expectedPolicies []Policy
+		expectedTotal    int
+		expectedFilter   *Filter
 
 		getGroupsByUserIDResult   []Group
 		getAttachedPoliciesResult []Policy
@@ -679,6 +712,7 @@ func TestAuthAPI_ListPolicies(t *testing.T) {
 		getUserByExternalIDErr    error
 
 		getPoliciesFilteredMethodResult []Policy
+		getPoliciesFilteredMethodTotal  int
 		getPoliciesFilteredMethodErr    error
 
 		wantError error
@@ -688,16 +722,44 @@ func TestAuthAPI_ListPolicies(t *testing.T) {
 				Identifier: "123456",
 				Admin:      true,
 			},
-			org:        "123",
-			pathPrefix: "/",
-			expectedPolicies: []PolicyIdentity{
+			filter:        &Filter{Org: "123", PathPrefix: "/"},
+			expectedTotal: 2,
+			expectedPolicies: []Policy{
 				{
-					Org:  "example",
+					ID:   "PolicyAllowed",
 					Name: "policyAllowed",
+					Org:  "example",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyAllowed"),
+					Statements: &[]Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								POLICY_ACTION_GET_POLICY,
+							},
+							Resources: []string{
+								GetUrnPrefix("example", RESOURCE_POLICY, "/path/"),
+							},
+						},
+					},
 				},
 				{
-					Org:  "example",
+					ID:   "PolicyDenied",
 					Name: "policyDenied",
+					Org:  "example",
+					Path: "/path2/",
+					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path2/", "policyDenied"),
+					Statements: &[]Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								POLICY_ACTION_GET_POLICY,
+							},
+							Resources: []string{
+								GetUrnPrefix("example", RESOURCE_POLICY, "/path/"),
+							},
+						},
+					},
 				},
 			},
 			getPoliciesFilteredMethodResult: []Policy{
@@ -738,18 +800,64 @@ func TestAuthAPI_ListPolicies(t *testing.T) {
 					},
 				},
 			},
+			getPoliciesFilteredMethodTotal: 2,
+		},
+		"OkCaseAdminFilterByTags": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter:         &Filter{Org: "example", PathPrefix: "/", Tags: map[string]string{"env": "prod"}},
+			expectedTotal:  1,
+			expectedFilter: &Filter{Org: "example", PathPrefix: "/", Tags: map[string]string{"env": "prod"}, Limit: DEFAULT_LIMIT_SIZE},
+			expectedPolicies: []Policy{
+				{
+					ID:         "PolicyDenied",
+					Name:       "policyDenied",
+					Org:        "example",
+					Path:       "/path2/",
+					Urn:        CreateUrn("example", RESOURCE_POLICY, "/path2/", "policyDenied"),
+					Statements: &[]Statement{},
+					Tags:       map[string]string{"env": "prod"},
+				},
+			},
+			getPoliciesFilteredMethodResult: []Policy{
+				{
+					ID:         "PolicyDenied",
+					Name:       "policyDenied",
+					Org:        "example",
+					Path:       "/path2/",
+					Urn:        CreateUrn("example", RESOURCE_POLICY, "/path2/", "policyDenied"),
+					Statements: &[]Statement{},
+					Tags:       map[string]string{"env": "prod"},
+				},
+			},
+			getPoliciesFilteredMethodTotal: 1,
 		},
 		"OkCaseAdminNoOrg": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      true,
 			},
-			org:        "",
-			pathPrefix: "/",
-			expectedPolicies: []PolicyIdentity{
+			filter: &Filter{PathPrefix: "/"},
+			expectedPolicies: []Policy{
 				{
-					Org:  "example",
+					ID:   "PolicyAllowed",
 					Name: "policyAllowed",
+					Org:  "example",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyAllowed"),
+					Statements: &[]Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								POLICY_ACTION_GET_POLICY,
+							},
+							Resources: []string{
+								GetUrnPrefix("example", RESOURCE_POLICY, "/path/"),
+							},
+						},
+					},
 				},
 			},
 			getPoliciesFilteredMethodResult: []Policy{
@@ -778,11 +886,25 @@ func TestAuthAPI_ListPolicies(t *testing.T) {
 				Identifier: "123456",
 				Admin:      false,
 			},
-			org: "example",
-			expectedPolicies: []PolicyIdentity{
+			filter: &Filter{Org: "example"},
+			expectedPolicies: []Policy{
 				{
-					Org:  "example",
+					ID:   "PolicyAllowed",
 					Name: "policyAllowed",
+					Org:  "example",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyAllowed"),
+					Statements: &[]Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								POLICY_ACTION_GET_POLICY,
+							},
+							Resources: []string{
+								GetUrnPrefix("example", RESOURCE_POLICY, "/path/"),
+							},
+						},
+					},
 				},
 			},
 			getPoliciesFilteredMethodResult: []Policy{
@@ -872,8 +994,7 @@ func TestAuthAPI_ListPolicies(t *testing.T) {
 				Identifier: "123456",
 				Admin:      true,
 			},
-			org:        "123",
-			pathPrefix: "/path*/",
+			filter: &Filter{Org: "123", PathPrefix: "/path*/"},
 			wantError: &Error{
 				Code:    INVALID_PARAMETER_ERROR,
 				Message: "Invalid parameter: PathPrefix /path*/",
@@ -884,8 +1005,7 @@ func TestAuthAPI_ListPolicies(t *testing.T) {
 				Identifier: "123456",
 				Admin:      true,
 			},
-			org:        "!#$$%**^",
-			pathPrefix: "/",
+			filter: &Filter{Org: "!#$$%**^", PathPrefix: "/"},
 			wantError: &Error{
 				Code:    INVALID_PARAMETER_ERROR,
 				Message: "Invalid parameter: org !#$$%**^",
@@ -896,8 +1016,7 @@ func TestAuthAPI_ListPolicies(t *testing.T) {
 				Identifier: "123456",
 				Admin:      true,
 			},
-			org:        "",
-			pathPrefix: "/path/",
+			filter: &Filter{PathPrefix: "/path/"},
 			getPoliciesFilteredMethodErr: &database.Error{
 				Code: database.INTERNAL_ERROR,
 			},
@@ -910,8 +1029,7 @@ func TestAuthAPI_ListPolicies(t *testing.T) {
 				Identifier: "123456",
 				Admin:      false,
 			},
-			org:        "123",
-			pathPrefix: "/path/",
+			filter: &Filter{Org: "123", PathPrefix: "/path/"},
 			getPoliciesFilteredMethodResult: []Policy{
 				{
 					ID:   "POLICY-USER-ID",
@@ -940,6 +1058,17 @@ func TestAuthAPI_ListPolicies(t *testing.T) {
 				Message: "Authenticated user with externalId 123456 not found. Unable to retrieve permissions.",
 			},
 		},
+		"ErrorCaseInvalidLimit": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter: &Filter{Limit: 1001},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Limit 1001, max limit allowed: 1000",
+			},
+		},
 	}
 
 	for x, testcase := range testcases {
@@ -948,17 +1077,91 @@ func TestAuthAPI_ListPolicies(t *testing.T) {
 		testAPI := makeTestAPI(testRepo)
 
 		testRepo.ArgsOut[GetPoliciesFilteredMethod][0] = testcase.getPoliciesFilteredMethodResult
-		testRepo.ArgsOut[GetPoliciesFilteredMethod][1] = testcase.getPoliciesFilteredMethodErr
+		testRepo.ArgsOut[GetPoliciesFilteredMethod][2] = testcase.getPoliciesFilteredMethodErr
 		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
 		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDErr
 		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
-		policies, err := testAPI.ListPolicies(testcase.requestInfo, testcase.org, testcase.pathPrefix)
+		testRepo.ArgsOut[GetPoliciesFilteredMethod][1] = testcase.getPoliciesFilteredMethodTotal
+		filter := testcase.filter
+		if filter == nil {
+			filter = &Filter{}
+		}
+		policies, total, err := testAPI.ListPolicies(testcase.requestInfo, filter)
 		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedPolicies, policies)
+		if testcase.wantError == nil {
+			if total != testcase.expectedTotal {
+				t.Errorf("Test %v failed. Received different total (wanted:%v / received:%v)", x, testcase.expectedTotal, total)
+			}
+			// Check filter received by repo
+			if testcase.expectedFilter != nil {
+				if diff := pretty.Compare(testRepo.ArgsIn[GetPoliciesFilteredMethod][0], testcase.expectedFilter); diff != "" {
+					t.Errorf("Test %v failed. Received different filter (received/wanted) %v", x, diff)
+				}
+			}
+		}
 	}
 }
 
 func TestAuthAPI_UpdatePolicy(t *testing.T) {
+	// Policy modified by another request since it was retrieved
+	modifiedPolicy := &Policy{
+		ID:   "test1",
+		Name: "test",
+		Org:  "123",
+		Path: "/path/",
+		Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
+		Statements: &[]Statement{
+			{
+				Effect: "deny",
+				Actions: []string{
+					USER_ACTION_GET_USER,
+				},
+				Resources: []string{
+					GetUrnPrefix("", RESOURCE_USER, "/path/"),
+				},
+			},
+		},
+	}
+	// Policy with a statement that references its own URN
+	selfReferencingPolicy := &Policy{
+		ID:   "test1",
+		Name: "test",
+		Org:  "123",
+		Path: "/path/",
+		Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
+		Statements: &[]Statement{
+			{
+				Sid:    "self",
+				Effect: "allow",
+				Actions: []string{
+					POLICY_ACTION_GET_POLICY,
+				},
+				Resources: []string{
+					CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
+				},
+			},
+		},
+	}
+	referencingPolicy := Policy{
+		ID:   "other1",
+		Name: "other",
+		Org:  "123",
+		Path: "/path/",
+		Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "other"),
+		Statements: &[]Statement{
+			{
+				Sid:    "policies",
+				Effect: "allow",
+				Actions: []string{
+					POLICY_ACTION_UPDATE_POLICY,
+				},
+				Resources: []string{
+					CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
+				},
+			},
+		},
+	}
 	testcases := map[string]struct {
 		requestInfo   RequestInfo
 		org           string
@@ -974,8 +1177,12 @@ func TestAuthAPI_UpdatePolicy(t *testing.T) {
 		getAttachedPoliciesResult   []Policy
 		getUserByExternalIDResult   *User
 		updatePolicyMethodResult    *Policy
+		// Policies returned by the reference index
+		getPoliciesReferencingUrnResult []Policy
 
-		wantError error
+		wantError          error
+		expectedReferences []StatementReference
+		expectedStatements []Statement
 
 		getPolicyByNameMethodErr error
 		getUserByExternalIDErr   error
@@ -1052,6 +1259,112 @@ func TestAuthAPI_UpdatePolicy(t *testing.T) {
 				},
 			},
 		},
+		"OKCaseDanglingReferences": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:                         "123",
+			policyName:                  "test",
+			newPolicyName:               "test2",
+			newPath:                     "/path/",
+			newStatements:               *selfReferencingPolicy.Statements,
+			getPolicyByNameMethodResult: selfReferencingPolicy,
+			updatePolicyMethodResult: &Policy{
+				ID:         "test1",
+				Name:       "test2",
+				Org:        "123",
+				Path:       "/path/",
+				Urn:        CreateUrn("123", RESOURCE_POLICY, "/path/", "test2"),
+				Statements: selfReferencingPolicy.Statements,
+			},
+			getPoliciesReferencingUrnResult: []Policy{referencingPolicy, *selfReferencingPolicy},
+			expectedReferences: []StatementReference{
+				{
+					Org:        "123",
+					PolicyName: "other",
+					Sid:        "policies",
+				},
+				{
+					Org:        "123",
+					PolicyName: "test2",
+					Sid:        "self",
+				},
+			},
+		},
+		"OKCaseRewriteSelfReferences": {
+			requestInfo: RequestInfo{
+				Identifier:        "123456",
+				Admin:             true,
+				RewriteReferences: true,
+			},
+			org:           "123",
+			policyName:    "test",
+			newPolicyName: "test2",
+			newPath:       "/path/",
+			newStatements: []Statement{
+				{
+					Sid:    "self",
+					Effect: "deny",
+					Actions: []string{
+						POLICY_ACTION_GET_POLICY,
+					},
+					Resources: []string{
+						CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
+					},
+				},
+			},
+			getPolicyByNameMethodResult: selfReferencingPolicy,
+			updatePolicyMethodResult: &Policy{
+				ID:   "test1",
+				Name: "test2",
+				Org:  "123",
+				Path: "/path/",
+				Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "test2"),
+			},
+			getPoliciesReferencingUrnResult: []Policy{*selfReferencingPolicy},
+			expectedReferences:              []StatementReference{},
+			expectedStatements: []Statement{
+				{
+					Sid:    "self",
+					Effect: "deny",
+					Actions: []string{
+						POLICY_ACTION_GET_POLICY,
+					},
+					Resources: []string{
+						CreateUrn("123", RESOURCE_POLICY, "/path/", "test2"),
+					},
+				},
+			},
+		},
+		"ErrorCasePolicyModified": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+				IfMatch:    "\"outdated\"",
+			},
+			org:           "123",
+			policyName:    "test",
+			newPolicyName: "test2",
+			newPath:       "/path2/",
+			newStatements: []Statement{
+				{
+					Effect: "allow",
+					Actions: []string{
+						USER_ACTION_GET_USER,
+					},
+					Resources: []string{
+						GetUrnPrefix("", RESOURCE_USER, "/path2/"),
+					},
+				},
+			},
+			getPolicyByNameMethodResult: modifiedPolicy,
+			wantError: &Error{
+				Code: PRECONDITION_FAILED_ERROR,
+				Message: fmt.Sprintf("Resource %v has been modified, entity tag \"outdated\" doesn't match current entity tag %v",
+					modifiedPolicy.Urn, ETag(*modifiedPolicy)),
+			},
+		},
 		"ErrorCaseInvalidPolicyName": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
@@ -2274,8 +2587,18 @@ func TestAuthAPI_UpdatePolicy(t *testing.T) {
 		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDErr
 		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
-		policy, err := testAPI.UpdatePolicy(testcase.requestInfo, testcase.org, testcase.policyName, testcase.newPolicyName, testcase.newPath, testcase.newStatements)
+		testRepo.ArgsOut[GetPoliciesReferencingUrnMethod][0] = testcase.getPoliciesReferencingUrnResult
+		policy, references, err := testAPI.UpdatePolicy(testcase.requestInfo, testcase.org, testcase.policyName, testcase.newPolicyName, testcase.newPath, testcase.newStatements)
 		checkMethodResponse(t, x, testcase.wantError, err, testcase.updatePolicyMethodResult, policy)
+		if testcase.expectedReferences == nil {
+			continue
+		}
+		if diff := pretty.Compare(references, testcase.expectedReferences); diff != "" {
+			t.Errorf("Test %v failed. Received different references (received/wanted) %v", x, diff)
+		}
+		if diff := pretty.Compare(testRepo.ArgsIn[UpdatePolicyMethod][4], testcase.expectedStatements); testcase.expectedStatements != nil && diff != "" {
+			t.Errorf("Test %v failed. Received different statements (received/wanted) %v", x, diff)
+		}
 	}
 }
 
@@ -2547,13 +2870,16 @@ func TestAuthAPI_ListAttachedGroups(t *testing.T) {
 		requestInfo    RequestInfo
 		org            string
 		policyName     string
+		filter         *Filter
 		expectedGroups []string
+		expectedTotal  int
 
 		getGroupsByUserIDResult   []Group
 		getAttachedPoliciesResult []Policy
 		getUserByExternalIDResult *User
 
 		getAttachedGroupsResult []Group
+		getAttachedGroupsTotal  int
 		getAttachedGroupsErr    error
 
 		getPolicyByNameMethodResult *Policy
@@ -2598,7 +2924,9 @@ func TestAuthAPI_ListAttachedGroups(t *testing.T) {
 					Name: "group2",
 				},
 			},
-			expectedGroups: []string{"group1", "group2"},
+			expectedGroups:         []string{"group1", "group2"},
+			expectedTotal:          2,
+			getAttachedGroupsTotal: 2,
 		},
 		"ErrorCaseInvalidName": {
 			requestInfo: RequestInfo{
@@ -2802,6 +3130,17 @@ func TestAuthAPI_ListAttachedGroups(t *testing.T) {
 				Code: database.INTERNAL_ERROR,
 			},
 		},
+		"ErrorCaseInvalidFilter": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter: &Filter{Limit: -1},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Limit -1, max limit allowed: 1000",
+			},
+		},
 	}
 
 	for x, testcase := range testcases {
@@ -2815,8 +3154,16 @@ func TestAuthAPI_ListAttachedGroups(t *testing.T) {
 		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
 		testRepo.ArgsOut[GetAttachedGroupsMethod][0] = testcase.getAttachedGroupsResult
-		testRepo.ArgsOut[GetAttachedGroupsMethod][1] = testcase.getAttachedGroupsErr
-		groups, err := testAPI.ListAttachedGroups(testcase.requestInfo, testcase.org, testcase.policyName)
+		testRepo.ArgsOut[GetAttachedGroupsMethod][1] = testcase.getAttachedGroupsTotal
+		testRepo.ArgsOut[GetAttachedGroupsMethod][2] = testcase.getAttachedGroupsErr
+		filter := testcase.filter
+		if filter == nil {
+			filter = &Filter{}
+		}
+		groups, total, err := testAPI.ListAttachedGroups(testcase.requestInfo, testcase.org, testcase.policyName, filter)
 		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedGroups, groups)
+		if testcase.wantError == nil && total != testcase.expectedTotal {
+			t.Errorf("Test %v failed. Received different total (wanted:%v / received:%v)", x, testcase.expectedTotal, total)
+		}
 	}
 }
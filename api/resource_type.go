@@ -0,0 +1,419 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/satori/go.uuid"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// TYPE DEFINITIONS
+
+// Resource type domain. Its URN template describes the URNs of the resources of a service,
+// e.g. urn:billing:{org}:invoice{path}{id}
+type ResourceType struct {
+	ID          string    `json:"id, omitempty"`
+	Namespace   string    `json:"namespace, omitempty"`
+	Name        string    `json:"name, omitempty"`
+	Description string    `json:"description, omitempty"`
+	UrnTemplate string    `json:"urnTemplate, omitempty"`
+	CreateAt    time.Time `json:"createAt, omitempty"`
+	// Built-in resource types can't be modified
+	BuiltIn bool `json:"builtIn, omitempty"`
+}
+
+func (r ResourceType) String() string {
+	return fmt.Sprintf("[id: %v, namespace: %v, name: %v, description: %v, urnTemplate: %v, builtIn: %v]",
+		r.ID, r.Namespace, r.Name, r.Description, r.UrnTemplate, r.BuiltIn)
+}
+
+// Piece of a parsed URN template: a literal text or a placeholder
+type urnTemplateToken struct {
+	literal string
+	param   string
+}
+
+var rUrnTemplateParam, _ = regexp.Compile(`^\w+$`)
+var rUrnTemplateLiteral, _ = regexp.Compile(`^[\w+\-_.@:/]+$`)
+
+// Built-in resource types of the IAM namespace
+var iamResourceTypes = []ResourceType{
+	createBuiltInResourceType(RESOURCE_USER, "Foulkon user", "urn:iws:iam::user{path}{name}"),
+	createBuiltInResourceType(RESOURCE_GROUP, "Foulkon group", "urn:iws:iam:{org}:group{path}{name}"),
+	createBuiltInResourceType(RESOURCE_POLICY, "Foulkon policy", "urn:iws:iam:{org}:policy{path}{name}"),
+	createBuiltInResourceType(RESOURCE_ORGANIZATION, "Foulkon organization", "urn:iws:iam::organization/{name}"),
+}
+
+// RESOURCE TYPE API IMPLEMENTATION
+
+func (api AuthAPI) AddResourceType(requestInfo RequestInfo, namespace string, name string, description string,
+	urnTemplate string) (*ResourceType, error) {
+	// Validate fields
+	if !IsValidName(namespace) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: namespace %v", namespace),
+		}
+	}
+	if namespace == IAM_NAMESPACE {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: namespace %v is built-in and can't be modified", namespace),
+		}
+	}
+	if !IsValidName(name) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: name %v", name),
+		}
+	}
+	if len(description) > MAX_DESCRIPTION_LENGTH {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: description %v", description),
+		}
+	}
+	if _, err := parseUrnTemplate(urnTemplate); err != nil {
+		return nil, err
+	}
+
+	// Check restrictions
+	if err := checkActionRegistryAdmin(requestInfo); err != nil {
+		return nil, err
+	}
+
+	// Namespace must be registered
+	if _, err := api.getNamespace(namespace); err != nil {
+		return nil, err
+	}
+
+	// Check if resource type already exists
+	_, err := api.ResourceTypeRepo.GetResourceTypeByName(namespace, name)
+
+	// Check if resource type could be retrieved
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		// Resource type doesn't exist in DB
+		case database.RESOURCE_TYPE_NOT_FOUND:
+			resourceType := ResourceType{
+				ID:          uuid.NewV4().String(),
+				Namespace:   namespace,
+				Name:        name,
+				Description: description,
+				UrnTemplate: urnTemplate,
+				CreateAt:    time.Now().UTC(),
+			}
+			var createdResourceType *ResourceType
+			err := api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+				var err error
+				createdResourceType, err = repo.AddResourceType(resourceType)
+				if err != nil {
+					return err
+				}
+				return api.auditOperation(repo, requestInfo, AUDIT_ACTION_CREATE_RESOURCE_TYPE,
+					getRegistryUrn(AUDIT_ENTITY_RESOURCE_TYPE, createdResourceType.Namespace, createdResourceType.Name), nil, createdResourceType)
+			})
+
+			// Check if there is an unexpected error in DB
+			if err != nil {
+				//Transform to DB error
+				dbError := err.(*database.Error)
+				return nil, &Error{
+					Code:    UNKNOWN_API_ERROR,
+					Message: dbError.Message,
+				}
+			}
+
+			LogOperation(api.Logger, requestInfo, fmt.Sprintf("Resource type created %+v", createdResourceType))
+			return createdResourceType, nil
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	} else { // Fail if resource type exists
+		return nil, &Error{
+			Code:    RESOURCE_TYPE_ALREADY_EXIST,
+			Message: fmt.Sprintf("Unable to create resource type, resource type %v:%v already exist", namespace, name),
+		}
+	}
+}
+
+func (api AuthAPI) GetResourceType(requestInfo RequestInfo, namespace string, name string) (*ResourceType, error) {
+	// Validate fields
+	if !IsValidName(namespace) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: namespace %v", namespace),
+		}
+	}
+	if !IsValidName(name) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: name %v", name),
+		}
+	}
+
+	if namespace == IAM_NAMESPACE {
+		for _, resourceType := range iamResourceTypes {
+			if resourceType.Name == name {
+				builtInResourceType := resourceType
+				return &builtInResourceType, nil
+			}
+		}
+		return nil, &Error{
+			Code:    RESOURCE_TYPE_NOT_FOUND,
+			Message: fmt.Sprintf("Resource type %v:%v not found", namespace, name),
+		}
+	}
+
+	// Call repo to retrieve the resource type
+	resourceType, err := api.ResourceTypeRepo.GetResourceTypeByName(namespace, name)
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.RESOURCE_TYPE_NOT_FOUND:
+			return nil, &Error{
+				Code:    RESOURCE_TYPE_NOT_FOUND,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	return resourceType, nil
+}
+
+func (api AuthAPI) ListResourceTypes(requestInfo RequestInfo, namespace string) ([]ResourceType, error) {
+	// Validate fields
+	if !IsValidName(namespace) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: namespace %v", namespace),
+		}
+	}
+
+	// Namespace must be registered
+	if _, err := api.getNamespace(namespace); err != nil {
+		return nil, err
+	}
+
+	if namespace == IAM_NAMESPACE {
+		return iamResourceTypes, nil
+	}
+
+	return api.getResourceTypes(namespace)
+}
+
+func (api AuthAPI) RemoveResourceType(requestInfo RequestInfo, namespace string, name string) error {
+	// Validate fields
+	if namespace == IAM_NAMESPACE {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: namespace %v is built-in and can't be modified", namespace),
+		}
+	}
+
+	// Check restrictions
+	if err := checkActionRegistryAdmin(requestInfo); err != nil {
+		return err
+	}
+
+	// Retrieve resource type
+	resourceType, err := api.GetResourceType(requestInfo, namespace, name)
+	if err != nil {
+		return err
+	}
+
+	// Remove resource type
+	err = api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		if err := repo.RemoveResourceType(resourceType.ID); err != nil {
+			return err
+		}
+		return api.auditOperation(repo, requestInfo, AUDIT_ACTION_DELETE_RESOURCE_TYPE,
+			getRegistryUrn(AUDIT_ENTITY_RESOURCE_TYPE, resourceType.Namespace, resourceType.Name), resourceType, nil)
+	})
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Resource type deleted %+v", resourceType))
+	return nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Check that every resource in statements conforms to a registered resource type
+func (api AuthAPI) areRegisteredResources(statements []Statement) error {
+	resources := []string{}
+	for _, statement := range statements {
+		resources = append(resources, statement.Resources...)
+	}
+	return api.checkRegisteredResources(resources)
+}
+
+// Check that every resource, which may end with a wildcard, conforms to a registered resource type
+func (api AuthAPI) checkRegisteredResources(resources []string) error {
+	resourceTypes, err := api.getResourceTypes("")
+	if err != nil {
+		return err
+	}
+	templates := [][]urnTemplateToken{}
+	for _, resourceType := range append(append([]ResourceType{}, iamResourceTypes...), resourceTypes...) {
+		// Templates are validated before being stored
+		if tokens, err := parseUrnTemplate(resourceType.UrnTemplate); err == nil {
+			templates = append(templates, tokens)
+		}
+	}
+
+	for _, resource := range resources {
+		prefix := strings.TrimSuffix(resource, "*")
+		isPrefix := prefix != resource
+		conforms := false
+		for _, tokens := range templates {
+			if matchUrnTemplate(tokens, prefix, isPrefix) {
+				conforms = true
+				break
+			}
+		}
+		if !conforms {
+			return &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Unknown resource: %v - It doesn't conform to any registered resource type", resource),
+			}
+		}
+	}
+	return nil
+}
+
+// Retrieve resource types stored for a namespace, or all of them if namespace is empty
+func (api AuthAPI) getResourceTypes(namespace string) ([]ResourceType, error) {
+	resourceTypes, err := api.ResourceTypeRepo.GetResourceTypesFiltered(namespace)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+	return resourceTypes, nil
+}
+
+// Split URN template in literals and placeholders, checking its syntax
+func parseUrnTemplate(urnTemplate string) ([]urnTemplateToken, error) {
+	errFunc := func() error {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: urnTemplate %v", urnTemplate),
+		}
+	}
+	if !strings.HasPrefix(urnTemplate, "urn:") || len(urnTemplate) > MAX_URN_TEMPLATE_LENGTH {
+		return nil, errFunc()
+	}
+
+	tokens := []urnTemplateToken{}
+	remaining := urnTemplate
+	for len(remaining) > 0 {
+		start := strings.Index(remaining, "{")
+		if start < 0 {
+			start = len(remaining)
+		}
+		if start > 0 {
+			if !rUrnTemplateLiteral.MatchString(remaining[:start]) {
+				return nil, errFunc()
+			}
+			tokens = append(tokens, urnTemplateToken{literal: remaining[:start]})
+			remaining = remaining[start:]
+			continue
+		}
+		end := strings.Index(remaining, "}")
+		if end < 0 || !rUrnTemplateParam.MatchString(remaining[1:end]) {
+			return nil, errFunc()
+		}
+		tokens = append(tokens, urnTemplateToken{param: remaining[1:end]})
+		remaining = remaining[end+1:]
+	}
+	return tokens, nil
+}
+
+// Check if resource matches URN template. If isPrefix is true, resource only needs to be
+// the beginning of a matching URN
+func matchUrnTemplate(tokens []urnTemplateToken, resource string, isPrefix bool) bool {
+	if isPrefix && resource == "" {
+		return true
+	}
+	if len(tokens) == 0 {
+		return resource == ""
+	}
+
+	token := tokens[0]
+	switch token.param {
+	case "": // Literal
+		if isPrefix && len(resource) < len(token.literal) {
+			return strings.HasPrefix(token.literal, resource)
+		}
+		if !strings.HasPrefix(resource, token.literal) {
+			return false
+		}
+		return matchUrnTemplate(tokens[1:], resource[len(token.literal):], isPrefix)
+	case URN_TEMPLATE_PATH_PARAM: // Path like /, /path/ or /path/subpath/
+		if resource == "" || resource[0] != '/' {
+			return false
+		}
+		for i := 0; i < len(resource); i++ {
+			if resource[i] == '/' {
+				if i > 0 && resource[i-1] == '/' {
+					return false
+				}
+				if matchUrnTemplate(tokens[1:], resource[i+1:], isPrefix) {
+					return true
+				}
+			} else if !isUrnWordChar(resource[i]) {
+				return false
+			}
+		}
+		// Resource ends in the middle of the path
+		return isPrefix
+	default: // Word
+		for i := 0; i < len(resource) && isUrnWordChar(resource[i]); i++ {
+			if matchUrnTemplate(tokens[1:], resource[i+1:], isPrefix) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func isUrnWordChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') ||
+		strings.IndexByte("_+-.@", c) >= 0
+}
+
+func createBuiltInResourceType(name string, description string, urnTemplate string) ResourceType {
+	return ResourceType{
+		Namespace:   IAM_NAMESPACE,
+		Name:        name,
+		Description: description,
+		UrnTemplate: urnTemplate,
+		BuiltIn:     true,
+	}
+}
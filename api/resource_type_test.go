@@ -0,0 +1,453 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestAuthAPI_AddResourceType(t *testing.T) {
+	now := time.Date(2016, 9, 1, 10, 0, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		namespace   string
+		name        string
+		urnTemplate string
+		// Expected results
+		expectedResourceType *ResourceType
+		wantError            error
+		// Manager Errors
+		getNamespaceByNameMethodErr    error
+		getResourceTypeByNameMethodErr error
+		addResourceTypeMethodErr       error
+	}{
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			namespace:   "billing",
+			name:        "invoice",
+			urnTemplate: "urn:billing:{org}:invoice{path}{id}",
+			expectedResourceType: &ResourceType{
+				ID:          "RT-ID",
+				Namespace:   "billing",
+				Name:        "invoice",
+				UrnTemplate: "urn:billing:{org}:invoice{path}{id}",
+				CreateAt:    now,
+			},
+			getResourceTypeByNameMethodErr: &database.Error{
+				Code: database.RESOURCE_TYPE_NOT_FOUND,
+			},
+		},
+		"ErrorCaseBuiltInNamespace": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			namespace:   IAM_NAMESPACE,
+			name:        "invoice",
+			urnTemplate: "urn:billing:{org}:invoice{path}{id}",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: namespace iam is built-in and can't be modified",
+			},
+		},
+		"ErrorCaseInvalidUrnTemplatePrefix": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			namespace:   "billing",
+			name:        "invoice",
+			urnTemplate: "billing:{org}:invoice{path}{id}",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: urnTemplate billing:{org}:invoice{path}{id}",
+			},
+		},
+		"ErrorCaseInvalidUrnTemplatePlaceholder": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			namespace:   "billing",
+			name:        "invoice",
+			urnTemplate: "urn:billing:{org:invoice",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: urnTemplate urn:billing:{org:invoice",
+			},
+		},
+		"ErrorCaseInvalidUrnTemplateWildcard": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			namespace:   "billing",
+			name:        "invoice",
+			urnTemplate: "urn:billing:*",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: urnTemplate urn:billing:*",
+			},
+		},
+		"ErrorCaseNotAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			namespace:   "billing",
+			name:        "invoice",
+			urnTemplate: "urn:billing:{org}:invoice{path}{id}",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to modify the action registry",
+			},
+		},
+		"ErrorCaseNamespaceNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			namespace:   "billing",
+			name:        "invoice",
+			urnTemplate: "urn:billing:{org}:invoice{path}{id}",
+			wantError: &Error{
+				Code:    NAMESPACE_NOT_FOUND,
+				Message: "Namespace with name billing not found",
+			},
+			getNamespaceByNameMethodErr: &database.Error{
+				Code:    database.NAMESPACE_NOT_FOUND,
+				Message: "Namespace with name billing not found",
+			},
+		},
+		"ErrorCaseResourceTypeAlreadyExist": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			namespace:   "billing",
+			name:        "invoice",
+			urnTemplate: "urn:billing:{org}:invoice{path}{id}",
+			wantError: &Error{
+				Code:    RESOURCE_TYPE_ALREADY_EXIST,
+				Message: "Unable to create resource type, resource type billing:invoice already exist",
+			},
+		},
+		"ErrorCaseAddResourceTypeDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			namespace:   "billing",
+			name:        "invoice",
+			urnTemplate: "urn:billing:{org}:invoice{path}{id}",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getResourceTypeByNameMethodErr: &database.Error{
+				Code: database.RESOURCE_TYPE_NOT_FOUND,
+			},
+			addResourceTypeMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetNamespaceByNameMethod][0] = &Namespace{Name: test.namespace}
+		testRepo.ArgsOut[GetNamespaceByNameMethod][1] = test.getNamespaceByNameMethodErr
+		testRepo.ArgsOut[GetResourceTypeByNameMethod][1] = test.getResourceTypeByNameMethodErr
+		testRepo.ArgsOut[AddResourceTypeMethod][0] = test.expectedResourceType
+		testRepo.ArgsOut[AddResourceTypeMethod][1] = test.addResourceTypeMethodErr
+
+		resourceType, err := testAPI.AddResourceType(test.requestInfo, test.namespace, test.name, "", test.urnTemplate)
+		checkMethodResponse(t, n, test.wantError, err, test.expectedResourceType, resourceType)
+	}
+}
+
+func TestAuthAPI_GetResourceType(t *testing.T) {
+	testcases := map[string]struct {
+		// API Method args
+		namespace string
+		name      string
+		// Expected results
+		expectedResourceType *ResourceType
+		wantError            error
+		// Manager Results
+		getResourceTypeByNameResult *ResourceType
+		// Manager Errors
+		getResourceTypeByNameMethodErr error
+	}{
+		"OKCase": {
+			namespace: "billing",
+			name:      "invoice",
+			expectedResourceType: &ResourceType{
+				ID:          "RT-ID",
+				Namespace:   "billing",
+				Name:        "invoice",
+				UrnTemplate: "urn:billing:{org}:invoice{path}{id}",
+			},
+			getResourceTypeByNameResult: &ResourceType{
+				ID:          "RT-ID",
+				Namespace:   "billing",
+				Name:        "invoice",
+				UrnTemplate: "urn:billing:{org}:invoice{path}{id}",
+			},
+		},
+		"OKCaseBuiltIn": {
+			namespace: IAM_NAMESPACE,
+			name:      RESOURCE_GROUP,
+			expectedResourceType: &ResourceType{
+				Namespace:   IAM_NAMESPACE,
+				Name:        RESOURCE_GROUP,
+				Description: "Foulkon group",
+				UrnTemplate: "urn:iws:iam:{org}:group{path}{name}",
+				BuiltIn:     true,
+			},
+		},
+		"ErrorCaseResourceTypeNotFound": {
+			namespace: "billing",
+			name:      "invoice",
+			wantError: &Error{
+				Code:    RESOURCE_TYPE_NOT_FOUND,
+				Message: "Resource type billing:invoice not found",
+			},
+			getResourceTypeByNameMethodErr: &database.Error{
+				Code:    database.RESOURCE_TYPE_NOT_FOUND,
+				Message: "Resource type billing:invoice not found",
+			},
+		},
+		"ErrorCaseDBErr": {
+			namespace: "billing",
+			name:      "invoice",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getResourceTypeByNameMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetResourceTypeByNameMethod][0] = test.getResourceTypeByNameResult
+		testRepo.ArgsOut[GetResourceTypeByNameMethod][1] = test.getResourceTypeByNameMethodErr
+
+		resourceType, err := testAPI.GetResourceType(RequestInfo{Identifier: "123456"}, test.namespace, test.name)
+		checkMethodResponse(t, n, test.wantError, err, test.expectedResourceType, resourceType)
+	}
+}
+
+func TestAuthAPI_RemoveResourceType(t *testing.T) {
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		namespace   string
+		name        string
+		// Expected results
+		wantError error
+		// Manager Errors
+		getResourceTypeByNameMethodErr error
+		removeResourceTypeMethodErr    error
+	}{
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			namespace: "billing",
+			name:      "invoice",
+		},
+		"ErrorCaseBuiltIn": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			namespace: IAM_NAMESPACE,
+			name:      RESOURCE_USER,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: namespace iam is built-in and can't be modified",
+			},
+		},
+		"ErrorCaseNotAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			namespace: "billing",
+			name:      "invoice",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to modify the action registry",
+			},
+		},
+		"ErrorCaseResourceTypeNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			namespace: "billing",
+			name:      "invoice",
+			wantError: &Error{
+				Code:    RESOURCE_TYPE_NOT_FOUND,
+				Message: "Resource type billing:invoice not found",
+			},
+			getResourceTypeByNameMethodErr: &database.Error{
+				Code:    database.RESOURCE_TYPE_NOT_FOUND,
+				Message: "Resource type billing:invoice not found",
+			},
+		},
+		"ErrorCaseRemoveResourceTypeDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			namespace: "billing",
+			name:      "invoice",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			removeResourceTypeMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetResourceTypeByNameMethod][0] = &ResourceType{
+			ID:        "RT-ID",
+			Namespace: test.namespace,
+			Name:      test.name,
+		}
+		testRepo.ArgsOut[GetResourceTypeByNameMethod][1] = test.getResourceTypeByNameMethodErr
+		testRepo.ArgsOut[RemoveResourceTypeMethod][0] = test.removeResourceTypeMethodErr
+
+		err := testAPI.RemoveResourceType(test.requestInfo, test.namespace, test.name)
+		checkMethodResponse(t, n, test.wantError, err, nil, nil)
+		if test.wantError == nil && testRepo.ArgsIn[RemoveResourceTypeMethod][0] != "RT-ID" {
+			t.Errorf("Test %v failed. Received different resource type id %v", n, testRepo.ArgsIn[RemoveResourceTypeMethod][0])
+		}
+	}
+}
+
+func TestAuthAPI_checkRegisteredResources(t *testing.T) {
+	testcases := map[string]struct {
+		resources []string
+		// Expected results
+		wantError error
+		// Manager Results
+		getResourceTypesFilteredResult []ResourceType
+		// Manager Errors
+		getResourceTypesFilteredMethodErr error
+	}{
+		"OKCaseBuiltIn": {
+			resources: []string{
+				"urn:iws:iam::user/path/user1",
+				"urn:iws:iam:org1:group/group1",
+				"urn:iws:iam:org1:policy/path/subpath/policy1",
+				"urn:iws:iam:org1:group/path/*",
+				"urn:iws:iam:*",
+				"*",
+			},
+		},
+		"OKCaseRegistered": {
+			resources: []string{
+				"urn:billing:org1:invoice/2016/09/inv-42",
+				"urn:billing:org1:invoice/inv-42",
+				"urn:billing:org1:invoice/2016/*",
+				"urn:billing:org1:inv*",
+				"urn:bill*",
+			},
+			getResourceTypesFilteredResult: []ResourceType{
+				{
+					Namespace:   "billing",
+					Name:        "invoice",
+					UrnTemplate: "urn:billing:{org}:invoice{path}{id}",
+				},
+			},
+		},
+		"ErrorCaseWrongStructure": {
+			resources: []string{
+				"urn:billing:invoice/inv-42",
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Unknown resource: urn:billing:invoice/inv-42 - It doesn't conform to any registered resource type",
+			},
+			getResourceTypesFilteredResult: []ResourceType{
+				{
+					Namespace:   "billing",
+					Name:        "invoice",
+					UrnTemplate: "urn:billing:{org}:invoice{path}{id}",
+				},
+			},
+		},
+		"ErrorCaseWrongPrefix": {
+			resources: []string{
+				"urn:billing:org1:receipt/*",
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Unknown resource: urn:billing:org1:receipt/* - It doesn't conform to any registered resource type",
+			},
+			getResourceTypesFilteredResult: []ResourceType{
+				{
+					Namespace:   "billing",
+					Name:        "invoice",
+					UrnTemplate: "urn:billing:{org}:invoice{path}{id}",
+				},
+			},
+		},
+		"ErrorCaseUnregistered": {
+			resources: []string{
+				"urn:everything:*",
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Unknown resource: urn:everything:* - It doesn't conform to any registered resource type",
+			},
+		},
+		"ErrorCaseDBErr": {
+			resources: []string{
+				"urn:iws:iam:org1:group/group1",
+			},
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getResourceTypesFilteredMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetResourceTypesFilteredMethod][0] = test.getResourceTypesFilteredResult
+		testRepo.ArgsOut[GetResourceTypesFilteredMethod][1] = test.getResourceTypesFilteredMethodErr
+
+		err := testAPI.checkRegisteredResources(test.resources)
+		checkMethodResponse(t, n, test.wantError, err, nil, nil)
+	}
+}
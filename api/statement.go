@@ -0,0 +1,142 @@
+package api
+
+import (
+	"fmt"
+)
+
+// STATEMENT API IMPLEMENTATION
+
+func (api AuthAPI) GetPolicyStatement(requestInfo RequestInfo, org string, policyName string, sid string) (*Statement, error) {
+	if !IsValidSid(sid) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: sid %v", sid),
+		}
+	}
+
+	// Call repo to retrieve the policy
+	policy, err := api.GetPolicyByName(requestInfo, org, policyName)
+	if err != nil {
+		return nil, err
+	}
+
+	i, err := findStatement(policy, sid)
+	if err != nil {
+		return nil, err
+	}
+
+	return &(*policy.Statements)[i], nil
+}
+
+func (api AuthAPI) AddPolicyStatement(requestInfo RequestInfo, org string, policyName string, statement Statement) (*Statement, error) {
+	if statement.Sid != "" && !IsValidSid(statement.Sid) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: sid %v", statement.Sid),
+		}
+	}
+
+	// Call repo to retrieve the policy
+	policy, err := api.GetPolicyByName(requestInfo, org, policyName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if statement already exists
+	if statement.Sid != "" {
+		if _, err := findStatement(policy, statement.Sid); err == nil {
+			return nil, &Error{
+				Code:    STATEMENT_ALREADY_EXIST,
+				Message: fmt.Sprintf("Unable to add statement, statement with sid %v already exist in policy %v", statement.Sid, policyName),
+			}
+		}
+	}
+
+	statements := append(append([]Statement{}, *policy.Statements...), statement)
+	assignStatementSids(statements[len(statements)-1:])
+	statement = statements[len(statements)-1]
+	if _, err := api.updateRetrievedPolicy(requestInfo, policy, policy.Name, policy.Path, statements); err != nil {
+		return nil, err
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Statement %+v added to policy %v", statement, policy.Urn))
+	return &statement, nil
+}
+
+func (api AuthAPI) UpdatePolicyStatement(requestInfo RequestInfo, org string, policyName string, sid string, statement Statement) (*Statement, error) {
+	if !IsValidSid(sid) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: sid %v", sid),
+		}
+	}
+
+	// Call repo to retrieve the policy
+	policy, err := api.GetPolicyByName(requestInfo, org, policyName)
+	if err != nil {
+		return nil, err
+	}
+
+	i, err := findStatement(policy, sid)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replace statement keeping its sid and position
+	statement.Sid = sid
+	statements := append([]Statement{}, *policy.Statements...)
+	oldStatement := statements[i]
+	statements[i] = statement
+	if _, err := api.updateRetrievedPolicy(requestInfo, policy, policy.Name, policy.Path, statements); err != nil {
+		return nil, err
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Statement of policy %v updated from %+v to %+v", policy.Urn, oldStatement, statement))
+	return &statement, nil
+}
+
+func (api AuthAPI) RemovePolicyStatement(requestInfo RequestInfo, org string, policyName string, sid string) error {
+	if !IsValidSid(sid) {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: sid %v", sid),
+		}
+	}
+
+	// Call repo to retrieve the policy
+	policy, err := api.GetPolicyByName(requestInfo, org, policyName)
+	if err != nil {
+		return err
+	}
+
+	i, err := findStatement(policy, sid)
+	if err != nil {
+		return err
+	}
+
+	statements := append([]Statement{}, (*policy.Statements)[:i]...)
+	statements = append(statements, (*policy.Statements)[i+1:]...)
+	if _, err := api.updateRetrievedPolicy(requestInfo, policy, policy.Name, policy.Path, statements); err != nil {
+		return err
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Statement %v removed from policy %v", sid, policy.Urn))
+	return nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Return the position of the statement with given sid in the policy
+func findStatement(policy *Policy, sid string) (int, error) {
+	if policy.Statements != nil {
+		for i, statement := range *policy.Statements {
+			if statement.Sid == sid {
+				return i, nil
+			}
+		}
+	}
+	return -1, &Error{
+		Code:    STATEMENT_NOT_FOUND,
+		Message: fmt.Sprintf("Statement with sid %v not found in policy %v", sid, policy.Name),
+	}
+}
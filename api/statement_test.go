@@ -0,0 +1,523 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// Policy with two statements used by statement API tests
+func makeStatementsTestPolicy() *Policy {
+	return &Policy{
+		ID:   "test1",
+		Name: "test",
+		Org:  "123",
+		Path: "/path/",
+		Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
+		Statements: &[]Statement{
+			{
+				Sid:    "first",
+				Effect: "allow",
+				Actions: []string{
+					USER_ACTION_GET_USER,
+				},
+				Resources: []string{
+					GetUrnPrefix("", RESOURCE_USER, "/path/"),
+				},
+			},
+			{
+				Sid:    "second",
+				Effect: "deny",
+				Actions: []string{
+					USER_ACTION_DELETE_USER,
+				},
+				Resources: []string{
+					GetUrnPrefix("", RESOURCE_USER, "/path/"),
+				},
+			},
+		},
+	}
+}
+
+func TestAuthAPI_GetPolicyStatement(t *testing.T) {
+	testcases := map[string]struct {
+		requestInfo RequestInfo
+		org         string
+		policyName  string
+		sid         string
+
+		getPolicyByNameMethodResult *Policy
+		getPolicyByNameMethodErr    error
+
+		expectedResponse *Statement
+		wantError        error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:                         "123",
+			policyName:                  "test",
+			sid:                         "second",
+			getPolicyByNameMethodResult: makeStatementsTestPolicy(),
+			expectedResponse: &Statement{
+				Sid:    "second",
+				Effect: "deny",
+				Actions: []string{
+					USER_ACTION_DELETE_USER,
+				},
+				Resources: []string{
+					GetUrnPrefix("", RESOURCE_USER, "/path/"),
+				},
+			},
+		},
+		"ErrorCaseInvalidSid": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:        "123",
+			policyName: "test",
+			sid:        "*%~#@|",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: sid *%~#@|",
+			},
+		},
+		"ErrorCasePolicyNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:        "123",
+			policyName: "test",
+			sid:        "first",
+			getPolicyByNameMethodErr: &database.Error{
+				Code: database.POLICY_NOT_FOUND,
+			},
+			wantError: &Error{
+				Code: POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+			},
+		},
+		"ErrorCaseStatementNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:                         "123",
+			policyName:                  "test",
+			sid:                         "third",
+			getPolicyByNameMethodResult: makeStatementsTestPolicy(),
+			wantError: &Error{
+				Code:    STATEMENT_NOT_FOUND,
+				Message: "Statement with sid third not found in policy test",
+			},
+		},
+	}
+
+	testRepo := makeTestRepo()
+	testAPI := makeTestAPI(testRepo)
+
+	for x, testcase := range testcases {
+		testRepo.ArgsOut[GetPolicyByNameMethod][0] = testcase.getPolicyByNameMethodResult
+		testRepo.ArgsOut[GetPolicyByNameMethod][1] = testcase.getPolicyByNameMethodErr
+		statement, err := testAPI.GetPolicyStatement(testcase.requestInfo, testcase.org, testcase.policyName, testcase.sid)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, statement)
+	}
+}
+
+func TestAuthAPI_AddPolicyStatement(t *testing.T) {
+	testcases := map[string]struct {
+		requestInfo RequestInfo
+		org         string
+		policyName  string
+		statement   Statement
+
+		getPolicyByNameMethodResult *Policy
+		updatePolicyMethodErr       error
+
+		expectedResponse   *Statement
+		expectedStatements []Statement
+		wantError          error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:        "123",
+			policyName: "test",
+			statement: Statement{
+				Sid:    "third",
+				Effect: "allow",
+				Actions: []string{
+					USER_ACTION_LIST_USERS,
+				},
+				Resources: []string{
+					GetUrnPrefix("", RESOURCE_USER, "/path/"),
+				},
+			},
+			getPolicyByNameMethodResult: makeStatementsTestPolicy(),
+			expectedResponse: &Statement{
+				Sid:    "third",
+				Effect: "allow",
+				Actions: []string{
+					USER_ACTION_LIST_USERS,
+				},
+				Resources: []string{
+					GetUrnPrefix("", RESOURCE_USER, "/path/"),
+				},
+			},
+			expectedStatements: append(*makeStatementsTestPolicy().Statements, Statement{
+				Sid:    "third",
+				Effect: "allow",
+				Actions: []string{
+					USER_ACTION_LIST_USERS,
+				},
+				Resources: []string{
+					GetUrnPrefix("", RESOURCE_USER, "/path/"),
+				},
+			}),
+		},
+		"ErrorCaseInvalidSid": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:        "123",
+			policyName: "test",
+			statement: Statement{
+				Sid: "*%~#@|",
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: sid *%~#@|",
+			},
+		},
+		"ErrorCaseStatementAlreadyExist": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:        "123",
+			policyName: "test",
+			statement: Statement{
+				Sid:    "first",
+				Effect: "allow",
+				Actions: []string{
+					USER_ACTION_LIST_USERS,
+				},
+				Resources: []string{
+					GetUrnPrefix("", RESOURCE_USER, "/path/"),
+				},
+			},
+			getPolicyByNameMethodResult: makeStatementsTestPolicy(),
+			wantError: &Error{
+				Code:    STATEMENT_ALREADY_EXIST,
+				Message: "Unable to add statement, statement with sid first already exist in policy test",
+			},
+		},
+		"ErrorCaseInvalidStatement": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:        "123",
+			policyName: "test",
+			statement: Statement{
+				Sid:    "third",
+				Effect: "allow",
+				Resources: []string{
+					GetUrnPrefix("", RESOURCE_USER, "/path/"),
+				},
+			},
+			getPolicyByNameMethodResult: makeStatementsTestPolicy(),
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Empty actions",
+			},
+		},
+		"ErrorCaseUpdatePolicyDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:        "123",
+			policyName: "test",
+			statement: Statement{
+				Sid:    "third",
+				Effect: "allow",
+				Actions: []string{
+					USER_ACTION_LIST_USERS,
+				},
+				Resources: []string{
+					GetUrnPrefix("", RESOURCE_USER, "/path/"),
+				},
+			},
+			getPolicyByNameMethodResult: makeStatementsTestPolicy(),
+			updatePolicyMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+		},
+	}
+
+	testRepo := makeTestRepo()
+	testAPI := makeTestAPI(testRepo)
+
+	for x, testcase := range testcases {
+		testRepo.ArgsOut[GetPolicyByNameMethod][0] = testcase.getPolicyByNameMethodResult
+		testRepo.ArgsOut[GetPolicyByNameMethod][1] = nil
+		testRepo.ArgsOut[UpdatePolicyMethod][0] = testcase.getPolicyByNameMethodResult
+		testRepo.ArgsOut[UpdatePolicyMethod][1] = testcase.updatePolicyMethodErr
+		statement, err := testAPI.AddPolicyStatement(testcase.requestInfo, testcase.org, testcase.policyName, testcase.statement)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, statement)
+		if testcase.wantError == nil {
+			if diff := pretty.Compare(testRepo.ArgsIn[UpdatePolicyMethod][4], testcase.expectedStatements); diff != "" {
+				t.Errorf("Test %v failed. Received different statements (received/wanted) %v", x, diff)
+			}
+		}
+	}
+}
+
+func TestAuthAPI_AddPolicyStatementGeneratedSid(t *testing.T) {
+	testRepo := makeTestRepo()
+	testAPI := makeTestAPI(testRepo)
+	testRepo.ArgsOut[GetPolicyByNameMethod][0] = makeStatementsTestPolicy()
+	testRepo.ArgsOut[UpdatePolicyMethod][0] = makeStatementsTestPolicy()
+
+	statement, err := testAPI.AddPolicyStatement(RequestInfo{Identifier: "123456", Admin: true}, "123", "test", Statement{
+		Effect: "allow",
+		Actions: []string{
+			USER_ACTION_LIST_USERS,
+		},
+		Resources: []string{
+			GetUrnPrefix("", RESOURCE_USER, "/path/"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !IsValidSid(statement.Sid) {
+		t.Errorf("Invalid generated sid %v", statement.Sid)
+	}
+	storedStatements := testRepo.ArgsIn[UpdatePolicyMethod][4].([]Statement)
+	if storedStatements[2].Sid != statement.Sid {
+		t.Errorf("Stored sid %v is different from returned sid %v", storedStatements[2].Sid, statement.Sid)
+	}
+}
+
+func TestAuthAPI_UpdatePolicyStatement(t *testing.T) {
+	testcases := map[string]struct {
+		requestInfo RequestInfo
+		org         string
+		policyName  string
+		sid         string
+		statement   Statement
+
+		getPolicyByNameMethodResult *Policy
+		getPolicyByNameMethodErr    error
+
+		expectedResponse   *Statement
+		expectedStatements []Statement
+		wantError          error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:        "123",
+			policyName: "test",
+			sid:        "first",
+			statement: Statement{
+				Sid:    "ignored",
+				Effect: "deny",
+				Actions: []string{
+					USER_ACTION_LIST_USERS,
+				},
+				Resources: []string{
+					GetUrnPrefix("", RESOURCE_USER, "/path/"),
+				},
+			},
+			getPolicyByNameMethodResult: makeStatementsTestPolicy(),
+			expectedResponse: &Statement{
+				Sid:    "first",
+				Effect: "deny",
+				Actions: []string{
+					USER_ACTION_LIST_USERS,
+				},
+				Resources: []string{
+					GetUrnPrefix("", RESOURCE_USER, "/path/"),
+				},
+			},
+			expectedStatements: []Statement{
+				{
+					Sid:    "first",
+					Effect: "deny",
+					Actions: []string{
+						USER_ACTION_LIST_USERS,
+					},
+					Resources: []string{
+						GetUrnPrefix("", RESOURCE_USER, "/path/"),
+					},
+				},
+				(*makeStatementsTestPolicy().Statements)[1],
+			},
+		},
+		"ErrorCaseStatementNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:        "123",
+			policyName: "test",
+			sid:        "third",
+			statement: Statement{
+				Effect: "deny",
+				Actions: []string{
+					USER_ACTION_LIST_USERS,
+				},
+				Resources: []string{
+					GetUrnPrefix("", RESOURCE_USER, "/path/"),
+				},
+			},
+			getPolicyByNameMethodResult: makeStatementsTestPolicy(),
+			wantError: &Error{
+				Code:    STATEMENT_NOT_FOUND,
+				Message: "Statement with sid third not found in policy test",
+			},
+		},
+		"ErrorCasePolicyNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:        "123",
+			policyName: "test",
+			sid:        "first",
+			getPolicyByNameMethodErr: &database.Error{
+				Code: database.POLICY_NOT_FOUND,
+			},
+			wantError: &Error{
+				Code: POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+			},
+		},
+		"ErrorCasePolicyModified": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+				IfMatch:    "\"outdated\"",
+			},
+			org:        "123",
+			policyName: "test",
+			sid:        "first",
+			statement: Statement{
+				Effect: "deny",
+				Actions: []string{
+					USER_ACTION_LIST_USERS,
+				},
+				Resources: []string{
+					GetUrnPrefix("", RESOURCE_USER, "/path/"),
+				},
+			},
+			getPolicyByNameMethodResult: makeStatementsTestPolicy(),
+			wantError: &Error{
+				Code: PRECONDITION_FAILED_ERROR,
+				Message: "Resource urn:iws:iam:123:policy/path/test has been modified, entity tag \"outdated\" doesn't match current entity tag " +
+					ETag(*makeStatementsTestPolicy()),
+			},
+		},
+	}
+
+	testRepo := makeTestRepo()
+	testAPI := makeTestAPI(testRepo)
+
+	for x, testcase := range testcases {
+		testRepo.ArgsOut[GetPolicyByNameMethod][0] = testcase.getPolicyByNameMethodResult
+		testRepo.ArgsOut[GetPolicyByNameMethod][1] = testcase.getPolicyByNameMethodErr
+		testRepo.ArgsOut[UpdatePolicyMethod][0] = testcase.getPolicyByNameMethodResult
+		statement, err := testAPI.UpdatePolicyStatement(testcase.requestInfo, testcase.org, testcase.policyName, testcase.sid, testcase.statement)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, statement)
+		if testcase.wantError == nil {
+			if diff := pretty.Compare(testRepo.ArgsIn[UpdatePolicyMethod][4], testcase.expectedStatements); diff != "" {
+				t.Errorf("Test %v failed. Received different statements (received/wanted) %v", x, diff)
+			}
+		}
+	}
+}
+
+func TestAuthAPI_RemovePolicyStatement(t *testing.T) {
+	testcases := map[string]struct {
+		requestInfo RequestInfo
+		org         string
+		policyName  string
+		sid         string
+
+		getPolicyByNameMethodResult *Policy
+
+		expectedStatements []Statement
+		wantError          error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:                         "123",
+			policyName:                  "test",
+			sid:                         "first",
+			getPolicyByNameMethodResult: makeStatementsTestPolicy(),
+			expectedStatements: []Statement{
+				(*makeStatementsTestPolicy().Statements)[1],
+			},
+		},
+		"ErrorCaseInvalidSid": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:        "123",
+			policyName: "test",
+			sid:        "",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: sid ",
+			},
+		},
+		"ErrorCaseStatementNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:                         "123",
+			policyName:                  "test",
+			sid:                         "third",
+			getPolicyByNameMethodResult: makeStatementsTestPolicy(),
+			wantError: &Error{
+				Code:    STATEMENT_NOT_FOUND,
+				Message: "Statement with sid third not found in policy test",
+			},
+		},
+	}
+
+	testRepo := makeTestRepo()
+	testAPI := makeTestAPI(testRepo)
+
+	for x, testcase := range testcases {
+		testRepo.ArgsOut[GetPolicyByNameMethod][0] = testcase.getPolicyByNameMethodResult
+		testRepo.ArgsOut[GetPolicyByNameMethod][1] = nil
+		testRepo.ArgsOut[UpdatePolicyMethod][0] = testcase.getPolicyByNameMethodResult
+		err := testAPI.RemovePolicyStatement(testcase.requestInfo, testcase.org, testcase.policyName, testcase.sid)
+		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+		if testcase.wantError == nil {
+			if diff := pretty.Compare(testRepo.ArgsIn[UpdatePolicyMethod][4], testcase.expectedStatements); diff != "" {
+				t.Errorf("Test %v failed. Received different statements (received/wanted) %v", x, diff)
+			}
+		}
+	}
+}
@@ -0,0 +1,499 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tecsisa/foulkon/database"
+)
+
+// TYPE DEFINITIONS
+
+const (
+	// Condition operators
+	CONDITION_OPERATOR_EQUALS     = "=="
+	CONDITION_OPERATOR_NOT_EQUALS = "!="
+
+	// Condition operand prefixes. Operands without prefix refer to principal tags
+	CONDITION_PRINCIPAL_PREFIX = "principal."
+	CONDITION_RESOURCE_PREFIX  = "resource."
+)
+
+var rCondition, _ = regexp.Compile(`^\s*([^\s=!]+)\s*(==|!=)\s*([^\s=!]+)\s*$`)
+
+// Tag comparison of a statement, e.g. "team == resource.team" or "resource.env != 'prod'"
+type condition struct {
+	left     conditionOperand
+	operator string
+	right    conditionOperand
+}
+
+// Operand of a condition, a principal tag, a resource tag or a quoted literal value
+type conditionOperand struct {
+	principalTag string
+	resourceTag  string
+	literal      string
+}
+
+// Retrieve operand value for the given tags. It returns false if the referenced tag doesn't exist
+func (o conditionOperand) value(principalTags map[string]string, resourceTags map[string]string) (string, bool) {
+	switch {
+	case o.principalTag != "":
+		value, ok := principalTags[o.principalTag]
+		return value, ok
+	case o.resourceTag != "":
+		value, ok := resourceTags[o.resourceTag]
+		return value, ok
+	default:
+		return o.literal, true
+	}
+}
+
+// Evaluate condition for the given tags. A missing tag isn't equal to any value, so conditions that
+// reference it are false with == and true with !=, and deny statements apply to untagged resources
+func (c condition) evaluate(principalTags map[string]string, resourceTags map[string]string) bool {
+	left, leftOk := c.left.value(principalTags, resourceTags)
+	right, rightOk := c.right.value(principalTags, resourceTags)
+	equals := leftOk && rightOk && left == right
+	if c.operator == CONDITION_OPERATOR_EQUALS {
+		return equals
+	}
+	return !equals
+}
+
+// TAG API IMPLEMENTATION
+
+func (api AuthAPI) ListUserTags(requestInfo RequestInfo, externalId string) (map[string]string, error) {
+	user, err := api.GetUserByExternalID(requestInfo, externalId)
+	if err != nil {
+		return nil, err
+	}
+
+	return getTagsOrEmpty(user.Tags), nil
+}
+
+func (api AuthAPI) SetUserTag(requestInfo RequestInfo, externalId string, key string, value string) (map[string]string, error) {
+	if err := isValidTag(key, value); err != nil {
+		return nil, err
+	}
+	user, err := api.getUserToTag(requestInfo, externalId, USER_ACTION_TAG_USER)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := api.setTag(requestInfo, USER_ACTION_TAG_USER, user.ID, user.Urn, user.Tags, key, value)
+	if err != nil {
+		return nil, err
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Tag %v=%v set to user %v", key, value, user.Urn))
+	return tags, nil
+}
+
+func (api AuthAPI) RemoveUserTag(requestInfo RequestInfo, externalId string, key string) error {
+	if !IsValidTagKey(key) {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: tag key %v", key),
+		}
+	}
+	user, err := api.getUserToTag(requestInfo, externalId, USER_ACTION_UNTAG_USER)
+	if err != nil {
+		return err
+	}
+
+	if err := api.removeTag(requestInfo, USER_ACTION_UNTAG_USER, user.ID, user.Urn, user.Tags, key); err != nil {
+		return err
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Tag %v removed from user %v", key, user.Urn))
+	return nil
+}
+
+func (api AuthAPI) ListGroupTags(requestInfo RequestInfo, org string, name string) (map[string]string, error) {
+	group, err := api.GetGroupByName(requestInfo, org, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return getTagsOrEmpty(group.Tags), nil
+}
+
+func (api AuthAPI) SetGroupTag(requestInfo RequestInfo, org string, name string, key string, value string) (map[string]string, error) {
+	if err := isValidTag(key, value); err != nil {
+		return nil, err
+	}
+	group, err := api.getGroupToTag(requestInfo, org, name, GROUP_ACTION_TAG_GROUP)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := api.setTag(requestInfo, GROUP_ACTION_TAG_GROUP, group.ID, group.Urn, group.Tags, key, value)
+	if err != nil {
+		return nil, err
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Tag %v=%v set to group %v", key, value, group.Urn))
+	return tags, nil
+}
+
+func (api AuthAPI) RemoveGroupTag(requestInfo RequestInfo, org string, name string, key string) error {
+	if !IsValidTagKey(key) {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: tag key %v", key),
+		}
+	}
+	group, err := api.getGroupToTag(requestInfo, org, name, GROUP_ACTION_UNTAG_GROUP)
+	if err != nil {
+		return err
+	}
+
+	if err := api.removeTag(requestInfo, GROUP_ACTION_UNTAG_GROUP, group.ID, group.Urn, group.Tags, key); err != nil {
+		return err
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Tag %v removed from group %v", key, group.Urn))
+	return nil
+}
+
+func (api AuthAPI) ListPolicyTags(requestInfo RequestInfo, org string, name string) (map[string]string, error) {
+	policy, err := api.GetPolicyByName(requestInfo, org, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return getTagsOrEmpty(policy.Tags), nil
+}
+
+func (api AuthAPI) SetPolicyTag(requestInfo RequestInfo, org string, name string, key string, value string) (map[string]string, error) {
+	if err := isValidTag(key, value); err != nil {
+		return nil, err
+	}
+	policy, err := api.getPolicyToTag(requestInfo, org, name, POLICY_ACTION_TAG_POLICY)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := api.setTag(requestInfo, POLICY_ACTION_TAG_POLICY, policy.ID, policy.Urn, policy.Tags, key, value)
+	if err != nil {
+		return nil, err
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Tag %v=%v set to policy %v", key, value, policy.Urn))
+	return tags, nil
+}
+
+func (api AuthAPI) RemovePolicyTag(requestInfo RequestInfo, org string, name string, key string) error {
+	if !IsValidTagKey(key) {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: tag key %v", key),
+		}
+	}
+	policy, err := api.getPolicyToTag(requestInfo, org, name, POLICY_ACTION_UNTAG_POLICY)
+	if err != nil {
+		return err
+	}
+
+	if err := api.removeTag(requestInfo, POLICY_ACTION_UNTAG_POLICY, policy.ID, policy.Urn, policy.Tags, key); err != nil {
+		return err
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Tag %v removed from policy %v", key, policy.Urn))
+	return nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Retrieve user and check that the authenticated user is allowed to modify its tags
+func (api AuthAPI) getUserToTag(requestInfo RequestInfo, externalId string, action string) (*User, error) {
+	if !IsValidUserExternalID(externalId) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: externalId %v", externalId),
+		}
+	}
+
+	user, err := api.UserRepo.GetUserByExternalID(externalId)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.USER_NOT_FOUND:
+			return nil, &Error{
+				Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	// Check restrictions
+	usersFiltered, err := api.GetAuthorizedUsers(requestInfo, user.Urn, action, []User{*user})
+	if err != nil {
+		return nil, err
+	}
+	if len(usersFiltered) < 1 {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, user.Urn),
+		}
+	}
+
+	return user, nil
+}
+
+// Retrieve group and check that the authenticated user is allowed to modify its tags
+func (api AuthAPI) getGroupToTag(requestInfo RequestInfo, org string, name string, action string) (*Group, error) {
+	if !IsValidName(name) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: name %v", name),
+		}
+	}
+	if !IsValidOrg(org) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: org %v", org),
+		}
+	}
+
+	group, err := api.GroupRepo.GetGroupByName(org, name)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.GROUP_NOT_FOUND:
+			return nil, &Error{
+				Code:    GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	// Check restrictions
+	groupsFiltered, err := api.GetAuthorizedGroups(requestInfo, group.Urn, action, []Group{*group})
+	if err != nil {
+		return nil, err
+	}
+	if len(groupsFiltered) < 1 {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, group.Urn),
+		}
+	}
+
+	return group, nil
+}
+
+// Retrieve policy and check that the authenticated user is allowed to modify its tags
+func (api AuthAPI) getPolicyToTag(requestInfo RequestInfo, org string, name string, action string) (*Policy, error) {
+	if !IsValidName(name) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: name %v", name),
+		}
+	}
+	if !IsValidOrg(org) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: org %v", org),
+		}
+	}
+
+	policy, err := api.PolicyRepo.GetPolicyByName(org, name)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.POLICY_NOT_FOUND:
+			return nil, &Error{
+				Code:    POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	// Check restrictions
+	policiesFiltered, err := api.GetAuthorizedPolicies(requestInfo, policy.Urn, action, []Policy{*policy})
+	if err != nil {
+		return nil, err
+	}
+	if len(policiesFiltered) < 1 {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, policy.Urn),
+		}
+	}
+
+	return policy, nil
+}
+
+// Set the tag of a resource with its audit event in the same transaction, and return the resulting tags
+func (api AuthAPI) setTag(requestInfo RequestInfo, action string, resourceID string, urn string, tags map[string]string,
+	key string, value string) (map[string]string, error) {
+	newTags := getTagsOrEmpty(tags)
+	newTags[key] = value
+
+	err := api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		if err := repo.SetTag(resourceID, key, value); err != nil {
+			return err
+		}
+		return api.auditOperation(repo, requestInfo, action, urn, getTagsOrEmpty(tags), newTags)
+	})
+
+	// Error handling
+	if err != nil {
+		return nil, toUnknownAPIError(err)
+	}
+
+	return newTags, nil
+}
+
+// Remove the tag of a resource with its audit event in the same transaction
+func (api AuthAPI) removeTag(requestInfo RequestInfo, action string, resourceID string, urn string, tags map[string]string,
+	key string) error {
+	if _, ok := tags[key]; !ok {
+		return &Error{
+			Code:    TAG_NOT_FOUND,
+			Message: fmt.Sprintf("Tag %v not found in resource %v", key, urn),
+		}
+	}
+	newTags := getTagsOrEmpty(tags)
+	delete(newTags, key)
+
+	err := api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		if err := repo.RemoveTag(resourceID, key); err != nil {
+			return err
+		}
+		return api.auditOperation(repo, requestInfo, action, urn, getTagsOrEmpty(tags), newTags)
+	})
+
+	// Error handling
+	if err != nil {
+		return toUnknownAPIError(err)
+	}
+
+	return nil
+}
+
+func isValidTag(key string, value string) error {
+	return AreValidTags(map[string]string{key: value})
+}
+
+// Return a copy of tags that is never nil
+func getTagsOrEmpty(tags map[string]string) map[string]string {
+	result := make(map[string]string, len(tags))
+	for key, value := range tags {
+		result[key] = value
+	}
+	return result
+}
+
+// Parse a statement condition like "team == resource.team"
+func parseCondition(cond string) (*condition, error) {
+	errFunc := func() error {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid condition: %v", cond),
+		}
+	}
+
+	match := rCondition.FindStringSubmatch(cond)
+	if match == nil {
+		return nil, errFunc()
+	}
+	left, ok := parseConditionOperand(match[1])
+	if !ok {
+		return nil, errFunc()
+	}
+	right, ok := parseConditionOperand(match[3])
+	if !ok {
+		return nil, errFunc()
+	}
+	// Comparing two literals makes no sense
+	if left.literal != "" && right.literal != "" {
+		return nil, errFunc()
+	}
+
+	return &condition{
+		left:     *left,
+		operator: match[2],
+		right:    *right,
+	}, nil
+}
+
+func parseConditionOperand(operand string) (*conditionOperand, bool) {
+	switch {
+	case len(operand) > 1 && strings.HasPrefix(operand, "'") && strings.HasSuffix(operand, "'"):
+		literal := strings.Trim(operand, "'")
+		return &conditionOperand{literal: literal}, IsValidTagValue(literal)
+	case strings.HasPrefix(operand, CONDITION_RESOURCE_PREFIX):
+		key := strings.TrimPrefix(operand, CONDITION_RESOURCE_PREFIX)
+		return &conditionOperand{resourceTag: key}, IsValidTagKey(key)
+	default:
+		key := strings.TrimPrefix(operand, CONDITION_PRINCIPAL_PREFIX)
+		return &conditionOperand{principalTag: key}, IsValidTagKey(key)
+	}
+}
+
+// Returns true if any statement has conditions
+func hasConditions(statements []Statement) bool {
+	for _, statement := range statements {
+		if len(statement.Conditions) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter statements whose conditions are all true for the given principal and resource tags
+func getStatementsByConditions(statements []Statement, principalTags map[string]string, resourceTags map[string]string) []Statement {
+	filtered := []Statement{}
+	for _, statement := range statements {
+		matches := true
+		for _, cond := range statement.Conditions {
+			c, err := parseCondition(cond)
+			if err != nil || !c.evaluate(principalTags, resourceTags) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			filtered = append(filtered, statement)
+		}
+	}
+	return filtered
+}
+
+// Filter statements in the most permissive way when resource tags are unknown: allow statements
+// are kept leaving out their conditions, and deny statements with conditions are discarded
+func getStatementsWithoutConditions(statements []Statement) []Statement {
+	filtered := []Statement{}
+	for _, statement := range statements {
+		if len(statement.Conditions) > 0 && statement.Effect != "allow" {
+			continue
+		}
+		filtered = append(filtered, statement)
+	}
+	return filtered
+}
@@ -0,0 +1,685 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestAuthAPI_ListUserTags(t *testing.T) {
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		externalID  string
+		// Expected results
+		expectedTags map[string]string
+		wantError    error
+		// Manager Results
+		getUserByExternalIDMethodResult *User
+		// Manager Errors
+		getUserByExternalIDMethodErr error
+	}{
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID:   "1234",
+			expectedTags: map[string]string{"team": "payments"},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+				Tags:       map[string]string{"team": "payments"},
+			},
+		},
+		"OKCaseNoTags": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID:   "1234",
+			expectedTags: map[string]string{},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			wantError: &Error{
+				Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User with externalId 1234 not found",
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code:    database.USER_NOT_FOUND,
+				Message: "User with externalId 1234 not found",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDMethodResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
+
+		tags, err := testAPI.ListUserTags(testcase.requestInfo, testcase.externalID)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedTags, tags)
+	}
+}
+
+func TestAuthAPI_SetUserTag(t *testing.T) {
+	principal := &User{
+		ID:         "000",
+		ExternalID: "000",
+		Path:       "/path/",
+		Urn:        CreateUrn("", RESOURCE_USER, "/path/", "000"),
+		Tags:       map[string]string{"team": "payments"},
+	}
+	tagPolicies := []Policy{
+		{
+			ID:   "POLICY-USER-ID",
+			Name: "policyUser",
+			Path: "/path/",
+			Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+			Statements: &[]Statement{
+				{
+					Effect: "allow",
+					Actions: []string{
+						USER_ACTION_TAG_USER,
+					},
+					Resources: []string{
+						GetUrnPrefix("", RESOURCE_USER, "/"),
+					},
+					Conditions: []string{
+						"team == resource.team",
+					},
+				},
+			},
+		},
+	}
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		externalID  string
+		key         string
+		value       string
+		// Expected results
+		expectedTags map[string]string
+		wantError    error
+		// Manager Results
+		getUserByExternalIDMethodResult *User
+		getGroupsByUserIDMethodResult   []Group
+		getAttachedPoliciesMethodResult []Policy
+		// Manager Errors
+		getUserByExternalIDMethodErr error
+		setTagMethodErr              error
+	}{
+		"OKCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID:   "1234",
+			key:          "env",
+			value:        "prod",
+			expectedTags: map[string]string{"team": "payments", "env": "prod"},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+				Tags:       map[string]string{"team": "payments"},
+			},
+		},
+		"OKCaseTagCondition": {
+			requestInfo: RequestInfo{
+				Identifier: "000",
+				Admin:      false,
+			},
+			externalID:   "1234",
+			key:          "team",
+			value:        "billing",
+			expectedTags: map[string]string{"team": "billing"},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+				Tags:       map[string]string{"team": "payments"},
+			},
+			getGroupsByUserIDMethodResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesMethodResult: tagPolicies,
+		},
+		"ErrorCaseInvalidKey": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			key:        "team.name",
+			value:      "payments",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: tag key team.name",
+			},
+		},
+		"ErrorCaseInvalidValue": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			key:        "team",
+			value:      "",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: tag value ",
+			},
+		},
+		"ErrorCaseInvalidExternalID": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "*%~#@|",
+			key:        "team",
+			value:      "payments",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: externalId *%~#@|",
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			key:        "team",
+			value:      "payments",
+			wantError: &Error{
+				Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User with externalId 1234 not found",
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code:    database.USER_NOT_FOUND,
+				Message: "User with externalId 1234 not found",
+			},
+		},
+		"ErrorCaseTagConditionNotMet": {
+			requestInfo: RequestInfo{
+				Identifier: "000",
+				Admin:      false,
+			},
+			externalID: "1234",
+			key:        "team",
+			value:      "payments",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 000 is not allowed to access to resource urn:iws:iam::user/path/1234",
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+				Tags:       map[string]string{"team": "billing"},
+			},
+			getGroupsByUserIDMethodResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesMethodResult: tagPolicies,
+		},
+		"ErrorCaseSetTagDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			key:        "team",
+			value:      "payments",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			setTagMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.SpecialFuncs[GetUserByExternalIDMethod] = func(id string) (*User, error) {
+			if id == principal.ExternalID {
+				return principal, nil
+			}
+			return testcase.getUserByExternalIDMethodResult, testcase.getUserByExternalIDMethodErr
+		}
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDMethodResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesMethodResult
+		testRepo.ArgsOut[SetTagMethod][0] = testcase.setTagMethodErr
+
+		tags, err := testAPI.SetUserTag(testcase.requestInfo, testcase.externalID, testcase.key, testcase.value)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedTags, tags)
+		if testcase.wantError == nil {
+			// Check received args in method SetTag
+			if diff := pretty.Compare(testRepo.ArgsIn[SetTagMethod], []interface{}{"543210", testcase.key, testcase.value}); diff != "" {
+				t.Errorf("Test %v failed. Received different SetTag args (received/wanted) %v", x, diff)
+			}
+		}
+	}
+}
+
+func TestAuthAPI_RemoveUserTag(t *testing.T) {
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		externalID  string
+		key         string
+		// Expected results
+		wantError error
+		// Manager Results
+		getUserByExternalIDMethodResult *User
+		// Manager Errors
+		removeTagMethodErr error
+	}{
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			key:        "team",
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+				Tags:       map[string]string{"team": "payments"},
+			},
+		},
+		"ErrorCaseInvalidKey": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			key:        "team.name",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: tag key team.name",
+			},
+		},
+		"ErrorCaseTagNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			key:        "env",
+			wantError: &Error{
+				Code:    TAG_NOT_FOUND,
+				Message: "Tag env not found in resource urn:iws:iam::user/path/1234",
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+				Tags:       map[string]string{"team": "payments"},
+			},
+		},
+		"ErrorCaseRemoveTagDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			key:        "team",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+				Tags:       map[string]string{"team": "payments"},
+			},
+			removeTagMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDMethodResult
+		testRepo.ArgsOut[RemoveTagMethod][0] = testcase.removeTagMethodErr
+
+		err := testAPI.RemoveUserTag(testcase.requestInfo, testcase.externalID, testcase.key)
+		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+	}
+}
+
+func TestAuthAPI_SetGroupTag(t *testing.T) {
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		org         string
+		name        string
+		key         string
+		value       string
+		// Expected results
+		expectedTags map[string]string
+		wantError    error
+		// Manager Results
+		getGroupByNameMethodResult *Group
+		// Manager Errors
+		getGroupByNameMethodErr error
+	}{
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:          "org1",
+			name:         "group1",
+			key:          "team",
+			value:        "payments",
+			expectedTags: map[string]string{"team": "payments"},
+			getGroupByNameMethodResult: &Group{
+				ID:   "GROUP-ID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/", "group1"),
+			},
+		},
+		"ErrorCaseInvalidName": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:   "org1",
+			name:  "*%~#@|",
+			key:   "team",
+			value: "payments",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: name *%~#@|",
+			},
+		},
+		"ErrorCaseGroupNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:   "org1",
+			name:  "group1",
+			key:   "team",
+			value: "payments",
+			wantError: &Error{
+				Code:    GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group with organization org1 and name group1 not found",
+			},
+			getGroupByNameMethodErr: &database.Error{
+				Code:    database.GROUP_NOT_FOUND,
+				Message: "Group with organization org1 and name group1 not found",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetGroupByNameMethod][0] = testcase.getGroupByNameMethodResult
+		testRepo.ArgsOut[GetGroupByNameMethod][1] = testcase.getGroupByNameMethodErr
+
+		tags, err := testAPI.SetGroupTag(testcase.requestInfo, testcase.org, testcase.name, testcase.key, testcase.value)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedTags, tags)
+	}
+}
+
+func TestAuthAPI_RemovePolicyTag(t *testing.T) {
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		org         string
+		name        string
+		key         string
+		// Expected results
+		wantError error
+		// Manager Results
+		getPolicyByNameMethodResult *Policy
+		// Manager Errors
+		getPolicyByNameMethodErr error
+	}{
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:  "org1",
+			name: "policy1",
+			key:  "env",
+			getPolicyByNameMethodResult: &Policy{
+				ID:         "POLICY-ID",
+				Name:       "policy1",
+				Org:        "org1",
+				Path:       "/path/",
+				Urn:        CreateUrn("org1", RESOURCE_POLICY, "/path/", "policy1"),
+				Statements: &[]Statement{},
+				Tags:       map[string]string{"env": "prod"},
+			},
+		},
+		"ErrorCasePolicyNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:  "org1",
+			name: "policy1",
+			key:  "env",
+			wantError: &Error{
+				Code:    POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Policy with organization org1 and name policy1 not found",
+			},
+			getPolicyByNameMethodErr: &database.Error{
+				Code:    database.POLICY_NOT_FOUND,
+				Message: "Policy with organization org1 and name policy1 not found",
+			},
+		},
+		"ErrorCaseTagNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:  "org1",
+			name: "policy1",
+			key:  "team",
+			wantError: &Error{
+				Code:    TAG_NOT_FOUND,
+				Message: "Tag team not found in resource urn:iws:iam:org1:policy/path/policy1",
+			},
+			getPolicyByNameMethodResult: &Policy{
+				ID:         "POLICY-ID",
+				Name:       "policy1",
+				Org:        "org1",
+				Path:       "/path/",
+				Urn:        CreateUrn("org1", RESOURCE_POLICY, "/path/", "policy1"),
+				Statements: &[]Statement{},
+				Tags:       map[string]string{"env": "prod"},
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetPolicyByNameMethod][0] = testcase.getPolicyByNameMethodResult
+		testRepo.ArgsOut[GetPolicyByNameMethod][1] = testcase.getPolicyByNameMethodErr
+
+		err := testAPI.RemovePolicyTag(testcase.requestInfo, testcase.org, testcase.name, testcase.key)
+		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+	}
+}
+
+func TestParseCondition(t *testing.T) {
+	testcases := map[string]struct {
+		condition         string
+		expectedCondition *condition
+		wantError         error
+	}{
+		"OKCaseResourceTag": {
+			condition: "team == resource.team",
+			expectedCondition: &condition{
+				left:     conditionOperand{principalTag: "team"},
+				operator: CONDITION_OPERATOR_EQUALS,
+				right:    conditionOperand{resourceTag: "team"},
+			},
+		},
+		"OKCasePrincipalPrefixWithoutSpaces": {
+			condition: "principal.team!=resource.owner",
+			expectedCondition: &condition{
+				left:     conditionOperand{principalTag: "team"},
+				operator: CONDITION_OPERATOR_NOT_EQUALS,
+				right:    conditionOperand{resourceTag: "owner"},
+			},
+		},
+		"OKCaseLiteral": {
+			condition: "resource.env == 'prod'",
+			expectedCondition: &condition{
+				left:     conditionOperand{resourceTag: "env"},
+				operator: CONDITION_OPERATOR_EQUALS,
+				right:    conditionOperand{literal: "prod"},
+			},
+		},
+		"ErrorCaseInvalidOperator": {
+			condition: "team = resource.team",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid condition: team = resource.team",
+			},
+		},
+		"ErrorCaseInvalidKey": {
+			condition: "team == resource.team.name",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid condition: team == resource.team.name",
+			},
+		},
+		"ErrorCaseTwoLiterals": {
+			condition: "'prod' == 'prod'",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid condition: 'prod' == 'prod'",
+			},
+		},
+		"ErrorCaseEmpty": {
+			condition: "",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid condition: ",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		cond, err := parseCondition(testcase.condition)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedCondition, cond)
+	}
+}
+
+func TestGetStatementsByConditions(t *testing.T) {
+	teamStatement := Statement{
+		Effect:     "allow",
+		Actions:    []string{"product:DoAction"},
+		Resources:  []string{"urn:ews:product:instance:resource/*"},
+		Conditions: []string{"team == resource.team"},
+	}
+	prodStatement := Statement{
+		Effect:     "deny",
+		Actions:    []string{"product:DoAction"},
+		Resources:  []string{"urn:ews:product:instance:resource/*"},
+		Conditions: []string{"resource.env == 'prod'", "role != 'admin'"},
+	}
+	plainStatement := Statement{
+		Effect:    "allow",
+		Actions:   []string{"product:DoAction"},
+		Resources: []string{"urn:ews:product:instance:resource/public/*"},
+	}
+	testcases := map[string]struct {
+		principalTags      map[string]string
+		resourceTags       map[string]string
+		expectedStatements []Statement
+	}{
+		"OKCaseAllConditionsMet": {
+			principalTags:      map[string]string{"team": "payments", "role": "developer"},
+			resourceTags:       map[string]string{"team": "payments", "env": "prod"},
+			expectedStatements: []Statement{teamStatement, prodStatement, plainStatement},
+		},
+		"OKCaseDifferentTeam": {
+			principalTags:      map[string]string{"team": "payments", "role": "admin"},
+			resourceTags:       map[string]string{"team": "billing", "env": "prod"},
+			expectedStatements: []Statement{plainStatement},
+		},
+		"OKCaseMissingTags": {
+			principalTags:      nil,
+			resourceTags:       nil,
+			expectedStatements: []Statement{plainStatement},
+		},
+		"OKCaseDenyWithMissingTag": {
+			principalTags:      map[string]string{"team": "payments"},
+			resourceTags:       map[string]string{"team": "payments", "env": "prod"},
+			expectedStatements: []Statement{teamStatement, prodStatement, plainStatement},
+		},
+	}
+
+	for x, testcase := range testcases {
+		statements := getStatementsByConditions([]Statement{teamStatement, prodStatement, plainStatement},
+			testcase.principalTags, testcase.resourceTags)
+		if diff := pretty.Compare(statements, testcase.expectedStatements); diff != "" {
+			t.Errorf("Test %v failed. Received different statements (received/wanted) %v", x, diff)
+		}
+	}
+}
@@ -5,34 +5,88 @@ import (
 	"github.com/kylelemons/godebug/pretty"
 	"math/rand"
 	"testing"
+	"time"
 )
 
 const (
-	GetUserByExternalIDMethod = "GetUserByExternalID"
-	AddUserMethod             = "AddUser"
-	UpdateUserMethod          = "UpdateUser"
-	GetUsersFilteredMethod    = "GetUsersFiltered"
-	GetGroupsByUserIDMethod   = "GetGroupsByUserID"
-	RemoveUserMethod          = "RemoveUser"
-	GetGroupByNameMethod      = "GetGroupByName"
-	IsMemberOfGroupMethod     = "IsMemberOfGroup"
-	GetGroupMembersMethod     = "GetGroupMembers"
-	IsAttachedToGroupMethod   = "IsAttachedToGroup"
-	GetAttachedPoliciesMethod = "GetAttachedPolicies"
-	GetGroupsFilteredMethod   = "GetGroupsFiltered"
-	RemoveGroupMethod         = "RemoveGroup"
-	AddGroupMethod            = "AddGroup"
-	AddMemberMethod           = "AddMember"
-	RemoveMemberMethod        = "RemoveMember"
-	UpdateGroupMethod         = "UpdateGroup"
-	AttachPolicyMethod        = "AttachPolicy"
-	DetachPolicyMethod        = "DetachPolicy"
-	GetPolicyByNameMethod     = "GetPolicyByName"
-	AddPolicyMethod           = "AddPolicy"
-	UpdatePolicyMethod        = "UpdatePolicy"
-	RemovePolicyMethod        = "RemovePolicy"
-	GetPoliciesFilteredMethod = "GetPoliciesFiltered"
-	GetAttachedGroupsMethod   = "GetAttachedGroups"
+	GetUserByExternalIDMethod           = "GetUserByExternalID"
+	AddUserMethod                       = "AddUser"
+	UpdateUserMethod                    = "UpdateUser"
+	UpdateUserStatusMethod              = "UpdateUserStatus"
+	UpdateUserExternalIDMethod          = "UpdateUserExternalID"
+	GetUsersFilteredMethod              = "GetUsersFiltered"
+	GetGroupsByUserIDMethod             = "GetGroupsByUserID"
+	RemoveUserMethod                    = "RemoveUser"
+	GetGroupByNameMethod                = "GetGroupByName"
+	IsMemberOfGroupMethod               = "IsMemberOfGroup"
+	GetGroupMembersMethod               = "GetGroupMembers"
+	IsAttachedToGroupMethod             = "IsAttachedToGroup"
+	GetAttachedPoliciesMethod           = "GetAttachedPolicies"
+	GetGroupPolicyRelationsMethod       = "GetGroupPolicyRelations"
+	GetGroupUserRelationsMethod         = "GetGroupUserRelations"
+	GetGroupsFilteredMethod             = "GetGroupsFiltered"
+	RemoveGroupMethod                   = "RemoveGroup"
+	AddGroupMethod                      = "AddGroup"
+	AddMemberMethod                     = "AddMember"
+	RemoveMemberMethod                  = "RemoveMember"
+	AddOwnerMethod                      = "AddOwner"
+	RemoveOwnerMethod                   = "RemoveOwner"
+	UpdateGroupMethod                   = "UpdateGroup"
+	SetGroupMembershipRuleMethod        = "SetGroupMembershipRule"
+	AttachPolicyMethod                  = "AttachPolicy"
+	DetachPolicyMethod                  = "DetachPolicy"
+	GetPolicyByNameMethod               = "GetPolicyByName"
+	AddPolicyMethod                     = "AddPolicy"
+	UpdatePolicyMethod                  = "UpdatePolicy"
+	RemovePolicyMethod                  = "RemovePolicy"
+	GetPoliciesFilteredMethod           = "GetPoliciesFiltered"
+	GetAttachedGroupsMethod             = "GetAttachedGroups"
+	GetPoliciesReferencingUrnMethod     = "GetPoliciesReferencingUrn"
+	AddAccessRequestMethod              = "AddAccessRequest"
+	GetAccessRequestByIDMethod          = "GetAccessRequestByID"
+	GetAccessRequestsFilteredMethod     = "GetAccessRequestsFiltered"
+	UpdateAccessRequestMethod           = "UpdateAccessRequest"
+	UpdateAccessRequestsRequesterMethod = "UpdateAccessRequestsRequester"
+	AddNamespaceMethod                  = "AddNamespace"
+	GetNamespaceByNameMethod            = "GetNamespaceByName"
+	GetNamespacesMethod                 = "GetNamespaces"
+	RemoveNamespaceMethod               = "RemoveNamespace"
+	AddActionMethod                     = "AddAction"
+	GetActionByNameMethod               = "GetActionByName"
+	GetActionsFilteredMethod            = "GetActionsFiltered"
+	RemoveActionMethod                  = "RemoveAction"
+	AddResourceTypeMethod               = "AddResourceType"
+	GetResourceTypeByNameMethod         = "GetResourceTypeByName"
+	GetResourceTypesFilteredMethod      = "GetResourceTypesFiltered"
+	RemoveResourceTypeMethod            = "RemoveResourceType"
+	SetTagMethod                        = "SetTag"
+	RemoveTagMethod                     = "RemoveTag"
+	GetDeletedResourcesFilteredMethod   = "GetDeletedResourcesFiltered"
+	GetDeletedResourceByIDMethod        = "GetDeletedResourceByID"
+	RestoreDeletedResourceMethod        = "RestoreDeletedResource"
+	PurgeDeletedResourcesMethod         = "PurgeDeletedResources"
+	GetConsistencyReportMethod          = "GetConsistencyReport"
+	AddAuditEventMethod                 = "AddAuditEvent"
+	GetAuditEventsFilteredMethod        = "GetAuditEventsFiltered"
+	AddOrganizationMethod               = "AddOrganization"
+	GetOrganizationByNameMethod         = "GetOrganizationByName"
+	GetOrganizationsFilteredMethod      = "GetOrganizationsFiltered"
+	UpdateOrganizationMethod            = "UpdateOrganization"
+	RemoveOrganizationMethod            = "RemoveOrganization"
+	RunInTransactionMethod              = "RunInTransaction"
+)
+
+// Webhook repo methods
+const (
+	AddWebhookMethod                    = "AddWebhook"
+	GetWebhookByIDMethod                = "GetWebhookByID"
+	GetWebhooksFilteredMethod           = "GetWebhooksFiltered"
+	UpdateWebhookMethod                 = "UpdateWebhook"
+	RemoveWebhookMethod                 = "RemoveWebhook"
+	AddWebhookDeliveryMethod            = "AddWebhookDelivery"
+	UpdateWebhookDeliveryMethod         = "UpdateWebhookDelivery"
+	GetWebhookDeliveriesFilteredMethod  = "GetWebhookDeliveriesFiltered"
+	ClaimPendingWebhookDeliveriesMethod = "ClaimPendingWebhookDeliveries"
 )
 
 // TestRepo that implements all repo manager interfaces
@@ -51,65 +105,171 @@ func makeTestRepo() *TestRepo {
 	}
 	testRepo.ArgsIn[GetUserByExternalIDMethod] = make([]interface{}, 1)
 	testRepo.ArgsIn[AddUserMethod] = make([]interface{}, 1)
-	testRepo.ArgsIn[UpdateUserMethod] = make([]interface{}, 3)
+	testRepo.ArgsIn[UpdateUserMethod] = make([]interface{}, 4)
+	testRepo.ArgsIn[UpdateUserStatusMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[UpdateUserExternalIDMethod] = make([]interface{}, 3)
 	testRepo.ArgsIn[GetUsersFilteredMethod] = make([]interface{}, 1)
-	testRepo.ArgsIn[GetGroupsByUserIDMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetGroupsByUserIDMethod] = make([]interface{}, 2)
 	testRepo.ArgsIn[RemoveUserMethod] = make([]interface{}, 1)
 	testRepo.ArgsIn[GetGroupByNameMethod] = make([]interface{}, 2)
 	testRepo.ArgsIn[IsMemberOfGroupMethod] = make([]interface{}, 2)
-	testRepo.ArgsIn[GetGroupMembersMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetGroupMembersMethod] = make([]interface{}, 2)
 	testRepo.ArgsIn[IsAttachedToGroupMethod] = make([]interface{}, 2)
-	testRepo.ArgsIn[GetAttachedPoliciesMethod] = make([]interface{}, 1)
-	testRepo.ArgsIn[GetGroupsFilteredMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[GetAttachedPoliciesMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[GetGroupPolicyRelationsMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[GetGroupUserRelationsMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[GetGroupsFilteredMethod] = make([]interface{}, 1)
 	testRepo.ArgsIn[RemoveGroupMethod] = make([]interface{}, 1)
 	testRepo.ArgsIn[AddGroupMethod] = make([]interface{}, 1)
-	testRepo.ArgsIn[AddMemberMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[AddMemberMethod] = make([]interface{}, 3)
 	testRepo.ArgsIn[RemoveMemberMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[AddOwnerMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[RemoveOwnerMethod] = make([]interface{}, 2)
 	testRepo.ArgsIn[UpdateGroupMethod] = make([]interface{}, 4)
-	testRepo.ArgsIn[AttachPolicyMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[SetGroupMembershipRuleMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[AttachPolicyMethod] = make([]interface{}, 4)
 	testRepo.ArgsIn[DetachPolicyMethod] = make([]interface{}, 2)
 	testRepo.ArgsIn[GetPolicyByNameMethod] = make([]interface{}, 2)
 	testRepo.ArgsIn[AddPolicyMethod] = make([]interface{}, 1)
 	testRepo.ArgsIn[UpdatePolicyMethod] = make([]interface{}, 5)
 	testRepo.ArgsIn[RemovePolicyMethod] = make([]interface{}, 1)
-	testRepo.ArgsIn[GetPoliciesFilteredMethod] = make([]interface{}, 2)
-	testRepo.ArgsIn[GetAttachedGroupsMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetPoliciesFilteredMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetAttachedGroupsMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[GetPoliciesReferencingUrnMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[AddAccessRequestMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetAccessRequestByIDMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetAccessRequestsFilteredMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[UpdateAccessRequestMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[UpdateAccessRequestsRequesterMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[AddNamespaceMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetNamespaceByNameMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetNamespacesMethod] = make([]interface{}, 0)
+	testRepo.ArgsIn[RemoveNamespaceMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[AddActionMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetActionByNameMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[GetActionsFilteredMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[RemoveActionMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[AddResourceTypeMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetResourceTypeByNameMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[GetResourceTypesFilteredMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[RemoveResourceTypeMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[SetTagMethod] = make([]interface{}, 3)
+	testRepo.ArgsIn[RemoveTagMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[GetDeletedResourcesFilteredMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[GetDeletedResourceByIDMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[RestoreDeletedResourceMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[PurgeDeletedResourcesMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[AddAuditEventMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetAuditEventsFilteredMethod] = make([]interface{}, 4)
+	testRepo.ArgsIn[AddWebhookMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetWebhookByIDMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetWebhooksFilteredMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[UpdateWebhookMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[RemoveWebhookMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[AddWebhookDeliveryMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[UpdateWebhookDeliveryMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetWebhookDeliveriesFilteredMethod] = make([]interface{}, 3)
+	testRepo.ArgsIn[ClaimPendingWebhookDeliveriesMethod] = make([]interface{}, 3)
+	testRepo.ArgsIn[AddOrganizationMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetOrganizationByNameMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetOrganizationsFilteredMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[UpdateOrganizationMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[RemoveOrganizationMethod] = make([]interface{}, 1)
 
 	testRepo.ArgsOut[GetUserByExternalIDMethod] = make([]interface{}, 2)
 	testRepo.ArgsOut[AddUserMethod] = make([]interface{}, 2)
 	testRepo.ArgsOut[UpdateUserMethod] = make([]interface{}, 2)
-	testRepo.ArgsOut[GetUsersFilteredMethod] = make([]interface{}, 2)
-	testRepo.ArgsOut[GetGroupsByUserIDMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[UpdateUserStatusMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[UpdateUserExternalIDMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetUsersFilteredMethod] = make([]interface{}, 3)
+	testRepo.ArgsOut[GetGroupsByUserIDMethod] = make([]interface{}, 3)
 	testRepo.ArgsOut[RemoveUserMethod] = make([]interface{}, 1)
 	testRepo.ArgsOut[GetGroupByNameMethod] = make([]interface{}, 2)
 	testRepo.ArgsOut[IsMemberOfGroupMethod] = make([]interface{}, 2)
-	testRepo.ArgsOut[GetGroupMembersMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetGroupMembersMethod] = make([]interface{}, 3)
 	testRepo.ArgsOut[IsAttachedToGroupMethod] = make([]interface{}, 2)
-	testRepo.ArgsOut[GetAttachedPoliciesMethod] = make([]interface{}, 2)
-	testRepo.ArgsOut[GetGroupsFilteredMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetAttachedPoliciesMethod] = make([]interface{}, 3)
+	testRepo.ArgsOut[GetGroupPolicyRelationsMethod] = make([]interface{}, 3)
+	testRepo.ArgsOut[GetGroupUserRelationsMethod] = make([]interface{}, 3)
+	testRepo.ArgsOut[GetGroupsFilteredMethod] = make([]interface{}, 3)
 	testRepo.ArgsOut[RemoveGroupMethod] = make([]interface{}, 1)
 	testRepo.ArgsOut[AddGroupMethod] = make([]interface{}, 2)
 	testRepo.ArgsOut[AddMemberMethod] = make([]interface{}, 1)
 	testRepo.ArgsOut[RemoveMemberMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[AddOwnerMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[RemoveOwnerMethod] = make([]interface{}, 1)
 	testRepo.ArgsOut[UpdateGroupMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[SetGroupMembershipRuleMethod] = make([]interface{}, 2)
 	testRepo.ArgsOut[AttachPolicyMethod] = make([]interface{}, 1)
 	testRepo.ArgsOut[DetachPolicyMethod] = make([]interface{}, 1)
 	testRepo.ArgsOut[GetPolicyByNameMethod] = make([]interface{}, 2)
 	testRepo.ArgsOut[AddPolicyMethod] = make([]interface{}, 2)
 	testRepo.ArgsOut[UpdatePolicyMethod] = make([]interface{}, 2)
 	testRepo.ArgsOut[RemovePolicyMethod] = make([]interface{}, 1)
-	testRepo.ArgsOut[GetPoliciesFilteredMethod] = make([]interface{}, 2)
-	testRepo.ArgsOut[GetAttachedGroupsMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetPoliciesFilteredMethod] = make([]interface{}, 3)
+	testRepo.ArgsOut[GetAttachedGroupsMethod] = make([]interface{}, 3)
+	testRepo.ArgsOut[GetPoliciesReferencingUrnMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[AddAccessRequestMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetAccessRequestByIDMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetAccessRequestsFilteredMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[UpdateAccessRequestMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[UpdateAccessRequestsRequesterMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[AddNamespaceMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetNamespaceByNameMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetNamespacesMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[RemoveNamespaceMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[AddActionMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetActionByNameMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetActionsFilteredMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[RemoveActionMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[AddResourceTypeMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetResourceTypeByNameMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetResourceTypesFilteredMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[RemoveResourceTypeMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[SetTagMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[RemoveTagMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[GetDeletedResourcesFilteredMethod] = make([]interface{}, 3)
+	testRepo.ArgsOut[GetDeletedResourceByIDMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[RestoreDeletedResourceMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[PurgeDeletedResourcesMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetConsistencyReportMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[AddAuditEventMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetAuditEventsFilteredMethod] = make([]interface{}, 3)
+	testRepo.ArgsOut[AddWebhookMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetWebhookByIDMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetWebhooksFilteredMethod] = make([]interface{}, 3)
+	testRepo.ArgsOut[UpdateWebhookMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[RemoveWebhookMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[AddWebhookDeliveryMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[UpdateWebhookDeliveryMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetWebhookDeliveriesFilteredMethod] = make([]interface{}, 3)
+	testRepo.ArgsOut[ClaimPendingWebhookDeliveriesMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[AddOrganizationMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetOrganizationByNameMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetOrganizationsFilteredMethod] = make([]interface{}, 3)
+	testRepo.ArgsOut[UpdateOrganizationMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[RemoveOrganizationMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[RunInTransactionMethod] = make([]interface{}, 1)
 
 	return testRepo
 }
 
 func makeTestAPI(testRepo *TestRepo) *AuthAPI {
 	api := &AuthAPI{
-		UserRepo:   testRepo,
-		GroupRepo:  testRepo,
-		PolicyRepo: testRepo,
-		Logger:     logrus.StandardLogger(),
+		UserRepo:          testRepo,
+		GroupRepo:         testRepo,
+		PolicyRepo:        testRepo,
+		AccessRequestRepo: testRepo,
+		ActionRepo:        testRepo,
+		ResourceTypeRepo:  testRepo,
+		TagRepo:           testRepo,
+		TrashRepo:         testRepo,
+		ConsistencyRepo:   testRepo,
+		AuditRepo:         testRepo,
+		WebhookRepo:       testRepo,
+		OrganizationRepo:  testRepo,
+		TransactionRepo:   testRepo,
+		Logger:            logrus.StandardLogger(),
 	}
 	return api
 }
@@ -147,10 +307,11 @@ func (t TestRepo) AddUser(user User) (*User, error) {
 	return created, err
 }
 
-func (t TestRepo) UpdateUser(user User, newPath string, newUrn string) (*User, error) {
+func (t TestRepo) UpdateUser(user User, newPath string, newUrn string, newProfile UserProfile) (*User, error) {
 	t.ArgsIn[UpdateUserMethod][0] = user
 	t.ArgsIn[UpdateUserMethod][1] = newPath
 	t.ArgsIn[UpdateUserMethod][2] = newUrn
+	t.ArgsIn[UpdateUserMethod][3] = newProfile
 	var updated *User
 	if t.ArgsOut[UpdateUserMethod][0] != nil {
 		updated = t.ArgsOut[UpdateUserMethod][0].(*User)
@@ -162,34 +323,72 @@ func (t TestRepo) UpdateUser(user User, newPath string, newUrn string) (*User, e
 	return updated, err
 }
 
-func (t TestRepo) GetUsersFiltered(pathPrefix string) ([]User, error) {
-	t.ArgsIn[GetUsersFilteredMethod][0] = pathPrefix
+func (t TestRepo) UpdateUserStatus(user User, status string) (*User, error) {
+	t.ArgsIn[UpdateUserStatusMethod][0] = user
+	t.ArgsIn[UpdateUserStatusMethod][1] = status
+	var updated *User
+	if t.ArgsOut[UpdateUserStatusMethod][0] != nil {
+		updated = t.ArgsOut[UpdateUserStatusMethod][0].(*User)
+	}
+	var err error
+	if t.ArgsOut[UpdateUserStatusMethod][1] != nil {
+		err = t.ArgsOut[UpdateUserStatusMethod][1].(error)
+	}
+	return updated, err
+}
+
+func (t TestRepo) UpdateUserExternalID(user User, newExternalId string, newUrn string) (*User, error) {
+	t.ArgsIn[UpdateUserExternalIDMethod][0] = user
+	t.ArgsIn[UpdateUserExternalIDMethod][1] = newExternalId
+	t.ArgsIn[UpdateUserExternalIDMethod][2] = newUrn
+	var updated *User
+	if t.ArgsOut[UpdateUserExternalIDMethod][0] != nil {
+		updated = t.ArgsOut[UpdateUserExternalIDMethod][0].(*User)
+	}
+	var err error
+	if t.ArgsOut[UpdateUserExternalIDMethod][1] != nil {
+		err = t.ArgsOut[UpdateUserExternalIDMethod][1].(error)
+	}
+	return updated, err
+}
+
+func (t TestRepo) GetUsersFiltered(filter *Filter) ([]User, int, error) {
+	t.ArgsIn[GetUsersFilteredMethod][0] = filter
 	var users []User
 	if t.ArgsOut[GetUsersFilteredMethod][0] != nil {
 		users = t.ArgsOut[GetUsersFilteredMethod][0].([]User)
 	}
-	var err error
+	var total int
 	if t.ArgsOut[GetUsersFilteredMethod][1] != nil {
-		err = t.ArgsOut[GetUsersFilteredMethod][1].(error)
+		total = t.ArgsOut[GetUsersFilteredMethod][1].(int)
 	}
-	return users, err
+	var err error
+	if t.ArgsOut[GetUsersFilteredMethod][2] != nil {
+		err = t.ArgsOut[GetUsersFilteredMethod][2].(error)
+	}
+	return users, total, err
 }
 
-func (t TestRepo) GetGroupsByUserID(id string) ([]Group, error) {
+func (t TestRepo) GetGroupsByUserID(id string, filter *Filter) ([]Group, int, error) {
 	t.ArgsIn[GetGroupsByUserIDMethod][0] = id
+	t.ArgsIn[GetGroupsByUserIDMethod][1] = filter
 	var groups []Group
 	if t.ArgsOut[GetGroupsByUserIDMethod][0] != nil {
 		groups = t.ArgsOut[GetGroupsByUserIDMethod][0].([]Group)
 	}
-	var err error
+	var total int
 	if t.ArgsOut[GetGroupsByUserIDMethod][1] != nil {
-		err = t.ArgsOut[GetGroupsByUserIDMethod][1].(error)
+		total = t.ArgsOut[GetGroupsByUserIDMethod][1].(int)
 	}
-	return groups, err
+	var err error
+	if t.ArgsOut[GetGroupsByUserIDMethod][2] != nil {
+		err = t.ArgsOut[GetGroupsByUserIDMethod][2].(error)
+	}
+	return groups, total, err
 }
 
-func (t TestRepo) RemoveUser(id string) error {
-	t.ArgsIn[RemoveUserMethod][0] = id
+func (t TestRepo) RemoveUser(user User) error {
+	t.ArgsIn[RemoveUserMethod][0] = user
 	var err error
 	if t.ArgsOut[RemoveUserMethod][0] != nil {
 		err = t.ArgsOut[RemoveUserMethod][0].(error)
@@ -232,17 +431,22 @@ func (t TestRepo) IsMemberOfGroup(userID string, groupID string) (bool, error) {
 	return isMember, err
 }
 
-func (t TestRepo) GetGroupMembers(groupID string) ([]User, error) {
+func (t TestRepo) GetGroupMembers(groupID string, filter *Filter) ([]User, int, error) {
 	t.ArgsIn[GetGroupMembersMethod][0] = groupID
+	t.ArgsIn[GetGroupMembersMethod][1] = filter
 	var members []User
 	if t.ArgsOut[GetGroupMembersMethod][0] != nil {
 		members = t.ArgsOut[GetGroupMembersMethod][0].([]User)
 	}
-	var err error
+	var total int
 	if t.ArgsOut[GetGroupMembersMethod][1] != nil {
-		err = t.ArgsOut[GetGroupMembersMethod][1].(error)
+		total = t.ArgsOut[GetGroupMembersMethod][1].(int)
 	}
-	return members, err
+	var err error
+	if t.ArgsOut[GetGroupMembersMethod][2] != nil {
+		err = t.ArgsOut[GetGroupMembersMethod][2].(error)
+	}
+	return members, total, err
 }
 
 func (t TestRepo) IsAttachedToGroup(groupID string, policyID string) (bool, error) {
@@ -259,35 +463,78 @@ func (t TestRepo) IsAttachedToGroup(groupID string, policyID string) (bool, erro
 	return isAttached, err
 }
 
-func (t TestRepo) GetAttachedPolicies(groupID string) ([]Policy, error) {
+func (t TestRepo) GetAttachedPolicies(groupID string, filter *Filter) ([]Policy, int, error) {
 	t.ArgsIn[GetAttachedPoliciesMethod][0] = groupID
+	t.ArgsIn[GetAttachedPoliciesMethod][1] = filter
 	var policies []Policy
 	if t.ArgsOut[GetAttachedPoliciesMethod][0] != nil {
 		policies = t.ArgsOut[GetAttachedPoliciesMethod][0].([]Policy)
 	}
-	var err error
+	var total int
 	if t.ArgsOut[GetAttachedPoliciesMethod][1] != nil {
-		err = t.ArgsOut[GetAttachedPoliciesMethod][1].(error)
+		total = t.ArgsOut[GetAttachedPoliciesMethod][1].(int)
 	}
-	return policies, err
+	var err error
+	if t.ArgsOut[GetAttachedPoliciesMethod][2] != nil {
+		err = t.ArgsOut[GetAttachedPoliciesMethod][2].(error)
+	}
+	return policies, total, err
 }
 
-func (t TestRepo) GetGroupsFiltered(org string, pathPrefix string) ([]Group, error) {
-	t.ArgsIn[GetGroupsFilteredMethod][0] = org
-	t.ArgsIn[GetGroupsFilteredMethod][1] = pathPrefix
+func (t TestRepo) GetGroupPolicyRelations(groupID string, filter *Filter) ([]GroupPolicyRelation, int, error) {
+	t.ArgsIn[GetGroupPolicyRelationsMethod][0] = groupID
+	t.ArgsIn[GetGroupPolicyRelationsMethod][1] = filter
+	var relations []GroupPolicyRelation
+	if t.ArgsOut[GetGroupPolicyRelationsMethod][0] != nil {
+		relations = t.ArgsOut[GetGroupPolicyRelationsMethod][0].([]GroupPolicyRelation)
+	}
+	var total int
+	if t.ArgsOut[GetGroupPolicyRelationsMethod][1] != nil {
+		total = t.ArgsOut[GetGroupPolicyRelationsMethod][1].(int)
+	}
+	var err error
+	if t.ArgsOut[GetGroupPolicyRelationsMethod][2] != nil {
+		err = t.ArgsOut[GetGroupPolicyRelationsMethod][2].(error)
+	}
+	return relations, total, err
+}
+
+func (t TestRepo) GetGroupUserRelations(groupID string, filter *Filter) ([]GroupUserRelation, int, error) {
+	t.ArgsIn[GetGroupUserRelationsMethod][0] = groupID
+	t.ArgsIn[GetGroupUserRelationsMethod][1] = filter
+	var relations []GroupUserRelation
+	if t.ArgsOut[GetGroupUserRelationsMethod][0] != nil {
+		relations = t.ArgsOut[GetGroupUserRelationsMethod][0].([]GroupUserRelation)
+	}
+	var total int
+	if t.ArgsOut[GetGroupUserRelationsMethod][1] != nil {
+		total = t.ArgsOut[GetGroupUserRelationsMethod][1].(int)
+	}
+	var err error
+	if t.ArgsOut[GetGroupUserRelationsMethod][2] != nil {
+		err = t.ArgsOut[GetGroupUserRelationsMethod][2].(error)
+	}
+	return relations, total, err
+}
 
+func (t TestRepo) GetGroupsFiltered(filter *Filter) ([]Group, int, error) {
+	t.ArgsIn[GetGroupsFilteredMethod][0] = filter
 	var groups []Group
 	if t.ArgsOut[GetGroupsFilteredMethod][0] != nil {
 		groups = t.ArgsOut[GetGroupsFilteredMethod][0].([]Group)
 	}
-	var err error
+	var total int
 	if t.ArgsOut[GetGroupsFilteredMethod][1] != nil {
-		err = t.ArgsOut[GetGroupsFilteredMethod][1].(error)
+		total = t.ArgsOut[GetGroupsFilteredMethod][1].(int)
 	}
-	return groups, err
+	var err error
+	if t.ArgsOut[GetGroupsFilteredMethod][2] != nil {
+		err = t.ArgsOut[GetGroupsFilteredMethod][2].(error)
+	}
+	return groups, total, err
 }
-func (t TestRepo) RemoveGroup(id string) error {
-	t.ArgsIn[RemoveGroupMethod][0] = id
+func (t TestRepo) RemoveGroup(group Group) error {
+	t.ArgsIn[RemoveGroupMethod][0] = group
 	var err error
 	if t.ArgsOut[RemoveGroupMethod][0] != nil {
 		err = t.ArgsOut[RemoveGroupMethod][0].(error)
@@ -308,9 +555,10 @@ func (t TestRepo) AddGroup(group Group) (*Group, error) {
 	return created, err
 }
 
-func (t TestRepo) AddMember(userID string, groupID string) error {
+func (t TestRepo) AddMember(userID string, groupID string, expiresAt *time.Time) error {
 	t.ArgsIn[AddMemberMethod][0] = userID
 	t.ArgsIn[AddMemberMethod][1] = groupID
+	t.ArgsIn[AddMemberMethod][2] = expiresAt
 	var err error
 	if t.ArgsOut[AddMemberMethod][0] != nil {
 		err = t.ArgsOut[AddMemberMethod][0].(error)
@@ -328,6 +576,26 @@ func (t TestRepo) RemoveMember(userID string, groupID string) error {
 	return err
 }
 
+func (t TestRepo) AddOwner(userID string, groupID string) error {
+	t.ArgsIn[AddOwnerMethod][0] = userID
+	t.ArgsIn[AddOwnerMethod][1] = groupID
+	var err error
+	if t.ArgsOut[AddOwnerMethod][0] != nil {
+		err = t.ArgsOut[AddOwnerMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestRepo) RemoveOwner(userID string, groupID string) error {
+	t.ArgsIn[RemoveOwnerMethod][0] = userID
+	t.ArgsIn[RemoveOwnerMethod][1] = groupID
+	var err error
+	if t.ArgsOut[RemoveOwnerMethod][0] != nil {
+		err = t.ArgsOut[RemoveOwnerMethod][0].(error)
+	}
+	return err
+}
+
 func (t TestRepo) UpdateGroup(group Group, newName string, newPath string, newUrn string) (*Group, error) {
 	t.ArgsIn[UpdateGroupMethod][0] = group
 	t.ArgsIn[UpdateGroupMethod][1] = newName
@@ -345,9 +613,26 @@ func (t TestRepo) UpdateGroup(group Group, newName string, newPath string, newUr
 	return updated, err
 }
 
-func (t TestRepo) AttachPolicy(groupID string, policyID string) error {
+func (t TestRepo) SetGroupMembershipRule(group Group, rule *MembershipRule) (*Group, error) {
+	t.ArgsIn[SetGroupMembershipRuleMethod][0] = group
+	t.ArgsIn[SetGroupMembershipRuleMethod][1] = rule
+
+	var updated *Group
+	if t.ArgsOut[SetGroupMembershipRuleMethod][0] != nil {
+		updated = t.ArgsOut[SetGroupMembershipRuleMethod][0].(*Group)
+	}
+	var err error
+	if t.ArgsOut[SetGroupMembershipRuleMethod][1] != nil {
+		err = t.ArgsOut[SetGroupMembershipRuleMethod][1].(error)
+	}
+	return updated, err
+}
+
+func (t TestRepo) AttachPolicy(groupID string, policyID string, notBefore *time.Time, notAfter *time.Time) error {
 	t.ArgsIn[AttachPolicyMethod][0] = groupID
 	t.ArgsIn[AttachPolicyMethod][1] = policyID
+	t.ArgsIn[AttachPolicyMethod][2] = notBefore
+	t.ArgsIn[AttachPolicyMethod][3] = notAfter
 	var err error
 	if t.ArgsOut[AttachPolicyMethod][0] != nil {
 		err = t.ArgsOut[AttachPolicyMethod][0].(error)
@@ -416,8 +701,8 @@ func (t TestRepo) UpdatePolicy(policy Policy, newName string, newPath string, ne
 	return updated, err
 }
 
-func (t TestRepo) RemovePolicy(id string) error {
-	t.ArgsIn[RemovePolicyMethod][0] = id
+func (t TestRepo) RemovePolicy(policy Policy) error {
+	t.ArgsIn[RemovePolicyMethod][0] = policy
 	var err error
 	if t.ArgsOut[RemovePolicyMethod][0] != nil {
 		err = t.ArgsOut[RemovePolicyMethod][0].(error)
@@ -425,33 +710,628 @@ func (t TestRepo) RemovePolicy(id string) error {
 	return err
 }
 
-func (t TestRepo) GetPoliciesFiltered(org string, pathPrefix string) ([]Policy, error) {
-	t.ArgsIn[GetPoliciesFilteredMethod][0] = org
-	t.ArgsIn[GetPoliciesFilteredMethod][1] = pathPrefix
-
+func (t TestRepo) GetPoliciesFiltered(filter *Filter) ([]Policy, int, error) {
+	t.ArgsIn[GetPoliciesFilteredMethod][0] = filter
 	var policies []Policy
 	if t.ArgsOut[GetPoliciesFilteredMethod][0] != nil {
 		policies = t.ArgsOut[GetPoliciesFilteredMethod][0].([]Policy)
 	}
-	var err error
+	var total int
 	if t.ArgsOut[GetPoliciesFilteredMethod][1] != nil {
-		err = t.ArgsOut[GetPoliciesFilteredMethod][1].(error)
+		total = t.ArgsOut[GetPoliciesFilteredMethod][1].(int)
 	}
-	return policies, err
+	var err error
+	if t.ArgsOut[GetPoliciesFilteredMethod][2] != nil {
+		err = t.ArgsOut[GetPoliciesFilteredMethod][2].(error)
+	}
+	return policies, total, err
 }
 
-func (t TestRepo) GetAttachedGroups(policyID string) ([]Group, error) {
+func (t TestRepo) GetAttachedGroups(policyID string, filter *Filter) ([]Group, int, error) {
 	t.ArgsIn[GetAttachedGroupsMethod][0] = policyID
-
+	t.ArgsIn[GetAttachedGroupsMethod][1] = filter
 	var groups []Group
 	if t.ArgsOut[GetAttachedGroupsMethod][0] != nil {
 		groups = t.ArgsOut[GetAttachedGroupsMethod][0].([]Group)
 	}
-	var err error
+	var total int
 	if t.ArgsOut[GetAttachedGroupsMethod][1] != nil {
-		err = t.ArgsOut[GetAttachedGroupsMethod][1].(error)
+		total = t.ArgsOut[GetAttachedGroupsMethod][1].(int)
+	}
+	var err error
+	if t.ArgsOut[GetAttachedGroupsMethod][2] != nil {
+		err = t.ArgsOut[GetAttachedGroupsMethod][2].(error)
+	}
+	return groups, total, err
+}
+
+func (t TestRepo) GetPoliciesReferencingUrn(urn string) ([]Policy, error) {
+	t.ArgsIn[GetPoliciesReferencingUrnMethod][0] = urn
+	var policies []Policy
+	if t.ArgsOut[GetPoliciesReferencingUrnMethod][0] != nil {
+		policies = t.ArgsOut[GetPoliciesReferencingUrnMethod][0].([]Policy)
+	}
+	var err error
+	if t.ArgsOut[GetPoliciesReferencingUrnMethod][1] != nil {
+		err = t.ArgsOut[GetPoliciesReferencingUrnMethod][1].(error)
+	}
+	return policies, err
+}
+
+//////////////////
+// Access request repo
+//////////////////
+
+func (t TestRepo) AddAccessRequest(accessRequest AccessRequest) (*AccessRequest, error) {
+	t.ArgsIn[AddAccessRequestMethod][0] = accessRequest
+	var created *AccessRequest
+	if t.ArgsOut[AddAccessRequestMethod][0] != nil {
+		created = t.ArgsOut[AddAccessRequestMethod][0].(*AccessRequest)
+	}
+	var err error
+	if t.ArgsOut[AddAccessRequestMethod][1] != nil {
+		err = t.ArgsOut[AddAccessRequestMethod][1].(error)
+	}
+	return created, err
+}
+
+func (t TestRepo) GetAccessRequestByID(id string) (*AccessRequest, error) {
+	t.ArgsIn[GetAccessRequestByIDMethod][0] = id
+	var accessRequest *AccessRequest
+	if t.ArgsOut[GetAccessRequestByIDMethod][0] != nil {
+		accessRequest = t.ArgsOut[GetAccessRequestByIDMethod][0].(*AccessRequest)
+	}
+	var err error
+	if t.ArgsOut[GetAccessRequestByIDMethod][1] != nil {
+		err = t.ArgsOut[GetAccessRequestByIDMethod][1].(error)
+	}
+	return accessRequest, err
+}
+
+func (t TestRepo) GetAccessRequestsFiltered(org string, status string) ([]AccessRequest, error) {
+	t.ArgsIn[GetAccessRequestsFilteredMethod][0] = org
+	t.ArgsIn[GetAccessRequestsFilteredMethod][1] = status
+
+	var accessRequests []AccessRequest
+	if t.ArgsOut[GetAccessRequestsFilteredMethod][0] != nil {
+		accessRequests = t.ArgsOut[GetAccessRequestsFilteredMethod][0].([]AccessRequest)
+	}
+	var err error
+	if t.ArgsOut[GetAccessRequestsFilteredMethod][1] != nil {
+		err = t.ArgsOut[GetAccessRequestsFilteredMethod][1].(error)
+	}
+	return accessRequests, err
+}
+
+func (t TestRepo) UpdateAccessRequest(accessRequest AccessRequest) (*AccessRequest, error) {
+	t.ArgsIn[UpdateAccessRequestMethod][0] = accessRequest
+	var updated *AccessRequest
+	if t.ArgsOut[UpdateAccessRequestMethod][0] != nil {
+		updated = t.ArgsOut[UpdateAccessRequestMethod][0].(*AccessRequest)
+	}
+	var err error
+	if t.ArgsOut[UpdateAccessRequestMethod][1] != nil {
+		err = t.ArgsOut[UpdateAccessRequestMethod][1].(error)
+	}
+	return updated, err
+}
+
+func (t TestRepo) UpdateAccessRequestsRequester(requester string, newRequester string) error {
+	t.ArgsIn[UpdateAccessRequestsRequesterMethod][0] = requester
+	t.ArgsIn[UpdateAccessRequestsRequesterMethod][1] = newRequester
+	var err error
+	if t.ArgsOut[UpdateAccessRequestsRequesterMethod][0] != nil {
+		err = t.ArgsOut[UpdateAccessRequestsRequesterMethod][0].(error)
+	}
+	return err
+}
+
+//////////////////
+// Action repo
+//////////////////
+
+func (t TestRepo) AddNamespace(namespace Namespace) (*Namespace, error) {
+	t.ArgsIn[AddNamespaceMethod][0] = namespace
+	var created *Namespace
+	if t.ArgsOut[AddNamespaceMethod][0] != nil {
+		created = t.ArgsOut[AddNamespaceMethod][0].(*Namespace)
+	}
+	var err error
+	if t.ArgsOut[AddNamespaceMethod][1] != nil {
+		err = t.ArgsOut[AddNamespaceMethod][1].(error)
+	}
+	return created, err
+}
+
+func (t TestRepo) GetNamespaceByName(name string) (*Namespace, error) {
+	t.ArgsIn[GetNamespaceByNameMethod][0] = name
+	var namespace *Namespace
+	if t.ArgsOut[GetNamespaceByNameMethod][0] != nil {
+		namespace = t.ArgsOut[GetNamespaceByNameMethod][0].(*Namespace)
+	}
+	var err error
+	if t.ArgsOut[GetNamespaceByNameMethod][1] != nil {
+		err = t.ArgsOut[GetNamespaceByNameMethod][1].(error)
+	}
+	return namespace, err
+}
+
+func (t TestRepo) GetNamespaces() ([]Namespace, error) {
+	var namespaces []Namespace
+	if t.ArgsOut[GetNamespacesMethod][0] != nil {
+		namespaces = t.ArgsOut[GetNamespacesMethod][0].([]Namespace)
+	}
+	var err error
+	if t.ArgsOut[GetNamespacesMethod][1] != nil {
+		err = t.ArgsOut[GetNamespacesMethod][1].(error)
+	}
+	return namespaces, err
+}
+
+func (t TestRepo) RemoveNamespace(name string) error {
+	t.ArgsIn[RemoveNamespaceMethod][0] = name
+	var err error
+	if t.ArgsOut[RemoveNamespaceMethod][0] != nil {
+		err = t.ArgsOut[RemoveNamespaceMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestRepo) AddAction(action Action) (*Action, error) {
+	t.ArgsIn[AddActionMethod][0] = action
+	var created *Action
+	if t.ArgsOut[AddActionMethod][0] != nil {
+		created = t.ArgsOut[AddActionMethod][0].(*Action)
+	}
+	var err error
+	if t.ArgsOut[AddActionMethod][1] != nil {
+		err = t.ArgsOut[AddActionMethod][1].(error)
+	}
+	return created, err
+}
+
+func (t TestRepo) GetActionByName(namespace string, name string) (*Action, error) {
+	t.ArgsIn[GetActionByNameMethod][0] = namespace
+	t.ArgsIn[GetActionByNameMethod][1] = name
+	var action *Action
+	if t.ArgsOut[GetActionByNameMethod][0] != nil {
+		action = t.ArgsOut[GetActionByNameMethod][0].(*Action)
+	}
+	var err error
+	if t.ArgsOut[GetActionByNameMethod][1] != nil {
+		err = t.ArgsOut[GetActionByNameMethod][1].(error)
+	}
+	return action, err
+}
+
+func (t TestRepo) GetActionsFiltered(namespace string) ([]Action, error) {
+	t.ArgsIn[GetActionsFilteredMethod][0] = namespace
+	var actions []Action
+	if t.ArgsOut[GetActionsFilteredMethod][0] != nil {
+		actions = t.ArgsOut[GetActionsFilteredMethod][0].([]Action)
+	}
+	var err error
+	if t.ArgsOut[GetActionsFilteredMethod][1] != nil {
+		err = t.ArgsOut[GetActionsFilteredMethod][1].(error)
+	}
+	return actions, err
+}
+
+func (t TestRepo) RemoveAction(id string) error {
+	t.ArgsIn[RemoveActionMethod][0] = id
+	var err error
+	if t.ArgsOut[RemoveActionMethod][0] != nil {
+		err = t.ArgsOut[RemoveActionMethod][0].(error)
+	}
+	return err
+}
+
+//////////////////
+// Resource type repo
+//////////////////
+
+func (t TestRepo) AddResourceType(resourceType ResourceType) (*ResourceType, error) {
+	t.ArgsIn[AddResourceTypeMethod][0] = resourceType
+	var created *ResourceType
+	if t.ArgsOut[AddResourceTypeMethod][0] != nil {
+		created = t.ArgsOut[AddResourceTypeMethod][0].(*ResourceType)
+	}
+	var err error
+	if t.ArgsOut[AddResourceTypeMethod][1] != nil {
+		err = t.ArgsOut[AddResourceTypeMethod][1].(error)
+	}
+	return created, err
+}
+
+func (t TestRepo) GetResourceTypeByName(namespace string, name string) (*ResourceType, error) {
+	t.ArgsIn[GetResourceTypeByNameMethod][0] = namespace
+	t.ArgsIn[GetResourceTypeByNameMethod][1] = name
+	var resourceType *ResourceType
+	if t.ArgsOut[GetResourceTypeByNameMethod][0] != nil {
+		resourceType = t.ArgsOut[GetResourceTypeByNameMethod][0].(*ResourceType)
+	}
+	var err error
+	if t.ArgsOut[GetResourceTypeByNameMethod][1] != nil {
+		err = t.ArgsOut[GetResourceTypeByNameMethod][1].(error)
+	}
+	return resourceType, err
+}
+
+func (t TestRepo) GetResourceTypesFiltered(namespace string) ([]ResourceType, error) {
+	t.ArgsIn[GetResourceTypesFilteredMethod][0] = namespace
+	var resourceTypes []ResourceType
+	if t.ArgsOut[GetResourceTypesFilteredMethod][0] != nil {
+		resourceTypes = t.ArgsOut[GetResourceTypesFilteredMethod][0].([]ResourceType)
+	}
+	var err error
+	if t.ArgsOut[GetResourceTypesFilteredMethod][1] != nil {
+		err = t.ArgsOut[GetResourceTypesFilteredMethod][1].(error)
+	}
+	return resourceTypes, err
+}
+
+func (t TestRepo) RemoveResourceType(id string) error {
+	t.ArgsIn[RemoveResourceTypeMethod][0] = id
+	var err error
+	if t.ArgsOut[RemoveResourceTypeMethod][0] != nil {
+		err = t.ArgsOut[RemoveResourceTypeMethod][0].(error)
+	}
+	return err
+}
+
+//////////////////
+// Tag repo
+//////////////////
+
+func (t TestRepo) SetTag(resourceID string, key string, value string) error {
+	t.ArgsIn[SetTagMethod][0] = resourceID
+	t.ArgsIn[SetTagMethod][1] = key
+	t.ArgsIn[SetTagMethod][2] = value
+	var err error
+	if t.ArgsOut[SetTagMethod][0] != nil {
+		err = t.ArgsOut[SetTagMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestRepo) RemoveTag(resourceID string, key string) error {
+	t.ArgsIn[RemoveTagMethod][0] = resourceID
+	t.ArgsIn[RemoveTagMethod][1] = key
+	var err error
+	if t.ArgsOut[RemoveTagMethod][0] != nil {
+		err = t.ArgsOut[RemoveTagMethod][0].(error)
+	}
+	return err
+}
+
+//////////////////
+// Trash repo
+//////////////////
+
+func (t TestRepo) GetDeletedResourcesFiltered(resourceType string, filter *Filter) ([]DeletedResource, int, error) {
+	t.ArgsIn[GetDeletedResourcesFilteredMethod][0] = resourceType
+	t.ArgsIn[GetDeletedResourcesFilteredMethod][1] = filter
+	var deletedResources []DeletedResource
+	if t.ArgsOut[GetDeletedResourcesFilteredMethod][0] != nil {
+		deletedResources = t.ArgsOut[GetDeletedResourcesFilteredMethod][0].([]DeletedResource)
+	}
+	var total int
+	if t.ArgsOut[GetDeletedResourcesFilteredMethod][1] != nil {
+		total = t.ArgsOut[GetDeletedResourcesFilteredMethod][1].(int)
+	}
+	var err error
+	if t.ArgsOut[GetDeletedResourcesFilteredMethod][2] != nil {
+		err = t.ArgsOut[GetDeletedResourcesFilteredMethod][2].(error)
+	}
+	return deletedResources, total, err
+}
+
+func (t TestRepo) GetDeletedResourceByID(id string) (*DeletedResource, error) {
+	t.ArgsIn[GetDeletedResourceByIDMethod][0] = id
+	var deletedResource *DeletedResource
+	if t.ArgsOut[GetDeletedResourceByIDMethod][0] != nil {
+		deletedResource = t.ArgsOut[GetDeletedResourceByIDMethod][0].(*DeletedResource)
+	}
+	var err error
+	if t.ArgsOut[GetDeletedResourceByIDMethod][1] != nil {
+		err = t.ArgsOut[GetDeletedResourceByIDMethod][1].(error)
+	}
+	return deletedResource, err
+}
+
+func (t TestRepo) RestoreDeletedResource(id string) error {
+	t.ArgsIn[RestoreDeletedResourceMethod][0] = id
+	var err error
+	if t.ArgsOut[RestoreDeletedResourceMethod][0] != nil {
+		err = t.ArgsOut[RestoreDeletedResourceMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestRepo) PurgeDeletedResources(deletedBefore time.Time) (int, error) {
+	t.ArgsIn[PurgeDeletedResourcesMethod][0] = deletedBefore
+	var purged int
+	if t.ArgsOut[PurgeDeletedResourcesMethod][0] != nil {
+		purged = t.ArgsOut[PurgeDeletedResourcesMethod][0].(int)
+	}
+	var err error
+	if t.ArgsOut[PurgeDeletedResourcesMethod][1] != nil {
+		err = t.ArgsOut[PurgeDeletedResourcesMethod][1].(error)
+	}
+	return purged, err
+}
+
+//////////////////
+// Consistency repo
+//////////////////
+
+func (t TestRepo) GetConsistencyReport() (*ConsistencyReport, error) {
+	var report *ConsistencyReport
+	if t.ArgsOut[GetConsistencyReportMethod][0] != nil {
+		report = t.ArgsOut[GetConsistencyReportMethod][0].(*ConsistencyReport)
+	}
+	var err error
+	if t.ArgsOut[GetConsistencyReportMethod][1] != nil {
+		err = t.ArgsOut[GetConsistencyReportMethod][1].(error)
+	}
+	return report, err
+}
+
+//////////////////
+// Audit repo
+//////////////////
+
+func (t TestRepo) AddAuditEvent(event AuditEvent) (*AuditEvent, error) {
+	t.ArgsIn[AddAuditEventMethod][0] = event
+	var created *AuditEvent
+	if t.ArgsOut[AddAuditEventMethod][0] != nil {
+		created = t.ArgsOut[AddAuditEventMethod][0].(*AuditEvent)
+	}
+	var err error
+	if t.ArgsOut[AddAuditEventMethod][1] != nil {
+		err = t.ArgsOut[AddAuditEventMethod][1].(error)
+	}
+	return created, err
+}
+
+func (t TestRepo) GetAuditEventsFiltered(actor string, urn string, action string, filter *Filter) ([]AuditEvent, int, error) {
+	t.ArgsIn[GetAuditEventsFilteredMethod][0] = actor
+	t.ArgsIn[GetAuditEventsFilteredMethod][1] = urn
+	t.ArgsIn[GetAuditEventsFilteredMethod][2] = action
+	t.ArgsIn[GetAuditEventsFilteredMethod][3] = filter
+	var events []AuditEvent
+	if t.ArgsOut[GetAuditEventsFilteredMethod][0] != nil {
+		events = t.ArgsOut[GetAuditEventsFilteredMethod][0].([]AuditEvent)
+	}
+	var total int
+	if t.ArgsOut[GetAuditEventsFilteredMethod][1] != nil {
+		total = t.ArgsOut[GetAuditEventsFilteredMethod][1].(int)
+	}
+	var err error
+	if t.ArgsOut[GetAuditEventsFilteredMethod][2] != nil {
+		err = t.ArgsOut[GetAuditEventsFilteredMethod][2].(error)
+	}
+	return events, total, err
+}
+
+//////////////////
+// Webhook repo
+//////////////////
+
+func (t TestRepo) AddWebhook(webhook Webhook) (*Webhook, error) {
+	t.ArgsIn[AddWebhookMethod][0] = webhook
+	var created *Webhook
+	if t.ArgsOut[AddWebhookMethod][0] != nil {
+		created = t.ArgsOut[AddWebhookMethod][0].(*Webhook)
+	}
+	var err error
+	if t.ArgsOut[AddWebhookMethod][1] != nil {
+		err = t.ArgsOut[AddWebhookMethod][1].(error)
+	}
+	return created, err
+}
+
+func (t TestRepo) GetWebhookByID(id string) (*Webhook, error) {
+	t.ArgsIn[GetWebhookByIDMethod][0] = id
+	if specialFunc, ok := t.SpecialFuncs[GetWebhookByIDMethod].(func(id string) (*Webhook, error)); ok && specialFunc != nil {
+		return specialFunc(id)
+	}
+	var webhook *Webhook
+	if t.ArgsOut[GetWebhookByIDMethod][0] != nil {
+		webhook = t.ArgsOut[GetWebhookByIDMethod][0].(*Webhook)
+	}
+	var err error
+	if t.ArgsOut[GetWebhookByIDMethod][1] != nil {
+		err = t.ArgsOut[GetWebhookByIDMethod][1].(error)
+	}
+	return webhook, err
+}
+
+func (t TestRepo) GetWebhooksFiltered(filter *Filter) ([]Webhook, int, error) {
+	t.ArgsIn[GetWebhooksFilteredMethod][0] = filter
+	var webhooks []Webhook
+	if t.ArgsOut[GetWebhooksFilteredMethod][0] != nil {
+		webhooks = t.ArgsOut[GetWebhooksFilteredMethod][0].([]Webhook)
+	}
+	var total int
+	if t.ArgsOut[GetWebhooksFilteredMethod][1] != nil {
+		total = t.ArgsOut[GetWebhooksFilteredMethod][1].(int)
+	}
+	var err error
+	if t.ArgsOut[GetWebhooksFilteredMethod][2] != nil {
+		err = t.ArgsOut[GetWebhooksFilteredMethod][2].(error)
+	}
+	return webhooks, total, err
+}
+
+func (t TestRepo) UpdateWebhook(webhook Webhook) (*Webhook, error) {
+	t.ArgsIn[UpdateWebhookMethod][0] = webhook
+	var updated *Webhook
+	if t.ArgsOut[UpdateWebhookMethod][0] != nil {
+		updated = t.ArgsOut[UpdateWebhookMethod][0].(*Webhook)
+	}
+	var err error
+	if t.ArgsOut[UpdateWebhookMethod][1] != nil {
+		err = t.ArgsOut[UpdateWebhookMethod][1].(error)
+	}
+	return updated, err
+}
+
+func (t TestRepo) RemoveWebhook(id string) error {
+	t.ArgsIn[RemoveWebhookMethod][0] = id
+	var err error
+	if t.ArgsOut[RemoveWebhookMethod][0] != nil {
+		err = t.ArgsOut[RemoveWebhookMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestRepo) AddWebhookDelivery(delivery WebhookDelivery) (*WebhookDelivery, error) {
+	t.ArgsIn[AddWebhookDeliveryMethod][0] = delivery
+	if specialFunc, ok := t.SpecialFuncs[AddWebhookDeliveryMethod].(func(delivery WebhookDelivery) (*WebhookDelivery, error)); ok && specialFunc != nil {
+		return specialFunc(delivery)
+	}
+	var created *WebhookDelivery
+	if t.ArgsOut[AddWebhookDeliveryMethod][0] != nil {
+		created = t.ArgsOut[AddWebhookDeliveryMethod][0].(*WebhookDelivery)
+	}
+	var err error
+	if t.ArgsOut[AddWebhookDeliveryMethod][1] != nil {
+		err = t.ArgsOut[AddWebhookDeliveryMethod][1].(error)
+	}
+	return created, err
+}
+
+func (t TestRepo) UpdateWebhookDelivery(delivery WebhookDelivery) (*WebhookDelivery, error) {
+	t.ArgsIn[UpdateWebhookDeliveryMethod][0] = delivery
+	if specialFunc, ok := t.SpecialFuncs[UpdateWebhookDeliveryMethod].(func(delivery WebhookDelivery) (*WebhookDelivery, error)); ok && specialFunc != nil {
+		return specialFunc(delivery)
+	}
+	var updated *WebhookDelivery
+	if t.ArgsOut[UpdateWebhookDeliveryMethod][0] != nil {
+		updated = t.ArgsOut[UpdateWebhookDeliveryMethod][0].(*WebhookDelivery)
+	}
+	var err error
+	if t.ArgsOut[UpdateWebhookDeliveryMethod][1] != nil {
+		err = t.ArgsOut[UpdateWebhookDeliveryMethod][1].(error)
+	}
+	return updated, err
+}
+
+func (t TestRepo) GetWebhookDeliveriesFiltered(webhookID string, status string, filter *Filter) ([]WebhookDelivery, int, error) {
+	t.ArgsIn[GetWebhookDeliveriesFilteredMethod][0] = webhookID
+	t.ArgsIn[GetWebhookDeliveriesFilteredMethod][1] = status
+	t.ArgsIn[GetWebhookDeliveriesFilteredMethod][2] = filter
+	var deliveries []WebhookDelivery
+	if t.ArgsOut[GetWebhookDeliveriesFilteredMethod][0] != nil {
+		deliveries = t.ArgsOut[GetWebhookDeliveriesFilteredMethod][0].([]WebhookDelivery)
+	}
+	var total int
+	if t.ArgsOut[GetWebhookDeliveriesFilteredMethod][1] != nil {
+		total = t.ArgsOut[GetWebhookDeliveriesFilteredMethod][1].(int)
+	}
+	var err error
+	if t.ArgsOut[GetWebhookDeliveriesFilteredMethod][2] != nil {
+		err = t.ArgsOut[GetWebhookDeliveriesFilteredMethod][2].(error)
+	}
+	return deliveries, total, err
+}
+
+func (t TestRepo) ClaimPendingWebhookDeliveries(before time.Time, leaseUntil time.Time, limit int) ([]WebhookDelivery, error) {
+	t.ArgsIn[ClaimPendingWebhookDeliveriesMethod][0] = before
+	t.ArgsIn[ClaimPendingWebhookDeliveriesMethod][1] = leaseUntil
+	t.ArgsIn[ClaimPendingWebhookDeliveriesMethod][2] = limit
+	var deliveries []WebhookDelivery
+	if t.ArgsOut[ClaimPendingWebhookDeliveriesMethod][0] != nil {
+		deliveries = t.ArgsOut[ClaimPendingWebhookDeliveriesMethod][0].([]WebhookDelivery)
+	}
+	var err error
+	if t.ArgsOut[ClaimPendingWebhookDeliveriesMethod][1] != nil {
+		err = t.ArgsOut[ClaimPendingWebhookDeliveriesMethod][1].(error)
+	}
+	return deliveries, err
+}
+
+//////////////////
+// Organization repo
+//////////////////
+
+func (t TestRepo) AddOrganization(organization Organization) (*Organization, error) {
+	t.ArgsIn[AddOrganizationMethod][0] = organization
+	var created *Organization
+	if t.ArgsOut[AddOrganizationMethod][0] != nil {
+		created = t.ArgsOut[AddOrganizationMethod][0].(*Organization)
+	}
+	var err error
+	if t.ArgsOut[AddOrganizationMethod][1] != nil {
+		err = t.ArgsOut[AddOrganizationMethod][1].(error)
+	}
+	return created, err
+}
+
+func (t TestRepo) GetOrganizationByName(name string) (*Organization, error) {
+	t.ArgsIn[GetOrganizationByNameMethod][0] = name
+	var organization *Organization
+	if t.ArgsOut[GetOrganizationByNameMethod][0] != nil {
+		organization = t.ArgsOut[GetOrganizationByNameMethod][0].(*Organization)
+	}
+	var err error
+	if t.ArgsOut[GetOrganizationByNameMethod][1] != nil {
+		err = t.ArgsOut[GetOrganizationByNameMethod][1].(error)
+	}
+	return organization, err
+}
+
+func (t TestRepo) GetOrganizationsFiltered(filter *Filter) ([]Organization, int, error) {
+	t.ArgsIn[GetOrganizationsFilteredMethod][0] = filter
+	var organizations []Organization
+	if t.ArgsOut[GetOrganizationsFilteredMethod][0] != nil {
+		organizations = t.ArgsOut[GetOrganizationsFilteredMethod][0].([]Organization)
+	}
+	var total int
+	if t.ArgsOut[GetOrganizationsFilteredMethod][1] != nil {
+		total = t.ArgsOut[GetOrganizationsFilteredMethod][1].(int)
+	}
+	var err error
+	if t.ArgsOut[GetOrganizationsFilteredMethod][2] != nil {
+		err = t.ArgsOut[GetOrganizationsFilteredMethod][2].(error)
+	}
+	return organizations, total, err
+}
+
+func (t TestRepo) UpdateOrganization(organization Organization, newDescription string) (*Organization, error) {
+	t.ArgsIn[UpdateOrganizationMethod][0] = organization
+	t.ArgsIn[UpdateOrganizationMethod][1] = newDescription
+	var updated *Organization
+	if t.ArgsOut[UpdateOrganizationMethod][0] != nil {
+		updated = t.ArgsOut[UpdateOrganizationMethod][0].(*Organization)
+	}
+	var err error
+	if t.ArgsOut[UpdateOrganizationMethod][1] != nil {
+		err = t.ArgsOut[UpdateOrganizationMethod][1].(error)
+	}
+	return updated, err
+}
+
+func (t TestRepo) RemoveOrganization(id string) error {
+	t.ArgsIn[RemoveOrganizationMethod][0] = id
+	var err error
+	if t.ArgsOut[RemoveOrganizationMethod][0] != nil {
+		err = t.ArgsOut[RemoveOrganizationMethod][0].(error)
+	}
+	return err
+}
+
+//////////////////
+// Transaction repo
+//////////////////
+
+// Functions run with the test repo itself, so operations see the same results
+func (t TestRepo) RunInTransaction(fn func(repo Repo) error) error {
+	if t.ArgsOut[RunInTransactionMethod][0] != nil {
+		return t.ArgsOut[RunInTransactionMethod][0].(error)
 	}
-	return groups, err
+	return fn(t)
 }
 
 // Private helper methods
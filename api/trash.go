@@ -0,0 +1,241 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tecsisa/foulkon/database"
+)
+
+// TYPE DEFINITIONS
+
+// User, group or policy removed with its relationships and tags. It's kept in the trash, where it
+// can be restored, until its retention period is over and it's purged. Name is the external ID for users
+type DeletedResource struct {
+	ID        string    `json:"id, omitempty"`
+	Type      string    `json:"type, omitempty"`
+	Org       string    `json:"org, omitempty"`
+	Name      string    `json:"name, omitempty"`
+	Urn       string    `json:"urn, omitempty"`
+	DeletedAt time.Time `json:"deletedAt, omitempty"`
+	PurgeAt   time.Time `json:"purgeAt, omitempty"`
+}
+
+func (d DeletedResource) String() string {
+	return fmt.Sprintf("[id: %v, type: %v, org: %v, name: %v, urn: %v, deletedAt: %v]",
+		d.ID, d.Type, d.Org, d.Name, d.Urn, d.DeletedAt.Format("2006-01-02 15:04:05 MST"))
+}
+
+// TRASH API IMPLEMENTATION
+
+func (api AuthAPI) ListDeletedResources(requestInfo RequestInfo, resourceType string, filter *Filter) ([]DeletedResource, int, error) {
+	if err := checkTrashAdmin(requestInfo); err != nil {
+		return nil, 0, err
+	}
+
+	// Validate fields
+	switch resourceType {
+	case "", RESOURCE_USER, RESOURCE_GROUP, RESOURCE_POLICY:
+	default:
+		return nil, 0, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: type %v", resourceType),
+		}
+	}
+	if err := validateFilter(filter); err != nil {
+		return nil, 0, err
+	}
+
+	// Call repo to retrieve the deleted resources
+	deletedResources, total, err := api.TrashRepo.GetDeletedResourcesFiltered(resourceType, filter)
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, 0, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	for i := range deletedResources {
+		deletedResources[i].PurgeAt = deletedResources[i].DeletedAt.Add(api.TrashRetention)
+	}
+
+	return deletedResources, total, nil
+}
+
+func (api AuthAPI) GetDeletedResource(requestInfo RequestInfo, id string) (*DeletedResource, error) {
+	if err := checkTrashAdmin(requestInfo); err != nil {
+		return nil, err
+	}
+
+	// Call repo to retrieve the deleted resource
+	deletedResource, err := api.TrashRepo.GetDeletedResourceByID(id)
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		// Deleted resource doesn't exist in DB
+		switch dbError.Code {
+		case database.DELETED_RESOURCE_NOT_FOUND:
+			return nil, &Error{
+				Code:    DELETED_RESOURCE_NOT_FOUND,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	deletedResource.PurgeAt = deletedResource.DeletedAt.Add(api.TrashRetention)
+	return deletedResource, nil
+}
+
+func (api AuthAPI) RestoreDeletedResource(requestInfo RequestInfo, id string) (*DeletedResource, error) {
+	// Call repo to retrieve the deleted resource
+	deletedResource, err := api.GetDeletedResource(requestInfo, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check that the organization still exists and the name hasn't been taken since the resource was removed
+	if deletedResource.Type != RESOURCE_USER {
+		if err := api.checkOrganizationExists(deletedResource.Org); err != nil {
+			return nil, err
+		}
+	}
+	if err := api.checkDeletedResourceName(*deletedResource); err != nil {
+		return nil, err
+	}
+
+	err = api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		if err := repo.RestoreDeletedResource(id); err != nil {
+			return err
+		}
+		return api.auditOperation(repo, requestInfo, AUDIT_ACTION_RESTORE_DELETED_RESOURCE, deletedResource.Urn, nil, deletedResource)
+	})
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.DELETED_RESOURCE_NOT_FOUND:
+			return nil, &Error{
+				Code:    DELETED_RESOURCE_NOT_FOUND,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Deleted resource restored %+v", deletedResource))
+	return deletedResource, nil
+}
+
+func (api AuthAPI) PurgeDeletedResources(requestInfo RequestInfo) (int, error) {
+	if err := checkTrashAdmin(requestInfo); err != nil {
+		return 0, err
+	}
+
+	// Call repo to purge deleted resources whose retention period is over
+	deletedBefore := time.Now().UTC().Add(-api.TrashRetention)
+	var purged int
+	err := api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		var err error
+		purged, err = repo.PurgeDeletedResources(deletedBefore)
+		if err != nil || purged == 0 {
+			return err
+		}
+		// Purged resources aren't identified one by one, so the event has no URN
+		return api.auditOperation(repo, requestInfo, AUDIT_ACTION_PURGE_DELETED_RESOURCES, "", map[string]interface{}{
+			"purged":        purged,
+			"deletedBefore": deletedBefore,
+		}, nil)
+	})
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return 0, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	if purged > 0 {
+		LogOperation(api.Logger, requestInfo, fmt.Sprintf("%v deleted resources purged, deleted before %v",
+			purged, deletedBefore.Format(time.RFC3339)))
+	}
+	return purged, nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Only admin can manage the trash, because deleted resources don't belong to any organization
+// and their policies can't authorize them anymore
+func checkTrashAdmin(requestInfo RequestInfo) error {
+	if !requestInfo.Admin {
+		return &Error{
+			Code:    UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to manage deleted resources", requestInfo.Identifier),
+		}
+	}
+	return nil
+}
+
+// Check that there isn't a user, group or policy with the name of the deleted resource
+func (api AuthAPI) checkDeletedResourceName(deletedResource DeletedResource) error {
+	var err error
+	var alreadyExist *Error
+	switch deletedResource.Type {
+	case RESOURCE_USER:
+		_, err = api.UserRepo.GetUserByExternalID(deletedResource.Name)
+		alreadyExist = &Error{
+			Code:    USER_ALREADY_EXIST,
+			Message: fmt.Sprintf("Unable to restore user, user with externalId %v already exist", deletedResource.Name),
+		}
+	case RESOURCE_GROUP:
+		_, err = api.GroupRepo.GetGroupByName(deletedResource.Org, deletedResource.Name)
+		alreadyExist = &Error{
+			Code: GROUP_ALREADY_EXIST,
+			Message: fmt.Sprintf("Unable to restore group, group with org %v and name %v already exist",
+				deletedResource.Org, deletedResource.Name),
+		}
+	default:
+		_, err = api.PolicyRepo.GetPolicyByName(deletedResource.Org, deletedResource.Name)
+		alreadyExist = &Error{
+			Code: POLICY_ALREADY_EXIST,
+			Message: fmt.Sprintf("Unable to restore policy, policy with org %v and name %v already exist",
+				deletedResource.Org, deletedResource.Name),
+		}
+	}
+
+	// Resource name is taken
+	if err == nil {
+		return alreadyExist
+	}
+
+	//Transform to DB error
+	dbError := err.(*database.Error)
+	switch dbError.Code {
+	case database.USER_NOT_FOUND, database.GROUP_NOT_FOUND, database.POLICY_NOT_FOUND: // Resource name is free
+		return nil
+	default: // Unexpected error
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+}
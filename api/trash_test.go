@@ -0,0 +1,465 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestAuthAPI_ListDeletedResources(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo  RequestInfo
+		resourceType string
+		filter       *Filter
+		// Expected results
+		expectedResponse []DeletedResource
+		expectedTotal    int
+		wantError        error
+		// Manager Results
+		getDeletedResourcesFilteredMethodResult []DeletedResource
+		getDeletedResourcesFilteredMethodTotal  int
+		// Manager Errors
+		getDeletedResourcesFilteredMethodErr error
+	}{
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			resourceType: RESOURCE_GROUP,
+			filter: &Filter{
+				Org: "example",
+			},
+			expectedResponse: []DeletedResource{
+				{
+					ID:        "GROUP-ID",
+					Type:      RESOURCE_GROUP,
+					Org:       "example",
+					Name:      "group",
+					Urn:       CreateUrn("example", RESOURCE_GROUP, "/path/", "group"),
+					DeletedAt: now,
+					PurgeAt:   now.Add(time.Hour),
+				},
+			},
+			expectedTotal: 1,
+			getDeletedResourcesFilteredMethodResult: []DeletedResource{
+				{
+					ID:        "GROUP-ID",
+					Type:      RESOURCE_GROUP,
+					Org:       "example",
+					Name:      "group",
+					Urn:       CreateUrn("example", RESOURCE_GROUP, "/path/", "group"),
+					DeletedAt: now,
+				},
+			},
+			getDeletedResourcesFilteredMethodTotal: 1,
+		},
+		"ErrorCaseNotAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			filter: &Filter{},
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to manage deleted resources",
+			},
+		},
+		"ErrorCaseInvalidType": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			resourceType: "invalid",
+			filter:       &Filter{},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: type invalid",
+			},
+		},
+		"ErrorCaseInvalidOrg": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter: &Filter{
+				Org: "!*^**~$%&/()",
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: org !*^**~$%&/()",
+			},
+		},
+		"ErrorCaseInternalError": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter: &Filter{},
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getDeletedResourcesFilteredMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+		testAPI.TrashRetention = time.Hour
+
+		testRepo.ArgsOut[GetDeletedResourcesFilteredMethod][0] = testcase.getDeletedResourcesFilteredMethodResult
+		testRepo.ArgsOut[GetDeletedResourcesFilteredMethod][1] = testcase.getDeletedResourcesFilteredMethodTotal
+		testRepo.ArgsOut[GetDeletedResourcesFilteredMethod][2] = testcase.getDeletedResourcesFilteredMethodErr
+
+		deletedResources, total, err := testAPI.ListDeletedResources(testcase.requestInfo, testcase.resourceType, testcase.filter)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, deletedResources)
+		if testcase.wantError == nil && total != testcase.expectedTotal {
+			t.Errorf("Test %v failed. Received different totals (received/wanted) %v/%v", x, total, testcase.expectedTotal)
+		}
+	}
+}
+
+func TestAuthAPI_GetDeletedResource(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		id          string
+		// Expected results
+		expectedResponse *DeletedResource
+		wantError        error
+		// Manager Results
+		getDeletedResourceByIDMethodResult *DeletedResource
+		// Manager Errors
+		getDeletedResourceByIDMethodErr error
+	}{
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			id: "USER-ID",
+			expectedResponse: &DeletedResource{
+				ID:        "USER-ID",
+				Type:      RESOURCE_USER,
+				Name:      "1234",
+				Urn:       CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+				DeletedAt: now,
+				PurgeAt:   now.Add(time.Hour),
+			},
+			getDeletedResourceByIDMethodResult: &DeletedResource{
+				ID:        "USER-ID",
+				Type:      RESOURCE_USER,
+				Name:      "1234",
+				Urn:       CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+				DeletedAt: now,
+			},
+		},
+		"ErrorCaseNotAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			id: "USER-ID",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to manage deleted resources",
+			},
+		},
+		"ErrorCaseNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			id: "USER-ID",
+			wantError: &Error{
+				Code:    DELETED_RESOURCE_NOT_FOUND,
+				Message: "Deleted resource with id USER-ID not found",
+			},
+			getDeletedResourceByIDMethodErr: &database.Error{
+				Code:    database.DELETED_RESOURCE_NOT_FOUND,
+				Message: "Deleted resource with id USER-ID not found",
+			},
+		},
+		"ErrorCaseInternalError": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			id: "USER-ID",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getDeletedResourceByIDMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+		testAPI.TrashRetention = time.Hour
+
+		testRepo.ArgsOut[GetDeletedResourceByIDMethod][0] = testcase.getDeletedResourceByIDMethodResult
+		testRepo.ArgsOut[GetDeletedResourceByIDMethod][1] = testcase.getDeletedResourceByIDMethodErr
+
+		deletedResource, err := testAPI.GetDeletedResource(testcase.requestInfo, testcase.id)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, deletedResource)
+	}
+}
+
+func TestAuthAPI_RestoreDeletedResource(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		id          string
+		// Expected results
+		expectedResponse *DeletedResource
+		wantError        error
+		// Manager Results
+		getDeletedResourceByIDMethodResult *DeletedResource
+		// Manager Errors
+		getDeletedResourceByIDMethodErr error
+		getUserByExternalIDMethodErr    error
+		getGroupByNameMethodErr         error
+		getPolicyByNameMethodErr        error
+		restoreDeletedResourceMethodErr error
+		getOrganizationByNameMethodErr  error
+	}{
+		"OKCaseUser": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			id: "USER-ID",
+			expectedResponse: &DeletedResource{
+				ID:        "USER-ID",
+				Type:      RESOURCE_USER,
+				Name:      "1234",
+				DeletedAt: now,
+				PurgeAt:   now,
+			},
+			getDeletedResourceByIDMethodResult: &DeletedResource{
+				ID:        "USER-ID",
+				Type:      RESOURCE_USER,
+				Name:      "1234",
+				DeletedAt: now,
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code: database.USER_NOT_FOUND,
+			},
+		},
+		"OKCasePolicy": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			id: "POLICY-ID",
+			expectedResponse: &DeletedResource{
+				ID:        "POLICY-ID",
+				Type:      RESOURCE_POLICY,
+				Org:       "example",
+				Name:      "policy",
+				DeletedAt: now,
+				PurgeAt:   now,
+			},
+			getDeletedResourceByIDMethodResult: &DeletedResource{
+				ID:        "POLICY-ID",
+				Type:      RESOURCE_POLICY,
+				Org:       "example",
+				Name:      "policy",
+				DeletedAt: now,
+			},
+			getPolicyByNameMethodErr: &database.Error{
+				Code: database.POLICY_NOT_FOUND,
+			},
+		},
+		"ErrorCaseNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			id: "USER-ID",
+			wantError: &Error{
+				Code:    DELETED_RESOURCE_NOT_FOUND,
+				Message: "Deleted resource with id USER-ID not found",
+			},
+			getDeletedResourceByIDMethodErr: &database.Error{
+				Code:    database.DELETED_RESOURCE_NOT_FOUND,
+				Message: "Deleted resource with id USER-ID not found",
+			},
+		},
+		"ErrorCaseGroupAlreadyExist": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			id: "GROUP-ID",
+			wantError: &Error{
+				Code:    GROUP_ALREADY_EXIST,
+				Message: "Unable to restore group, group with org example and name group already exist",
+			},
+			getDeletedResourceByIDMethodResult: &DeletedResource{
+				ID:   "GROUP-ID",
+				Type: RESOURCE_GROUP,
+				Org:  "example",
+				Name: "group",
+			},
+		},
+		"ErrorCaseOrganizationNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			id: "GROUP-ID",
+			wantError: &Error{
+				Code:    ORGANIZATION_BY_NAME_NOT_FOUND,
+				Message: "Organization with name example not found",
+			},
+			getDeletedResourceByIDMethodResult: &DeletedResource{
+				ID:   "GROUP-ID",
+				Type: RESOURCE_GROUP,
+				Org:  "example",
+				Name: "group",
+			},
+			getOrganizationByNameMethodErr: &database.Error{
+				Code:    database.ORGANIZATION_NOT_FOUND,
+				Message: "Organization with name example not found",
+			},
+		},
+		"ErrorCaseGetGroupDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			id: "GROUP-ID",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getDeletedResourceByIDMethodResult: &DeletedResource{
+				ID:   "GROUP-ID",
+				Type: RESOURCE_GROUP,
+				Org:  "example",
+				Name: "group",
+			},
+			getGroupByNameMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+		"ErrorCaseRestoreDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			id: "USER-ID",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getDeletedResourceByIDMethodResult: &DeletedResource{
+				ID:   "USER-ID",
+				Type: RESOURCE_USER,
+				Name: "1234",
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code: database.USER_NOT_FOUND,
+			},
+			restoreDeletedResourceMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetDeletedResourceByIDMethod][0] = testcase.getDeletedResourceByIDMethodResult
+		testRepo.ArgsOut[GetDeletedResourceByIDMethod][1] = testcase.getDeletedResourceByIDMethodErr
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
+		testRepo.ArgsOut[GetGroupByNameMethod][1] = testcase.getGroupByNameMethodErr
+		testRepo.ArgsOut[GetPolicyByNameMethod][1] = testcase.getPolicyByNameMethodErr
+		testRepo.ArgsOut[RestoreDeletedResourceMethod][0] = testcase.restoreDeletedResourceMethodErr
+		testRepo.ArgsOut[GetOrganizationByNameMethod][1] = testcase.getOrganizationByNameMethodErr
+
+		deletedResource, err := testAPI.RestoreDeletedResource(testcase.requestInfo, testcase.id)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, deletedResource)
+	}
+}
+
+func TestAuthAPI_PurgeDeletedResources(t *testing.T) {
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		// Expected results
+		expectedResponse int
+		wantError        error
+		// Manager Results
+		purgeDeletedResourcesMethodResult int
+		// Manager Errors
+		purgeDeletedResourcesMethodErr error
+	}{
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			expectedResponse:                  2,
+			purgeDeletedResourcesMethodResult: 2,
+		},
+		"ErrorCaseNotAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to manage deleted resources",
+			},
+		},
+		"ErrorCaseInternalError": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			purgeDeletedResourcesMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+		testAPI.TrashRetention = time.Hour
+
+		testRepo.ArgsOut[PurgeDeletedResourcesMethod][0] = testcase.purgeDeletedResourcesMethodResult
+		testRepo.ArgsOut[PurgeDeletedResourcesMethod][1] = testcase.purgeDeletedResourcesMethodErr
+
+		before := time.Now().UTC().Add(-time.Hour)
+		purged, err := testAPI.PurgeDeletedResources(testcase.requestInfo)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, purged)
+		if testcase.wantError == nil {
+			if deletedBefore := testRepo.ArgsIn[PurgeDeletedResourcesMethod][0].(time.Time); deletedBefore.Before(before) {
+				t.Errorf("Test %v failed. Purged resources deleted before %v, older than retention period", x, deletedBefore)
+			}
+		}
+	}
+}
@@ -10,27 +10,75 @@ import (
 
 // TYPE DEFINITIONS
 
-// User domain
+// User domain. Suspended users keep their group memberships, but every action is denied to them
 type User struct {
-	ID         string    `json:"id, omitempty"`
-	ExternalID string    `json:"externalId, omitempty"`
-	Path       string    `json:"path, omitempty"`
-	Urn        string    `json:"urn, omitempty"`
-	CreateAt   time.Time `json:"createAt, omitempty"`
+	ID          string            `json:"id, omitempty"`
+	ExternalID  string            `json:"externalId, omitempty"`
+	Path        string            `json:"path, omitempty"`
+	Urn         string            `json:"urn, omitempty"`
+	CreateAt    time.Time         `json:"createAt, omitempty"`
+	DisplayName string            `json:"displayName, omitempty"`
+	Email       string            `json:"email, omitempty"`
+	Attributes  map[string]string `json:"attributes, omitempty"`
+	Status      string            `json:"status, omitempty"`
+	Tags        map[string]string `json:"tags, omitempty"`
+	// Revision of the stored user, updates fail if it changed after the user was retrieved
+	Revision int64 `json:"-"`
+}
+
+// Profile attributes of a user
+type UserProfile struct {
+	DisplayName string            `json:"displayName, omitempty"`
+	Email       string            `json:"email, omitempty"`
+	Attributes  map[string]string `json:"attributes, omitempty"`
+}
+
+// Changes to the profile attributes of a user. Nil fields keep their current value, so an empty
+// attributes map is needed to remove all of them
+type UserProfileUpdate struct {
+	DisplayName *string
+	Email       *string
+	Attributes  map[string]string
+}
+
+// Profile resulting of applying the changes to the given one
+func (update UserProfileUpdate) apply(profile UserProfile) UserProfile {
+	if update.DisplayName != nil {
+		profile.DisplayName = *update.DisplayName
+	}
+	if update.Email != nil {
+		profile.Email = *update.Email
+	}
+	if update.Attributes != nil {
+		profile.Attributes = update.Attributes
+	}
+	return profile
+}
+
+// Result of an externalId change. References are the policy statements that referenced the old user URN,
+// which have been changed to the new one if Rewritten is true
+type UserRename struct {
+	User       *User                `json:"user, omitempty"`
+	References []StatementReference `json:"references, omitempty"`
+	Rewritten  bool                 `json:"rewritten, omitempty"`
 }
 
 func (u User) String() string {
-	return fmt.Sprintf("[id: %v, externalId: %v, path: %v, urn: %v, createAt: %v]",
-		u.ID, u.ExternalID, u.Path, u.Urn, u.CreateAt.Format("2006-01-02 15:04:05 MST"))
+	return fmt.Sprintf("[id: %v, externalId: %v, path: %v, urn: %v, createAt: %v, displayName: %v, email: %v, status: %v]",
+		u.ID, u.ExternalID, u.Path, u.Urn, u.CreateAt.Format("2006-01-02 15:04:05 MST"), u.DisplayName, u.Email, u.Status)
 }
 
 func (u User) GetUrn() string {
 	return u.Urn
 }
 
+func (u User) GetTags() map[string]string {
+	return u.Tags
+}
+
 // USER API IMPLEMENTATION
 
-func (api AuthAPI) AddUser(requestInfo RequestInfo, externalId string, path string) (*User, error) {
+func (api AuthAPI) AddUser(requestInfo RequestInfo, externalId string, path string, profile UserProfile) (*User, error) {
 	// Validate fields
 	if !IsValidUserExternalID(externalId) {
 		return nil, &Error{
@@ -44,8 +92,14 @@ func (api AuthAPI) AddUser(requestInfo RequestInfo, externalId string, path stri
 			Message: fmt.Sprintf("Invalid parameter: path %v", path),
 		}
 	}
+	if err := validateUserProfile(profile); err != nil {
+		return nil, err
+	}
 
 	user := createUser(externalId, path)
+	user.DisplayName = profile.DisplayName
+	user.Email = profile.Email
+	user.Attributes = profile.Attributes
 
 	// Check restrictions
 	usersFiltered, err := api.GetAuthorizedUsers(requestInfo, user.Urn, USER_ACTION_CREATE_USER, []User{user})
@@ -70,7 +124,15 @@ func (api AuthAPI) AddUser(requestInfo RequestInfo, externalId string, path stri
 		switch dbError.Code {
 		case database.USER_NOT_FOUND:
 			// Create user
-			createdUser, err := api.UserRepo.AddUser(user)
+			var createdUser *User
+			err := api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+				var err error
+				createdUser, err = repo.AddUser(user)
+				if err != nil {
+					return err
+				}
+				return api.auditOperation(repo, requestInfo, USER_ACTION_CREATE_USER, createdUser.Urn, nil, createdUser)
+			})
 
 			// Check unexpected DB error
 			if err != nil {
@@ -143,104 +205,125 @@ func (api AuthAPI) GetUserByExternalID(requestInfo RequestInfo, externalId strin
 
 }
 
-func (api AuthAPI) ListUsers(requestInfo RequestInfo, pathPrefix string) ([]string, error) {
+func (api AuthAPI) ListUsers(requestInfo RequestInfo, filter *Filter) ([]User, int, error) {
 	// Check parameters
-	if len(pathPrefix) > 0 && !IsValidPath(pathPrefix) {
-		return nil, &Error{
-			Code:    INVALID_PARAMETER_ERROR,
-			Message: fmt.Sprintf("Invalid parameter: PathPrefix %v", pathPrefix),
-		}
-	}
-
-	if len(pathPrefix) == 0 {
-		pathPrefix = "/"
+	if err := validateFilter(filter); err != nil {
+		return nil, 0, err
 	}
 
-	// Retrieve users with specified path prefix
-	users, err := api.UserRepo.GetUsersFiltered(pathPrefix)
+	// Retrieve users with specified path prefix and tags
+	users, total, err := api.UserRepo.GetUsersFiltered(filter)
 
 	// Error handling
 	if err != nil {
 		//Transform to DB error
 		dbError := err.(*database.Error)
-		return nil, &Error{
+		return nil, 0, &Error{
 			Code:    UNKNOWN_API_ERROR,
 			Message: dbError.Message,
 		}
 	}
 
 	// Check restrictions
-	urnPrefix := GetUrnPrefix("", RESOURCE_USER, pathPrefix)
+	urnPrefix := GetUrnPrefix("", RESOURCE_USER, filter.PathPrefix)
 	usersFiltered, err := api.GetAuthorizedUsers(requestInfo, urnPrefix, USER_ACTION_LIST_USERS, users)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	// Return user IDs
-	externalIds := []string{}
-	for _, u := range usersFiltered {
-		externalIds = append(externalIds, u.ExternalID)
-	}
-
-	return externalIds, nil
+	return usersFiltered, total, nil
 }
 
-func (api AuthAPI) UpdateUser(requestInfo RequestInfo, externalId string, newPath string) (*User, error) {
+func (api AuthAPI) UpdateUser(requestInfo RequestInfo, externalId string, newPath string, profileUpdate UserProfileUpdate) (*User, []StatementReference, error) {
 	if !IsValidPath(newPath) {
-		return nil, &Error{
+		return nil, nil, &Error{
 			Code:    INVALID_PARAMETER_ERROR,
 			Message: fmt.Sprintf("Invalid parameter: path %v", newPath),
 		}
 	}
+	if err := validateUserProfile(profileUpdate.apply(UserProfile{})); err != nil {
+		return nil, nil, err
+	}
 
 	// Call repo to retrieve the user
 	userDB, err := api.GetUserByExternalID(requestInfo, externalId)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Check restrictions
 	usersFiltered, err := api.GetAuthorizedUsers(requestInfo, userDB.Urn, USER_ACTION_UPDATE_USER, []User{*userDB})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if len(usersFiltered) < 1 {
-		return nil, &Error{
+		return nil, nil, &Error{
 			Code: UNAUTHORIZED_RESOURCES_ERROR,
 			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
 				requestInfo.Identifier, userDB.Urn),
 		}
 	}
 
+	// Check that the user hasn't been modified since the client retrieved it
+	if err := checkIfMatch(requestInfo, *userDB); err != nil {
+		return nil, nil, err
+	}
+
 	userToUpdate := createUser(externalId, newPath)
+	newProfile := profileUpdate.apply(UserProfile{
+		DisplayName: userDB.DisplayName,
+		Email:       userDB.Email,
+		Attributes:  userDB.Attributes,
+	})
 
 	// Check restrictions
 	usersFiltered, err = api.GetAuthorizedUsers(requestInfo, userToUpdate.Urn, USER_ACTION_GET_USER, []User{userToUpdate})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if len(usersFiltered) < 1 {
-		return nil, &Error{
+		return nil, nil, &Error{
 			Code: UNAUTHORIZED_RESOURCES_ERROR,
 			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
 				requestInfo.Identifier, userToUpdate.Urn),
 		}
 	}
 
-	user, err := api.UserRepo.UpdateUser(*userDB, newPath, userToUpdate.Urn)
+	// Retrieve policies with statements that reference the user when its URN changes
+	policies, err := api.getReferencingPolicies(requestInfo, userDB.Urn, userToUpdate.Urn, requestInfo.RewriteReferences)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var user *User
+	err = api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		var err error
+		user, err = repo.UpdateUser(*userDB, newPath, userToUpdate.Urn, newProfile)
+		if err != nil {
+			return err
+		}
+		if err := api.auditOperation(repo, requestInfo, USER_ACTION_UPDATE_USER, user.Urn, userDB, user); err != nil {
+			return err
+		}
+		if !requestInfo.RewriteReferences {
+			return nil
+		}
+		return api.rewriteReferencingPolicies(repo, requestInfo, policies, userDB.Urn, userToUpdate.Urn)
+	})
 
 	// Check unexpected DB error
 	if err != nil {
-		//Transform to DB error
-		dbError := err.(*database.Error)
-		return nil, &Error{
-			Code:    UNKNOWN_API_ERROR,
-			Message: dbError.Message,
-		}
+		return nil, nil, toRevisionAPIError(err)
 	}
 
-	LogOperation(api.Logger, requestInfo, fmt.Sprintf("User updated from %+v to %+v", userDB, user))
-	return user, nil
+	references := []StatementReference{}
+	if !requestInfo.RewriteReferences {
+		references = getStatementReferences(policies, userDB.Urn)
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("User updated from %+v to %+v with %v referencing policies, rewritten: %v",
+		userDB, user, len(policies), requestInfo.RewriteReferences))
+	return user, references, nil
 
 }
 
@@ -264,35 +347,157 @@ func (api AuthAPI) RemoveUser(requestInfo RequestInfo, externalId string) error
 		}
 	}
 
-	err = api.UserRepo.RemoveUser(user.ID)
+	// Check that the user hasn't been modified since the client retrieved it
+	if err := checkIfMatch(requestInfo, *user); err != nil {
+		return err
+	}
+
+	err = api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		if err := repo.RemoveUser(*user); err != nil {
+			return err
+		}
+		return api.auditOperation(repo, requestInfo, USER_ACTION_DELETE_USER, user.Urn, user, nil)
+	})
 
 	// Error handling
 	if err != nil {
-		//Transform to DB error
-		dbError := err.(*database.Error)
-		return &Error{
-			Code:    UNKNOWN_API_ERROR,
-			Message: dbError.Message,
-		}
+		return toRevisionAPIError(err)
 	}
 	LogOperation(api.Logger, requestInfo, fmt.Sprintf("User deleted %+v", user))
 	return nil
 }
 
-func (api AuthAPI) ListGroupsByUser(requestInfo RequestInfo, externalId string) ([]GroupIdentity, error) {
+func (api AuthAPI) SuspendUser(requestInfo RequestInfo, externalId string) (*User, error) {
+	return api.updateUserStatus(requestInfo, externalId, USER_STATUS_SUSPENDED, USER_ACTION_SUSPEND_USER)
+}
+
+func (api AuthAPI) ReactivateUser(requestInfo RequestInfo, externalId string) (*User, error) {
+	return api.updateUserStatus(requestInfo, externalId, USER_STATUS_ACTIVE, USER_ACTION_REACTIVATE_USER)
+}
+
+func (api AuthAPI) RenameUser(requestInfo RequestInfo, externalId string, newExternalId string, rewritePolicies bool) (*UserRename, error) {
+	if !IsValidUserExternalID(newExternalId) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: newExternalId %v", newExternalId),
+		}
+	}
+
 	// Call repo to retrieve the user
-	user, err := api.GetUserByExternalID(requestInfo, externalId)
+	userDB, err := api.GetUserByExternalID(requestInfo, externalId)
 	if err != nil {
 		return nil, err
 	}
 
 	// Check restrictions
-	usersFiltered, err := api.GetAuthorizedUsers(requestInfo, user.Urn, USER_ACTION_LIST_GROUPS_FOR_USER, []User{*user})
+	usersFiltered, err := api.GetAuthorizedUsers(requestInfo, userDB.Urn, USER_ACTION_RENAME_USER, []User{*userDB})
+	if err != nil {
+		return nil, err
+	}
+	if len(usersFiltered) < 1 {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, userDB.Urn),
+		}
+	}
+
+	// Check that the user hasn't been modified since the client retrieved it
+	if err := checkIfMatch(requestInfo, *userDB); err != nil {
+		return nil, err
+	}
+
+	userToUpdate := createUser(newExternalId, userDB.Path)
+
+	// Check restrictions
+	usersFiltered, err = api.GetAuthorizedUsers(requestInfo, userToUpdate.Urn, USER_ACTION_RENAME_USER, []User{userToUpdate})
 	if err != nil {
 		return nil, err
 	}
 	if len(usersFiltered) < 1 {
 		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, userToUpdate.Urn),
+		}
+	}
+
+	// Check if new externalId is already taken
+	_, err = api.UserRepo.GetUserByExternalID(newExternalId)
+	if err == nil {
+		return nil, &Error{
+			Code:    USER_ALREADY_EXIST,
+			Message: fmt.Sprintf("Unable to rename user, user with externalId %v already exist", newExternalId),
+		}
+	}
+	if dbError := err.(*database.Error); dbError.Code != database.USER_NOT_FOUND {
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	// Retrieve policies with statements that reference the user
+	policies, err := api.getReferencingPolicies(requestInfo, userDB.Urn, userToUpdate.Urn, rewritePolicies)
+	if err != nil {
+		return nil, err
+	}
+
+	var user *User
+	err = api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		var err error
+		user, err = repo.UpdateUserExternalID(*userDB, newExternalId, userToUpdate.Urn)
+		if err != nil {
+			return err
+		}
+		if err := api.auditOperation(repo, requestInfo, USER_ACTION_RENAME_USER, user.Urn, userDB, user); err != nil {
+			return err
+		}
+		// Pending access requests of the user must still be reviewable under the new externalId
+		if err := repo.UpdateAccessRequestsRequester(externalId, newExternalId); err != nil {
+			return err
+		}
+		if !rewritePolicies {
+			return nil
+		}
+		return api.rewriteReferencingPolicies(repo, requestInfo, policies, userDB.Urn, userToUpdate.Urn)
+	})
+
+	// Error handling
+	if err != nil {
+		return nil, toRevisionAPIError(err)
+	}
+
+	rename := &UserRename{
+		User:       user,
+		References: getStatementReferences(policies, userDB.Urn),
+		Rewritten:  rewritePolicies,
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("User renamed from %+v to %+v with %v policy references, rewritten: %v",
+		userDB, user, len(rename.References), rewritePolicies))
+	return rename, nil
+}
+
+func (api AuthAPI) ListGroupsByUser(requestInfo RequestInfo, externalId string, filter *Filter) ([]GroupIdentity, int, error) {
+	// Validate filter
+	if err := validateFilter(filter); err != nil {
+		return nil, 0, err
+	}
+
+	// Call repo to retrieve the user
+	user, err := api.GetUserByExternalID(requestInfo, externalId)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Check restrictions
+	usersFiltered, err := api.GetAuthorizedUsers(requestInfo, user.Urn, USER_ACTION_LIST_GROUPS_FOR_USER, []User{*user})
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(usersFiltered) < 1 {
+		return nil, 0, &Error{
 			Code: UNAUTHORIZED_RESOURCES_ERROR,
 			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
 				requestInfo.Identifier, user.Urn),
@@ -300,13 +505,13 @@ func (api AuthAPI) ListGroupsByUser(requestInfo RequestInfo, externalId string)
 	}
 
 	// Call group repo to retrieve groups associated to user
-	groups, err := api.UserRepo.GetGroupsByUserID(user.ID)
+	groups, total, err := api.UserRepo.GetGroupsByUserID(user.ID, filter)
 
 	// Error handling
 	if err != nil {
 		//Transform to DB error
 		dbError := err.(*database.Error)
-		return nil, &Error{
+		return nil, 0, &Error{
 			Code:    UNKNOWN_API_ERROR,
 			Message: dbError.Message,
 		}
@@ -321,7 +526,7 @@ func (api AuthAPI) ListGroupsByUser(requestInfo RequestInfo, externalId string)
 		})
 	}
 
-	return groupIDs, nil
+	return groupIDs, total, nil
 }
 
 // PRIVATE HELPER METHODS
@@ -334,7 +539,82 @@ func createUser(externalId string, path string) User {
 		Path:       path,
 		CreateAt:   time.Now().UTC(),
 		Urn:        urn,
+		Status:     USER_STATUS_ACTIVE,
 	}
 
 	return user
 }
+
+// Suspend or reactivate a user. Users already in the requested status are returned without changes
+func (api AuthAPI) updateUserStatus(requestInfo RequestInfo, externalId string, status string, action string) (*User, error) {
+	// Call repo to retrieve the user
+	userDB, err := api.GetUserByExternalID(requestInfo, externalId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check restrictions
+	usersFiltered, err := api.GetAuthorizedUsers(requestInfo, userDB.Urn, action, []User{*userDB})
+	if err != nil {
+		return nil, err
+	}
+	if len(usersFiltered) < 1 {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, userDB.Urn),
+		}
+	}
+
+	if userDB.Status == status {
+		return userDB, nil
+	}
+
+	var user *User
+	err = api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		var err error
+		user, err = repo.UpdateUserStatus(*userDB, status)
+		if err != nil {
+			return err
+		}
+		return api.auditOperation(repo, requestInfo, action, user.Urn, userDB, user)
+	})
+
+	// Check unexpected DB error
+	if err != nil {
+		return nil, toRevisionAPIError(err)
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("User status updated from %v to %v %+v", userDB.Status, status, user))
+	return user, nil
+}
+
+func validateUserProfile(profile UserProfile) error {
+	if len(profile.DisplayName) > MAX_NAME_LENGTH {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: displayName %v", profile.DisplayName),
+		}
+	}
+	if profile.Email != "" && !IsValidEmail(profile.Email) {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: email %v", profile.Email),
+		}
+	}
+	for key, value := range profile.Attributes {
+		if !IsValidTagKey(key) {
+			return &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: attribute key %v", key),
+			}
+		}
+		if len(value) > MAX_TAG_VALUE_LENGTH {
+			return &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: attribute value %v", value),
+			}
+		}
+	}
+	return nil
+}
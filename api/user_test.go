@@ -1,8 +1,11 @@
 package api
 
 import (
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/kylelemons/godebug/pretty"
 	"github.com/tecsisa/foulkon/database"
 )
 
@@ -12,6 +15,7 @@ func TestAuthAPI_AddUser(t *testing.T) {
 		requestInfo RequestInfo
 		externalID  string
 		path        string
+		profile     UserProfile
 		// Expected result
 		expectedUser *User
 		wantError    error
@@ -23,6 +27,7 @@ func TestAuthAPI_AddUser(t *testing.T) {
 		// API Errors
 		addUserMethodErr             error
 		getUserByExternalIDMethodErr error
+		addAuditEventMethodErr       error
 	}{
 		"OKCaseAdmin": {
 			requestInfo: RequestInfo{
@@ -118,6 +123,30 @@ func TestAuthAPI_AddUser(t *testing.T) {
 				Message: "Invalid parameter: path /**%%/*123",
 			},
 		},
+		"ErrorCaseInvalidEmail": {
+			externalID: "1234",
+			path:       "/example/",
+			profile: UserProfile{
+				Email: "notanemail",
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: email notanemail",
+			},
+		},
+		"ErrorCaseInvalidAttributeKey": {
+			externalID: "1234",
+			path:       "/example/",
+			profile: UserProfile{
+				Attributes: map[string]string{
+					"*%~#": "value",
+				},
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: attribute key *%~#",
+			},
+		},
 		"ErrorCaseNopath": {
 			externalID: "1234",
 			wantError: &Error{
@@ -254,6 +283,29 @@ func TestAuthAPI_AddUser(t *testing.T) {
 				Code: database.INTERNAL_ERROR,
 			},
 		},
+		"ErrorCaseAuditDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			path:       "/example/",
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			expectedUser: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code:    database.USER_NOT_FOUND,
+				Message: "User not found",
+			},
+			addAuditEventMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
 		"ErrorCaseGetUserDBErr": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
@@ -301,7 +353,8 @@ func TestAuthAPI_AddUser(t *testing.T) {
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
 		testRepo.ArgsOut[AddUserMethod][0] = testcase.expectedUser
 		testRepo.ArgsOut[AddUserMethod][1] = testcase.addUserMethodErr
-		user, err := testAPI.AddUser(testcase.requestInfo, testcase.externalID, testcase.path)
+		testRepo.ArgsOut[AddAuditEventMethod][1] = testcase.addAuditEventMethodErr
+		user, err := testAPI.AddUser(testcase.requestInfo, testcase.externalID, testcase.path, testcase.profile)
 		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedUser, user)
 	}
 
@@ -541,15 +594,20 @@ func TestAuthAPI_GetUserByExternalID(t *testing.T) {
 }
 
 func TestAuthAPI_ListUsers(t *testing.T) {
+	now := time.Now().UTC()
+	yesterday := now.Add(-24 * time.Hour)
 	testcases := map[string]struct {
 		// API method args
 		requestInfo RequestInfo
-		pathPrefix  string
+		filter      *Filter
 		// Expected result
-		expectedResult []string
+		expectedResult []User
+		expectedTotal  int
+		expectedFilter *Filter
 		wantError      error
 		// Manager Results
 		getUsersFilteredMethodResult    []User
+		getUsersFilteredMethodTotal     int
 		getGroupsByUserIDMethodResult   []Group
 		getAttachedPoliciesMethodResult []Policy
 		getUserByExternalIDMethodResult *User
@@ -562,8 +620,23 @@ func TestAuthAPI_ListUsers(t *testing.T) {
 				Identifier: "123456",
 				Admin:      true,
 			},
-			pathPrefix:     "",
-			expectedResult: []string{"123", "321"},
+			filter: &Filter{},
+			expectedResult: []User{
+				{
+					ID:         "123",
+					ExternalID: "123",
+					Path:       "/example/test/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/example/test/", "123"),
+				},
+				{
+					ID:         "321",
+					ExternalID: "321",
+					Path:       "/example/test2/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/example/test2/", "321"),
+				},
+			},
+			expectedTotal:               2,
+			getUsersFilteredMethodTotal: 2,
 			getUsersFilteredMethodResult: []User{
 				{
 					ID:         "123",
@@ -584,8 +657,21 @@ func TestAuthAPI_ListUsers(t *testing.T) {
 				Identifier: "123456",
 				Admin:      false,
 			},
-			pathPrefix:     "",
-			expectedResult: []string{"123", "321"},
+			filter: &Filter{},
+			expectedResult: []User{
+				{
+					ID:         "123",
+					ExternalID: "123",
+					Path:       "/example/test/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/example/test/", "123"),
+				},
+				{
+					ID:         "321",
+					ExternalID: "321",
+					Path:       "/example/test2/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/example/test2/", "321"),
+				},
+			},
 			getUserByExternalIDMethodResult: &User{
 				ID:         "000",
 				ExternalID: "000",
@@ -639,8 +725,8 @@ func TestAuthAPI_ListUsers(t *testing.T) {
 				Identifier: "123456",
 				Admin:      false,
 			},
-			pathPrefix:     "",
-			expectedResult: []string{},
+			filter:         &Filter{},
+			expectedResult: []User{},
 			getUserByExternalIDMethodResult: &User{
 				ID:         "000",
 				ExternalID: "000",
@@ -698,23 +784,289 @@ func TestAuthAPI_ListUsers(t *testing.T) {
 				},
 			},
 		},
+		"OKCaseAdminFilterByTags": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter: &Filter{Tags: map[string]string{"team": "payments"}},
+			expectedResult: []User{
+				{
+					ID:         "123",
+					ExternalID: "123",
+					Path:       "/example/test/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/example/test/", "123"),
+					Tags:       map[string]string{"team": "payments", "env": "prod"},
+				},
+			},
+			expectedTotal: 1,
+			expectedFilter: &Filter{
+				PathPrefix: "/",
+				Tags:       map[string]string{"team": "payments"},
+				Limit:      DEFAULT_LIMIT_SIZE,
+			},
+			getUsersFilteredMethodResult: []User{
+				{
+					ID:         "123",
+					ExternalID: "123",
+					Path:       "/example/test/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/example/test/", "123"),
+					Tags:       map[string]string{"team": "payments", "env": "prod"},
+				},
+			},
+			getUsersFilteredMethodTotal: 1,
+		},
+		"OKCaseAdminPaging": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter: &Filter{
+				PathPrefix: "/example/",
+				Offset:     2,
+				Limit:      2,
+			},
+			expectedResult: []User{
+				{
+					ID:         "123",
+					ExternalID: "123",
+					Path:       "/example/test/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/example/test/", "123"),
+				},
+				{
+					ID:         "321",
+					ExternalID: "321",
+					Path:       "/example/test2/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/example/test2/", "321"),
+				},
+			},
+			expectedTotal: 5,
+			expectedFilter: &Filter{
+				PathPrefix: "/example/",
+				Offset:     2,
+				Limit:      2,
+			},
+			getUsersFilteredMethodResult: []User{
+				{
+					ID:         "123",
+					ExternalID: "123",
+					Path:       "/example/test/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/example/test/", "123"),
+				},
+				{
+					ID:         "321",
+					ExternalID: "321",
+					Path:       "/example/test2/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/example/test2/", "321"),
+				},
+			},
+			getUsersFilteredMethodTotal: 5,
+		},
+		"OKCaseTagCondition": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			filter: &Filter{},
+			expectedResult: []User{
+				{
+					ID:         "123",
+					ExternalID: "123",
+					Path:       "/example/test/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/example/test/", "123"),
+					Tags:       map[string]string{"team": "payments"},
+				},
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "000",
+				ExternalID: "000",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "000"),
+				Tags:       map[string]string{"team": "payments"},
+			},
+			getUsersFilteredMethodResult: []User{
+				{
+					ID:         "123",
+					ExternalID: "123",
+					Path:       "/example/test/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/example/test/", "123"),
+					Tags:       map[string]string{"team": "payments"},
+				},
+				{
+					ID:         "321",
+					ExternalID: "321",
+					Path:       "/example/test2/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/example/test2/", "321"),
+					Tags:       map[string]string{"team": "billing"},
+				},
+			},
+			getGroupsByUserIDMethodResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesMethodResult: []Policy{
+				{
+					ID:   "POLICY-USER-ID",
+					Name: "policyUser",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+					Statements: &[]Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								USER_ACTION_LIST_USERS,
+							},
+							Resources: []string{
+								GetUrnPrefix("", RESOURCE_USER, ""),
+							},
+							Conditions: []string{
+								"team == resource.team",
+							},
+						},
+					},
+				},
+			},
+		},
 		"ErrorCaseInvalidPath": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      true,
 			},
-			pathPrefix: "/^*$**~#!/",
+			filter: &Filter{PathPrefix: "/^*$**~#!/"},
 			wantError: &Error{
 				Code:    INVALID_PARAMETER_ERROR,
 				Message: "Invalid parameter: PathPrefix /^*$**~#!/",
 			},
 		},
+		"ErrorCaseInvalidTag": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter: &Filter{Tags: map[string]string{"team": "pay ments"}},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: tag value pay ments",
+			},
+		},
+		"OKCaseAdminFilterAndSort": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter: &Filter{
+				Name:          "12",
+				CreatedAfter:  &yesterday,
+				CreatedBefore: &now,
+				Orphan:        true,
+				SortBy:        SORT_BY_CREATE_AT,
+				Order:         ORDER_DESC,
+			},
+			expectedResult: []User{
+				{
+					ID:         "123",
+					ExternalID: "123",
+					Path:       "/example/test/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/example/test/", "123"),
+				},
+			},
+			expectedTotal: 1,
+			expectedFilter: &Filter{
+				PathPrefix:    "/",
+				Name:          "12",
+				CreatedAfter:  &yesterday,
+				CreatedBefore: &now,
+				Orphan:        true,
+				SortBy:        SORT_BY_CREATE_AT,
+				Order:         ORDER_DESC,
+				Limit:         DEFAULT_LIMIT_SIZE,
+			},
+			getUsersFilteredMethodResult: []User{
+				{
+					ID:         "123",
+					ExternalID: "123",
+					Path:       "/example/test/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/example/test/", "123"),
+				},
+			},
+			getUsersFilteredMethodTotal: 1,
+		},
+		"ErrorCaseInvalidName": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter: &Filter{Name: "*12"},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Name *12",
+			},
+		},
+		"ErrorCaseInvalidDateRange": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter: &Filter{CreatedAfter: &now, CreatedBefore: &yesterday},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: CreatedAfter " + now.Format(time.RFC3339) + " must be before CreatedBefore " + yesterday.Format(time.RFC3339),
+			},
+		},
+		"ErrorCaseInvalidSort": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter: &Filter{SortBy: "path"},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Sort path",
+			},
+		},
+		"ErrorCaseInvalidOrder": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter: &Filter{Order: "up"},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Order up",
+			},
+		},
+		"ErrorCaseInvalidOffset": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter: &Filter{Offset: -1},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Offset -1",
+			},
+		},
+		"ErrorCaseInvalidLimit": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter: &Filter{Limit: 1001},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Limit 1001, max limit allowed: 1000",
+			},
+		},
 		"ErrorCaseNoAuth": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      false,
 			},
-			pathPrefix: "/example/",
+			filter: &Filter{PathPrefix: "/example/"},
 			getUserByExternalIDMethodErr: &database.Error{
 				Code: database.USER_NOT_FOUND,
 			},
@@ -728,7 +1080,7 @@ func TestAuthAPI_ListUsers(t *testing.T) {
 				Identifier: "123456",
 				Admin:      true,
 			},
-			pathPrefix: "/example/",
+			filter: &Filter{PathPrefix: "/example/"},
 			wantError: &Error{
 				Code: UNKNOWN_API_ERROR,
 			},
@@ -741,7 +1093,7 @@ func TestAuthAPI_ListUsers(t *testing.T) {
 				Identifier: "123456",
 				Admin:      false,
 			},
-			pathPrefix: "",
+			filter: &Filter{},
 			wantError: &Error{
 				Code:    UNAUTHORIZED_RESOURCES_ERROR,
 				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam::user/*",
@@ -789,7 +1141,7 @@ func TestAuthAPI_ListUsers(t *testing.T) {
 				Identifier: "123456",
 				Admin:      false,
 			},
-			pathPrefix: "",
+			filter: &Filter{},
 			wantError: &Error{
 				Code:    UNAUTHORIZED_RESOURCES_ERROR,
 				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam::user/*",
@@ -850,7 +1202,7 @@ func TestAuthAPI_ListUsers(t *testing.T) {
 				Identifier: "123456",
 				Admin:      false,
 			},
-			pathPrefix: "/example/",
+			filter: &Filter{PathPrefix: "/example/"},
 			wantError: &Error{
 				Code: UNKNOWN_API_ERROR,
 			},
@@ -869,31 +1221,144 @@ func TestAuthAPI_ListUsers(t *testing.T) {
 		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDMethodResult
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesMethodResult
 		testRepo.ArgsOut[GetUsersFilteredMethod][0] = testcase.getUsersFilteredMethodResult
-		testRepo.ArgsOut[GetUsersFilteredMethod][1] = testcase.GetUsersFilteredMethodErr
-		users, err := testAPI.ListUsers(testcase.requestInfo, testcase.pathPrefix)
+		testRepo.ArgsOut[GetUsersFilteredMethod][2] = testcase.GetUsersFilteredMethodErr
+		testRepo.ArgsOut[GetUsersFilteredMethod][1] = testcase.getUsersFilteredMethodTotal
+		filter := testcase.filter
+		if filter == nil {
+			filter = &Filter{}
+		}
+		users, total, err := testAPI.ListUsers(testcase.requestInfo, filter)
 		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResult, users)
+		if testcase.wantError == nil {
+			if total != testcase.expectedTotal {
+				t.Errorf("Test %v failed. Received different total (wanted:%v / received:%v)", x, testcase.expectedTotal, total)
+			}
+			// Check filter received by repo
+			if testcase.expectedFilter != nil {
+				if diff := pretty.Compare(testRepo.ArgsIn[GetUsersFilteredMethod][0], testcase.expectedFilter); diff != "" {
+					t.Errorf("Test %v failed. Received different filter (received/wanted) %v", x, diff)
+				}
+			}
+		}
 	}
 
 }
 
 func TestAuthAPI_UpdateUser(t *testing.T) {
+	displayName := "John Doe"
+	email := "john@example.com"
+	longDisplayName := strings.Repeat("a", MAX_NAME_LENGTH+1)
+	referencingPolicy := Policy{
+		ID:   "POLICY-ID",
+		Name: "policy",
+		Org:  "example",
+		Path: "/path/",
+		Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policy"),
+		Statements: &[]Statement{
+			{
+				Sid:    "user",
+				Effect: "allow",
+				Actions: []string{
+					USER_ACTION_GET_USER,
+				},
+				Resources: []string{
+					CreateUrn("", RESOURCE_USER, "/example/", "1234"),
+				},
+			},
+		},
+	}
 	testcases := map[string]struct {
 		// API method args
 		requestInfo RequestInfo
 		externalID  string
 		newPath     string
+		newProfile  UserProfileUpdate
 		// Expected result
-		expectedUser *User
-		wantError    error
+		expectedUser       *User
+		expectedProfile    *UserProfile
+		expectedReferences []StatementReference
+		expectedStatements []Statement
+		wantError          error
 		// Manager Results
 		getUserByExternalIDMethodResult *User
 		getGroupsByUserIDMethodResult   []Group
 		getAttachedPoliciesMethodResult []Policy
+		getPoliciesReferencingUrnResult []Policy
+		updatePolicyMethodResult        *Policy
 		// API Errors
 		updateUserMethodErr          error
 		getUserByExternalIDMethodErr error
 	}{
 		"OKCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			newPath:    "/example2/",
+			newProfile: UserProfileUpdate{
+				DisplayName: &displayName,
+				Email:       &email,
+				Attributes: map[string]string{
+					"department": "sales",
+				},
+			},
+			expectedUser: &User{
+				ID:          "543210",
+				ExternalID:  "1234",
+				Path:        "/example2/",
+				Urn:         CreateUrn("", RESOURCE_USER, "/example/", "1234"),
+				DisplayName: "John Doe",
+				Email:       "john@example.com",
+				Attributes: map[string]string{
+					"department": "sales",
+				},
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/example/", "1234"),
+			},
+		},
+		"OKCasePathOnlyKeepsProfile": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			newPath:    "/example2/",
+			expectedUser: &User{
+				ID:          "543210",
+				ExternalID:  "1234",
+				Path:        "/example2/",
+				Urn:         CreateUrn("", RESOURCE_USER, "/example2/", "1234"),
+				DisplayName: "John Doe",
+				Email:       "john@example.com",
+				Attributes: map[string]string{
+					"department": "sales",
+				},
+			},
+			expectedProfile: &UserProfile{
+				DisplayName: "John Doe",
+				Email:       "john@example.com",
+				Attributes: map[string]string{
+					"department": "sales",
+				},
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:          "543210",
+				ExternalID:  "1234",
+				Path:        "/example/",
+				Urn:         CreateUrn("", RESOURCE_USER, "/example/", "1234"),
+				DisplayName: "John Doe",
+				Email:       "john@example.com",
+				Attributes: map[string]string{
+					"department": "sales",
+				},
+			},
+		},
+		"OKCaseDanglingReferences": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      true,
@@ -904,14 +1369,58 @@ func TestAuthAPI_UpdateUser(t *testing.T) {
 				ID:         "543210",
 				ExternalID: "1234",
 				Path:       "/example2/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/example2/", "1234"),
+			},
+			expectedReferences: []StatementReference{
+				{
+					Org:        "example",
+					PolicyName: "policy",
+					Sid:        "user",
+				},
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
 				Urn:        CreateUrn("", RESOURCE_USER, "/example/", "1234"),
 			},
+			getPoliciesReferencingUrnResult: []Policy{referencingPolicy},
+		},
+		"OKCaseRewriteReferences": {
+			requestInfo: RequestInfo{
+				Identifier:        "123456",
+				Admin:             true,
+				RewriteReferences: true,
+			},
+			externalID: "1234",
+			newPath:    "/example2/",
+			expectedUser: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example2/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/example2/", "1234"),
+			},
+			expectedReferences: []StatementReference{},
+			expectedStatements: []Statement{
+				{
+					Sid:    "user",
+					Effect: "allow",
+					Actions: []string{
+						USER_ACTION_GET_USER,
+					},
+					Resources: []string{
+						CreateUrn("", RESOURCE_USER, "/example2/", "1234"),
+					},
+				},
+			},
 			getUserByExternalIDMethodResult: &User{
 				ID:         "543210",
 				ExternalID: "1234",
 				Path:       "/example/",
 				Urn:        CreateUrn("", RESOURCE_USER, "/example/", "1234"),
 			},
+			getPoliciesReferencingUrnResult: []Policy{referencingPolicy},
+			updatePolicyMethodResult:        &referencingPolicy,
 		},
 		"OKCase": {
 			requestInfo: RequestInfo{
@@ -968,6 +1477,17 @@ func TestAuthAPI_UpdateUser(t *testing.T) {
 				Message: "Invalid parameter: path /**%%/*123",
 			},
 		},
+		"ErrorCaseInvalidDisplayName": {
+			externalID: "1234",
+			newPath:    "/example/",
+			newProfile: UserProfileUpdate{
+				DisplayName: &longDisplayName,
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: displayName " + strings.Repeat("a", MAX_NAME_LENGTH+1),
+			},
+		},
 		"ErrorCaseNoPath": {
 			wantError: &Error{
 				Code:    INVALID_PARAMETER_ERROR,
@@ -1310,8 +1830,30 @@ func TestAuthAPI_UpdateUser(t *testing.T) {
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesMethodResult
 		testRepo.ArgsOut[UpdateUserMethod][0] = testcase.expectedUser
 		testRepo.ArgsOut[UpdateUserMethod][1] = testcase.updateUserMethodErr
-		user, err := testAPI.UpdateUser(testcase.requestInfo, testcase.externalID, testcase.newPath)
+		testRepo.ArgsOut[GetPoliciesReferencingUrnMethod][0] = testcase.getPoliciesReferencingUrnResult
+		testRepo.ArgsOut[UpdatePolicyMethod][0] = testcase.updatePolicyMethodResult
+		user, references, err := testAPI.UpdateUser(testcase.requestInfo, testcase.externalID, testcase.newPath, testcase.newProfile)
 		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedUser, user)
+		if testcase.expectedProfile != nil {
+			if diff := pretty.Compare(testRepo.ArgsIn[UpdateUserMethod][3], *testcase.expectedProfile); diff != "" {
+				t.Errorf("Test %v failed. Received different profile (received/wanted) %v", x, diff)
+			}
+		}
+		if testcase.expectedReferences == nil {
+			continue
+		}
+		if diff := pretty.Compare(references, testcase.expectedReferences); diff != "" {
+			t.Errorf("Test %v failed. Received different references (received/wanted) %v", x, diff)
+		}
+		if diff := pretty.Compare(testRepo.ArgsIn[UpdatePolicyMethod][4], testcase.expectedStatements); testcase.expectedStatements != nil && diff != "" {
+			t.Errorf("Test %v failed. Received different statements (received/wanted) %v", x, diff)
+		}
+		if testcase.updatePolicyMethodResult != nil {
+			event := testRepo.ArgsIn[AddAuditEventMethod][0].(AuditEvent)
+			if event.Action != POLICY_ACTION_UPDATE_POLICY || event.Urn != testcase.updatePolicyMethodResult.Urn {
+				t.Errorf("Test %v failed. Rewritten policy not audited, last audit event %v", x, event)
+			}
+		}
 	}
 
 }
@@ -1427,6 +1969,27 @@ func TestAuthAPI_RemoveUser(t *testing.T) {
 				Code: database.USER_NOT_FOUND,
 			},
 		},
+		"ErrorCaseRevisionConflict": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			wantError: &Error{
+				Code:    PRECONDITION_FAILED_ERROR,
+				Message: "Resource urn:iws:iam::user/example/1234 has been modified since it was retrieved",
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/example/", "1234"),
+			},
+			removeUserMethodErr: &database.Error{
+				Code:    database.REVISION_CONFLICT,
+				Message: "Resource urn:iws:iam::user/example/1234 has been modified since it was retrieved",
+			},
+		},
 		"ErrorCaseGetUserExtIDDBErr": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
@@ -1580,21 +2143,22 @@ func TestAuthAPI_RemoveUser(t *testing.T) {
 	}
 }
 
-func TestAuthAPI_ListGroupsByUser(t *testing.T) {
+func TestAuthAPI_SuspendUser(t *testing.T) {
 	testcases := map[string]struct {
-		// API Method args
+		// API method args
 		requestInfo RequestInfo
 		externalID  string
-		wantError   error
 		// Expected result
-		expectedResponse []GroupIdentity
+		expectedUser *User
+		wantError    error
 		// Manager Results
 		getUserByExternalIDMethodResult *User
-		getGroupsByUserIDMethodResult   []Group
-		getAttachedPoliciesMethodResult []Policy
-		// Manager Errors
-		getGroupsByUserIDMethodErr   error
+		getGroupsByUserIDResult         []Group
+		getAttachedPoliciesResult       []Policy
+		updateUserStatusMethodResult    *User
+		// API Errors
 		getUserByExternalIDMethodErr error
+		updateUserStatusMethodErr    error
 	}{
 		"OKCaseAdmin": {
 			requestInfo: RequestInfo{
@@ -1602,96 +2166,65 @@ func TestAuthAPI_ListGroupsByUser(t *testing.T) {
 				Admin:      true,
 			},
 			externalID: "1234",
-			expectedResponse: []GroupIdentity{
-				{
-					Org:  "org1",
-					Name: "groupUser1",
-				},
-				{
-					Org:  "org2",
-					Name: "groupUser2",
-				},
+			expectedUser: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+				Status:     USER_STATUS_SUSPENDED,
 			},
 			getUserByExternalIDMethodResult: &User{
 				ID:         "543210",
 				ExternalID: "1234",
 				Path:       "/example/",
+				Status:     USER_STATUS_ACTIVE,
 			},
-			getGroupsByUserIDMethodResult: []Group{
-				{
-					ID:   "GROUP1",
-					Org:  "org1",
-					Name: "groupUser1",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser1"),
-				},
-				{
-					ID:   "GROUP2",
-					Org:  "org2",
-					Name: "groupUser2",
-					Path: "/path/",
-					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser1"),
-				},
+			updateUserStatusMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+				Status:     USER_STATUS_SUSPENDED,
 			},
 		},
 		"OKCase": {
 			requestInfo: RequestInfo{
-				Identifier: "123456",
+				Identifier: "1234",
 				Admin:      false,
 			},
 			externalID: "1234",
-			expectedResponse: []GroupIdentity{
-				{
-					Org:  "org1",
-					Name: "group1",
-				},
-				{
-					Org:  "org2",
-					Name: "group2",
-				},
+			expectedUser: &User{
+				ID:         "1234",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+				Status:     USER_STATUS_SUSPENDED,
 			},
 			getUserByExternalIDMethodResult: &User{
-				ID:         "543210",
+				ID:         "1234",
 				ExternalID: "1234",
 				Path:       "/path/",
 				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+				Status:     USER_STATUS_ACTIVE,
 			},
-			getGroupsByUserIDMethodResult: []Group{
-				{
-					ID:   "GROUP-USER-ID-1",
-					Name: "group1",
-					Path: "/path/1/",
-					Org:  "org1",
-					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/1/", "group1"),
-				},
+			getGroupsByUserIDResult: []Group{
 				{
-					ID:   "GROUP-USER-ID-2",
-					Name: "group2",
-					Path: "/path/2/",
-					Org:  "org2",
-					Urn:  CreateUrn("org2", RESOURCE_GROUP, "/path/2/", "group2"),
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
 				},
 			},
-			getAttachedPoliciesMethodResult: []Policy{
+			getAttachedPoliciesResult: []Policy{
 				{
 					ID:   "POLICY-USER-ID",
 					Name: "policyUser",
 					Path: "/path/",
-					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
 					Statements: &[]Statement{
 						{
 							Effect: "allow",
 							Actions: []string{
 								USER_ACTION_GET_USER,
-							},
-							Resources: []string{
-								CreateUrn("", RESOURCE_USER, "/path/", "1234"),
-							},
-						},
-						{
-							Effect: "allow",
-							Actions: []string{
-								USER_ACTION_LIST_GROUPS_FOR_USER,
+								USER_ACTION_SUSPEND_USER,
 							},
 							Resources: []string{
 								GetUrnPrefix("", RESOURCE_USER, "/path/"),
@@ -1700,66 +2233,54 @@ func TestAuthAPI_ListGroupsByUser(t *testing.T) {
 					},
 				},
 			},
+			updateUserStatusMethodResult: &User{
+				ID:         "1234",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+				Status:     USER_STATUS_SUSPENDED,
+			},
 		},
-		"ErrorCaseNoAuth": {
+		"OKCaseAlreadySuspended": {
 			requestInfo: RequestInfo{
-				Identifier: "1234",
-				Admin:      false,
+				Identifier: "123456",
+				Admin:      true,
 			},
 			externalID: "1234",
-			wantError: &Error{
-				Code:    UNAUTHORIZED_RESOURCES_ERROR,
-				Message: "User with externalId 1234 is not allowed to access to resource urn:iws:iam::user/example/12345",
+			expectedUser: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+				Status:     USER_STATUS_SUSPENDED,
 			},
 			getUserByExternalIDMethodResult: &User{
 				ID:         "543210",
-				ExternalID: "12345",
+				ExternalID: "1234",
 				Path:       "/example/",
-				Urn:        CreateUrn("", RESOURCE_USER, "/example/", "12345"),
-			},
-		},
-		"ErrorCaseUserNotFound": {
-			requestInfo: RequestInfo{
-				Identifier: "1234",
-				Admin:      false,
-			},
-			externalID: "1234",
-			wantError: &Error{
-				Code: USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Status:     USER_STATUS_SUSPENDED,
 			},
-			getUserByExternalIDMethodErr: &database.Error{
-				Code: database.USER_NOT_FOUND,
+			updateUserStatusMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
 			},
 		},
-		"ErrorCaseGetUserExtIDDBErr": {
-			requestInfo: RequestInfo{
-				Identifier: "123456",
-				Admin:      true,
-			},
-			externalID: "1234",
+		"ErrorCaseInvalidExtID": {
+			externalID: "*%~#@|",
 			wantError: &Error{
-				Code: UNKNOWN_API_ERROR,
-			},
-			getUserByExternalIDMethodErr: &database.Error{
-				Code: database.INTERNAL_ERROR,
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: externalId *%~#@|",
 			},
 		},
-		"ErrorCaseGetGroupsDBErr": {
+		"ErrorCaseUserNotFound": {
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      true,
 			},
 			externalID: "1234",
 			wantError: &Error{
-				Code: UNKNOWN_API_ERROR,
-			},
-			getUserByExternalIDMethodResult: &User{
-				ID:         "543210",
-				ExternalID: "1234",
-				Path:       "/example/",
+				Code: USER_BY_EXTERNAL_ID_NOT_FOUND,
 			},
-			getGroupsByUserIDMethodErr: &database.Error{
-				Code: database.INTERNAL_ERROR,
+			getUserByExternalIDMethodErr: &database.Error{
+				Code: database.USER_NOT_FOUND,
 			},
 		},
 		"ErrorCaseNoPermissions": {
@@ -1773,20 +2294,13 @@ func TestAuthAPI_ListGroupsByUser(t *testing.T) {
 				Message: "User with externalId 1234 is not allowed to access to resource urn:iws:iam::user/path/1234",
 			},
 			getUserByExternalIDMethodResult: &User{
-				ID:         "543210",
+				ID:         "1234",
 				ExternalID: "1234",
 				Path:       "/path/",
 				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+				Status:     USER_STATUS_ACTIVE,
 			},
-			getGroupsByUserIDMethodResult: []Group{
-				{
-					ID:   "GROUP-USER-ID",
-					Name: "groupUser",
-					Path: "/path/1/",
-					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
-				},
-			},
-			getAttachedPoliciesMethodResult: []Policy{
+			getAttachedPoliciesResult: []Policy{
 				{
 					ID:   "POLICY-USER-ID",
 					Name: "policyUser",
@@ -1799,11 +2313,829 @@ func TestAuthAPI_ListGroupsByUser(t *testing.T) {
 								USER_ACTION_GET_USER,
 							},
 							Resources: []string{
-								CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+								GetUrnPrefix("", RESOURCE_USER, "/path/"),
 							},
 						},
-						{
-							Effect: "allow",
+					},
+				},
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+		},
+		"ErrorCaseUpdateUserStatusDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+				Status:     USER_STATUS_ACTIVE,
+			},
+			updateUserStatusMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDMethodResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
+		testRepo.ArgsOut[UpdateUserStatusMethod][0] = testcase.updateUserStatusMethodResult
+		testRepo.ArgsOut[UpdateUserStatusMethod][1] = testcase.updateUserStatusMethodErr
+		user, err := testAPI.SuspendUser(testcase.requestInfo, testcase.externalID)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedUser, user)
+	}
+}
+
+func TestAuthAPI_ReactivateUser(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		externalID  string
+		// Expected result
+		expectedUser *User
+		wantError    error
+		// Manager Results
+		getUserByExternalIDMethodResult *User
+		getGroupsByUserIDResult         []Group
+		getAttachedPoliciesResult       []Policy
+		updateUserStatusMethodResult    *User
+		// API Errors
+		getUserByExternalIDMethodErr error
+		updateUserStatusMethodErr    error
+	}{
+		"OKCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			expectedUser: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+				Status:     USER_STATUS_ACTIVE,
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+				Status:     USER_STATUS_SUSPENDED,
+			},
+			updateUserStatusMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+				Status:     USER_STATUS_ACTIVE,
+			},
+		},
+		"OKCaseAlreadyActive": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			expectedUser: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+				Status:     USER_STATUS_ACTIVE,
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+				Status:     USER_STATUS_ACTIVE,
+			},
+			updateUserStatusMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+		"ErrorCaseSuspendedUserCannotReactivate": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+				Admin:      false,
+			},
+			externalID: "1234",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 1234 is not allowed to access to resource urn:iws:iam::user/path/1234",
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "1234",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+				Status:     USER_STATUS_SUSPENDED,
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesResult: []Policy{
+				{
+					ID:   "POLICY-USER-ID",
+					Name: "policyUser",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+					Statements: &[]Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								USER_ACTION_GET_USER,
+								USER_ACTION_REACTIVATE_USER,
+							},
+							Resources: []string{
+								GetUrnPrefix("", RESOURCE_USER, "/path/"),
+							},
+						},
+					},
+				},
+			},
+		},
+		"ErrorCaseUpdateUserStatusDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+				Status:     USER_STATUS_SUSPENDED,
+			},
+			updateUserStatusMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDMethodResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
+		testRepo.ArgsOut[UpdateUserStatusMethod][0] = testcase.updateUserStatusMethodResult
+		testRepo.ArgsOut[UpdateUserStatusMethod][1] = testcase.updateUserStatusMethodErr
+		user, err := testAPI.ReactivateUser(testcase.requestInfo, testcase.externalID)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedUser, user)
+	}
+}
+
+func TestAuthAPI_RenameUser(t *testing.T) {
+	userUrn := CreateUrn("", RESOURCE_USER, "/path/", "1234")
+	newUserUrn := CreateUrn("", RESOURCE_USER, "/path/", "5678")
+	referencingPolicy := Policy{
+		ID:   "POLICY-ID",
+		Name: "policy",
+		Org:  "example",
+		Path: "/path/",
+		Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policy"),
+		Statements: &[]Statement{
+			{
+				Sid:    "user",
+				Effect: "allow",
+				Actions: []string{
+					USER_ACTION_GET_USER,
+				},
+				Resources: []string{
+					userUrn,
+					GetUrnPrefix("", RESOURCE_USER, "/other/"),
+				},
+			},
+			{
+				Sid:    "prefix",
+				Effect: "allow",
+				Actions: []string{
+					USER_ACTION_GET_USER,
+				},
+				Resources: []string{
+					GetUrnPrefix("", RESOURCE_USER, "/path/"),
+				},
+			},
+		},
+	}
+	testcases := map[string]struct {
+		// API method args
+		requestInfo     RequestInfo
+		externalID      string
+		newExternalID   string
+		rewritePolicies bool
+		// Expected result
+		expectedRename     *UserRename
+		expectedStatements []Statement
+		wantError          error
+		// Manager Results
+		getUserByExternalIDMethodSpecialFunc  func(string) (*User, error)
+		getPoliciesReferencingUrnMethodResult []Policy
+		getGroupsByUserIDResult               []Group
+		getAttachedPoliciesResult             []Policy
+		updateUserExternalIDMethodResult      *User
+		updatePolicyMethodResult              *Policy
+		// API Errors
+		updateUserExternalIDMethodErr          error
+		updateAccessRequestsRequesterMethodErr error
+	}{
+		"OKCaseReport": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID:    "1234",
+			newExternalID: "5678",
+			expectedRename: &UserRename{
+				User: &User{
+					ID:         "USER-ID",
+					ExternalID: "5678",
+					Path:       "/path/",
+					Urn:        newUserUrn,
+				},
+				References: []StatementReference{
+					{
+						Org:        "example",
+						PolicyName: "policy",
+						Sid:        "user",
+					},
+				},
+			},
+			getPoliciesReferencingUrnMethodResult: []Policy{referencingPolicy},
+		},
+		"OKCaseRewrite": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID:      "1234",
+			newExternalID:   "5678",
+			rewritePolicies: true,
+			expectedRename: &UserRename{
+				User: &User{
+					ID:         "USER-ID",
+					ExternalID: "5678",
+					Path:       "/path/",
+					Urn:        newUserUrn,
+				},
+				References: []StatementReference{
+					{
+						Org:        "example",
+						PolicyName: "policy",
+						Sid:        "user",
+					},
+				},
+				Rewritten: true,
+			},
+			expectedStatements: []Statement{
+				{
+					Sid:    "user",
+					Effect: "allow",
+					Actions: []string{
+						USER_ACTION_GET_USER,
+					},
+					Resources: []string{
+						newUserUrn,
+						GetUrnPrefix("", RESOURCE_USER, "/other/"),
+					},
+				},
+				{
+					Sid:    "prefix",
+					Effect: "allow",
+					Actions: []string{
+						USER_ACTION_GET_USER,
+					},
+					Resources: []string{
+						GetUrnPrefix("", RESOURCE_USER, "/path/"),
+					},
+				},
+			},
+			getPoliciesReferencingUrnMethodResult: []Policy{referencingPolicy},
+			updatePolicyMethodResult:              &referencingPolicy,
+		},
+		"OKCaseReferencesNotAllowed": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			externalID:    "1234",
+			newExternalID: "5678",
+			expectedRename: &UserRename{
+				User: &User{
+					ID:         "USER-ID",
+					ExternalID: "5678",
+					Path:       "/path/",
+					Urn:        newUserUrn,
+				},
+				References: []StatementReference{},
+			},
+			getPoliciesReferencingUrnMethodResult: []Policy{referencingPolicy},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesResult: []Policy{
+				{
+					ID:   "POLICY-USER-ID",
+					Name: "policyUser",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+					Statements: &[]Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								USER_ACTION_GET_USER,
+								USER_ACTION_RENAME_USER,
+							},
+							Resources: []string{
+								GetUrnPrefix("", RESOURCE_USER, "/path/"),
+							},
+						},
+					},
+				},
+			},
+		},
+		"ErrorCaseRewriteNotAllowed": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			externalID:      "1234",
+			newExternalID:   "5678",
+			rewritePolicies: true,
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to access to resource *",
+			},
+			getPoliciesReferencingUrnMethodResult: []Policy{referencingPolicy},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesResult: []Policy{
+				{
+					ID:   "POLICY-USER-ID",
+					Name: "policyUser",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+					Statements: &[]Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								USER_ACTION_GET_USER,
+								USER_ACTION_RENAME_USER,
+							},
+							Resources: []string{
+								GetUrnPrefix("", RESOURCE_USER, "/path/"),
+							},
+						},
+					},
+				},
+			},
+		},
+		"ErrorCaseRenameToNotAllowed": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			externalID:    "1234",
+			newExternalID: "5678",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to access to resource " + newUserUrn,
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesResult: []Policy{
+				{
+					ID:   "POLICY-USER-ID",
+					Name: "policyUser",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+					Statements: &[]Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								USER_ACTION_GET_USER,
+							},
+							Resources: []string{
+								GetUrnPrefix("", RESOURCE_USER, "/path/"),
+							},
+						},
+						{
+							Effect: "allow",
+							Actions: []string{
+								USER_ACTION_RENAME_USER,
+							},
+							Resources: []string{
+								userUrn,
+							},
+						},
+					},
+				},
+			},
+		},
+		"ErrorCaseInvalidNewExtID": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID:    "1234",
+			newExternalID: "*%~#@|",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: newExternalId *%~#@|",
+			},
+		},
+		"ErrorCaseUserAlreadyExist": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID:    "1234",
+			newExternalID: "5678",
+			wantError: &Error{
+				Code:    USER_ALREADY_EXIST,
+				Message: "Unable to rename user, user with externalId 5678 already exist",
+			},
+			getUserByExternalIDMethodSpecialFunc: func(id string) (*User, error) {
+				return &User{
+					ID:         "USER-ID",
+					ExternalID: id,
+					Path:       "/path/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/path/", id),
+				}, nil
+			},
+		},
+		"ErrorCaseUpdateUserExternalIDDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID:    "1234",
+			newExternalID: "5678",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			updateUserExternalIDMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+		"ErrorCaseUpdateAccessRequestsRequesterDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID:    "1234",
+			newExternalID: "5678",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			updateUserExternalIDMethodResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "5678",
+				Path:       "/path/",
+				Urn:        newUserUrn,
+			},
+			updateAccessRequestsRequesterMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.SpecialFuncs[GetUserByExternalIDMethod] = testcase.getUserByExternalIDMethodSpecialFunc
+		if testcase.getUserByExternalIDMethodSpecialFunc == nil {
+			testRepo.SpecialFuncs[GetUserByExternalIDMethod] = func(id string) (*User, error) {
+				if id == "5678" {
+					return nil, &database.Error{
+						Code:    database.USER_NOT_FOUND,
+						Message: "User not found",
+					}
+				}
+				return &User{
+					ID:         "USER-ID",
+					ExternalID: id,
+					Path:       "/path/",
+					Urn:        CreateUrn("", RESOURCE_USER, "/path/", id),
+				}, nil
+			}
+		}
+		testRepo.ArgsOut[GetPoliciesReferencingUrnMethod][0] = testcase.getPoliciesReferencingUrnMethodResult
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
+		testRepo.ArgsOut[UpdateUserExternalIDMethod][0] = testcase.updateUserExternalIDMethodResult
+		if testcase.expectedRename != nil {
+			testRepo.ArgsOut[UpdateUserExternalIDMethod][0] = testcase.expectedRename.User
+		}
+		testRepo.ArgsOut[UpdateUserExternalIDMethod][1] = testcase.updateUserExternalIDMethodErr
+		testRepo.ArgsOut[UpdateAccessRequestsRequesterMethod][0] = testcase.updateAccessRequestsRequesterMethodErr
+		testRepo.ArgsOut[UpdatePolicyMethod][0] = testcase.updatePolicyMethodResult
+		rename, err := testAPI.RenameUser(testcase.requestInfo, testcase.externalID, testcase.newExternalID, testcase.rewritePolicies)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedRename, rename)
+		if testcase.wantError != nil {
+			continue
+		}
+		// Check pending access requests moved to the new externalId
+		requesters := []interface{}{
+			testRepo.ArgsIn[UpdateAccessRequestsRequesterMethod][0],
+			testRepo.ArgsIn[UpdateAccessRequestsRequesterMethod][1],
+		}
+		if diff := pretty.Compare(requesters, []interface{}{testcase.externalID, testcase.newExternalID}); diff != "" {
+			t.Errorf("Test %v failed. Received different access request requesters (received/wanted) %v", x, diff)
+		}
+		// Check rewritten policy statements
+		if testcase.rewritePolicies {
+			if diff := pretty.Compare(testRepo.ArgsIn[UpdatePolicyMethod][4], testcase.expectedStatements); diff != "" {
+				t.Errorf("Test %v failed. Received different statements (received/wanted) %v", x, diff)
+			}
+		} else if testRepo.ArgsIn[UpdatePolicyMethod][0] != nil {
+			t.Errorf("Test %v failed. Policy updated without rewritePolicies", x)
+		}
+		if testcase.updatePolicyMethodResult != nil {
+			event := testRepo.ArgsIn[AddAuditEventMethod][0].(AuditEvent)
+			if event.Action != POLICY_ACTION_UPDATE_POLICY || event.Urn != testcase.updatePolicyMethodResult.Urn {
+				t.Errorf("Test %v failed. Rewritten policy not audited, last audit event %v", x, event)
+			}
+		}
+	}
+}
+
+func TestAuthAPI_ListGroupsByUser(t *testing.T) {
+	testcases := map[string]struct {
+		// API Method args
+		requestInfo RequestInfo
+		filter      *Filter
+		externalID  string
+		wantError   error
+		// Expected result
+		expectedTotal    int
+		expectedResponse []GroupIdentity
+		// Manager Results
+		getGroupsByUserIDMethodTotal    int
+		getUserByExternalIDMethodResult *User
+		getGroupsByUserIDMethodResult   []Group
+		getAttachedPoliciesMethodResult []Policy
+		// Manager Errors
+		getGroupsByUserIDMethodErr   error
+		getUserByExternalIDMethodErr error
+	}{
+		"OKCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			expectedTotal:                2,
+			getGroupsByUserIDMethodTotal: 2,
+			externalID:                   "1234",
+			expectedResponse: []GroupIdentity{
+				{
+					Org:  "org1",
+					Name: "groupUser1",
+				},
+				{
+					Org:  "org2",
+					Name: "groupUser2",
+				},
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+			},
+			getGroupsByUserIDMethodResult: []Group{
+				{
+					ID:   "GROUP1",
+					Org:  "org1",
+					Name: "groupUser1",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser1"),
+				},
+				{
+					ID:   "GROUP2",
+					Org:  "org2",
+					Name: "groupUser2",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser1"),
+				},
+			},
+		},
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			externalID: "1234",
+			expectedResponse: []GroupIdentity{
+				{
+					Org:  "org1",
+					Name: "group1",
+				},
+				{
+					Org:  "org2",
+					Name: "group2",
+				},
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getGroupsByUserIDMethodResult: []Group{
+				{
+					ID:   "GROUP-USER-ID-1",
+					Name: "group1",
+					Path: "/path/1/",
+					Org:  "org1",
+					Urn:  CreateUrn("org1", RESOURCE_GROUP, "/path/1/", "group1"),
+				},
+				{
+					ID:   "GROUP-USER-ID-2",
+					Name: "group2",
+					Path: "/path/2/",
+					Org:  "org2",
+					Urn:  CreateUrn("org2", RESOURCE_GROUP, "/path/2/", "group2"),
+				},
+			},
+			getAttachedPoliciesMethodResult: []Policy{
+				{
+					ID:   "POLICY-USER-ID",
+					Name: "policyUser",
+					Path: "/path/",
+					Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policyUser"),
+					Statements: &[]Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								USER_ACTION_GET_USER,
+							},
+							Resources: []string{
+								CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+							},
+						},
+						{
+							Effect: "allow",
+							Actions: []string{
+								USER_ACTION_LIST_GROUPS_FOR_USER,
+							},
+							Resources: []string{
+								GetUrnPrefix("", RESOURCE_USER, "/path/"),
+							},
+						},
+					},
+				},
+			},
+		},
+		"ErrorCaseNoAuth": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+				Admin:      false,
+			},
+			externalID: "1234",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 1234 is not allowed to access to resource urn:iws:iam::user/example/12345",
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "12345",
+				Path:       "/example/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/example/", "12345"),
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+				Admin:      false,
+			},
+			externalID: "1234",
+			wantError: &Error{
+				Code: USER_BY_EXTERNAL_ID_NOT_FOUND,
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code: database.USER_NOT_FOUND,
+			},
+		},
+		"ErrorCaseGetUserExtIDDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+		"ErrorCaseGetGroupsDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/example/",
+			},
+			getGroupsByUserIDMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+		"ErrorCaseNoPermissions": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+				Admin:      false,
+			},
+			externalID: "1234",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 1234 is not allowed to access to resource urn:iws:iam::user/path/1234",
+			},
+			getUserByExternalIDMethodResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getGroupsByUserIDMethodResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Path: "/path/1/",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesMethodResult: []Policy{
+				{
+					ID:   "POLICY-USER-ID",
+					Name: "policyUser",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+					Statements: &[]Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								USER_ACTION_GET_USER,
+							},
+							Resources: []string{
+								CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+							},
+						},
+						{
+							Effect: "allow",
 							Actions: []string{
 								USER_ACTION_LIST_GROUPS_FOR_USER,
 							},
@@ -1868,6 +3200,17 @@ func TestAuthAPI_ListGroupsByUser(t *testing.T) {
 				},
 			},
 		},
+		"ErrorCaseInvalidFilter": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter: &Filter{Limit: -1},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Limit -1, max limit allowed: 1000",
+			},
+		},
 	}
 
 	for x, testcase := range testcases {
@@ -1877,10 +3220,18 @@ func TestAuthAPI_ListGroupsByUser(t *testing.T) {
 		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDMethodResult
 		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
 		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDMethodResult
-		testRepo.ArgsOut[GetGroupsByUserIDMethod][1] = testcase.getGroupsByUserIDMethodErr
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][2] = testcase.getGroupsByUserIDMethodErr
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesMethodResult
-		groups, err := testAPI.ListGroupsByUser(testcase.requestInfo, testcase.externalID)
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][1] = testcase.getGroupsByUserIDMethodTotal
+		filter := testcase.filter
+		if filter == nil {
+			filter = &Filter{}
+		}
+		groups, total, err := testAPI.ListGroupsByUser(testcase.requestInfo, testcase.externalID, filter)
 		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, groups)
+		if testcase.wantError == nil && total != testcase.expectedTotal {
+			t.Errorf("Test %v failed. Received different total (wanted:%v / received:%v)", x, testcase.expectedTotal, total)
+		}
 	}
 
 }
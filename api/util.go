@@ -1,24 +1,65 @@
 package api
 
 import (
+	"crypto/sha1"
+	"encoding/json"
 	"fmt"
 	//"github.com/Sirupsen/logrus"
 	"github.com/Sirupsen/logrus"
+	"github.com/tecsisa/foulkon/database"
 	"regexp"
 	"strings"
+	"time"
 )
 
 const (
 	// Resource types
-	RESOURCE_GROUP  = "group"
-	RESOURCE_USER   = "user"
-	RESOURCE_POLICY = "policy"
+	RESOURCE_GROUP        = "group"
+	RESOURCE_USER         = "user"
+	RESOURCE_POLICY       = "policy"
+	RESOURCE_ORGANIZATION = "organization"
 
 	// Constraints
-	MAX_EXTERNAL_ID_LENGTH = 128
-	MAX_NAME_LENGTH        = 128
-	MAX_ACTION_LENGTH      = 128
-	MAX_PATH_LENGTH        = 512
+	MAX_EXTERNAL_ID_LENGTH   = 128
+	MAX_NAME_LENGTH          = 128
+	MAX_ACTION_LENGTH        = 128
+	MAX_PATH_LENGTH          = 512
+	MAX_JUSTIFICATION_LENGTH = 1024
+	MAX_DESCRIPTION_LENGTH   = 1024
+	MAX_URN_TEMPLATE_LENGTH  = 512
+	MAX_TAG_KEY_LENGTH       = 128
+	MAX_TAG_VALUE_LENGTH     = 256
+	MAX_EMAIL_LENGTH         = 256
+
+	// Pagination
+	DEFAULT_LIMIT_SIZE = 20
+	MAX_LIMIT_SIZE     = 1000
+
+	// List sorting
+	SORT_BY_NAME      = "name"
+	SORT_BY_CREATE_AT = "createAt"
+	ORDER_ASC         = "asc"
+	ORDER_DESC        = "desc"
+
+	// Built-in action namespace
+	IAM_NAMESPACE = "iam"
+
+	// URN template placeholder that matches a resource path, e.g. /example/admin/
+	URN_TEMPLATE_PATH_PARAM = "path"
+
+	// Access requests
+	MAX_ACCESS_REQUEST_DURATION = 30 * 24 * time.Hour
+
+	ACCESS_REQUEST_TYPE_MEMBERSHIP        = "membership"
+	ACCESS_REQUEST_TYPE_POLICY_ATTACHMENT = "policyAttachment"
+
+	ACCESS_REQUEST_STATUS_PENDING  = "pending"
+	ACCESS_REQUEST_STATUS_APPROVED = "approved"
+	ACCESS_REQUEST_STATUS_REJECTED = "rejected"
+
+	// User status
+	USER_STATUS_ACTIVE    = "active"
+	USER_STATUS_SUSPENDED = "suspended"
 
 	// Actions
 
@@ -29,6 +70,11 @@ const (
 	USER_ACTION_LIST_USERS           = "iam:ListUsers"
 	USER_ACTION_UPDATE_USER          = "iam:UpdateUser"
 	USER_ACTION_LIST_GROUPS_FOR_USER = "iam:ListGroupsForUser"
+	USER_ACTION_TAG_USER             = "iam:TagUser"
+	USER_ACTION_UNTAG_USER           = "iam:UntagUser"
+	USER_ACTION_SUSPEND_USER         = "iam:SuspendUser"
+	USER_ACTION_REACTIVATE_USER      = "iam:ReactivateUser"
+	USER_ACTION_RENAME_USER          = "iam:RenameUser"
 
 	// Group actions
 	GROUP_ACTION_CREATE_GROUP                 = "iam:CreateGroup"
@@ -42,6 +88,10 @@ const (
 	GROUP_ACTION_ATTACH_GROUP_POLICY          = "iam:AttachGroupPolicy"
 	GROUP_ACTION_DETACH_GROUP_POLICY          = "iam:DetachGroupPolicy"
 	GROUP_ACTION_LIST_ATTACHED_GROUP_POLICIES = "iam:ListAttachedGroupPolicies"
+	GROUP_ACTION_TAG_GROUP                    = "iam:TagGroup"
+	GROUP_ACTION_UNTAG_GROUP                  = "iam:UntagGroup"
+	GROUP_ACTION_ADD_GROUP_OWNER              = "iam:AddGroupOwner"
+	GROUP_ACTION_REMOVE_GROUP_OWNER           = "iam:RemoveGroupOwner"
 
 	// Policy actions
 	POLICY_ACTION_CREATE_POLICY        = "iam:CreatePolicy"
@@ -50,6 +100,18 @@ const (
 	POLICY_ACTION_GET_POLICY           = "iam:GetPolicy"
 	POLICY_ACTION_LIST_ATTACHED_GROUPS = "iam:ListAttachedGroups"
 	POLICY_ACTION_LIST_POLICIES        = "iam:ListPolicies"
+	POLICY_ACTION_TAG_POLICY           = "iam:TagPolicy"
+	POLICY_ACTION_UNTAG_POLICY         = "iam:UntagPolicy"
+
+	// Organization actions
+	ORGANIZATION_ACTION_CREATE_ORGANIZATION = "iam:CreateOrganization"
+	ORGANIZATION_ACTION_DELETE_ORGANIZATION = "iam:DeleteOrganization"
+	ORGANIZATION_ACTION_GET_ORGANIZATION    = "iam:GetOrganization"
+	ORGANIZATION_ACTION_LIST_ORGANIZATIONS  = "iam:ListOrganizations"
+	ORGANIZATION_ACTION_UPDATE_ORGANIZATION = "iam:UpdateOrganization"
+
+	// Access request actions
+	ACCESS_REQUEST_ACTION_APPROVE = "iam:ApproveAccessRequest"
 )
 
 var (
@@ -64,12 +126,36 @@ var (
 	rWordResourcePrefix, _ = regexp.Compile(`^[\w+\-_.@]+\*$`)
 	rUrn, _                = regexp.Compile(`^\*$|^[\w+\-@.]+\*?$|^[\w+\-@.]+\*?$|^[\w+\-@.]+(/?([\w+\-@.]+/)*([\w+\-@.]|[*])+)?$`)
 	rUrnExclude, _         = regexp.Compile(`[/]{2,}|[:]{2,}|[*]{2,}`)
+	rTagKey, _             = regexp.Compile(`^[\w\-_]+$`)
+	rTagValue, _           = regexp.Compile(`^[\w+\-_.:@/]+$`)
+	rEmail, _              = regexp.Compile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
 )
 
+// Filter used to retrieve lists. All fields are optional. Name matches a substring of the resource
+// name (external ID for users), CreatedAfter and CreatedBefore bound its creation date and Orphan
+// keeps only users and policies not related to any group or groups without members. SortBy (name
+// or createAt) and Order (asc or desc) sort the results. Offset and Limit page the results,
+// repositories return all results when Limit is 0.
+type Filter struct {
+	PathPrefix    string
+	Org           string
+	Name          string
+	Tags          map[string]string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Orphan        bool
+	SortBy        string
+	Order         string
+	Offset        int
+	Limit         int
+}
+
 func CreateUrn(org string, resource string, path string, name string) string {
 	switch resource {
 	case RESOURCE_USER:
 		return fmt.Sprintf("urn:iws:iam::user%v%v", path, name)
+	case RESOURCE_ORGANIZATION:
+		return fmt.Sprintf("urn:iws:iam::organization%v%v", path, name)
 	default:
 		return fmt.Sprintf("urn:iws:iam:%v:%v%v%v", org, resource, path, name)
 	}
@@ -79,6 +165,8 @@ func GetUrnPrefix(org string, resource string, path string) string {
 	switch resource {
 	case RESOURCE_USER:
 		return fmt.Sprintf("urn:iws:iam::user%v*", path)
+	case RESOURCE_ORGANIZATION:
+		return fmt.Sprintf("urn:iws:iam::organization%v*", path)
 	default:
 		return fmt.Sprintf("urn:iws:iam:%v:%v%v*", org, resource, path)
 	}
@@ -97,10 +185,130 @@ func IsValidName(name string) bool {
 	return rName.MatchString(name) && len(name) < MAX_NAME_LENGTH
 }
 
+// this func validates statement ids, unique inside a policy
+func IsValidSid(sid string) bool {
+	return rName.MatchString(sid) && len(sid) < MAX_NAME_LENGTH
+}
+
 func IsValidPath(path string) bool {
 	return rPath.MatchString(path) && !rPathExclude.MatchString(path) && len(path) < MAX_PATH_LENGTH
 }
 
+// this func validates free text fields like justifications and review comments
+func IsValidJustification(justification string) bool {
+	return len(strings.TrimSpace(justification)) > 0 && len(justification) < MAX_JUSTIFICATION_LENGTH
+}
+
+func IsValidAccessRequestStatus(status string) bool {
+	switch status {
+	case ACCESS_REQUEST_STATUS_PENDING, ACCESS_REQUEST_STATUS_APPROVED, ACCESS_REQUEST_STATUS_REJECTED:
+		return true
+	default:
+		return false
+	}
+}
+
+func IsValidTagKey(key string) bool {
+	return rTagKey.MatchString(key) && len(key) < MAX_TAG_KEY_LENGTH
+}
+
+func IsValidTagValue(value string) bool {
+	return rTagValue.MatchString(value) && len(value) < MAX_TAG_VALUE_LENGTH
+}
+
+// this func validates user emails
+func IsValidEmail(email string) bool {
+	return rEmail.MatchString(email) && len(email) < MAX_EMAIL_LENGTH
+}
+
+// this func validates tag maps received as tags filter or as authorization request resource tags
+func AreValidTags(tags map[string]string) error {
+	for key, value := range tags {
+		if !IsValidTagKey(key) {
+			return &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: tag key %v", key),
+			}
+		}
+		if !IsValidTagValue(value) {
+			return &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: tag value %v", value),
+			}
+		}
+	}
+	return nil
+}
+
+// this func validates list filters, setting default path prefix and limit if they are empty
+func validateFilter(filter *Filter) error {
+	if len(filter.Org) > 0 && !IsValidOrg(filter.Org) {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: org %v", filter.Org),
+		}
+	}
+	if len(filter.PathPrefix) > 0 && !IsValidPath(filter.PathPrefix) {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: PathPrefix %v", filter.PathPrefix),
+		}
+	}
+	if len(filter.Name) > 0 && !IsValidUserExternalID(filter.Name) {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: Name %v", filter.Name),
+		}
+	}
+	if err := AreValidTags(filter.Tags); err != nil {
+		return err
+	}
+	if filter.CreatedAfter != nil && filter.CreatedBefore != nil && !filter.CreatedAfter.Before(*filter.CreatedBefore) {
+		return &Error{
+			Code: INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: CreatedAfter %v must be before CreatedBefore %v",
+				filter.CreatedAfter.Format(time.RFC3339), filter.CreatedBefore.Format(time.RFC3339)),
+		}
+	}
+	switch filter.SortBy {
+	case "", SORT_BY_NAME, SORT_BY_CREATE_AT:
+	default:
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: Sort %v", filter.SortBy),
+		}
+	}
+	switch filter.Order {
+	case "", ORDER_ASC, ORDER_DESC:
+	default:
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: Order %v", filter.Order),
+		}
+	}
+	if filter.Offset < 0 {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: Offset %v", filter.Offset),
+		}
+	}
+	if filter.Limit < 0 || filter.Limit > MAX_LIMIT_SIZE {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: Limit %v, max limit allowed: %v", filter.Limit, MAX_LIMIT_SIZE),
+		}
+	}
+
+	if len(filter.PathPrefix) == 0 {
+		filter.PathPrefix = "/"
+	}
+	if filter.Limit == 0 {
+		filter.Limit = DEFAULT_LIMIT_SIZE
+	}
+
+	return nil
+}
+
 func IsValidEffect(effect string) error {
 	if effect != "allow" && effect != "deny" {
 		return &Error{
@@ -194,7 +402,24 @@ func AreValidResources(resources []string) error {
 }
 
 func AreValidStatements(statements *[]Statement) error {
+	sids := make(map[string]bool, len(*statements))
 	for _, statement := range *statements {
+		// Statements without sid get one when stored
+		if statement.Sid != "" {
+			if !IsValidSid(statement.Sid) {
+				return &Error{
+					Code:    INVALID_PARAMETER_ERROR,
+					Message: fmt.Sprintf("Invalid statement sid: %v", statement.Sid),
+				}
+			}
+			if sids[statement.Sid] {
+				return &Error{
+					Code:    INVALID_PARAMETER_ERROR,
+					Message: fmt.Sprintf("Duplicated statement sid: %v", statement.Sid),
+				}
+			}
+			sids[statement.Sid] = true
+		}
 		err := IsValidEffect(statement.Effect)
 		if err != nil {
 			return err
@@ -221,10 +446,64 @@ func AreValidStatements(statements *[]Statement) error {
 				return err
 			}
 		}
+		err = AreValidConditions(statement.Conditions)
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+func AreValidConditions(conditions []string) error {
+	for _, cond := range conditions {
+		if _, err := parseCondition(cond); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Return the entity tag of a resource, a quoted hash of its content that changes whenever the resource changes
+func ETag(resource Resource) string {
+	content, err := json.Marshal(resource)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("\"%x\"", sha1.Sum(content))
+}
+
+// Check that the entity tags in the If-Match of the request match the current resource.
+// Requests without If-Match aren't conditional
+func checkIfMatch(requestInfo RequestInfo, resource Resource) error {
+	if requestInfo.IfMatch == "" {
+		return nil
+	}
+	current := ETag(resource)
+	for _, tag := range strings.Split(requestInfo.IfMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == current {
+			return nil
+		}
+	}
+	return &Error{
+		Code: PRECONDITION_FAILED_ERROR,
+		Message: fmt.Sprintf("Resource %v has been modified, entity tag %v doesn't match current entity tag %v",
+			resource.GetUrn(), requestInfo.IfMatch, current),
+	}
+}
+
+// Keep API errors and transform DB errors of updates conditioned on the revision of the resource. A revision
+// conflict means that the resource was modified after it was retrieved, so its precondition failed
+func toRevisionAPIError(err error) error {
+	if dbError, ok := err.(*database.Error); ok && dbError.Code == database.REVISION_CONFLICT {
+		return &Error{
+			Code:    PRECONDITION_FAILED_ERROR,
+			Message: dbError.Message,
+		}
+	}
+	return toUnknownAPIError(err)
+}
+
 func LogOperation(logger *logrus.Logger, requestInfo RequestInfo, message string) {
 	logger.WithFields(logrus.Fields{
 		"requestID": requestInfo.RequestID,
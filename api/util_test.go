@@ -576,3 +576,80 @@ func TestAreValidResources(t *testing.T) {
 		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
 	}
 }
+
+func TestETag(t *testing.T) {
+	policy := Policy{
+		ID:   "1234",
+		Name: "policy",
+		Path: "/path/",
+		Org:  "org1",
+		Urn:  CreateUrn("org1", RESOURCE_POLICY, "/path/", "policy"),
+		Statements: &[]Statement{
+			{
+				Effect:    "allow",
+				Actions:   []string{USER_ACTION_GET_USER},
+				Resources: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+			},
+		},
+	}
+	modifiedPolicy := policy
+	modifiedPolicy.Statements = &[]Statement{
+		{
+			Effect:    "deny",
+			Actions:   []string{USER_ACTION_GET_USER},
+			Resources: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+		},
+	}
+
+	etag := ETag(policy)
+	if len(etag) != 42 || etag[0] != '"' || etag[41] != '"' {
+		t.Errorf("Unexpected entity tag format %v", etag)
+	}
+	if etag != ETag(policy) {
+		t.Errorf("Entity tag of the same policy changed")
+	}
+	if etag == ETag(modifiedPolicy) {
+		t.Errorf("Entity tag of modified policy didn't change")
+	}
+}
+
+func TestCheckIfMatch(t *testing.T) {
+	user := User{
+		ID:         "1234",
+		ExternalID: "1234",
+		Path:       "/path/",
+		Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+	}
+	testcases := map[string]struct {
+		// Method args
+		ifMatch string
+		// Expected results
+		wantError error
+	}{
+		"OKCaseUnconditional": {
+			ifMatch: "",
+		},
+		"OKCaseMatch": {
+			ifMatch: ETag(user),
+		},
+		"OKCaseMatchInList": {
+			ifMatch: "\"other\", " + ETag(user),
+		},
+		"OKCaseAny": {
+			ifMatch: "*",
+		},
+		"ErrorCaseNoMatch": {
+			ifMatch: "\"other\"",
+			wantError: &Error{
+				Code: PRECONDITION_FAILED_ERROR,
+				Message: fmt.Sprintf("Resource %v has been modified, entity tag \"other\" doesn't match current entity tag %v",
+					user.Urn, ETag(user)),
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		err := checkIfMatch(RequestInfo{IfMatch: testcase.ifMatch}, user)
+		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+	}
+}
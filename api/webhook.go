@@ -0,0 +1,507 @@
+package api
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/satori/go.uuid"
+	"github.com/tecsisa/foulkon/database"
+)
+
+const (
+	// Webhook delivery status
+	WEBHOOK_DELIVERY_STATUS_PENDING   = "pending"
+	WEBHOOK_DELIVERY_STATUS_DELIVERED = "delivered"
+	WEBHOOK_DELIVERY_STATUS_FAILED    = "failed"
+
+	// Headers of the webhook requests
+	WEBHOOK_EVENT_HEADER     = "X-Foulkon-Event"
+	WEBHOOK_DELIVERY_HEADER  = "X-Foulkon-Delivery"
+	WEBHOOK_SIGNATURE_HEADER = "X-Foulkon-Signature"
+
+	// Attempts to deliver an event before the delivery fails
+	WEBHOOK_MAX_DELIVERY_ATTEMPTS = 5
+	// Time before the first retry, it's doubled in each attempt
+	WEBHOOK_RETRY_INTERVAL = time.Minute
+	// Deliveries sent each time pending deliveries are processed
+	WEBHOOK_DELIVERY_BATCH_SIZE = 100
+	// Time other workers skip the deliveries claimed by a worker. Deliveries not sent by then are left
+	// to be claimed again
+	WEBHOOK_DELIVERY_LEASE = 10 * time.Minute
+
+	MAX_WEBHOOK_URL_LENGTH    = 2048
+	MAX_WEBHOOK_SECRET_LENGTH = 256
+)
+
+// TYPE DEFINITIONS
+
+// Subscription to the changes made through the API. Events are the actions of the audit log that are
+// delivered to the URL, prefixes like iam:* are allowed. Secret signs the requests and it's never returned
+type Webhook struct {
+	ID       string    `json:"id, omitempty"`
+	Url      string    `json:"url, omitempty"`
+	Secret   string    `json:"-"`
+	Events   []string  `json:"events, omitempty"`
+	CreateAt time.Time `json:"createAt, omitempty"`
+	UpdateAt time.Time `json:"updateAt, omitempty"`
+}
+
+func (w Webhook) String() string {
+	return fmt.Sprintf("[id: %v, url: %v, events: %v, createAt: %v, updateAt: %v]",
+		w.ID, w.Url, w.Events, w.CreateAt.Format("2006-01-02 15:04:05 MST"), w.UpdateAt.Format("2006-01-02 15:04:05 MST"))
+}
+
+// Audit event sent to a webhook. Payload is the audit event document, and StatusCode and LastError
+// are the result of the last attempt
+type WebhookDelivery struct {
+	ID            string          `json:"id, omitempty"`
+	WebhookID     string          `json:"webhookId, omitempty"`
+	EventID       string          `json:"eventId, omitempty"`
+	Action        string          `json:"action, omitempty"`
+	Urn           string          `json:"urn, omitempty"`
+	Payload       json.RawMessage `json:"payload, omitempty"`
+	Status        string          `json:"status, omitempty"`
+	Attempts      int             `json:"attempts, omitempty"`
+	StatusCode    int             `json:"statusCode, omitempty"`
+	LastError     string          `json:"lastError, omitempty"`
+	CreateAt      time.Time       `json:"createAt, omitempty"`
+	UpdateAt      time.Time       `json:"updateAt, omitempty"`
+	NextAttemptAt time.Time       `json:"nextAttemptAt, omitempty"`
+}
+
+func (d WebhookDelivery) String() string {
+	return fmt.Sprintf("[id: %v, webhookId: %v, eventId: %v, action: %v, urn: %v, status: %v, attempts: %v]",
+		d.ID, d.WebhookID, d.EventID, d.Action, d.Urn, d.Status, d.Attempts)
+}
+
+// WEBHOOK API IMPLEMENTATION
+
+func (api AuthAPI) AddWebhook(requestInfo RequestInfo, webhookUrl string, secret string, events []string) (*Webhook, error) {
+	if err := checkWebhookAdmin(requestInfo); err != nil {
+		return nil, err
+	}
+
+	// Validate fields
+	if err := validateWebhook(webhookUrl, secret, events); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	webhook := Webhook{
+		ID:       uuid.NewV4().String(),
+		Url:      webhookUrl,
+		Secret:   secret,
+		Events:   events,
+		CreateAt: now,
+		UpdateAt: now,
+	}
+
+	var createdWebhook *Webhook
+	err := api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		var err error
+		createdWebhook, err = repo.AddWebhook(webhook)
+		if err != nil {
+			return err
+		}
+		return api.auditOperation(repo, requestInfo, AUDIT_ACTION_CREATE_WEBHOOK, getWebhookUrn(createdWebhook.ID), nil, createdWebhook)
+	})
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Webhook created %+v", createdWebhook))
+	return createdWebhook, nil
+}
+
+func (api AuthAPI) GetWebhookByID(requestInfo RequestInfo, id string) (*Webhook, error) {
+	if err := checkWebhookAdmin(requestInfo); err != nil {
+		return nil, err
+	}
+
+	return api.getWebhook(id)
+}
+
+func (api AuthAPI) ListWebhooks(requestInfo RequestInfo, filter *Filter) ([]Webhook, int, error) {
+	if err := checkWebhookAdmin(requestInfo); err != nil {
+		return nil, 0, err
+	}
+
+	// Validate fields
+	if err := validateFilter(filter); err != nil {
+		return nil, 0, err
+	}
+
+	// Call repo to retrieve the webhooks
+	webhooks, total, err := api.WebhookRepo.GetWebhooksFiltered(filter)
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, 0, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	return webhooks, total, nil
+}
+
+func (api AuthAPI) UpdateWebhook(requestInfo RequestInfo, id string, newUrl string, newSecret string, newEvents []string) (*Webhook, error) {
+	if err := checkWebhookAdmin(requestInfo); err != nil {
+		return nil, err
+	}
+
+	// Validate fields. Current secret is kept if the new one is empty
+	webhook, err := api.getWebhook(id)
+	if err != nil {
+		return nil, err
+	}
+	if newSecret == "" {
+		newSecret = webhook.Secret
+	}
+	if err := validateWebhook(newUrl, newSecret, newEvents); err != nil {
+		return nil, err
+	}
+
+	webhookToUpdate := *webhook
+	webhookToUpdate.Url = newUrl
+	webhookToUpdate.Secret = newSecret
+	webhookToUpdate.Events = newEvents
+	webhookToUpdate.UpdateAt = time.Now().UTC()
+
+	var updatedWebhook *Webhook
+	err = api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		var err error
+		updatedWebhook, err = repo.UpdateWebhook(webhookToUpdate)
+		if err != nil {
+			return err
+		}
+		return api.auditOperation(repo, requestInfo, AUDIT_ACTION_UPDATE_WEBHOOK, getWebhookUrn(id), webhook, updatedWebhook)
+	})
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Webhook updated from %+v to %+v", webhook, updatedWebhook))
+	return updatedWebhook, nil
+}
+
+func (api AuthAPI) RemoveWebhook(requestInfo RequestInfo, id string) error {
+	if err := checkWebhookAdmin(requestInfo); err != nil {
+		return err
+	}
+
+	// Call repo to retrieve the webhook
+	webhook, err := api.getWebhook(id)
+	if err != nil {
+		return err
+	}
+
+	err = api.TransactionRepo.RunInTransaction(func(repo Repo) error {
+		if err := repo.RemoveWebhook(id); err != nil {
+			return err
+		}
+		return api.auditOperation(repo, requestInfo, AUDIT_ACTION_DELETE_WEBHOOK, getWebhookUrn(id), webhook, nil)
+	})
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Webhook deleted %+v", webhook))
+	return nil
+}
+
+func (api AuthAPI) ListWebhookDeliveries(requestInfo RequestInfo, id string, status string, filter *Filter) ([]WebhookDelivery, int, error) {
+	if err := checkWebhookAdmin(requestInfo); err != nil {
+		return nil, 0, err
+	}
+
+	// Validate fields
+	switch status {
+	case "", WEBHOOK_DELIVERY_STATUS_PENDING, WEBHOOK_DELIVERY_STATUS_DELIVERED, WEBHOOK_DELIVERY_STATUS_FAILED:
+	default:
+		return nil, 0, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: status %v", status),
+		}
+	}
+	if err := validateFilter(filter); err != nil {
+		return nil, 0, err
+	}
+
+	// Check that webhook exists
+	if _, err := api.getWebhook(id); err != nil {
+		return nil, 0, err
+	}
+
+	// Call repo to retrieve the deliveries
+	deliveries, total, err := api.WebhookRepo.GetWebhookDeliveriesFiltered(id, status, filter)
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, 0, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	return deliveries, total, nil
+}
+
+func (api AuthAPI) DeliverWebhookEvents(requestInfo RequestInfo) (int, error) {
+	if err := checkWebhookAdmin(requestInfo); err != nil {
+		return 0, err
+	}
+
+	// Call repo to claim the deliveries whose next attempt is due, so concurrent workers don't send them too
+	now := time.Now().UTC()
+	leaseUntil := now.Add(WEBHOOK_DELIVERY_LEASE)
+	deliveries, err := api.WebhookRepo.ClaimPendingWebhookDeliveries(now, leaseUntil, WEBHOOK_DELIVERY_BATCH_SIZE)
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return 0, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	delivered := 0
+	sent := 0
+	webhooks := map[string]*Webhook{}
+	for _, delivery := range deliveries {
+		// Other workers can claim the deliveries again once the lease ends
+		if !time.Now().UTC().Before(leaseUntil) {
+			break
+		}
+
+		// Webhooks that can't be retrieved don't stop the rest of the batch. Deliveries of removed
+		// webhooks fail, and the rest are claimed again when their lease ends
+		webhook, ok := webhooks[delivery.WebhookID]
+		if !ok {
+			webhook, err = api.getWebhook(delivery.WebhookID)
+			if err != nil {
+				apiError := err.(*Error)
+				LogErrorMessage(api.Logger, requestInfo, apiError)
+				if apiError.Code != WEBHOOK_NOT_FOUND {
+					continue
+				}
+			}
+			webhooks[delivery.WebhookID] = webhook
+		}
+
+		if webhook == nil {
+			delivery.Status = WEBHOOK_DELIVERY_STATUS_FAILED
+			delivery.LastError = fmt.Sprintf("Webhook with id %v not found", delivery.WebhookID)
+			delivery.UpdateAt = time.Now().UTC()
+		} else {
+			sent++
+
+			// Failed attempts are retried with exponential backoff until the maximum attempts are reached
+			delivery.StatusCode, err = api.sendWebhookEvent(*webhook, delivery)
+			delivery.Attempts++
+			delivery.UpdateAt = time.Now().UTC()
+			if err == nil {
+				delivery.Status = WEBHOOK_DELIVERY_STATUS_DELIVERED
+				delivery.LastError = ""
+				delivered++
+			} else {
+				delivery.LastError = err.Error()
+				if delivery.Attempts >= WEBHOOK_MAX_DELIVERY_ATTEMPTS {
+					delivery.Status = WEBHOOK_DELIVERY_STATUS_FAILED
+				} else {
+					delivery.NextAttemptAt = delivery.UpdateAt.Add(WEBHOOK_RETRY_INTERVAL << uint(delivery.Attempts-1))
+				}
+			}
+		}
+
+		if _, err := api.WebhookRepo.UpdateWebhookDelivery(delivery); err != nil {
+			//Transform to DB error
+			dbError := err.(*database.Error)
+			return delivered, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	// Deliveries aren't audited, they would create new deliveries
+	if len(deliveries) > 0 {
+		LogOperation(api.Logger, requestInfo, fmt.Sprintf("%v of %v webhook deliveries sent, %v claimed", delivered, sent, len(deliveries)))
+	}
+	return delivered, nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Only admin can manage webhooks, because they receive the changes of every organization
+func checkWebhookAdmin(requestInfo RequestInfo) error {
+	if !requestInfo.Admin {
+		return &Error{
+			Code:    UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to manage webhooks", requestInfo.Identifier),
+		}
+	}
+	return nil
+}
+
+func validateWebhook(webhookUrl string, secret string, events []string) error {
+	parsedUrl, err := url.Parse(webhookUrl)
+	if err != nil || (parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https") || parsedUrl.Host == "" ||
+		len(webhookUrl) > MAX_WEBHOOK_URL_LENGTH {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: url %v", webhookUrl),
+		}
+	}
+	if len(secret) < 1 || len(secret) > MAX_WEBHOOK_SECRET_LENGTH {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: secret, it must have between 1 and %v characters", MAX_WEBHOOK_SECRET_LENGTH),
+		}
+	}
+	if len(events) < 1 {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: "Invalid parameter: events, they can't be empty",
+		}
+	}
+	if err := AreValidActions(events); err != nil {
+		// Transform to API error
+		apiError := err.(*Error)
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: apiError.Message,
+		}
+	}
+	return nil
+}
+
+func (api AuthAPI) getWebhook(id string) (*Webhook, error) {
+	// Call repo to retrieve the webhook
+	webhook, err := api.WebhookRepo.GetWebhookByID(id)
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		switch dbError.Code {
+		case database.WEBHOOK_NOT_FOUND:
+			return nil, &Error{
+				Code:    WEBHOOK_NOT_FOUND,
+				Message: dbError.Message,
+			}
+		default: // Unexpected error
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+	}
+
+	return webhook, nil
+}
+
+// Store a pending delivery of the audit event for each webhook subscribed to its action, with the repository
+// of the operation transaction
+func enqueueWebhookDeliveries(repo Repo, event AuditEvent) error {
+	webhooks, _, err := repo.GetWebhooksFiltered(&Filter{})
+	if err != nil {
+		return err
+	}
+
+	payload := toAuditDocument(event)
+	for _, webhook := range webhooks {
+		if !isActionContained(event.Action, webhook.Events) {
+			continue
+		}
+		delivery := WebhookDelivery{
+			ID:            uuid.NewV4().String(),
+			WebhookID:     webhook.ID,
+			EventID:       event.ID,
+			Action:        event.Action,
+			Urn:           event.Urn,
+			Payload:       payload,
+			Status:        WEBHOOK_DELIVERY_STATUS_PENDING,
+			CreateAt:      event.CreateAt,
+			UpdateAt:      event.CreateAt,
+			NextAttemptAt: event.CreateAt,
+		}
+		if _, err := repo.AddWebhookDelivery(delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Post the delivery payload to the webhook, returning the response status code
+func (api AuthAPI) sendWebhookEvent(webhook Webhook, delivery WebhookDelivery) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, webhook.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WEBHOOK_EVENT_HEADER, delivery.Action)
+	req.Header.Set(WEBHOOK_DELIVERY_HEADER, delivery.ID)
+	req.Header.Set(WEBHOOK_SIGNATURE_HEADER, signWebhookPayload(webhook.Secret, delivery.Payload))
+
+	client := api.WebhookClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return res.StatusCode, fmt.Errorf("Unexpected status code %v", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+// HMAC-SHA256 signature of the payload with the webhook secret, e.g. sha256=5257a869...
+func signWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// URN of a webhook in the audit log, e.g. urn:iws:iam::webhook/8c5cd3ad-...
+func getWebhookUrn(id string) string {
+	return CreateUrn("", AUDIT_ENTITY_WEBHOOK, "/", id)
+}
@@ -0,0 +1,743 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestAuthAPI_AddWebhook(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		url         string
+		secret      string
+		events      []string
+		// Expected result
+		expectedResponse *Webhook
+		wantError        error
+		// Manager Results
+		addWebhookMethodResult *Webhook
+		// Manager Errors
+		addWebhookMethodErr error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			url:    "https://example.com/hook",
+			secret: "secret",
+			events: []string{GROUP_ACTION_DETACH_GROUP_POLICY, "iam:Add*"},
+			expectedResponse: &Webhook{
+				ID:     "WEBHOOK-ID",
+				Url:    "https://example.com/hook",
+				Secret: "secret",
+				Events: []string{GROUP_ACTION_DETACH_GROUP_POLICY, "iam:Add*"},
+			},
+			addWebhookMethodResult: &Webhook{
+				ID:     "WEBHOOK-ID",
+				Url:    "https://example.com/hook",
+				Secret: "secret",
+				Events: []string{GROUP_ACTION_DETACH_GROUP_POLICY, "iam:Add*"},
+			},
+		},
+		"ErrorCaseNotAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			url:    "https://example.com/hook",
+			secret: "secret",
+			events: []string{GROUP_ACTION_DETACH_GROUP_POLICY},
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to manage webhooks",
+			},
+		},
+		"ErrorCaseInvalidUrl": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			url:    "ftp://example.com/hook",
+			secret: "secret",
+			events: []string{GROUP_ACTION_DETACH_GROUP_POLICY},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: url ftp://example.com/hook",
+			},
+		},
+		"ErrorCaseEmptySecret": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			url:    "https://example.com/hook",
+			events: []string{GROUP_ACTION_DETACH_GROUP_POLICY},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: secret, it must have between 1 and 256 characters",
+			},
+		},
+		"ErrorCaseEmptyEvents": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			url:    "https://example.com/hook",
+			secret: "secret",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: events, they can't be empty",
+			},
+		},
+		"ErrorCaseInvalidEvent": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			url:    "https://example.com/hook",
+			secret: "secret",
+			events: []string{"iam:**"},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "No regex match in action: iam:**",
+			},
+		},
+		"ErrorCaseInternalError": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			url:    "https://example.com/hook",
+			secret: "secret",
+			events: []string{GROUP_ACTION_DETACH_GROUP_POLICY},
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			addWebhookMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[AddWebhookMethod][0] = testcase.addWebhookMethodResult
+		testRepo.ArgsOut[AddWebhookMethod][1] = testcase.addWebhookMethodErr
+
+		webhook, err := testAPI.AddWebhook(testcase.requestInfo, testcase.url, testcase.secret, testcase.events)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, webhook)
+	}
+}
+
+func TestAuthAPI_UpdateWebhook(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		id          string
+		newUrl      string
+		newSecret   string
+		newEvents   []string
+		// Expected result
+		expectedResponse *Webhook
+		expectedWebhook  *Webhook
+		wantError        error
+		// Manager Results
+		getWebhookByIDMethodResult *Webhook
+		updateWebhookMethodResult  *Webhook
+		// Manager Errors
+		getWebhookByIDMethodErr error
+		updateWebhookMethodErr  error
+	}{
+		"OkCaseSecretKept": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id:        "WEBHOOK-ID",
+			newUrl:    "https://example.com/newhook",
+			newEvents: []string{"iam:*"},
+			expectedResponse: &Webhook{
+				ID:     "WEBHOOK-ID",
+				Url:    "https://example.com/newhook",
+				Secret: "secret",
+				Events: []string{"iam:*"},
+			},
+			expectedWebhook: &Webhook{
+				ID:     "WEBHOOK-ID",
+				Url:    "https://example.com/newhook",
+				Secret: "secret",
+				Events: []string{"iam:*"},
+			},
+			getWebhookByIDMethodResult: &Webhook{
+				ID:     "WEBHOOK-ID",
+				Url:    "https://example.com/hook",
+				Secret: "secret",
+				Events: []string{GROUP_ACTION_DETACH_GROUP_POLICY},
+			},
+			updateWebhookMethodResult: &Webhook{
+				ID:     "WEBHOOK-ID",
+				Url:    "https://example.com/newhook",
+				Secret: "secret",
+				Events: []string{"iam:*"},
+			},
+		},
+		"ErrorCaseNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id:        "WEBHOOK-ID",
+			newUrl:    "https://example.com/newhook",
+			newEvents: []string{"iam:*"},
+			wantError: &Error{
+				Code:    WEBHOOK_NOT_FOUND,
+				Message: "Webhook with id WEBHOOK-ID not found",
+			},
+			getWebhookByIDMethodErr: &database.Error{
+				Code:    database.WEBHOOK_NOT_FOUND,
+				Message: "Webhook with id WEBHOOK-ID not found",
+			},
+		},
+		"ErrorCaseInvalidUrl": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id:        "WEBHOOK-ID",
+			newUrl:    "example.com",
+			newEvents: []string{"iam:*"},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: url example.com",
+			},
+			getWebhookByIDMethodResult: &Webhook{
+				ID:     "WEBHOOK-ID",
+				Secret: "secret",
+			},
+		},
+		"ErrorCaseInternalError": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id:        "WEBHOOK-ID",
+			newUrl:    "https://example.com/newhook",
+			newSecret: "newsecret",
+			newEvents: []string{"iam:*"},
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getWebhookByIDMethodResult: &Webhook{
+				ID:     "WEBHOOK-ID",
+				Secret: "secret",
+			},
+			updateWebhookMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetWebhookByIDMethod][0] = testcase.getWebhookByIDMethodResult
+		testRepo.ArgsOut[GetWebhookByIDMethod][1] = testcase.getWebhookByIDMethodErr
+		testRepo.ArgsOut[UpdateWebhookMethod][0] = testcase.updateWebhookMethodResult
+		testRepo.ArgsOut[UpdateWebhookMethod][1] = testcase.updateWebhookMethodErr
+
+		webhook, err := testAPI.UpdateWebhook(testcase.requestInfo, testcase.id, testcase.newUrl, testcase.newSecret, testcase.newEvents)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, webhook)
+		if testcase.expectedWebhook != nil {
+			received := testRepo.ArgsIn[UpdateWebhookMethod][0].(Webhook)
+			received.UpdateAt = time.Time{}
+			if diff := pretty.Compare(received, *testcase.expectedWebhook); diff != "" {
+				t.Errorf("Test %v failed. Received different webhooks to update (received/wanted) %v", x, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestAuthAPI_RemoveWebhook(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		id          string
+		// Expected result
+		wantError error
+		// Manager Results
+		getWebhookByIDMethodResult *Webhook
+		// Manager Errors
+		getWebhookByIDMethodErr error
+		removeWebhookMethodErr  error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id: "WEBHOOK-ID",
+			getWebhookByIDMethodResult: &Webhook{
+				ID: "WEBHOOK-ID",
+			},
+		},
+		"ErrorCaseNotAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			id: "WEBHOOK-ID",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to manage webhooks",
+			},
+		},
+		"ErrorCaseNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id: "WEBHOOK-ID",
+			wantError: &Error{
+				Code:    WEBHOOK_NOT_FOUND,
+				Message: "Webhook with id WEBHOOK-ID not found",
+			},
+			getWebhookByIDMethodErr: &database.Error{
+				Code:    database.WEBHOOK_NOT_FOUND,
+				Message: "Webhook with id WEBHOOK-ID not found",
+			},
+		},
+		"ErrorCaseInternalError": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id: "WEBHOOK-ID",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getWebhookByIDMethodResult: &Webhook{
+				ID: "WEBHOOK-ID",
+			},
+			removeWebhookMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetWebhookByIDMethod][0] = testcase.getWebhookByIDMethodResult
+		testRepo.ArgsOut[GetWebhookByIDMethod][1] = testcase.getWebhookByIDMethodErr
+		testRepo.ArgsOut[RemoveWebhookMethod][0] = testcase.removeWebhookMethodErr
+
+		err := testAPI.RemoveWebhook(testcase.requestInfo, testcase.id)
+		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+	}
+}
+
+func TestAuthAPI_ListWebhookDeliveries(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		id          string
+		status      string
+		filter      *Filter
+		// Expected result
+		expectedResponse []WebhookDelivery
+		expectedTotal    int
+		wantError        error
+		// Manager Results
+		getWebhookByIDMethodResult               *Webhook
+		getWebhookDeliveriesFilteredMethodResult []WebhookDelivery
+		getWebhookDeliveriesFilteredMethodTotal  int
+		// Manager Errors
+		getWebhookByIDMethodErr error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id:     "WEBHOOK-ID",
+			status: WEBHOOK_DELIVERY_STATUS_FAILED,
+			filter: &Filter{},
+			expectedResponse: []WebhookDelivery{
+				{
+					ID:        "DELIVERY-ID",
+					WebhookID: "WEBHOOK-ID",
+					Status:    WEBHOOK_DELIVERY_STATUS_FAILED,
+					Attempts:  WEBHOOK_MAX_DELIVERY_ATTEMPTS,
+				},
+			},
+			expectedTotal: 1,
+			getWebhookByIDMethodResult: &Webhook{
+				ID: "WEBHOOK-ID",
+			},
+			getWebhookDeliveriesFilteredMethodResult: []WebhookDelivery{
+				{
+					ID:        "DELIVERY-ID",
+					WebhookID: "WEBHOOK-ID",
+					Status:    WEBHOOK_DELIVERY_STATUS_FAILED,
+					Attempts:  WEBHOOK_MAX_DELIVERY_ATTEMPTS,
+				},
+			},
+			getWebhookDeliveriesFilteredMethodTotal: 1,
+		},
+		"ErrorCaseInvalidStatus": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id:     "WEBHOOK-ID",
+			status: "lost",
+			filter: &Filter{},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: status lost",
+			},
+		},
+		"ErrorCaseNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			id:     "WEBHOOK-ID",
+			filter: &Filter{},
+			wantError: &Error{
+				Code:    WEBHOOK_NOT_FOUND,
+				Message: "Webhook with id WEBHOOK-ID not found",
+			},
+			getWebhookByIDMethodErr: &database.Error{
+				Code:    database.WEBHOOK_NOT_FOUND,
+				Message: "Webhook with id WEBHOOK-ID not found",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetWebhookByIDMethod][0] = testcase.getWebhookByIDMethodResult
+		testRepo.ArgsOut[GetWebhookByIDMethod][1] = testcase.getWebhookByIDMethodErr
+		testRepo.ArgsOut[GetWebhookDeliveriesFilteredMethod][0] = testcase.getWebhookDeliveriesFilteredMethodResult
+		testRepo.ArgsOut[GetWebhookDeliveriesFilteredMethod][1] = testcase.getWebhookDeliveriesFilteredMethodTotal
+
+		deliveries, total, err := testAPI.ListWebhookDeliveries(testcase.requestInfo, testcase.id, testcase.status, testcase.filter)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, deliveries)
+		if testcase.wantError == nil && total != testcase.expectedTotal {
+			t.Errorf("Test %v failed. Received different total (wanted:%v / received:%v)", x, testcase.expectedTotal, total)
+		}
+	}
+}
+
+func TestAuthAPI_DeliverWebhookEvents(t *testing.T) {
+	payload := json.RawMessage(`{"action":"iam:DetachGroupPolicy"}`)
+	testcases := map[string]struct {
+		// Webhook response
+		statusCode int
+		// Previous delivery attempts
+		attempts int
+		// Expected result
+		expectedDelivered int
+		expectedStatus    string
+		expectedRetry     bool
+	}{
+		"OkCaseDelivered": {
+			statusCode:        http.StatusOK,
+			expectedDelivered: 1,
+			expectedStatus:    WEBHOOK_DELIVERY_STATUS_DELIVERED,
+		},
+		"OkCaseRetried": {
+			statusCode:     http.StatusInternalServerError,
+			attempts:       1,
+			expectedStatus: WEBHOOK_DELIVERY_STATUS_PENDING,
+			expectedRetry:  true,
+		},
+		"OkCaseFailed": {
+			statusCode:     http.StatusInternalServerError,
+			attempts:       WEBHOOK_MAX_DELIVERY_ATTEMPTS - 1,
+			expectedStatus: WEBHOOK_DELIVERY_STATUS_FAILED,
+		},
+	}
+
+	for x, testcase := range testcases {
+		var receivedHeader http.Header
+		var receivedBody []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			receivedHeader = r.Header
+			receivedBody, _ = ioutil.ReadAll(r.Body)
+			w.WriteHeader(testcase.statusCode)
+		}))
+
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		now := time.Now().UTC()
+		testRepo.ArgsOut[ClaimPendingWebhookDeliveriesMethod][0] = []WebhookDelivery{
+			{
+				ID:            "DELIVERY-ID",
+				WebhookID:     "WEBHOOK-ID",
+				Action:        GROUP_ACTION_DETACH_GROUP_POLICY,
+				Payload:       payload,
+				Status:        WEBHOOK_DELIVERY_STATUS_PENDING,
+				Attempts:      testcase.attempts,
+				NextAttemptAt: now,
+			},
+		}
+		testRepo.ArgsOut[GetWebhookByIDMethod][0] = &Webhook{
+			ID:     "WEBHOOK-ID",
+			Url:    server.URL,
+			Secret: "secret",
+		}
+
+		delivered, err := testAPI.DeliverWebhookEvents(RequestInfo{Identifier: "admin", Admin: true})
+		server.Close()
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", x, err)
+			continue
+		}
+		if delivered != testcase.expectedDelivered {
+			t.Errorf("Test %v failed. Received different delivered number (wanted:%v / received:%v)", x, testcase.expectedDelivered, delivered)
+			continue
+		}
+
+		// Check claim lease
+		claim := testRepo.ArgsIn[ClaimPendingWebhookDeliveriesMethod]
+		if lease := claim[1].(time.Time).Sub(claim[0].(time.Time)); lease != WEBHOOK_DELIVERY_LEASE {
+			t.Errorf("Test %v failed. Received different claim lease %v", x, lease)
+			continue
+		}
+
+		// Check request
+		if string(receivedBody) != string(payload) {
+			t.Errorf("Test %v failed. Received different payload %v", x, string(receivedBody))
+			continue
+		}
+		if receivedHeader.Get(WEBHOOK_SIGNATURE_HEADER) != signWebhookPayload("secret", payload) ||
+			receivedHeader.Get(WEBHOOK_EVENT_HEADER) != GROUP_ACTION_DETACH_GROUP_POLICY ||
+			receivedHeader.Get(WEBHOOK_DELIVERY_HEADER) != "DELIVERY-ID" {
+			t.Errorf("Test %v failed. Received different headers %v", x, receivedHeader)
+			continue
+		}
+
+		// Check updated delivery
+		updated := testRepo.ArgsIn[UpdateWebhookDeliveryMethod][0].(WebhookDelivery)
+		if updated.Status != testcase.expectedStatus || updated.Attempts != testcase.attempts+1 ||
+			updated.StatusCode != testcase.statusCode {
+			t.Errorf("Test %v failed. Received different delivery %v", x, updated)
+			continue
+		}
+		if retry := updated.NextAttemptAt.After(now); retry != testcase.expectedRetry {
+			t.Errorf("Test %v failed. Received different next attempt %v", x, updated.NextAttemptAt)
+			continue
+		}
+	}
+}
+
+func TestAuthAPI_DeliverWebhookEventsWithMissingWebhook(t *testing.T) {
+	received := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	testRepo := makeTestRepo()
+	testAPI := makeTestAPI(testRepo)
+
+	now := time.Now().UTC()
+	testRepo.ArgsOut[ClaimPendingWebhookDeliveriesMethod][0] = []WebhookDelivery{
+		{
+			ID:            "DELIVERY-ID1",
+			WebhookID:     "WEBHOOK-ID",
+			Status:        WEBHOOK_DELIVERY_STATUS_PENDING,
+			NextAttemptAt: now,
+		},
+		{
+			ID:            "DELIVERY-ID2",
+			WebhookID:     "REMOVED-WEBHOOK-ID",
+			Status:        WEBHOOK_DELIVERY_STATUS_PENDING,
+			NextAttemptAt: now,
+		},
+		{
+			ID:            "DELIVERY-ID3",
+			WebhookID:     "WEBHOOK-ID",
+			Status:        WEBHOOK_DELIVERY_STATUS_PENDING,
+			NextAttemptAt: now,
+		},
+	}
+	testRepo.SpecialFuncs[GetWebhookByIDMethod] = func(id string) (*Webhook, error) {
+		if id != "WEBHOOK-ID" {
+			return nil, &database.Error{
+				Code:    database.WEBHOOK_NOT_FOUND,
+				Message: "Webhook with id " + id + " not found",
+			}
+		}
+		return &Webhook{
+			ID:     id,
+			Url:    server.URL,
+			Secret: "secret",
+		}, nil
+	}
+	updated := map[string]WebhookDelivery{}
+	testRepo.SpecialFuncs[UpdateWebhookDeliveryMethod] = func(delivery WebhookDelivery) (*WebhookDelivery, error) {
+		updated[delivery.ID] = delivery
+		return &delivery, nil
+	}
+
+	delivered, err := testAPI.DeliverWebhookEvents(RequestInfo{Identifier: "admin", Admin: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if delivered != 2 || received != 2 {
+		t.Errorf("Received different delivered number (wanted:2 / delivered:%v, received:%v)", delivered, received)
+	}
+	for _, id := range []string{"DELIVERY-ID1", "DELIVERY-ID3"} {
+		if updated[id].Status != WEBHOOK_DELIVERY_STATUS_DELIVERED {
+			t.Errorf("Received different delivery %v", updated[id])
+		}
+	}
+	if missing := updated["DELIVERY-ID2"]; missing.Status != WEBHOOK_DELIVERY_STATUS_FAILED || missing.Attempts != 0 {
+		t.Errorf("Received different delivery of removed webhook %v", missing)
+	}
+}
+
+func TestEnqueueWebhookDeliveries(t *testing.T) {
+	now := time.Now().UTC()
+	event := AuditEvent{
+		ID:       "EVENT-ID",
+		Actor:    "admin",
+		Action:   GROUP_ACTION_DETACH_GROUP_POLICY,
+		Urn:      CreateUrn("example", RESOURCE_GROUP, "/path/", "group"),
+		CreateAt: now,
+	}
+	testcases := map[string]struct {
+		// Manager Results
+		getWebhooksFilteredMethodResult []Webhook
+		// Expected result
+		expectedDeliveries []WebhookDelivery
+		wantError          error
+		// Manager Errors
+		addWebhookDeliveryMethodErr error
+	}{
+		"OkCaseSubscribed": {
+			getWebhooksFilteredMethodResult: []Webhook{
+				{
+					ID:     "WEBHOOK-ID1",
+					Events: []string{GROUP_ACTION_DETACH_GROUP_POLICY},
+				},
+				{
+					ID:     "WEBHOOK-ID2",
+					Events: []string{USER_ACTION_CREATE_USER},
+				},
+				{
+					ID:     "WEBHOOK-ID3",
+					Events: []string{"iam:*"},
+				},
+			},
+			expectedDeliveries: []WebhookDelivery{
+				{
+					WebhookID:     "WEBHOOK-ID1",
+					EventID:       "EVENT-ID",
+					Action:        GROUP_ACTION_DETACH_GROUP_POLICY,
+					Urn:           CreateUrn("example", RESOURCE_GROUP, "/path/", "group"),
+					Payload:       toAuditDocument(event),
+					Status:        WEBHOOK_DELIVERY_STATUS_PENDING,
+					CreateAt:      now,
+					UpdateAt:      now,
+					NextAttemptAt: now,
+				},
+				{
+					WebhookID:     "WEBHOOK-ID3",
+					EventID:       "EVENT-ID",
+					Action:        GROUP_ACTION_DETACH_GROUP_POLICY,
+					Urn:           CreateUrn("example", RESOURCE_GROUP, "/path/", "group"),
+					Payload:       toAuditDocument(event),
+					Status:        WEBHOOK_DELIVERY_STATUS_PENDING,
+					CreateAt:      now,
+					UpdateAt:      now,
+					NextAttemptAt: now,
+				},
+			},
+		},
+		"OkCaseNotSubscribed": {
+			getWebhooksFilteredMethodResult: []Webhook{
+				{
+					ID:     "WEBHOOK-ID",
+					Events: []string{"iam:Create*"},
+				},
+			},
+			expectedDeliveries: []WebhookDelivery{},
+		},
+		"ErrorCaseStoreError": {
+			getWebhooksFilteredMethodResult: []Webhook{
+				{
+					ID:     "WEBHOOK-ID",
+					Events: []string{"iam:*"},
+				},
+			},
+			wantError: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+			addWebhookDeliveryMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+
+		testRepo.ArgsOut[GetWebhooksFilteredMethod][0] = testcase.getWebhooksFilteredMethodResult
+		deliveries := []WebhookDelivery{}
+		testRepo.SpecialFuncs[AddWebhookDeliveryMethod] = func(delivery WebhookDelivery) (*WebhookDelivery, error) {
+			if testcase.addWebhookDeliveryMethodErr != nil {
+				return nil, testcase.addWebhookDeliveryMethodErr
+			}
+			deliveries = append(deliveries, delivery)
+			return &delivery, nil
+		}
+
+		err := enqueueWebhookDeliveries(testRepo, event)
+		if diff := pretty.Compare(err, testcase.wantError); diff != "" {
+			t.Errorf("Test %v failed. Received different errors (received/wanted) %v", x, diff)
+			continue
+		}
+		if testcase.wantError != nil {
+			continue
+		}
+
+		for i := range deliveries {
+			if deliveries[i].ID == "" {
+				t.Errorf("Test %v failed. Delivery without id %v", x, deliveries[i])
+			}
+			deliveries[i].ID = ""
+		}
+		if diff := pretty.Compare(deliveries, testcase.expectedDeliveries); diff != "" {
+			t.Errorf("Test %v failed. Received different deliveries (received/wanted) %v", x, diff)
+			continue
+		}
+	}
+}
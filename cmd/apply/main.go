@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/tecsisa/foulkon/api"
+	internalhttp "github.com/tecsisa/foulkon/http"
+)
+
+// Apply the desired state of an organization stored in a file. It shows the plan of changes computed
+// by the worker and applies it after confirmation.
+func main() {
+	fs := flag.NewFlagSet("apply", flag.ExitOnError)
+	workerURL := fs.String("worker-url", "http://localhost:8000", "Worker url")
+	org := fs.String("org", "", "Organization to apply the desired state")
+	file := fs.String("file", "", "File with the desired state of the organization, as returned by the export")
+	adminUser := fs.String("admin-user", "", "Admin user, for basic authentication")
+	adminPassword := fs.String("admin-password", "", "Admin password, for basic authentication")
+	token := fs.String("token", "", "Bearer token, when admin user isn't used")
+	planOnly := fs.Bool("plan-only", false, "Show the plan without applying it")
+	autoApprove := fs.Bool("auto-approve", false, "Apply the plan without asking for confirmation")
+
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+
+	// Read desired state
+	content, err := os.Open(*file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot read desired state file %v, error: %v\n", *file, err)
+		os.Exit(1)
+	}
+	document := &api.OrganizationDocument{}
+	err = json.NewDecoder(content).Decode(document)
+	content.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot parse desired state file %v, error: %v\n", *file, err)
+		os.Exit(1)
+	}
+
+	client := &workerClient{
+		url:           strings.TrimSuffix(*workerURL, "/") + "/api/v1/organizations/" + *org,
+		adminUser:     *adminUser,
+		adminPassword: *adminPassword,
+		token:         *token,
+	}
+
+	// Compute and show plan
+	plan := &api.Plan{}
+	if err := client.post("/plan", document, plan); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot plan organization %v, error: %v\n", *org, err)
+		os.Exit(1)
+	}
+	if len(plan.Changes) == 0 {
+		fmt.Printf("No changes, organization %v matches the desired state\n", *org)
+		os.Exit(0)
+	}
+	fmt.Printf("Plan for organization %v:\n", *org)
+	for _, change := range plan.Changes {
+		fmt.Printf("  %v\n", change)
+	}
+	if *planOnly {
+		os.Exit(0)
+	}
+
+	// Ask for confirmation
+	if !*autoApprove {
+		fmt.Print("Apply these changes? Only 'yes' will be accepted: ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if strings.TrimSpace(answer) != "yes" {
+			fmt.Println("Apply cancelled")
+			os.Exit(1)
+		}
+	}
+
+	// Apply confirmed plan
+	applied := &api.Plan{}
+	request := &internalhttp.ApplyOrganizationRequest{
+		Document: document,
+		Plan:     plan,
+	}
+	if err := client.post("/apply", request, applied); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot apply plan to organization %v, error: %v\n", *org, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Applied %v changes to organization %v\n", len(applied.Changes), *org)
+}
+
+// Client of the organization API of the worker
+type workerClient struct {
+	url           string
+	adminUser     string
+	adminPassword string
+	token         string
+}
+
+// Send request to the worker and decode its response. API errors are returned as *api.Error
+func (c *workerClient) post(path string, request interface{}, response interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.url+path, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.adminUser != "" {
+		req.SetBasicAuth(c.adminUser, c.adminPassword)
+	} else if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		apiError := &api.Error{}
+		if err := json.NewDecoder(res.Body).Decode(apiError); err != nil {
+			return fmt.Errorf("unexpected status %v", res.Status)
+		}
+		return apiError
+	}
+	return json.NewDecoder(res.Body).Decode(response)
+}
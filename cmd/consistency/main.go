@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/tecsisa/foulkon/api"
+)
+
+// Check the consistency of the data stored by the worker. It shows the inconsistencies found and exits
+// with status 2 if there are any, so it can be run periodically.
+func main() {
+	fs := flag.NewFlagSet("consistency", flag.ExitOnError)
+	workerURL := fs.String("worker-url", "http://localhost:8000", "Worker url")
+	adminUser := fs.String("admin-user", "", "Admin user, for basic authentication")
+	adminPassword := fs.String("admin-password", "", "Admin password, for basic authentication")
+	token := fs.String("token", "", "Bearer token, when admin user isn't used")
+
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(*workerURL, "/")+"/api/v1/admin/consistency", nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot check consistency, error: %v\n", err)
+		os.Exit(1)
+	}
+	if *adminUser != "" {
+		req.SetBasicAuth(*adminUser, *adminPassword)
+	} else if *token != "" {
+		req.Header.Set("Authorization", "Bearer "+*token)
+	}
+
+	// Retrieve report
+	report := &api.ConsistencyReport{}
+	if err := getReport(req, report); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot check consistency, error: %v\n", err)
+		os.Exit(1)
+	}
+	if report.Total() == 0 {
+		fmt.Println("No inconsistencies found")
+		os.Exit(0)
+	}
+
+	fmt.Printf("%v inconsistencies found:\n", report.Total())
+	for _, reference := range report.DanglingReferences {
+		fmt.Printf("  Statement %v of policy %v in organization %v references nonexistent %v\n",
+			reference.Sid, reference.PolicyName, reference.Org, reference.Urn)
+	}
+	for _, relation := range report.DanglingMemberships {
+		fmt.Printf("  Membership of user %v in group %v references nonexistent user or group\n",
+			relation.UserID, relation.GroupID)
+	}
+	for _, relation := range report.DanglingAttachments {
+		fmt.Printf("  Attachment of policy %v to group %v references nonexistent policy or group\n",
+			relation.PolicyID, relation.GroupID)
+	}
+	for _, relation := range report.DanglingOwnerships {
+		fmt.Printf("  Ownership of user %v in group %v references nonexistent user or group\n",
+			relation.UserID, relation.GroupID)
+	}
+	for _, tag := range report.DanglingTags {
+		fmt.Printf("  Tag %v references nonexistent resource %v\n", tag.Key, tag.ResourceID)
+	}
+	for _, policy := range report.EmptyPolicies {
+		fmt.Printf("  Policy %v in organization %v has no statements\n", policy.Name, policy.Org)
+	}
+	for _, group := range report.GroupsWithoutOrgPolicies {
+		fmt.Printf("  Group %v is in organization %v, which has no policies\n", group.Name, group.Org)
+	}
+	os.Exit(2)
+}
+
+// Send request to the worker and decode the report. API errors are returned as *api.Error
+func getReport(req *http.Request, report *api.ConsistencyReport) error {
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		apiError := &api.Error{}
+		if err := json.NewDecoder(res.Body).Decode(apiError); err != nil {
+			return fmt.Errorf("unexpected status %v", res.Status)
+		}
+		return apiError
+	}
+	return json.NewDecoder(res.Body).Decode(report)
+}
@@ -58,6 +58,10 @@ func main() {
 		}
 	}()
 
+	// Purge the trash and deliver webhook events in background
+	go core.PurgeTrash()
+	go core.DeliverWebhooks()
+
 	core.Logger.Infof("Server running in %v:%v", core.Host, core.Port)
 	if core.CertFile != "" && core.KeyFile != "" {
 		core.Logger.Error(http.ListenAndServeTLS(core.Host+":"+core.Port, core.CertFile, core.KeyFile, internalhttp.WorkerHandlerRouter(core)).Error())
@@ -6,6 +6,9 @@ const (
 	// Database
 	INTERNAL_ERROR = "InternalError"
 
+	// Revision Codes
+	REVISION_CONFLICT = "RevisionConflict"
+
 	// User Codes
 	USER_NOT_FOUND = "UserNotFound"
 
@@ -20,6 +23,26 @@ const (
 
 	// Policy Codes
 	POLICY_NOT_FOUND = "PolicyNotFound"
+
+	// Organization Codes
+	ORGANIZATION_NOT_FOUND = "OrganizationNotFound"
+
+	// Access Request Codes
+	ACCESS_REQUEST_NOT_FOUND        = "AccessRequestNotFound"
+	ACCESS_REQUEST_ALREADY_REVIEWED = "AccessRequestAlreadyReviewed"
+
+	// Action registry Codes
+	NAMESPACE_NOT_FOUND = "NamespaceNotFound"
+	ACTION_NOT_FOUND    = "ActionNotFound"
+
+	// Resource type registry Codes
+	RESOURCE_TYPE_NOT_FOUND = "ResourceTypeNotFound"
+
+	// Trash Codes
+	DELETED_RESOURCE_NOT_FOUND = "DeletedResourceNotFound"
+
+	// Webhook Codes
+	WEBHOOK_NOT_FOUND = "WebhookNotFound"
 )
 
 type Error struct {
@@ -0,0 +1,174 @@
+package postgresql
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// ACCESS REQUEST REPOSITORY IMPLEMENTATION
+
+func (a PostgresRepo) AddAccessRequest(accessRequest api.AccessRequest) (*api.AccessRequest, error) {
+
+	// Create access request model
+	accessRequestDB := apiAccessRequestToDBAccessRequest(accessRequest)
+
+	// Store access request
+	err := a.Dbmap.Create(accessRequestDB).Error
+
+	// Error handling
+	if err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbAccessRequestToAPIAccessRequest(accessRequestDB), nil
+}
+
+func (a PostgresRepo) GetAccessRequestByID(id string) (*api.AccessRequest, error) {
+	accessRequest := &AccessRequest{}
+	query := a.Dbmap.Where("id like ?", id).First(accessRequest)
+
+	// Check if access request exists
+	if query.RecordNotFound() {
+		return nil, &database.Error{
+			Code:    database.ACCESS_REQUEST_NOT_FOUND,
+			Message: fmt.Sprintf("Access request with id %v not found", id),
+		}
+	}
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbAccessRequestToAPIAccessRequest(accessRequest), nil
+}
+
+func (a PostgresRepo) GetAccessRequestsFiltered(org string, status string) ([]api.AccessRequest, error) {
+	accessRequests := []AccessRequest{}
+	query := a.Dbmap
+	if len(org) > 0 {
+		query = query.Where("org like ? ", org)
+	}
+	if len(status) > 0 {
+		query = query.Where("status like ? ", status)
+	}
+	// Error handling
+	if err := query.Order("create_at").Find(&accessRequests).Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Transform access requests for API
+	if accessRequests != nil {
+		apiAccessRequests := make([]api.AccessRequest, len(accessRequests), cap(accessRequests))
+		for i, ar := range accessRequests {
+			apiAccessRequests[i] = *dbAccessRequestToAPIAccessRequest(&ar)
+		}
+		return apiAccessRequests, nil
+	}
+
+	// No data to return
+	return nil, nil
+}
+
+func (a PostgresRepo) UpdateAccessRequest(accessRequest api.AccessRequest) (*api.AccessRequest, error) {
+	accessRequestDB := apiAccessRequestToDBAccessRequest(accessRequest)
+
+	// Update review fields. Only pending requests can be reviewed, so concurrent reviews of the
+	// same request can't both succeed
+	query := a.Dbmap.Model(&AccessRequest{ID: accessRequest.ID}).Where("status = ?", api.ACCESS_REQUEST_STATUS_PENDING).
+		Updates(map[string]interface{}{
+			"status":         accessRequestDB.Status,
+			"reviewer":       accessRequestDB.Reviewer,
+			"review_comment": accessRequestDB.ReviewComment,
+			"review_at":      accessRequestDB.ReviewAt,
+			"expires_at":     accessRequestDB.ExpiresAt,
+		})
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	if query.RowsAffected == 0 {
+		return nil, &database.Error{
+			Code:    database.ACCESS_REQUEST_ALREADY_REVIEWED,
+			Message: fmt.Sprintf("Access request %v has already been reviewed", accessRequest.ID),
+		}
+	}
+
+	return dbAccessRequestToAPIAccessRequest(accessRequestDB), nil
+}
+
+func (a PostgresRepo) UpdateAccessRequestsRequester(requester string, newRequester string) error {
+	// Reviewed requests keep the requester they had, like the rest of their review history
+	query := a.Dbmap.Model(&AccessRequest{}).Where("requester = ? AND status = ?", requester, api.ACCESS_REQUEST_STATUS_PENDING).
+		Update("requester", newRequester)
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Transform an access request from API into an access request for db
+func apiAccessRequestToDBAccessRequest(accessRequest api.AccessRequest) *AccessRequest {
+	return &AccessRequest{
+		ID:            accessRequest.ID,
+		Type:          accessRequest.Type,
+		Requester:     accessRequest.Requester,
+		Org:           accessRequest.Org,
+		GroupName:     accessRequest.GroupName,
+		PolicyName:    accessRequest.PolicyName,
+		Justification: accessRequest.Justification,
+		Duration:      accessRequest.Duration,
+		Status:        accessRequest.Status,
+		Reviewer:      accessRequest.Reviewer,
+		ReviewComment: accessRequest.ReviewComment,
+		CreateAt:      accessRequest.CreateAt.UTC().UnixNano(),
+		ReviewAt:      timeToUnixNano(accessRequest.ReviewAt),
+		ExpiresAt:     timeToUnixNano(accessRequest.ExpiresAt),
+		GroupUrn:      accessRequest.GroupUrn,
+	}
+}
+
+// Transform an access request retrieved from db into an access request for API
+func dbAccessRequestToAPIAccessRequest(accessRequestDB *AccessRequest) *api.AccessRequest {
+	return &api.AccessRequest{
+		ID:            accessRequestDB.ID,
+		Type:          accessRequestDB.Type,
+		Requester:     accessRequestDB.Requester,
+		Org:           accessRequestDB.Org,
+		GroupName:     accessRequestDB.GroupName,
+		PolicyName:    accessRequestDB.PolicyName,
+		Justification: accessRequestDB.Justification,
+		Duration:      accessRequestDB.Duration,
+		Status:        accessRequestDB.Status,
+		Reviewer:      accessRequestDB.Reviewer,
+		ReviewComment: accessRequestDB.ReviewComment,
+		CreateAt:      time.Unix(0, accessRequestDB.CreateAt).UTC(),
+		ReviewAt:      unixNanoToTime(accessRequestDB.ReviewAt),
+		ExpiresAt:     unixNanoToTime(accessRequestDB.ExpiresAt),
+		GroupUrn:      accessRequestDB.GroupUrn,
+	}
+}
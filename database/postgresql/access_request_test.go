@@ -0,0 +1,496 @@
+package postgresql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestPostgresRepo_AddAccessRequest(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousAccessRequest *AccessRequest
+		// Postgres Repo Args
+		accessRequestToCreate *api.AccessRequest
+		// Expected result
+		expectedResponse *api.AccessRequest
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			accessRequestToCreate: &api.AccessRequest{
+				ID:            "AccessRequestID",
+				Type:          api.ACCESS_REQUEST_TYPE_MEMBERSHIP,
+				Requester:     "123456",
+				Org:           "Org",
+				GroupName:     "Group",
+				Justification: "Justification",
+				Duration:      "1h0m0s",
+				Status:        api.ACCESS_REQUEST_STATUS_PENDING,
+				CreateAt:      now,
+				GroupUrn:      "urn",
+			},
+			expectedResponse: &api.AccessRequest{
+				ID:            "AccessRequestID",
+				Type:          api.ACCESS_REQUEST_TYPE_MEMBERSHIP,
+				Requester:     "123456",
+				Org:           "Org",
+				GroupName:     "Group",
+				Justification: "Justification",
+				Duration:      "1h0m0s",
+				Status:        api.ACCESS_REQUEST_STATUS_PENDING,
+				CreateAt:      now,
+				GroupUrn:      "urn",
+			},
+		},
+		"ErrorCaseAccessRequestAlreadyExist": {
+			previousAccessRequest: &AccessRequest{
+				ID:            "AccessRequestID",
+				Type:          api.ACCESS_REQUEST_TYPE_MEMBERSHIP,
+				Requester:     "123456",
+				Org:           "Org",
+				GroupName:     "Group",
+				Justification: "Justification",
+				Duration:      "1h0m0s",
+				Status:        api.ACCESS_REQUEST_STATUS_PENDING,
+				CreateAt:      now.UnixNano(),
+				GroupUrn:      "urn",
+			},
+			accessRequestToCreate: &api.AccessRequest{
+				ID:            "AccessRequestID",
+				Type:          api.ACCESS_REQUEST_TYPE_MEMBERSHIP,
+				Requester:     "123456",
+				Org:           "Org",
+				GroupName:     "Group",
+				Justification: "Justification",
+				Duration:      "1h0m0s",
+				Status:        api.ACCESS_REQUEST_STATUS_PENDING,
+				CreateAt:      now,
+				GroupUrn:      "urn",
+			},
+			expectedError: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "pq: duplicate key value violates unique constraint \"access_requests_pkey\"",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean access request database
+		cleanAccessRequestTable()
+
+		// Insert previous data
+		if test.previousAccessRequest != nil {
+			if err := insertAccessRequest(*test.previousAccessRequest); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+		// Call to repository to store access request
+		storedAccessRequest, err := repoDB.AddAccessRequest(*test.accessRequestToCreate)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check response
+			if diff := pretty.Compare(storedAccessRequest, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+			// Check database
+			accessRequestNumber, err := getAccessRequestsCountFiltered(test.accessRequestToCreate.ID,
+				test.accessRequestToCreate.Status, "")
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error counting access requests: %v", n, err)
+				continue
+			}
+			if accessRequestNumber != 1 {
+				t.Errorf("Test %v failed. Received different access request number: %v", n, accessRequestNumber)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_GetAccessRequestByID(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousAccessRequest *AccessRequest
+		// Postgres Repo Args
+		id string
+		// Expected result
+		expectedResponse *api.AccessRequest
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			previousAccessRequest: &AccessRequest{
+				ID:        "AccessRequestID",
+				Type:      api.ACCESS_REQUEST_TYPE_MEMBERSHIP,
+				Requester: "123456",
+				Org:       "Org",
+				GroupName: "Group",
+				Duration:  "1h0m0s",
+				Status:    api.ACCESS_REQUEST_STATUS_PENDING,
+				CreateAt:  now.UnixNano(),
+				GroupUrn:  "urn",
+			},
+			id: "AccessRequestID",
+			expectedResponse: &api.AccessRequest{
+				ID:        "AccessRequestID",
+				Type:      api.ACCESS_REQUEST_TYPE_MEMBERSHIP,
+				Requester: "123456",
+				Org:       "Org",
+				GroupName: "Group",
+				Duration:  "1h0m0s",
+				Status:    api.ACCESS_REQUEST_STATUS_PENDING,
+				CreateAt:  now,
+				GroupUrn:  "urn",
+			},
+		},
+		"ErrorCaseAccessRequestNotExist": {
+			id: "AccessRequestID",
+			expectedError: &database.Error{
+				Code:    database.ACCESS_REQUEST_NOT_FOUND,
+				Message: "Access request with id AccessRequestID not found",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean access request database
+		cleanAccessRequestTable()
+
+		// Insert previous data
+		if test.previousAccessRequest != nil {
+			if err := insertAccessRequest(*test.previousAccessRequest); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+		// Call to repository to get access request
+		receivedAccessRequest, err := repoDB.GetAccessRequestByID(test.id)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check response
+			if diff := pretty.Compare(receivedAccessRequest, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_GetAccessRequestsFiltered(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousAccessRequests []AccessRequest
+		// Postgres Repo Args
+		org    string
+		status string
+		// Expected result
+		expectedResponse []api.AccessRequest
+	}{
+		"OkCaseFilterByStatus": {
+			previousAccessRequests: []AccessRequest{
+				{
+					ID:       "AccessRequestID1",
+					Org:      "Org",
+					Status:   api.ACCESS_REQUEST_STATUS_PENDING,
+					CreateAt: now.UnixNano(),
+				},
+				{
+					ID:       "AccessRequestID2",
+					Org:      "Org",
+					Status:   api.ACCESS_REQUEST_STATUS_REJECTED,
+					CreateAt: now.UnixNano(),
+				},
+			},
+			org:    "Org",
+			status: api.ACCESS_REQUEST_STATUS_PENDING,
+			expectedResponse: []api.AccessRequest{
+				{
+					ID:       "AccessRequestID1",
+					Org:      "Org",
+					Status:   api.ACCESS_REQUEST_STATUS_PENDING,
+					CreateAt: now,
+				},
+			},
+		},
+		"OkCaseFilterByOrg": {
+			previousAccessRequests: []AccessRequest{
+				{
+					ID:       "AccessRequestID1",
+					Org:      "Org",
+					Status:   api.ACCESS_REQUEST_STATUS_PENDING,
+					CreateAt: now.UnixNano(),
+				},
+				{
+					ID:       "AccessRequestID2",
+					Org:      "Org2",
+					Status:   api.ACCESS_REQUEST_STATUS_PENDING,
+					CreateAt: now.UnixNano(),
+				},
+			},
+			org: "Org2",
+			expectedResponse: []api.AccessRequest{
+				{
+					ID:       "AccessRequestID2",
+					Org:      "Org2",
+					Status:   api.ACCESS_REQUEST_STATUS_PENDING,
+					CreateAt: now,
+				},
+			},
+		},
+		"OkCaseNoResults": {
+			org:              "Org",
+			expectedResponse: []api.AccessRequest{},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean access request database
+		cleanAccessRequestTable()
+
+		// Insert previous data
+		for _, previous := range test.previousAccessRequests {
+			if err := insertAccessRequest(previous); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+		// Call to repository to get access requests
+		receivedAccessRequests, err := repoDB.GetAccessRequestsFiltered(test.org, test.status)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		// Check response
+		if diff := pretty.Compare(receivedAccessRequests, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_UpdateAccessRequest(t *testing.T) {
+	now := time.Now().UTC()
+	expiresAt := now.Add(time.Hour)
+	testcases := map[string]struct {
+		// Previous data
+		previousAccessRequest *AccessRequest
+		// Postgres Repo Args
+		accessRequestToUpdate *api.AccessRequest
+		// Expected result
+		expectedResponse *api.AccessRequest
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			previousAccessRequest: &AccessRequest{
+				ID:        "AccessRequestID",
+				Requester: "123456",
+				Duration:  "1h0m0s",
+				Status:    api.ACCESS_REQUEST_STATUS_PENDING,
+				CreateAt:  now.UnixNano(),
+			},
+			accessRequestToUpdate: &api.AccessRequest{
+				ID:            "AccessRequestID",
+				Requester:     "123456",
+				Duration:      "1h0m0s",
+				Status:        api.ACCESS_REQUEST_STATUS_APPROVED,
+				Reviewer:      "654321",
+				ReviewComment: "Comment",
+				CreateAt:      now,
+				ReviewAt:      &now,
+				ExpiresAt:     &expiresAt,
+			},
+			expectedResponse: &api.AccessRequest{
+				ID:            "AccessRequestID",
+				Requester:     "123456",
+				Duration:      "1h0m0s",
+				Status:        api.ACCESS_REQUEST_STATUS_APPROVED,
+				Reviewer:      "654321",
+				ReviewComment: "Comment",
+				CreateAt:      now,
+				ReviewAt:      &now,
+				ExpiresAt:     &expiresAt,
+			},
+		},
+		"ErrorCaseAlreadyReviewed": {
+			previousAccessRequest: &AccessRequest{
+				ID:        "AccessRequestID",
+				Requester: "123456",
+				Duration:  "1h0m0s",
+				Status:    api.ACCESS_REQUEST_STATUS_REJECTED,
+				Reviewer:  "111111",
+				CreateAt:  now.UnixNano(),
+			},
+			accessRequestToUpdate: &api.AccessRequest{
+				ID:            "AccessRequestID",
+				Requester:     "123456",
+				Duration:      "1h0m0s",
+				Status:        api.ACCESS_REQUEST_STATUS_APPROVED,
+				Reviewer:      "654321",
+				ReviewComment: "Comment",
+				CreateAt:      now,
+				ReviewAt:      &now,
+				ExpiresAt:     &expiresAt,
+			},
+			expectedError: &database.Error{
+				Code:    database.ACCESS_REQUEST_ALREADY_REVIEWED,
+				Message: "Access request AccessRequestID has already been reviewed",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean access request database
+		cleanAccessRequestTable()
+
+		// Insert previous data
+		if test.previousAccessRequest != nil {
+			if err := insertAccessRequest(*test.previousAccessRequest); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+		// Call to repository to update access request
+		updatedAccessRequest, err := repoDB.UpdateAccessRequest(*test.accessRequestToUpdate)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		// Check response
+		if diff := pretty.Compare(updatedAccessRequest, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+		// Check database
+		accessRequestNumber, err := getAccessRequestsCountFiltered(test.accessRequestToUpdate.ID,
+			test.accessRequestToUpdate.Status, test.accessRequestToUpdate.Reviewer)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting access requests: %v", n, err)
+			continue
+		}
+		if accessRequestNumber != 1 {
+			t.Errorf("Test %v failed. Received different access request number: %v", n, accessRequestNumber)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_UpdateAccessRequestsRequester(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousAccessRequests []AccessRequest
+		// Postgres Repo Args
+		requester    string
+		newRequester string
+		// Expected result
+		expectedRequesters map[string]string
+	}{
+		"OkCase": {
+			previousAccessRequests: []AccessRequest{
+				{
+					ID:        "PendingAccessRequestID",
+					Requester: "123456",
+					Duration:  "1h0m0s",
+					Status:    api.ACCESS_REQUEST_STATUS_PENDING,
+					CreateAt:  now.UnixNano(),
+				},
+				{
+					ID:        "ReviewedAccessRequestID",
+					Requester: "123456",
+					Duration:  "1h0m0s",
+					Status:    api.ACCESS_REQUEST_STATUS_APPROVED,
+					Reviewer:  "654321",
+					CreateAt:  now.UnixNano(),
+				},
+				{
+					ID:        "OtherAccessRequestID",
+					Requester: "111111",
+					Duration:  "1h0m0s",
+					Status:    api.ACCESS_REQUEST_STATUS_PENDING,
+					CreateAt:  now.UnixNano(),
+				},
+			},
+			requester:    "123456",
+			newRequester: "999999",
+			expectedRequesters: map[string]string{
+				"PendingAccessRequestID":  "999999",
+				"ReviewedAccessRequestID": "123456",
+				"OtherAccessRequestID":    "111111",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean access request database
+		cleanAccessRequestTable()
+
+		// Insert previous data
+		for _, accessRequest := range test.previousAccessRequests {
+			if err := insertAccessRequest(accessRequest); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+		// Call to repository to update requester
+		if err := repoDB.UpdateAccessRequestsRequester(test.requester, test.newRequester); err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		// Check database
+		for id, requester := range test.expectedRequesters {
+			accessRequest, err := repoDB.GetAccessRequestByID(id)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error retrieving access request %v: %v", n, id, err)
+				continue
+			}
+			if accessRequest.Requester != requester {
+				t.Errorf("Test %v failed. Received different requester of access request %v (wanted:%v / received:%v)",
+					n, id, requester, accessRequest.Requester)
+			}
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package postgresql
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// ACTION REGISTRY REPOSITORY IMPLEMENTATION
+
+func (a PostgresRepo) AddNamespace(namespace api.Namespace) (*api.Namespace, error) {
+
+	// Create namespace model
+	namespaceDB := &Namespace{
+		Name:        namespace.Name,
+		Description: namespace.Description,
+		CreateAt:    namespace.CreateAt.UTC().UnixNano(),
+	}
+
+	// Store namespace
+	err := a.Dbmap.Create(namespaceDB).Error
+
+	// Error handling
+	if err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbNamespaceToAPINamespace(namespaceDB), nil
+}
+
+func (a PostgresRepo) GetNamespaceByName(name string) (*api.Namespace, error) {
+	namespace := &Namespace{}
+	query := a.Dbmap.Where("name like ?", name).First(namespace)
+
+	// Check if namespace exists
+	if query.RecordNotFound() {
+		return nil, &database.Error{
+			Code:    database.NAMESPACE_NOT_FOUND,
+			Message: fmt.Sprintf("Namespace with name %v not found", name),
+		}
+	}
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbNamespaceToAPINamespace(namespace), nil
+}
+
+func (a PostgresRepo) GetNamespaces() ([]api.Namespace, error) {
+	namespaces := []Namespace{}
+
+	// Error handling
+	if err := a.Dbmap.Order("name").Find(&namespaces).Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Transform namespaces for API
+	if namespaces != nil {
+		apiNamespaces := make([]api.Namespace, len(namespaces), cap(namespaces))
+		for i, n := range namespaces {
+			apiNamespaces[i] = *dbNamespaceToAPINamespace(&n)
+		}
+		return apiNamespaces, nil
+	}
+
+	// No data to return
+	return nil, nil
+}
+
+func (a PostgresRepo) RemoveNamespace(name string) error {
+	transaction := a.begin()
+	// Delete namespace
+	transaction.Where("name like ?", name).Delete(&Namespace{})
+
+	// Error handling
+	if err := transaction.Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Delete all namespace actions
+	transaction.Where("namespace like ?", name).Delete(&Action{})
+
+	// Error handling
+	if err := transaction.Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Delete all namespace resource types
+	transaction.Where("namespace like ?", name).Delete(&ResourceType{})
+
+	// Error handling
+	if err := transaction.Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	transaction.Commit()
+	return nil
+}
+
+func (a PostgresRepo) AddAction(action api.Action) (*api.Action, error) {
+
+	// Create action model
+	actionDB := &Action{
+		ID:                 action.ID,
+		Namespace:          action.Namespace,
+		Name:               action.Name,
+		Description:        action.Description,
+		ResourceUrnPattern: action.ResourceUrnPattern,
+		CreateAt:           action.CreateAt.UTC().UnixNano(),
+	}
+
+	// Store action
+	err := a.Dbmap.Create(actionDB).Error
+
+	// Error handling
+	if err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbActionToAPIAction(actionDB), nil
+}
+
+func (a PostgresRepo) GetActionByName(namespace string, name string) (*api.Action, error) {
+	action := &Action{}
+	query := a.Dbmap.Where("namespace like ? AND name like ?", namespace, name).First(action)
+
+	// Check if action exists
+	if query.RecordNotFound() {
+		return nil, &database.Error{
+			Code:    database.ACTION_NOT_FOUND,
+			Message: fmt.Sprintf("Action %v:%v not found", namespace, name),
+		}
+	}
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbActionToAPIAction(action), nil
+}
+
+func (a PostgresRepo) GetActionsFiltered(namespace string) ([]api.Action, error) {
+	actions := []Action{}
+
+	// Error handling
+	if err := a.Dbmap.Where("namespace like ?", namespace).Order("name").Find(&actions).Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Transform actions for API
+	if actions != nil {
+		apiActions := make([]api.Action, len(actions), cap(actions))
+		for i, ac := range actions {
+			apiActions[i] = *dbActionToAPIAction(&ac)
+		}
+		return apiActions, nil
+	}
+
+	// No data to return
+	return nil, nil
+}
+
+func (a PostgresRepo) RemoveAction(id string) error {
+	// Delete action
+	err := a.Dbmap.Where("id like ?", id).Delete(&Action{}).Error
+
+	// Error handling
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Transform a namespace retrieved from db into a namespace for API
+func dbNamespaceToAPINamespace(namespaceDB *Namespace) *api.Namespace {
+	return &api.Namespace{
+		Name:        namespaceDB.Name,
+		Description: namespaceDB.Description,
+		CreateAt:    time.Unix(0, namespaceDB.CreateAt).UTC(),
+	}
+}
+
+// Transform an action retrieved from db into an action for API
+func dbActionToAPIAction(actionDB *Action) *api.Action {
+	return &api.Action{
+		ID:                 actionDB.ID,
+		Namespace:          actionDB.Namespace,
+		Name:               actionDB.Name,
+		Description:        actionDB.Description,
+		ResourceUrnPattern: actionDB.ResourceUrnPattern,
+		CreateAt:           time.Unix(0, actionDB.CreateAt).UTC(),
+	}
+}
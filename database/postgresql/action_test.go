@@ -0,0 +1,357 @@
+package postgresql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestPostgresRepo_AddNamespace(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousNamespace *Namespace
+		// Postgres Repo Args
+		namespaceToCreate *api.Namespace
+		// Expected result
+		expectedResponse *api.Namespace
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			namespaceToCreate: &api.Namespace{
+				Name:        "billing",
+				Description: "Billing service",
+				CreateAt:    now,
+			},
+			expectedResponse: &api.Namespace{
+				Name:        "billing",
+				Description: "Billing service",
+				CreateAt:    now,
+			},
+		},
+		"ErrorCaseNamespaceAlreadyExist": {
+			previousNamespace: &Namespace{
+				Name:     "billing",
+				CreateAt: now.UnixNano(),
+			},
+			namespaceToCreate: &api.Namespace{
+				Name:     "billing",
+				CreateAt: now,
+			},
+			expectedError: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "pq: duplicate key value violates unique constraint \"namespaces_pkey\"",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean namespace database
+		cleanNamespaceTable()
+
+		// Insert previous data
+		if test.previousNamespace != nil {
+			if err := insertNamespace(*test.previousNamespace); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+		// Call to repository to store namespace
+		storedNamespace, err := repoDB.AddNamespace(*test.namespaceToCreate)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check response
+			if diff := pretty.Compare(storedNamespace, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+			// Check database
+			namespaceNumber, err := getNamespacesCountFiltered(test.namespaceToCreate.Name)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error counting namespaces: %v", n, err)
+				continue
+			}
+			if namespaceNumber != 1 {
+				t.Errorf("Test %v failed. Received different namespace number: %v", n, namespaceNumber)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_RemoveNamespace(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousNamespaces []Namespace
+		previousActions    []Action
+		// Postgres Repo Args
+		name string
+		// Expected result
+		expectedNamespaces int
+		expectedActions    int
+	}{
+		"OkCase": {
+			previousNamespaces: []Namespace{
+				{
+					Name:     "billing",
+					CreateAt: now.UnixNano(),
+				},
+				{
+					Name:     "storage",
+					CreateAt: now.UnixNano(),
+				},
+			},
+			previousActions: []Action{
+				{
+					ID:        "ActionID1",
+					Namespace: "billing",
+					Name:      "GetInvoice",
+					CreateAt:  now.UnixNano(),
+				},
+				{
+					ID:        "ActionID2",
+					Namespace: "storage",
+					Name:      "GetObject",
+					CreateAt:  now.UnixNano(),
+				},
+			},
+			name:               "billing",
+			expectedNamespaces: 1,
+			expectedActions:    1,
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean database
+		cleanNamespaceTable()
+		cleanActionTable()
+
+		// Insert previous data
+		for _, namespace := range test.previousNamespaces {
+			if err := insertNamespace(namespace); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous namespaces: %v", n, err)
+				continue
+			}
+		}
+		for _, action := range test.previousActions {
+			if err := insertAction(action); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous actions: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to remove namespace
+		if err := repoDB.RemoveNamespace(test.name); err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		// Check database
+		namespaceNumber, err := getNamespacesCountFiltered("")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting namespaces: %v", n, err)
+			continue
+		}
+		if namespaceNumber != test.expectedNamespaces {
+			t.Errorf("Test %v failed. Received different namespace number: %v", n, namespaceNumber)
+			continue
+		}
+		actionNumber, err := getActionsCountFiltered("", "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting actions: %v", n, err)
+			continue
+		}
+		if actionNumber != test.expectedActions {
+			t.Errorf("Test %v failed. Received different action number: %v", n, actionNumber)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_AddAction(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousAction *Action
+		// Postgres Repo Args
+		actionToCreate *api.Action
+		// Expected result
+		expectedResponse *api.Action
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			actionToCreate: &api.Action{
+				ID:                 "ActionID",
+				Namespace:          "billing",
+				Name:               "GetInvoice",
+				Description:        "Retrieve an invoice",
+				ResourceUrnPattern: "urn:ews:billing:instance:invoice/*",
+				CreateAt:           now,
+			},
+			expectedResponse: &api.Action{
+				ID:                 "ActionID",
+				Namespace:          "billing",
+				Name:               "GetInvoice",
+				Description:        "Retrieve an invoice",
+				ResourceUrnPattern: "urn:ews:billing:instance:invoice/*",
+				CreateAt:           now,
+			},
+		},
+		"ErrorCaseActionAlreadyExist": {
+			previousAction: &Action{
+				ID:        "ActionID1",
+				Namespace: "billing",
+				Name:      "GetInvoice",
+				CreateAt:  now.UnixNano(),
+			},
+			actionToCreate: &api.Action{
+				ID:        "ActionID2",
+				Namespace: "billing",
+				Name:      "GetInvoice",
+				CreateAt:  now,
+			},
+			expectedError: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "pq: duplicate key value violates unique constraint \"idx_action_namespace_name\"",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean action database
+		cleanActionTable()
+
+		// Insert previous data
+		if test.previousAction != nil {
+			if err := insertAction(*test.previousAction); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+		// Call to repository to store action
+		storedAction, err := repoDB.AddAction(*test.actionToCreate)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check response
+			if diff := pretty.Compare(storedAction, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+			// Check database
+			actionNumber, err := getActionsCountFiltered(test.actionToCreate.ID, "")
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error counting actions: %v", n, err)
+				continue
+			}
+			if actionNumber != 1 {
+				t.Errorf("Test %v failed. Received different action number: %v", n, actionNumber)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_GetActionByName(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousAction *Action
+		// Postgres Repo Args
+		namespace string
+		name      string
+		// Expected result
+		expectedResponse *api.Action
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			previousAction: &Action{
+				ID:        "ActionID",
+				Namespace: "billing",
+				Name:      "GetInvoice",
+				CreateAt:  now.UnixNano(),
+			},
+			namespace: "billing",
+			name:      "GetInvoice",
+			expectedResponse: &api.Action{
+				ID:        "ActionID",
+				Namespace: "billing",
+				Name:      "GetInvoice",
+				CreateAt:  now,
+			},
+		},
+		"ErrorCaseActionNotFound": {
+			namespace: "billing",
+			name:      "GetInvoice",
+			expectedError: &database.Error{
+				Code:    database.ACTION_NOT_FOUND,
+				Message: "Action billing:GetInvoice not found",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean action database
+		cleanActionTable()
+
+		// Insert previous data
+		if test.previousAction != nil {
+			if err := insertAction(*test.previousAction); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+		// Call to repository to get action
+		receivedAction, err := repoDB.GetActionByName(test.namespace, test.name)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check response
+			if diff := pretty.Compare(receivedAction, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package postgresql
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// AUDIT REPOSITORY IMPLEMENTATION
+
+func (a PostgresRepo) AddAuditEvent(event api.AuditEvent) (*api.AuditEvent, error) {
+	// Create audit event model
+	eventDB := apiAuditEventToDBAuditEvent(event)
+
+	// Store audit event
+	err := a.Dbmap.Create(eventDB).Error
+
+	// Error handling
+	if err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbAuditEventToAPIAuditEvent(eventDB), nil
+}
+
+func (a PostgresRepo) GetAuditEventsFiltered(actor string, urn string, action string, filter *api.Filter) ([]api.AuditEvent, int, error) {
+	events := []AuditEvent{}
+	query := a.Dbmap
+
+	if len(actor) > 0 {
+		query = query.Where("actor = ?", actor)
+	}
+	if len(urn) > 0 {
+		query = query.Where("urn = ?", urn)
+	}
+	if len(action) > 0 {
+		query = query.Where("action = ?", action)
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("create_at > ?", filter.CreatedAfter.UnixNano())
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("create_at < ?", filter.CreatedBefore.UnixNano())
+	}
+
+	// Count audit events and retrieve the requested page
+	query, total, err := paginate(query, &AuditEvent{}, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Error handling. Last stored audit events are returned first
+	if err := query.Order("create_at desc").Order("id").Find(&events).Error; err != nil {
+		return nil, 0, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Transform audit events for API
+	apiEvents := make([]api.AuditEvent, len(events), cap(events))
+	for i, e := range events {
+		apiEvents[i] = *dbAuditEventToAPIAuditEvent(&e)
+	}
+
+	return apiEvents, total, nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Transform an audit event from API into an audit event for db
+func apiAuditEventToDBAuditEvent(event api.AuditEvent) *AuditEvent {
+	return &AuditEvent{
+		ID:        event.ID,
+		RequestID: event.RequestID,
+		Actor:     event.Actor,
+		Action:    event.Action,
+		Urn:       event.Urn,
+		Before:    string(event.Before),
+		After:     string(event.After),
+		CreateAt:  event.CreateAt.UTC().UnixNano(),
+	}
+}
+
+// Transform an audit event retrieved from db into an audit event for API
+func dbAuditEventToAPIAuditEvent(eventDB *AuditEvent) *api.AuditEvent {
+	return &api.AuditEvent{
+		ID:        eventDB.ID,
+		RequestID: eventDB.RequestID,
+		Actor:     eventDB.Actor,
+		Action:    eventDB.Action,
+		Urn:       eventDB.Urn,
+		Before:    toJSONDocument(eventDB.Before),
+		After:     toJSONDocument(eventDB.After),
+		CreateAt:  time.Unix(0, eventDB.CreateAt).UTC(),
+	}
+}
+
+// Stored JSON document, empty documents are nil
+func toJSONDocument(document string) json.RawMessage {
+	if document == "" {
+		return nil
+	}
+	return json.RawMessage(document)
+}
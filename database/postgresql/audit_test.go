@@ -0,0 +1,221 @@
+package postgresql
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+)
+
+func TestPostgresRepo_AddAuditEvent(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Postgres Repo Args
+		event api.AuditEvent
+		// Expected result
+		expectedResponse *api.AuditEvent
+	}{
+		"OkCase": {
+			event: api.AuditEvent{
+				ID:        "EventID",
+				RequestID: "RequestID",
+				Actor:     "admin",
+				Action:    api.USER_ACTION_CREATE_USER,
+				Urn:       "urn:user",
+				After:     json.RawMessage(`{"externalId":"user"}`),
+				CreateAt:  now,
+			},
+			expectedResponse: &api.AuditEvent{
+				ID:        "EventID",
+				RequestID: "RequestID",
+				Actor:     "admin",
+				Action:    api.USER_ACTION_CREATE_USER,
+				Urn:       "urn:user",
+				After:     json.RawMessage(`{"externalId":"user"}`),
+				CreateAt:  now,
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean audit event database
+		cleanAuditEventTable()
+
+		// Call to repository to store audit event
+		event, err := repoDB.AddAuditEvent(test.event)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if diff := pretty.Compare(event, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+		// Check database
+		eventNumber, err := getAuditEventsCountFiltered(test.event.ID, test.event.Actor, test.event.Action, test.event.Urn)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting audit events: %v", n, err)
+			continue
+		}
+		if eventNumber != 1 {
+			t.Errorf("Test %v failed. Received different audit event number: %v", n, eventNumber)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_GetAuditEventsFiltered(t *testing.T) {
+	now := time.Now().UTC()
+	before := now.Add(-time.Hour)
+	testcases := map[string]struct {
+		// Previous data
+		previousAuditEvents []AuditEvent
+		// Postgres Repo Args
+		actor  string
+		urn    string
+		action string
+		filter *api.Filter
+		// Expected result
+		expectedResponse []api.AuditEvent
+		expectedTotal    int
+	}{
+		"OkCaseAll": {
+			previousAuditEvents: []AuditEvent{
+				{
+					ID:       "EventID1",
+					Actor:    "admin",
+					Action:   api.USER_ACTION_CREATE_USER,
+					Urn:      "urn:user",
+					After:    "{}",
+					CreateAt: now.Add(-2 * time.Hour).UnixNano(),
+				},
+				{
+					ID:       "EventID2",
+					Actor:    "admin",
+					Action:   api.USER_ACTION_DELETE_USER,
+					Urn:      "urn:user",
+					Before:   "{}",
+					CreateAt: now.UnixNano(),
+				},
+			},
+			filter: &api.Filter{},
+			expectedResponse: []api.AuditEvent{
+				{
+					ID:       "EventID2",
+					Actor:    "admin",
+					Action:   api.USER_ACTION_DELETE_USER,
+					Urn:      "urn:user",
+					Before:   json.RawMessage("{}"),
+					CreateAt: now,
+				},
+				{
+					ID:       "EventID1",
+					Actor:    "admin",
+					Action:   api.USER_ACTION_CREATE_USER,
+					Urn:      "urn:user",
+					After:    json.RawMessage("{}"),
+					CreateAt: now.Add(-2 * time.Hour),
+				},
+			},
+			expectedTotal: 2,
+		},
+		"OkCaseFilteredByAction": {
+			previousAuditEvents: []AuditEvent{
+				{
+					ID:       "EventID1",
+					Actor:    "admin",
+					Action:   api.USER_ACTION_CREATE_USER,
+					Urn:      "urn:user",
+					After:    "{}",
+					CreateAt: now.UnixNano(),
+				},
+				{
+					ID:       "EventID2",
+					Actor:    "admin",
+					Action:   api.USER_ACTION_DELETE_USER,
+					Urn:      "urn:user",
+					Before:   "{}",
+					CreateAt: now.UnixNano(),
+				},
+			},
+			actor:  "admin",
+			urn:    "urn:user",
+			action: api.USER_ACTION_CREATE_USER,
+			filter: &api.Filter{},
+			expectedResponse: []api.AuditEvent{
+				{
+					ID:       "EventID1",
+					Actor:    "admin",
+					Action:   api.USER_ACTION_CREATE_USER,
+					Urn:      "urn:user",
+					After:    json.RawMessage("{}"),
+					CreateAt: now,
+				},
+			},
+			expectedTotal: 1,
+		},
+		"OkCaseFilteredByDate": {
+			previousAuditEvents: []AuditEvent{
+				{
+					ID:       "EventID1",
+					Actor:    "admin",
+					Action:   api.USER_ACTION_CREATE_USER,
+					Urn:      "urn:user",
+					After:    "{}",
+					CreateAt: now.Add(-2 * time.Hour).UnixNano(),
+				},
+				{
+					ID:       "EventID2",
+					Actor:    "admin",
+					Action:   api.USER_ACTION_DELETE_USER,
+					Urn:      "urn:user",
+					Before:   "{}",
+					CreateAt: now.UnixNano(),
+				},
+			},
+			filter: &api.Filter{
+				CreatedAfter: &before,
+			},
+			expectedResponse: []api.AuditEvent{
+				{
+					ID:       "EventID2",
+					Actor:    "admin",
+					Action:   api.USER_ACTION_DELETE_USER,
+					Urn:      "urn:user",
+					Before:   json.RawMessage("{}"),
+					CreateAt: now,
+				},
+			},
+			expectedTotal: 1,
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean audit event database
+		cleanAuditEventTable()
+
+		// Insert previous data
+		for _, event := range test.previousAuditEvents {
+			if err := insertAuditEvent(event); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+		// Call to repository to get audit events
+		events, total, err := repoDB.GetAuditEventsFiltered(test.actor, test.urn, test.action, test.filter)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if total != test.expectedTotal {
+			t.Errorf("Test %v failed. Received different total: %v", n, total)
+			continue
+		}
+		if diff := pretty.Compare(events, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
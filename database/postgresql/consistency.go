@@ -0,0 +1,107 @@
+package postgresql
+
+import (
+	"strings"
+
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// CONSISTENCY REPOSITORY IMPLEMENTATION
+
+func (c PostgresRepo) GetConsistencyReport() (*api.ConsistencyReport, error) {
+	report := &api.ConsistencyReport{
+		DanglingReferences:       []api.DanglingReference{},
+		DanglingMemberships:      []api.DanglingRelation{},
+		DanglingAttachments:      []api.DanglingRelation{},
+		DanglingOwnerships:       []api.DanglingRelation{},
+		DanglingTags:             []api.DanglingTag{},
+		EmptyPolicies:            []api.PolicyIdentity{},
+		GroupsWithoutOrgPolicies: []api.GroupIdentity{},
+	}
+
+	// Statements referencing users, groups and policies that don't exist. Other URNs, like organizations
+	// or external resources, aren't checked
+	query := c.Dbmap.Raw("SELECT p.org, p.name AS policy_name, s.sid, sr.urn FROM statement_references sr "+
+		"JOIN statements s ON s.id = sr.statement_id JOIN policies p ON p.id = sr.policy_id "+
+		"WHERE (sr.urn LIKE ? OR sr.urn LIKE ? OR sr.urn LIKE ?) "+
+		"AND sr.urn NOT IN (SELECT urn FROM users UNION SELECT urn FROM groups UNION SELECT urn FROM policies) "+
+		"ORDER BY p.org, p.name, s.position, sr.urn",
+		urnPattern("", api.RESOURCE_USER), urnPattern("%", api.RESOURCE_GROUP), urnPattern("%", api.RESOURCE_POLICY)).
+		Scan(&report.DanglingReferences)
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Relations with groups, users and policies that don't exist
+	query = c.Dbmap.Raw("SELECT group_id, user_id FROM group_user_relations " +
+		"WHERE group_id NOT IN (SELECT id FROM groups) OR user_id NOT IN (SELECT id FROM users) " +
+		"ORDER BY group_id, user_id").Scan(&report.DanglingMemberships)
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	query = c.Dbmap.Raw("SELECT group_id, policy_id FROM group_policy_relations " +
+		"WHERE group_id NOT IN (SELECT id FROM groups) OR policy_id NOT IN (SELECT id FROM policies) " +
+		"ORDER BY group_id, policy_id").Scan(&report.DanglingAttachments)
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	query = c.Dbmap.Raw("SELECT group_id, user_id FROM group_owner_relations " +
+		"WHERE group_id NOT IN (SELECT id FROM groups) OR user_id NOT IN (SELECT id FROM users) " +
+		"ORDER BY group_id, user_id").Scan(&report.DanglingOwnerships)
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Tags of users, groups and policies that don't exist
+	query = c.Dbmap.Raw("SELECT resource_id, key FROM tags " +
+		"WHERE resource_id NOT IN (SELECT id FROM users UNION SELECT id FROM groups UNION SELECT id FROM policies) " +
+		"ORDER BY resource_id, key").Scan(&report.DanglingTags)
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Policies without statements
+	query = c.Dbmap.Raw("SELECT org, name FROM policies WHERE id NOT IN (SELECT policy_id FROM statements) " +
+		"ORDER BY org, name").Scan(&report.EmptyPolicies)
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Groups in organizations without policies
+	query = c.Dbmap.Raw("SELECT org, name FROM groups WHERE org NOT IN (SELECT org FROM policies) " +
+		"ORDER BY org, name").Scan(&report.GroupsWithoutOrgPolicies)
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return report, nil
+}
+
+// PRIVATE HELPER METHODS
+
+// LIKE pattern of the URNs of a resource type in an organization, which can be a pattern too
+func urnPattern(org string, resource string) string {
+	return strings.TrimSuffix(api.GetUrnPrefix(org, resource, "/"), "*") + "%"
+}
@@ -0,0 +1,293 @@
+package postgresql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+)
+
+func TestPostgresRepo_GetConsistencyReport(t *testing.T) {
+	now := time.Now().UTC()
+	existingGroupUrn := api.CreateUrn("example", api.RESOURCE_GROUP, "/path/", "group")
+	removedGroupUrn := api.CreateUrn("example", api.RESOURCE_GROUP, "/path/", "removed")
+	testcases := map[string]struct {
+		// Policy added with the repository, so its references are indexed
+		policy *api.Policy
+		// Policy stored without statements
+		emptyPolicy *Policy
+		group       *Group
+		user        *User
+		// Relations stored directly
+		groupUserRelations   []GroupUserRelation
+		groupPolicyRelations []GroupPolicyRelation
+		groupOwnerRelations  []GroupOwnerRelation
+		tags                 []Tag
+		// Expected result
+		expectedResponse *api.ConsistencyReport
+	}{
+		"OkCaseConsistent": {
+			policy: &api.Policy{
+				ID:       "POLICY-ID",
+				Name:     "policy",
+				Org:      "example",
+				Path:     "/path/",
+				CreateAt: now,
+				Urn:      api.CreateUrn("example", api.RESOURCE_POLICY, "/path/", "policy"),
+				Statements: &[]api.Statement{
+					{
+						Sid:    "members",
+						Effect: "allow",
+						Actions: []string{
+							api.GROUP_ACTION_ADD_MEMBER,
+						},
+						Resources: []string{
+							existingGroupUrn,
+							api.GetUrnPrefix("example", api.RESOURCE_GROUP, "/other/"),
+						},
+					},
+				},
+			},
+			group: &Group{
+				ID:       "GROUP-ID",
+				Name:     "group",
+				Org:      "example",
+				Path:     "/path/",
+				CreateAt: now.UnixNano(),
+				Urn:      existingGroupUrn,
+			},
+			user: &User{
+				ID:         "USER-ID",
+				ExternalID: "user",
+				Path:       "/path/",
+				CreateAt:   now.UnixNano(),
+				Urn:        api.CreateUrn("", api.RESOURCE_USER, "/path/", "user"),
+			},
+			groupUserRelations: []GroupUserRelation{
+				{
+					GroupID: "GROUP-ID",
+					UserID:  "USER-ID",
+				},
+			},
+			groupPolicyRelations: []GroupPolicyRelation{
+				{
+					GroupID:  "GROUP-ID",
+					PolicyID: "POLICY-ID",
+				},
+			},
+			groupOwnerRelations: []GroupOwnerRelation{
+				{
+					GroupID: "GROUP-ID",
+					UserID:  "USER-ID",
+				},
+			},
+			tags: []Tag{
+				{
+					ResourceID: "USER-ID",
+					Key:        "team",
+					Value:      "security",
+				},
+				{
+					ResourceID: "GROUP-ID",
+					Key:        "team",
+					Value:      "security",
+				},
+				{
+					ResourceID: "POLICY-ID",
+					Key:        "team",
+					Value:      "security",
+				},
+			},
+			expectedResponse: &api.ConsistencyReport{
+				DanglingReferences:       []api.DanglingReference{},
+				DanglingMemberships:      []api.DanglingRelation{},
+				DanglingAttachments:      []api.DanglingRelation{},
+				DanglingOwnerships:       []api.DanglingRelation{},
+				DanglingTags:             []api.DanglingTag{},
+				EmptyPolicies:            []api.PolicyIdentity{},
+				GroupsWithoutOrgPolicies: []api.GroupIdentity{},
+			},
+		},
+		"OkCaseInconsistent": {
+			policy: &api.Policy{
+				ID:       "POLICY-ID",
+				Name:     "policy",
+				Org:      "example",
+				Path:     "/path/",
+				CreateAt: now,
+				Urn:      api.CreateUrn("example", api.RESOURCE_POLICY, "/path/", "policy"),
+				Statements: &[]api.Statement{
+					{
+						Sid:    "members",
+						Effect: "allow",
+						Actions: []string{
+							api.GROUP_ACTION_ADD_MEMBER,
+						},
+						Resources: []string{
+							removedGroupUrn,
+							"urn:ews:product:instance:example/resource",
+						},
+					},
+				},
+			},
+			emptyPolicy: &Policy{
+				ID:       "EMPTY-POLICY-ID",
+				Name:     "empty",
+				Org:      "example",
+				Path:     "/path/",
+				CreateAt: now.UnixNano(),
+				Urn:      api.CreateUrn("example", api.RESOURCE_POLICY, "/path/", "empty"),
+			},
+			group: &Group{
+				ID:       "GROUP-ID",
+				Name:     "group",
+				Org:      "other",
+				Path:     "/path/",
+				CreateAt: now.UnixNano(),
+				Urn:      api.CreateUrn("other", api.RESOURCE_GROUP, "/path/", "group"),
+			},
+			groupUserRelations: []GroupUserRelation{
+				{
+					GroupID: "GROUP-ID",
+					UserID:  "REMOVED-USER-ID",
+				},
+			},
+			groupPolicyRelations: []GroupPolicyRelation{
+				{
+					GroupID:  "REMOVED-GROUP-ID",
+					PolicyID: "POLICY-ID",
+				},
+			},
+			groupOwnerRelations: []GroupOwnerRelation{
+				{
+					GroupID: "GROUP-ID",
+					UserID:  "REMOVED-USER-ID",
+				},
+				{
+					GroupID: "REMOVED-GROUP-ID",
+					UserID:  "REMOVED-USER-ID",
+				},
+			},
+			tags: []Tag{
+				{
+					ResourceID: "GROUP-ID",
+					Key:        "team",
+					Value:      "security",
+				},
+				{
+					ResourceID: "REMOVED-USER-ID",
+					Key:        "team",
+					Value:      "security",
+				},
+			},
+			expectedResponse: &api.ConsistencyReport{
+				DanglingReferences: []api.DanglingReference{
+					{
+						Org:        "example",
+						PolicyName: "policy",
+						Sid:        "members",
+						Urn:        removedGroupUrn,
+					},
+				},
+				DanglingMemberships: []api.DanglingRelation{
+					{
+						GroupID: "GROUP-ID",
+						UserID:  "REMOVED-USER-ID",
+					},
+				},
+				DanglingAttachments: []api.DanglingRelation{
+					{
+						GroupID:  "REMOVED-GROUP-ID",
+						PolicyID: "POLICY-ID",
+					},
+				},
+				DanglingOwnerships: []api.DanglingRelation{
+					{
+						GroupID: "GROUP-ID",
+						UserID:  "REMOVED-USER-ID",
+					},
+					{
+						GroupID: "REMOVED-GROUP-ID",
+						UserID:  "REMOVED-USER-ID",
+					},
+				},
+				DanglingTags: []api.DanglingTag{
+					{
+						ResourceID: "REMOVED-USER-ID",
+						Key:        "team",
+					},
+				},
+				EmptyPolicies: []api.PolicyIdentity{
+					{
+						Org:  "example",
+						Name: "empty",
+					},
+				},
+				GroupsWithoutOrgPolicies: []api.GroupIdentity{
+					{
+						Org:  "other",
+						Name: "group",
+					},
+				},
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean database
+		cleanUserTable()
+		cleanGroupTable()
+		cleanPolicyTable()
+		cleanStatementTable()
+		cleanGroupUserRelationTable()
+		cleanGroupPolicyRelationTable()
+		cleanGroupOwnerRelationTable()
+		cleanTagTable()
+
+		// Insert previous data
+		if test.policy != nil {
+			if _, err := repoDB.AddPolicy(*test.policy); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting policy: %v", n, err)
+				continue
+			}
+		}
+		rows := []interface{}{}
+		if test.emptyPolicy != nil {
+			rows = append(rows, test.emptyPolicy)
+		}
+		if test.group != nil {
+			rows = append(rows, test.group)
+		}
+		if test.user != nil {
+			rows = append(rows, test.user)
+		}
+		for i := range test.groupUserRelations {
+			rows = append(rows, &test.groupUserRelations[i])
+		}
+		for i := range test.groupPolicyRelations {
+			rows = append(rows, &test.groupPolicyRelations[i])
+		}
+		for i := range test.groupOwnerRelations {
+			rows = append(rows, &test.groupOwnerRelations[i])
+		}
+		for i := range test.tags {
+			rows = append(rows, &test.tags[i])
+		}
+		if err := createRows(repoDB.Dbmap, rows); err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting rows: %v", n, err)
+			continue
+		}
+
+		// Call repository to check consistency
+		report, err := repoDB.GetConsistencyReport()
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if diff := pretty.Compare(report, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
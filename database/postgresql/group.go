@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jinzhu/gorm"
 	"github.com/tecsisa/foulkon/api"
 	"github.com/tecsisa/foulkon/database"
 )
@@ -56,7 +57,20 @@ func (g PostgresRepo) GetGroupByName(org string, name string) (*api.Group, error
 		}
 	}
 
-	return dbGroupToAPIGroup(group), nil
+	// Retrieve group tags and owners
+	apiGroup := dbGroupToAPIGroup(group)
+	tags, err := g.getTags(apiGroup.ID)
+	if err != nil {
+		return nil, err
+	}
+	apiGroup.Tags = tags
+	owners, err := g.getOwnersByGroupIDs([]string{apiGroup.ID})
+	if err != nil {
+		return nil, err
+	}
+	apiGroup.Owners = owners[apiGroup.ID]
+
+	return apiGroup, nil
 }
 
 func (g PostgresRepo) GetGroupById(id string) (*api.Group, error) {
@@ -79,21 +93,44 @@ func (g PostgresRepo) GetGroupById(id string) (*api.Group, error) {
 		}
 	}
 
-	return dbGroupToAPIGroup(group), nil
+	// Retrieve group tags and owners
+	apiGroup := dbGroupToAPIGroup(group)
+	tags, err := g.getTags(apiGroup.ID)
+	if err != nil {
+		return nil, err
+	}
+	apiGroup.Tags = tags
+	owners, err := g.getOwnersByGroupIDs([]string{apiGroup.ID})
+	if err != nil {
+		return nil, err
+	}
+	apiGroup.Owners = owners[apiGroup.ID]
+
+	return apiGroup, nil
 }
 
-func (g PostgresRepo) GetGroupsFiltered(org string, pathPrefix string) ([]api.Group, error) {
+func (g PostgresRepo) GetGroupsFiltered(filter *api.Filter) ([]api.Group, int, error) {
 	groups := []Group{}
 	query := g.Dbmap
-	if len(org) > 0 {
-		query = query.Where("org like ? ", org)
+	if len(filter.Org) > 0 {
+		query = query.Where("org like ? ", filter.Org)
+	}
+	query = filterQuery(query, filter, "name")
+	if filter.Orphan {
+		// Groups without members
+		query = query.Where("id not in (SELECT group_id FROM group_user_relations WHERE expires_at = 0 OR expires_at > ?)",
+			time.Now().UTC().UnixNano())
 	}
-	if len(pathPrefix) > 0 {
-		query = query.Where("path like ? ", pathPrefix+"%")
+
+	// Count groups and retrieve the requested page
+	query, total, err := paginate(query, &Group{}, filter)
+	if err != nil {
+		return nil, 0, err
 	}
+
 	// Error handling
-	if err := query.Find(&groups).Error; err != nil {
-		return nil, &database.Error{
+	if err := sortQuery(query, filter, "name", "org, name").Find(&groups).Error; err != nil {
+		return nil, 0, &database.Error{
 			Code:    database.INTERNAL_ERROR,
 			Message: err.Error(),
 		}
@@ -101,24 +138,39 @@ func (g PostgresRepo) GetGroupsFiltered(org string, pathPrefix string) ([]api.Gr
 
 	// Transform users for API
 	if groups != nil {
+		ids := make([]string, len(groups))
+		for i, group := range groups {
+			ids[i] = group.ID
+		}
+		tags, err := g.getTagsByResourceIDs(ids)
+		if err != nil {
+			return nil, 0, err
+		}
+		owners, err := g.getOwnersByGroupIDs(ids)
+		if err != nil {
+			return nil, 0, err
+		}
 		apiGroups := make([]api.Group, len(groups), cap(groups))
 		for i, g := range groups {
 			apiGroups[i] = *dbGroupToAPIGroup(&g)
+			apiGroups[i].Tags = tags[g.ID]
+			apiGroups[i].Owners = owners[g.ID]
 		}
-		return apiGroups, nil
+		return apiGroups, total, nil
 	}
 
 	// No data to return
-	return nil, nil
+	return nil, total, nil
 }
 
 func (g PostgresRepo) UpdateGroup(group api.Group, newName string, newPath string, urn string) (*api.Group, error) {
 
 	// Create new group
 	updatedGroup := Group{
-		Name: newName,
-		Path: newPath,
-		Urn:  urn,
+		Name:     newName,
+		Path:     newPath,
+		Urn:      urn,
+		Revision: group.Revision + 1,
 	}
 
 	groupDB := Group{
@@ -130,8 +182,8 @@ func (g PostgresRepo) UpdateGroup(group api.Group, newName string, newPath strin
 		Org:      group.Org,
 	}
 
-	// Update group
-	query := g.Dbmap.Model(&groupDB).Update(updatedGroup)
+	// Update group if it hasn't changed since it was retrieved
+	query := g.Dbmap.Model(&groupDB).Where("revision = ?", group.Revision).Update(updatedGroup)
 
 	// Check if group exist
 	if query.RecordNotFound() {
@@ -148,23 +200,71 @@ func (g PostgresRepo) UpdateGroup(group api.Group, newName string, newPath strin
 			Message: err.Error(),
 		}
 	}
+	if query.RowsAffected == 0 {
+		return nil, revisionConflictError(group.Urn)
+	}
+
+	// Membership rule, owners and tags don't change
+	groupApi := dbGroupToAPIGroup(&groupDB)
+	groupApi.MembershipRule = group.MembershipRule
+	groupApi.Owners = group.Owners
+	groupApi.Tags = group.Tags
 
-	return dbGroupToAPIGroup(&groupDB), nil
+	return groupApi, nil
 }
 
-func (g PostgresRepo) RemoveGroup(id string) error {
-	transaction := g.Dbmap.Begin()
-	// Delete group
-	transaction.Where("id like ?", id).Delete(&Group{})
+func (g PostgresRepo) RemoveGroup(group api.Group) error {
+	id := group.ID
+	transaction := g.begin()
+	// Move group with its relationships and tags to the trash
+	document := trashDocument{Group: &Group{}}
+	if err := findTrashRows(transaction.DB, document.Group, "id", id); err != nil {
+		transaction.Rollback()
+		return err
+	}
+	if err := findTrashRows(transaction.DB, &document.GroupUserRelations, "group_id", id); err != nil {
+		transaction.Rollback()
+		return err
+	}
+	if err := findTrashRows(transaction.DB, &document.GroupOwnerRelations, "group_id", id); err != nil {
+		transaction.Rollback()
+		return err
+	}
+	if err := findTrashRows(transaction.DB, &document.GroupPolicyRelations, "group_id", id); err != nil {
+		transaction.Rollback()
+		return err
+	}
+	if err := findTrashRows(transaction.DB, &document.Tags, "resource_id", id); err != nil {
+		transaction.Rollback()
+		return err
+	}
+	err := moveToTrash(transaction.DB, DeletedResource{
+		ID:   id,
+		Type: api.RESOURCE_GROUP,
+		Org:  document.Group.Org,
+		Name: document.Group.Name,
+		Urn:  document.Group.Urn,
+	}, document)
+	if err != nil {
+		transaction.Rollback()
+		return err
+	}
+
+	// Delete group if it hasn't changed since it was retrieved
+	query := transaction.Where("id like ? AND revision = ?", id, group.Revision).Delete(&Group{})
 
 	// Error handling
-	if err := transaction.Error; err != nil {
+	if err := query.Error; err != nil {
 		transaction.Rollback()
 		return &database.Error{
 			Code:    database.INTERNAL_ERROR,
 			Message: err.Error(),
 		}
 	}
+	if query.RowsAffected == 0 {
+		transaction.Rollback()
+		return revisionConflictError(group.Urn)
+	}
 
 	// Delete all group relations
 	transaction.Where("group_id like ?", id).Delete(&GroupUserRelation{})
@@ -178,20 +278,129 @@ func (g PostgresRepo) RemoveGroup(id string) error {
 		}
 	}
 
+	// Delete group owners
+	transaction.Where("group_id like ?", id).Delete(&GroupOwnerRelation{})
+
+	// Error handling
+	if err := transaction.Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Delete all group policy relations, they are kept in the trash
+	transaction.Where("group_id like ?", id).Delete(&GroupPolicyRelation{})
+
+	// Error handling
+	if err := transaction.Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Delete group tags
+	transaction.Where("resource_id like ?", id).Delete(&Tag{})
+
+	// Error handling
+	if err := transaction.Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
 	transaction.Commit()
 	return nil
 }
 
-func (g PostgresRepo) AddMember(userID string, groupID string) error {
+func (g PostgresRepo) SetGroupMembershipRule(group api.Group, rule *api.MembershipRule) (*api.Group, error) {
+	transaction := g.begin()
+	newRule := api.MembershipRule{}
+	if rule != nil {
+		newRule = *rule
+	}
+
+	// Update group rule if the group hasn't changed since it was retrieved. Blank fields of structs
+	// aren't updated, so a map is used to clear them
+	query := transaction.Model(&Group{ID: group.ID}).Where("revision = ?", group.Revision).Updates(map[string]interface{}{
+		"rule_path_prefix":     newRule.PathPrefix,
+		"rule_attribute_key":   newRule.AttributeKey,
+		"rule_attribute_value": newRule.AttributeValue,
+		"revision":             group.Revision + 1,
+	})
+
+	// Error handling
+	if err := query.Error; err != nil {
+		transaction.Rollback()
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	if query.RowsAffected == 0 {
+		transaction.Rollback()
+		return nil, revisionConflictError(group.Urn)
+	}
+
+	// Explicit members are replaced by the rule
+	if rule != nil {
+		if err := transaction.Where("group_id like ?", group.ID).Delete(&GroupUserRelation{}).Error; err != nil {
+			transaction.Rollback()
+			return nil, &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: err.Error(),
+			}
+		}
+	}
+
+	transaction.Commit()
+
+	updatedGroup := group
+	updatedGroup.MembershipRule = rule
+	updatedGroup.Revision = group.Revision + 1
+	return &updatedGroup, nil
+}
+
+func (g PostgresRepo) AddMember(userID string, groupID string, expiresAt *time.Time) error {
 
 	// Create relation
 	relation := &GroupUserRelation{
-		UserID:  userID,
-		GroupID: groupID,
+		UserID:    userID,
+		GroupID:   groupID,
+		ExpiresAt: timeToUnixNano(expiresAt),
+	}
+
+	transaction := g.begin()
+
+	// Delete expired relation if exists
+	if err := transaction.Where("user_id like ? AND group_id like ?", userID, groupID).Delete(&GroupUserRelation{}).Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
 	}
 
 	// Store relation
-	err := g.Dbmap.Create(relation).Error
+	if err := transaction.Create(relation).Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	transaction.Commit()
+	return nil
+}
+
+func (g PostgresRepo) RemoveMember(userID string, groupID string) error {
+	err := g.Dbmap.Where("user_id like ? AND group_id like ?", userID, groupID).Delete(&GroupUserRelation{}).Error
 
 	// Error handling
 	if err != nil {
@@ -200,12 +409,27 @@ func (g PostgresRepo) AddMember(userID string, groupID string) error {
 			Message: err.Error(),
 		}
 	}
+	return nil
+}
 
+func (g PostgresRepo) AddOwner(userID string, groupID string) error {
+	relation := &GroupOwnerRelation{
+		UserID:  userID,
+		GroupID: groupID,
+	}
+
+	// Store relation
+	if err := g.Dbmap.Create(relation).Error; err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
 	return nil
 }
 
-func (g PostgresRepo) RemoveMember(userID string, groupID string) error {
-	err := g.Dbmap.Where("user_id like ? AND group_id like ?", userID, groupID).Delete(&GroupUserRelation{}).Error
+func (g PostgresRepo) RemoveOwner(userID string, groupID string) error {
+	err := g.Dbmap.Where("user_id like ? AND group_id like ?", userID, groupID).Delete(&GroupOwnerRelation{}).Error
 
 	// Error handling
 	if err != nil {
@@ -218,8 +442,28 @@ func (g PostgresRepo) RemoveMember(userID string, groupID string) error {
 }
 
 func (g PostgresRepo) IsMemberOfGroup(userID string, groupID string) (bool, error) {
+	rule, err := g.getGroupMembershipRule(groupID)
+	if err != nil {
+		return false, err
+	}
+	if rule != nil {
+		var count int
+		query := whereMatchesMembershipRule(g.Dbmap.Model(&User{}).Where("id like ?", userID), *rule).Count(&count)
+
+		// Error Handling
+		if err := query.Error; err != nil {
+			return false, &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: err.Error(),
+			}
+		}
+		return count > 0, nil
+	}
+
 	relation := GroupUserRelation{}
-	query := g.Dbmap.Where("user_id like ? AND group_id like ?", userID, groupID).First(&relation)
+	query := g.Dbmap.Where("user_id like ? AND group_id like ?", userID, groupID).
+		Where("expires_at = 0 OR expires_at > ?", time.Now().UTC().UnixNano()).
+		First(&relation)
 
 	// Check if relation exists
 	if query.RecordNotFound() {
@@ -237,13 +481,28 @@ func (g PostgresRepo) IsMemberOfGroup(userID string, groupID string) (bool, erro
 	return true, nil
 }
 
-func (g PostgresRepo) GetGroupMembers(groupID string) ([]api.User, error) {
+func (g PostgresRepo) GetGroupMembers(groupID string, filter *api.Filter) ([]api.User, int, error) {
+	rule, err := g.getGroupMembershipRule(groupID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if rule != nil {
+		return g.getMembershipRuleUsers(*rule, filter)
+	}
+
 	members := []GroupUserRelation{}
-	query := g.Dbmap.Where("group_id like ?", groupID)
+	query := g.Dbmap.Where("group_id like ?", groupID).
+		Where("expires_at = 0 OR expires_at > ?", time.Now().UTC().UnixNano())
+
+	// Count members and retrieve the requested page
+	query, total, err := paginate(query, &GroupUserRelation{}, filter)
+	if err != nil {
+		return nil, 0, err
+	}
 
 	// Error handling
-	if err := query.Find(&members).Error; err != nil {
-		return nil, &database.Error{
+	if err := query.Order("user_id").Find(&members).Error; err != nil {
+		return nil, 0, &database.Error{
 			Code:    database.INTERNAL_ERROR,
 			Message: err.Error(),
 		}
@@ -257,7 +516,7 @@ func (g PostgresRepo) GetGroupMembers(groupID string) ([]api.User, error) {
 			user, err := g.GetUserByID(m.UserID)
 			// Error handling
 			if err != nil {
-				return nil, &database.Error{
+				return nil, 0, &database.Error{
 					Code:    database.INTERNAL_ERROR,
 					Message: err.Error(),
 				}
@@ -267,27 +526,82 @@ func (g PostgresRepo) GetGroupMembers(groupID string) ([]api.User, error) {
 		}
 	}
 
-	return apiUsers, nil
+	return apiUsers, total, nil
 }
 
-func (g PostgresRepo) AttachPolicy(groupID string, policyID string) error {
+func (g PostgresRepo) GetGroupUserRelations(groupID string, filter *api.Filter) ([]api.GroupUserRelation, int, error) {
+	relations := []GroupUserRelation{}
+	query := g.Dbmap.Where("group_id like ?", groupID).
+		Where("expires_at = 0 OR expires_at > ?", time.Now().UTC().UnixNano())
+
+	// Count relations and retrieve the requested page
+	query, total, err := paginate(query, &GroupUserRelation{}, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Error handling
+	if err := query.Order("user_id").Find(&relations).Error; err != nil {
+		return nil, 0, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	var apiRelations []api.GroupUserRelation
+	// Transform relations to API domain
+	if relations != nil {
+		apiRelations = make([]api.GroupUserRelation, len(relations), cap(relations))
+		for i, r := range relations {
+			user, err := g.GetUserByID(r.UserID)
+			// Error handling
+			if err != nil {
+				return nil, 0, &database.Error{
+					Code:    database.INTERNAL_ERROR,
+					Message: err.Error(),
+				}
+			}
+
+			apiRelations[i] = api.GroupUserRelation{
+				User:      user,
+				ExpiresAt: unixNanoToTime(r.ExpiresAt),
+			}
+		}
+	}
+
+	return apiRelations, total, nil
+}
+
+func (g PostgresRepo) AttachPolicy(groupID string, policyID string, notBefore *time.Time, notAfter *time.Time) error {
 	// Create relation
 	relation := &GroupPolicyRelation{
-		GroupID:  groupID,
-		PolicyID: policyID,
+		GroupID:   groupID,
+		PolicyID:  policyID,
+		NotBefore: timeToUnixNano(notBefore),
+		NotAfter:  timeToUnixNano(notAfter),
 	}
 
-	// Store relation
-	err := g.Dbmap.Create(relation).Error
+	transaction := g.begin()
 
-	// Error handling
-	if err != nil {
+	// Delete expired relation if exists
+	if err := transaction.Where("group_id like ? AND policy_id like ?", groupID, policyID).Delete(&GroupPolicyRelation{}).Error; err != nil {
+		transaction.Rollback()
 		return &database.Error{
 			Code:    database.INTERNAL_ERROR,
 			Message: err.Error(),
 		}
 	}
 
+	// Store relation
+	if err := transaction.Create(relation).Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	transaction.Commit()
 	return nil
 }
 
@@ -308,7 +622,9 @@ func (g PostgresRepo) DetachPolicy(groupID string, policyID string) error {
 
 func (g PostgresRepo) IsAttachedToGroup(groupID string, policyID string) (bool, error) {
 	relation := GroupPolicyRelation{}
-	query := g.Dbmap.Where("group_id like ? AND policy_id like ?", groupID, policyID).First(&relation)
+	query := g.Dbmap.Where("group_id like ? AND policy_id like ?", groupID, policyID).
+		Where("not_after = 0 OR not_after > ?", time.Now().UTC().UnixNano()).
+		First(&relation)
 
 	// Check if relation exists
 	if query.RecordNotFound() {
@@ -326,13 +642,22 @@ func (g PostgresRepo) IsAttachedToGroup(groupID string, policyID string) (bool,
 	return true, nil
 }
 
-func (g PostgresRepo) GetAttachedPolicies(groupID string) ([]api.Policy, error) {
+func (g PostgresRepo) GetAttachedPolicies(groupID string, filter *api.Filter) ([]api.Policy, int, error) {
+	now := time.Now().UTC().UnixNano()
 	relations := []GroupPolicyRelation{}
-	query := g.Dbmap.Where("group_id like ?", groupID).Find(&relations)
+	query := g.Dbmap.Where("group_id like ?", groupID).
+		Where("not_before = 0 OR not_before <= ?", now).
+		Where("not_after = 0 OR not_after > ?", now)
+
+	// Count relations and retrieve the requested page
+	query, total, err := paginate(query, &GroupPolicyRelation{}, filter)
+	if err != nil {
+		return nil, 0, err
+	}
 
 	// Error Handling
-	if err := query.Error; err != nil {
-		return nil, &database.Error{
+	if err := query.Order("policy_id").Find(&relations).Error; err != nil {
+		return nil, 0, &database.Error{
 			Code:    database.INTERNAL_ERROR,
 			Message: err.Error(),
 		}
@@ -345,7 +670,7 @@ func (g PostgresRepo) GetAttachedPolicies(groupID string) ([]api.Policy, error)
 			policy, err := g.GetPolicyById(r.PolicyID)
 			// Error handling
 			if err != nil {
-				return nil, &database.Error{
+				return nil, 0, &database.Error{
 					Code:    database.INTERNAL_ERROR,
 					Message: err.Error(),
 				}
@@ -355,19 +680,175 @@ func (g PostgresRepo) GetAttachedPolicies(groupID string) ([]api.Policy, error)
 		}
 	}
 
-	return apiPolicies, nil
+	return apiPolicies, total, nil
+}
+
+func (g PostgresRepo) GetGroupPolicyRelations(groupID string, filter *api.Filter) ([]api.GroupPolicyRelation, int, error) {
+	relations := []GroupPolicyRelation{}
+	query := g.Dbmap.Where("group_id like ?", groupID)
+
+	// Count relations and retrieve the requested page
+	query, total, err := paginate(query, &GroupPolicyRelation{}, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Error Handling
+	if err := query.Order("policy_id").Find(&relations).Error; err != nil {
+		return nil, 0, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	var apiRelations []api.GroupPolicyRelation
+	// Transform relations to API domain
+	if relations != nil {
+		apiRelations = make([]api.GroupPolicyRelation, len(relations), cap(relations))
+		for i, r := range relations {
+			policy, err := g.GetPolicyById(r.PolicyID)
+			// Error handling
+			if err != nil {
+				return nil, 0, &database.Error{
+					Code:    database.INTERNAL_ERROR,
+					Message: err.Error(),
+				}
+			}
+
+			apiRelations[i] = api.GroupPolicyRelation{
+				Policy:    policy,
+				NotBefore: unixNanoToTime(r.NotBefore),
+				NotAfter:  unixNanoToTime(r.NotAfter),
+			}
+		}
+	}
+
+	return apiRelations, total, nil
 }
 
 // PRIVATE HELPER METHODS
 
+// Transform an optional time into unix nano timestamp, nil is stored as 0
+func timeToUnixNano(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+	return t.UTC().UnixNano()
+}
+
+// Transform unix nano timestamp into an optional time, 0 is returned as nil
+func unixNanoToTime(nano int64) *time.Time {
+	if nano == 0 {
+		return nil
+	}
+	t := time.Unix(0, nano).UTC()
+	return &t
+}
+
 // Transform a Group retrieved from db into a group for API
 func dbGroupToAPIGroup(groupdb *Group) *api.Group {
-	return &api.Group{
+	group := &api.Group{
 		ID:       groupdb.ID,
 		Name:     groupdb.Name,
 		Path:     groupdb.Path,
 		CreateAt: time.Unix(0, groupdb.CreateAt).UTC(),
 		Urn:      groupdb.Urn,
 		Org:      groupdb.Org,
+		Revision: groupdb.Revision,
+	}
+	if groupdb.RulePathPrefix != "" || groupdb.RuleAttributeKey != "" {
+		group.MembershipRule = &api.MembershipRule{
+			PathPrefix:     groupdb.RulePathPrefix,
+			AttributeKey:   groupdb.RuleAttributeKey,
+			AttributeValue: groupdb.RuleAttributeValue,
+		}
+	}
+	return group
+}
+
+// Retrieve the membership rule of the group, nil if the group doesn't have rule or doesn't exist
+func (g PostgresRepo) getGroupMembershipRule(groupID string) (*api.MembershipRule, error) {
+	group := &Group{}
+	query := g.Dbmap.Where("id like ?", groupID).First(group)
+	if query.RecordNotFound() {
+		return nil, nil
+	}
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
 	}
+
+	return dbGroupToAPIGroup(group).MembershipRule, nil
+}
+
+// Retrieve a page of users that match the membership rule and the total number of them
+func (g PostgresRepo) getMembershipRuleUsers(rule api.MembershipRule, filter *api.Filter) ([]api.User, int, error) {
+	users := []User{}
+	query := whereMatchesMembershipRule(g.Dbmap, rule)
+
+	// Count users and retrieve the requested page
+	query, total, err := paginate(query, &User{}, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Error handling
+	if err := query.Order("id").Find(&users).Error; err != nil {
+		return nil, 0, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Transform users to API domain
+	apiUsers := make([]api.User, len(users))
+	for i, u := range users {
+		apiUsers[i] = *dbUserToAPIUser(&u)
+	}
+
+	return apiUsers, total, nil
+}
+
+// Filter users query by the conditions of the membership rule
+func whereMatchesMembershipRule(query *gorm.DB, rule api.MembershipRule) *gorm.DB {
+	if rule.PathPrefix != "" {
+		// Compare the prefix literally, paths may contain like wildcards such as _
+		query = query.Where("left(path, ?) = ?", len(rule.PathPrefix), rule.PathPrefix)
+	}
+	if rule.AttributeKey != "" {
+		// Users without attributes store an empty string, which isn't valid JSON
+		query = query.Where("(NULLIF(attributes, '')::jsonb ->> ?) = ?", rule.AttributeKey, rule.AttributeValue)
+	}
+	return query
+}
+
+// Retrieve external ids of the owners of several groups, indexed by group id
+func (g PostgresRepo) getOwnersByGroupIDs(groupIDs []string) (map[string][]string, error) {
+	owners := []struct {
+		GroupID    string
+		ExternalID string
+	}{}
+	query := g.Dbmap.Table("group_owner_relations").
+		Select("group_owner_relations.group_id, users.external_id").
+		Joins("JOIN users ON users.id = group_owner_relations.user_id").
+		Where("group_owner_relations.group_id in (?)", groupIDs).
+		Order("users.external_id")
+
+	// Error handling
+	if err := query.Scan(&owners).Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	ownersByGroup := map[string][]string{}
+	for _, owner := range owners {
+		ownersByGroup[owner.GroupID] = append(ownersByGroup[owner.GroupID], owner.ExternalID)
+	}
+
+	return ownersByGroup, nil
 }
@@ -116,7 +116,8 @@ func TestPostgresRepo_GetGroupByName(t *testing.T) {
 	now := time.Now().UTC()
 	testcases := map[string]struct {
 		// Previous data
-		previousGroup *api.Group
+		previousGroup  *api.Group
+		previousOwners []api.User
 		// Postgres Repo Args
 		org  string
 		name string
@@ -144,6 +145,37 @@ func TestPostgresRepo_GetGroupByName(t *testing.T) {
 				Org:      "Org",
 			},
 		},
+		"OkCaseWithOwners": {
+			previousGroup: &api.Group{
+				ID:       "GroupID",
+				Name:     "Name",
+				Path:     "Path",
+				Urn:      "Urn",
+				CreateAt: now,
+				Org:      "Org",
+			},
+			previousOwners: []api.User{
+				{
+					ID:         "UserID2",
+					ExternalID: "owner2",
+				},
+				{
+					ID:         "UserID1",
+					ExternalID: "owner1",
+				},
+			},
+			org:  "Org",
+			name: "Name",
+			expectedResponse: &api.Group{
+				ID:       "GroupID",
+				Name:     "Name",
+				Path:     "Path",
+				Urn:      "Urn",
+				CreateAt: now,
+				Org:      "Org",
+				Owners:   []string{"owner1", "owner2"},
+			},
+		},
 		"ErrorCaseGroupNotExist": {
 			previousGroup: &api.Group{
 				ID:       "GroupID",
@@ -165,6 +197,8 @@ func TestPostgresRepo_GetGroupByName(t *testing.T) {
 	for n, test := range testcases {
 		// Clean group database
 		cleanGroupTable()
+		cleanUserTable()
+		cleanGroupOwnerRelationTable()
 
 		// Insert previous data
 		if test.previousGroup != nil {
@@ -175,6 +209,16 @@ func TestPostgresRepo_GetGroupByName(t *testing.T) {
 				continue
 			}
 		}
+		for _, owner := range test.previousOwners {
+			if err := insertUser(owner.ID, owner.ExternalID, "/", now.UnixNano(), "Urn"+owner.ID); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous users: %v", n, err)
+				continue
+			}
+			if err := insertGroupOwnerRelation(owner.ID, test.previousGroup.ID); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous group owner relations: %v", n, err)
+				continue
+			}
+		}
 
 		// Call to repository to get group
 		receivedGroup, err := repoDB.GetGroupByName(test.org, test.name)
@@ -286,10 +330,12 @@ func TestPostgresRepo_GetGroupsFiltered(t *testing.T) {
 	testcases := map[string]struct {
 		// Previous data
 		previousGroups []api.Group
+		// Groups that have members
+		previousMembers []string
 		// Postgres Repo Args
-		org        string
-		pathPrefix string
+		filter *api.Filter
 		// Expected result
+		expectedTotal    int
 		expectedResponse []api.Group
 	}{
 		"OkCasePathPrefix1": {
@@ -311,7 +357,8 @@ func TestPostgresRepo_GetGroupsFiltered(t *testing.T) {
 					Org:      "Org2",
 				},
 			},
-			pathPrefix: "Path",
+			filter:        &api.Filter{PathPrefix: "Path"},
+			expectedTotal: 2,
 			expectedResponse: []api.Group{
 				{
 					ID:       "GroupID1",
@@ -350,7 +397,8 @@ func TestPostgresRepo_GetGroupsFiltered(t *testing.T) {
 					Org:      "Org2",
 				},
 			},
-			pathPrefix: "Path123",
+			filter:        &api.Filter{PathPrefix: "Path123"},
+			expectedTotal: 1,
 			expectedResponse: []api.Group{
 				{
 					ID:       "GroupID1",
@@ -381,7 +429,7 @@ func TestPostgresRepo_GetGroupsFiltered(t *testing.T) {
 					Org:      "Org2",
 				},
 			},
-			pathPrefix:       "NoPath",
+			filter:           &api.Filter{PathPrefix: "NoPath"},
 			expectedResponse: []api.Group{},
 		},
 		"OkCaseGetByOrg": {
@@ -403,7 +451,8 @@ func TestPostgresRepo_GetGroupsFiltered(t *testing.T) {
 					Org:      "Org2",
 				},
 			},
-			org: "Org1",
+			filter:        &api.Filter{Org: "Org1"},
+			expectedTotal: 1,
 			expectedResponse: []api.Group{
 				{
 					ID:       "GroupID1",
@@ -434,9 +483,61 @@ func TestPostgresRepo_GetGroupsFiltered(t *testing.T) {
 					Org:      "Org2",
 				},
 			},
-			org:        "Org1",
-			pathPrefix: "Path123",
+			filter:        &api.Filter{Org: "Org1", PathPrefix: "Path123"},
+			expectedTotal: 1,
+			expectedResponse: []api.Group{
+				{
+					ID:       "GroupID1",
+					Name:     "Name1",
+					Path:     "Path123",
+					Urn:      "urn1",
+					CreateAt: now,
+					Org:      "Org1",
+				},
+			},
+		},
+		"OkCaseFilterByNameSortDesc": {
+			previousGroups: []api.Group{
+				{
+					ID:       "GroupID1",
+					Name:     "Name1",
+					Path:     "Path123",
+					Urn:      "urn1",
+					CreateAt: now,
+					Org:      "Org1",
+				},
+				{
+					ID:       "GroupID2",
+					Name:     "Name2",
+					Path:     "Path456",
+					Urn:      "urn2",
+					CreateAt: now,
+					Org:      "Org2",
+				},
+			},
+			filter:        &api.Filter{Name: "ame", SortBy: api.SORT_BY_NAME, Order: api.ORDER_DESC},
+			expectedTotal: 2,
 			expectedResponse: []api.Group{
+				{
+					ID:       "GroupID2",
+					Name:     "Name2",
+					Path:     "Path456",
+					Urn:      "urn2",
+					CreateAt: now,
+					Org:      "Org2",
+				},
+				{
+					ID:       "GroupID1",
+					Name:     "Name1",
+					Path:     "Path123",
+					Urn:      "urn1",
+					CreateAt: now,
+					Org:      "Org1",
+				},
+			},
+		},
+		"OkCaseOrphan": {
+			previousGroups: []api.Group{
 				{
 					ID:       "GroupID1",
 					Name:     "Name1",
@@ -445,6 +546,27 @@ func TestPostgresRepo_GetGroupsFiltered(t *testing.T) {
 					CreateAt: now,
 					Org:      "Org1",
 				},
+				{
+					ID:       "GroupID2",
+					Name:     "Name2",
+					Path:     "Path456",
+					Urn:      "urn2",
+					CreateAt: now,
+					Org:      "Org2",
+				},
+			},
+			previousMembers: []string{"GroupID1"},
+			filter:          &api.Filter{Orphan: true},
+			expectedTotal:   1,
+			expectedResponse: []api.Group{
+				{
+					ID:       "GroupID2",
+					Name:     "Name2",
+					Path:     "Path456",
+					Urn:      "urn2",
+					CreateAt: now,
+					Org:      "Org2",
+				},
 			},
 		},
 		"OkCaseWithoutParams": {
@@ -466,6 +588,8 @@ func TestPostgresRepo_GetGroupsFiltered(t *testing.T) {
 					Org:      "Org2",
 				},
 			},
+			filter:        &api.Filter{},
+			expectedTotal: 2,
 			expectedResponse: []api.Group{
 				{
 					ID:       "GroupID1",
@@ -490,6 +614,7 @@ func TestPostgresRepo_GetGroupsFiltered(t *testing.T) {
 	for n, test := range testcases {
 		// Clean group database
 		cleanGroupTable()
+		cleanGroupUserRelationTable()
 
 		// Insert previous data
 		if test.previousGroups != nil {
@@ -501,12 +626,23 @@ func TestPostgresRepo_GetGroupsFiltered(t *testing.T) {
 				}
 			}
 		}
+		for _, groupID := range test.previousMembers {
+			if err := insertGroupUserRelation("UserID", groupID); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous group user relations: %v", n, err)
+				continue
+			}
+		}
 		// Call to repository to get groups
-		receivedGroups, err := repoDB.GetGroupsFiltered(test.org, test.pathPrefix)
+		receivedGroups, total, err := repoDB.GetGroupsFiltered(test.filter)
 		if err != nil {
 			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
 			continue
 		}
+		// Check total
+		if total != test.expectedTotal {
+			t.Errorf("Test %v failed. Received different total (wanted:%v / received:%v)", n, test.expectedTotal, total)
+			continue
+		}
 		// Check response
 		if diff := pretty.Compare(receivedGroups, test.expectedResponse); diff != "" {
 			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
@@ -558,6 +694,73 @@ func TestPostgresRepo_UpdateGroup(t *testing.T) {
 				Urn:      "NewUrn",
 				CreateAt: now,
 				Org:      "Org",
+				Revision: 1,
+			},
+		},
+		"OkCaseWithMembershipRule": {
+			previousGroups: []api.Group{
+				{
+					ID:       "GroupID",
+					Name:     "Name",
+					Path:     "Path",
+					Urn:      "Urn",
+					CreateAt: now,
+					Org:      "Org",
+				},
+			},
+			groupToUpdate: &api.Group{
+				ID:       "GroupID",
+				Name:     "Name",
+				Path:     "Path",
+				Urn:      "Urn",
+				CreateAt: now,
+				Org:      "Org",
+				MembershipRule: &api.MembershipRule{
+					PathPrefix: "/engineering/",
+				},
+			},
+			newName: "NewName",
+			newPath: "NewPath",
+			newUrn:  "NewUrn",
+			expectedResponse: &api.Group{
+				ID:       "GroupID",
+				Name:     "NewName",
+				Path:     "NewPath",
+				Urn:      "NewUrn",
+				CreateAt: now,
+				Org:      "Org",
+				MembershipRule: &api.MembershipRule{
+					PathPrefix: "/engineering/",
+				},
+				Revision: 1,
+			},
+		},
+		"ErrorCaseRevisionConflict": {
+			previousGroups: []api.Group{
+				{
+					ID:       "GroupID",
+					Name:     "Name",
+					Path:     "Path",
+					Urn:      "Urn",
+					CreateAt: now,
+					Org:      "Org",
+				},
+			},
+			groupToUpdate: &api.Group{
+				ID:       "GroupID",
+				Name:     "Name",
+				Path:     "Path",
+				Urn:      "Urn",
+				CreateAt: now,
+				Org:      "Org",
+				Revision: 2,
+			},
+			newName: "NewName",
+			newPath: "NewPath",
+			newUrn:  "NewUrn",
+			expectedError: &database.Error{
+				Code:    database.REVISION_CONFLICT,
+				Message: "Resource Urn has been modified since it was retrieved",
 			},
 		},
 		"ErrorCaseDuplicateUrn": {
@@ -687,6 +890,8 @@ func TestPostgresRepo_RemoveGroup(t *testing.T) {
 	for n, test := range testcases {
 		cleanGroupTable()
 		cleanGroupUserRelationTable()
+		cleanGroupOwnerRelationTable()
+		cleanDeletedResourceTable()
 
 		// Insert previous data
 		if test.previousGroup != nil {
@@ -702,10 +907,14 @@ func TestPostgresRepo_RemoveGroup(t *testing.T) {
 					t.Errorf("Test %v failed. Unexpected error inserting previous group user relations: %v", n, err)
 					continue
 				}
+				if err := insertGroupOwnerRelation(test.relation.user_id, id); err != nil {
+					t.Errorf("Test %v failed. Unexpected error inserting previous group owner relations: %v", n, err)
+					continue
+				}
 			}
 		}
 		// Call to repository to remove group
-		err := repoDB.RemoveGroup(test.groupToDelete)
+		err := repoDB.RemoveGroup(api.Group{ID: test.groupToDelete})
 
 		// Check database
 		groupNumber, err := getGroupsCountFiltered(test.groupToDelete, "", "",
@@ -728,14 +937,222 @@ func TestPostgresRepo_RemoveGroup(t *testing.T) {
 			t.Errorf("Test %v failed. Received different relations number: %v", n, relations)
 			continue
 		}
+		owners, err := getGroupOwnerRelations(test.previousGroup.ID, "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting owner relations: %v", n, err)
+			continue
+		}
+		if owners != 0 {
+			t.Errorf("Test %v failed. Received different owner relations number: %v", n, owners)
+			continue
+		}
+
+		// Check group was moved to the trash
+		deletedResources, err := getDeletedResourcesCountFiltered(test.groupToDelete, api.RESOURCE_GROUP)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting deleted resources: %v", n, err)
+			continue
+		}
+		if deletedResources != 1 {
+			t.Errorf("Test %v failed. Received different deleted resources number: %v", n, deletedResources)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_SetGroupMembershipRule(t *testing.T) {
+	now := time.Now().UTC()
+	group := api.Group{
+		ID:       "GroupID",
+		Name:     "Name",
+		Path:     "/path/",
+		Urn:      "Urn",
+		CreateAt: now,
+		Org:      "Org",
+	}
+	testcases := map[string]struct {
+		// Previous data
+		previousRule *api.MembershipRule
+		// Postgres Repo Args
+		rule *api.MembershipRule
+		// Expected result
+		expectedRelations int
+	}{
+		"OkCaseSetRule": {
+			rule: &api.MembershipRule{
+				PathPrefix:     "/engineering/",
+				AttributeKey:   "team",
+				AttributeValue: "backend",
+			},
+			expectedRelations: 0,
+		},
+		"OkCaseRemoveRule": {
+			previousRule: &api.MembershipRule{
+				PathPrefix: "/engineering/",
+			},
+			expectedRelations: 1,
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean database
+		cleanGroupTable()
+		cleanGroupUserRelationTable()
+
+		// Insert previous data
+		groupDB := &Group{
+			ID:       group.ID,
+			Name:     group.Name,
+			Path:     group.Path,
+			CreateAt: group.CreateAt.UnixNano(),
+			Urn:      group.Urn,
+			Org:      group.Org,
+		}
+		if test.previousRule != nil {
+			groupDB.RulePathPrefix = test.previousRule.PathPrefix
+			groupDB.RuleAttributeKey = test.previousRule.AttributeKey
+			groupDB.RuleAttributeValue = test.previousRule.AttributeValue
+		}
+		if err := repoDB.Dbmap.Create(groupDB).Error; err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting previous group: %v", n, err)
+			continue
+		}
+		if err := insertGroupUserRelation("UserID", group.ID); err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting previous group user relation: %v", n, err)
+			continue
+		}
+
+		// Call repository to set the rule
+		updatedGroup, err := repoDB.SetGroupMembershipRule(group, test.rule)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		expectedGroup := group
+		expectedGroup.MembershipRule = test.rule
+		expectedGroup.Revision = 1
+		if diff := pretty.Compare(updatedGroup, &expectedGroup); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+
+		// Check database
+		storedGroup, err := repoDB.GetGroupById(group.ID)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error retrieving group: %v", n, err)
+			continue
+		}
+		if diff := pretty.Compare(storedGroup.MembershipRule, test.rule); diff != "" {
+			t.Errorf("Test %v failed. Received different stored rules (received/wanted) %v", n, diff)
+			continue
+		}
+		relations, err := getGroupUserRelations(group.ID, "UserID")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting relations: %v", n, err)
+			continue
+		}
+		if relations != test.expectedRelations {
+			t.Errorf("Test %v failed. Received different number of relations (received/wanted) %v/%v", n, relations, test.expectedRelations)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_MembershipRuleMembers(t *testing.T) {
+	// Clean database
+	cleanUserTable()
+	cleanGroupTable()
+	cleanGroupUserRelationTable()
+
+	// Insert previous data. Explicit relations of groups with rule are ignored, and rule prefixes are literal
+	rows := []interface{}{
+		&User{ID: "USER-1", ExternalID: "user1", Path: "/engineering/backend/", Urn: "urn1", Attributes: `{"team":"backend"}`},
+		&User{ID: "USER-2", ExternalID: "user2", Path: "/engineering/", Urn: "urn2"},
+		&User{ID: "USER-3", ExternalID: "user3", Path: "/sales/", Urn: "urn3", Attributes: `{"team":"backend"}`},
+		&Group{ID: "GROUP-ATTR", Name: "attr", Org: "org", Path: "/", Urn: "urn-attr",
+			RuleAttributeKey: "team", RuleAttributeValue: "backend"},
+		&Group{ID: "GROUP-BOTH", Name: "both", Org: "org", Path: "/", Urn: "urn-both",
+			RulePathPrefix: "/engineering/", RuleAttributeKey: "team", RuleAttributeValue: "backend"},
+		&Group{ID: "GROUP-PATH", Name: "path", Org: "org", Path: "/", Urn: "urn-path", RulePathPrefix: "/engineering/"},
+		&Group{ID: "GROUP-STATIC", Name: "static", Org: "org", Path: "/", Urn: "urn-static"},
+		&Group{ID: "GROUP-WILDCARD", Name: "wildcard", Org: "org", Path: "/", Urn: "urn-wildcard", RulePathPrefix: "/sale_/"},
+		&GroupUserRelation{GroupID: "GROUP-STATIC", UserID: "USER-2"},
+		&GroupUserRelation{GroupID: "GROUP-PATH", UserID: "USER-3"},
+	}
+	if err := createRows(repoDB.Dbmap, rows); err != nil {
+		t.Fatalf("Unexpected error inserting previous data: %v", err)
+	}
+
+	// Members of groups
+	members := map[string][]string{
+		"GROUP-ATTR":     {"USER-1", "USER-3"},
+		"GROUP-BOTH":     {"USER-1"},
+		"GROUP-PATH":     {"USER-1", "USER-2"},
+		"GROUP-STATIC":   {"USER-2"},
+		"GROUP-WILDCARD": {},
+	}
+	for groupID, expected := range members {
+		users, total, err := repoDB.GetGroupMembers(groupID, &api.Filter{})
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error retrieving members: %v", groupID, err)
+			continue
+		}
+		ids := []string{}
+		for _, u := range users {
+			ids = append(ids, u.ID)
+		}
+		if diff := pretty.Compare(ids, expected); diff != "" || total != len(expected) {
+			t.Errorf("Test %v failed. Received different members (received/wanted) %v, total %v", groupID, diff, total)
+			continue
+		}
+		for _, userID := range []string{"USER-1", "USER-2", "USER-3"} {
+			isMember, err := repoDB.IsMemberOfGroup(userID, groupID)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error checking member %v: %v", groupID, userID, err)
+				continue
+			}
+			expectedMember := false
+			for _, id := range expected {
+				expectedMember = expectedMember || id == userID
+			}
+			if isMember != expectedMember {
+				t.Errorf("Test %v failed. Received different membership of user %v: %v", groupID, userID, isMember)
+			}
+		}
+	}
+
+	// Groups of users
+	groups := map[string][]string{
+		"USER-1": {"GROUP-ATTR", "GROUP-BOTH", "GROUP-PATH"},
+		"USER-2": {"GROUP-PATH", "GROUP-STATIC"},
+		"USER-3": {"GROUP-ATTR"},
+	}
+	for userID, expected := range groups {
+		userGroups, total, err := repoDB.GetGroupsByUserID(userID, &api.Filter{})
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error retrieving groups: %v", userID, err)
+			continue
+		}
+		ids := []string{}
+		for _, g := range userGroups {
+			ids = append(ids, g.ID)
+		}
+		if diff := pretty.Compare(ids, expected); diff != "" || total != len(expected) {
+			t.Errorf("Test %v failed. Received different groups (received/wanted) %v, total %v", userID, diff, total)
+			continue
+		}
 	}
 }
 
 func TestPostgresRepo_AddMember(t *testing.T) {
+	expiresAt := time.Now().UTC().Add(time.Hour)
 	testcases := map[string]struct {
+		// Previous data
+		expiredRelation bool
 		// Postgres Repo Args
-		userID  string
-		groupID string
+		userID    string
+		groupID   string
+		expiresAt *time.Time
 		// Expected result
 		expectedError *database.Error
 	}{
@@ -743,6 +1160,16 @@ func TestPostgresRepo_AddMember(t *testing.T) {
 			userID:  "UserID",
 			groupID: "GroupID",
 		},
+		"OkCaseWithExpiration": {
+			userID:    "UserID",
+			groupID:   "GroupID",
+			expiresAt: &expiresAt,
+		},
+		"OkCaseReplaceExpiredRelation": {
+			expiredRelation: true,
+			userID:          "UserID",
+			groupID:         "GroupID",
+		},
 		"ErrorCaseInternalError": {
 			groupID: "GroupID",
 			expectedError: &database.Error{
@@ -756,8 +1183,17 @@ func TestPostgresRepo_AddMember(t *testing.T) {
 		// Clean GroupUserRelation database
 		cleanGroupUserRelationTable()
 
+		// Insert previous data
+		if test.expiredRelation {
+			if err := insertGroupUserRelationWithExpiration(test.userID, test.groupID,
+				time.Now().UTC().Add(-time.Hour).UnixNano()); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous group user relations: %v", n, err)
+				continue
+			}
+		}
+
 		// Call to repository to store member
-		err := repoDB.AddMember(test.userID, test.groupID)
+		err := repoDB.AddMember(test.userID, test.groupID, test.expiresAt)
 		if test.expectedError != nil {
 			dbError, ok := err.(*database.Error)
 			if !ok || dbError == nil {
@@ -843,6 +1279,107 @@ func TestPostgresRepo_RemoveMember(t *testing.T) {
 	}
 }
 
+func TestPostgresRepo_AddOwner(t *testing.T) {
+	testcases := map[string]struct {
+		// Postgres Repo Args
+		userID  string
+		groupID string
+		// Expected result
+		expectedError *database.Error
+	}{
+		"OkCase": {
+			userID:  "UserID",
+			groupID: "GroupID",
+		},
+		"ErrorCaseInternalError": {
+			groupID: "GroupID",
+			expectedError: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "pq: null value in column user_id violates not-null constraint",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean GroupOwnerRelation database
+		cleanGroupOwnerRelationTable()
+
+		// Call to repository to store owner
+		err := repoDB.AddOwner(test.userID, test.groupID)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+
+			// Check database
+			relations, err := getGroupOwnerRelations(test.groupID, test.userID)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error counting relations: %v", n, err)
+				continue
+			}
+			if relations != 1 {
+				t.Errorf("Test %v failed. Received different relations number: %v", n, relations)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_RemoveOwner(t *testing.T) {
+	testcases := map[string]struct {
+		// Previous data
+		previousOwner bool
+		// Postgres Repo Args
+		userID  string
+		groupID string
+	}{
+		"OkCase": {
+			previousOwner: true,
+			userID:        "UserID",
+			groupID:       "GroupID",
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean GroupOwnerRelation database
+		cleanGroupOwnerRelationTable()
+
+		// Insert previous data
+		if test.previousOwner {
+			if err := insertGroupOwnerRelation(test.userID, test.groupID); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous group owner relations: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to remove owner
+		err := repoDB.RemoveOwner(test.userID, test.groupID)
+
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		// Check database
+		relations, err := getGroupOwnerRelations(test.groupID, test.userID)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting relations: %v", n, err)
+			continue
+		}
+		if relations != 0 {
+			t.Errorf("Test %v failed. Received different relations number: %v", n, relations)
+			continue
+		}
+	}
+}
+
 func TestPostgresRepo_IsMemberOfGroup(t *testing.T) {
 	testcases := map[string]struct {
 		// Previous data
@@ -850,6 +1387,7 @@ func TestPostgresRepo_IsMemberOfGroup(t *testing.T) {
 			user_id  string
 			group_id string
 		}
+		expired bool
 		// Postgres Repo Args
 		group  string
 		member string
@@ -873,6 +1411,19 @@ func TestPostgresRepo_IsMemberOfGroup(t *testing.T) {
 			member:   "UserID",
 			isMember: false,
 		},
+		"OkCaseExpiredMembership": {
+			relation: &struct {
+				user_id  string
+				group_id string
+			}{
+				user_id:  "UserID",
+				group_id: "GroupID",
+			},
+			expired:  true,
+			group:    "GroupID",
+			member:   "UserID",
+			isMember: false,
+		},
 	}
 
 	for n, test := range testcases {
@@ -880,7 +1431,11 @@ func TestPostgresRepo_IsMemberOfGroup(t *testing.T) {
 
 		// Insert previous data
 		if test.relation != nil {
-			if err := insertGroupUserRelation(test.relation.user_id, test.relation.group_id); err != nil {
+			var expiresAt int64
+			if test.expired {
+				expiresAt = time.Now().UTC().Add(-time.Hour).UnixNano()
+			}
+			if err := insertGroupUserRelationWithExpiration(test.relation.user_id, test.relation.group_id, expiresAt); err != nil {
 				t.Errorf("Test %v failed. Unexpected error inserting previous group user relations: %v", n, err)
 				continue
 			}
@@ -928,6 +1483,7 @@ func TestPostgresRepo_GetGroupMembers(t *testing.T) {
 						Path:       "Path",
 						Urn:        "urn1",
 						CreateAt:   now,
+						Status:     api.USER_STATUS_ACTIVE,
 					},
 					{
 						ID:         "UserID2",
@@ -935,6 +1491,7 @@ func TestPostgresRepo_GetGroupMembers(t *testing.T) {
 						Path:       "Path",
 						Urn:        "urn2",
 						CreateAt:   now,
+						Status:     api.USER_STATUS_ACTIVE,
 					},
 				},
 				group_id: "GroupID",
@@ -947,6 +1504,7 @@ func TestPostgresRepo_GetGroupMembers(t *testing.T) {
 					Path:       "Path",
 					Urn:        "urn1",
 					CreateAt:   now,
+					Status:     api.USER_STATUS_ACTIVE,
 				},
 				{
 					ID:         "UserID2",
@@ -954,6 +1512,7 @@ func TestPostgresRepo_GetGroupMembers(t *testing.T) {
 					Path:       "Path",
 					Urn:        "urn2",
 					CreateAt:   now,
+					Status:     api.USER_STATUS_ACTIVE,
 				},
 			},
 		},
@@ -1001,7 +1560,7 @@ func TestPostgresRepo_GetGroupMembers(t *testing.T) {
 
 		}
 
-		receivedUsers, err := repoDB.GetGroupMembers(test.groupID)
+		receivedUsers, total, err := repoDB.GetGroupMembers(test.groupID, &api.Filter{})
 		if test.expectedError != nil {
 			dbError, ok := err.(*database.Error)
 			if !ok || dbError == nil {
@@ -1022,15 +1581,25 @@ func TestPostgresRepo_GetGroupMembers(t *testing.T) {
 				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
 				continue
 			}
+			if total != len(test.expectedResponse) {
+				t.Errorf("Test %v failed. Received different total (wanted:%v / received:%v)", n, len(test.expectedResponse), total)
+				continue
+			}
 		}
 	}
 }
 
 func TestPostgresRepo_AttachPolicy(t *testing.T) {
+	notBefore := time.Now().UTC().Add(time.Hour)
+	notAfter := notBefore.Add(time.Hour)
 	testcases := map[string]struct {
+		// Previous data
+		expiredRelation bool
 		// Postgres Repo Args
-		policyID string
-		groupID  string
+		policyID  string
+		groupID   string
+		notBefore *time.Time
+		notAfter  *time.Time
 		// Expected result
 		expectedError *database.Error
 	}{
@@ -1038,6 +1607,18 @@ func TestPostgresRepo_AttachPolicy(t *testing.T) {
 			policyID: "PolicyID",
 			groupID:  "GroupID",
 		},
+		"OkCaseWithActivationWindow": {
+			policyID:  "PolicyID",
+			groupID:   "GroupID",
+			notBefore: &notBefore,
+			notAfter:  &notAfter,
+		},
+		"OkCaseReplaceExpiredRelation": {
+			expiredRelation: true,
+			policyID:        "PolicyID",
+			groupID:         "GroupID",
+			notAfter:        &notAfter,
+		},
 		"ErrorCaseInternalError": {
 			expectedError: &database.Error{
 				Code:    database.INTERNAL_ERROR,
@@ -1050,8 +1631,17 @@ func TestPostgresRepo_AttachPolicy(t *testing.T) {
 		// Clean GroupPolicyRelation database
 		cleanGroupPolicyRelationTable()
 
+		// Insert previous data
+		if test.expiredRelation {
+			if err := insertGroupPolicyRelationWithWindow(test.groupID, test.policyID, 0,
+				time.Now().UTC().Add(-time.Hour).UnixNano()); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous group policy relations: %v", n, err)
+				continue
+			}
+		}
+
 		// Call to repository to attach policy
-		err := repoDB.AttachPolicy(test.groupID, test.policyID)
+		err := repoDB.AttachPolicy(test.groupID, test.policyID, test.notBefore, test.notAfter)
 		if test.expectedError != nil {
 			dbError, ok := err.(*database.Error)
 			if !ok || dbError == nil {
@@ -1074,6 +1664,15 @@ func TestPostgresRepo_AttachPolicy(t *testing.T) {
 				t.Errorf("Test %v failed. Received different relations number: %v", n, relations)
 				continue
 			}
+			relation := GroupPolicyRelation{}
+			if err := repoDB.Dbmap.Where("group_id = ? AND policy_id = ?", test.groupID, test.policyID).First(&relation).Error; err != nil {
+				t.Errorf("Test %v failed. Unexpected error retrieving relation: %v", n, err)
+				continue
+			}
+			if relation.NotBefore != timeToUnixNano(test.notBefore) || relation.NotAfter != timeToUnixNano(test.notAfter) {
+				t.Errorf("Test %v failed. Received different activation window: %v - %v", n, relation.NotBefore, relation.NotAfter)
+				continue
+			}
 		}
 	}
 }
@@ -1144,6 +1743,7 @@ func TestPostgresRepo_IsAttachedToGroup(t *testing.T) {
 			group_id  string
 			policy_id string
 		}
+		expired bool
 		// Postgres Repo Args
 		groupID  string
 		policyID string
@@ -1174,6 +1774,19 @@ func TestPostgresRepo_IsAttachedToGroup(t *testing.T) {
 			policyID:       "PolicyIDXXXXXXX",
 			expectedResult: false,
 		},
+		"OkCaseExpired": {
+			relation: &struct {
+				group_id  string
+				policy_id string
+			}{
+				group_id:  "GroupID",
+				policy_id: "PolicyID",
+			},
+			expired:        true,
+			groupID:        "GroupID",
+			policyID:       "PolicyID",
+			expectedResult: false,
+		},
 	}
 
 	for n, test := range testcases {
@@ -1182,7 +1795,11 @@ func TestPostgresRepo_IsAttachedToGroup(t *testing.T) {
 
 		// Insert previous data
 		if test.relation != nil {
-			if err := insertGroupPolicyRelation(test.relation.group_id, test.relation.policy_id); err != nil {
+			var notAfter int64
+			if test.expired {
+				notAfter = time.Now().UTC().Add(-time.Hour).UnixNano()
+			}
+			if err := insertGroupPolicyRelationWithWindow(test.relation.group_id, test.relation.policy_id, 0, notAfter); err != nil {
 				t.Errorf("Test %v failed. Unexpected error inserting previous group policy relations: %v", n, err)
 				continue
 			}
@@ -1326,7 +1943,7 @@ func TestPostgresRepo_GetAttachedPolicies(t *testing.T) {
 
 		}
 
-		receivedPolicies, err := repoDB.GetAttachedPolicies(test.groupID)
+		receivedPolicies, total, err := repoDB.GetAttachedPolicies(test.groupID, &api.Filter{})
 		if test.expectedError != nil {
 			dbError, ok := err.(*database.Error)
 			if !ok || dbError == nil {
@@ -1347,6 +1964,274 @@ func TestPostgresRepo_GetAttachedPolicies(t *testing.T) {
 				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
 				continue
 			}
+			if total != len(test.expectedResponse) {
+				t.Errorf("Test %v failed. Received different total (wanted:%v / received:%v)", n, len(test.expectedResponse), total)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_GetAttachedPoliciesActivationWindow(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		notBefore int64
+		notAfter  int64
+		// Expected result
+		expectedPolicies int
+	}{
+		"OkCaseNoWindow": {
+			expectedPolicies: 1,
+		},
+		"OkCaseActiveWindow": {
+			notBefore:        now.Add(-time.Hour).UnixNano(),
+			notAfter:         now.Add(time.Hour).UnixNano(),
+			expectedPolicies: 1,
+		},
+		"OkCaseNotStarted": {
+			notBefore:        now.Add(time.Hour).UnixNano(),
+			expectedPolicies: 0,
+		},
+		"OkCaseExpired": {
+			notAfter:         now.Add(-time.Hour).UnixNano(),
+			expectedPolicies: 0,
+		},
+	}
+
+	for n, test := range testcases {
+		cleanPolicyTable()
+		cleanGroupPolicyRelationTable()
+
+		// Insert previous data
+		if err := insertPolicy("PolicyID", "Name", "org1", "/path/", now.UnixNano(), "Urn", []Statement{}); err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+			continue
+		}
+		if err := insertGroupPolicyRelationWithWindow("GroupID", "PolicyID", test.notBefore, test.notAfter); err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting previous group policy relations: %v", n, err)
+			continue
+		}
+
+		receivedPolicies, _, err := repoDB.GetAttachedPolicies("GroupID", &api.Filter{})
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if len(receivedPolicies) != test.expectedPolicies {
+			t.Errorf("Test %v failed. Received different policies number: %v", n, len(receivedPolicies))
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_GetGroupPolicyRelations(t *testing.T) {
+	now := time.Now().UTC()
+	notBefore := now.Add(time.Hour)
+	testcases := map[string]struct {
+		// Previous data
+		policy    api.Policy
+		notBefore int64
+		insert    bool
+		// Postgres Repo Args
+		groupID string
+		// Expected result
+		expectedResponse []api.GroupPolicyRelation
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			policy: api.Policy{
+				ID:       "PolicyID1",
+				Name:     "Name1",
+				Org:      "org1",
+				Path:     "/path/",
+				CreateAt: now,
+				Urn:      "Urn1",
+			},
+			notBefore: notBefore.UnixNano(),
+			insert:    true,
+			groupID:   "GroupID",
+			expectedResponse: []api.GroupPolicyRelation{
+				{
+					Policy: &api.Policy{
+						ID:         "PolicyID1",
+						Name:       "Name1",
+						Org:        "org1",
+						Path:       "/path/",
+						CreateAt:   now,
+						Urn:        "Urn1",
+						Statements: &[]api.Statement{},
+					},
+					NotBefore: &notBefore,
+				},
+			},
+		},
+		"ErrorCasePolicyNotFound": {
+			policy: api.Policy{
+				ID: "PolicyID1",
+			},
+			groupID: "GroupID",
+			expectedError: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Code: PolicyNotFound, Message: Policy with id PolicyID1 not found",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		cleanPolicyTable()
+		cleanGroupPolicyRelationTable()
+
+		// Insert previous data
+		if err := insertGroupPolicyRelationWithWindow(test.groupID, test.policy.ID, test.notBefore, 0); err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting previous group policy relations: %v", n, err)
+			continue
+		}
+		if test.insert {
+			if err := insertPolicy(test.policy.ID, test.policy.Name, test.policy.Org, test.policy.Path,
+				test.policy.CreateAt.UnixNano(), test.policy.Urn, []Statement{}); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		receivedRelations, total, err := repoDB.GetGroupPolicyRelations(test.groupID, &api.Filter{})
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check response
+			if diff := pretty.Compare(receivedRelations, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+			if total != len(test.expectedResponse) {
+				t.Errorf("Test %v failed. Received different total (wanted:%v / received:%v)", n, len(test.expectedResponse), total)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_GetGroupUserRelations(t *testing.T) {
+	now := time.Now().UTC()
+	expiresAt := now.Add(time.Hour)
+	testcases := map[string]struct {
+		// Previous data
+		user      api.User
+		expiresAt int64
+		insert    bool
+		// Postgres Repo Args
+		groupID string
+		// Expected result
+		expectedResponse []api.GroupUserRelation
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			user: api.User{
+				ID:         "UserID1",
+				ExternalID: "ExternalID1",
+				Path:       "/path/",
+				CreateAt:   now,
+				Urn:        "Urn1",
+				Status:     api.USER_STATUS_ACTIVE,
+			},
+			expiresAt: expiresAt.UnixNano(),
+			insert:    true,
+			groupID:   "GroupID",
+			expectedResponse: []api.GroupUserRelation{
+				{
+					User: &api.User{
+						ID:         "UserID1",
+						ExternalID: "ExternalID1",
+						Path:       "/path/",
+						CreateAt:   now,
+						Urn:        "Urn1",
+						Status:     api.USER_STATUS_ACTIVE,
+					},
+					ExpiresAt: &expiresAt,
+				},
+			},
+		},
+		"OkCaseExpiredMembership": {
+			user: api.User{
+				ID:         "UserID1",
+				ExternalID: "ExternalID1",
+				Path:       "/path/",
+				CreateAt:   now,
+				Urn:        "Urn1",
+				Status:     api.USER_STATUS_ACTIVE,
+			},
+			expiresAt:        now.Add(-time.Hour).UnixNano(),
+			insert:           true,
+			groupID:          "GroupID",
+			expectedResponse: []api.GroupUserRelation{},
+		},
+		"ErrorCaseUserNotFound": {
+			user: api.User{
+				ID: "UserID1",
+			},
+			groupID: "GroupID",
+			expectedError: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Code: UserNotFound, Message: User with id UserID1 not found",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		cleanUserTable()
+		cleanGroupUserRelationTable()
+
+		// Insert previous data
+		if err := insertGroupUserRelationWithExpiration(test.user.ID, test.groupID, test.expiresAt); err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting previous group user relations: %v", n, err)
+			continue
+		}
+		if test.insert {
+			if err := insertUser(test.user.ID, test.user.ExternalID, test.user.Path,
+				test.user.CreateAt.UnixNano(), test.user.Urn); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+
+		receivedRelations, total, err := repoDB.GetGroupUserRelations(test.groupID, &api.Filter{})
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check response
+			if diff := pretty.Compare(receivedRelations, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+			if total != len(test.expectedResponse) {
+				t.Errorf("Test %v failed. Received different total (wanted:%v / received:%v)", n, len(test.expectedResponse), total)
+				continue
+			}
 		}
 	}
 }
@@ -0,0 +1,169 @@
+package postgresql
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// ORGANIZATION REPOSITORY IMPLEMENTATION
+
+func (o PostgresRepo) AddOrganization(organization api.Organization) (*api.Organization, error) {
+
+	// Create organization model
+	organizationDB := &Organization{
+		ID:          organization.ID,
+		Name:        organization.Name,
+		Description: organization.Description,
+		Urn:         organization.Urn,
+		CreateAt:    organization.CreateAt.UnixNano(),
+		UpdateAt:    organization.UpdateAt.UnixNano(),
+	}
+
+	// Store organization
+	err := o.Dbmap.Create(organizationDB).Error
+
+	// Error handling
+	if err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbOrganizationToAPIOrganization(organizationDB), nil
+}
+
+func (o PostgresRepo) GetOrganizationByName(name string) (*api.Organization, error) {
+	organization := &Organization{}
+	query := o.Dbmap.Where("name like ?", name).First(organization)
+
+	// Check if organization exists
+	if query.RecordNotFound() {
+		return nil, &database.Error{
+			Code:    database.ORGANIZATION_NOT_FOUND,
+			Message: fmt.Sprintf("Organization with name %v not found", name),
+		}
+	}
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbOrganizationToAPIOrganization(organization), nil
+}
+
+func (o PostgresRepo) GetOrganizationsFiltered(filter *api.Filter) ([]api.Organization, int, error) {
+	organizations := []Organization{}
+	query := o.Dbmap
+
+	// Organizations don't have path nor tags, so only name and creation dates are filtered
+	if len(filter.Name) > 0 {
+		// Underscore is a wildcard in like expressions
+		query = query.Where("name like ?", "%"+strings.Replace(filter.Name, "_", `\_`, -1)+"%")
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("create_at > ?", filter.CreatedAfter.UnixNano())
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("create_at < ?", filter.CreatedBefore.UnixNano())
+	}
+
+	// Count organizations and retrieve the requested page
+	query, total, err := paginate(query, &Organization{}, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Error handling
+	if err := sortQuery(query, filter, "name", "name").Find(&organizations).Error; err != nil {
+		return nil, 0, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Transform organizations for API
+	apiOrganizations := make([]api.Organization, len(organizations), cap(organizations))
+	for i, org := range organizations {
+		apiOrganizations[i] = *dbOrganizationToAPIOrganization(&org)
+	}
+
+	return apiOrganizations, total, nil
+}
+
+func (o PostgresRepo) UpdateOrganization(organization api.Organization, newDescription string) (*api.Organization, error) {
+
+	organizationDB := Organization{
+		ID:          organization.ID,
+		Name:        organization.Name,
+		Description: organization.Description,
+		Urn:         organization.Urn,
+		CreateAt:    organization.CreateAt.UTC().UnixNano(),
+		UpdateAt:    organization.UpdateAt.UTC().UnixNano(),
+	}
+
+	// Update organization. Description is updated with a map because it can be empty
+	updateAt := time.Now().UTC().UnixNano()
+	query := o.Dbmap.Model(&organizationDB).Updates(map[string]interface{}{
+		"description": newDescription,
+		"update_at":   updateAt,
+	})
+
+	// Check if organization exist
+	if query.RecordNotFound() {
+		return nil, &database.Error{
+			Code:    database.ORGANIZATION_NOT_FOUND,
+			Message: fmt.Sprintf("Organization with name %v not found", organization.Name),
+		}
+	}
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	organizationDB.Description = newDescription
+	organizationDB.UpdateAt = updateAt
+
+	return dbOrganizationToAPIOrganization(&organizationDB), nil
+}
+
+func (o PostgresRepo) RemoveOrganization(id string) error {
+	// Delete organization
+	err := o.Dbmap.Where("id like ?", id).Delete(&Organization{}).Error
+
+	// Error handling
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Transform an organization retrieved from db into an organization for API
+func dbOrganizationToAPIOrganization(organizationdb *Organization) *api.Organization {
+	return &api.Organization{
+		ID:          organizationdb.ID,
+		Name:        organizationdb.Name,
+		Description: organizationdb.Description,
+		Urn:         organizationdb.Urn,
+		CreateAt:    time.Unix(0, organizationdb.CreateAt).UTC(),
+		UpdateAt:    time.Unix(0, organizationdb.UpdateAt).UTC(),
+	}
+}
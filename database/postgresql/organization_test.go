@@ -0,0 +1,407 @@
+package postgresql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestPostgresRepo_AddOrganization(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousOrganization *Organization
+		// Postgres Repo Args
+		organizationToCreate *api.Organization
+		// Expected result
+		expectedResponse *api.Organization
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			organizationToCreate: &api.Organization{
+				ID:          "OrgID",
+				Name:        "org1",
+				Description: "Organization 1",
+				Urn:         "urn:org1",
+				CreateAt:    now,
+				UpdateAt:    now,
+			},
+			expectedResponse: &api.Organization{
+				ID:          "OrgID",
+				Name:        "org1",
+				Description: "Organization 1",
+				Urn:         "urn:org1",
+				CreateAt:    now,
+				UpdateAt:    now,
+			},
+		},
+		"ErrorCaseAlreadyExist": {
+			previousOrganization: &Organization{
+				ID:       "OrgID",
+				Name:     "org1",
+				Urn:      "urn:org1",
+				CreateAt: now.UnixNano(),
+				UpdateAt: now.UnixNano(),
+			},
+			organizationToCreate: &api.Organization{
+				ID:       "OrgID",
+				Name:     "org1",
+				Urn:      "urn:org1",
+				CreateAt: now,
+				UpdateAt: now,
+			},
+			expectedError: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "pq: duplicate key value violates unique constraint \"organizations_pkey\"",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean organization database
+		cleanOrganizationTable()
+
+		// Insert previous data
+		if test.previousOrganization != nil {
+			if err := insertOrganization(*test.previousOrganization); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+		// Call to repository to store organization
+		storedOrganization, err := repoDB.AddOrganization(*test.organizationToCreate)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(storedOrganization, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+			// Check database
+			organizationNumber, err := getOrganizationsCountFiltered(test.organizationToCreate.ID, test.organizationToCreate.Name)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error counting organizations: %v", n, err)
+				continue
+			}
+			if organizationNumber != 1 {
+				t.Errorf("Test %v failed. Received different organization number: %v", n, organizationNumber)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_GetOrganizationByName(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousOrganization *Organization
+		// Postgres Repo Args
+		name string
+		// Expected result
+		expectedResponse *api.Organization
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			previousOrganization: &Organization{
+				ID:          "OrgID",
+				Name:        "org1",
+				Description: "Organization 1",
+				Urn:         "urn:org1",
+				CreateAt:    now.UnixNano(),
+				UpdateAt:    now.UnixNano(),
+			},
+			name: "org1",
+			expectedResponse: &api.Organization{
+				ID:          "OrgID",
+				Name:        "org1",
+				Description: "Organization 1",
+				Urn:         "urn:org1",
+				CreateAt:    now,
+				UpdateAt:    now,
+			},
+		},
+		"ErrorCaseNotFound": {
+			name: "org1",
+			expectedError: &database.Error{
+				Code:    database.ORGANIZATION_NOT_FOUND,
+				Message: "Organization with name org1 not found",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean organization database
+		cleanOrganizationTable()
+
+		// Insert previous data
+		if test.previousOrganization != nil {
+			if err := insertOrganization(*test.previousOrganization); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+		// Call to repository to get organization
+		organization, err := repoDB.GetOrganizationByName(test.name)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(organization, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_GetOrganizationsFiltered(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousOrganizations []Organization
+		// Postgres Repo Args
+		filter *api.Filter
+		// Expected result
+		expectedResponse []api.Organization
+		expectedTotal    int
+	}{
+		"OkCaseAll": {
+			previousOrganizations: []Organization{
+				{
+					ID:       "OrgID2",
+					Name:     "org2",
+					Urn:      "urn:org2",
+					CreateAt: now.UnixNano(),
+					UpdateAt: now.UnixNano(),
+				},
+				{
+					ID:       "OrgID1",
+					Name:     "org1",
+					Urn:      "urn:org1",
+					CreateAt: now.UnixNano(),
+					UpdateAt: now.UnixNano(),
+				},
+			},
+			filter: &api.Filter{},
+			expectedResponse: []api.Organization{
+				{
+					ID:       "OrgID1",
+					Name:     "org1",
+					Urn:      "urn:org1",
+					CreateAt: now,
+					UpdateAt: now,
+				},
+				{
+					ID:       "OrgID2",
+					Name:     "org2",
+					Urn:      "urn:org2",
+					CreateAt: now,
+					UpdateAt: now,
+				},
+			},
+			expectedTotal: 2,
+		},
+		"OkCaseFilteredByName": {
+			previousOrganizations: []Organization{
+				{
+					ID:       "OrgID1",
+					Name:     "org1",
+					Urn:      "urn:org1",
+					CreateAt: now.UnixNano(),
+					UpdateAt: now.UnixNano(),
+				},
+				{
+					ID:       "OrgID2",
+					Name:     "other",
+					Urn:      "urn:other",
+					CreateAt: now.UnixNano(),
+					UpdateAt: now.UnixNano(),
+				},
+			},
+			filter: &api.Filter{
+				PathPrefix: "/",
+				Name:       "org",
+			},
+			expectedResponse: []api.Organization{
+				{
+					ID:       "OrgID1",
+					Name:     "org1",
+					Urn:      "urn:org1",
+					CreateAt: now,
+					UpdateAt: now,
+				},
+			},
+			expectedTotal: 1,
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean organization database
+		cleanOrganizationTable()
+
+		// Insert previous data
+		for _, organization := range test.previousOrganizations {
+			if err := insertOrganization(organization); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+		// Call to repository to get organizations
+		organizations, total, err := repoDB.GetOrganizationsFiltered(test.filter)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if total != test.expectedTotal {
+			t.Errorf("Test %v failed. Received different total: %v", n, total)
+			continue
+		}
+		if diff := pretty.Compare(organizations, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_UpdateOrganization(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousOrganization *Organization
+		// Postgres Repo Args
+		organization   api.Organization
+		newDescription string
+		// Expected result
+		expectedDescription string
+	}{
+		"OkCase": {
+			previousOrganization: &Organization{
+				ID:          "OrgID",
+				Name:        "org1",
+				Description: "Old description",
+				Urn:         "urn:org1",
+				CreateAt:    now.UnixNano(),
+				UpdateAt:    now.UnixNano(),
+			},
+			organization: api.Organization{
+				ID:          "OrgID",
+				Name:        "org1",
+				Description: "Old description",
+				Urn:         "urn:org1",
+				CreateAt:    now,
+				UpdateAt:    now,
+			},
+			newDescription:      "",
+			expectedDescription: "",
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean organization database
+		cleanOrganizationTable()
+
+		// Insert previous data
+		if err := insertOrganization(*test.previousOrganization); err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+			continue
+		}
+		// Call to repository to update organization
+		organization, err := repoDB.UpdateOrganization(test.organization, test.newDescription)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if organization.Description != test.expectedDescription {
+			t.Errorf("Test %v failed. Received different description: %v", n, organization.Description)
+			continue
+		}
+		if !organization.UpdateAt.After(test.organization.UpdateAt) {
+			t.Errorf("Test %v failed. Update date wasn't changed: %v", n, organization.UpdateAt)
+			continue
+		}
+		// Check database
+		organizationDB, err := repoDB.GetOrganizationByName(test.organization.Name)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error retrieving organization: %v", n, err)
+			continue
+		}
+		if diff := pretty.Compare(organizationDB, organization); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_RemoveOrganization(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousOrganization *Organization
+		// Postgres Repo Args
+		id string
+	}{
+		"OkCase": {
+			previousOrganization: &Organization{
+				ID:       "OrgID",
+				Name:     "org1",
+				Urn:      "urn:org1",
+				CreateAt: now.UnixNano(),
+				UpdateAt: now.UnixNano(),
+			},
+			id: "OrgID",
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean organization database
+		cleanOrganizationTable()
+
+		// Insert previous data
+		if err := insertOrganization(*test.previousOrganization); err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+			continue
+		}
+		// Call to repository to remove organization
+		if err := repoDB.RemoveOrganization(test.id); err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		// Check database
+		organizationNumber, err := getOrganizationsCountFiltered(test.id, "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting organizations: %v", n, err)
+			continue
+		}
+		if organizationNumber != 0 {
+			t.Errorf("Test %v failed. Received different organization number: %v", n, organizationNumber)
+			continue
+		}
+	}
+}
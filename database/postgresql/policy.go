@@ -5,11 +5,15 @@ import (
 	"strings"
 	"time"
 
+	"github.com/jinzhu/gorm"
 	"github.com/satori/go.uuid"
 	"github.com/tecsisa/foulkon/api"
 	"github.com/tecsisa/foulkon/database"
 )
 
+// Prefix of the user, group and policy URNs indexed in statement references
+const REFERENCED_URN_PREFIX = "urn:iws:iam:"
+
 // POLICY REPOSITORY IMPLEMENTATION
 
 func (p PostgresRepo) AddPolicy(policy api.Policy) (*api.Policy, error) {
@@ -23,7 +27,7 @@ func (p PostgresRepo) AddPolicy(policy api.Policy) (*api.Policy, error) {
 		Org:      policy.Org,
 	}
 
-	transaction := p.Dbmap.Begin()
+	transaction := p.begin()
 
 	// Create policy
 	if err := transaction.Create(policyDB).Error; err != nil {
@@ -35,14 +39,17 @@ func (p PostgresRepo) AddPolicy(policy api.Policy) (*api.Policy, error) {
 	}
 
 	// Create statements
-	for _, statementApi := range *policy.Statements {
+	for i, statementApi := range *policy.Statements {
 		// Create statement model
 		statementDB := &Statement{
-			ID:        uuid.NewV4().String(),
-			PolicyID:  policy.ID,
-			Effect:    statementApi.Effect,
-			Actions:   stringArrayToString(statementApi.Actions),
-			Resources: stringArrayToString(statementApi.Resources),
+			ID:         uuid.NewV4().String(),
+			PolicyID:   policy.ID,
+			Sid:        statementApi.Sid,
+			Position:   i,
+			Effect:     statementApi.Effect,
+			Actions:    stringArrayToString(statementApi.Actions),
+			Resources:  stringArrayToString(statementApi.Resources),
+			Conditions: stringArrayToString(statementApi.Conditions),
 		}
 		if err := transaction.Create(statementDB).Error; err != nil {
 			transaction.Rollback()
@@ -51,6 +58,10 @@ func (p PostgresRepo) AddPolicy(policy api.Policy) (*api.Policy, error) {
 				Message: err.Error(),
 			}
 		}
+		if err := createStatementReferences(transaction.DB, statementDB); err != nil {
+			transaction.Rollback()
+			return nil, err
+		}
 	}
 
 	transaction.Commit()
@@ -84,7 +95,7 @@ func (p PostgresRepo) GetPolicyByName(org string, name string) (*api.Policy, err
 
 	// Retrieve associated statements
 	statements := []Statement{}
-	query = p.Dbmap.Where("policy_id like ?", policy.ID).Find(&statements)
+	query = p.Dbmap.Where("policy_id like ?", policy.ID).Order("position").Find(&statements)
 	// Error Handling
 	if err := query.Error; err != nil {
 		return nil, &database.Error{
@@ -93,9 +104,16 @@ func (p PostgresRepo) GetPolicyByName(org string, name string) (*api.Policy, err
 		}
 	}
 
+	// Retrieve policy tags
+	tags, err := p.getTags(policy.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create API policy
 	policyApi := dbPolicyToAPIPolicy(policy)
 	policyApi.Statements = dbStatementsToAPIStatements(statements)
+	policyApi.Tags = tags
 
 	return policyApi, nil
 }
@@ -122,7 +140,7 @@ func (p PostgresRepo) GetPolicyById(id string) (*api.Policy, error) {
 
 	// Retrieve associated statements
 	statements := []Statement{}
-	query = p.Dbmap.Where("policy_id like ?", policy.ID).Find(&statements)
+	query = p.Dbmap.Where("policy_id like ?", policy.ID).Order("position").Find(&statements)
 	// Error Handling
 	if err := query.Error; err != nil {
 		return nil, &database.Error{
@@ -131,27 +149,42 @@ func (p PostgresRepo) GetPolicyById(id string) (*api.Policy, error) {
 		}
 	}
 
+	// Retrieve policy tags
+	tags, err := p.getTags(policy.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create API policy
 	policyApi := dbPolicyToAPIPolicy(policy)
 	policyApi.Statements = dbStatementsToAPIStatements(statements)
+	policyApi.Tags = tags
 
 	return policyApi, nil
 }
 
-func (p PostgresRepo) GetPoliciesFiltered(org string, pathPrefix string) ([]api.Policy, error) {
+func (p PostgresRepo) GetPoliciesFiltered(filter *api.Filter) ([]api.Policy, int, error) {
 	policies := []Policy{}
 	var apiPolicies []api.Policy
 	query := p.Dbmap
-	if len(org) > 0 {
-		query = query.Where("org like ?", org)
+	if len(filter.Org) > 0 {
+		query = query.Where("org like ?", filter.Org)
+	}
+	query = filterQuery(query, filter, "name")
+	if filter.Orphan {
+		// Policies that aren't attached to any group
+		query = query.Where("id not in (SELECT policy_id FROM group_policy_relations)")
 	}
-	if len(pathPrefix) > 0 {
-		query = query.Where("path like ?", pathPrefix+"%")
+
+	// Count policies and retrieve the requested page
+	query, total, err := paginate(query, &Policy{}, filter)
+	if err != nil {
+		return nil, 0, err
 	}
 
 	// Error handling
-	if err := query.Find(&policies).Error; err != nil {
-		return nil, &database.Error{
+	if err := sortQuery(query, filter, "name", "org, name").Find(&policies).Error; err != nil {
+		return nil, 0, &database.Error{
 			Code:    database.INTERNAL_ERROR,
 			Message: err.Error(),
 		}
@@ -159,6 +192,14 @@ func (p PostgresRepo) GetPoliciesFiltered(org string, pathPrefix string) ([]api.
 
 	// Transform policies for API
 	if policies != nil {
+		ids := make([]string, len(policies))
+		for i, policy := range policies {
+			ids[i] = policy.ID
+		}
+		tags, err := p.getTagsByResourceIDs(ids)
+		if err != nil {
+			return nil, 0, err
+		}
 		apiPolicies = make([]api.Policy, len(policies), cap(policies))
 
 		for i, pol := range policies {
@@ -166,16 +207,17 @@ func (p PostgresRepo) GetPoliciesFiltered(org string, pathPrefix string) ([]api.
 
 			// Retrieve associated statements
 			statements := []Statement{}
-			query = p.Dbmap.Where("policy_id like ?", policy.ID).Find(&statements)
+			query = p.Dbmap.Where("policy_id like ?", policy.ID).Order("position").Find(&statements)
 			// Error Handling
 			if err := query.Error; err != nil {
-				return nil, &database.Error{
+				return nil, 0, &database.Error{
 					Code:    database.INTERNAL_ERROR,
 					Message: err.Error(),
 				}
 			}
 
 			policy.Statements = dbStatementsToAPIStatements(statements)
+			policy.Tags = tags[policy.ID]
 
 			// Assign policy
 			apiPolicies[i] = *policy
@@ -183,15 +225,16 @@ func (p PostgresRepo) GetPoliciesFiltered(org string, pathPrefix string) ([]api.
 
 	}
 
-	return apiPolicies, nil
+	return apiPolicies, total, nil
 }
 
 func (p PostgresRepo) UpdatePolicy(policy api.Policy, name string, path string, urn string, statements []api.Statement) (*api.Policy, error) {
 	// Create policy to update
 	policyUpdated := Policy{
-		Name: name,
-		Path: path,
-		Urn:  urn,
+		Name:     name,
+		Path:     path,
+		Urn:      urn,
+		Revision: policy.Revision + 1,
 	}
 
 	policyDB := Policy{
@@ -203,18 +246,30 @@ func (p PostgresRepo) UpdatePolicy(policy api.Policy, name string, path string,
 		Org:      policy.Org,
 	}
 
-	transaction := p.Dbmap.Begin()
+	transaction := p.begin()
 
-	// Update policy
-	if err := transaction.Model(&policyDB).Update(policyUpdated).Error; err != nil {
+	// Update policy if it hasn't changed since it was retrieved
+	query := transaction.Model(&policyDB).Where("revision = ?", policy.Revision).Update(policyUpdated)
+	if err := query.Error; err != nil {
 		transaction.Rollback()
 		return nil, &database.Error{
 			Code:    database.INTERNAL_ERROR,
 			Message: err.Error(),
 		}
 	}
+	if query.RowsAffected == 0 {
+		transaction.Rollback()
+		return nil, revisionConflictError(policy.Urn)
+	}
 
-	// Clear old statements
+	// Clear old statements and their references
+	if err := transaction.Where("policy_id like ?", policy.ID).Delete(StatementReference{}).Error; err != nil {
+		transaction.Rollback()
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
 	if err := transaction.Where("policy_id like ?", policy.ID).Delete(Statement{}).Error; err != nil {
 		transaction.Rollback()
 		return nil, &database.Error{
@@ -224,13 +279,16 @@ func (p PostgresRepo) UpdatePolicy(policy api.Policy, name string, path string,
 	}
 
 	// Create new statements
-	for _, s := range statements {
+	for i, s := range statements {
 		statementDB := &Statement{
-			ID:        uuid.NewV4().String(),
-			PolicyID:  policy.ID,
-			Effect:    s.Effect,
-			Actions:   stringArrayToString(s.Actions),
-			Resources: stringArrayToString(s.Resources),
+			ID:         uuid.NewV4().String(),
+			PolicyID:   policy.ID,
+			Sid:        s.Sid,
+			Position:   i,
+			Effect:     s.Effect,
+			Actions:    stringArrayToString(s.Actions),
+			Resources:  stringArrayToString(s.Resources),
+			Conditions: stringArrayToString(s.Conditions),
 		}
 		if err := transaction.Create(statementDB).Error; err != nil {
 			transaction.Rollback()
@@ -239,6 +297,10 @@ func (p PostgresRepo) UpdatePolicy(policy api.Policy, name string, path string,
 				Message: err.Error(),
 			}
 		}
+		if err := createStatementReferences(transaction.DB, statementDB); err != nil {
+			transaction.Rollback()
+			return nil, err
+		}
 	}
 
 	transaction.Commit()
@@ -246,13 +308,44 @@ func (p PostgresRepo) UpdatePolicy(policy api.Policy, name string, path string,
 	// Create API policy
 	policyApi := dbPolicyToAPIPolicy(&policyDB)
 	policyApi.Statements = &statements
+	policyApi.Tags = policy.Tags
 
 	return policyApi, nil
 }
 
-func (p PostgresRepo) RemovePolicy(id string) error {
+func (p PostgresRepo) RemovePolicy(policy api.Policy) error {
+	id := policy.ID
 
-	transaction := p.Dbmap.Begin()
+	transaction := p.begin()
+	// Move policy with its relationships and tags to the trash
+	document := trashDocument{Policy: &Policy{}}
+	if err := findTrashRows(transaction.DB, document.Policy, "id", id); err != nil {
+		transaction.Rollback()
+		return err
+	}
+	if err := findTrashRows(transaction.DB, &document.Statements, "policy_id", id); err != nil {
+		transaction.Rollback()
+		return err
+	}
+	if err := findTrashRows(transaction.DB, &document.GroupPolicyRelations, "policy_id", id); err != nil {
+		transaction.Rollback()
+		return err
+	}
+	if err := findTrashRows(transaction.DB, &document.Tags, "resource_id", id); err != nil {
+		transaction.Rollback()
+		return err
+	}
+	err := moveToTrash(transaction.DB, DeletedResource{
+		ID:   id,
+		Type: api.RESOURCE_POLICY,
+		Org:  document.Policy.Org,
+		Name: document.Policy.Name,
+		Urn:  document.Policy.Urn,
+	}, document)
+	if err != nil {
+		transaction.Rollback()
+		return err
+	}
 
 	// Delete policy relations (group)
 	transaction.Where("policy_id like ?", id).Delete(&GroupPolicyRelation{})
@@ -263,7 +356,15 @@ func (p PostgresRepo) RemovePolicy(id string) error {
 			Message: err.Error(),
 		}
 	}
-	// Delete policy statements
+	// Delete policy statements with their references
+	transaction.Where("policy_id like ?", id).Delete(&StatementReference{})
+	if err := transaction.Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
 	transaction.Where("policy_id like ?", id).Delete(&Statement{})
 	if err := transaction.Error; err != nil {
 		transaction.Rollback()
@@ -272,8 +373,8 @@ func (p PostgresRepo) RemovePolicy(id string) error {
 			Message: err.Error(),
 		}
 	}
-	//  Delete policy
-	transaction.Where("id like ?", id).Delete(&Policy{})
+	// Delete policy tags
+	transaction.Where("resource_id like ?", id).Delete(&Tag{})
 	if err := transaction.Error; err != nil {
 		transaction.Rollback()
 		return &database.Error{
@@ -281,18 +382,38 @@ func (p PostgresRepo) RemovePolicy(id string) error {
 			Message: err.Error(),
 		}
 	}
+	//  Delete policy if it hasn't changed since it was retrieved
+	query := transaction.Where("id like ? AND revision = ?", id, policy.Revision).Delete(&Policy{})
+	if err := query.Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	if query.RowsAffected == 0 {
+		transaction.Rollback()
+		return revisionConflictError(policy.Urn)
+	}
 
 	transaction.Commit()
 	return nil
 }
 
-func (p PostgresRepo) GetAttachedGroups(policyID string) ([]api.Group, error) {
+func (p PostgresRepo) GetAttachedGroups(policyID string, filter *api.Filter) ([]api.Group, int, error) {
 	relations := []GroupPolicyRelation{}
-	query := p.Dbmap.Where("policy_id like ?", policyID).Find(&relations)
+	query := p.Dbmap.Where("policy_id like ?", policyID)
+
+	// Count relations and retrieve the requested page
+	query, total, err := paginate(query, &GroupPolicyRelation{}, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	var groups []api.Group
 	// Error Handling
-	if err := query.Error; err != nil {
-		return nil, &database.Error{
+	if err := query.Order("group_id").Find(&relations).Error; err != nil {
+		return nil, 0, &database.Error{
 			Code:    database.INTERNAL_ERROR,
 			Message: err.Error(),
 		}
@@ -305,7 +426,7 @@ func (p PostgresRepo) GetAttachedGroups(policyID string) ([]api.Group, error) {
 			group, err := p.GetGroupById(r.GroupID)
 			// Error handling
 			if err != nil {
-				return nil, &database.Error{
+				return nil, 0, &database.Error{
 					Code:    database.INTERNAL_ERROR,
 					Message: err.Error(),
 				}
@@ -315,11 +436,78 @@ func (p PostgresRepo) GetAttachedGroups(policyID string) ([]api.Group, error) {
 		}
 	}
 
-	return groups, nil
+	return groups, total, nil
+}
+
+func (p PostgresRepo) GetPoliciesReferencingUrn(urn string) ([]api.Policy, error) {
+	policies := []Policy{}
+	query := p.Dbmap.Where("id in (SELECT policy_id FROM statement_references WHERE urn = ?)", urn).
+		Order("org, name").Find(&policies)
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Transform policies for API
+	ids := make([]string, len(policies))
+	for i, policy := range policies {
+		ids[i] = policy.ID
+	}
+	tags, err := p.getTagsByResourceIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	apiPolicies := make([]api.Policy, len(policies))
+	for i, pol := range policies {
+		policy := dbPolicyToAPIPolicy(&pol)
+
+		// Retrieve associated statements
+		statements := []Statement{}
+		if err := p.Dbmap.Where("policy_id like ?", policy.ID).Order("position").Find(&statements).Error; err != nil {
+			return nil, &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: err.Error(),
+			}
+		}
+
+		policy.Statements = dbStatementsToAPIStatements(statements)
+		policy.Tags = tags[policy.ID]
+		apiPolicies[i] = *policy
+	}
+
+	return apiPolicies, nil
 }
 
 // PRIVATE HELPER METHODS
 
+// Index the user, group and policy URNs in the statement resources. Prefixes ending in * aren't indexed
+func createStatementReferences(transaction *gorm.DB, statement *Statement) error {
+	indexed := map[string]bool{}
+	for _, resource := range strings.Split(statement.Resources, ";") {
+		if !strings.HasPrefix(resource, REFERENCED_URN_PREFIX) || strings.HasSuffix(resource, "*") || indexed[resource] {
+			continue
+		}
+		indexed[resource] = true
+		reference := &StatementReference{
+			StatementID: statement.ID,
+			Urn:         resource,
+			PolicyID:    statement.PolicyID,
+		}
+		if err := transaction.Create(reference).Error; err != nil {
+			return &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: err.Error(),
+			}
+		}
+	}
+
+	return nil
+}
+
 // Transform a policy retrieved from db into a policy for API
 func dbPolicyToAPIPolicy(policydb *Policy) *api.Policy {
 	return &api.Policy{
@@ -329,6 +517,7 @@ func dbPolicyToAPIPolicy(policydb *Policy) *api.Policy {
 		CreateAt: time.Unix(0, policydb.CreateAt).UTC(),
 		Urn:      policydb.Urn,
 		Org:      policydb.Org,
+		Revision: policydb.Revision,
 	}
 }
 
@@ -337,10 +526,15 @@ func dbStatementsToAPIStatements(statements []Statement) *[]api.Statement {
 	statementsApi := make([]api.Statement, len(statements), cap(statements))
 	for i, s := range statements {
 		statementsApi[i] = api.Statement{
+			Sid:       s.Sid,
 			Actions:   strings.Split(s.Actions, ";"),
 			Effect:    s.Effect,
 			Resources: strings.Split(s.Resources, ";"),
 		}
+		// Statements without conditions store an empty string
+		if len(s.Conditions) > 0 {
+			statementsApi[i].Conditions = strings.Split(s.Conditions, ";")
+		}
 	}
 
 	return &statementsApi
@@ -371,15 +371,15 @@ func TestPostgresRepo_GetPoliciesFiltered(t *testing.T) {
 	testcases := map[string]struct {
 		policy     *Policy
 		statements []Statement
+		// Group the policy is attached to
+		attachedGroupID string
 		// Postgres Repo Args
-		org        string
-		pathPrefix string
+		filter *api.Filter
 		// Expected result
+		expectedTotal    int
 		expectedResponse []api.Policy
 	}{
 		"OkCase": {
-			org:        "org1",
-			pathPrefix: "/path/",
 			policy: &Policy{
 				ID:       "1234",
 				Name:     "test",
@@ -397,6 +397,8 @@ func TestPostgresRepo_GetPoliciesFiltered(t *testing.T) {
 					Resources: api.GetUrnPrefix("", api.RESOURCE_USER, "/path/"),
 				},
 			},
+			filter:        &api.Filter{Org: "org1", PathPrefix: "/path/"},
+			expectedTotal: 1,
 			expectedResponse: []api.Policy{
 				{
 					ID:       "1234",
@@ -419,9 +421,72 @@ func TestPostgresRepo_GetPoliciesFiltered(t *testing.T) {
 				},
 			},
 		},
+		"OkCaseFilterByName": {
+			policy: &Policy{
+				ID:       "1234",
+				Name:     "test",
+				Org:      "org1",
+				Path:     "/path/",
+				CreateAt: now.UnixNano(),
+				Urn:      api.CreateUrn("org1", api.RESOURCE_POLICY, "/path/", "test"),
+			},
+			statements: []Statement{
+				{
+					ID:        "0123",
+					Effect:    "allow",
+					PolicyID:  "1234",
+					Actions:   api.USER_ACTION_GET_USER,
+					Resources: api.GetUrnPrefix("", api.RESOURCE_USER, "/path/"),
+				},
+			},
+			filter:        &api.Filter{Name: "es"},
+			expectedTotal: 1,
+			expectedResponse: []api.Policy{
+				{
+					ID:       "1234",
+					Name:     "test",
+					Org:      "org1",
+					Path:     "/path/",
+					CreateAt: now,
+					Urn:      api.CreateUrn("org1", api.RESOURCE_POLICY, "/path/", "test"),
+					Statements: &[]api.Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								api.USER_ACTION_GET_USER,
+							},
+							Resources: []string{
+								api.GetUrnPrefix("", api.RESOURCE_USER, "/path/"),
+							},
+						},
+					},
+				},
+			},
+		},
+		"OkCaseOrphanFilterExcludesAttached": {
+			policy: &Policy{
+				ID:       "1234",
+				Name:     "test",
+				Org:      "org1",
+				Path:     "/path/",
+				CreateAt: now.UnixNano(),
+				Urn:      api.CreateUrn("org1", api.RESOURCE_POLICY, "/path/", "test"),
+			},
+			statements: []Statement{
+				{
+					ID:        "0123",
+					Effect:    "allow",
+					PolicyID:  "1234",
+					Actions:   api.USER_ACTION_GET_USER,
+					Resources: api.GetUrnPrefix("", api.RESOURCE_USER, "/path/"),
+				},
+			},
+			attachedGroupID:  "GroupID",
+			filter:           &api.Filter{Orphan: true},
+			expectedResponse: []api.Policy{},
+		},
 		"OKCaseNotFound": {
-			org:              "org1",
-			pathPrefix:       "test",
+			filter:           &api.Filter{Org: "org1", PathPrefix: "test"},
 			expectedResponse: []api.Policy{},
 		},
 	}
@@ -430,6 +495,7 @@ func TestPostgresRepo_GetPoliciesFiltered(t *testing.T) {
 		// Clean policy database
 		cleanPolicyTable()
 		cleanStatementTable()
+		cleanGroupPolicyRelationTable()
 
 		// Insert previous data
 		if test.policy != nil {
@@ -438,12 +504,23 @@ func TestPostgresRepo_GetPoliciesFiltered(t *testing.T) {
 				t.Errorf("Test %v failed. Error inserting policy/statements: %v", n, err)
 			}
 		}
+		if test.attachedGroupID != "" {
+			if err := insertGroupPolicyRelation(test.attachedGroupID, test.policy.ID); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous group policy relations: %v", n, err)
+				continue
+			}
+		}
 		// Call to repository to get a policy
-		receivedPolicy, err := repoDB.GetPoliciesFiltered(test.org, test.pathPrefix)
+		receivedPolicy, total, err := repoDB.GetPoliciesFiltered(test.filter)
 		if err != nil {
 			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
 			continue
 		}
+		// Check total
+		if total != test.expectedTotal {
+			t.Errorf("Test %v failed. Received different total (wanted:%v / received:%v)", n, test.expectedTotal, total)
+			continue
+		}
 		// Check response
 		if diff := pretty.Compare(receivedPolicy, test.expectedResponse); diff != "" {
 			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
@@ -535,6 +612,93 @@ func TestPostgresRepo_UpdatePolicy(t *testing.T) {
 						},
 					},
 				},
+				Revision: 1,
+			},
+		},
+		"OkCaseStatementsWithSid": {
+			previousPolicy: &api.Policy{
+				ID:       "test1",
+				Name:     "test",
+				Org:      "123",
+				Path:     "/path/",
+				CreateAt: now,
+				Urn:      api.CreateUrn("123", api.RESOURCE_POLICY, "/path/", "test"),
+				Statements: &[]api.Statement{
+					{
+						Sid:    "first",
+						Effect: "allow",
+						Actions: []string{
+							api.USER_ACTION_GET_USER,
+						},
+						Resources: []string{
+							api.GetUrnPrefix("", api.RESOURCE_USER, "/path/"),
+						},
+					},
+				},
+			},
+			policy: api.Policy{
+				ID:       "test1",
+				Name:     "test",
+				Org:      "123",
+				Path:     "/path/",
+				CreateAt: now,
+				Urn:      api.CreateUrn("123", api.RESOURCE_POLICY, "/path/", "test"),
+			},
+			name: "test",
+			path: "/path/",
+			urn:  api.CreateUrn("123", api.RESOURCE_POLICY, "/path/", "test"),
+			statements: []api.Statement{
+				{
+					Sid:    "second",
+					Effect: "deny",
+					Actions: []string{
+						api.USER_ACTION_GET_USER,
+					},
+					Resources: []string{
+						api.GetUrnPrefix("", api.RESOURCE_USER, "/path/admin/"),
+					},
+				},
+				{
+					Sid:    "first",
+					Effect: "allow",
+					Actions: []string{
+						api.USER_ACTION_GET_USER,
+					},
+					Resources: []string{
+						api.GetUrnPrefix("", api.RESOURCE_USER, "/path/"),
+					},
+				},
+			},
+			expectedResponse: &api.Policy{
+				ID:       "test1",
+				Name:     "test",
+				Org:      "123",
+				Path:     "/path/",
+				CreateAt: now,
+				Urn:      api.CreateUrn("123", api.RESOURCE_POLICY, "/path/", "test"),
+				Statements: &[]api.Statement{
+					{
+						Sid:    "second",
+						Effect: "deny",
+						Actions: []string{
+							api.USER_ACTION_GET_USER,
+						},
+						Resources: []string{
+							api.GetUrnPrefix("", api.RESOURCE_USER, "/path/admin/"),
+						},
+					},
+					{
+						Sid:    "first",
+						Effect: "allow",
+						Actions: []string{
+							api.USER_ACTION_GET_USER,
+						},
+						Resources: []string{
+							api.GetUrnPrefix("", api.RESOURCE_USER, "/path/"),
+						},
+					},
+				},
+				Revision: 1,
 			},
 		},
 	}
@@ -562,6 +726,16 @@ func TestPostgresRepo_UpdatePolicy(t *testing.T) {
 			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
 			continue
 		}
+		// Check stored statements keep their sids and order
+		storedPolicy, err := repoDB.GetPolicyById(test.policy.ID)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error retrieving policy: %v", n, err)
+			continue
+		}
+		if diff := pretty.Compare(storedPolicy.Statements, test.expectedResponse.Statements); diff != "" {
+			t.Errorf("Test %v failed. Received different stored statements (received/wanted) %v", n, diff)
+			continue
+		}
 	}
 }
 
@@ -610,6 +784,7 @@ func TestPostgresRepo_RemovePolicy(t *testing.T) {
 		cleanStatementTable()
 		cleanGroupTable()
 		cleanGroupPolicyRelationTable()
+		cleanDeletedResourceTable()
 
 		// Call to repository to add a policy
 		if test.previousPolicy != nil {
@@ -632,7 +807,7 @@ func TestPostgresRepo_RemovePolicy(t *testing.T) {
 				continue
 			}
 		}
-		err := repoDB.RemovePolicy(test.id)
+		err := repoDB.RemovePolicy(api.Policy{ID: test.id})
 		if err != nil {
 			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
 			continue
@@ -672,6 +847,17 @@ func TestPostgresRepo_RemovePolicy(t *testing.T) {
 			t.Errorf("Test %v failed. Received different relations number: %v", n, groupPolicyRelationNumber)
 			continue
 		}
+
+		// Check policy was moved to the trash
+		deletedResources, err := getDeletedResourcesCountFiltered(test.id, api.RESOURCE_POLICY)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting deleted resources: %v", n, err)
+			continue
+		}
+		if deletedResources != 1 {
+			t.Errorf("Test %v failed. Received different deleted resources number: %v", n, deletedResources)
+			continue
+		}
 	}
 }
 
@@ -750,7 +936,7 @@ func TestPostgresRepo_GetAttachedGroups(t *testing.T) {
 			}
 		}
 
-		groups, err := repoDB.GetAttachedGroups(test.previousPolicy.ID)
+		groups, total, err := repoDB.GetAttachedGroups(test.previousPolicy.ID, &api.Filter{})
 		if err != nil {
 			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
 			continue
@@ -760,6 +946,126 @@ func TestPostgresRepo_GetAttachedGroups(t *testing.T) {
 			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
 			continue
 		}
+		if total != len(test.expectedResponse) {
+			t.Errorf("Test %v failed. Received different total (wanted:%v / received:%v)", n, len(test.expectedResponse), total)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_GetPoliciesReferencingUrn(t *testing.T) {
+	now := time.Now().UTC()
+	groupUrn := api.CreateUrn("123", api.RESOURCE_GROUP, "/path/", "group")
+	referencingPolicy := api.Policy{
+		ID:       "test1",
+		Name:     "test",
+		Org:      "123",
+		Path:     "/path/",
+		CreateAt: now,
+		Urn:      api.CreateUrn("123", api.RESOURCE_POLICY, "/path/", "test"),
+		Statements: &[]api.Statement{
+			{
+				Sid:    "members",
+				Effect: "allow",
+				Actions: []string{
+					api.GROUP_ACTION_ADD_MEMBER,
+				},
+				Resources: []string{
+					groupUrn,
+					api.GetUrnPrefix("123", api.RESOURCE_GROUP, "/path/"),
+				},
+			},
+		},
+	}
+	prefixPolicy := api.Policy{
+		ID:       "test2",
+		Name:     "prefix",
+		Org:      "123",
+		Path:     "/path/",
+		CreateAt: now,
+		Urn:      api.CreateUrn("123", api.RESOURCE_POLICY, "/path/", "prefix"),
+		Statements: &[]api.Statement{
+			{
+				Sid:    "groups",
+				Effect: "allow",
+				Actions: []string{
+					api.GROUP_ACTION_ADD_MEMBER,
+				},
+				Resources: []string{
+					api.GetUrnPrefix("123", api.RESOURCE_GROUP, "/path/"),
+				},
+			},
+		},
+	}
+	testcases := map[string]struct {
+		// Statements of the referencing policy after an update, nil to keep it unchanged
+		updatedStatements []api.Statement
+		removePolicy      bool
+		// Expected result
+		expectedResponse []api.Policy
+	}{
+		"OkCase": {
+			expectedResponse: []api.Policy{referencingPolicy},
+		},
+		"OkCaseUpdatedPolicy": {
+			updatedStatements: *prefixPolicy.Statements,
+			expectedResponse:  []api.Policy{},
+		},
+		"OkCaseRemovedPolicy": {
+			removePolicy:     true,
+			expectedResponse: []api.Policy{},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean database
+		cleanPolicyTable()
+		cleanStatementTable()
+		cleanDeletedResourceTable()
+
+		// Call to repository to add policies
+		if _, err := repoDB.AddPolicy(referencingPolicy); err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if _, err := repoDB.AddPolicy(prefixPolicy); err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if test.updatedStatements != nil {
+			_, err := repoDB.UpdatePolicy(referencingPolicy, referencingPolicy.Name, referencingPolicy.Path,
+				referencingPolicy.Urn, test.updatedStatements)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+		}
+		if test.removePolicy {
+			if err := repoDB.RemovePolicy(referencingPolicy); err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+		}
+
+		policies, err := repoDB.GetPoliciesReferencingUrn(groupUrn)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if diff := pretty.Compare(policies, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+		// Check that prefixes aren't indexed
+		count, err := getStatementReferencesCountFiltered(prefixPolicy.ID, "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting references: %v", n, err)
+			continue
+		}
+		if count != 0 {
+			t.Errorf("Test %v failed. Received different references number for prefix policy: %v", n, count)
+			continue
+		}
 	}
 }
 
@@ -830,6 +1136,7 @@ func Test_dbStatementsToAPIStatements(t *testing.T) {
 			dbStatements: []Statement{
 				{
 					ID:        "0123",
+					Sid:       "first",
 					Effect:    "allow",
 					PolicyID:  "1234",
 					Actions:   api.USER_ACTION_GET_USER,
@@ -845,6 +1152,7 @@ func Test_dbStatementsToAPIStatements(t *testing.T) {
 			},
 			apiStatements: &[]api.Statement{
 				{
+					Sid:    "first",
 					Effect: "allow",
 					Actions: []string{
 						api.USER_ACTION_GET_USER,
@@ -4,12 +4,15 @@ import (
 	"time"
 
 	"strconv"
+	"strings"
 
 	"errors"
 	"fmt"
 
 	"github.com/jinzhu/gorm"
 	_ "github.com/lib/pq"
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
 )
 
 type PostgresRepo struct {
@@ -47,7 +50,36 @@ func InitDb(datasourcename string, idleConns string, maxOpenConns string, connTT
 	}
 
 	// Create tables if not exist =
-	err = db.AutoMigrate(&User{}, &Group{}, &Policy{}, &Statement{}, &GroupUserRelation{}, &GroupPolicyRelation{}).Error
+	err = db.AutoMigrate(&User{}, &Group{}, &Policy{}, &Statement{}, &GroupUserRelation{}, &GroupPolicyRelation{},
+		&GroupOwnerRelation{}, &AccessRequest{}, &Namespace{}, &Action{}, &ResourceType{}, &Tag{}, &DeletedResource{}, &Organization{}, &StatementReference{},
+		&AuditEvent{}, &Webhook{}, &WebhookDelivery{}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Statements stored before sids were introduced are identified by their id
+	err = db.Exec("UPDATE statements SET sid = id WHERE sid = ''").Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Organizations used by groups and policies stored before organizations were introduced are created
+	now := time.Now().UTC().UnixNano()
+	err = db.Exec("INSERT INTO organizations (id, name, description, urn, create_at, update_at) "+
+		"SELECT md5(o.org)::uuid::text, o.org, '', ? || o.org, ?, ? "+
+		"FROM (SELECT org FROM groups UNION SELECT org FROM policies) o "+
+		"WHERE o.org NOT IN (SELECT name FROM organizations)",
+		api.CreateUrn("", api.RESOURCE_ORGANIZATION, "/", ""), now, now).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Statements stored before the reference index was introduced are indexed
+	err = db.Exec("INSERT INTO statement_references (statement_id, urn, policy_id) "+
+		"SELECT DISTINCT s.id, r.urn, s.policy_id FROM statements s, unnest(string_to_array(s.resources, ';')) r(urn) "+
+		"WHERE r.urn LIKE ? AND r.urn NOT LIKE '%*' "+
+		"AND NOT EXISTS (SELECT 1 FROM statement_references sr WHERE sr.statement_id = s.id)",
+		REFERENCED_URN_PREFIX+"%").Error
 	if err != nil {
 		return nil, err
 	}
@@ -59,13 +91,19 @@ func InitDb(datasourcename string, idleConns string, maxOpenConns string, connTT
 	return db, nil
 }
 
-// User table
+// User table. Attributes is the JSON object with the user profile attributes. Revision is increased
+// by every update, so updates conditioned on it fail when the user changed after it was retrieved
 type User struct {
-	ID         string `gorm:"primary_key"`
-	ExternalID string `gorm:"not null;unique"`
-	Path       string `gorm:"not null"`
-	CreateAt   int64  `gorm:"not null"`
-	Urn        string `gorm:"not null;unique"`
+	ID          string `gorm:"primary_key"`
+	ExternalID  string `gorm:"not null;unique"`
+	Path        string `gorm:"not null"`
+	CreateAt    int64  `gorm:"not null"`
+	Urn         string `gorm:"not null;unique"`
+	DisplayName string `gorm:"not null;default:''"`
+	Email       string `gorm:"not null;default:''"`
+	Attributes  string `gorm:"not null;default:''"`
+	Status      string `gorm:"not null;default:'active'"`
+	Revision    int64  `gorm:"not null;default:0"`
 }
 
 // User's table name
@@ -73,14 +111,19 @@ func (User) TableName() string {
 	return "users"
 }
 
-// Group table
+// Group table. Rule columns store the membership rule, empty in groups without rule.
+// Revision is increased by every update like in users
 type Group struct {
-	ID       string `gorm:"primary_key"`
-	Name     string `gorm:"not null"`
-	Path     string `gorm:"not null"`
-	Org      string `gorm:"not null"`
-	CreateAt int64  `gorm:"not null"`
-	Urn      string `gorm:"not null;unique"`
+	ID                 string `gorm:"primary_key"`
+	Name               string `gorm:"not null"`
+	Path               string `gorm:"not null"`
+	Org                string `gorm:"not null"`
+	CreateAt           int64  `gorm:"not null"`
+	Urn                string `gorm:"not null;unique"`
+	RulePathPrefix     string `gorm:"not null;default:''"`
+	RuleAttributeKey   string `gorm:"not null;default:''"`
+	RuleAttributeValue string `gorm:"not null;default:''"`
+	Revision           int64  `gorm:"not null;default:0"`
 }
 
 // Group's table name
@@ -88,7 +131,7 @@ func (Group) TableName() string {
 	return "groups"
 }
 
-// Policy table
+// Policy table. Revision is increased by every update like in users
 type Policy struct {
 	ID       string `gorm:"primary_key"`
 	Name     string `gorm:"not null"`
@@ -96,6 +139,7 @@ type Policy struct {
 	Org      string `gorm:"not null"`
 	CreateAt int64  `gorm:"not null"`
 	Urn      string `gorm:"not null;unique"`
+	Revision int64  `gorm:"not null;default:0"`
 }
 
 // Policy's table name
@@ -103,13 +147,17 @@ func (Policy) TableName() string {
 	return "policies"
 }
 
-// Statement table
+// Statement table. Sid is the statement identifier exposed by the API, unique inside the policy,
+// and Position keeps the order of the statements in the policy.
 type Statement struct {
-	ID        string `gorm:"primary_key"`
-	PolicyID  string `gorm:"not null"`
-	Effect    string `gorm:"not null"`
-	Actions   string `gorm:"not null"`
-	Resources string `gorm:"not null"`
+	ID         string `gorm:"primary_key"`
+	PolicyID   string `gorm:"not null"`
+	Sid        string `gorm:"not null;default:''"`
+	Position   int    `gorm:"not null;default:0"`
+	Effect     string `gorm:"not null"`
+	Actions    string `gorm:"not null"`
+	Resources  string `gorm:"not null"`
+	Conditions string
 }
 
 // Statement's table name
@@ -117,10 +165,24 @@ func (Statement) TableName() string {
 	return "statements"
 }
 
-// Group-Users Relationship
+// Statement-Urn Relationship. Index of the user, group and policy URNs in the resources of each statement
+type StatementReference struct {
+	StatementID string `gorm:"primary_key"`
+	Urn         string `gorm:"primary_key;index"`
+	PolicyID    string `gorm:"not null;index"`
+}
+
+// StatementReference's table name
+func (StatementReference) TableName() string {
+	return "statement_references"
+}
+
+// Group-Users Relationship. ExpiresAt stores the membership expiration as
+// unix nano timestamp, where 0 means that membership never expires.
 type GroupUserRelation struct {
-	UserID  string `gorm:"primary_key"`
-	GroupID string `gorm:"primary_key"`
+	UserID    string `gorm:"primary_key"`
+	GroupID   string `gorm:"primary_key"`
+	ExpiresAt int64  `gorm:"not null;default:0"`
 }
 
 // GroupUserRelation's table name
@@ -128,13 +190,256 @@ func (GroupUserRelation) TableName() string {
 	return "group_user_relations"
 }
 
-// Group Policy table
+// Group-Owners Relationship. Owners can manage members of the group
+type GroupOwnerRelation struct {
+	UserID  string `gorm:"primary_key"`
+	GroupID string `gorm:"primary_key"`
+}
+
+// GroupOwnerRelation's table name
+func (GroupOwnerRelation) TableName() string {
+	return "group_owner_relations"
+}
+
+// Group Policy table. NotBefore and NotAfter store the activation window as
+// unix nano timestamps, where 0 means no bound.
 type GroupPolicyRelation struct {
-	GroupID  string `gorm:"primary_key"`
-	PolicyID string `gorm:"primary_key"`
+	GroupID   string `gorm:"primary_key"`
+	PolicyID  string `gorm:"primary_key"`
+	NotBefore int64  `gorm:"not null;default:0"`
+	NotAfter  int64  `gorm:"not null;default:0"`
 }
 
 // GroupPolicyRelation's table name
 func (GroupPolicyRelation) TableName() string {
 	return "group_policy_relations"
 }
+
+// Access Request table
+type AccessRequest struct {
+	ID            string `gorm:"primary_key"`
+	Type          string `gorm:"not null"`
+	Requester     string `gorm:"not null"`
+	Org           string `gorm:"not null"`
+	GroupName     string `gorm:"not null"`
+	PolicyName    string
+	Justification string `gorm:"not null"`
+	Duration      string `gorm:"not null"`
+	Status        string `gorm:"not null"`
+	Reviewer      string
+	ReviewComment string
+	CreateAt      int64 `gorm:"not null"`
+	ReviewAt      int64
+	ExpiresAt     int64
+	GroupUrn      string `gorm:"not null"`
+}
+
+// AccessRequest's table name
+func (AccessRequest) TableName() string {
+	return "access_requests"
+}
+
+// Namespace table
+type Namespace struct {
+	Name        string `gorm:"primary_key"`
+	Description string
+	CreateAt    int64 `gorm:"not null"`
+}
+
+// Namespace's table name
+func (Namespace) TableName() string {
+	return "namespaces"
+}
+
+// Action table
+type Action struct {
+	ID                 string `gorm:"primary_key"`
+	Namespace          string `gorm:"not null;unique_index:idx_action_namespace_name"`
+	Name               string `gorm:"not null;unique_index:idx_action_namespace_name"`
+	Description        string
+	ResourceUrnPattern string
+	CreateAt           int64 `gorm:"not null"`
+}
+
+// Action's table name
+func (Action) TableName() string {
+	return "actions"
+}
+
+// Resource type table
+type ResourceType struct {
+	ID          string `gorm:"primary_key"`
+	Namespace   string `gorm:"not null;unique_index:idx_resource_type_namespace_name"`
+	Name        string `gorm:"not null;unique_index:idx_resource_type_namespace_name"`
+	Description string
+	UrnTemplate string `gorm:"not null"`
+	CreateAt    int64  `gorm:"not null"`
+}
+
+// Resource type's table name
+func (ResourceType) TableName() string {
+	return "resource_types"
+}
+
+// Tag table. ResourceID is the id of the tagged user, group or policy
+type Tag struct {
+	ResourceID string `gorm:"primary_key"`
+	Key        string `gorm:"primary_key"`
+	Value      string `gorm:"not null"`
+}
+
+// Tag's table name
+func (Tag) TableName() string {
+	return "tags"
+}
+
+// Deleted resource table. ID is the id of the deleted user, group or policy and Document
+// is the JSON trash document with its rows and relationships, used to restore them.
+type DeletedResource struct {
+	ID        string `gorm:"primary_key"`
+	Type      string `gorm:"not null"`
+	Org       string `gorm:"not null;default:''"`
+	Name      string `gorm:"not null"`
+	Urn       string `gorm:"not null"`
+	DeletedAt int64  `gorm:"not null"`
+	Document  string `gorm:"not null"`
+}
+
+// DeletedResource's table name
+func (DeletedResource) TableName() string {
+	return "deleted_resources"
+}
+
+// Organization table
+type Organization struct {
+	ID          string `gorm:"primary_key"`
+	Name        string `gorm:"not null;unique"`
+	Description string `gorm:"not null;default:''"`
+	Urn         string `gorm:"not null;unique"`
+	CreateAt    int64  `gorm:"not null"`
+	UpdateAt    int64  `gorm:"not null"`
+}
+
+// Organization's table name
+func (Organization) TableName() string {
+	return "organizations"
+}
+
+// Audit event table. Before and After are the JSON documents of the entity, empty when there isn't any
+type AuditEvent struct {
+	ID        string `gorm:"primary_key"`
+	RequestID string `gorm:"not null;default:''"`
+	Actor     string `gorm:"not null;index"`
+	Action    string `gorm:"not null;index"`
+	Urn       string `gorm:"not null;index"`
+	Before    string `gorm:"not null;default:''"`
+	After     string `gorm:"not null;default:''"`
+	CreateAt  int64  `gorm:"not null;index"`
+}
+
+// AuditEvent's table name
+func (AuditEvent) TableName() string {
+	return "audit_events"
+}
+
+// Webhook table. Events are stored separated by ;
+type Webhook struct {
+	ID       string `gorm:"primary_key"`
+	Url      string `gorm:"not null"`
+	Secret   string `gorm:"not null"`
+	Events   string `gorm:"not null"`
+	CreateAt int64  `gorm:"not null"`
+	UpdateAt int64  `gorm:"not null"`
+}
+
+// Webhook's table name
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// Webhook delivery table
+type WebhookDelivery struct {
+	ID            string `gorm:"primary_key"`
+	WebhookID     string `gorm:"not null;index"`
+	EventID       string `gorm:"not null"`
+	Action        string `gorm:"not null"`
+	Urn           string `gorm:"not null"`
+	Payload       string `gorm:"not null"`
+	Status        string `gorm:"not null;index"`
+	Attempts      int    `gorm:"not null"`
+	StatusCode    int    `gorm:"not null"`
+	LastError     string `gorm:"not null;default:''"`
+	CreateAt      int64  `gorm:"not null;index"`
+	UpdateAt      int64  `gorm:"not null"`
+	NextAttemptAt int64  `gorm:"not null;index"`
+}
+
+// WebhookDelivery's table name
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// PRIVATE HELPER METHODS
+
+// Count the rows matched by query and apply filter offset and limit to it.
+// Query isn't paged when limit is 0
+func paginate(query *gorm.DB, model interface{}, filter *api.Filter) (*gorm.DB, int, error) {
+	var total int
+	if err := query.Model(model).Count(&total).Error; err != nil {
+		return nil, 0, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	if filter.Limit > 0 {
+		query = query.Offset(filter.Offset).Limit(filter.Limit)
+	}
+
+	return query, total, nil
+}
+
+// Apply filters shared by users, groups and policies: path prefix, name substring,
+// creation date range and tags. nameColumn is the column matched by filter name
+func filterQuery(query *gorm.DB, filter *api.Filter, nameColumn string) *gorm.DB {
+	if len(filter.PathPrefix) > 0 {
+		query = query.Where("path like ?", filter.PathPrefix+"%")
+	}
+	if len(filter.Name) > 0 {
+		// Underscore is a wildcard in like expressions
+		query = query.Where(nameColumn+" like ?", "%"+strings.Replace(filter.Name, "_", `\_`, -1)+"%")
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("create_at > ?", filter.CreatedAfter.UnixNano())
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("create_at < ?", filter.CreatedBefore.UnixNano())
+	}
+
+	return filterByTags(query, filter.Tags)
+}
+
+// Sort query by filter sort field and order. Name is the default sort field when only the order
+// is specified, and defaultOrder is used when filter has neither of them
+func sortQuery(query *gorm.DB, filter *api.Filter, nameColumn string, defaultOrder string) *gorm.DB {
+	if filter.SortBy == "" && filter.Order == "" {
+		return query.Order(defaultOrder)
+	}
+	column := nameColumn
+	if filter.SortBy == api.SORT_BY_CREATE_AT {
+		column = "create_at"
+	}
+	if filter.Order == api.ORDER_DESC {
+		column += " desc"
+	}
+
+	// Break ties by id so pages are stable
+	return query.Order(column).Order("id")
+}
+
+// Error of an update conditioned on the revision of a resource that changed after it was retrieved
+func revisionConflictError(urn string) *database.Error {
+	return &database.Error{
+		Code:    database.REVISION_CONFLICT,
+		Message: fmt.Sprintf("Resource %v has been modified since it was retrieved", urn),
+	}
+}
@@ -100,6 +100,20 @@ func insertGroupUserRelation(userID string, groupID string) error {
 	return nil
 }
 
+func insertGroupUserRelationWithExpiration(userID string, groupID string, expiresAt int64) error {
+	err := repoDB.Dbmap.Exec("INSERT INTO public.group_user_relations (user_id, group_id, expires_at) VALUES (?, ?, ?)",
+		userID, groupID, expiresAt).Error
+
+	// Error handling
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
 func getUsersCountFiltered(id string, externalID string, path string, createAt int64, urn string, pathPrefix string) (int, error) {
 	query := repoDB.Dbmap.Table(User{}.TableName())
 	if id != "" {
@@ -203,6 +217,44 @@ func getGroupUserRelations(groupID string, userID string) (int, error) {
 	return number, nil
 }
 
+func insertGroupOwnerRelation(userID string, groupID string) error {
+	err := repoDB.Dbmap.Exec("INSERT INTO public.group_owner_relations (user_id, group_id) VALUES (?, ?)",
+		userID, groupID).Error
+
+	// Error handling
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func getGroupOwnerRelations(groupID string, userID string) (int, error) {
+	query := repoDB.Dbmap.Table(GroupOwnerRelation{}.TableName())
+	if groupID != "" {
+		query = query.Where("group_id = ?", groupID)
+	}
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var number int
+	if err := query.Count(&number).Error; err != nil {
+		return 0, err
+	}
+
+	return number, nil
+}
+
+func cleanGroupOwnerRelationTable() error {
+	if err := repoDB.Dbmap.Delete(&GroupOwnerRelation{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
 func cleanGroupTable() error {
 	if err := repoDB.Dbmap.Delete(&Group{}).Error; err != nil {
 		return err
@@ -227,12 +279,31 @@ func cleanPolicyTable() error {
 }
 
 func cleanStatementTable() error {
+	if err := repoDB.Dbmap.Delete(&StatementReference{}).Error; err != nil {
+		return err
+	}
 	if err := repoDB.Dbmap.Delete(&Statement{}).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
+func getStatementReferencesCountFiltered(policyID string, urn string) (int, error) {
+	query := repoDB.Dbmap.Table(StatementReference{}.TableName())
+	if policyID != "" {
+		query = query.Where("policy_id = ?", policyID)
+	}
+	if urn != "" {
+		query = query.Where("urn = ?", urn)
+	}
+	var number int
+	if err := query.Count(&number).Error; err != nil {
+		return 0, err
+	}
+
+	return number, nil
+}
+
 func insertPolicy(id string, name string, org string, path string, createAt int64, urn string, statements []Statement) error {
 	err := repoDB.Dbmap.Exec("INSERT INTO public.policies (id, name, org, path, create_at, urn) VALUES (?, ?, ?, ?, ?, ?)",
 		id, name, org, path, createAt, urn).Error
@@ -332,6 +403,20 @@ func insertGroupPolicyRelation(groupID string, policyID string) error {
 	return nil
 }
 
+func insertGroupPolicyRelationWithWindow(groupID string, policyID string, notBefore int64, notAfter int64) error {
+	err := repoDB.Dbmap.Exec("INSERT INTO public.group_policy_relations (group_id, policy_id, not_before, not_after) VALUES (?, ?, ?, ?)",
+		groupID, policyID, notBefore, notAfter).Error
+
+	// Error handling
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
 func getStatementsCountFiltered(id string, policyId string, effect string, actions string, resources string) (int, error) {
 	query := repoDB.Dbmap.Table(Statement{}.TableName())
 	if id != "" {
@@ -356,3 +441,375 @@ func getStatementsCountFiltered(id string, policyId string, effect string, actio
 
 	return number, nil
 }
+
+func insertAccessRequest(accessRequest AccessRequest) error {
+	err := repoDB.Dbmap.Create(&accessRequest).Error
+
+	// Error handling
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func getAccessRequestsCountFiltered(id string, status string, reviewer string) (int, error) {
+	query := repoDB.Dbmap.Table(AccessRequest{}.TableName())
+	if id != "" {
+		query = query.Where("id = ?", id)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if reviewer != "" {
+		query = query.Where("reviewer = ?", reviewer)
+	}
+	var number int
+	if err := query.Count(&number).Error; err != nil {
+		return 0, err
+	}
+
+	return number, nil
+}
+
+func cleanAccessRequestTable() error {
+	if err := repoDB.Dbmap.Delete(&AccessRequest{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func insertNamespace(namespace Namespace) error {
+	err := repoDB.Dbmap.Create(&namespace).Error
+
+	// Error handling
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func getNamespacesCountFiltered(name string) (int, error) {
+	query := repoDB.Dbmap.Table(Namespace{}.TableName())
+	if name != "" {
+		query = query.Where("name = ?", name)
+	}
+	var number int
+	if err := query.Count(&number).Error; err != nil {
+		return 0, err
+	}
+
+	return number, nil
+}
+
+func cleanNamespaceTable() error {
+	if err := repoDB.Dbmap.Delete(&Namespace{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func insertAction(action Action) error {
+	err := repoDB.Dbmap.Create(&action).Error
+
+	// Error handling
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func getActionsCountFiltered(id string, namespace string) (int, error) {
+	query := repoDB.Dbmap.Table(Action{}.TableName())
+	if id != "" {
+		query = query.Where("id = ?", id)
+	}
+	if namespace != "" {
+		query = query.Where("namespace = ?", namespace)
+	}
+	var number int
+	if err := query.Count(&number).Error; err != nil {
+		return 0, err
+	}
+
+	return number, nil
+}
+
+func cleanActionTable() error {
+	if err := repoDB.Dbmap.Delete(&Action{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func insertResourceType(resourceType ResourceType) error {
+	err := repoDB.Dbmap.Create(&resourceType).Error
+
+	// Error handling
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func getResourceTypesCountFiltered(id string, namespace string) (int, error) {
+	query := repoDB.Dbmap.Table(ResourceType{}.TableName())
+	if id != "" {
+		query = query.Where("id = ?", id)
+	}
+	if namespace != "" {
+		query = query.Where("namespace = ?", namespace)
+	}
+	var number int
+	if err := query.Count(&number).Error; err != nil {
+		return 0, err
+	}
+
+	return number, nil
+}
+
+func cleanResourceTypeTable() error {
+	if err := repoDB.Dbmap.Delete(&ResourceType{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func insertTag(tag Tag) error {
+	err := repoDB.Dbmap.Create(&tag).Error
+
+	// Error handling
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func getTagsCountFiltered(resourceID string, key string, value string) (int, error) {
+	query := repoDB.Dbmap.Table(Tag{}.TableName())
+	if resourceID != "" {
+		query = query.Where("resource_id = ?", resourceID)
+	}
+	if key != "" {
+		query = query.Where("key = ?", key)
+	}
+	if value != "" {
+		query = query.Where("value = ?", value)
+	}
+	var number int
+	if err := query.Count(&number).Error; err != nil {
+		return 0, err
+	}
+
+	return number, nil
+}
+
+func cleanTagTable() error {
+	if err := repoDB.Dbmap.Delete(&Tag{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func insertDeletedResource(deletedResource DeletedResource) error {
+	err := repoDB.Dbmap.Create(&deletedResource).Error
+
+	// Error handling
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func getDeletedResourcesCountFiltered(id string, resourceType string) (int, error) {
+	query := repoDB.Dbmap.Table(DeletedResource{}.TableName())
+	if id != "" {
+		query = query.Where("id = ?", id)
+	}
+	if resourceType != "" {
+		query = query.Where("type = ?", resourceType)
+	}
+	var number int
+	if err := query.Count(&number).Error; err != nil {
+		return 0, err
+	}
+
+	return number, nil
+}
+
+func cleanDeletedResourceTable() error {
+	if err := repoDB.Dbmap.Delete(&DeletedResource{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func insertOrganization(organization Organization) error {
+	err := repoDB.Dbmap.Create(&organization).Error
+
+	// Error handling
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func getOrganizationsCountFiltered(id string, name string) (int, error) {
+	query := repoDB.Dbmap.Table(Organization{}.TableName())
+	if id != "" {
+		query = query.Where("id = ?", id)
+	}
+	if name != "" {
+		query = query.Where("name = ?", name)
+	}
+	var number int
+	if err := query.Count(&number).Error; err != nil {
+		return 0, err
+	}
+
+	return number, nil
+}
+
+func cleanOrganizationTable() error {
+	if err := repoDB.Dbmap.Delete(&Organization{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func insertAuditEvent(event AuditEvent) error {
+	err := repoDB.Dbmap.Create(&event).Error
+
+	// Error handling
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func getAuditEventsCountFiltered(id string, actor string, action string, urn string) (int, error) {
+	query := repoDB.Dbmap.Table(AuditEvent{}.TableName())
+	if id != "" {
+		query = query.Where("id = ?", id)
+	}
+	if actor != "" {
+		query = query.Where("actor = ?", actor)
+	}
+	if action != "" {
+		query = query.Where("action = ?", action)
+	}
+	if urn != "" {
+		query = query.Where("urn = ?", urn)
+	}
+	var number int
+	if err := query.Count(&number).Error; err != nil {
+		return 0, err
+	}
+
+	return number, nil
+}
+
+func cleanAuditEventTable() error {
+	if err := repoDB.Dbmap.Delete(&AuditEvent{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func insertWebhook(webhook Webhook) error {
+	err := repoDB.Dbmap.Create(&webhook).Error
+
+	// Error handling
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func getWebhooksCountFiltered(id string, url string) (int, error) {
+	query := repoDB.Dbmap.Table(Webhook{}.TableName())
+	if id != "" {
+		query = query.Where("id = ?", id)
+	}
+	if url != "" {
+		query = query.Where("url = ?", url)
+	}
+	var number int
+	if err := query.Count(&number).Error; err != nil {
+		return 0, err
+	}
+
+	return number, nil
+}
+
+func cleanWebhookTable() error {
+	if err := repoDB.Dbmap.Delete(&Webhook{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func insertWebhookDelivery(delivery WebhookDelivery) error {
+	err := repoDB.Dbmap.Create(&delivery).Error
+
+	// Error handling
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+func getWebhookDeliveriesCountFiltered(id string, webhookID string, status string) (int, error) {
+	query := repoDB.Dbmap.Table(WebhookDelivery{}.TableName())
+	if id != "" {
+		query = query.Where("id = ?", id)
+	}
+	if webhookID != "" {
+		query = query.Where("webhook_id = ?", webhookID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var number int
+	if err := query.Count(&number).Error; err != nil {
+		return 0, err
+	}
+
+	return number, nil
+}
+
+func cleanWebhookDeliveryTable() error {
+	if err := repoDB.Dbmap.Delete(&WebhookDelivery{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package postgresql
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// RESOURCE TYPE REGISTRY REPOSITORY IMPLEMENTATION
+
+func (a PostgresRepo) AddResourceType(resourceType api.ResourceType) (*api.ResourceType, error) {
+
+	// Create resource type model
+	resourceTypeDB := &ResourceType{
+		ID:          resourceType.ID,
+		Namespace:   resourceType.Namespace,
+		Name:        resourceType.Name,
+		Description: resourceType.Description,
+		UrnTemplate: resourceType.UrnTemplate,
+		CreateAt:    resourceType.CreateAt.UTC().UnixNano(),
+	}
+
+	// Store resource type
+	err := a.Dbmap.Create(resourceTypeDB).Error
+
+	// Error handling
+	if err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbResourceTypeToAPIResourceType(resourceTypeDB), nil
+}
+
+func (a PostgresRepo) GetResourceTypeByName(namespace string, name string) (*api.ResourceType, error) {
+	resourceType := &ResourceType{}
+	query := a.Dbmap.Where("namespace like ? AND name like ?", namespace, name).First(resourceType)
+
+	// Check if resource type exists
+	if query.RecordNotFound() {
+		return nil, &database.Error{
+			Code:    database.RESOURCE_TYPE_NOT_FOUND,
+			Message: fmt.Sprintf("Resource type %v:%v not found", namespace, name),
+		}
+	}
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbResourceTypeToAPIResourceType(resourceType), nil
+}
+
+func (a PostgresRepo) GetResourceTypesFiltered(namespace string) ([]api.ResourceType, error) {
+	resourceTypes := []ResourceType{}
+	query := a.Dbmap
+	if len(namespace) > 0 {
+		query = query.Where("namespace like ?", namespace)
+	}
+
+	// Error handling
+	if err := query.Order("namespace, name").Find(&resourceTypes).Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Transform resource types for API
+	if resourceTypes != nil {
+		apiResourceTypes := make([]api.ResourceType, len(resourceTypes), cap(resourceTypes))
+		for i, rt := range resourceTypes {
+			apiResourceTypes[i] = *dbResourceTypeToAPIResourceType(&rt)
+		}
+		return apiResourceTypes, nil
+	}
+
+	// No data to return
+	return nil, nil
+}
+
+func (a PostgresRepo) RemoveResourceType(id string) error {
+	// Delete resource type
+	err := a.Dbmap.Where("id like ?", id).Delete(&ResourceType{}).Error
+
+	// Error handling
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Transform a resource type retrieved from db into a resource type for API
+func dbResourceTypeToAPIResourceType(resourceTypeDB *ResourceType) *api.ResourceType {
+	return &api.ResourceType{
+		ID:          resourceTypeDB.ID,
+		Namespace:   resourceTypeDB.Namespace,
+		Name:        resourceTypeDB.Name,
+		Description: resourceTypeDB.Description,
+		UrnTemplate: resourceTypeDB.UrnTemplate,
+		CreateAt:    time.Unix(0, resourceTypeDB.CreateAt).UTC(),
+	}
+}
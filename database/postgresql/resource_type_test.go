@@ -0,0 +1,181 @@
+package postgresql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestPostgresRepo_AddResourceType(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousResourceType *ResourceType
+		// Postgres Repo Args
+		resourceTypeToCreate *api.ResourceType
+		// Expected result
+		expectedResponse *api.ResourceType
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			resourceTypeToCreate: &api.ResourceType{
+				ID:          "ResourceTypeID",
+				Namespace:   "billing",
+				Name:        "invoice",
+				Description: "Billing invoice",
+				UrnTemplate: "urn:billing:{org}:invoice{path}{id}",
+				CreateAt:    now,
+			},
+			expectedResponse: &api.ResourceType{
+				ID:          "ResourceTypeID",
+				Namespace:   "billing",
+				Name:        "invoice",
+				Description: "Billing invoice",
+				UrnTemplate: "urn:billing:{org}:invoice{path}{id}",
+				CreateAt:    now,
+			},
+		},
+		"ErrorCaseResourceTypeAlreadyExist": {
+			previousResourceType: &ResourceType{
+				ID:        "ResourceTypeID1",
+				Namespace: "billing",
+				Name:      "invoice",
+				CreateAt:  now.UnixNano(),
+			},
+			resourceTypeToCreate: &api.ResourceType{
+				ID:        "ResourceTypeID2",
+				Namespace: "billing",
+				Name:      "invoice",
+				CreateAt:  now,
+			},
+			expectedError: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "pq: duplicate key value violates unique constraint \"idx_resource_type_namespace_name\"",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean resource type database
+		cleanResourceTypeTable()
+
+		// Insert previous data
+		if test.previousResourceType != nil {
+			if err := insertResourceType(*test.previousResourceType); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+		// Call to repository to store resource type
+		storedResourceType, err := repoDB.AddResourceType(*test.resourceTypeToCreate)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check response
+			if diff := pretty.Compare(storedResourceType, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+			// Check database
+			resourceTypeNumber, err := getResourceTypesCountFiltered(test.resourceTypeToCreate.ID, "")
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error counting resource types: %v", n, err)
+				continue
+			}
+			if resourceTypeNumber != 1 {
+				t.Errorf("Test %v failed. Received different resource type number: %v", n, resourceTypeNumber)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_GetResourceTypeByName(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousResourceType *ResourceType
+		// Postgres Repo Args
+		namespace string
+		name      string
+		// Expected result
+		expectedResponse *api.ResourceType
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			previousResourceType: &ResourceType{
+				ID:        "ResourceTypeID",
+				Namespace: "billing",
+				Name:      "invoice",
+				CreateAt:  now.UnixNano(),
+			},
+			namespace: "billing",
+			name:      "invoice",
+			expectedResponse: &api.ResourceType{
+				ID:        "ResourceTypeID",
+				Namespace: "billing",
+				Name:      "invoice",
+				CreateAt:  now,
+			},
+		},
+		"ErrorCaseResourceTypeNotFound": {
+			namespace: "billing",
+			name:      "invoice",
+			expectedError: &database.Error{
+				Code:    database.RESOURCE_TYPE_NOT_FOUND,
+				Message: "Resource type billing:invoice not found",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean resource type database
+		cleanResourceTypeTable()
+
+		// Insert previous data
+		if test.previousResourceType != nil {
+			if err := insertResourceType(*test.previousResourceType); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+		// Call to repository to get resource type
+		receivedResourceType, err := repoDB.GetResourceTypeByName(test.namespace, test.name)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check response
+			if diff := pretty.Compare(receivedResourceType, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package postgresql
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// TAG REPOSITORY IMPLEMENTATION
+
+func (t PostgresRepo) SetTag(resourceID string, key string, value string) error {
+	tag := &Tag{}
+
+	// Create tag or update its value if it already exists
+	err := t.Dbmap.Where(Tag{ResourceID: resourceID, Key: key}).Assign(Tag{Value: value}).FirstOrCreate(tag).Error
+
+	// Error handling
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return nil
+}
+
+func (t PostgresRepo) RemoveTag(resourceID string, key string) error {
+	// Delete tag
+	err := t.Dbmap.Where("resource_id like ? AND key like ?", resourceID, key).Delete(&Tag{}).Error
+
+	// Error handling
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Retrieve tags of a resource. It returns nil if the resource doesn't have tags
+func (t PostgresRepo) getTags(resourceID string) (map[string]string, error) {
+	tags, err := t.getTagsByResourceIDs([]string{resourceID})
+	if err != nil {
+		return nil, err
+	}
+
+	return tags[resourceID], nil
+}
+
+// Retrieve tags of several resources, indexed by resource id
+func (t PostgresRepo) getTagsByResourceIDs(resourceIDs []string) (map[string]map[string]string, error) {
+	tags := []Tag{}
+	if len(resourceIDs) < 1 {
+		return nil, nil
+	}
+
+	// Error handling
+	if err := t.Dbmap.Where("resource_id in (?)", resourceIDs).Find(&tags).Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	tagsByResource := map[string]map[string]string{}
+	for _, tag := range tags {
+		if tagsByResource[tag.ResourceID] == nil {
+			tagsByResource[tag.ResourceID] = map[string]string{}
+		}
+		tagsByResource[tag.ResourceID][tag.Key] = tag.Value
+	}
+
+	return tagsByResource, nil
+}
+
+// Restrict query to resources that have all the tags
+func filterByTags(query *gorm.DB, tags map[string]string) *gorm.DB {
+	for key, value := range tags {
+		query = query.Where("id in (SELECT resource_id FROM tags WHERE key = ? AND value = ?)", key, value)
+	}
+
+	return query
+}
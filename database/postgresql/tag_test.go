@@ -0,0 +1,143 @@
+package postgresql
+
+import (
+	"testing"
+)
+
+func TestPostgresRepo_SetTag(t *testing.T) {
+	testcases := map[string]struct {
+		// Previous data
+		previousTag *Tag
+		// Postgres Repo Args
+		resourceID string
+		key        string
+		value      string
+	}{
+		"OkCaseNewTag": {
+			resourceID: "ResourceID",
+			key:        "team",
+			value:      "payments",
+		},
+		"OkCaseUpdateTag": {
+			previousTag: &Tag{
+				ResourceID: "ResourceID",
+				Key:        "team",
+				Value:      "billing",
+			},
+			resourceID: "ResourceID",
+			key:        "team",
+			value:      "payments",
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean tag database
+		cleanTagTable()
+
+		// Insert previous data
+		if test.previousTag != nil {
+			if err := insertTag(*test.previousTag); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+		// Call to repository to set tag
+		err := repoDB.SetTag(test.resourceID, test.key, test.value)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		// Check database
+		tagNumber, err := getTagsCountFiltered(test.resourceID, test.key, "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting tags: %v", n, err)
+			continue
+		}
+		if tagNumber != 1 {
+			t.Errorf("Test %v failed. Received different tag number: %v", n, tagNumber)
+			continue
+		}
+		tagNumber, err = getTagsCountFiltered(test.resourceID, test.key, test.value)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting tags: %v", n, err)
+			continue
+		}
+		if tagNumber != 1 {
+			t.Errorf("Test %v failed. Tag value wasn't stored", n)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_RemoveTag(t *testing.T) {
+	testcases := map[string]struct {
+		// Previous data
+		previousTags []Tag
+		// Postgres Repo Args
+		resourceID string
+		key        string
+		// Expected result
+		expectedTagNumber int
+	}{
+		"OkCase": {
+			previousTags: []Tag{
+				{
+					ResourceID: "ResourceID",
+					Key:        "team",
+					Value:      "payments",
+				},
+				{
+					ResourceID: "ResourceID",
+					Key:        "env",
+					Value:      "prod",
+				},
+				{
+					ResourceID: "OtherResourceID",
+					Key:        "team",
+					Value:      "payments",
+				},
+			},
+			resourceID:        "ResourceID",
+			key:               "team",
+			expectedTagNumber: 2,
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean tag database
+		cleanTagTable()
+
+		// Insert previous data
+		for _, tag := range test.previousTags {
+			if err := insertTag(tag); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+		// Call to repository to remove tag
+		err := repoDB.RemoveTag(test.resourceID, test.key)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		// Check database
+		tagNumber, err := getTagsCountFiltered(test.resourceID, test.key, "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting tags: %v", n, err)
+			continue
+		}
+		if tagNumber != 0 {
+			t.Errorf("Test %v failed. Tag wasn't removed", n)
+			continue
+		}
+		tagNumber, err = getTagsCountFiltered("", "", "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting tags: %v", n, err)
+			continue
+		}
+		if tagNumber != test.expectedTagNumber {
+			t.Errorf("Test %v failed. Received different tag number: %v", n, tagNumber)
+			continue
+		}
+	}
+}
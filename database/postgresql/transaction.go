@@ -0,0 +1,78 @@
+package postgresql
+
+import (
+	"database/sql"
+
+	"github.com/jinzhu/gorm"
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// TRANSACTION REPOSITORY IMPLEMENTATION
+
+func (p PostgresRepo) RunInTransaction(fn func(repo api.Repo) error) error {
+	// Repositories bound to a transaction run the function inside it
+	transaction := p.begin()
+
+	// Error handling
+	if err := transaction.Error; err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Roll back if the function panics, so the connection isn't left inside the transaction
+	defer func() {
+		if r := recover(); r != nil {
+			transaction.Rollback()
+			panic(r)
+		}
+	}()
+
+	// Operations of the function share the transaction
+	if err := fn(PostgresRepo{Dbmap: transaction.DB}); err != nil {
+		transaction.Rollback()
+		return err
+	}
+
+	if err := transaction.Commit().Error; err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Transaction of a repository operation. When the repository is already bound to a transaction
+// the operation joins it, so commit and rollback are left to the outer transaction
+type repoTransaction struct {
+	*gorm.DB
+	joined bool
+}
+
+func (t repoTransaction) Commit() *gorm.DB {
+	if t.joined {
+		return t.DB
+	}
+	return t.DB.Commit()
+}
+
+func (t repoTransaction) Rollback() *gorm.DB {
+	if t.joined {
+		return t.DB
+	}
+	return t.DB.Rollback()
+}
+
+// Start a transaction, or join the current one if the repository is bound to a transaction
+func (p PostgresRepo) begin() repoTransaction {
+	if _, ok := p.Dbmap.CommonDB().(*sql.Tx); ok {
+		return repoTransaction{DB: p.Dbmap, joined: true}
+	}
+	return repoTransaction{DB: p.Dbmap.Begin()}
+}
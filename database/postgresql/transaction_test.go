@@ -0,0 +1,90 @@
+package postgresql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+)
+
+func TestPostgresRepo_RunInTransaction(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Error returned by the function after its operations
+		fnErr error
+		// Expected result
+		expectedUsers     int
+		expectedRelations int
+		expectedError     error
+	}{
+		"OkCaseCommit": {
+			expectedUsers:     1,
+			expectedRelations: 1,
+		},
+		"OkCaseRollback": {
+			fnErr: &api.Error{
+				Code:    api.USER_ALREADY_EXIST,
+				Message: "Error",
+			},
+			expectedError: &api.Error{
+				Code:    api.USER_ALREADY_EXIST,
+				Message: "Error",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean user database
+		cleanUserTable()
+		cleanGroupUserRelationTable()
+
+		// Call to repository to run operations in a transaction. AddMember starts its own
+		// transaction, so it checks that it joins the outer one
+		err := repoDB.RunInTransaction(func(repo api.Repo) error {
+			if _, err := repo.AddUser(api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "Path",
+				CreateAt:   now,
+				Urn:        "urn",
+				Status:     api.USER_STATUS_ACTIVE,
+			}); err != nil {
+				return err
+			}
+			if err := repo.AddMember("UserID", "GroupID", nil); err != nil {
+				return err
+			}
+			return test.fnErr
+		})
+		if test.expectedError != nil {
+			if diff := pretty.Compare(err, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		// Check database
+		users, err := getUsersCountFiltered("UserID", "", "", 0, "", "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting users: %v", n, err)
+			continue
+		}
+		if users != test.expectedUsers {
+			t.Errorf("Test %v failed. Received different user number (wanted:%v / received:%v)", n, test.expectedUsers, users)
+			continue
+		}
+		relations, err := getGroupUserRelations("GroupID", "UserID")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting relations: %v", n, err)
+			continue
+		}
+		if relations != test.expectedRelations {
+			t.Errorf("Test %v failed. Received different relation number (wanted:%v / received:%v)", n, test.expectedRelations, relations)
+			continue
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package postgresql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// Rows of a deleted user, group or policy with its relationships and tags,
+// stored as the document of the deleted resource
+type trashDocument struct {
+	User                 *User                 `json:"user,omitempty"`
+	Group                *Group                `json:"group,omitempty"`
+	Policy               *Policy               `json:"policy,omitempty"`
+	Statements           []Statement           `json:"statements,omitempty"`
+	GroupUserRelations   []GroupUserRelation   `json:"groupUserRelations,omitempty"`
+	GroupOwnerRelations  []GroupOwnerRelation  `json:"groupOwnerRelations,omitempty"`
+	GroupPolicyRelations []GroupPolicyRelation `json:"groupPolicyRelations,omitempty"`
+	Tags                 []Tag                 `json:"tags,omitempty"`
+}
+
+// TRASH REPOSITORY IMPLEMENTATION
+
+func (t PostgresRepo) GetDeletedResourcesFiltered(resourceType string, filter *api.Filter) ([]api.DeletedResource, int, error) {
+	deletedResources := []DeletedResource{}
+	query := t.Dbmap
+
+	if len(resourceType) > 0 {
+		query = query.Where("type like ?", resourceType)
+	}
+	if len(filter.Org) > 0 {
+		query = query.Where("org like ?", filter.Org)
+	}
+	if len(filter.Name) > 0 {
+		// Underscore is a wildcard in like expressions
+		query = query.Where("name like ?", "%"+strings.Replace(filter.Name, "_", `\_`, -1)+"%")
+	}
+
+	// Count deleted resources and retrieve the requested page
+	query, total, err := paginate(query, &DeletedResource{}, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Error handling. Last deleted resources are returned first
+	if err := query.Order("deleted_at desc").Order("id").Find(&deletedResources).Error; err != nil {
+		return nil, 0, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Transform deleted resources for API
+	apiDeletedResources := make([]api.DeletedResource, len(deletedResources), cap(deletedResources))
+	for i, r := range deletedResources {
+		apiDeletedResources[i] = *dbDeletedResourceToAPIDeletedResource(&r)
+	}
+
+	return apiDeletedResources, total, nil
+}
+
+func (t PostgresRepo) GetDeletedResourceByID(id string) (*api.DeletedResource, error) {
+	deletedResource := &DeletedResource{}
+	query := t.Dbmap.Where("id like ?", id).First(deletedResource)
+
+	// Check if deleted resource exists
+	if query.RecordNotFound() {
+		return nil, &database.Error{
+			Code:    database.DELETED_RESOURCE_NOT_FOUND,
+			Message: fmt.Sprintf("Deleted resource with id %v not found", id),
+		}
+	}
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbDeletedResourceToAPIDeletedResource(deletedResource), nil
+}
+
+func (t PostgresRepo) RestoreDeletedResource(id string) error {
+	transaction := t.begin()
+
+	// Retrieve deleted resource with its rows
+	deletedResource := &DeletedResource{}
+	query := transaction.Where("id like ?", id).First(deletedResource)
+	if query.RecordNotFound() {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.DELETED_RESOURCE_NOT_FOUND,
+			Message: fmt.Sprintf("Deleted resource with id %v not found", id),
+		}
+	}
+	if err := query.Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	document := trashDocument{}
+	if err := json.Unmarshal([]byte(deletedResource.Document), &document); err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Store resource with its statements and tags
+	rows := []interface{}{}
+	if document.User != nil {
+		rows = append(rows, document.User)
+	}
+	if document.Group != nil {
+		rows = append(rows, document.Group)
+	}
+	if document.Policy != nil {
+		rows = append(rows, document.Policy)
+	}
+	for i := range document.Statements {
+		rows = append(rows, &document.Statements[i])
+	}
+	for i := range document.Tags {
+		rows = append(rows, &document.Tags[i])
+	}
+	if err := createRows(transaction.DB, rows); err != nil {
+		transaction.Rollback()
+		return err
+	}
+	for i := range document.Statements {
+		if err := createStatementReferences(transaction.DB, &document.Statements[i]); err != nil {
+			transaction.Rollback()
+			return err
+		}
+	}
+
+	// Store relationships with users, groups and policies that still exist
+	rows = []interface{}{}
+	for i, relation := range document.GroupUserRelations {
+		userExists, err := rowExists(transaction.DB, &User{}, relation.UserID)
+		if err != nil {
+			transaction.Rollback()
+			return err
+		}
+		groupExists, err := rowExists(transaction.DB, &Group{}, relation.GroupID)
+		if err != nil {
+			transaction.Rollback()
+			return err
+		}
+		if userExists && groupExists {
+			rows = append(rows, &document.GroupUserRelations[i])
+		}
+	}
+	for i, relation := range document.GroupOwnerRelations {
+		userExists, err := rowExists(transaction.DB, &User{}, relation.UserID)
+		if err != nil {
+			transaction.Rollback()
+			return err
+		}
+		groupExists, err := rowExists(transaction.DB, &Group{}, relation.GroupID)
+		if err != nil {
+			transaction.Rollback()
+			return err
+		}
+		if userExists && groupExists {
+			rows = append(rows, &document.GroupOwnerRelations[i])
+		}
+	}
+	for i, relation := range document.GroupPolicyRelations {
+		groupExists, err := rowExists(transaction.DB, &Group{}, relation.GroupID)
+		if err != nil {
+			transaction.Rollback()
+			return err
+		}
+		policyExists, err := rowExists(transaction.DB, &Policy{}, relation.PolicyID)
+		if err != nil {
+			transaction.Rollback()
+			return err
+		}
+		if groupExists && policyExists {
+			rows = append(rows, &document.GroupPolicyRelations[i])
+		}
+	}
+	if err := createRows(transaction.DB, rows); err != nil {
+		transaction.Rollback()
+		return err
+	}
+
+	// Remove resource from trash
+	if err := transaction.Where("id like ?", id).Delete(&DeletedResource{}).Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	transaction.Commit()
+	return nil
+}
+
+func (t PostgresRepo) PurgeDeletedResources(deletedBefore time.Time) (int, error) {
+	// Delete resources deleted before the date with their documents
+	query := t.Dbmap.Where("deleted_at < ?", deletedBefore.UTC().UnixNano()).Delete(&DeletedResource{})
+
+	// Error handling
+	if err := query.Error; err != nil {
+		return 0, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return int(query.RowsAffected), nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Store the document of a user, group or policy that is being removed in transaction
+func moveToTrash(transaction *gorm.DB, deletedResource DeletedResource, document trashDocument) error {
+	documentJSON, err := json.Marshal(document)
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	deletedResource.DeletedAt = time.Now().UTC().UnixNano()
+	deletedResource.Document = string(documentJSON)
+
+	// Error handling
+	if err := transaction.Create(&deletedResource).Error; err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return nil
+}
+
+// Retrieve rows related to a user, group or policy that is being removed, where column is the
+// column with the id of the resource
+func findTrashRows(transaction *gorm.DB, rows interface{}, column string, id string) error {
+	if err := transaction.Where(column+" like ?", id).Find(rows).Error; err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return nil
+}
+
+// Store rows in transaction
+func createRows(transaction *gorm.DB, rows []interface{}) error {
+	for _, row := range rows {
+		if err := transaction.Create(row).Error; err != nil {
+			return &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: err.Error(),
+			}
+		}
+	}
+
+	return nil
+}
+
+// Check if the row with id exists in the table of model
+func rowExists(transaction *gorm.DB, model interface{}, id string) (bool, error) {
+	var count int
+	if err := transaction.Model(model).Where("id like ?", id).Count(&count).Error; err != nil {
+		return false, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return count > 0, nil
+}
+
+// Transform a deleted resource retrieved from db into a deleted resource for API
+func dbDeletedResourceToAPIDeletedResource(deletedResourcedb *DeletedResource) *api.DeletedResource {
+	return &api.DeletedResource{
+		ID:        deletedResourcedb.ID,
+		Type:      deletedResourcedb.Type,
+		Org:       deletedResourcedb.Org,
+		Name:      deletedResourcedb.Name,
+		Urn:       deletedResourcedb.Urn,
+		DeletedAt: time.Unix(0, deletedResourcedb.DeletedAt).UTC(),
+	}
+}
@@ -0,0 +1,370 @@
+package postgresql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestPostgresRepo_GetDeletedResourcesFiltered(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousDeletedResources []DeletedResource
+		// Postgres Repo Args
+		resourceType string
+		filter       *api.Filter
+		// Expected result
+		expectedResponse []api.DeletedResource
+		expectedTotal    int
+	}{
+		"OkCaseAll": {
+			previousDeletedResources: []DeletedResource{
+				{
+					ID:        "UserID",
+					Type:      api.RESOURCE_USER,
+					Name:      "user",
+					Urn:       "urn:user",
+					DeletedAt: now.UnixNano(),
+					Document:  "{}",
+				},
+				{
+					ID:        "GroupID",
+					Type:      api.RESOURCE_GROUP,
+					Org:       "org1",
+					Name:      "group",
+					Urn:       "urn:group",
+					DeletedAt: now.Add(time.Second).UnixNano(),
+					Document:  "{}",
+				},
+			},
+			filter: &api.Filter{},
+			expectedResponse: []api.DeletedResource{
+				{
+					ID:        "GroupID",
+					Type:      api.RESOURCE_GROUP,
+					Org:       "org1",
+					Name:      "group",
+					Urn:       "urn:group",
+					DeletedAt: now.Add(time.Second),
+				},
+				{
+					ID:        "UserID",
+					Type:      api.RESOURCE_USER,
+					Name:      "user",
+					Urn:       "urn:user",
+					DeletedAt: now,
+				},
+			},
+			expectedTotal: 2,
+		},
+		"OkCaseFilteredByType": {
+			previousDeletedResources: []DeletedResource{
+				{
+					ID:        "UserID",
+					Type:      api.RESOURCE_USER,
+					Name:      "user",
+					Urn:       "urn:user",
+					DeletedAt: now.UnixNano(),
+					Document:  "{}",
+				},
+				{
+					ID:        "GroupID",
+					Type:      api.RESOURCE_GROUP,
+					Org:       "org1",
+					Name:      "group",
+					Urn:       "urn:group",
+					DeletedAt: now.UnixNano(),
+					Document:  "{}",
+				},
+			},
+			resourceType: api.RESOURCE_USER,
+			filter:       &api.Filter{},
+			expectedResponse: []api.DeletedResource{
+				{
+					ID:        "UserID",
+					Type:      api.RESOURCE_USER,
+					Name:      "user",
+					Urn:       "urn:user",
+					DeletedAt: now,
+				},
+			},
+			expectedTotal: 1,
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean deleted resource database
+		cleanDeletedResourceTable()
+
+		// Insert previous data
+		for _, deletedResource := range test.previousDeletedResources {
+			if err := insertDeletedResource(deletedResource); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+		// Call to repository to get deleted resources
+		deletedResources, total, err := repoDB.GetDeletedResourcesFiltered(test.resourceType, test.filter)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if total != test.expectedTotal {
+			t.Errorf("Test %v failed. Received different total: %v", n, total)
+			continue
+		}
+		if diff := pretty.Compare(deletedResources, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_GetDeletedResourceByID(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousDeletedResource *DeletedResource
+		// Postgres Repo Args
+		id string
+		// Expected result
+		expectedResponse *api.DeletedResource
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			previousDeletedResource: &DeletedResource{
+				ID:        "PolicyID",
+				Type:      api.RESOURCE_POLICY,
+				Org:       "org1",
+				Name:      "policy",
+				Urn:       "urn:policy",
+				DeletedAt: now.UnixNano(),
+				Document:  "{}",
+			},
+			id: "PolicyID",
+			expectedResponse: &api.DeletedResource{
+				ID:        "PolicyID",
+				Type:      api.RESOURCE_POLICY,
+				Org:       "org1",
+				Name:      "policy",
+				Urn:       "urn:policy",
+				DeletedAt: now,
+			},
+		},
+		"ErrorCaseNotFound": {
+			id: "PolicyID",
+			expectedError: &database.Error{
+				Code:    database.DELETED_RESOURCE_NOT_FOUND,
+				Message: "Deleted resource with id PolicyID not found",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean deleted resource database
+		cleanDeletedResourceTable()
+
+		// Insert previous data
+		if test.previousDeletedResource != nil {
+			if err := insertDeletedResource(*test.previousDeletedResource); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+		// Call to repository to get deleted resource
+		deletedResource, err := repoDB.GetDeletedResourceByID(test.id)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(deletedResource, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_RestoreDeletedResource(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousUser *api.User
+		groupIDs     []string
+		// Expected result
+		expectedRelations int
+	}{
+		"OkCaseWithGroup": {
+			previousUser: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "/path/",
+				Urn:        "urn:user",
+				CreateAt:   now,
+				Status:     api.USER_STATUS_ACTIVE,
+			},
+			groupIDs:          []string{"GroupID"},
+			expectedRelations: 1,
+		},
+		"OkCaseGroupRemoved": {
+			previousUser: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "/path/",
+				Urn:        "urn:user",
+				CreateAt:   now,
+				Status:     api.USER_STATUS_ACTIVE,
+			},
+			expectedRelations: 0,
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean database
+		cleanUserTable()
+		cleanGroupTable()
+		cleanGroupUserRelationTable()
+		cleanDeletedResourceTable()
+
+		// Insert previous data and move user to the trash
+		if err := insertUser(test.previousUser.ID, test.previousUser.ExternalID, test.previousUser.Path,
+			test.previousUser.CreateAt.UnixNano(), test.previousUser.Urn); err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting previous user: %v", n, err)
+			continue
+		}
+		if err := insertGroup("GroupID", "group", "/path/", now.UnixNano(), "urn:group", "org1"); err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting previous group: %v", n, err)
+			continue
+		}
+		if err := insertGroupUserRelation(test.previousUser.ID, "GroupID"); err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting previous relation: %v", n, err)
+			continue
+		}
+		if err := repoDB.RemoveUser(api.User{ID: test.previousUser.ID}); err != nil {
+			t.Errorf("Test %v failed. Unexpected error removing user: %v", n, err)
+			continue
+		}
+		if len(test.groupIDs) == 0 {
+			cleanGroupTable()
+		}
+
+		// Call to repository to restore user
+		if err := repoDB.RestoreDeletedResource(test.previousUser.ID); err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		// Check database
+		userNumber, err := getUsersCountFiltered(test.previousUser.ID, test.previousUser.ExternalID, "", 0, "", "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting users: %v", n, err)
+			continue
+		}
+		if userNumber != 1 {
+			t.Errorf("Test %v failed. Received different user number: %v", n, userNumber)
+			continue
+		}
+		relations, err := getGroupUserRelations("", test.previousUser.ID)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting relations: %v", n, err)
+			continue
+		}
+		if relations != test.expectedRelations {
+			t.Errorf("Test %v failed. Received different relations number: %v", n, relations)
+			continue
+		}
+		deletedResources, err := getDeletedResourcesCountFiltered(test.previousUser.ID, "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting deleted resources: %v", n, err)
+			continue
+		}
+		if deletedResources != 0 {
+			t.Errorf("Test %v failed. Received different deleted resources number: %v", n, deletedResources)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_PurgeDeletedResources(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousDeletedResources []DeletedResource
+		// Postgres Repo Args
+		deletedBefore time.Time
+		// Expected result
+		expectedPurged    int
+		expectedRemaining int
+	}{
+		"OkCase": {
+			previousDeletedResources: []DeletedResource{
+				{
+					ID:        "OldID",
+					Type:      api.RESOURCE_USER,
+					Name:      "old",
+					Urn:       "urn:old",
+					DeletedAt: now.Add(-2 * time.Hour).UnixNano(),
+					Document:  "{}",
+				},
+				{
+					ID:        "NewID",
+					Type:      api.RESOURCE_USER,
+					Name:      "new",
+					Urn:       "urn:new",
+					DeletedAt: now.UnixNano(),
+					Document:  "{}",
+				},
+			},
+			deletedBefore:     now.Add(-time.Hour),
+			expectedPurged:    1,
+			expectedRemaining: 1,
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean deleted resource database
+		cleanDeletedResourceTable()
+
+		// Insert previous data
+		for _, deletedResource := range test.previousDeletedResources {
+			if err := insertDeletedResource(deletedResource); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+		// Call to repository to purge deleted resources
+		purged, err := repoDB.PurgeDeletedResources(test.deletedBefore)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if purged != test.expectedPurged {
+			t.Errorf("Test %v failed. Received different purged number: %v", n, purged)
+			continue
+		}
+		remaining, err := getDeletedResourcesCountFiltered("", "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting deleted resources: %v", n, err)
+			continue
+		}
+		if remaining != test.expectedRemaining {
+			t.Errorf("Test %v failed. Received different deleted resources number: %v", n, remaining)
+			continue
+		}
+	}
+}
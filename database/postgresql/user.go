@@ -1,6 +1,7 @@
 package postgresql
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -13,16 +14,24 @@ import (
 func (u PostgresRepo) AddUser(user api.User) (*api.User, error) {
 
 	// Create user model
+	attributes, err := marshalUserAttributes(user.Attributes)
+	if err != nil {
+		return nil, err
+	}
 	userDB := &User{
-		ID:         user.ID,
-		ExternalID: user.ExternalID,
-		Path:       user.Path,
-		CreateAt:   user.CreateAt.UnixNano(),
-		Urn:        user.Urn,
+		ID:          user.ID,
+		ExternalID:  user.ExternalID,
+		Path:        user.Path,
+		CreateAt:    user.CreateAt.UnixNano(),
+		Urn:         user.Urn,
+		DisplayName: user.DisplayName,
+		Email:       user.Email,
+		Attributes:  attributes,
+		Status:      user.Status,
 	}
 
 	// Store user
-	err := u.Dbmap.Create(userDB).Error
+	err = u.Dbmap.Create(userDB).Error
 
 	// Error handling
 	if err != nil {
@@ -55,7 +64,15 @@ func (u PostgresRepo) GetUserByExternalID(id string) (*api.User, error) {
 		}
 	}
 
-	return dbUserToAPIUser(user), nil
+	// Retrieve user tags
+	apiUser := dbUserToAPIUser(user)
+	tags, err := u.getTags(apiUser.ID)
+	if err != nil {
+		return nil, err
+	}
+	apiUser.Tags = tags
+
+	return apiUser, nil
 }
 
 func (u PostgresRepo) GetUserByID(id string) (*api.User, error) {
@@ -78,21 +95,37 @@ func (u PostgresRepo) GetUserByID(id string) (*api.User, error) {
 		}
 	}
 
-	return dbUserToAPIUser(user), nil
+	// Retrieve user tags
+	apiUser := dbUserToAPIUser(user)
+	tags, err := u.getTags(apiUser.ID)
+	if err != nil {
+		return nil, err
+	}
+	apiUser.Tags = tags
+
+	return apiUser, nil
 }
 
-func (u PostgresRepo) GetUsersFiltered(pathPrefix string) ([]api.User, error) {
+func (u PostgresRepo) GetUsersFiltered(filter *api.Filter) ([]api.User, int, error) {
 	users := []User{}
 	query := u.Dbmap
 
-	// Check if path is filled, else it doesn't use it to filter
-	if len(pathPrefix) > 0 {
-		query = query.Where("path like ?", pathPrefix+"%")
+	query = filterQuery(query, filter, "external_id")
+	if filter.Orphan {
+		// Users that aren't members of any group
+		query = query.Where("id not in (SELECT user_id FROM group_user_relations WHERE expires_at = 0 OR expires_at > ?)",
+			time.Now().UTC().UnixNano())
+	}
+
+	// Count users and retrieve the requested page
+	query, total, err := paginate(query, &User{}, filter)
+	if err != nil {
+		return nil, 0, err
 	}
 
 	// Error handling
-	if err := query.Find(&users).Error; err != nil {
-		return nil, &database.Error{
+	if err := sortQuery(query, filter, "external_id", "external_id").Find(&users).Error; err != nil {
+		return nil, 0, &database.Error{
 			Code:    database.INTERNAL_ERROR,
 			Message: err.Error(),
 		}
@@ -100,22 +133,30 @@ func (u PostgresRepo) GetUsersFiltered(pathPrefix string) ([]api.User, error) {
 
 	// Transform users for API
 	if users != nil {
+		ids := make([]string, len(users))
+		for i, user := range users {
+			ids[i] = user.ID
+		}
+		tags, err := u.getTagsByResourceIDs(ids)
+		if err != nil {
+			return nil, 0, err
+		}
 		apiusers := make([]api.User, len(users), cap(users))
 		for i, u := range users {
 			apiusers[i] = *dbUserToAPIUser(&u)
+			apiusers[i].Tags = tags[u.ID]
 		}
-		return apiusers, nil
+		return apiusers, total, nil
 	}
 
-	return nil, nil
+	return nil, total, nil
 }
 
-func (u PostgresRepo) UpdateUser(user api.User, newPath string, newUrn string) (*api.User, error) {
+func (u PostgresRepo) UpdateUser(user api.User, newPath string, newUrn string, newProfile api.UserProfile) (*api.User, error) {
 
-	// Create new user
-	userUpdated := User{
-		Path: newPath,
-		Urn:  newUrn,
+	attributes, err := marshalUserAttributes(newProfile.Attributes)
+	if err != nil {
+		return nil, err
 	}
 
 	userDB := User{
@@ -124,10 +165,51 @@ func (u PostgresRepo) UpdateUser(user api.User, newPath string, newUrn string) (
 		Path:       user.Path,
 		CreateAt:   user.CreateAt.UnixNano(),
 		Urn:        user.Urn,
+		Status:     user.Status,
+	}
+
+	// Update user if it hasn't changed since it was retrieved. Profile fields are updated
+	// with a map because they can be empty
+	query := u.Dbmap.Model(&userDB).Where("revision = ?", user.Revision).Updates(map[string]interface{}{
+		"path":         newPath,
+		"urn":          newUrn,
+		"display_name": newProfile.DisplayName,
+		"email":        newProfile.Email,
+		"attributes":   attributes,
+		"revision":     user.Revision + 1,
+	})
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	if query.RowsAffected == 0 {
+		return nil, revisionConflictError(user.Urn)
 	}
 
-	// Update user
-	query := u.Dbmap.Model(&userDB).Update(userUpdated)
+	userDB.Path = newPath
+	userDB.Urn = newUrn
+	userDB.DisplayName = newProfile.DisplayName
+	userDB.Email = newProfile.Email
+	userDB.Attributes = attributes
+	userDB.Revision = user.Revision + 1
+
+	// Tags don't change
+	updatedUser := dbUserToAPIUser(&userDB)
+	updatedUser.Tags = user.Tags
+
+	return updatedUser, nil
+}
+
+func (u PostgresRepo) UpdateUserStatus(user api.User, status string) (*api.User, error) {
+	// Update user status if it hasn't changed since it was retrieved
+	query := u.Dbmap.Model(&User{ID: user.ID}).Where("revision = ?", user.Revision).Updates(map[string]interface{}{
+		"status":   status,
+		"revision": user.Revision + 1,
+	})
 
 	// Error Handling
 	if err := query.Error; err != nil {
@@ -136,23 +218,115 @@ func (u PostgresRepo) UpdateUser(user api.User, newPath string, newUrn string) (
 			Message: err.Error(),
 		}
 	}
+	if query.RowsAffected == 0 {
+		return nil, revisionConflictError(user.Urn)
+	}
+
+	updatedUser := user
+	updatedUser.Status = status
+	updatedUser.Revision = user.Revision + 1
+
+	return &updatedUser, nil
+}
+
+func (u PostgresRepo) UpdateUserExternalID(user api.User, newExternalId string, newUrn string) (*api.User, error) {
+	transaction := u.begin()
+
+	// Update user if it hasn't changed since it was retrieved
+	query := transaction.Model(&User{ID: user.ID}).Where("revision = ?", user.Revision).Updates(map[string]interface{}{
+		"external_id": newExternalId,
+		"urn":         newUrn,
+		"revision":    user.Revision + 1,
+	})
+	if err := query.Error; err != nil {
+		transaction.Rollback()
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	if query.RowsAffected == 0 {
+		transaction.Rollback()
+		return nil, revisionConflictError(user.Urn)
+	}
+
+	// Access requests reference users by externalId
+	err := transaction.Model(&AccessRequest{}).Where("requester = ?", user.ExternalID).
+		Update("requester", newExternalId).Error
+	if err != nil {
+		transaction.Rollback()
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	err = transaction.Model(&AccessRequest{}).Where("reviewer = ?", user.ExternalID).
+		Update("reviewer", newExternalId).Error
+	if err != nil {
+		transaction.Rollback()
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
 
-	return dbUserToAPIUser(&userDB), nil
+	transaction.Commit()
+
+	updatedUser := user
+	updatedUser.ExternalID = newExternalId
+	updatedUser.Urn = newUrn
+	updatedUser.Revision = user.Revision + 1
+
+	return &updatedUser, nil
 }
 
-func (u PostgresRepo) RemoveUser(id string) error {
-	transaction := u.Dbmap.Begin()
-	// Delete user
-	transaction.Where("id like ?", id).Delete(&User{})
+func (u PostgresRepo) RemoveUser(user api.User) error {
+	id := user.ID
+	transaction := u.begin()
+	// Move user with its relationships and tags to the trash
+	document := trashDocument{User: &User{}}
+	if err := findTrashRows(transaction.DB, document.User, "id", id); err != nil {
+		transaction.Rollback()
+		return err
+	}
+	if err := findTrashRows(transaction.DB, &document.GroupUserRelations, "user_id", id); err != nil {
+		transaction.Rollback()
+		return err
+	}
+	if err := findTrashRows(transaction.DB, &document.GroupOwnerRelations, "user_id", id); err != nil {
+		transaction.Rollback()
+		return err
+	}
+	if err := findTrashRows(transaction.DB, &document.Tags, "resource_id", id); err != nil {
+		transaction.Rollback()
+		return err
+	}
+	err := moveToTrash(transaction.DB, DeletedResource{
+		ID:   id,
+		Type: api.RESOURCE_USER,
+		Name: document.User.ExternalID,
+		Urn:  document.User.Urn,
+	}, document)
+	if err != nil {
+		transaction.Rollback()
+		return err
+	}
+
+	// Delete user if it hasn't changed since it was retrieved
+	query := transaction.Where("id like ? AND revision = ?", id, user.Revision).Delete(&User{})
 
 	// Error handling
-	if err := transaction.Error; err != nil {
+	if err := query.Error; err != nil {
 		transaction.Rollback()
 		return &database.Error{
 			Code:    database.INTERNAL_ERROR,
 			Message: err.Error(),
 		}
 	}
+	if query.RowsAffected == 0 {
+		transaction.Rollback()
+		return revisionConflictError(user.Urn)
+	}
 
 	//  delete all user relations
 	transaction.Where("user_id like ?", id).Delete(&GroupUserRelation{})
@@ -166,17 +340,64 @@ func (u PostgresRepo) RemoveUser(id string) error {
 		}
 	}
 
+	// Delete group ownerships of the user
+	transaction.Where("user_id like ?", id).Delete(&GroupOwnerRelation{})
+
+	// Error handling
+	if err := transaction.Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Delete user tags
+	transaction.Where("resource_id like ?", id).Delete(&Tag{})
+
+	// Error handling
+	if err := transaction.Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
 	transaction.Commit()
 	return nil
 }
 
-func (u PostgresRepo) GetGroupsByUserID(id string) ([]api.Group, error) {
+func (u PostgresRepo) GetGroupsByUserID(id string, filter *api.Filter) ([]api.Group, int, error) {
 	relations := []GroupUserRelation{}
-	query := u.Dbmap.Where("user_id like ?", id).Find(&relations)
+	// Explicit memberships of groups without rule, and groups whose rule matches the user
+	groupIDs := "SELECT group_id FROM group_user_relations WHERE user_id like ? AND (expires_at = 0 OR expires_at > ?) " +
+		"AND group_id NOT IN (SELECT id FROM groups WHERE rule_path_prefix <> '' OR rule_attribute_key <> '') " +
+		"UNION SELECT groups.id FROM groups JOIN users ON users.id like ? " +
+		"WHERE (groups.rule_path_prefix <> '' OR groups.rule_attribute_key <> '') " +
+		"AND left(users.path, length(groups.rule_path_prefix)) = groups.rule_path_prefix AND (groups.rule_attribute_key = '' OR " +
+		"(NULLIF(users.attributes, '')::jsonb ->> groups.rule_attribute_key) = groups.rule_attribute_value)"
+	args := []interface{}{id, time.Now().UTC().UnixNano(), id}
+
+	// Count groups
+	var total int
+	if err := u.Dbmap.Raw("SELECT count(*) FROM ("+groupIDs+") AS ids", args...).Row().Scan(&total); err != nil {
+		return nil, 0, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Retrieve the requested page
+	page := groupIDs + " ORDER BY group_id"
+	if filter.Limit > 0 {
+		page += " OFFSET ? LIMIT ?"
+		args = append(args, filter.Offset, filter.Limit)
+	}
 
 	// Error Handling
-	if err := query.Error; err != nil {
-		return nil, &database.Error{
+	if err := u.Dbmap.Raw(page, args...).Scan(&relations).Error; err != nil {
+		return nil, 0, &database.Error{
 			Code:    database.INTERNAL_ERROR,
 			Message: err.Error(),
 		}
@@ -190,7 +411,7 @@ func (u PostgresRepo) GetGroupsByUserID(id string) ([]api.Group, error) {
 			group, err := u.GetGroupById(r.GroupID)
 			// Error handling
 			if err != nil {
-				return nil, &database.Error{
+				return nil, 0, &database.Error{
 					Code:    database.INTERNAL_ERROR,
 					Message: err.Error(),
 				}
@@ -199,18 +420,43 @@ func (u PostgresRepo) GetGroupsByUserID(id string) ([]api.Group, error) {
 		}
 	}
 
-	return apiGroups, nil
+	return apiGroups, total, nil
 }
 
 // PRIVATE HELPER METHODS
 
 // Transform a user retrieved from db into a user for API
 func dbUserToAPIUser(userdb *User) *api.User {
+	// Attributes are always stored by marshalUserAttributes, so they are valid JSON when not empty
+	var attributes map[string]string
+	if userdb.Attributes != "" {
+		json.Unmarshal([]byte(userdb.Attributes), &attributes)
+	}
 	return &api.User{
-		ID:         userdb.ID,
-		ExternalID: userdb.ExternalID,
-		Path:       userdb.Path,
-		CreateAt:   time.Unix(0, userdb.CreateAt).UTC(),
-		Urn:        userdb.Urn,
+		ID:          userdb.ID,
+		ExternalID:  userdb.ExternalID,
+		Path:        userdb.Path,
+		CreateAt:    time.Unix(0, userdb.CreateAt).UTC(),
+		Urn:         userdb.Urn,
+		DisplayName: userdb.DisplayName,
+		Email:       userdb.Email,
+		Attributes:  attributes,
+		Status:      userdb.Status,
+		Revision:    userdb.Revision,
+	}
+}
+
+// Users without attributes store an empty string
+func marshalUserAttributes(attributes map[string]string) (string, error) {
+	if len(attributes) == 0 {
+		return "", nil
+	}
+	content, err := json.Marshal(attributes)
+	if err != nil {
+		return "", &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
 	}
+	return string(content), nil
 }
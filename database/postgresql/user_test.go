@@ -27,6 +27,7 @@ func TestPostgresRepo_AddUser(t *testing.T) {
 				Path:       "Path",
 				Urn:        "urn",
 				CreateAt:   now,
+				Status:     api.USER_STATUS_ACTIVE,
 			},
 			expectedResponse: &api.User{
 				ID:         "UserID",
@@ -34,6 +35,7 @@ func TestPostgresRepo_AddUser(t *testing.T) {
 				Path:       "Path",
 				Urn:        "urn",
 				CreateAt:   now,
+				Status:     api.USER_STATUS_ACTIVE,
 			},
 		},
 		"ErrorCaseUserAlreadyExist": {
@@ -43,6 +45,7 @@ func TestPostgresRepo_AddUser(t *testing.T) {
 				Path:       "Path",
 				Urn:        "urn",
 				CreateAt:   now,
+				Status:     api.USER_STATUS_ACTIVE,
 			},
 			userToCreate: &api.User{
 				ID:         "UserID",
@@ -50,6 +53,7 @@ func TestPostgresRepo_AddUser(t *testing.T) {
 				Path:       "Path",
 				Urn:        "urn",
 				CreateAt:   now,
+				Status:     api.USER_STATUS_ACTIVE,
 			},
 			expectedError: &database.Error{
 				Code:    database.INTERNAL_ERROR,
@@ -127,6 +131,7 @@ func TestPostgresRepo_GetUserByExternalID(t *testing.T) {
 				Path:       "Path",
 				Urn:        "urn",
 				CreateAt:   now,
+				Status:     api.USER_STATUS_ACTIVE,
 			},
 			externalID: "ExternalID",
 			expectedResponse: &api.User{
@@ -135,6 +140,7 @@ func TestPostgresRepo_GetUserByExternalID(t *testing.T) {
 				Path:       "Path",
 				Urn:        "urn",
 				CreateAt:   now,
+				Status:     api.USER_STATUS_ACTIVE,
 			},
 		},
 		"ErrorCaseUserNotExist": {
@@ -144,6 +150,7 @@ func TestPostgresRepo_GetUserByExternalID(t *testing.T) {
 				Path:       "Path",
 				Urn:        "urn",
 				CreateAt:   now,
+				Status:     api.USER_STATUS_ACTIVE,
 			},
 			externalID: "NotExist",
 			expectedError: &database.Error{
@@ -210,6 +217,7 @@ func TestPostgresRepo_GetUserByID(t *testing.T) {
 				Path:       "Path",
 				Urn:        "urn",
 				CreateAt:   now,
+				Status:     api.USER_STATUS_ACTIVE,
 			},
 			userID: "UserID",
 			expectedResponse: &api.User{
@@ -218,6 +226,7 @@ func TestPostgresRepo_GetUserByID(t *testing.T) {
 				Path:       "Path",
 				Urn:        "urn",
 				CreateAt:   now,
+				Status:     api.USER_STATUS_ACTIVE,
 			},
 		},
 		"ErrorCaseUserNotExist": {
@@ -227,6 +236,7 @@ func TestPostgresRepo_GetUserByID(t *testing.T) {
 				Path:       "Path",
 				Urn:        "urn",
 				CreateAt:   now,
+				Status:     api.USER_STATUS_ACTIVE,
 			},
 			userID: "NotExist",
 			expectedError: &database.Error{
@@ -278,12 +288,18 @@ func TestPostgresRepo_GetUserByID(t *testing.T) {
 
 func TestPostgresRepo_GetUsersFiltered(t *testing.T) {
 	now := time.Now().UTC()
+	yesterday := now.Add(-24 * time.Hour)
+	tomorrow := now.Add(24 * time.Hour)
 	testcases := map[string]struct {
 		// Previous data
 		previousUsers []api.User
+		previousTags  []Tag
+		// Users that are members of a group
+		previousMembers []string
 		// Postgres Repo Args
-		pathPrefix string
+		filter *api.Filter
 		// Expected result
+		expectedTotal    int
 		expectedResponse []api.User
 	}{
 		"OkCase1": {
@@ -294,6 +310,7 @@ func TestPostgresRepo_GetUsersFiltered(t *testing.T) {
 					Path:       "Path123",
 					Urn:        "urn1",
 					CreateAt:   now,
+					Status:     api.USER_STATUS_ACTIVE,
 				},
 				{
 					ID:         "UserID2",
@@ -301,9 +318,11 @@ func TestPostgresRepo_GetUsersFiltered(t *testing.T) {
 					Path:       "Path456",
 					Urn:        "urn2",
 					CreateAt:   now,
+					Status:     api.USER_STATUS_ACTIVE,
 				},
 			},
-			pathPrefix: "Path",
+			filter:        &api.Filter{PathPrefix: "Path"},
+			expectedTotal: 2,
 			expectedResponse: []api.User{
 				{
 					ID:         "UserID1",
@@ -311,6 +330,7 @@ func TestPostgresRepo_GetUsersFiltered(t *testing.T) {
 					Path:       "Path123",
 					Urn:        "urn1",
 					CreateAt:   now,
+					Status:     api.USER_STATUS_ACTIVE,
 				},
 				{
 					ID:         "UserID2",
@@ -318,6 +338,7 @@ func TestPostgresRepo_GetUsersFiltered(t *testing.T) {
 					Path:       "Path456",
 					Urn:        "urn2",
 					CreateAt:   now,
+					Status:     api.USER_STATUS_ACTIVE,
 				},
 			},
 		},
@@ -329,6 +350,7 @@ func TestPostgresRepo_GetUsersFiltered(t *testing.T) {
 					Path:       "Path123",
 					Urn:        "urn1",
 					CreateAt:   now,
+					Status:     api.USER_STATUS_ACTIVE,
 				},
 				{
 					ID:         "UserID2",
@@ -336,9 +358,11 @@ func TestPostgresRepo_GetUsersFiltered(t *testing.T) {
 					Path:       "Path456",
 					Urn:        "urn2",
 					CreateAt:   now,
+					Status:     api.USER_STATUS_ACTIVE,
 				},
 			},
-			pathPrefix: "Path123",
+			filter:        &api.Filter{PathPrefix: "Path123"},
+			expectedTotal: 1,
 			expectedResponse: []api.User{
 				{
 					ID:         "UserID1",
@@ -346,6 +370,7 @@ func TestPostgresRepo_GetUsersFiltered(t *testing.T) {
 					Path:       "Path123",
 					Urn:        "urn1",
 					CreateAt:   now,
+					Status:     api.USER_STATUS_ACTIVE,
 				},
 			},
 		},
@@ -357,6 +382,7 @@ func TestPostgresRepo_GetUsersFiltered(t *testing.T) {
 					Path:       "Path123",
 					Urn:        "urn1",
 					CreateAt:   now,
+					Status:     api.USER_STATUS_ACTIVE,
 				},
 				{
 					ID:         "UserID2",
@@ -364,16 +390,257 @@ func TestPostgresRepo_GetUsersFiltered(t *testing.T) {
 					Path:       "Path456",
 					Urn:        "urn2",
 					CreateAt:   now,
+					Status:     api.USER_STATUS_ACTIVE,
 				},
 			},
-			pathPrefix:       "NoPath",
+			filter:           &api.Filter{PathPrefix: "NoPath"},
 			expectedResponse: []api.User{},
 		},
+		"OkCasePaging": {
+			previousUsers: []api.User{
+				{
+					ID:         "UserID1",
+					ExternalID: "ExternalID1",
+					Path:       "Path123",
+					Urn:        "urn1",
+					CreateAt:   now,
+					Status:     api.USER_STATUS_ACTIVE,
+				},
+				{
+					ID:         "UserID2",
+					ExternalID: "ExternalID2",
+					Path:       "Path456",
+					Urn:        "urn2",
+					CreateAt:   now,
+					Status:     api.USER_STATUS_ACTIVE,
+				},
+				{
+					ID:         "UserID3",
+					ExternalID: "ExternalID3",
+					Path:       "Path789",
+					Urn:        "urn3",
+					CreateAt:   now,
+					Status:     api.USER_STATUS_ACTIVE,
+				},
+			},
+			filter:        &api.Filter{PathPrefix: "Path", Offset: 1, Limit: 1},
+			expectedTotal: 3,
+			expectedResponse: []api.User{
+				{
+					ID:         "UserID2",
+					ExternalID: "ExternalID2",
+					Path:       "Path456",
+					Urn:        "urn2",
+					CreateAt:   now,
+					Status:     api.USER_STATUS_ACTIVE,
+				},
+			},
+		},
+		"OkCaseFilterByTags": {
+			previousUsers: []api.User{
+				{
+					ID:         "UserID1",
+					ExternalID: "ExternalID1",
+					Path:       "Path123",
+					Urn:        "urn1",
+					CreateAt:   now,
+					Status:     api.USER_STATUS_ACTIVE,
+				},
+				{
+					ID:         "UserID2",
+					ExternalID: "ExternalID2",
+					Path:       "Path456",
+					Urn:        "urn2",
+					CreateAt:   now,
+					Status:     api.USER_STATUS_ACTIVE,
+				},
+			},
+			previousTags: []Tag{
+				{
+					ResourceID: "UserID1",
+					Key:        "env",
+					Value:      "dev",
+				},
+				{
+					ResourceID: "UserID2",
+					Key:        "env",
+					Value:      "prod",
+				},
+			},
+			filter:        &api.Filter{Tags: map[string]string{"env": "prod"}},
+			expectedTotal: 1,
+			expectedResponse: []api.User{
+				{
+					ID:         "UserID2",
+					ExternalID: "ExternalID2",
+					Path:       "Path456",
+					Urn:        "urn2",
+					CreateAt:   now,
+					Tags:       map[string]string{"env": "prod"},
+					Status:     api.USER_STATUS_ACTIVE,
+				},
+			},
+		},
+		"OkCaseFilterByName": {
+			previousUsers: []api.User{
+				{
+					ID:         "UserID1",
+					ExternalID: "ExternalID1",
+					Path:       "Path123",
+					Urn:        "urn1",
+					CreateAt:   now,
+					Status:     api.USER_STATUS_ACTIVE,
+				},
+				{
+					ID:         "UserID2",
+					ExternalID: "ExternalID2",
+					Path:       "Path456",
+					Urn:        "urn2",
+					CreateAt:   now,
+					Status:     api.USER_STATUS_ACTIVE,
+				},
+				{
+					ID:         "UserID3",
+					ExternalID: "Other",
+					Path:       "Path789",
+					Urn:        "urn3",
+					CreateAt:   now,
+					Status:     api.USER_STATUS_ACTIVE,
+				},
+			},
+			filter:        &api.Filter{Name: "ternalID2"},
+			expectedTotal: 1,
+			expectedResponse: []api.User{
+				{
+					ID:         "UserID2",
+					ExternalID: "ExternalID2",
+					Path:       "Path456",
+					Urn:        "urn2",
+					CreateAt:   now,
+					Status:     api.USER_STATUS_ACTIVE,
+				},
+			},
+		},
+		"OkCaseSortByCreateAtDesc": {
+			previousUsers: []api.User{
+				{
+					ID:         "UserID1",
+					ExternalID: "ExternalID1",
+					Path:       "Path123",
+					Urn:        "urn1",
+					CreateAt:   now.Add(-time.Hour),
+					Status:     api.USER_STATUS_ACTIVE,
+				},
+				{
+					ID:         "UserID2",
+					ExternalID: "ExternalID2",
+					Path:       "Path456",
+					Urn:        "urn2",
+					CreateAt:   now,
+					Status:     api.USER_STATUS_ACTIVE,
+				},
+			},
+			filter:        &api.Filter{SortBy: api.SORT_BY_CREATE_AT, Order: api.ORDER_DESC},
+			expectedTotal: 2,
+			expectedResponse: []api.User{
+				{
+					ID:         "UserID2",
+					ExternalID: "ExternalID2",
+					Path:       "Path456",
+					Urn:        "urn2",
+					CreateAt:   now,
+					Status:     api.USER_STATUS_ACTIVE,
+				},
+				{
+					ID:         "UserID1",
+					ExternalID: "ExternalID1",
+					Path:       "Path123",
+					Urn:        "urn1",
+					CreateAt:   now.Add(-time.Hour),
+					Status:     api.USER_STATUS_ACTIVE,
+				},
+			},
+		},
+		"OkCaseCreationDateRange": {
+			previousUsers: []api.User{
+				{
+					ID:         "UserID1",
+					ExternalID: "ExternalID1",
+					Path:       "Path123",
+					Urn:        "urn1",
+					CreateAt:   now.Add(-48 * time.Hour),
+					Status:     api.USER_STATUS_ACTIVE,
+				},
+				{
+					ID:         "UserID2",
+					ExternalID: "ExternalID2",
+					Path:       "Path456",
+					Urn:        "urn2",
+					CreateAt:   now,
+					Status:     api.USER_STATUS_ACTIVE,
+				},
+				{
+					ID:         "UserID3",
+					ExternalID: "ExternalID3",
+					Path:       "Path789",
+					Urn:        "urn3",
+					CreateAt:   now.Add(time.Hour),
+					Status:     api.USER_STATUS_ACTIVE,
+				},
+			},
+			filter:        &api.Filter{CreatedAfter: &yesterday, CreatedBefore: &tomorrow},
+			expectedTotal: 1,
+			expectedResponse: []api.User{
+				{
+					ID:         "UserID2",
+					ExternalID: "ExternalID2",
+					Path:       "Path456",
+					Urn:        "urn2",
+					CreateAt:   now,
+					Status:     api.USER_STATUS_ACTIVE,
+				},
+			},
+		},
+		"OkCaseOrphan": {
+			previousUsers: []api.User{
+				{
+					ID:         "UserID1",
+					ExternalID: "ExternalID1",
+					Path:       "Path123",
+					Urn:        "urn1",
+					CreateAt:   now,
+					Status:     api.USER_STATUS_ACTIVE,
+				},
+				{
+					ID:         "UserID2",
+					ExternalID: "ExternalID2",
+					Path:       "Path456",
+					Urn:        "urn2",
+					CreateAt:   now,
+					Status:     api.USER_STATUS_ACTIVE,
+				},
+			},
+			previousMembers: []string{"UserID1"},
+			filter:          &api.Filter{Orphan: true},
+			expectedTotal:   1,
+			expectedResponse: []api.User{
+				{
+					ID:         "UserID2",
+					ExternalID: "ExternalID2",
+					Path:       "Path456",
+					Urn:        "urn2",
+					CreateAt:   now,
+					Status:     api.USER_STATUS_ACTIVE,
+				},
+			},
+		},
 	}
 
 	for n, test := range testcases {
 		// Clean user database
 		cleanUserTable()
+		cleanTagTable()
+		cleanGroupUserRelationTable()
 
 		// Insert previous data
 		if test.previousUsers != nil {
@@ -385,12 +652,29 @@ func TestPostgresRepo_GetUsersFiltered(t *testing.T) {
 				}
 			}
 		}
+		for _, tag := range test.previousTags {
+			if err := insertTag(tag); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous tags: %v", n, err)
+				continue
+			}
+		}
+		for _, userID := range test.previousMembers {
+			if err := insertGroupUserRelation(userID, "GroupID"); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous group user relations: %v", n, err)
+				continue
+			}
+		}
 		// Call to repository to get users
-		receivedUsers, err := repoDB.GetUsersFiltered(test.pathPrefix)
+		receivedUsers, total, err := repoDB.GetUsersFiltered(test.filter)
 		if err != nil {
 			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
 			continue
 		}
+		// Check total
+		if total != test.expectedTotal {
+			t.Errorf("Test %v failed. Received different total (wanted:%v / received:%v)", n, test.expectedTotal, total)
+			continue
+		}
 		// Check response
 		if diff := pretty.Compare(receivedUsers, test.expectedResponse); diff != "" {
 			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
@@ -409,8 +693,10 @@ func TestPostgresRepo_UpdateUser(t *testing.T) {
 		userToUpdate *api.User
 		newPath      string
 		newUrn       string
+		newProfile   api.UserProfile
 		// Expected result
 		expectedResponse *api.User
+		expectedError    *database.Error
 	}{
 		"OkCase": {
 			previousUser: &api.User{
@@ -419,6 +705,7 @@ func TestPostgresRepo_UpdateUser(t *testing.T) {
 				Path:       "OldPath",
 				Urn:        "Oldurn",
 				CreateAt:   now,
+				Status:     api.USER_STATUS_ACTIVE,
 			},
 			userToUpdate: &api.User{
 				ID:         "UserID",
@@ -426,16 +713,55 @@ func TestPostgresRepo_UpdateUser(t *testing.T) {
 				Path:       "OldPath",
 				Urn:        "Oldurn",
 				CreateAt:   now,
+				Status:     api.USER_STATUS_ACTIVE,
 			},
 			newPath: "NewPath",
 			newUrn:  "NewUrn",
+			newProfile: api.UserProfile{
+				DisplayName: "User Name",
+				Email:       "user@example.com",
+				Attributes: map[string]string{
+					"department": "sales",
+				},
+			},
 			expectedResponse: &api.User{
+				ID:          "UserID",
+				ExternalID:  "ExternalID",
+				Path:        "NewPath",
+				Urn:         "NewUrn",
+				CreateAt:    now,
+				DisplayName: "User Name",
+				Email:       "user@example.com",
+				Attributes: map[string]string{
+					"department": "sales",
+				},
+				Status:   api.USER_STATUS_ACTIVE,
+				Revision: 1,
+			},
+		},
+		"ErrorCaseRevisionConflict": {
+			previousUser: &api.User{
 				ID:         "UserID",
 				ExternalID: "ExternalID",
-				Path:       "NewPath",
-				Urn:        "NewUrn",
+				Path:       "OldPath",
+				Urn:        "Oldurn",
 				CreateAt:   now,
 			},
+			userToUpdate: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "OldPath",
+				Urn:        "Oldurn",
+				CreateAt:   now,
+				Status:     api.USER_STATUS_ACTIVE,
+				Revision:   2,
+			},
+			newPath: "NewPath",
+			newUrn:  "NewUrn",
+			expectedError: &database.Error{
+				Code:    database.REVISION_CONFLICT,
+				Message: "Resource Oldurn has been modified since it was retrieved",
+			},
 		},
 	}
 
@@ -452,8 +778,18 @@ func TestPostgresRepo_UpdateUser(t *testing.T) {
 			}
 		}
 		// Call to repository to update an user
-		updatedUser, err := repoDB.UpdateUser(*test.userToUpdate, test.newPath, test.newUrn)
-
+		updatedUser, err := repoDB.UpdateUser(*test.userToUpdate, test.newPath, test.newUrn, test.newProfile)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+			}
+			continue
+		}
 		if err != nil {
 			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
 			continue
@@ -474,7 +810,208 @@ func TestPostgresRepo_UpdateUser(t *testing.T) {
 			t.Fatalf("Test %v failed. Received different user number: %v", n, userNumber)
 			continue
 		}
+		// Check stored profile
+		storedUser, err := repoDB.GetUserByExternalID(test.expectedResponse.ExternalID)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error retrieving user: %v", n, err)
+			continue
+		}
+		if diff := pretty.Compare(storedUser, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different stored user (received/wanted) %v", n, diff)
+			continue
+		}
+
+	}
+}
+
+func TestPostgresRepo_UpdateUserStatus(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousUser *api.User
+		// Postgres Repo Args
+		userToUpdate *api.User
+		status       string
+		// Expected result
+		expectedResponse *api.User
+	}{
+		"OkCaseSuspend": {
+			previousUser: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "Path",
+				Urn:        "urn",
+				CreateAt:   now,
+			},
+			userToUpdate: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "Path",
+				Urn:        "urn",
+				CreateAt:   now,
+				Status:     api.USER_STATUS_ACTIVE,
+			},
+			status: api.USER_STATUS_SUSPENDED,
+			expectedResponse: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "Path",
+				Urn:        "urn",
+				CreateAt:   now,
+				Status:     api.USER_STATUS_SUSPENDED,
+				Revision:   1,
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean user database
+		cleanUserTable()
+
+		// Insert previous data
+		if test.previousUser != nil {
+			if err := insertUser(test.previousUser.ID, test.previousUser.ExternalID, test.previousUser.Path,
+				test.previousUser.CreateAt.UnixNano(), test.previousUser.Urn); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous users: %v", n, err)
+				continue
+			}
+		}
+		// Call to repository to update the user status
+		updatedUser, err := repoDB.UpdateUserStatus(*test.userToUpdate, test.status)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		// Check response
+		if diff := pretty.Compare(updatedUser, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+		// Check database
+		storedUser, err := repoDB.GetUserByExternalID(test.expectedResponse.ExternalID)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error retrieving user: %v", n, err)
+			continue
+		}
+		if diff := pretty.Compare(storedUser, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different stored user (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_UpdateUserExternalID(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousUser          *api.User
+		previousAccessRequest *AccessRequest
+		// Postgres Repo Args
+		userToUpdate  *api.User
+		newExternalID string
+		newUrn        string
+		// Expected result
+		expectedResponse  *api.User
+		expectedRequester string
+		expectedReviewer  string
+	}{
+		"OkCase": {
+			previousUser: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "Path",
+				Urn:        "urn",
+				CreateAt:   now,
+			},
+			previousAccessRequest: &AccessRequest{
+				ID:            "AccessRequestID",
+				Type:          api.ACCESS_REQUEST_TYPE_MEMBERSHIP,
+				Requester:     "ExternalID",
+				Org:           "org",
+				GroupName:     "group",
+				Justification: "justification",
+				Duration:      "1h",
+				Status:        api.ACCESS_REQUEST_STATUS_APPROVED,
+				Reviewer:      "Reviewer",
+				CreateAt:      now.UnixNano(),
+				GroupUrn:      "groupUrn",
+			},
+			userToUpdate: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "Path",
+				Urn:        "urn",
+				CreateAt:   now,
+				Status:     api.USER_STATUS_ACTIVE,
+			},
+			newExternalID: "NewExternalID",
+			newUrn:        "NewUrn",
+			expectedResponse: &api.User{
+				ID:         "UserID",
+				ExternalID: "NewExternalID",
+				Path:       "Path",
+				Urn:        "NewUrn",
+				CreateAt:   now,
+				Status:     api.USER_STATUS_ACTIVE,
+				Revision:   1,
+			},
+			expectedRequester: "NewExternalID",
+			expectedReviewer:  "Reviewer",
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean database
+		cleanUserTable()
+		cleanAccessRequestTable()
 
+		// Insert previous data
+		if test.previousUser != nil {
+			if err := insertUser(test.previousUser.ID, test.previousUser.ExternalID, test.previousUser.Path,
+				test.previousUser.CreateAt.UnixNano(), test.previousUser.Urn); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous users: %v", n, err)
+				continue
+			}
+		}
+		if test.previousAccessRequest != nil {
+			if err := insertAccessRequest(*test.previousAccessRequest); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous access requests: %v", n, err)
+				continue
+			}
+		}
+		// Call to repository to update the user externalId
+		updatedUser, err := repoDB.UpdateUserExternalID(*test.userToUpdate, test.newExternalID, test.newUrn)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		// Check response
+		if diff := pretty.Compare(updatedUser, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+		// Check database
+		storedUser, err := repoDB.GetUserByExternalID(test.newExternalID)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error retrieving user: %v", n, err)
+			continue
+		}
+		if diff := pretty.Compare(storedUser, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different stored user (received/wanted) %v", n, diff)
+			continue
+		}
+		if test.previousAccessRequest != nil {
+			accessRequest, err := repoDB.GetAccessRequestByID(test.previousAccessRequest.ID)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error retrieving access request: %v", n, err)
+				continue
+			}
+			if accessRequest.Requester != test.expectedRequester || accessRequest.Reviewer != test.expectedReviewer {
+				t.Errorf("Test %v failed. Received different access request users (wanted: %v, %v / received: %v, %v)",
+					n, test.expectedRequester, test.expectedReviewer, accessRequest.Requester, accessRequest.Reviewer)
+				continue
+			}
+		}
 	}
 }
 
@@ -498,6 +1035,7 @@ func TestPostgresRepo_RemoveUser(t *testing.T) {
 				Path:       "OldPath",
 				Urn:        "Oldurn",
 				CreateAt:   now,
+				Status:     api.USER_STATUS_ACTIVE,
 			},
 			relation: &struct {
 				user_id       string
@@ -515,6 +1053,7 @@ func TestPostgresRepo_RemoveUser(t *testing.T) {
 		// Clean user database
 		cleanUserTable()
 		cleanGroupUserRelationTable()
+		cleanDeletedResourceTable()
 
 		// Insert previous data
 		if test.previousUser != nil {
@@ -533,7 +1072,7 @@ func TestPostgresRepo_RemoveUser(t *testing.T) {
 			}
 		}
 		// Call to repository to remove user
-		err := repoDB.RemoveUser(test.userToDelete)
+		err := repoDB.RemoveUser(api.User{ID: test.userToDelete})
 
 		// Check database
 		userNumber, err := getUsersCountFiltered(test.userToDelete, "", "",
@@ -557,6 +1096,16 @@ func TestPostgresRepo_RemoveUser(t *testing.T) {
 			continue
 		}
 
+		// Check user was moved to the trash
+		deletedResources, err := getDeletedResourcesCountFiltered(test.userToDelete, api.RESOURCE_USER)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting deleted resources: %v", n, err)
+			continue
+		}
+		if deletedResources != 1 {
+			t.Errorf("Test %v failed. Received different deleted resources number: %v", n, deletedResources)
+			continue
+		}
 	}
 }
 
@@ -669,7 +1218,7 @@ func TestPostgresRepo_GetGroupsByUserID(t *testing.T) {
 			}
 		}
 		// Call to repository to get groups associated
-		receivedUsers, err := repoDB.GetGroupsByUserID(test.userID)
+		receivedUsers, total, err := repoDB.GetGroupsByUserID(test.userID, &api.Filter{})
 		if test.expectedError != nil {
 			dbError, ok := err.(*database.Error)
 			if !ok || dbError == nil {
@@ -690,6 +1239,10 @@ func TestPostgresRepo_GetGroupsByUserID(t *testing.T) {
 				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
 				continue
 			}
+			if total != len(test.expectedResponse) {
+				t.Errorf("Test %v failed. Received different total (wanted:%v / received:%v)", n, len(test.expectedResponse), total)
+				continue
+			}
 		}
 
 	}
@@ -0,0 +1,315 @@
+package postgresql
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+// WEBHOOK REPOSITORY IMPLEMENTATION
+
+func (w PostgresRepo) AddWebhook(webhook api.Webhook) (*api.Webhook, error) {
+	// Create webhook model
+	webhookDB := apiWebhookToDBWebhook(webhook)
+
+	// Store webhook
+	err := w.Dbmap.Create(webhookDB).Error
+
+	// Error handling
+	if err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbWebhookToAPIWebhook(webhookDB), nil
+}
+
+func (w PostgresRepo) GetWebhookByID(id string) (*api.Webhook, error) {
+	webhook := &Webhook{}
+	query := w.Dbmap.Where("id like ?", id).First(webhook)
+
+	// Check if webhook exists
+	if query.RecordNotFound() {
+		return nil, &database.Error{
+			Code:    database.WEBHOOK_NOT_FOUND,
+			Message: fmt.Sprintf("Webhook with id %v not found", id),
+		}
+	}
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbWebhookToAPIWebhook(webhook), nil
+}
+
+func (w PostgresRepo) GetWebhooksFiltered(filter *api.Filter) ([]api.Webhook, int, error) {
+	webhooks := []Webhook{}
+
+	// Count webhooks and retrieve the requested page
+	query, total, err := paginate(w.Dbmap, &Webhook{}, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Error handling
+	if err := query.Order("create_at").Order("id").Find(&webhooks).Error; err != nil {
+		return nil, 0, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Transform webhooks for API
+	apiWebhooks := make([]api.Webhook, len(webhooks), cap(webhooks))
+	for i, wh := range webhooks {
+		apiWebhooks[i] = *dbWebhookToAPIWebhook(&wh)
+	}
+
+	return apiWebhooks, total, nil
+}
+
+func (w PostgresRepo) UpdateWebhook(webhook api.Webhook) (*api.Webhook, error) {
+	webhookDB := apiWebhookToDBWebhook(webhook)
+
+	// Update webhook
+	query := w.Dbmap.Model(&Webhook{ID: webhookDB.ID}).Updates(map[string]interface{}{
+		"url":       webhookDB.Url,
+		"secret":    webhookDB.Secret,
+		"events":    webhookDB.Events,
+		"update_at": webhookDB.UpdateAt,
+	})
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbWebhookToAPIWebhook(webhookDB), nil
+}
+
+func (w PostgresRepo) RemoveWebhook(id string) error {
+	transaction := w.begin()
+	// Delete webhook
+	transaction.Where("id like ?", id).Delete(&Webhook{})
+
+	// Error handling
+	if err := transaction.Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Delete all webhook deliveries
+	transaction.Where("webhook_id like ?", id).Delete(&WebhookDelivery{})
+
+	// Error handling
+	if err := transaction.Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	transaction.Commit()
+	return nil
+}
+
+func (w PostgresRepo) AddWebhookDelivery(delivery api.WebhookDelivery) (*api.WebhookDelivery, error) {
+	// Create webhook delivery model
+	deliveryDB := apiWebhookDeliveryToDBWebhookDelivery(delivery)
+
+	// Store webhook delivery
+	err := w.Dbmap.Create(deliveryDB).Error
+
+	// Error handling
+	if err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbWebhookDeliveryToAPIWebhookDelivery(deliveryDB), nil
+}
+
+func (w PostgresRepo) UpdateWebhookDelivery(delivery api.WebhookDelivery) (*api.WebhookDelivery, error) {
+	deliveryDB := apiWebhookDeliveryToDBWebhookDelivery(delivery)
+
+	// Update webhook delivery. Values are updated with a map because they can be empty
+	query := w.Dbmap.Model(&WebhookDelivery{ID: deliveryDB.ID}).Updates(map[string]interface{}{
+		"status":          deliveryDB.Status,
+		"attempts":        deliveryDB.Attempts,
+		"status_code":     deliveryDB.StatusCode,
+		"last_error":      deliveryDB.LastError,
+		"update_at":       deliveryDB.UpdateAt,
+		"next_attempt_at": deliveryDB.NextAttemptAt,
+	})
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbWebhookDeliveryToAPIWebhookDelivery(deliveryDB), nil
+}
+
+func (w PostgresRepo) GetWebhookDeliveriesFiltered(webhookID string, status string, filter *api.Filter) ([]api.WebhookDelivery, int, error) {
+	deliveries := []WebhookDelivery{}
+	query := w.Dbmap.Where("webhook_id like ?", webhookID)
+	if len(status) > 0 {
+		query = query.Where("status like ?", status)
+	}
+
+	// Count webhook deliveries and retrieve the requested page
+	query, total, err := paginate(query, &WebhookDelivery{}, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Error handling. Last created deliveries are returned first
+	if err := query.Order("create_at desc").Order("id").Find(&deliveries).Error; err != nil {
+		return nil, 0, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Transform webhook deliveries for API
+	apiDeliveries := make([]api.WebhookDelivery, len(deliveries), cap(deliveries))
+	for i, d := range deliveries {
+		apiDeliveries[i] = *dbWebhookDeliveryToAPIWebhookDelivery(&d)
+	}
+
+	return apiDeliveries, total, nil
+}
+
+func (w PostgresRepo) ClaimPendingWebhookDeliveries(before time.Time, leaseUntil time.Time, limit int) ([]api.WebhookDelivery, error) {
+	transaction := w.begin()
+
+	// Lock the due deliveries, skipping the ones locked by concurrent claims. Oldest deliveries are sent first
+	deliveries := []WebhookDelivery{}
+	query := transaction.Set("gorm:query_option", "FOR UPDATE SKIP LOCKED").
+		Where("status like ? AND next_attempt_at <= ?", api.WEBHOOK_DELIVERY_STATUS_PENDING, before.UnixNano())
+	if err := query.Order("create_at").Order("id").Limit(limit).Find(&deliveries).Error; err != nil {
+		transaction.Rollback()
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Postpone their next attempt until the lease ends, so they aren't due for other claims after the commit
+	ids := make([]string, len(deliveries))
+	for i := range deliveries {
+		ids[i] = deliveries[i].ID
+		deliveries[i].NextAttemptAt = leaseUntil.UnixNano()
+	}
+	if len(ids) > 0 {
+		err := transaction.Model(&WebhookDelivery{}).Where("id in (?)", ids).Update("next_attempt_at", leaseUntil.UnixNano()).Error
+		if err != nil {
+			transaction.Rollback()
+			return nil, &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: err.Error(),
+			}
+		}
+	}
+
+	if err := transaction.Commit().Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Transform webhook deliveries for API
+	apiDeliveries := make([]api.WebhookDelivery, len(deliveries), cap(deliveries))
+	for i, d := range deliveries {
+		apiDeliveries[i] = *dbWebhookDeliveryToAPIWebhookDelivery(&d)
+	}
+
+	return apiDeliveries, nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Transform a webhook from API into a webhook for db
+func apiWebhookToDBWebhook(webhook api.Webhook) *Webhook {
+	return &Webhook{
+		ID:       webhook.ID,
+		Url:      webhook.Url,
+		Secret:   webhook.Secret,
+		Events:   strings.Join(webhook.Events, ";"),
+		CreateAt: webhook.CreateAt.UTC().UnixNano(),
+		UpdateAt: webhook.UpdateAt.UTC().UnixNano(),
+	}
+}
+
+// Transform a webhook retrieved from db into a webhook for API
+func dbWebhookToAPIWebhook(webhookDB *Webhook) *api.Webhook {
+	return &api.Webhook{
+		ID:       webhookDB.ID,
+		Url:      webhookDB.Url,
+		Secret:   webhookDB.Secret,
+		Events:   strings.Split(webhookDB.Events, ";"),
+		CreateAt: time.Unix(0, webhookDB.CreateAt).UTC(),
+		UpdateAt: time.Unix(0, webhookDB.UpdateAt).UTC(),
+	}
+}
+
+// Transform a webhook delivery from API into a webhook delivery for db
+func apiWebhookDeliveryToDBWebhookDelivery(delivery api.WebhookDelivery) *WebhookDelivery {
+	return &WebhookDelivery{
+		ID:            delivery.ID,
+		WebhookID:     delivery.WebhookID,
+		EventID:       delivery.EventID,
+		Action:        delivery.Action,
+		Urn:           delivery.Urn,
+		Payload:       string(delivery.Payload),
+		Status:        delivery.Status,
+		Attempts:      delivery.Attempts,
+		StatusCode:    delivery.StatusCode,
+		LastError:     delivery.LastError,
+		CreateAt:      delivery.CreateAt.UTC().UnixNano(),
+		UpdateAt:      delivery.UpdateAt.UTC().UnixNano(),
+		NextAttemptAt: delivery.NextAttemptAt.UTC().UnixNano(),
+	}
+}
+
+// Transform a webhook delivery retrieved from db into a webhook delivery for API
+func dbWebhookDeliveryToAPIWebhookDelivery(deliveryDB *WebhookDelivery) *api.WebhookDelivery {
+	return &api.WebhookDelivery{
+		ID:            deliveryDB.ID,
+		WebhookID:     deliveryDB.WebhookID,
+		EventID:       deliveryDB.EventID,
+		Action:        deliveryDB.Action,
+		Urn:           deliveryDB.Urn,
+		Payload:       toJSONDocument(deliveryDB.Payload),
+		Status:        deliveryDB.Status,
+		Attempts:      deliveryDB.Attempts,
+		StatusCode:    deliveryDB.StatusCode,
+		LastError:     deliveryDB.LastError,
+		CreateAt:      time.Unix(0, deliveryDB.CreateAt).UTC(),
+		UpdateAt:      time.Unix(0, deliveryDB.UpdateAt).UTC(),
+		NextAttemptAt: time.Unix(0, deliveryDB.NextAttemptAt).UTC(),
+	}
+}
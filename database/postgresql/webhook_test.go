@@ -0,0 +1,844 @@
+package postgresql
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+	"github.com/tecsisa/foulkon/database"
+)
+
+func TestPostgresRepo_AddWebhook(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Postgres Repo Args
+		webhook api.Webhook
+		// Expected result
+		expectedResponse *api.Webhook
+	}{
+		"OkCase": {
+			webhook: api.Webhook{
+				ID:       "WebhookID",
+				Url:      "https://example.com/hook",
+				Secret:   "secret",
+				Events:   []string{api.USER_ACTION_CREATE_USER, "iam:Delete*"},
+				CreateAt: now,
+				UpdateAt: now,
+			},
+			expectedResponse: &api.Webhook{
+				ID:       "WebhookID",
+				Url:      "https://example.com/hook",
+				Secret:   "secret",
+				Events:   []string{api.USER_ACTION_CREATE_USER, "iam:Delete*"},
+				CreateAt: now,
+				UpdateAt: now,
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean webhook database
+		cleanWebhookTable()
+
+		// Call to repository to store webhook
+		webhook, err := repoDB.AddWebhook(test.webhook)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if diff := pretty.Compare(webhook, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+		// Check database
+		webhookNumber, err := getWebhooksCountFiltered(test.webhook.ID, test.webhook.Url)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting webhooks: %v", n, err)
+			continue
+		}
+		if webhookNumber != 1 {
+			t.Errorf("Test %v failed. Received different webhook number: %v", n, webhookNumber)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_GetWebhookByID(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousWebhook *Webhook
+		// Postgres Repo Args
+		id string
+		// Expected result
+		expectedResponse *api.Webhook
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			previousWebhook: &Webhook{
+				ID:       "WebhookID",
+				Url:      "https://example.com/hook",
+				Secret:   "secret",
+				Events:   "iam:*",
+				CreateAt: now.UnixNano(),
+				UpdateAt: now.UnixNano(),
+			},
+			id: "WebhookID",
+			expectedResponse: &api.Webhook{
+				ID:       "WebhookID",
+				Url:      "https://example.com/hook",
+				Secret:   "secret",
+				Events:   []string{"iam:*"},
+				CreateAt: now,
+				UpdateAt: now,
+			},
+		},
+		"ErrorCaseNotFound": {
+			id: "WebhookID",
+			expectedError: &database.Error{
+				Code:    database.WEBHOOK_NOT_FOUND,
+				Message: "Webhook with id WebhookID not found",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean webhook database
+		cleanWebhookTable()
+
+		// Insert previous data
+		if test.previousWebhook != nil {
+			if err := insertWebhook(*test.previousWebhook); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+		// Call to repository to get webhook
+		webhook, err := repoDB.GetWebhookByID(test.id)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(webhook, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_GetWebhooksFiltered(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousWebhooks []Webhook
+		// Postgres Repo Args
+		filter *api.Filter
+		// Expected result
+		expectedResponse []api.Webhook
+		expectedTotal    int
+	}{
+		"OkCaseAll": {
+			previousWebhooks: []Webhook{
+				{
+					ID:       "WebhookID2",
+					Url:      "https://example.com/hook2",
+					Secret:   "secret",
+					Events:   "iam:*",
+					CreateAt: now.UnixNano(),
+					UpdateAt: now.UnixNano(),
+				},
+				{
+					ID:       "WebhookID1",
+					Url:      "https://example.com/hook1",
+					Secret:   "secret",
+					Events:   "iam:*",
+					CreateAt: now.Add(-time.Hour).UnixNano(),
+					UpdateAt: now.Add(-time.Hour).UnixNano(),
+				},
+			},
+			filter: &api.Filter{},
+			expectedResponse: []api.Webhook{
+				{
+					ID:       "WebhookID1",
+					Url:      "https://example.com/hook1",
+					Secret:   "secret",
+					Events:   []string{"iam:*"},
+					CreateAt: now.Add(-time.Hour),
+					UpdateAt: now.Add(-time.Hour),
+				},
+				{
+					ID:       "WebhookID2",
+					Url:      "https://example.com/hook2",
+					Secret:   "secret",
+					Events:   []string{"iam:*"},
+					CreateAt: now,
+					UpdateAt: now,
+				},
+			},
+			expectedTotal: 2,
+		},
+		"OkCasePaginated": {
+			previousWebhooks: []Webhook{
+				{
+					ID:       "WebhookID2",
+					Url:      "https://example.com/hook2",
+					Secret:   "secret",
+					Events:   "iam:*",
+					CreateAt: now.UnixNano(),
+					UpdateAt: now.UnixNano(),
+				},
+				{
+					ID:       "WebhookID1",
+					Url:      "https://example.com/hook1",
+					Secret:   "secret",
+					Events:   "iam:*",
+					CreateAt: now.Add(-time.Hour).UnixNano(),
+					UpdateAt: now.Add(-time.Hour).UnixNano(),
+				},
+			},
+			filter: &api.Filter{
+				Offset: 1,
+				Limit:  1,
+			},
+			expectedResponse: []api.Webhook{
+				{
+					ID:       "WebhookID2",
+					Url:      "https://example.com/hook2",
+					Secret:   "secret",
+					Events:   []string{"iam:*"},
+					CreateAt: now,
+					UpdateAt: now,
+				},
+			},
+			expectedTotal: 2,
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean webhook database
+		cleanWebhookTable()
+
+		// Insert previous data
+		for _, webhook := range test.previousWebhooks {
+			if err := insertWebhook(webhook); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+		// Call to repository to get webhooks
+		webhooks, total, err := repoDB.GetWebhooksFiltered(test.filter)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if total != test.expectedTotal {
+			t.Errorf("Test %v failed. Received different total: %v", n, total)
+			continue
+		}
+		if diff := pretty.Compare(webhooks, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_UpdateWebhook(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousWebhook *Webhook
+		// Postgres Repo Args
+		webhook api.Webhook
+		// Expected result
+		expectedResponse *api.Webhook
+	}{
+		"OkCase": {
+			previousWebhook: &Webhook{
+				ID:       "WebhookID",
+				Url:      "https://example.com/hook",
+				Secret:   "secret",
+				Events:   "iam:*",
+				CreateAt: now.UnixNano(),
+				UpdateAt: now.UnixNano(),
+			},
+			webhook: api.Webhook{
+				ID:       "WebhookID",
+				Url:      "https://example.com/newhook",
+				Secret:   "newsecret",
+				Events:   []string{api.USER_ACTION_CREATE_USER},
+				CreateAt: now,
+				UpdateAt: now.Add(time.Hour),
+			},
+			expectedResponse: &api.Webhook{
+				ID:       "WebhookID",
+				Url:      "https://example.com/newhook",
+				Secret:   "newsecret",
+				Events:   []string{api.USER_ACTION_CREATE_USER},
+				CreateAt: now,
+				UpdateAt: now.Add(time.Hour),
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean webhook database
+		cleanWebhookTable()
+
+		// Insert previous data
+		if err := insertWebhook(*test.previousWebhook); err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+			continue
+		}
+		// Call to repository to update webhook
+		webhook, err := repoDB.UpdateWebhook(test.webhook)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if diff := pretty.Compare(webhook, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+		// Check database
+		stored, err := repoDB.GetWebhookByID(test.webhook.ID)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error retrieving webhook: %v", n, err)
+			continue
+		}
+		if diff := pretty.Compare(stored, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different stored webhook (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_RemoveWebhook(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousWebhooks   []Webhook
+		previousDeliveries []WebhookDelivery
+		// Postgres Repo Args
+		id string
+	}{
+		"OkCase": {
+			previousWebhooks: []Webhook{
+				{
+					ID:       "WebhookID1",
+					Url:      "https://example.com/hook1",
+					Secret:   "secret",
+					Events:   "iam:*",
+					CreateAt: now.UnixNano(),
+					UpdateAt: now.UnixNano(),
+				},
+				{
+					ID:       "WebhookID2",
+					Url:      "https://example.com/hook2",
+					Secret:   "secret",
+					Events:   "iam:*",
+					CreateAt: now.UnixNano(),
+					UpdateAt: now.UnixNano(),
+				},
+			},
+			previousDeliveries: []WebhookDelivery{
+				{
+					ID:            "DeliveryID1",
+					WebhookID:     "WebhookID1",
+					EventID:       "EventID",
+					Action:        api.USER_ACTION_CREATE_USER,
+					Urn:           "urn:user",
+					Payload:       "{}",
+					Status:        api.WEBHOOK_DELIVERY_STATUS_PENDING,
+					CreateAt:      now.UnixNano(),
+					UpdateAt:      now.UnixNano(),
+					NextAttemptAt: now.UnixNano(),
+				},
+				{
+					ID:            "DeliveryID2",
+					WebhookID:     "WebhookID2",
+					EventID:       "EventID",
+					Action:        api.USER_ACTION_CREATE_USER,
+					Urn:           "urn:user",
+					Payload:       "{}",
+					Status:        api.WEBHOOK_DELIVERY_STATUS_PENDING,
+					CreateAt:      now.UnixNano(),
+					UpdateAt:      now.UnixNano(),
+					NextAttemptAt: now.UnixNano(),
+				},
+			},
+			id: "WebhookID1",
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean webhook database
+		cleanWebhookTable()
+		cleanWebhookDeliveryTable()
+
+		// Insert previous data
+		for _, webhook := range test.previousWebhooks {
+			if err := insertWebhook(webhook); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+		for _, delivery := range test.previousDeliveries {
+			if err := insertWebhookDelivery(delivery); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+		// Call to repository to remove webhook
+		if err := repoDB.RemoveWebhook(test.id); err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		// Check database. Other webhooks and their deliveries are kept
+		webhookNumber, err := getWebhooksCountFiltered(test.id, "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting webhooks: %v", n, err)
+			continue
+		}
+		if webhookNumber != 0 {
+			t.Errorf("Test %v failed. Received different webhook number: %v", n, webhookNumber)
+			continue
+		}
+		deliveryNumber, err := getWebhookDeliveriesCountFiltered("", test.id, "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting webhook deliveries: %v", n, err)
+			continue
+		}
+		if deliveryNumber != 0 {
+			t.Errorf("Test %v failed. Received different webhook delivery number: %v", n, deliveryNumber)
+			continue
+		}
+		totalWebhooks, err := getWebhooksCountFiltered("", "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting webhooks: %v", n, err)
+			continue
+		}
+		totalDeliveries, err := getWebhookDeliveriesCountFiltered("", "", "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting webhook deliveries: %v", n, err)
+			continue
+		}
+		if totalWebhooks != len(test.previousWebhooks)-1 || totalDeliveries != 1 {
+			t.Errorf("Test %v failed. Received different number of webhooks %v and deliveries %v", n, totalWebhooks, totalDeliveries)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_AddWebhookDelivery(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Postgres Repo Args
+		delivery api.WebhookDelivery
+		// Expected result
+		expectedResponse *api.WebhookDelivery
+	}{
+		"OkCase": {
+			delivery: api.WebhookDelivery{
+				ID:            "DeliveryID",
+				WebhookID:     "WebhookID",
+				EventID:       "EventID",
+				Action:        api.USER_ACTION_CREATE_USER,
+				Urn:           "urn:user",
+				Payload:       json.RawMessage(`{"action":"iam:CreateUser"}`),
+				Status:        api.WEBHOOK_DELIVERY_STATUS_PENDING,
+				CreateAt:      now,
+				UpdateAt:      now,
+				NextAttemptAt: now,
+			},
+			expectedResponse: &api.WebhookDelivery{
+				ID:            "DeliveryID",
+				WebhookID:     "WebhookID",
+				EventID:       "EventID",
+				Action:        api.USER_ACTION_CREATE_USER,
+				Urn:           "urn:user",
+				Payload:       json.RawMessage(`{"action":"iam:CreateUser"}`),
+				Status:        api.WEBHOOK_DELIVERY_STATUS_PENDING,
+				CreateAt:      now,
+				UpdateAt:      now,
+				NextAttemptAt: now,
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean webhook delivery database
+		cleanWebhookDeliveryTable()
+
+		// Call to repository to store webhook delivery
+		delivery, err := repoDB.AddWebhookDelivery(test.delivery)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if diff := pretty.Compare(delivery, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+		// Check database
+		deliveryNumber, err := getWebhookDeliveriesCountFiltered(test.delivery.ID, test.delivery.WebhookID, test.delivery.Status)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting webhook deliveries: %v", n, err)
+			continue
+		}
+		if deliveryNumber != 1 {
+			t.Errorf("Test %v failed. Received different webhook delivery number: %v", n, deliveryNumber)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_UpdateWebhookDelivery(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		previousDelivery *WebhookDelivery
+		// Postgres Repo Args
+		delivery api.WebhookDelivery
+		// Expected result
+		expectedResponse *api.WebhookDelivery
+	}{
+		"OkCase": {
+			previousDelivery: &WebhookDelivery{
+				ID:            "DeliveryID",
+				WebhookID:     "WebhookID",
+				EventID:       "EventID",
+				Action:        api.USER_ACTION_CREATE_USER,
+				Urn:           "urn:user",
+				Payload:       "{}",
+				Status:        api.WEBHOOK_DELIVERY_STATUS_PENDING,
+				Attempts:      1,
+				StatusCode:    500,
+				LastError:     "Unexpected status code 500",
+				CreateAt:      now.UnixNano(),
+				UpdateAt:      now.UnixNano(),
+				NextAttemptAt: now.UnixNano(),
+			},
+			delivery: api.WebhookDelivery{
+				ID:            "DeliveryID",
+				WebhookID:     "WebhookID",
+				EventID:       "EventID",
+				Action:        api.USER_ACTION_CREATE_USER,
+				Urn:           "urn:user",
+				Payload:       json.RawMessage("{}"),
+				Status:        api.WEBHOOK_DELIVERY_STATUS_DELIVERED,
+				Attempts:      2,
+				StatusCode:    200,
+				CreateAt:      now,
+				UpdateAt:      now.Add(time.Minute),
+				NextAttemptAt: now,
+			},
+			expectedResponse: &api.WebhookDelivery{
+				ID:            "DeliveryID",
+				WebhookID:     "WebhookID",
+				EventID:       "EventID",
+				Action:        api.USER_ACTION_CREATE_USER,
+				Urn:           "urn:user",
+				Payload:       json.RawMessage("{}"),
+				Status:        api.WEBHOOK_DELIVERY_STATUS_DELIVERED,
+				Attempts:      2,
+				StatusCode:    200,
+				CreateAt:      now,
+				UpdateAt:      now.Add(time.Minute),
+				NextAttemptAt: now,
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean webhook delivery database
+		cleanWebhookDeliveryTable()
+
+		// Insert previous data
+		if err := insertWebhookDelivery(*test.previousDelivery); err != nil {
+			t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+			continue
+		}
+		// Call to repository to update webhook delivery
+		delivery, err := repoDB.UpdateWebhookDelivery(test.delivery)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if diff := pretty.Compare(delivery, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+		// Check database. Last error must be cleaned
+		deliveries, _, err := repoDB.GetWebhookDeliveriesFiltered(test.delivery.WebhookID, test.delivery.Status, &api.Filter{})
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error retrieving webhook deliveries: %v", n, err)
+			continue
+		}
+		if diff := pretty.Compare(deliveries, []api.WebhookDelivery{*test.expectedResponse}); diff != "" {
+			t.Errorf("Test %v failed. Received different stored webhook deliveries (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_GetWebhookDeliveriesFiltered(t *testing.T) {
+	now := time.Now().UTC()
+	previousDeliveries := []WebhookDelivery{
+		{
+			ID:            "DeliveryID1",
+			WebhookID:     "WebhookID",
+			EventID:       "EventID1",
+			Action:        api.USER_ACTION_CREATE_USER,
+			Urn:           "urn:user",
+			Payload:       "{}",
+			Status:        api.WEBHOOK_DELIVERY_STATUS_FAILED,
+			Attempts:      api.WEBHOOK_MAX_DELIVERY_ATTEMPTS,
+			CreateAt:      now.Add(-time.Hour).UnixNano(),
+			UpdateAt:      now.Add(-time.Hour).UnixNano(),
+			NextAttemptAt: now.Add(-time.Hour).UnixNano(),
+		},
+		{
+			ID:            "DeliveryID2",
+			WebhookID:     "WebhookID",
+			EventID:       "EventID2",
+			Action:        api.USER_ACTION_DELETE_USER,
+			Urn:           "urn:user",
+			Payload:       "{}",
+			Status:        api.WEBHOOK_DELIVERY_STATUS_DELIVERED,
+			Attempts:      1,
+			CreateAt:      now.UnixNano(),
+			UpdateAt:      now.UnixNano(),
+			NextAttemptAt: now.UnixNano(),
+		},
+		{
+			ID:            "DeliveryID3",
+			WebhookID:     "OtherWebhookID",
+			EventID:       "EventID2",
+			Action:        api.USER_ACTION_DELETE_USER,
+			Urn:           "urn:user",
+			Payload:       "{}",
+			Status:        api.WEBHOOK_DELIVERY_STATUS_DELIVERED,
+			Attempts:      1,
+			CreateAt:      now.UnixNano(),
+			UpdateAt:      now.UnixNano(),
+			NextAttemptAt: now.UnixNano(),
+		},
+	}
+	testcases := map[string]struct {
+		// Postgres Repo Args
+		webhookID string
+		status    string
+		filter    *api.Filter
+		// Expected result
+		expectedResponse []api.WebhookDelivery
+		expectedTotal    int
+	}{
+		"OkCaseAll": {
+			webhookID: "WebhookID",
+			filter:    &api.Filter{},
+			expectedResponse: []api.WebhookDelivery{
+				{
+					ID:            "DeliveryID2",
+					WebhookID:     "WebhookID",
+					EventID:       "EventID2",
+					Action:        api.USER_ACTION_DELETE_USER,
+					Urn:           "urn:user",
+					Payload:       json.RawMessage("{}"),
+					Status:        api.WEBHOOK_DELIVERY_STATUS_DELIVERED,
+					Attempts:      1,
+					CreateAt:      now,
+					UpdateAt:      now,
+					NextAttemptAt: now,
+				},
+				{
+					ID:            "DeliveryID1",
+					WebhookID:     "WebhookID",
+					EventID:       "EventID1",
+					Action:        api.USER_ACTION_CREATE_USER,
+					Urn:           "urn:user",
+					Payload:       json.RawMessage("{}"),
+					Status:        api.WEBHOOK_DELIVERY_STATUS_FAILED,
+					Attempts:      api.WEBHOOK_MAX_DELIVERY_ATTEMPTS,
+					CreateAt:      now.Add(-time.Hour),
+					UpdateAt:      now.Add(-time.Hour),
+					NextAttemptAt: now.Add(-time.Hour),
+				},
+			},
+			expectedTotal: 2,
+		},
+		"OkCaseFilteredByStatus": {
+			webhookID: "WebhookID",
+			status:    api.WEBHOOK_DELIVERY_STATUS_FAILED,
+			filter:    &api.Filter{},
+			expectedResponse: []api.WebhookDelivery{
+				{
+					ID:            "DeliveryID1",
+					WebhookID:     "WebhookID",
+					EventID:       "EventID1",
+					Action:        api.USER_ACTION_CREATE_USER,
+					Urn:           "urn:user",
+					Payload:       json.RawMessage("{}"),
+					Status:        api.WEBHOOK_DELIVERY_STATUS_FAILED,
+					Attempts:      api.WEBHOOK_MAX_DELIVERY_ATTEMPTS,
+					CreateAt:      now.Add(-time.Hour),
+					UpdateAt:      now.Add(-time.Hour),
+					NextAttemptAt: now.Add(-time.Hour),
+				},
+			},
+			expectedTotal: 1,
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean webhook delivery database
+		cleanWebhookDeliveryTable()
+
+		// Insert previous data
+		for _, delivery := range previousDeliveries {
+			if err := insertWebhookDelivery(delivery); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+		// Call to repository to get webhook deliveries
+		deliveries, total, err := repoDB.GetWebhookDeliveriesFiltered(test.webhookID, test.status, test.filter)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if total != test.expectedTotal {
+			t.Errorf("Test %v failed. Received different total: %v", n, total)
+			continue
+		}
+		if diff := pretty.Compare(deliveries, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_ClaimPendingWebhookDeliveries(t *testing.T) {
+	now := time.Now().UTC()
+	leaseUntil := now.Add(api.WEBHOOK_DELIVERY_LEASE)
+	testcases := map[string]struct {
+		// Previous data
+		previousDeliveries []WebhookDelivery
+		// Postgres Repo Args
+		before     time.Time
+		leaseUntil time.Time
+		limit      int
+		// Expected result
+		expectedResponse []api.WebhookDelivery
+	}{
+		"OkCase": {
+			previousDeliveries: []WebhookDelivery{
+				{
+					ID:            "DeliveryID1",
+					WebhookID:     "WebhookID",
+					EventID:       "EventID1",
+					Action:        api.USER_ACTION_CREATE_USER,
+					Urn:           "urn:user",
+					Payload:       "{}",
+					Status:        api.WEBHOOK_DELIVERY_STATUS_PENDING,
+					CreateAt:      now.Add(-time.Hour).UnixNano(),
+					UpdateAt:      now.Add(-time.Hour).UnixNano(),
+					NextAttemptAt: now.Add(-time.Hour).UnixNano(),
+				},
+				// Next attempt isn't due
+				{
+					ID:            "DeliveryID2",
+					WebhookID:     "WebhookID",
+					EventID:       "EventID2",
+					Action:        api.USER_ACTION_CREATE_USER,
+					Urn:           "urn:user",
+					Payload:       "{}",
+					Status:        api.WEBHOOK_DELIVERY_STATUS_PENDING,
+					Attempts:      1,
+					CreateAt:      now.Add(-time.Hour).UnixNano(),
+					UpdateAt:      now.UnixNano(),
+					NextAttemptAt: now.Add(time.Minute).UnixNano(),
+				},
+				// Already delivered
+				{
+					ID:            "DeliveryID3",
+					WebhookID:     "WebhookID",
+					EventID:       "EventID3",
+					Action:        api.USER_ACTION_CREATE_USER,
+					Urn:           "urn:user",
+					Payload:       "{}",
+					Status:        api.WEBHOOK_DELIVERY_STATUS_DELIVERED,
+					Attempts:      1,
+					CreateAt:      now.Add(-time.Hour).UnixNano(),
+					UpdateAt:      now.UnixNano(),
+					NextAttemptAt: now.Add(-time.Hour).UnixNano(),
+				},
+			},
+			before:     now,
+			leaseUntil: leaseUntil,
+			limit:      10,
+			expectedResponse: []api.WebhookDelivery{
+				{
+					ID:            "DeliveryID1",
+					WebhookID:     "WebhookID",
+					EventID:       "EventID1",
+					Action:        api.USER_ACTION_CREATE_USER,
+					Urn:           "urn:user",
+					Payload:       json.RawMessage("{}"),
+					Status:        api.WEBHOOK_DELIVERY_STATUS_PENDING,
+					CreateAt:      now.Add(-time.Hour),
+					UpdateAt:      now.Add(-time.Hour),
+					NextAttemptAt: leaseUntil,
+				},
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean webhook delivery database
+		cleanWebhookDeliveryTable()
+
+		// Insert previous data
+		for _, delivery := range test.previousDeliveries {
+			if err := insertWebhookDelivery(delivery); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+				continue
+			}
+		}
+		// Call to repository to claim pending webhook deliveries
+		deliveries, err := repoDB.ClaimPendingWebhookDeliveries(test.before, test.leaseUntil, test.limit)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if diff := pretty.Compare(deliveries, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+
+		// Claimed deliveries aren't claimed again until the lease ends
+		claimed, err := repoDB.ClaimPendingWebhookDeliveries(test.before, test.leaseUntil, test.limit)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if len(claimed) > 0 {
+			t.Errorf("Test %v failed. Deliveries claimed twice %v", n, claimed)
+			continue
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package foulkon
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/pelletier/go-toml"
+	"github.com/tecsisa/foulkon/api"
+)
+
+// Decisions waiting to be sent to the webhook. Newer decisions are dropped when it's full
+const decisionWebhookBufferSize = 1000
+
+var decision_logfile *os.File
+
+// Create the authorization decision logger using configuration values. It returns nil if decisions
+// aren't recorded
+func newDecisionLogger(config *toml.TomlTree, logger *log.Logger) (api.DecisionLogger, error) {
+	var decisionLogger api.DecisionLogger
+	decisionLogType := getDefaultValue(config, "decisionlog.type", "none")
+	switch decisionLogType {
+	case "", "none":
+		return nil, nil
+	case "stdout":
+		decisionLogger = &jsonDecisionLogger{out: os.Stdout, logger: logger}
+	case "file":
+		decisionLogFileDir, err := getMandatoryValue(config, "decisionlog.file.dir")
+		if err != nil {
+			return nil, err
+		}
+		decision_logfile, err = os.OpenFile(decisionLogFileDir, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0666)
+		if err != nil {
+			return nil, err
+		}
+		decisionLogger = &jsonDecisionLogger{out: decision_logfile, logger: logger}
+	case "webhook":
+		url, err := getMandatoryValue(config, "decisionlog.webhook.url")
+		if err != nil {
+			return nil, err
+		}
+		timeout, err := time.ParseDuration(getDefaultValue(config, "decisionlog.webhook.timeout", "5s"))
+		if err != nil {
+			return nil, err
+		}
+		decisionLogger = newWebhookDecisionLogger(url, timeout, logger)
+	default:
+		return nil, errors.New(fmt.Sprintf("Unexpected decision log type %v", decisionLogType))
+	}
+
+	// Rate of decisions recorded. Defaults to all of them
+	sampleRate, err := strconv.ParseFloat(getDefaultValue(config, "decisionlog.samplerate", "1"), 64)
+	if err != nil {
+		return nil, err
+	}
+	if sampleRate <= 0 || sampleRate > 1 {
+		return nil, errors.New(fmt.Sprintf("Unexpected decision log sample rate %v", sampleRate))
+	}
+	logger.Infof("Decision log type: %v, sample rate: %v", decisionLogType, sampleRate)
+	if sampleRate < 1 {
+		return &sampledDecisionLogger{rate: sampleRate, next: decisionLogger}, nil
+	}
+
+	return decisionLogger, nil
+}
+
+// Decision logger that writes every decision as a JSON line
+type jsonDecisionLogger struct {
+	mutex  sync.Mutex
+	out    io.Writer
+	logger *log.Logger
+}
+
+func (l *jsonDecisionLogger) LogDecision(decision api.AuthzDecision) {
+	line, err := json.Marshal(decision)
+	if err != nil {
+		l.logger.Errorf("Couldn't encode authorization decision %v: %v", decision, err)
+		return
+	}
+	// Lines of concurrent decisions mustn't be mixed
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if _, err := l.out.Write(append(line, '\n')); err != nil {
+		l.logger.Errorf("Couldn't write authorization decision %v: %v", decision, err)
+	}
+}
+
+// Decision logger that posts every decision as JSON to a webhook. Decisions are sent in the
+// background so the webhook latency doesn't delay the authorization
+type webhookDecisionLogger struct {
+	url       string
+	client    *http.Client
+	decisions chan api.AuthzDecision
+	logger    *log.Logger
+}
+
+func newWebhookDecisionLogger(url string, timeout time.Duration, logger *log.Logger) *webhookDecisionLogger {
+	l := &webhookDecisionLogger{
+		url:       url,
+		client:    &http.Client{Timeout: timeout},
+		decisions: make(chan api.AuthzDecision, decisionWebhookBufferSize),
+		logger:    logger,
+	}
+	go l.send()
+	return l
+}
+
+func (l *webhookDecisionLogger) LogDecision(decision api.AuthzDecision) {
+	select {
+	case l.decisions <- decision:
+	default:
+		l.logger.Errorf("Authorization decision %v dropped, webhook queue is full", decision)
+	}
+}
+
+func (l *webhookDecisionLogger) send() {
+	for decision := range l.decisions {
+		body, err := json.Marshal(decision)
+		if err != nil {
+			l.logger.Errorf("Couldn't encode authorization decision %v: %v", decision, err)
+			continue
+		}
+		res, err := l.client.Post(l.url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			l.logger.Errorf("Couldn't send authorization decision %v: %v", decision, err)
+			continue
+		}
+		res.Body.Close()
+		if res.StatusCode >= http.StatusBadRequest {
+			l.logger.Errorf("Authorization decision %v rejected by webhook with status %v", decision, res.StatusCode)
+		}
+	}
+}
+
+// Decision logger that only records a random sample of the decisions
+type sampledDecisionLogger struct {
+	rate float64
+	next api.DecisionLogger
+}
+
+func (l *sampledDecisionLogger) LogDecision(decision api.AuthzDecision) {
+	if rand.Float64() < l.rate {
+		l.next.LogDecision(decision)
+	}
+}
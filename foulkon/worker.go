@@ -2,6 +2,7 @@ package foulkon
 
 import (
 	"io"
+	"net/http"
 	"regexp"
 
 	"errors"
@@ -12,6 +13,8 @@ import (
 
 	"database/sql"
 
+	"time"
+
 	log "github.com/Sirupsen/logrus"
 	"github.com/pelletier/go-toml"
 	"github.com/tecsisa/foulkon/api"
@@ -35,11 +38,29 @@ type Worker struct {
 	CertFile string
 	KeyFile  string
 
+	// Reject updates and removals of users, groups and policies without If-Match header
+	RequireIfMatch bool
+
+	// Time between purges of deleted users, groups and policies whose retention period is over
+	TrashPurgeInterval time.Duration
+
+	// Time between deliveries of pending webhook events
+	WebhookInterval time.Duration
+
 	// APIs
-	UserApi   api.UserAPI
-	GroupApi  api.GroupAPI
-	PolicyApi api.PolicyAPI
-	AuthzApi  api.AuthzAPI
+	UserApi          api.UserAPI
+	GroupApi         api.GroupAPI
+	PolicyApi        api.PolicyAPI
+	AuthzApi         api.AuthzAPI
+	AccessRequestApi api.AccessRequestAPI
+	ActionApi        api.ActionAPI
+	ResourceTypeApi  api.ResourceTypeAPI
+	BatchApi         api.BatchAPI
+	OrganizationApi  api.OrganizationAPI
+	TrashApi         api.TrashAPI
+	ConsistencyApi   api.ConsistencyAPI
+	AuditApi         api.AuditAPI
+	WebhookApi       api.WebhookAPI
 
 	// Logger
 	Logger *log.Logger
@@ -111,9 +132,19 @@ func NewWorker(config *toml.TomlTree) (*Worker, error) {
 			Dbmap: gormDB,
 		}
 		authApi = api.AuthAPI{
-			GroupRepo:  repoDB,
-			UserRepo:   repoDB,
-			PolicyRepo: repoDB,
+			GroupRepo:         repoDB,
+			UserRepo:          repoDB,
+			PolicyRepo:        repoDB,
+			AccessRequestRepo: repoDB,
+			ActionRepo:        repoDB,
+			ResourceTypeRepo:  repoDB,
+			TagRepo:           repoDB,
+			TrashRepo:         repoDB,
+			ConsistencyRepo:   repoDB,
+			AuditRepo:         repoDB,
+			WebhookRepo:       repoDB,
+			OrganizationRepo:  repoDB,
+			TransactionRepo:   repoDB,
 		}
 
 	default:
@@ -124,6 +155,57 @@ func NewWorker(config *toml.TomlTree) (*Worker, error) {
 
 	authApi.Logger = logger
 
+	// Authorization decisions record. Defaults to none
+	authApi.DecisionLogger, err = newDecisionLogger(config, logger)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	// Reject policy statements with unregistered actions. Defaults to false
+	authApi.ValidateActions = getDefaultValue(config, "policy.validateactions", "false") == "true"
+	logger.Infof("Policy action validation against registry: %v", authApi.ValidateActions)
+	// Reject policy statements and authorization requests with unregistered resource types. Defaults to false
+	authApi.ValidateResources = getDefaultValue(config, "policy.validateresources", "false") == "true"
+	logger.Infof("Resource validation against registry: %v", authApi.ValidateResources)
+
+	// Time that removed users, groups and policies are kept in the trash. Defaults to 30 days
+	authApi.TrashRetention, err = time.ParseDuration(getDefaultValue(config, "trash.retention", "720h"))
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	trashPurgeInterval, err := time.ParseDuration(getDefaultValue(config, "trash.purgeinterval", "1h"))
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	if trashPurgeInterval <= 0 {
+		err := errors.New(fmt.Sprintf("Unexpected trash purge interval %v", trashPurgeInterval))
+		logger.Error(err)
+		return nil, err
+	}
+	logger.Infof("Trash retention: %v, purge interval: %v", authApi.TrashRetention, trashPurgeInterval)
+
+	// Timeout of each webhook request and time between deliveries of pending events
+	webhookTimeout, err := time.ParseDuration(getDefaultValue(config, "webhook.timeout", "5s"))
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	webhookDeliveryInterval, err := time.ParseDuration(getDefaultValue(config, "webhook.deliveryinterval", "10s"))
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	if webhookDeliveryInterval <= 0 {
+		err := errors.New(fmt.Sprintf("Unexpected webhook delivery interval %v", webhookDeliveryInterval))
+		logger.Error(err)
+		return nil, err
+	}
+	authApi.WebhookClient = &http.Client{Timeout: webhookTimeout}
+	logger.Infof("Webhook timeout: %v, delivery interval: %v", webhookTimeout, webhookDeliveryInterval)
+
 	// Instantiate Auth Connector
 	var authConnector auth.AuthConnector
 	authType, err := getMandatoryValue(config, "authenticator.type")
@@ -183,20 +265,66 @@ func NewWorker(config *toml.TomlTree) (*Worker, error) {
 		return nil, err
 	}
 
+	// Reject unconditional updates and removals. Defaults to false
+	requireIfMatch := getDefaultValue(config, "server.requireifmatch", "false") == "true"
+	logger.Infof("If-Match required on updates and removals: %v", requireIfMatch)
+
 	return &Worker{
-		Host:          host,
-		Port:          port,
-		CertFile:      getDefaultValue(config, "server.certfile", ""),
-		KeyFile:       getDefaultValue(config, "server.keyfile", ""),
-		Logger:        logger,
-		Authenticator: authenticator,
-		UserApi:       authApi,
-		GroupApi:      authApi,
-		PolicyApi:     authApi,
-		AuthzApi:      authApi,
+		Host:               host,
+		Port:               port,
+		CertFile:           getDefaultValue(config, "server.certfile", ""),
+		KeyFile:            getDefaultValue(config, "server.keyfile", ""),
+		RequireIfMatch:     requireIfMatch,
+		TrashPurgeInterval: trashPurgeInterval,
+		WebhookInterval:    webhookDeliveryInterval,
+		Logger:             logger,
+		Authenticator:      authenticator,
+		UserApi:            authApi,
+		GroupApi:           authApi,
+		PolicyApi:          authApi,
+		AuthzApi:           authApi,
+		AccessRequestApi:   authApi,
+		ActionApi:          authApi,
+		ResourceTypeApi:    authApi,
+		BatchApi:           authApi,
+		OrganizationApi:    authApi,
+		TrashApi:           authApi,
+		ConsistencyApi:     authApi,
+		AuditApi:           authApi,
+		WebhookApi:         authApi,
 	}, nil
 }
 
+// Purge the deleted users, groups and policies whose retention period is over every purge interval
+func (w *Worker) PurgeTrash() {
+	requestInfo := api.RequestInfo{
+		Identifier: "trash-purge",
+		Admin:      true,
+	}
+	ticker := time.NewTicker(w.TrashPurgeInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if _, err := w.TrashApi.PurgeDeletedResources(requestInfo); err != nil {
+			w.Logger.Errorf("Couldn't purge trash: %v", err)
+		}
+	}
+}
+
+// Deliver the pending webhook events every delivery interval
+func (w *Worker) DeliverWebhooks() {
+	requestInfo := api.RequestInfo{
+		Identifier: "webhook-delivery",
+		Admin:      true,
+	}
+	ticker := time.NewTicker(w.WebhookInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if _, err := w.WebhookApi.DeliverWebhookEvents(requestInfo); err != nil {
+			w.Logger.Errorf("Couldn't deliver webhook events: %v", err)
+		}
+	}
+}
+
 func CloseWorker() int {
 	status := 0
 	if err := db.Close(); err != nil {
@@ -207,6 +335,12 @@ func CloseWorker() int {
 		fmt.Fprintf(os.Stderr, "Couldn't close logfile: %v", err)
 		status = 1
 	}
+	if decision_logfile != nil {
+		if err := decision_logfile.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Couldn't close decision logfile: %v", err)
+			status = 1
+		}
+	}
 	return status
 }
 
@@ -0,0 +1,209 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/tecsisa/foulkon/api"
+)
+
+// REQUESTS
+
+type CreateAccessRequestRequest struct {
+	GroupName     string `json:"groupName, omitempty"`
+	PolicyName    string `json:"policyName, omitempty"`
+	Justification string `json:"justification, omitempty"`
+	Duration      string `json:"duration, omitempty"`
+}
+
+type ReviewAccessRequestRequest struct {
+	Comment string `json:"comment, omitempty"`
+}
+
+// RESPONSES
+
+type ListAccessRequestsResponse struct {
+	AccessRequests []api.AccessRequest `json:"accessRequests, omitempty"`
+}
+
+// HANDLERS
+
+func (h *WorkerHandler) HandleAddAccessRequest(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Decode request
+	request := CreateAccessRequestRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	duration, err := time.ParseDuration(request.Duration)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: duration %v", request.Duration),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	org := ps.ByName(ORG_NAME)
+	// Call access request API to create an access request
+	response, err := h.worker.AccessRequestApi.AddAccessRequest(requestInfo, org, request.GroupName, request.PolicyName,
+		request.Justification, duration)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.GROUP_BY_ORG_AND_NAME_NOT_FOUND, api.POLICY_BY_ORG_AND_NAME_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.USER_IS_ALREADY_A_MEMBER_OF_GROUP, api.GROUP_HAS_MEMBERSHIP_RULE:
+			h.RespondConflict(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Write access request to response
+	h.RespondCreated(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleGetAccessRequestByID(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve org and access request id from path
+	org := ps.ByName(ORG_NAME)
+	id := ps.ByName(ACCESS_REQUEST_ID)
+
+	// Call access request API to retrieve the access request
+	response, err := h.worker.AccessRequestApi.GetAccessRequestByID(requestInfo, org, id)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.ACCESS_REQUEST_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Write access request to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleListAccessRequests(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve org from path
+	org := ps.ByName(ORG_NAME)
+
+	// Retrieve query param if exists
+	status := r.URL.Query().Get("Status")
+
+	// Call access request API to retrieve access requests
+	result, err := h.worker.AccessRequestApi.ListAccessRequests(requestInfo, org, status)
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Create response
+	response := &ListAccessRequestsResponse{
+		AccessRequests: result,
+	}
+
+	// Return data
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleApproveAccessRequest(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	h.handleReviewAccessRequest(w, r, ps, h.worker.AccessRequestApi.ApproveAccessRequest)
+}
+
+func (h *WorkerHandler) HandleRejectAccessRequest(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	h.handleReviewAccessRequest(w, r, ps, h.worker.AccessRequestApi.RejectAccessRequest)
+}
+
+// Private Helper Methods
+
+// Decode review comment and call the given review method of the access request API
+func (h *WorkerHandler) handleReviewAccessRequest(w http.ResponseWriter, r *http.Request, ps httprouter.Params,
+	review func(requestInfo api.RequestInfo, org string, id string, comment string) (*api.AccessRequest, error)) {
+	requestInfo := h.GetRequestInfo(r)
+	// Decode request
+	request := ReviewAccessRequestRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Retrieve org and access request id from path
+	org := ps.ByName(ORG_NAME)
+	id := ps.ByName(ACCESS_REQUEST_ID)
+
+	response, err := review(requestInfo, org, id, request.Comment)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.ACCESS_REQUEST_NOT_FOUND, api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+			api.POLICY_BY_ORG_AND_NAME_NOT_FOUND, api.USER_BY_EXTERNAL_ID_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.ACCESS_REQUEST_ALREADY_REVIEWED, api.USER_IS_ALREADY_A_MEMBER_OF_GROUP,
+			api.POLICY_IS_ALREADY_ATTACHED_TO_GROUP, api.GROUP_HAS_MEMBERSHIP_RULE:
+			h.RespondConflict(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Write access request to response
+	h.RespondOk(r, requestInfo, w, response)
+}
@@ -0,0 +1,693 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+)
+
+func TestWorkerHandler_HandleAddAccessRequest(t *testing.T) {
+	now := time.Date(2016, 9, 1, 10, 0, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		// API method args
+		org     string
+		request *CreateAccessRequestRequest
+		// Expected result
+		expectedStatusCode int
+		expectedDuration   time.Duration
+		expectedResponse   *api.AccessRequest
+		expectedError      api.Error
+		// Manager Results
+		addAccessRequestResult *api.AccessRequest
+		// Manager Errors
+		addAccessRequestErr error
+	}{
+		"OkCase": {
+			org: "org1",
+			request: &CreateAccessRequestRequest{
+				GroupName:     "group1",
+				Justification: "Incident 42",
+				Duration:      "2h",
+			},
+			expectedStatusCode: http.StatusCreated,
+			expectedDuration:   2 * time.Hour,
+			expectedResponse: &api.AccessRequest{
+				ID:            "AR-ID",
+				Type:          api.ACCESS_REQUEST_TYPE_MEMBERSHIP,
+				Requester:     "userID",
+				Org:           "org1",
+				GroupName:     "group1",
+				Justification: "Incident 42",
+				Duration:      "2h0m0s",
+				Status:        api.ACCESS_REQUEST_STATUS_PENDING,
+				CreateAt:      now,
+			},
+			addAccessRequestResult: &api.AccessRequest{
+				ID:            "AR-ID",
+				Type:          api.ACCESS_REQUEST_TYPE_MEMBERSHIP,
+				Requester:     "userID",
+				Org:           "org1",
+				GroupName:     "group1",
+				Justification: "Incident 42",
+				Duration:      "2h0m0s",
+				Status:        api.ACCESS_REQUEST_STATUS_PENDING,
+				CreateAt:      now,
+			},
+		},
+		"ErrorCaseMalformedRequest": {
+			org:                "org1",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseInvalidDuration": {
+			org: "org1",
+			request: &CreateAccessRequestRequest{
+				GroupName:     "group1",
+				Justification: "Incident 42",
+				Duration:      "forever",
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: duration forever",
+			},
+		},
+		"ErrorCaseGroupNotFound": {
+			org: "org1",
+			request: &CreateAccessRequestRequest{
+				GroupName:     "group1",
+				Justification: "Incident 42",
+				Duration:      "2h",
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedDuration:   2 * time.Hour,
+			expectedError: api.Error{
+				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group not found",
+			},
+			addAccessRequestErr: &api.Error{
+				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group not found",
+			},
+		},
+		"ErrorCaseAlreadyMember": {
+			org: "org1",
+			request: &CreateAccessRequestRequest{
+				GroupName:     "group1",
+				Justification: "Incident 42",
+				Duration:      "2h",
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedDuration:   2 * time.Hour,
+			expectedError: api.Error{
+				Code:    api.USER_IS_ALREADY_A_MEMBER_OF_GROUP,
+				Message: "Already member",
+			},
+			addAccessRequestErr: &api.Error{
+				Code:    api.USER_IS_ALREADY_A_MEMBER_OF_GROUP,
+				Message: "Already member",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			org: "org1",
+			request: &CreateAccessRequestRequest{
+				GroupName:     "group1",
+				Justification: "Incident 42",
+				Duration:      "2h",
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedDuration:   2 * time.Hour,
+			addAccessRequestErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[AddAccessRequestMethod][0] = test.addAccessRequestResult
+		testApi.ArgsOut[AddAccessRequestMethod][1] = test.addAccessRequestErr
+
+		body := bytes.NewBuffer([]byte{})
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/access-requests", test.org)
+		req, err := http.NewRequest(http.MethodPost, url, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		if test.expectedDuration > 0 {
+			// Check received parameters
+			if testApi.ArgsIn[AddAccessRequestMethod][1] != test.org {
+				t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[AddAccessRequestMethod][1])
+				continue
+			}
+			if testApi.ArgsIn[AddAccessRequestMethod][2] != test.request.GroupName {
+				t.Errorf("Test case %v. Received different GroupName (wanted:%v / received:%v)", n, test.request.GroupName, testApi.ArgsIn[AddAccessRequestMethod][2])
+				continue
+			}
+			if testApi.ArgsIn[AddAccessRequestMethod][5] != test.expectedDuration {
+				t.Errorf("Test case %v. Received different Duration (wanted:%v / received:%v)", n, test.expectedDuration, testApi.ArgsIn[AddAccessRequestMethod][5])
+				continue
+			}
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusCreated:
+			response := api.AccessRequest{}
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleGetAccessRequestByID(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		org string
+		id  string
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.AccessRequest
+		expectedError      api.Error
+		// Manager Results
+		getAccessRequestByIDResult *api.AccessRequest
+		// Manager Errors
+		getAccessRequestByIDErr error
+	}{
+		"OkCase": {
+			org:                "org1",
+			id:                 "AR-ID",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.AccessRequest{
+				ID:        "AR-ID",
+				Org:       "org1",
+				GroupName: "group1",
+				Status:    api.ACCESS_REQUEST_STATUS_PENDING,
+				CreateAt:  time.Date(2016, 9, 1, 10, 0, 0, 0, time.UTC),
+			},
+			getAccessRequestByIDResult: &api.AccessRequest{
+				ID:        "AR-ID",
+				Org:       "org1",
+				GroupName: "group1",
+				Status:    api.ACCESS_REQUEST_STATUS_PENDING,
+				CreateAt:  time.Date(2016, 9, 1, 10, 0, 0, 0, time.UTC),
+			},
+		},
+		"ErrorCaseNotFound": {
+			org:                "org1",
+			id:                 "AR-ID",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.ACCESS_REQUEST_NOT_FOUND,
+				Message: "Not found",
+			},
+			getAccessRequestByIDErr: &api.Error{
+				Code:    api.ACCESS_REQUEST_NOT_FOUND,
+				Message: "Not found",
+			},
+		},
+		"ErrorCaseUnauthorized": {
+			org:                "org1",
+			id:                 "AR-ID",
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			getAccessRequestByIDErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			org:                "org1",
+			id:                 "AR-ID",
+			expectedStatusCode: http.StatusInternalServerError,
+			getAccessRequestByIDErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[GetAccessRequestByIDMethod][0] = test.getAccessRequestByIDResult
+		testApi.ArgsOut[GetAccessRequestByIDMethod][1] = test.getAccessRequestByIDErr
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/access-requests/%v", test.org, test.id)
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[GetAccessRequestByIDMethod][1] != test.org {
+			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[GetAccessRequestByIDMethod][1])
+			continue
+		}
+		if testApi.ArgsIn[GetAccessRequestByIDMethod][2] != test.id {
+			t.Errorf("Test case %v. Received different ID (wanted:%v / received:%v)", n, test.id, testApi.ArgsIn[GetAccessRequestByIDMethod][2])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			response := api.AccessRequest{}
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleListAccessRequests(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		org    string
+		status string
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   ListAccessRequestsResponse
+		expectedError      api.Error
+		// Manager Results
+		listAccessRequestsResult []api.AccessRequest
+		// Manager Errors
+		listAccessRequestsErr error
+	}{
+		"OkCase": {
+			org:                "org1",
+			status:             api.ACCESS_REQUEST_STATUS_PENDING,
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: ListAccessRequestsResponse{
+				AccessRequests: []api.AccessRequest{
+					{
+						ID:       "AR-ID",
+						Status:   api.ACCESS_REQUEST_STATUS_PENDING,
+						CreateAt: time.Date(2016, 9, 1, 10, 0, 0, 0, time.UTC),
+					},
+				},
+			},
+			listAccessRequestsResult: []api.AccessRequest{
+				{
+					ID:       "AR-ID",
+					Status:   api.ACCESS_REQUEST_STATUS_PENDING,
+					CreateAt: time.Date(2016, 9, 1, 10, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			org:                "org1",
+			status:             "unknown",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+			listAccessRequestsErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			org:                "org1",
+			expectedStatusCode: http.StatusInternalServerError,
+			listAccessRequestsErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[ListAccessRequestsMethod][0] = test.listAccessRequestsResult
+		testApi.ArgsOut[ListAccessRequestsMethod][1] = test.listAccessRequestsErr
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/access-requests", test.org)
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+		if test.status != "" {
+			q := req.URL.Query()
+			q.Add("Status", test.status)
+			req.URL.RawQuery = q.Encode()
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[ListAccessRequestsMethod][1] != test.org {
+			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[ListAccessRequestsMethod][1])
+			continue
+		}
+		if testApi.ArgsIn[ListAccessRequestsMethod][2] != test.status {
+			t.Errorf("Test case %v. Received different Status (wanted:%v / received:%v)", n, test.status, testApi.ArgsIn[ListAccessRequestsMethod][2])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			response := ListAccessRequestsResponse{}
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleReviewAccessRequest(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		org     string
+		id      string
+		action  string
+		request *ReviewAccessRequestRequest
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.AccessRequest
+		expectedError      api.Error
+		// Manager Results
+		reviewResult *api.AccessRequest
+		// Manager Errors
+		reviewErr error
+	}{
+		"OkCaseApprove": {
+			org:    "org1",
+			id:     "AR-ID",
+			action: "approve",
+			request: &ReviewAccessRequestRequest{
+				Comment: "Approved",
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.AccessRequest{
+				ID:            "AR-ID",
+				Status:        api.ACCESS_REQUEST_STATUS_APPROVED,
+				ReviewComment: "Approved",
+				CreateAt:      time.Date(2016, 9, 1, 10, 0, 0, 0, time.UTC),
+			},
+			reviewResult: &api.AccessRequest{
+				ID:            "AR-ID",
+				Status:        api.ACCESS_REQUEST_STATUS_APPROVED,
+				ReviewComment: "Approved",
+				CreateAt:      time.Date(2016, 9, 1, 10, 0, 0, 0, time.UTC),
+			},
+		},
+		"OkCaseReject": {
+			org:    "org1",
+			id:     "AR-ID",
+			action: "reject",
+			request: &ReviewAccessRequestRequest{
+				Comment: "Rejected",
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.AccessRequest{
+				ID:            "AR-ID",
+				Status:        api.ACCESS_REQUEST_STATUS_REJECTED,
+				ReviewComment: "Rejected",
+				CreateAt:      time.Date(2016, 9, 1, 10, 0, 0, 0, time.UTC),
+			},
+			reviewResult: &api.AccessRequest{
+				ID:            "AR-ID",
+				Status:        api.ACCESS_REQUEST_STATUS_REJECTED,
+				ReviewComment: "Rejected",
+				CreateAt:      time.Date(2016, 9, 1, 10, 0, 0, 0, time.UTC),
+			},
+		},
+		"ErrorCaseMalformedRequest": {
+			org:                "org1",
+			id:                 "AR-ID",
+			action:             "approve",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseAlreadyReviewed": {
+			org:    "org1",
+			id:     "AR-ID",
+			action: "approve",
+			request: &ReviewAccessRequestRequest{
+				Comment: "Approved",
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedError: api.Error{
+				Code:    api.ACCESS_REQUEST_ALREADY_REVIEWED,
+				Message: "Already reviewed",
+			},
+			reviewErr: &api.Error{
+				Code:    api.ACCESS_REQUEST_ALREADY_REVIEWED,
+				Message: "Already reviewed",
+			},
+		},
+		"ErrorCaseNotFound": {
+			org:    "org1",
+			id:     "AR-ID",
+			action: "reject",
+			request: &ReviewAccessRequestRequest{
+				Comment: "Rejected",
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.ACCESS_REQUEST_NOT_FOUND,
+				Message: "Not found",
+			},
+			reviewErr: &api.Error{
+				Code:    api.ACCESS_REQUEST_NOT_FOUND,
+				Message: "Not found",
+			},
+		},
+		"ErrorCaseUnauthorized": {
+			org:    "org1",
+			id:     "AR-ID",
+			action: "approve",
+			request: &ReviewAccessRequestRequest{
+				Comment: "Approved",
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			reviewErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		method := ApproveAccessRequestMethod
+		if test.action == "reject" {
+			method = RejectAccessRequestMethod
+		}
+		testApi.ArgsIn[method][1] = nil
+		testApi.ArgsOut[method][0] = test.reviewResult
+		testApi.ArgsOut[method][1] = test.reviewErr
+
+		body := bytes.NewBuffer([]byte{})
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/access-requests/%v/%v", test.org, test.id, test.action)
+		req, err := http.NewRequest(http.MethodPost, url, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		if test.request != nil {
+			// Check received parameters
+			if testApi.ArgsIn[method][1] != test.org {
+				t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[method][1])
+				continue
+			}
+			if testApi.ArgsIn[method][2] != test.id {
+				t.Errorf("Test case %v. Received different ID (wanted:%v / received:%v)", n, test.id, testApi.ArgsIn[method][2])
+				continue
+			}
+			if testApi.ArgsIn[method][3] != test.request.Comment {
+				t.Errorf("Test case %v. Received different Comment (wanted:%v / received:%v)", n, test.request.Comment, testApi.ArgsIn[method][3])
+				continue
+			}
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			response := api.AccessRequest{}
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
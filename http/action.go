@@ -0,0 +1,259 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/tecsisa/foulkon/api"
+)
+
+// REQUESTS
+
+type CreateNamespaceRequest struct {
+	Name        string `json:"name, omitempty"`
+	Description string `json:"description, omitempty"`
+}
+
+type CreateActionRequest struct {
+	Name               string `json:"name, omitempty"`
+	Description        string `json:"description, omitempty"`
+	ResourceUrnPattern string `json:"resourceUrnPattern, omitempty"`
+}
+
+// RESPONSES
+
+type ListNamespacesResponse struct {
+	Namespaces []api.Namespace `json:"namespaces, omitempty"`
+}
+
+type ListActionsResponse struct {
+	Actions []api.Action `json:"actions, omitempty"`
+}
+
+// HANDLERS
+
+func (h *WorkerHandler) HandleAddNamespace(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Decode request
+	request := CreateNamespaceRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call action API to create a namespace
+	response, err := h.worker.ActionApi.AddNamespace(requestInfo, request.Name, request.Description)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.NAMESPACE_ALREADY_EXIST:
+			h.RespondConflict(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Write namespace to response
+	h.RespondCreated(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleListNamespaces(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+
+	// Call action API to retrieve namespaces
+	result, err := h.worker.ActionApi.ListNamespaces(requestInfo)
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondInternalServerError(r, requestInfo, w)
+		return
+	}
+
+	// Create response
+	response := &ListNamespacesResponse{
+		Namespaces: result,
+	}
+
+	// Return data
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleRemoveNamespace(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve namespace from path
+	namespace := ps.ByName(NAMESPACE_NAME)
+
+	// Call action API to delete namespace
+	err := h.worker.ActionApi.RemoveNamespace(requestInfo, namespace)
+
+	// Check if there were errors
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.NAMESPACE_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	h.RespondNoContent(r, requestInfo, w)
+}
+
+func (h *WorkerHandler) HandleAddAction(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Decode request
+	request := CreateActionRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	namespace := ps.ByName(NAMESPACE_NAME)
+	// Call action API to create an action
+	response, err := h.worker.ActionApi.AddAction(requestInfo, namespace, request.Name, request.Description,
+		request.ResourceUrnPattern)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.NAMESPACE_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.ACTION_ALREADY_EXIST:
+			h.RespondConflict(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Write action to response
+	h.RespondCreated(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleGetAction(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve namespace and action name from path
+	namespace := ps.ByName(NAMESPACE_NAME)
+	name := ps.ByName(ACTION_NAME)
+
+	// Call action API to retrieve action
+	response, err := h.worker.ActionApi.GetAction(requestInfo, namespace, name)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.ACTION_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Write action to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleListActions(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve namespace from path
+	namespace := ps.ByName(NAMESPACE_NAME)
+
+	// Call action API to retrieve actions
+	result, err := h.worker.ActionApi.ListActions(requestInfo, namespace)
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.NAMESPACE_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Create response
+	response := &ListActionsResponse{
+		Actions: result,
+	}
+
+	// Return data
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleRemoveAction(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve namespace and action name from path
+	namespace := ps.ByName(NAMESPACE_NAME)
+	name := ps.ByName(ACTION_NAME)
+
+	// Call action API to delete action
+	err := h.worker.ActionApi.RemoveAction(requestInfo, namespace, name)
+
+	// Check if there were errors
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.ACTION_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	h.RespondNoContent(r, requestInfo, w)
+}
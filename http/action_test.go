@@ -0,0 +1,418 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+)
+
+func TestWorkerHandler_HandleAddAction(t *testing.T) {
+	now := time.Date(2016, 9, 1, 10, 0, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		// API method args
+		namespace string
+		request   *CreateActionRequest
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.Action
+		expectedError      api.Error
+		// Manager Results
+		addActionResult *api.Action
+		// Manager Errors
+		addActionErr error
+	}{
+		"OkCase": {
+			namespace: "billing",
+			request: &CreateActionRequest{
+				Name:               "GetInvoice",
+				Description:        "Retrieve an invoice",
+				ResourceUrnPattern: "urn:ews:billing:instance:invoice/*",
+			},
+			expectedStatusCode: http.StatusCreated,
+			expectedResponse: &api.Action{
+				ID:                 "ACTION-ID",
+				Namespace:          "billing",
+				Name:               "GetInvoice",
+				Description:        "Retrieve an invoice",
+				ResourceUrnPattern: "urn:ews:billing:instance:invoice/*",
+				CreateAt:           now,
+			},
+			addActionResult: &api.Action{
+				ID:                 "ACTION-ID",
+				Namespace:          "billing",
+				Name:               "GetInvoice",
+				Description:        "Retrieve an invoice",
+				ResourceUrnPattern: "urn:ews:billing:instance:invoice/*",
+				CreateAt:           now,
+			},
+		},
+		"ErrorCaseMalformedRequest": {
+			namespace:          "billing",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseNamespaceNotFound": {
+			namespace: "billing",
+			request: &CreateActionRequest{
+				Name: "GetInvoice",
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.NAMESPACE_NOT_FOUND,
+				Message: "Namespace not found",
+			},
+			addActionErr: &api.Error{
+				Code:    api.NAMESPACE_NOT_FOUND,
+				Message: "Namespace not found",
+			},
+		},
+		"ErrorCaseActionAlreadyExist": {
+			namespace: "billing",
+			request: &CreateActionRequest{
+				Name: "GetInvoice",
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedError: api.Error{
+				Code:    api.ACTION_ALREADY_EXIST,
+				Message: "Action already exist",
+			},
+			addActionErr: &api.Error{
+				Code:    api.ACTION_ALREADY_EXIST,
+				Message: "Action already exist",
+			},
+		},
+		"ErrorCaseNotAdmin": {
+			namespace: "billing",
+			request: &CreateActionRequest{
+				Name: "GetInvoice",
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			addActionErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			namespace: "billing",
+			request: &CreateActionRequest{
+				Name: "GetInvoice",
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			addActionErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[AddActionMethod][0] = test.addActionResult
+		testApi.ArgsOut[AddActionMethod][1] = test.addActionErr
+
+		body := bytes.NewBuffer([]byte{})
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/namespaces/%v/actions", test.namespace)
+		req, err := http.NewRequest(http.MethodPost, url, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		if test.request != nil {
+			// Check received parameters
+			if testApi.ArgsIn[AddActionMethod][1] != test.namespace {
+				t.Errorf("Test case %v. Received different Namespace (wanted:%v / received:%v)", n, test.namespace, testApi.ArgsIn[AddActionMethod][1])
+				continue
+			}
+			if testApi.ArgsIn[AddActionMethod][2] != test.request.Name {
+				t.Errorf("Test case %v. Received different Name (wanted:%v / received:%v)", n, test.request.Name, testApi.ArgsIn[AddActionMethod][2])
+				continue
+			}
+			if testApi.ArgsIn[AddActionMethod][4] != test.request.ResourceUrnPattern {
+				t.Errorf("Test case %v. Received different ResourceUrnPattern (wanted:%v / received:%v)", n, test.request.ResourceUrnPattern, testApi.ArgsIn[AddActionMethod][4])
+				continue
+			}
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusCreated:
+			response := api.Action{}
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleListActions(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		namespace string
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   ListActionsResponse
+		expectedError      api.Error
+		// Manager Results
+		listActionsResult []api.Action
+		// Manager Errors
+		listActionsErr error
+	}{
+		"OkCase": {
+			namespace:          "billing",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: ListActionsResponse{
+				Actions: []api.Action{
+					{
+						ID:        "ACTION-ID",
+						Namespace: "billing",
+						Name:      "GetInvoice",
+					},
+				},
+			},
+			listActionsResult: []api.Action{
+				{
+					ID:        "ACTION-ID",
+					Namespace: "billing",
+					Name:      "GetInvoice",
+				},
+			},
+		},
+		"ErrorCaseNamespaceNotFound": {
+			namespace:          "billing",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.NAMESPACE_NOT_FOUND,
+				Message: "Namespace not found",
+			},
+			listActionsErr: &api.Error{
+				Code:    api.NAMESPACE_NOT_FOUND,
+				Message: "Namespace not found",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			namespace:          "billing",
+			expectedStatusCode: http.StatusInternalServerError,
+			listActionsErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[ListActionsMethod][0] = test.listActionsResult
+		testApi.ArgsOut[ListActionsMethod][1] = test.listActionsErr
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/namespaces/%v/actions", test.namespace)
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[ListActionsMethod][1] != test.namespace {
+			t.Errorf("Test case %v. Received different Namespace (wanted:%v / received:%v)", n, test.namespace, testApi.ArgsIn[ListActionsMethod][1])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			response := ListActionsResponse{}
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleRemoveNamespace(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		namespace string
+		// Expected result
+		expectedStatusCode int
+		expectedError      api.Error
+		// Manager Errors
+		removeNamespaceErr error
+	}{
+		"OkCase": {
+			namespace:          "billing",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		"ErrorCaseBuiltInNamespace": {
+			namespace:          "iam",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: namespace iam is built-in and can't be modified",
+			},
+			removeNamespaceErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: namespace iam is built-in and can't be modified",
+			},
+		},
+		"ErrorCaseNamespaceNotFound": {
+			namespace:          "billing",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.NAMESPACE_NOT_FOUND,
+				Message: "Namespace not found",
+			},
+			removeNamespaceErr: &api.Error{
+				Code:    api.NAMESPACE_NOT_FOUND,
+				Message: "Namespace not found",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			namespace:          "billing",
+			expectedStatusCode: http.StatusInternalServerError,
+			removeNamespaceErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[RemoveNamespaceMethod][0] = test.removeNamespaceErr
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/namespaces/%v", test.namespace)
+		req, err := http.NewRequest(http.MethodDelete, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[RemoveNamespaceMethod][1] != test.namespace {
+			t.Errorf("Test case %v. Received different Namespace (wanted:%v / received:%v)", n, test.namespace, testApi.ArgsIn[RemoveNamespaceMethod][1])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusNoContent, http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
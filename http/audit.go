@@ -0,0 +1,84 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/tecsisa/foulkon/api"
+)
+
+// RESPONSES
+
+type ListAuditEventsResponse struct {
+	AuditEvents []api.AuditEvent `json:"auditEvents, omitempty"`
+	Offset      int              `json:"offset, omitempty"`
+	Limit       int              `json:"limit, omitempty"`
+	Total       int              `json:"total, omitempty"`
+}
+
+// HANDLERS
+
+func (h *WorkerHandler) HandleListAuditEvents(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve filter from query params
+	filter, err := getAuditFilter(r)
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+	actor := r.URL.Query().Get("Actor")
+	urn := r.URL.Query().Get("Urn")
+	action := r.URL.Query().Get("Action")
+
+	// Call audit API to retrieve audit events
+	result, total, err := h.worker.AuditApi.ListAuditEvents(requestInfo, actor, urn, action, filter)
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Create response
+	response := &ListAuditEventsResponse{
+		AuditEvents: result,
+		Offset:      filter.Offset,
+		Limit:       filter.Limit,
+		Total:       total,
+	}
+
+	// Return data
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+// PRIVATE HELPER METHODS
+
+// Retrieve audit filter from request query params: CreatedAfter, CreatedBefore, Offset and Limit
+func getAuditFilter(r *http.Request) (*api.Filter, error) {
+	filter, err := getPaginationFilter(r)
+	if err != nil {
+		return nil, err
+	}
+	createdAfter, err := getTimeQueryParam(r, "CreatedAfter")
+	if err != nil {
+		return nil, err
+	}
+	createdBefore, err := getTimeQueryParam(r, "CreatedBefore")
+	if err != nil {
+		return nil, err
+	}
+	filter.CreatedAfter = createdAfter
+	filter.CreatedBefore = createdBefore
+
+	return filter, nil
+}
@@ -0,0 +1,191 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+)
+
+func TestWorkerHandler_HandleListAuditEvents(t *testing.T) {
+	createdAfter := time.Date(2016, time.November, 20, 10, 0, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		// API method args
+		actor         string
+		urn           string
+		action        string
+		createdAfter  string
+		createdBefore string
+		filter        *api.Filter
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   ListAuditEventsResponse
+		expectedError      api.Error
+		// Manager Results
+		listAuditEventsResult []api.AuditEvent
+		totalResult           int
+		// Manager Errors
+		listAuditEventsErr error
+	}{
+		"OkCase": {
+			actor:        "user1",
+			urn:          api.CreateUrn("org1", api.RESOURCE_GROUP, "/path/", "group"),
+			action:       api.GROUP_ACTION_ADD_MEMBER,
+			createdAfter: "2016-11-20T10:00:00Z",
+			filter: &api.Filter{
+				CreatedAfter: &createdAfter,
+				Offset:       0,
+				Limit:        10,
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: ListAuditEventsResponse{
+				AuditEvents: []api.AuditEvent{
+					{
+						ID:        "EVENT-ID",
+						RequestID: "REQUEST-ID",
+						Actor:     "user1",
+						Action:    api.GROUP_ACTION_ADD_MEMBER,
+						Urn:       api.CreateUrn("org1", api.RESOURCE_GROUP, "/path/", "group"),
+						// Documents that don't exist are null
+						Before: json.RawMessage("null"),
+						After:  json.RawMessage(`{"externalId":"user2"}`),
+					},
+				},
+				Offset: 0,
+				Limit:  10,
+				Total:  1,
+			},
+			listAuditEventsResult: []api.AuditEvent{
+				{
+					ID:        "EVENT-ID",
+					RequestID: "REQUEST-ID",
+					Actor:     "user1",
+					Action:    api.GROUP_ACTION_ADD_MEMBER,
+					Urn:       api.CreateUrn("org1", api.RESOURCE_GROUP, "/path/", "group"),
+					After:     json.RawMessage(`{"externalId":"user2"}`),
+				},
+			},
+			totalResult: 1,
+		},
+		"ErrorCaseInvalidDate": {
+			createdBefore:      "yesterday",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: CreatedBefore yesterday",
+			},
+		},
+		"ErrorCaseInvalidParameter": {
+			actor:  "invalid*",
+			filter: &api.Filter{},
+			listAuditEventsErr: &api.Error{
+				Code: api.INVALID_PARAMETER_ERROR,
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code: api.INVALID_PARAMETER_ERROR,
+			},
+		},
+		"ErrorCaseUnauthorized": {
+			filter: &api.Filter{},
+			listAuditEventsErr: &api.Error{
+				Code: api.UNAUTHORIZED_RESOURCES_ERROR,
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code: api.UNAUTHORIZED_RESOURCES_ERROR,
+			},
+		},
+		"ErrorCaseInternalServerError": {
+			filter: &api.Filter{},
+			listAuditEventsErr: &api.Error{
+				Code: api.UNKNOWN_API_ERROR,
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsOut[ListAuditEventsMethod][0] = test.listAuditEventsResult
+		testApi.ArgsOut[ListAuditEventsMethod][1] = test.totalResult
+		testApi.ArgsOut[ListAuditEventsMethod][2] = test.listAuditEventsErr
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+AUDIT_URL, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+		q := req.URL.Query()
+		q.Add("Actor", test.actor)
+		q.Add("Urn", test.urn)
+		q.Add("Action", test.action)
+		q.Add("CreatedAfter", test.createdAfter)
+		q.Add("CreatedBefore", test.createdBefore)
+		if test.filter != nil {
+			q.Add("Offset", fmt.Sprintf("%v", test.filter.Offset))
+			q.Add("Limit", fmt.Sprintf("%v", test.filter.Limit))
+		}
+		req.URL.RawQuery = q.Encode()
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if test.filter != nil {
+			received := []interface{}{
+				testApi.ArgsIn[ListAuditEventsMethod][1],
+				testApi.ArgsIn[ListAuditEventsMethod][2],
+				testApi.ArgsIn[ListAuditEventsMethod][3],
+				testApi.ArgsIn[ListAuditEventsMethod][4],
+			}
+			if diff := pretty.Compare(received, []interface{}{test.actor, test.urn, test.action, test.filter}); diff != "" {
+				t.Errorf("Test case %v. Received different parameters (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			response := ListAuditEventsResponse{}
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
@@ -14,6 +14,8 @@ import (
 type AuthorizeResourcesRequest struct {
 	Action    string   `json:"action, omitempty"`
 	Resources []string `json:"resources, omitempty"`
+	// Tags of the resources to evaluate statement conditions
+	ResourceTags map[string]string `json:"resourceTags, omitempty"`
 }
 
 // RESPONSES
@@ -40,7 +42,8 @@ func (h *WorkerHandler) HandleGetAuthorizedExternalResources(w http.ResponseWrit
 	}
 
 	// Retrieve allowed resources
-	result, err := h.worker.AuthzApi.GetAuthorizedExternalResources(requestInfo, request.Action, request.Resources)
+	result, err := h.worker.AuthzApi.GetAuthorizedExternalResources(requestInfo, request.Action, request.Resources,
+		request.ResourceTags)
 	if err != nil {
 		// Transform to API errors
 		apiError := err.(*api.Error)
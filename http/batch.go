@@ -0,0 +1,65 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/tecsisa/foulkon/api"
+)
+
+// REQUESTS
+
+type BatchRequest struct {
+	Operations []api.BatchOperation `json:"operations, omitempty"`
+	Atomic     bool                 `json:"atomic, omitempty"`
+}
+
+// RESPONSES
+
+type BatchResponse struct {
+	Results []api.BatchResult `json:"results, omitempty"`
+}
+
+// HANDLERS
+
+func (h *WorkerHandler) HandleBatch(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Decode request
+	request := BatchRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call batch API to execute operations
+	result, err := h.worker.BatchApi.Batch(requestInfo, request.Operations, request.Atomic)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Create response
+	response := &BatchResponse{
+		Results: result,
+	}
+
+	// Return operation results
+	h.RespondOk(r, requestInfo, w, response)
+}
@@ -0,0 +1,222 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+)
+
+func TestWorkerHandler_HandleBatch(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		request *BatchRequest
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   BatchResponse
+		expectedError      api.Error
+		// Manager Results
+		batchResult []api.BatchResult
+		// Manager Errors
+		batchErr error
+	}{
+		"OkCase": {
+			request: &BatchRequest{
+				Operations: []api.BatchOperation{
+					{
+						Operation:  api.BATCH_OPERATION_ADD_USER,
+						ExternalID: "user1",
+						Path:       "/path/",
+					},
+					{
+						Operation:  api.BATCH_OPERATION_ADD_MEMBER,
+						ExternalID: "user1",
+						Org:        "org1",
+						GroupName:  "group1",
+					},
+				},
+				Atomic: true,
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: BatchResponse{
+				Results: []api.BatchResult{
+					{
+						Operation: api.BATCH_OPERATION_ADD_USER,
+						Status:    api.BATCH_STATUS_OK,
+					},
+					{
+						Operation: api.BATCH_OPERATION_ADD_MEMBER,
+						Status:    api.BATCH_STATUS_OK,
+					},
+				},
+			},
+			batchResult: []api.BatchResult{
+				{
+					Operation: api.BATCH_OPERATION_ADD_USER,
+					Status:    api.BATCH_STATUS_OK,
+				},
+				{
+					Operation: api.BATCH_OPERATION_ADD_MEMBER,
+					Status:    api.BATCH_STATUS_OK,
+				},
+			},
+		},
+		"OkCaseOperationFailed": {
+			request: &BatchRequest{
+				Operations: []api.BatchOperation{
+					{
+						Operation:  api.BATCH_OPERATION_ADD_MEMBER,
+						ExternalID: "user1",
+						Org:        "org1",
+						GroupName:  "group1",
+					},
+				},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: BatchResponse{
+				Results: []api.BatchResult{
+					{
+						Operation: api.BATCH_OPERATION_ADD_MEMBER,
+						Status:    api.BATCH_STATUS_FAILED,
+						Error: &api.Error{
+							Code:    api.USER_IS_ALREADY_A_MEMBER_OF_GROUP,
+							Message: "Already member",
+						},
+					},
+				},
+			},
+			batchResult: []api.BatchResult{
+				{
+					Operation: api.BATCH_OPERATION_ADD_MEMBER,
+					Status:    api.BATCH_STATUS_FAILED,
+					Error: &api.Error{
+						Code:    api.USER_IS_ALREADY_A_MEMBER_OF_GROUP,
+						Message: "Already member",
+					},
+				},
+			},
+		},
+		"ErrorCaseMalformedRequest": {
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			request: &BatchRequest{
+				Operations: []api.BatchOperation{
+					{
+						Operation: "addEverything",
+					},
+				},
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: operation addEverything",
+			},
+			batchErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: operation addEverything",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			request: &BatchRequest{
+				Operations: []api.BatchOperation{
+					{
+						Operation:  api.BATCH_OPERATION_ADD_USER,
+						ExternalID: "user1",
+						Path:       "/path/",
+					},
+				},
+				Atomic: true,
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			batchErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[BatchMethod][0] = test.batchResult
+		testApi.ArgsOut[BatchMethod][1] = test.batchErr
+
+		body := bytes.NewBuffer([]byte{})
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+
+		req, err := http.NewRequest(http.MethodPost, server.URL+BATCH_URL, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		if test.request != nil {
+			// Check received parameters
+			if diff := pretty.Compare(testApi.ArgsIn[BatchMethod][1], test.request.Operations); diff != "" {
+				t.Errorf("Test case %v. Received different operations (received/wanted) %v", n, diff)
+				continue
+			}
+			if testApi.ArgsIn[BatchMethod][2] != test.request.Atomic {
+				t.Errorf("Test case %v. Received different Atomic (wanted:%v / received:%v)", n, test.request.Atomic, testApi.ArgsIn[BatchMethod][2])
+				continue
+			}
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			response := BatchResponse{}
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
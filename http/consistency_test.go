@@ -0,0 +1,144 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+)
+
+func TestWorkerHandler_HandleCheckConsistency(t *testing.T) {
+	testcases := map[string]struct {
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.ConsistencyReport
+		expectedError      api.Error
+		// Manager Results
+		checkConsistencyResult *api.ConsistencyReport
+		// Manager Errors
+		checkConsistencyErr error
+	}{
+		"OkCase": {
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.ConsistencyReport{
+				DanglingReferences: []api.DanglingReference{
+					{
+						Org:        "example",
+						PolicyName: "policy",
+						Sid:        "members",
+						Urn:        api.CreateUrn("example", api.RESOURCE_GROUP, "/path/", "group"),
+					},
+				},
+				DanglingMemberships: []api.DanglingRelation{
+					{
+						GroupID: "GROUP-ID",
+						UserID:  "USER-ID",
+					},
+				},
+				DanglingAttachments: []api.DanglingRelation{},
+				EmptyPolicies: []api.PolicyIdentity{
+					{
+						Org:  "example",
+						Name: "empty",
+					},
+				},
+				GroupsWithoutOrgPolicies: []api.GroupIdentity{},
+			},
+			checkConsistencyResult: &api.ConsistencyReport{
+				DanglingReferences: []api.DanglingReference{
+					{
+						Org:        "example",
+						PolicyName: "policy",
+						Sid:        "members",
+						Urn:        api.CreateUrn("example", api.RESOURCE_GROUP, "/path/", "group"),
+					},
+				},
+				DanglingMemberships: []api.DanglingRelation{
+					{
+						GroupID: "GROUP-ID",
+						UserID:  "USER-ID",
+					},
+				},
+				DanglingAttachments: []api.DanglingRelation{},
+				EmptyPolicies: []api.PolicyIdentity{
+					{
+						Org:  "example",
+						Name: "empty",
+					},
+				},
+				GroupsWithoutOrgPolicies: []api.GroupIdentity{},
+			},
+		},
+		"ErrorCaseUnauthorized": {
+			checkConsistencyErr: &api.Error{
+				Code: api.UNAUTHORIZED_RESOURCES_ERROR,
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code: api.UNAUTHORIZED_RESOURCES_ERROR,
+			},
+		},
+		"ErrorCaseInternalServerError": {
+			checkConsistencyErr: &api.Error{
+				Code: api.UNKNOWN_API_ERROR,
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsOut[CheckConsistencyMethod][0] = test.checkConsistencyResult
+		testApi.ArgsOut[CheckConsistencyMethod][1] = test.checkConsistencyErr
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+CONSISTENCY_URL, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			response := &api.ConsistencyReport{}
+			err = json.NewDecoder(res.Body).Decode(response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
@@ -2,7 +2,9 @@ package http
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/tecsisa/foulkon/api"
@@ -20,22 +22,56 @@ type UpdateGroupRequest struct {
 	Path string `json:"path, omitempty"`
 }
 
+type AttachGroupPolicyRequest struct {
+	NotBefore *time.Time `json:"notBefore, omitempty"`
+	NotAfter  *time.Time `json:"notAfter, omitempty"`
+}
+
 // RESPONSES
 
+// Updated group with the policy statements that still reference its old URN
+type UpdateGroupResponse struct {
+	*api.Group
+	DanglingReferences []api.StatementReference `json:"danglingReferences, omitempty"`
+}
+
 type ListGroupsResponse struct {
 	Groups []string `json:"groups, omitempty"`
+	Offset int      `json:"offset, omitempty"`
+	Limit  int      `json:"limit, omitempty"`
+	Total  int      `json:"total, omitempty"`
 }
 
 type ListAllGroupsResponse struct {
 	Groups []api.GroupIdentity `json:"groups, omitempty"`
+	Offset int                 `json:"offset, omitempty"`
+	Limit  int                 `json:"limit, omitempty"`
+	Total  int                 `json:"total, omitempty"`
+}
+
+type ListGroupsExpandedResponse struct {
+	Groups []api.Group `json:"groups, omitempty"`
+	Offset int         `json:"offset, omitempty"`
+	Limit  int         `json:"limit, omitempty"`
+	Total  int         `json:"total, omitempty"`
 }
 
 type ListMembersResponse struct {
 	Members []string `json:"members, omitempty"`
+	Offset  int      `json:"offset, omitempty"`
+	Limit   int      `json:"limit, omitempty"`
+	Total   int      `json:"total, omitempty"`
+}
+
+type ListGroupOwnersResponse struct {
+	Owners []string `json:"owners, omitempty"`
 }
 
 type ListAttachedGroupPoliciesResponse struct {
-	AttachedPolicies []string `json:"policies, omitempty"`
+	AttachedPolicies []api.AttachedPolicy `json:"policies, omitempty"`
+	Offset           int                  `json:"offset, omitempty"`
+	Limit            int                  `json:"limit, omitempty"`
+	Total            int                  `json:"total, omitempty"`
 }
 
 // HANDLERS
@@ -67,6 +103,8 @@ func (h *WorkerHandler) HandleAddGroup(w http.ResponseWriter, r *http.Request, p
 		switch apiError.Code {
 		case api.GROUP_ALREADY_EXIST:
 			h.RespondConflict(r, requestInfo, w, apiError)
+		case api.ORGANIZATION_BY_NAME_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
 		case api.INVALID_PARAMETER_ERROR:
 			h.RespondBadRequest(r, requestInfo, w, apiError)
 		case api.UNAUTHORIZED_RESOURCES_ERROR:
@@ -109,6 +147,7 @@ func (h *WorkerHandler) HandleGetGroupByName(w http.ResponseWriter, r *http.Requ
 	}
 
 	// Write group to response
+	setETagHeader(w, response)
 	h.RespondOk(r, requestInfo, w, response)
 }
 
@@ -117,11 +156,24 @@ func (h *WorkerHandler) HandleListGroups(w http.ResponseWriter, r *http.Request,
 	// Retrieve group org from path
 	org := ps.ByName(ORG_NAME)
 
-	// Retrieve query param if exists
-	pathPrefix := r.URL.Query().Get("PathPrefix")
+	// Retrieve filter from query params
+	filter, err := getListFilter(r, org)
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+	expand, err := getBoolQueryParam(r, "Expand")
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
 
 	// Call group API to retrieve groups
-	result, err := h.worker.GroupApi.ListGroups(requestInfo, org, pathPrefix)
+	result, total, err := h.worker.GroupApi.ListGroups(requestInfo, filter)
 	if err != nil {
 		// Transform to API errors
 		apiError := err.(*api.Error)
@@ -137,6 +189,17 @@ func (h *WorkerHandler) HandleListGroups(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	// Return full groups if requested
+	if expand {
+		h.RespondOk(r, requestInfo, w, &ListGroupsExpandedResponse{
+			Groups: result,
+			Offset: filter.Offset,
+			Limit:  filter.Limit,
+			Total:  total,
+		})
+		return
+	}
+
 	groups := []string{}
 	for _, group := range result {
 		groups = append(groups, group.Name)
@@ -145,6 +208,9 @@ func (h *WorkerHandler) HandleListGroups(w http.ResponseWriter, r *http.Request,
 	// Create response
 	response := &ListGroupsResponse{
 		Groups: groups,
+		Offset: filter.Offset,
+		Limit:  filter.Limit,
+		Total:  total,
 	}
 
 	// Return groups
@@ -153,11 +219,24 @@ func (h *WorkerHandler) HandleListGroups(w http.ResponseWriter, r *http.Request,
 
 func (h *WorkerHandler) HandleListAllGroups(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	requestInfo := h.GetRequestInfo(r)
-	// get PathPrefix from request, so the query can be filtered
-	pathPrefix := r.URL.Query().Get("PathPrefix")
+	// Retrieve filter from query params
+	filter, err := getListFilter(r, "")
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+	expand, err := getBoolQueryParam(r, "Expand")
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
 
 	// Call group API to retrieve groups
-	result, err := h.worker.GroupApi.ListGroups(requestInfo, "", pathPrefix)
+	result, total, err := h.worker.GroupApi.ListGroups(requestInfo, filter)
 	if err != nil {
 		// Transform to API errors
 		apiError := err.(*api.Error)
@@ -173,9 +252,30 @@ func (h *WorkerHandler) HandleListAllGroups(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// Return full groups if requested
+	if expand {
+		h.RespondOk(r, requestInfo, w, &ListGroupsExpandedResponse{
+			Groups: result,
+			Offset: filter.Offset,
+			Limit:  filter.Limit,
+			Total:  total,
+		})
+		return
+	}
+
 	// Create response
+	groups := []api.GroupIdentity{}
+	for _, group := range result {
+		groups = append(groups, api.GroupIdentity{
+			Org:  group.Org,
+			Name: group.Name,
+		})
+	}
 	response := &ListAllGroupsResponse{
-		Groups: result,
+		Groups: groups,
+		Offset: filter.Offset,
+		Limit:  filter.Limit,
+		Total:  total,
 	}
 
 	// Return groups
@@ -184,6 +284,9 @@ func (h *WorkerHandler) HandleListAllGroups(w http.ResponseWriter, r *http.Reque
 
 func (h *WorkerHandler) HandleUpdateGroup(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	requestInfo := h.GetRequestInfo(r)
+	if !h.checkIfMatchRequired(r, requestInfo, w) || !h.readRewriteReferences(r, &requestInfo, w) {
+		return
+	}
 	// Decode request
 	request := UpdateGroupRequest{}
 	err := json.NewDecoder(r.Body).Decode(&request)
@@ -202,7 +305,7 @@ func (h *WorkerHandler) HandleUpdateGroup(w http.ResponseWriter, r *http.Request
 	groupName := ps.ByName(GROUP_NAME)
 
 	// Call group API to update group
-	response, err := h.worker.GroupApi.UpdateGroup(requestInfo, org, groupName, request.Name, request.Path)
+	group, references, err := h.worker.GroupApi.UpdateGroup(requestInfo, org, groupName, request.Name, request.Path)
 
 	// Check errors
 	if err != nil {
@@ -216,6 +319,8 @@ func (h *WorkerHandler) HandleUpdateGroup(w http.ResponseWriter, r *http.Request
 			h.RespondConflict(r, requestInfo, w, apiError)
 		case api.UNAUTHORIZED_RESOURCES_ERROR:
 			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.PRECONDITION_FAILED_ERROR:
+			h.RespondPreconditionFailed(r, requestInfo, w, apiError)
 		case api.INVALID_PARAMETER_ERROR:
 			h.RespondBadRequest(r, requestInfo, w, apiError)
 		default:
@@ -225,11 +330,19 @@ func (h *WorkerHandler) HandleUpdateGroup(w http.ResponseWriter, r *http.Request
 	}
 
 	// Write group to response
+	response := &UpdateGroupResponse{
+		Group:              group,
+		DanglingReferences: references,
+	}
+	setETagHeader(w, group)
 	h.RespondOk(r, requestInfo, w, response)
 }
 
 func (h *WorkerHandler) HandleRemoveGroup(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	requestInfo := h.GetRequestInfo(r)
+	if !h.checkIfMatchRequired(r, requestInfo, w) {
+		return
+	}
 	// Retrieve group org and name from path
 	org := ps.ByName(ORG_NAME)
 	name := ps.ByName(GROUP_NAME)
@@ -247,6 +360,8 @@ func (h *WorkerHandler) HandleRemoveGroup(w http.ResponseWriter, r *http.Request
 			h.RespondNotFound(r, requestInfo, w, apiError)
 		case api.UNAUTHORIZED_RESOURCES_ERROR:
 			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.PRECONDITION_FAILED_ERROR:
+			h.RespondPreconditionFailed(r, requestInfo, w, apiError)
 		case api.INVALID_PARAMETER_ERROR:
 			h.RespondBadRequest(r, requestInfo, w, apiError)
 		default: // Unexpected API error
@@ -258,6 +373,58 @@ func (h *WorkerHandler) HandleRemoveGroup(w http.ResponseWriter, r *http.Request
 	h.RespondNoContent(r, requestInfo, w)
 }
 
+func (h *WorkerHandler) HandleSetGroupMembershipRule(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	if !h.checkIfMatchRequired(r, requestInfo, w) {
+		return
+	}
+	// Decode request
+	request := api.MembershipRule{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Retrieve group, org from path
+	org := ps.ByName(ORG_NAME)
+	groupName := ps.ByName(GROUP_NAME)
+
+	// Call group API to set the membership rule
+	group, err := h.worker.GroupApi.SetGroupMembershipRule(requestInfo, org, groupName, &request)
+	if err != nil {
+		h.respondMembershipRuleError(r, requestInfo, w, err)
+		return
+	}
+
+	// Write group to response
+	setETagHeader(w, group)
+	h.RespondOk(r, requestInfo, w, group)
+}
+
+func (h *WorkerHandler) HandleRemoveGroupMembershipRule(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	if !h.checkIfMatchRequired(r, requestInfo, w) {
+		return
+	}
+	// Retrieve group, org from path
+	org := ps.ByName(ORG_NAME)
+	groupName := ps.ByName(GROUP_NAME)
+
+	// Call group API to remove the membership rule
+	if _, err := h.worker.GroupApi.SetGroupMembershipRule(requestInfo, org, groupName, nil); err != nil {
+		h.respondMembershipRuleError(r, requestInfo, w, err)
+		return
+	}
+
+	h.RespondNoContent(r, requestInfo, w)
+}
+
 func (h *WorkerHandler) HandleAddMember(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	requestInfo := h.GetRequestInfo(r)
 	// Retrieve group, org and user from path
@@ -279,7 +446,7 @@ func (h *WorkerHandler) HandleAddMember(w http.ResponseWriter, r *http.Request,
 			h.RespondForbidden(r, requestInfo, w, apiError)
 		case api.INVALID_PARAMETER_ERROR:
 			h.RespondBadRequest(r, requestInfo, w, apiError)
-		case api.USER_IS_ALREADY_A_MEMBER_OF_GROUP:
+		case api.USER_IS_ALREADY_A_MEMBER_OF_GROUP, api.GROUP_HAS_MEMBERSHIP_RULE:
 			h.RespondConflict(r, requestInfo, w, apiError)
 		default:
 			h.RespondInternalServerError(r, requestInfo, w)
@@ -311,6 +478,8 @@ func (h *WorkerHandler) HandleRemoveMember(w http.ResponseWriter, r *http.Reques
 			h.RespondForbidden(r, requestInfo, w, apiError)
 		case api.INVALID_PARAMETER_ERROR:
 			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.GROUP_HAS_MEMBERSHIP_RULE:
+			h.RespondConflict(r, requestInfo, w, apiError)
 		default:
 			h.RespondInternalServerError(r, requestInfo, w)
 		}
@@ -326,8 +495,17 @@ func (h *WorkerHandler) HandleListMembers(w http.ResponseWriter, r *http.Request
 	org := ps.ByName(ORG_NAME)
 	group := ps.ByName(GROUP_NAME)
 
+	// Retrieve pagination from query params
+	filter, err := getPaginationFilter(r)
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
 	// Call group API to list members
-	result, err := h.worker.GroupApi.ListMembers(requestInfo, org, group)
+	result, total, err := h.worker.GroupApi.ListMembers(requestInfo, org, group, filter)
 
 	// Check errors
 	if err != nil {
@@ -350,21 +528,132 @@ func (h *WorkerHandler) HandleListMembers(w http.ResponseWriter, r *http.Request
 	// Create response
 	response := &ListMembersResponse{
 		Members: result,
+		Offset:  filter.Offset,
+		Limit:   filter.Limit,
+		Total:   total,
 	}
 
 	// Write GroupMembers to response
 	h.RespondOk(r, requestInfo, w, response)
 }
 
+func (h *WorkerHandler) HandleListGroupOwners(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve group and org from path
+	org := ps.ByName(ORG_NAME)
+	group := ps.ByName(GROUP_NAME)
+
+	// Call group API to list owners
+	result, err := h.worker.GroupApi.ListGroupOwners(requestInfo, org, group)
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.GROUP_BY_ORG_AND_NAME_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		default:
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Create response
+	response := &ListGroupOwnersResponse{
+		Owners: result,
+	}
+
+	// Return group owners
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleAddGroupOwner(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve group, org and user from path
+	org := ps.ByName(ORG_NAME)
+	user := ps.ByName(USER_ID)
+	group := ps.ByName(GROUP_NAME)
+
+	// Call group API to add owner
+	err := h.worker.GroupApi.AddGroupOwner(requestInfo, org, group, user)
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.GROUP_BY_ORG_AND_NAME_NOT_FOUND, api.USER_BY_EXTERNAL_ID_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.USER_IS_ALREADY_AN_OWNER_OF_GROUP:
+			h.RespondConflict(r, requestInfo, w, apiError)
+		default:
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	h.RespondNoContent(r, requestInfo, w)
+}
+
+func (h *WorkerHandler) HandleRemoveGroupOwner(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve group, org and user from path
+	org := ps.ByName(ORG_NAME)
+	user := ps.ByName(USER_ID)
+	group := ps.ByName(GROUP_NAME)
+
+	// Call group API to remove owner
+	err := h.worker.GroupApi.RemoveGroupOwner(requestInfo, org, group, user)
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.GROUP_BY_ORG_AND_NAME_NOT_FOUND, api.USER_BY_EXTERNAL_ID_NOT_FOUND, api.USER_IS_NOT_AN_OWNER_OF_GROUP:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		default:
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	h.RespondNoContent(r, requestInfo, w)
+}
+
 func (h *WorkerHandler) HandleAttachPolicyToGroup(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	requestInfo := h.GetRequestInfo(r)
+	// Decode request. Body is optional, it only contains the activation window
+	request := AttachGroupPolicyRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && err != io.EOF {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
 	// Retrieve group, org and policy from path
 	org := ps.ByName(ORG_NAME)
 	groupName := ps.ByName(GROUP_NAME)
 	policyName := ps.ByName(POLICY_NAME)
 
 	// Call group API to attach policy to group
-	err := h.worker.GroupApi.AttachPolicyToGroup(requestInfo, org, groupName, policyName)
+	err := h.worker.GroupApi.AttachPolicyToGroup(requestInfo, org, groupName, policyName, request.NotBefore, request.NotAfter)
 
 	// Error handling
 	if err != nil {
@@ -428,8 +717,17 @@ func (h *WorkerHandler) HandleListAttachedGroupPolicies(w http.ResponseWriter, r
 	org := ps.ByName(ORG_NAME)
 	groupName := ps.ByName(GROUP_NAME)
 
+	// Retrieve pagination from query params
+	filter, err := getPaginationFilter(r)
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
 	// Call group API to retrieve attached policies
-	result, err := h.worker.GroupApi.ListAttachedGroupPolicies(requestInfo, org, groupName)
+	result, total, err := h.worker.GroupApi.ListAttachedGroupPolicies(requestInfo, org, groupName, filter)
 	if err != nil {
 		// Transform to API errors
 		apiError := err.(*api.Error)
@@ -450,8 +748,31 @@ func (h *WorkerHandler) HandleListAttachedGroupPolicies(w http.ResponseWriter, r
 	// Create response
 	response := &ListAttachedGroupPoliciesResponse{
 		AttachedPolicies: result,
+		Offset:           filter.Offset,
+		Limit:            filter.Limit,
+		Total:            total,
 	}
 
 	// Return group policies
 	h.RespondOk(r, requestInfo, w, response)
 }
+
+// PRIVATE HELPER METHODS
+
+func (h *WorkerHandler) respondMembershipRuleError(r *http.Request, requestInfo api.RequestInfo, w http.ResponseWriter, err error) {
+	// Transform to API errors
+	apiError := err.(*api.Error)
+	api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+	switch apiError.Code {
+	case api.GROUP_BY_ORG_AND_NAME_NOT_FOUND:
+		h.RespondNotFound(r, requestInfo, w, apiError)
+	case api.UNAUTHORIZED_RESOURCES_ERROR:
+		h.RespondForbidden(r, requestInfo, w, apiError)
+	case api.PRECONDITION_FAILED_ERROR:
+		h.RespondPreconditionFailed(r, requestInfo, w, apiError)
+	case api.INVALID_PARAMETER_ERROR:
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+	default:
+		h.RespondInternalServerError(r, requestInfo, w)
+	}
+}
@@ -362,12 +362,15 @@ func TestWorkerHandler_HandleListGroups(t *testing.T) {
 		// API method args
 		org        string
 		pathPrefix string
+		offset     string
+		limit      string
 		// Expected result
 		expectedStatusCode int
 		expectedResponse   ListGroupsResponse
 		expectedError      api.Error
 		// Manager Results
-		getListGroupResult []api.GroupIdentity
+		getListGroupResult []api.Group
+		getListGroupTotal  int
 		// Manager Errors
 		getListGroupsErr error
 	}{
@@ -377,13 +380,45 @@ func TestWorkerHandler_HandleListGroups(t *testing.T) {
 			expectedStatusCode: http.StatusOK,
 			expectedResponse: ListGroupsResponse{
 				Groups: []string{"group1"},
+				Total:  1,
 			},
-			getListGroupResult: []api.GroupIdentity{
+			getListGroupResult: []api.Group{
 				{
 					Org:  "org1",
 					Name: "group1",
 				},
 			},
+			getListGroupTotal: 1,
+		},
+		"OkCasePaging": {
+			org:                "org1",
+			pathPrefix:         "path",
+			offset:             "1",
+			limit:              "1",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: ListGroupsResponse{
+				Groups: []string{"group1"},
+				Offset: 1,
+				Limit:  1,
+				Total:  1,
+			},
+			getListGroupResult: []api.Group{
+				{
+					Org:  "org1",
+					Name: "group1",
+				},
+			},
+			getListGroupTotal: 1,
+		},
+		"ErrorCaseInvalidLimit": {
+			org:                "org1",
+			pathPrefix:         "path",
+			limit:              "a",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Limit a",
+			},
 		},
 		"ErrorCaseUnauthorizedError": {
 			org:                "org1",
@@ -427,7 +462,8 @@ func TestWorkerHandler_HandleListGroups(t *testing.T) {
 	for n, test := range testcases {
 
 		testApi.ArgsOut[ListGroupsMethod][0] = test.getListGroupResult
-		testApi.ArgsOut[ListGroupsMethod][1] = test.getListGroupsErr
+		testApi.ArgsOut[ListGroupsMethod][1] = test.getListGroupTotal
+		testApi.ArgsOut[ListGroupsMethod][2] = test.getListGroupsErr
 
 		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups?PathPrefix=", test.org, test.pathPrefix)
 		req, err := http.NewRequest(http.MethodGet, url, nil)
@@ -436,11 +472,17 @@ func TestWorkerHandler_HandleListGroups(t *testing.T) {
 			continue
 		}
 
+		q := req.URL.Query()
 		if test.pathPrefix != "" {
-			q := req.URL.Query()
 			q.Add("PathPrefix", test.pathPrefix)
-			req.URL.RawQuery = q.Encode()
 		}
+		if test.offset != "" {
+			q.Add("Offset", test.offset)
+		}
+		if test.limit != "" {
+			q.Add("Limit", test.limit)
+		}
+		req.URL.RawQuery = q.Encode()
 
 		res, err := client.Do(req)
 		if err != nil {
@@ -448,14 +490,17 @@ func TestWorkerHandler_HandleListGroups(t *testing.T) {
 			continue
 		}
 
-		// Check received parameter
-		if testApi.ArgsIn[ListGroupsMethod][1] != test.org {
-			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[ListGroupsMethod][1])
-			continue
-		}
-		if testApi.ArgsIn[ListGroupsMethod][2] != test.pathPrefix {
-			t.Errorf("Test case %v. Received different PathPrefix (wanted:%v / received:%v)", n, test.pathPrefix, testApi.ArgsIn[ListGroupsMethod][2])
-			continue
+		// Check received parameters
+		if test.expectedStatusCode != http.StatusBadRequest {
+			filter := testApi.ArgsIn[ListGroupsMethod][1].(*api.Filter)
+			if filter.Org != test.org {
+				t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, filter.Org)
+				continue
+			}
+			if filter.PathPrefix != test.pathPrefix {
+				t.Errorf("Test case %v. Received different PathPrefix (wanted:%v / received:%v)", n, test.pathPrefix, filter.PathPrefix)
+				continue
+			}
 		}
 
 		// check status code
@@ -499,12 +544,15 @@ func TestWorkerHandler_HandleListAllGroups(t *testing.T) {
 	testcases := map[string]struct {
 		// API method args
 		pathPrefix string
+		offset     string
+		limit      string
 		// Expected result
 		expectedStatusCode int
 		expectedResponse   ListAllGroupsResponse
 		expectedError      api.Error
 		// Manager Results
-		getListAllGroupResult []api.GroupIdentity
+		getListAllGroupResult []api.Group
+		getListAllGroupTotal  int
 		// Manager Errors
 		getListAllGroupErr error
 	}{
@@ -512,19 +560,54 @@ func TestWorkerHandler_HandleListAllGroups(t *testing.T) {
 			pathPrefix:         "/path/",
 			expectedStatusCode: http.StatusOK,
 			expectedResponse: ListAllGroupsResponse{
-				[]api.GroupIdentity{
+				Groups: []api.GroupIdentity{
+					{
+						Org:  "org1",
+						Name: "group1",
+					},
+				},
+				Total: 1,
+			},
+			getListAllGroupResult: []api.Group{
+				{
+					Org:  "org1",
+					Name: "group1",
+				},
+			},
+			getListAllGroupTotal: 1,
+		},
+		"OkCasePaging": {
+			pathPrefix:         "/path/",
+			offset:             "1",
+			limit:              "1",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: ListAllGroupsResponse{
+				Groups: []api.GroupIdentity{
 					{
 						Org:  "org1",
 						Name: "group1",
 					},
 				},
+				Offset: 1,
+				Limit:  1,
+				Total:  1,
 			},
-			getListAllGroupResult: []api.GroupIdentity{
+			getListAllGroupResult: []api.Group{
 				{
 					Org:  "org1",
 					Name: "group1",
 				},
 			},
+			getListAllGroupTotal: 1,
+		},
+		"ErrorCaseInvalidLimit": {
+			pathPrefix:         "/path/",
+			limit:              "a",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Limit a",
+			},
 		},
 		"ErrorCaseUnauthorizedError": {
 			pathPrefix:         "/path/",
@@ -565,7 +648,8 @@ func TestWorkerHandler_HandleListAllGroups(t *testing.T) {
 	for n, test := range testcases {
 
 		testApi.ArgsOut[ListGroupsMethod][0] = test.getListAllGroupResult
-		testApi.ArgsOut[ListGroupsMethod][1] = test.getListAllGroupErr
+		testApi.ArgsOut[ListGroupsMethod][1] = test.getListAllGroupTotal
+		testApi.ArgsOut[ListGroupsMethod][2] = test.getListAllGroupErr
 
 		url := fmt.Sprintf(server.URL+API_VERSION_1+"/groups?PathPrefix=%v", test.pathPrefix)
 		req, err := http.NewRequest(http.MethodGet, url, nil)
@@ -574,20 +658,32 @@ func TestWorkerHandler_HandleListAllGroups(t *testing.T) {
 			continue
 		}
 
+		q := req.URL.Query()
+		if test.offset != "" {
+			q.Add("Offset", test.offset)
+		}
+		if test.limit != "" {
+			q.Add("Limit", test.limit)
+		}
+		req.URL.RawQuery = q.Encode()
+
 		res, err := client.Do(req)
 		if err != nil {
 			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
 			continue
 		}
 
-		// Check received parameter
-		if testApi.ArgsIn[ListGroupsMethod][1] != "" {
-			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, "", testApi.ArgsIn[ListGroupsMethod][1])
-			continue
-		}
-		if testApi.ArgsIn[ListGroupsMethod][2] != test.pathPrefix {
-			t.Errorf("Test case %v. Received different PathPrefix (wanted:%v / received:%v)", n, test.pathPrefix, testApi.ArgsIn[ListGroupsMethod][2])
-			continue
+		// Check received parameters
+		if test.expectedStatusCode != http.StatusBadRequest {
+			filter := testApi.ArgsIn[ListGroupsMethod][1].(*api.Filter)
+			if filter.Org != "" {
+				t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, "", filter.Org)
+				continue
+			}
+			if filter.PathPrefix != test.pathPrefix {
+				t.Errorf("Test case %v. Received different PathPrefix (wanted:%v / received:%v)", n, test.pathPrefix, filter.PathPrefix)
+				continue
+			}
 		}
 
 		// check status code
@@ -632,13 +728,17 @@ func TestWorkerHandler_HandleUpdateGroup(t *testing.T) {
 	testcases := map[string]struct {
 		// API method args
 		org     string
+		query   string
 		request *UpdateGroupRequest
 		// Expected result
 		expectedStatusCode int
 		expectedResponse   *api.Group
+		expectedReferences []api.StatementReference
+		expectedRewrite    bool
 		expectedError      api.Error
 		// Manager Results
-		updateGroupResult *api.Group
+		updateGroupResult     *api.Group
+		updateGroupReferences []api.StatementReference
 		// Manager Errors
 		updateGroupErr error
 	}{
@@ -664,6 +764,73 @@ func TestWorkerHandler_HandleUpdateGroup(t *testing.T) {
 				CreateAt: now,
 			},
 		},
+		"OkCaseDanglingReferences": {
+			org: "org1",
+			request: &UpdateGroupRequest{
+				Name: "group1",
+				Path: "/new/",
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.Group{
+				ID:   "GroupID",
+				Name: "group1",
+				Path: "/new/",
+				Urn:  "urn",
+			},
+			expectedReferences: []api.StatementReference{
+				{
+					Org:        "org1",
+					PolicyName: "policy",
+					Sid:        "members",
+				},
+			},
+			updateGroupResult: &api.Group{
+				ID:   "GroupID",
+				Name: "group1",
+				Path: "/new/",
+				Urn:  "urn",
+			},
+			updateGroupReferences: []api.StatementReference{
+				{
+					Org:        "org1",
+					PolicyName: "policy",
+					Sid:        "members",
+				},
+			},
+		},
+		"OkCaseRewriteReferences": {
+			org:   "org1",
+			query: "?RewriteReferences=true",
+			request: &UpdateGroupRequest{
+				Name: "group1",
+				Path: "/new/",
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.Group{
+				ID:   "GroupID",
+				Name: "group1",
+				Path: "/new/",
+				Urn:  "urn",
+			},
+			expectedReferences: []api.StatementReference{},
+			expectedRewrite:    true,
+			updateGroupResult: &api.Group{
+				ID:   "GroupID",
+				Name: "group1",
+				Path: "/new/",
+				Urn:  "urn",
+			},
+			updateGroupReferences: []api.StatementReference{},
+		},
+		"ErrorCaseInvalidRewriteReferences": {
+			org:                "org1",
+			query:              "?RewriteReferences=maybe",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: RewriteReferences maybe",
+			},
+		},
 		"ErrorCaseMalformedRequest": {
 			expectedStatusCode: http.StatusBadRequest,
 			expectedError: api.Error{
@@ -716,6 +883,21 @@ func TestWorkerHandler_HandleUpdateGroup(t *testing.T) {
 				Message: "Group already exist",
 			},
 		},
+		"ErrorCasePreconditionFailed": {
+			request: &UpdateGroupRequest{
+				Name: "newName",
+				Path: "newPath",
+			},
+			expectedStatusCode: http.StatusPreconditionFailed,
+			expectedError: api.Error{
+				Code:    api.PRECONDITION_FAILED_ERROR,
+				Message: "Resource urn has been modified since it was retrieved",
+			},
+			updateGroupErr: &api.Error{
+				Code:    api.PRECONDITION_FAILED_ERROR,
+				Message: "Resource urn has been modified since it was retrieved",
+			},
+		},
 		"ErrorCaseUnauthorizedResourcesError": {
 			request: &UpdateGroupRequest{
 				Name: "newName",
@@ -749,7 +931,8 @@ func TestWorkerHandler_HandleUpdateGroup(t *testing.T) {
 	for n, test := range testcases {
 
 		testApi.ArgsOut[UpdateGroupMethod][0] = test.updateGroupResult
-		testApi.ArgsOut[UpdateGroupMethod][1] = test.updateGroupErr
+		testApi.ArgsOut[UpdateGroupMethod][1] = test.updateGroupReferences
+		testApi.ArgsOut[UpdateGroupMethod][2] = test.updateGroupErr
 
 		var body *bytes.Buffer
 		if test.request != nil {
@@ -764,7 +947,7 @@ func TestWorkerHandler_HandleUpdateGroup(t *testing.T) {
 			body = bytes.NewBuffer([]byte{})
 		}
 
-		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups/group1", test.org)
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups/group1%v", test.org, test.query)
 		req, err := http.NewRequest(http.MethodPut, url, body)
 		if err != nil {
 			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
@@ -795,6 +978,10 @@ func TestWorkerHandler_HandleUpdateGroup(t *testing.T) {
 				t.Errorf("Test case %v. Received different Path (wanted:%v / received:%v)", n, test.request.Path, testApi.ArgsIn[UpdateGroupMethod][4])
 				continue
 			}
+			if rewrite := testApi.ArgsIn[UpdateGroupMethod][0].(api.RequestInfo).RewriteReferences; rewrite != test.expectedRewrite {
+				t.Errorf("Test case %v. Received different RewriteReferences (wanted:%v / received:%v)", n, test.expectedRewrite, rewrite)
+				continue
+			}
 
 		}
 
@@ -806,17 +993,21 @@ func TestWorkerHandler_HandleUpdateGroup(t *testing.T) {
 
 		switch res.StatusCode {
 		case http.StatusOK:
-			response := api.Group{}
+			response := UpdateGroupResponse{}
 			err = json.NewDecoder(res.Body).Decode(&response)
 			if err != nil {
 				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
 				continue
 			}
 			// Check result
-			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+			if diff := pretty.Compare(response.Group, test.expectedResponse); diff != "" {
 				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
 				continue
 			}
+			if diff := pretty.Compare(response.DanglingReferences, test.expectedReferences); test.expectedReferences != nil && diff != "" {
+				t.Errorf("Test %v failed. Received different references (received/wanted) %v", n, diff)
+				continue
+			}
 		case http.StatusInternalServerError: // Empty message so continue
 			continue
 		default:
@@ -838,8 +1029,11 @@ func TestWorkerHandler_HandleUpdateGroup(t *testing.T) {
 func TestWorkerHandler_HandleRemoveGroup(t *testing.T) {
 	testcases := map[string]struct {
 		// API method args
-		org  string
-		name string
+		org     string
+		name    string
+		ifMatch string
+		// Worker config
+		requireIfMatch bool
 		// Expected result
 		expectedStatusCode int
 		expectedError      api.Error
@@ -851,6 +1045,35 @@ func TestWorkerHandler_HandleRemoveGroup(t *testing.T) {
 			name:               "group1",
 			expectedStatusCode: http.StatusNoContent,
 		},
+		"OkCaseIfMatch": {
+			org:                "org1",
+			name:               "group1",
+			ifMatch:            "\"etag\"",
+			requireIfMatch:     true,
+			expectedStatusCode: http.StatusNoContent,
+		},
+		"ErrorCasePreconditionFailed": {
+			org:     "org1",
+			name:    "group1",
+			ifMatch: "\"etag\"",
+			removeGroupErr: &api.Error{
+				Code: api.PRECONDITION_FAILED_ERROR,
+			},
+			expectedStatusCode: http.StatusPreconditionFailed,
+			expectedError: api.Error{
+				Code: api.PRECONDITION_FAILED_ERROR,
+			},
+		},
+		"ErrorCasePreconditionRequired": {
+			org:                "org1",
+			name:               "group1",
+			requireIfMatch:     true,
+			expectedStatusCode: http.StatusPreconditionRequired,
+			expectedError: api.Error{
+				Code:    api.PRECONDITION_REQUIRED_ERROR,
+				Message: "Header If-Match is required to modify resources",
+			},
+		},
 		"ErrorCaseGroupNotFound": {
 			org:                "org1",
 			name:               "group1",
@@ -904,8 +1127,9 @@ func TestWorkerHandler_HandleRemoveGroup(t *testing.T) {
 	client := http.DefaultClient
 
 	for n, test := range testcases {
-
+		testApi.ArgsIn[RemoveGroupMethod] = make([]interface{}, 3)
 		testApi.ArgsOut[RemoveGroupMethod][0] = test.removeGroupErr
+		worker.RequireIfMatch = test.requireIfMatch
 
 		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups/%v", test.org, test.name)
 		req, err := http.NewRequest(http.MethodDelete, url, nil)
@@ -913,6 +1137,9 @@ func TestWorkerHandler_HandleRemoveGroup(t *testing.T) {
 			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
 			continue
 		}
+		if test.ifMatch != "" {
+			req.Header.Set(IF_MATCH_HEADER, test.ifMatch)
+		}
 
 		res, err := client.Do(req)
 		if err != nil {
@@ -920,14 +1147,25 @@ func TestWorkerHandler_HandleRemoveGroup(t *testing.T) {
 			continue
 		}
 
-		// Check received parameters
-		if testApi.ArgsIn[RemoveGroupMethod][1] != test.org {
-			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[RemoveGroupMethod][1])
-			continue
-		}
-		if testApi.ArgsIn[RemoveGroupMethod][2] != test.name {
-			t.Errorf("Test case %v. Received different Name (wanted:%v / received:%v)", n, test.name, testApi.ArgsIn[RemoveGroupMethod][2])
-			continue
+		// Check received parameters, API isn't called without required If-Match
+		if test.expectedStatusCode == http.StatusPreconditionRequired {
+			if testApi.ArgsIn[RemoveGroupMethod][0] != nil {
+				t.Errorf("Test case %v. Unexpected call to API without If-Match header", n)
+				continue
+			}
+		} else {
+			if testApi.ArgsIn[RemoveGroupMethod][0].(api.RequestInfo).IfMatch != test.ifMatch {
+				t.Errorf("Test case %v. Received different If-Match (wanted:%v / received:%v)", n, test.ifMatch, testApi.ArgsIn[RemoveGroupMethod][0].(api.RequestInfo).IfMatch)
+				continue
+			}
+			if testApi.ArgsIn[RemoveGroupMethod][1] != test.org {
+				t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[RemoveGroupMethod][1])
+				continue
+			}
+			if testApi.ArgsIn[RemoveGroupMethod][2] != test.name {
+				t.Errorf("Test case %v. Received different Name (wanted:%v / received:%v)", n, test.name, testApi.ArgsIn[RemoveGroupMethod][2])
+				continue
+			}
 		}
 
 		// check status code
@@ -956,102 +1194,891 @@ func TestWorkerHandler_HandleRemoveGroup(t *testing.T) {
 			}
 		}
 	}
+	worker.RequireIfMatch = false
 }
 
-func TestWorkerHandler_HandleAddMember(t *testing.T) {
+func TestWorkerHandler_HandleSetGroupMembershipRule(t *testing.T) {
 	testcases := map[string]struct {
 		// API method args
-		org       string
-		userID    string
-		groupName string
+		request *api.MembershipRule
 		// Expected result
 		expectedStatusCode int
+		expectedResponse   *api.Group
 		expectedError      api.Error
+		// Manager Results
+		setGroupMembershipRuleResult *api.Group
 		// Manager Errors
-		addMemberErr error
+		setGroupMembershipRuleErr error
 	}{
 		"OkCase": {
-			org:                "org1",
-			userID:             "user1",
-			groupName:          "group1",
-			expectedStatusCode: http.StatusNoContent,
+			request: &api.MembershipRule{
+				PathPrefix:     "/engineering/",
+				AttributeKey:   "team",
+				AttributeValue: "backend",
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.Group{
+				ID:   "GroupID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  "urn",
+				MembershipRule: &api.MembershipRule{
+					PathPrefix:     "/engineering/",
+					AttributeKey:   "team",
+					AttributeValue: "backend",
+				},
+			},
+			setGroupMembershipRuleResult: &api.Group{
+				ID:   "GroupID",
+				Name: "group1",
+				Org:  "org1",
+				Path: "/path/",
+				Urn:  "urn",
+				MembershipRule: &api.MembershipRule{
+					PathPrefix:     "/engineering/",
+					AttributeKey:   "team",
+					AttributeValue: "backend",
+				},
+			},
 		},
-		"ErrorCaseGroupNotFoundErr": {
-			org:                "org1",
-			userID:             "user1",
-			groupName:          "Invalid Group",
+		"ErrorCaseMalformedRequest": {
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseGroupNotFound": {
+			request: &api.MembershipRule{
+				PathPrefix: "/engineering/",
+			},
 			expectedStatusCode: http.StatusNotFound,
 			expectedError: api.Error{
 				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
-				Message: "Group Not Found",
+				Message: "Group not found",
 			},
-			addMemberErr: &api.Error{
+			setGroupMembershipRuleErr: &api.Error{
 				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
-				Message: "Group Not Found",
+				Message: "Group not found",
 			},
 		},
-		"ErrorCaseUserNotFoundErr": {
-			org:                "org1",
-			userID:             "Invalid User",
-			groupName:          "group1",
-			expectedStatusCode: http.StatusNotFound,
+		"ErrorCaseInvalidParameterError": {
+			request: &api.MembershipRule{
+				PathPrefix: "invalid",
+			},
+			expectedStatusCode: http.StatusBadRequest,
 			expectedError: api.Error{
-				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
-				Message: "User Not Found",
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
 			},
-			addMemberErr: &api.Error{
-				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
-				Message: "User Not Found",
+			setGroupMembershipRuleErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
 			},
 		},
-		"ErrorCaseUnauthorizedError": {
-			org:                "org1",
-			userID:             "user1",
-			groupName:          "group1",
+		"ErrorCaseUnauthorizedResourcesError": {
+			request: &api.MembershipRule{
+				PathPrefix: "/engineering/",
+			},
 			expectedStatusCode: http.StatusForbidden,
 			expectedError: api.Error{
 				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
 				Message: "Unauthorized",
 			},
-			addMemberErr: &api.Error{
+			setGroupMembershipRuleErr: &api.Error{
 				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
 				Message: "Unauthorized",
 			},
 		},
-		"ErrorCaseInvalidParameterErr": {
-			org:                "org1",
-			userID:             "user1",
-			groupName:          "group1",
+		"ErrorCasePreconditionFailedError": {
+			request: &api.MembershipRule{
+				PathPrefix: "/engineering/",
+			},
+			expectedStatusCode: http.StatusPreconditionFailed,
+			expectedError: api.Error{
+				Code:    api.PRECONDITION_FAILED_ERROR,
+				Message: "Modified",
+			},
+			setGroupMembershipRuleErr: &api.Error{
+				Code:    api.PRECONDITION_FAILED_ERROR,
+				Message: "Modified",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			request: &api.MembershipRule{
+				PathPrefix: "/engineering/",
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			setGroupMembershipRuleErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[SetGroupMembershipRuleMethod][0] = test.setGroupMembershipRuleResult
+		testApi.ArgsOut[SetGroupMembershipRuleMethod][1] = test.setGroupMembershipRuleErr
+
+		body := bytes.NewBuffer([]byte{})
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+
+		url := server.URL + API_VERSION_1 + "/organizations/org1/groups/group1/membership-rule"
+		req, err := http.NewRequest(http.MethodPut, url, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		if test.request != nil {
+			// Check received parameters
+			if testApi.ArgsIn[SetGroupMembershipRuleMethod][1] != "org1" {
+				t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, "org1", testApi.ArgsIn[SetGroupMembershipRuleMethod][1])
+				continue
+			}
+			if testApi.ArgsIn[SetGroupMembershipRuleMethod][2] != "group1" {
+				t.Errorf("Test case %v. Received different Name (wanted:%v / received:%v)", n, "group1", testApi.ArgsIn[SetGroupMembershipRuleMethod][2])
+				continue
+			}
+			if diff := pretty.Compare(testApi.ArgsIn[SetGroupMembershipRuleMethod][3], test.request); diff != "" {
+				t.Errorf("Test %v failed. Received different rule (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			response := &api.Group{}
+			err = json.NewDecoder(res.Body).Decode(response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleRemoveGroupMembershipRule(t *testing.T) {
+	testcases := map[string]struct {
+		// Expected result
+		expectedStatusCode int
+		expectedError      api.Error
+		// Manager Errors
+		setGroupMembershipRuleErr error
+	}{
+		"OkCase": {
+			expectedStatusCode: http.StatusNoContent,
+		},
+		"ErrorCaseGroupNotFound": {
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group not found",
+			},
+			setGroupMembershipRuleErr: &api.Error{
+				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group not found",
+			},
+		},
+		"ErrorCaseUnauthorizedResourcesError": {
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			setGroupMembershipRuleErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			expectedStatusCode: http.StatusInternalServerError,
+			setGroupMembershipRuleErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[SetGroupMembershipRuleMethod][0] = nil
+		testApi.ArgsOut[SetGroupMembershipRuleMethod][1] = test.setGroupMembershipRuleErr
+
+		url := server.URL + API_VERSION_1 + "/organizations/org1/groups/group1/membership-rule"
+		req, err := http.NewRequest(http.MethodDelete, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if rule := testApi.ArgsIn[SetGroupMembershipRuleMethod][3].(*api.MembershipRule); rule != nil {
+			t.Errorf("Test case %v. Received unexpected rule %v", n, rule)
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusNoContent:
+			// No message expected
+			continue
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleAddMember(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		org       string
+		userID    string
+		groupName string
+		// Expected result
+		expectedStatusCode int
+		expectedError      api.Error
+		// Manager Errors
+		addMemberErr error
+	}{
+		"OkCase": {
+			org:                "org1",
+			userID:             "user1",
+			groupName:          "group1",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		"ErrorCaseGroupNotFoundErr": {
+			org:                "org1",
+			userID:             "user1",
+			groupName:          "Invalid Group",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group Not Found",
+			},
+			addMemberErr: &api.Error{
+				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group Not Found",
+			},
+		},
+		"ErrorCaseUserNotFoundErr": {
+			org:                "org1",
+			userID:             "Invalid User",
+			groupName:          "group1",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User Not Found",
+			},
+			addMemberErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User Not Found",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			org:                "org1",
+			userID:             "user1",
+			groupName:          "group1",
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			addMemberErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseInvalidParameterErr": {
+			org:                "org1",
+			userID:             "user1",
+			groupName:          "group1",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+			addMemberErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+		},
+		"ErrorCaseUserIsAlreadyMemberErr": {
+			org:                "org1",
+			userID:             "user1",
+			groupName:          "group1",
+			expectedStatusCode: http.StatusConflict,
+			expectedError: api.Error{
+				Code:    api.USER_IS_ALREADY_A_MEMBER_OF_GROUP,
+				Message: "User is already a member of group",
+			},
+			addMemberErr: &api.Error{
+				Code:    api.USER_IS_ALREADY_A_MEMBER_OF_GROUP,
+				Message: "User is already a member of group",
+			},
+		},
+		"ErrorCaseGroupHasMembershipRuleErr": {
+			org:                "org1",
+			userID:             "user1",
+			groupName:          "group1",
+			expectedStatusCode: http.StatusConflict,
+			expectedError: api.Error{
+				Code:    api.GROUP_HAS_MEMBERSHIP_RULE,
+				Message: "Group has membership rule",
+			},
+			addMemberErr: &api.Error{
+				Code:    api.GROUP_HAS_MEMBERSHIP_RULE,
+				Message: "Group has membership rule",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			org:                "org1",
+			userID:             "user1",
+			groupName:          "group1",
+			expectedStatusCode: http.StatusInternalServerError,
+			addMemberErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[AddMemberMethod][0] = test.addMemberErr
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups/%v/users/%v", test.org, test.groupName, test.userID)
+		req, err := http.NewRequest(http.MethodPost, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[AddMemberMethod][1] != test.userID {
+			t.Errorf("Test case %v. Received different UserID (wanted:%v / received:%v)", n, test.userID, testApi.ArgsIn[AddMemberMethod][1])
+			continue
+		}
+		if testApi.ArgsIn[AddMemberMethod][2] != test.groupName {
+			t.Errorf("Test case %v. Received different GroupName (wanted:%v / received:%v)", n, test.groupName, testApi.ArgsIn[AddMemberMethod][2])
+			continue
+		}
+		if testApi.ArgsIn[AddMemberMethod][3] != test.org {
+			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[AddMemberMethod][3])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusNoContent:
+			// No message expected
+			continue
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleRemoveMember(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		org       string
+		userID    string
+		groupName string
+		// Expected result
+		expectedStatusCode int
+		expectedError      api.Error
+		// Manager Errors
+		removeMemberErr error
+	}{
+		"OkCase": {
+			org:                "org1",
+			userID:             "user1",
+			groupName:          "group1",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		"ErrorCaseGroupNotFoundErr": {
+			org:                "org1",
+			userID:             "user1",
+			groupName:          "Invalid Group",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group Not Found",
+			},
+			removeMemberErr: &api.Error{
+				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group Not Found",
+			},
+		},
+		"ErrorCaseUserNotFoundErr": {
+			org:                "org1",
+			userID:             "Invalid User",
+			groupName:          "group1",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User Not Found",
+			},
+			removeMemberErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User Not Found",
+			},
+		},
+		"ErrorCaseUserIsNotMemberErr": {
+			org:                "org1",
+			userID:             "user1",
+			groupName:          "group1",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_IS_NOT_A_MEMBER_OF_GROUP,
+				Message: "User is not a member",
+			},
+			removeMemberErr: &api.Error{
+				Code:    api.USER_IS_NOT_A_MEMBER_OF_GROUP,
+				Message: "User is not a member",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			org:                "org1",
+			userID:             "user1",
+			groupName:          "group1",
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			removeMemberErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseInvalidParameterErr": {
+			org:                "org1",
+			userID:             "user1",
+			groupName:          "group1",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+			removeMemberErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			org:                "org1",
+			userID:             "user1",
+			groupName:          "group1",
+			expectedStatusCode: http.StatusInternalServerError,
+			removeMemberErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[RemoveMemberMethod][0] = test.removeMemberErr
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups/%v/users/%v", test.org, test.groupName, test.userID)
+		req, err := http.NewRequest(http.MethodDelete, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[RemoveMemberMethod][1] != test.userID {
+			t.Errorf("Test case %v. Received different UserID (wanted:%v / received:%v)", n, test.userID, testApi.ArgsIn[RemoveMemberMethod][2])
+			continue
+		}
+		if testApi.ArgsIn[RemoveMemberMethod][2] != test.groupName {
+			t.Errorf("Test case %v. Received different GroupName (wanted:%v / received:%v)", n, test.groupName, testApi.ArgsIn[RemoveMemberMethod][2])
+			continue
+		}
+		if testApi.ArgsIn[RemoveMemberMethod][3] != test.org {
+			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[RemoveMemberMethod][1])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusNoContent:
+			// No message expected
+			continue
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleListMembers(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		org    string
+		name   string
+		offset string
+		limit  string
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   ListMembersResponse
+		expectedError      api.Error
+		// Manager Results
+		getListMembersResult []string
+		getListMembersTotal  int
+		// Manager Errors
+		getListMembersErr error
+	}{
+		"OkCase": {
+			org:                "org1",
+			name:               "group1",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: ListMembersResponse{
+				Members: []string{"member1", "member2"},
+				Total:   2,
+			},
+			getListMembersResult: []string{"member1", "member2"},
+			getListMembersTotal:  2,
+		},
+		"OkCasePaging": {
+			org:                "org1",
+			name:               "group1",
+			offset:             "1",
+			limit:              "1",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: ListMembersResponse{
+				Members: []string{"member1", "member2"},
+				Offset:  1,
+				Limit:   1,
+				Total:   2,
+			},
+			getListMembersResult: []string{"member1", "member2"},
+			getListMembersTotal:  2,
+		},
+		"ErrorCaseInvalidLimit": {
+			org:                "org1",
+			name:               "group1",
+			limit:              "a",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Limit a",
+			},
+		},
+		"ErrorCaseGroupNotFoundErr": {
+			org:                "org1",
+			name:               "group1",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group Not Found",
+			},
+			getListMembersErr: &api.Error{
+				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group Not Found",
+			},
+		},
+		"ErrorCaseInvalidParameterErr": {
+			org:                "org1",
+			name:               "group1",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+			getListMembersErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			org:                "org1",
+			name:               "group1",
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			getListMembersErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			expectedStatusCode: http.StatusInternalServerError,
+			getListMembersErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[ListMembersMethod][0] = test.getListMembersResult
+		testApi.ArgsOut[ListMembersMethod][1] = test.getListMembersTotal
+		testApi.ArgsOut[ListMembersMethod][2] = test.getListMembersErr
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups/%v/users", test.org, test.name)
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		q := req.URL.Query()
+		if test.offset != "" {
+			q.Add("Offset", test.offset)
+		}
+		if test.limit != "" {
+			q.Add("Limit", test.limit)
+		}
+		req.URL.RawQuery = q.Encode()
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if test.expectedStatusCode != http.StatusBadRequest {
+			if testApi.ArgsIn[ListMembersMethod][1] != test.org {
+				t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[ListMembersMethod][1])
+				continue
+			}
+			if testApi.ArgsIn[ListMembersMethod][2] != test.name {
+				t.Errorf("Test case %v. Received different Name (wanted:%v / received:%v)", n, test.name, testApi.ArgsIn[ListMembersMethod][2])
+				continue
+			}
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			getGroupMembersResponse := ListMembersResponse{}
+			err = json.NewDecoder(res.Body).Decode(&getGroupMembersResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(getGroupMembersResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleListGroupOwners(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		org  string
+		name string
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   ListGroupOwnersResponse
+		expectedError      api.Error
+		// Manager Results
+		listGroupOwnersResult []string
+		// Manager Errors
+		listGroupOwnersErr error
+	}{
+		"OkCase": {
+			org:                "org1",
+			name:               "group1",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: ListGroupOwnersResponse{
+				Owners: []string{"owner1", "owner2"},
+			},
+			listGroupOwnersResult: []string{"owner1", "owner2"},
+		},
+		"ErrorCaseGroupNotFoundErr": {
+			org:                "org1",
+			name:               "group1",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group Not Found",
+			},
+			listGroupOwnersErr: &api.Error{
+				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group Not Found",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			org:                "org1",
+			name:               "group1",
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			listGroupOwnersErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseInvalidParameterErr": {
+			org:                "org1",
+			name:               "group1",
 			expectedStatusCode: http.StatusBadRequest,
 			expectedError: api.Error{
 				Code:    api.INVALID_PARAMETER_ERROR,
 				Message: "Invalid Parameter",
 			},
-			addMemberErr: &api.Error{
+			listGroupOwnersErr: &api.Error{
 				Code:    api.INVALID_PARAMETER_ERROR,
 				Message: "Invalid Parameter",
 			},
 		},
-		"ErrorCaseUserIsAlreadyMemberErr": {
-			org:                "org1",
-			userID:             "user1",
-			groupName:          "group1",
-			expectedStatusCode: http.StatusConflict,
-			expectedError: api.Error{
-				Code:    api.USER_IS_ALREADY_A_MEMBER_OF_GROUP,
-				Message: "User is already a member of group",
-			},
-			addMemberErr: &api.Error{
-				Code:    api.USER_IS_ALREADY_A_MEMBER_OF_GROUP,
-				Message: "User is already a member of group",
-			},
-		},
 		"ErrorCaseUnknownApiError": {
 			org:                "org1",
-			userID:             "user1",
-			groupName:          "group1",
+			name:               "group1",
 			expectedStatusCode: http.StatusInternalServerError,
-			addMemberErr: &api.Error{
+			listGroupOwnersErr: &api.Error{
 				Code:    api.UNKNOWN_API_ERROR,
 				Message: "Error",
 			},
@@ -1062,10 +2089,11 @@ func TestWorkerHandler_HandleAddMember(t *testing.T) {
 
 	for n, test := range testcases {
 
-		testApi.ArgsOut[AddMemberMethod][0] = test.addMemberErr
+		testApi.ArgsOut[ListGroupOwnersMethod][0] = test.listGroupOwnersResult
+		testApi.ArgsOut[ListGroupOwnersMethod][1] = test.listGroupOwnersErr
 
-		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups/%v/users/%v", test.org, test.groupName, test.userID)
-		req, err := http.NewRequest(http.MethodPost, url, nil)
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups/%v/owners", test.org, test.name)
+		req, err := http.NewRequest(http.MethodGet, url, nil)
 		if err != nil {
 			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
 			continue
@@ -1078,16 +2106,12 @@ func TestWorkerHandler_HandleAddMember(t *testing.T) {
 		}
 
 		// Check received parameters
-		if testApi.ArgsIn[AddMemberMethod][1] != test.userID {
-			t.Errorf("Test case %v. Received different UserID (wanted:%v / received:%v)", n, test.userID, testApi.ArgsIn[AddMemberMethod][1])
-			continue
-		}
-		if testApi.ArgsIn[AddMemberMethod][2] != test.groupName {
-			t.Errorf("Test case %v. Received different GroupName (wanted:%v / received:%v)", n, test.groupName, testApi.ArgsIn[AddMemberMethod][2])
+		if testApi.ArgsIn[ListGroupOwnersMethod][1] != test.org {
+			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[ListGroupOwnersMethod][1])
 			continue
 		}
-		if testApi.ArgsIn[AddMemberMethod][3] != test.org {
-			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[AddMemberMethod][3])
+		if testApi.ArgsIn[ListGroupOwnersMethod][2] != test.name {
+			t.Errorf("Test case %v. Received different Name (wanted:%v / received:%v)", n, test.name, testApi.ArgsIn[ListGroupOwnersMethod][2])
 			continue
 		}
 
@@ -1098,9 +2122,18 @@ func TestWorkerHandler_HandleAddMember(t *testing.T) {
 		}
 
 		switch res.StatusCode {
-		case http.StatusNoContent:
-			// No message expected
-			continue
+		case http.StatusOK:
+			listGroupOwnersResponse := ListGroupOwnersResponse{}
+			err = json.NewDecoder(res.Body).Decode(&listGroupOwnersResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(listGroupOwnersResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
 		case http.StatusInternalServerError: // Empty message so continue
 			continue
 		default:
@@ -1119,7 +2152,7 @@ func TestWorkerHandler_HandleAddMember(t *testing.T) {
 	}
 }
 
-func TestWorkerHandler_HandleRemoveMember(t *testing.T) {
+func TestWorkerHandler_HandleAddGroupOwner(t *testing.T) {
 	testcases := map[string]struct {
 		// API method args
 		org       string
@@ -1129,7 +2162,7 @@ func TestWorkerHandler_HandleRemoveMember(t *testing.T) {
 		expectedStatusCode int
 		expectedError      api.Error
 		// Manager Errors
-		removeMemberErr error
+		addGroupOwnerErr error
 	}{
 		"OkCase": {
 			org:                "org1",
@@ -1140,45 +2173,31 @@ func TestWorkerHandler_HandleRemoveMember(t *testing.T) {
 		"ErrorCaseGroupNotFoundErr": {
 			org:                "org1",
 			userID:             "user1",
-			groupName:          "Invalid Group",
+			groupName:          "group1",
 			expectedStatusCode: http.StatusNotFound,
 			expectedError: api.Error{
 				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
 				Message: "Group Not Found",
 			},
-			removeMemberErr: &api.Error{
+			addGroupOwnerErr: &api.Error{
 				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
 				Message: "Group Not Found",
 			},
 		},
 		"ErrorCaseUserNotFoundErr": {
 			org:                "org1",
-			userID:             "Invalid User",
+			userID:             "user1",
 			groupName:          "group1",
 			expectedStatusCode: http.StatusNotFound,
 			expectedError: api.Error{
 				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
 				Message: "User Not Found",
 			},
-			removeMemberErr: &api.Error{
+			addGroupOwnerErr: &api.Error{
 				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
 				Message: "User Not Found",
 			},
 		},
-		"ErrorCaseUserIsNotMemberErr": {
-			org:                "org1",
-			userID:             "user1",
-			groupName:          "group1",
-			expectedStatusCode: http.StatusNotFound,
-			expectedError: api.Error{
-				Code:    api.USER_IS_NOT_A_MEMBER_OF_GROUP,
-				Message: "User is not a member",
-			},
-			removeMemberErr: &api.Error{
-				Code:    api.USER_IS_NOT_A_MEMBER_OF_GROUP,
-				Message: "User is not a member",
-			},
-		},
 		"ErrorCaseUnauthorizedError": {
 			org:                "org1",
 			userID:             "user1",
@@ -1188,7 +2207,7 @@ func TestWorkerHandler_HandleRemoveMember(t *testing.T) {
 				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
 				Message: "Unauthorized",
 			},
-			removeMemberErr: &api.Error{
+			addGroupOwnerErr: &api.Error{
 				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
 				Message: "Unauthorized",
 			},
@@ -1202,17 +2221,31 @@ func TestWorkerHandler_HandleRemoveMember(t *testing.T) {
 				Code:    api.INVALID_PARAMETER_ERROR,
 				Message: "Invalid Parameter",
 			},
-			removeMemberErr: &api.Error{
+			addGroupOwnerErr: &api.Error{
 				Code:    api.INVALID_PARAMETER_ERROR,
 				Message: "Invalid Parameter",
 			},
 		},
+		"ErrorCaseUserIsAlreadyOwnerErr": {
+			org:                "org1",
+			userID:             "user1",
+			groupName:          "group1",
+			expectedStatusCode: http.StatusConflict,
+			expectedError: api.Error{
+				Code:    api.USER_IS_ALREADY_AN_OWNER_OF_GROUP,
+				Message: "User is already an owner of group",
+			},
+			addGroupOwnerErr: &api.Error{
+				Code:    api.USER_IS_ALREADY_AN_OWNER_OF_GROUP,
+				Message: "User is already an owner of group",
+			},
+		},
 		"ErrorCaseUnknownApiError": {
 			org:                "org1",
 			userID:             "user1",
 			groupName:          "group1",
 			expectedStatusCode: http.StatusInternalServerError,
-			removeMemberErr: &api.Error{
+			addGroupOwnerErr: &api.Error{
 				Code:    api.UNKNOWN_API_ERROR,
 				Message: "Error",
 			},
@@ -1223,10 +2256,10 @@ func TestWorkerHandler_HandleRemoveMember(t *testing.T) {
 
 	for n, test := range testcases {
 
-		testApi.ArgsOut[RemoveMemberMethod][0] = test.removeMemberErr
+		testApi.ArgsOut[AddGroupOwnerMethod][0] = test.addGroupOwnerErr
 
-		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups/%v/users/%v", test.org, test.groupName, test.userID)
-		req, err := http.NewRequest(http.MethodDelete, url, nil)
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups/%v/owners/%v", test.org, test.groupName, test.userID)
+		req, err := http.NewRequest(http.MethodPost, url, nil)
 		if err != nil {
 			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
 			continue
@@ -1239,16 +2272,16 @@ func TestWorkerHandler_HandleRemoveMember(t *testing.T) {
 		}
 
 		// Check received parameters
-		if testApi.ArgsIn[RemoveMemberMethod][1] != test.userID {
-			t.Errorf("Test case %v. Received different UserID (wanted:%v / received:%v)", n, test.userID, testApi.ArgsIn[RemoveMemberMethod][2])
+		if testApi.ArgsIn[AddGroupOwnerMethod][1] != test.org {
+			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[AddGroupOwnerMethod][1])
 			continue
 		}
-		if testApi.ArgsIn[RemoveMemberMethod][2] != test.groupName {
-			t.Errorf("Test case %v. Received different GroupName (wanted:%v / received:%v)", n, test.groupName, testApi.ArgsIn[RemoveMemberMethod][2])
+		if testApi.ArgsIn[AddGroupOwnerMethod][2] != test.groupName {
+			t.Errorf("Test case %v. Received different GroupName (wanted:%v / received:%v)", n, test.groupName, testApi.ArgsIn[AddGroupOwnerMethod][2])
 			continue
 		}
-		if testApi.ArgsIn[RemoveMemberMethod][3] != test.org {
-			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[RemoveMemberMethod][1])
+		if testApi.ArgsIn[AddGroupOwnerMethod][3] != test.userID {
+			t.Errorf("Test case %v. Received different UserID (wanted:%v / received:%v)", n, test.userID, testApi.ArgsIn[AddGroupOwnerMethod][3])
 			continue
 		}
 
@@ -1280,71 +2313,100 @@ func TestWorkerHandler_HandleRemoveMember(t *testing.T) {
 	}
 }
 
-func TestWorkerHandler_HandleListMembers(t *testing.T) {
+func TestWorkerHandler_HandleRemoveGroupOwner(t *testing.T) {
 	testcases := map[string]struct {
 		// API method args
-		org  string
-		name string
+		org       string
+		userID    string
+		groupName string
 		// Expected result
 		expectedStatusCode int
-		expectedResponse   ListMembersResponse
 		expectedError      api.Error
-		// Manager Results
-		getListMembersResult []string
 		// Manager Errors
-		getListMembersErr error
+		removeGroupOwnerErr error
 	}{
 		"OkCase": {
 			org:                "org1",
-			name:               "group1",
-			expectedStatusCode: http.StatusOK,
-			expectedResponse: ListMembersResponse{
-				Members: []string{"member1", "member2"},
-			},
-			getListMembersResult: []string{"member1", "member2"},
+			userID:             "user1",
+			groupName:          "group1",
+			expectedStatusCode: http.StatusNoContent,
 		},
 		"ErrorCaseGroupNotFoundErr": {
 			org:                "org1",
-			name:               "group1",
+			userID:             "user1",
+			groupName:          "group1",
 			expectedStatusCode: http.StatusNotFound,
 			expectedError: api.Error{
 				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
 				Message: "Group Not Found",
 			},
-			getListMembersErr: &api.Error{
+			removeGroupOwnerErr: &api.Error{
 				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
 				Message: "Group Not Found",
 			},
 		},
-		"ErrorCaseInvalidParameterErr": {
+		"ErrorCaseUserNotFoundErr": {
 			org:                "org1",
-			name:               "group1",
-			expectedStatusCode: http.StatusBadRequest,
+			userID:             "user1",
+			groupName:          "group1",
+			expectedStatusCode: http.StatusNotFound,
 			expectedError: api.Error{
-				Code:    api.INVALID_PARAMETER_ERROR,
-				Message: "Invalid Parameter",
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User Not Found",
 			},
-			getListMembersErr: &api.Error{
-				Code:    api.INVALID_PARAMETER_ERROR,
-				Message: "Invalid Parameter",
+			removeGroupOwnerErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User Not Found",
+			},
+		},
+		"ErrorCaseUserIsNotOwnerErr": {
+			org:                "org1",
+			userID:             "user1",
+			groupName:          "group1",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_IS_NOT_AN_OWNER_OF_GROUP,
+				Message: "User is not an owner of group",
+			},
+			removeGroupOwnerErr: &api.Error{
+				Code:    api.USER_IS_NOT_AN_OWNER_OF_GROUP,
+				Message: "User is not an owner of group",
 			},
 		},
 		"ErrorCaseUnauthorizedError": {
 			org:                "org1",
-			name:               "group1",
+			userID:             "user1",
+			groupName:          "group1",
 			expectedStatusCode: http.StatusForbidden,
 			expectedError: api.Error{
 				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
 				Message: "Unauthorized",
 			},
-			getListMembersErr: &api.Error{
+			removeGroupOwnerErr: &api.Error{
 				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
 				Message: "Unauthorized",
 			},
 		},
+		"ErrorCaseInvalidParameterErr": {
+			org:                "org1",
+			userID:             "user1",
+			groupName:          "group1",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+			removeGroupOwnerErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+		},
 		"ErrorCaseUnknownApiError": {
+			org:                "org1",
+			userID:             "user1",
+			groupName:          "group1",
 			expectedStatusCode: http.StatusInternalServerError,
-			getListMembersErr: &api.Error{
+			removeGroupOwnerErr: &api.Error{
 				Code:    api.UNKNOWN_API_ERROR,
 				Message: "Error",
 			},
@@ -1355,11 +2417,10 @@ func TestWorkerHandler_HandleListMembers(t *testing.T) {
 
 	for n, test := range testcases {
 
-		testApi.ArgsOut[ListMembersMethod][0] = test.getListMembersResult
-		testApi.ArgsOut[ListMembersMethod][1] = test.getListMembersErr
+		testApi.ArgsOut[RemoveGroupOwnerMethod][0] = test.removeGroupOwnerErr
 
-		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups/%v/users", test.org, test.name)
-		req, err := http.NewRequest(http.MethodGet, url, nil)
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups/%v/owners/%v", test.org, test.groupName, test.userID)
+		req, err := http.NewRequest(http.MethodDelete, url, nil)
 		if err != nil {
 			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
 			continue
@@ -1371,13 +2432,17 @@ func TestWorkerHandler_HandleListMembers(t *testing.T) {
 			continue
 		}
 
-		// Check received parameter
-		if testApi.ArgsIn[ListMembersMethod][1] != test.org {
-			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[ListMembersMethod][1])
+		// Check received parameters
+		if testApi.ArgsIn[RemoveGroupOwnerMethod][1] != test.org {
+			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[RemoveGroupOwnerMethod][1])
+			continue
+		}
+		if testApi.ArgsIn[RemoveGroupOwnerMethod][2] != test.groupName {
+			t.Errorf("Test case %v. Received different GroupName (wanted:%v / received:%v)", n, test.groupName, testApi.ArgsIn[RemoveGroupOwnerMethod][2])
 			continue
 		}
-		if testApi.ArgsIn[ListMembersMethod][2] != test.name {
-			t.Errorf("Test case %v. Received different Name (wanted:%v / received:%v)", n, test.name, testApi.ArgsIn[ListMembersMethod][2])
+		if testApi.ArgsIn[RemoveGroupOwnerMethod][3] != test.userID {
+			t.Errorf("Test case %v. Received different UserID (wanted:%v / received:%v)", n, test.userID, testApi.ArgsIn[RemoveGroupOwnerMethod][3])
 			continue
 		}
 
@@ -1388,18 +2453,9 @@ func TestWorkerHandler_HandleListMembers(t *testing.T) {
 		}
 
 		switch res.StatusCode {
-		case http.StatusOK:
-			getGroupMembersResponse := ListMembersResponse{}
-			err = json.NewDecoder(res.Body).Decode(&getGroupMembersResponse)
-			if err != nil {
-				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
-				continue
-			}
-			// Check result
-			if diff := pretty.Compare(getGroupMembersResponse, test.expectedResponse); diff != "" {
-				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
-				continue
-			}
+		case http.StatusNoContent:
+			// No message expected
+			continue
 		case http.StatusInternalServerError: // Empty message so continue
 			continue
 		default:
@@ -1419,11 +2475,15 @@ func TestWorkerHandler_HandleListMembers(t *testing.T) {
 }
 
 func TestWorkerHandler_HandleAttachPolicyToGroup(t *testing.T) {
+	notBefore := time.Date(2030, time.January, 1, 3, 0, 0, 0, time.UTC)
+	notAfter := time.Date(2030, time.January, 2, 3, 0, 0, 0, time.UTC)
 	testcases := map[string]struct {
 		// API method args
 		org        string
 		groupName  string
 		policyName string
+		request    *AttachGroupPolicyRequest
+		rawBody    string
 		// Expected result
 		expectedStatusCode int
 		expectedError      api.Error
@@ -1436,6 +2496,27 @@ func TestWorkerHandler_HandleAttachPolicyToGroup(t *testing.T) {
 			policyName:         "policy1",
 			expectedStatusCode: http.StatusNoContent,
 		},
+		"OkCaseWithActivationWindow": {
+			org:        "org1",
+			groupName:  "group1",
+			policyName: "policy1",
+			request: &AttachGroupPolicyRequest{
+				NotBefore: &notBefore,
+				NotAfter:  &notAfter,
+			},
+			expectedStatusCode: http.StatusNoContent,
+		},
+		"ErrorCaseMalformedRequest": {
+			org:                "org1",
+			groupName:          "group1",
+			policyName:         "policy1",
+			rawBody:            "{notBefore: invalid}",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "invalid character 'n' looking for beginning of object key string",
+			},
+		},
 		"ErrorCaseGroupNotFoundErr": {
 			org:                "org1",
 			groupName:          "Invalid Group",
@@ -1524,8 +2605,18 @@ func TestWorkerHandler_HandleAttachPolicyToGroup(t *testing.T) {
 
 		testApi.ArgsOut[AttachPolicyToGroupMethod][0] = test.attachGroupPolicyErr
 
+		body := bytes.NewBufferString(test.rawBody)
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+
 		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups/%v/policies/%v", test.org, test.groupName, test.policyName)
-		req, err := http.NewRequest(http.MethodPost, url, nil)
+		req, err := http.NewRequest(http.MethodPost, url, body)
 		if err != nil {
 			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
 			continue
@@ -1538,6 +2629,13 @@ func TestWorkerHandler_HandleAttachPolicyToGroup(t *testing.T) {
 		}
 
 		// Check received parameters
+		if test.rawBody != "" {
+			// Request rejected before calling API
+			if test.expectedStatusCode != res.StatusCode {
+				t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			}
+			continue
+		}
 		if testApi.ArgsIn[AttachPolicyToGroupMethod][1] != test.org {
 			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[AttachPolicyToGroupMethod][1])
 			continue
@@ -1550,6 +2648,16 @@ func TestWorkerHandler_HandleAttachPolicyToGroup(t *testing.T) {
 			t.Errorf("Test case %v. Received different policyName (wanted:%v / received:%v)", n, test.policyName, testApi.ArgsIn[AttachPolicyToGroupMethod][3])
 			continue
 		}
+		if test.request != nil {
+			if diff := pretty.Compare(testApi.ArgsIn[AttachPolicyToGroupMethod][4], test.request.NotBefore); diff != "" {
+				t.Errorf("Test %v failed. Received different notBefore (received/wanted) %v", n, diff)
+				continue
+			}
+			if diff := pretty.Compare(testApi.ArgsIn[AttachPolicyToGroupMethod][5], test.request.NotAfter); diff != "" {
+				t.Errorf("Test %v failed. Received different notAfter (received/wanted) %v", n, diff)
+				continue
+			}
+		}
 
 		// check status code
 		if test.expectedStatusCode != res.StatusCode {
@@ -1743,14 +2851,17 @@ func TestWorkerHandler_HandleDetachPolicyToGroup(t *testing.T) {
 func TestWorkerHandler_HandleListAttachedGroupPolicies(t *testing.T) {
 	testcases := map[string]struct {
 		// API method args
-		org  string
-		name string
+		org    string
+		name   string
+		offset string
+		limit  string
 		// Expected result
 		expectedStatusCode int
 		expectedResponse   ListAttachedGroupPoliciesResponse
 		expectedError      api.Error
 		// Manager Results
-		getListAttachedGroupPoliciesResult []string
+		getListAttachedGroupPoliciesResult []api.AttachedPolicy
+		getListAttachedGroupPoliciesTotal  int
 		// Manager Errors
 		getListAttachedGroupPoliciesErr error
 	}{
@@ -1759,9 +2870,72 @@ func TestWorkerHandler_HandleListAttachedGroupPolicies(t *testing.T) {
 			name:               "group1",
 			expectedStatusCode: http.StatusOK,
 			expectedResponse: ListAttachedGroupPoliciesResponse{
-				AttachedPolicies: []string{"policy1", "policy2"},
+				AttachedPolicies: []api.AttachedPolicy{
+					{
+						Name:   "policy1",
+						Active: true,
+					},
+					{
+						Name:   "policy2",
+						Active: true,
+					},
+				},
+				Total: 2,
+			},
+			getListAttachedGroupPoliciesResult: []api.AttachedPolicy{
+				{
+					Name:   "policy1",
+					Active: true,
+				},
+				{
+					Name:   "policy2",
+					Active: true,
+				},
+			},
+			getListAttachedGroupPoliciesTotal: 2,
+		},
+		"OkCasePaging": {
+			org:                "org1",
+			name:               "group1",
+			offset:             "1",
+			limit:              "1",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: ListAttachedGroupPoliciesResponse{
+				AttachedPolicies: []api.AttachedPolicy{
+					{
+						Name:   "policy1",
+						Active: true,
+					},
+					{
+						Name:   "policy2",
+						Active: true,
+					},
+				},
+				Offset: 1,
+				Limit:  1,
+				Total:  2,
+			},
+			getListAttachedGroupPoliciesResult: []api.AttachedPolicy{
+				{
+					Name:   "policy1",
+					Active: true,
+				},
+				{
+					Name:   "policy2",
+					Active: true,
+				},
+			},
+			getListAttachedGroupPoliciesTotal: 2,
+		},
+		"ErrorCaseInvalidLimit": {
+			org:                "org1",
+			name:               "group1",
+			limit:              "a",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Limit a",
 			},
-			getListAttachedGroupPoliciesResult: []string{"policy1", "policy2"},
 		},
 		"ErrorCaseGroupNotFoundErr": {
 			org:                "org1",
@@ -1816,7 +2990,8 @@ func TestWorkerHandler_HandleListAttachedGroupPolicies(t *testing.T) {
 	for n, test := range testcases {
 
 		testApi.ArgsOut[ListAttachedGroupPoliciesMethod][0] = test.getListAttachedGroupPoliciesResult
-		testApi.ArgsOut[ListAttachedGroupPoliciesMethod][1] = test.getListAttachedGroupPoliciesErr
+		testApi.ArgsOut[ListAttachedGroupPoliciesMethod][1] = test.getListAttachedGroupPoliciesTotal
+		testApi.ArgsOut[ListAttachedGroupPoliciesMethod][2] = test.getListAttachedGroupPoliciesErr
 
 		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups/%v/policies", test.org, test.name)
 		req, err := http.NewRequest(http.MethodGet, url, nil)
@@ -1825,20 +3000,31 @@ func TestWorkerHandler_HandleListAttachedGroupPolicies(t *testing.T) {
 			continue
 		}
 
+		q := req.URL.Query()
+		if test.offset != "" {
+			q.Add("Offset", test.offset)
+		}
+		if test.limit != "" {
+			q.Add("Limit", test.limit)
+		}
+		req.URL.RawQuery = q.Encode()
+
 		res, err := client.Do(req)
 		if err != nil {
 			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
 			continue
 		}
 
-		// Check received parameter
-		if testApi.ArgsIn[ListAttachedGroupPoliciesMethod][1] != test.org {
-			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[ListAttachedGroupPoliciesMethod][1])
-			continue
-		}
-		if testApi.ArgsIn[ListAttachedGroupPoliciesMethod][2] != test.name {
-			t.Errorf("Test case %v. Received different Name (wanted:%v / received:%v)", n, test.name, testApi.ArgsIn[ListAttachedGroupPoliciesMethod][2])
-			continue
+		// Check received parameters
+		if test.expectedStatusCode != http.StatusBadRequest {
+			if testApi.ArgsIn[ListAttachedGroupPoliciesMethod][1] != test.org {
+				t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[ListAttachedGroupPoliciesMethod][1])
+				continue
+			}
+			if testApi.ArgsIn[ListAttachedGroupPoliciesMethod][2] != test.name {
+				t.Errorf("Test case %v. Received different Name (wanted:%v / received:%v)", n, test.name, testApi.ArgsIn[ListAttachedGroupPoliciesMethod][2])
+				continue
+			}
 		}
 
 		// check status code
@@ -2,7 +2,10 @@ package http
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/julienschmidt/httprouter"
@@ -18,6 +21,15 @@ const (
 	POLICY_NAME = "policyname"
 	ORG_NAME    = "orgname"
 
+	ACCESS_REQUEST_ID   = "accessrequestid"
+	NAMESPACE_NAME      = "namespace"
+	ACTION_NAME         = "actionname"
+	RESOURCE_TYPE_NAME  = "resourcetypename"
+	TAG_KEY             = "tagkey"
+	STATEMENT_ID        = "sid"
+	DELETED_RESOURCE_ID = "deletedresourceid"
+	WEBHOOK_ID          = "webhookid"
+
 	// URI Path param prefix
 	URI_PATH_PREFIX = "/:"
 
@@ -29,9 +41,19 @@ const (
 	ORG_ROOT = "/organizations/:" + ORG_NAME
 
 	// User API urls
-	USER_ROOT_URL      = API_VERSION_1 + "/users"
-	USER_ID_URL        = USER_ROOT_URL + URI_PATH_PREFIX + USER_ID
-	USER_ID_GROUPS_URL = USER_ID_URL + "/groups"
+	USER_ROOT_URL       = API_VERSION_1 + "/users"
+	USER_ID_URL         = USER_ROOT_URL + URI_PATH_PREFIX + USER_ID
+	USER_ID_GROUPS_URL  = USER_ID_URL + "/groups"
+	USER_ID_TAGS_URL    = USER_ID_URL + "/tags"
+	USER_ID_TAGS_ID_URL = USER_ID_TAGS_URL + URI_PATH_PREFIX + TAG_KEY
+	USER_SUSPEND_URL    = USER_ID_URL + "/suspend"
+	USER_REACTIVATE_URL = USER_ID_URL + "/reactivate"
+	USER_RENAME_URL     = USER_ID_URL + "/rename"
+
+	// Authenticated user API urls
+	ME_URL             = API_VERSION_1 + "/me"
+	ME_GROUPS_URL      = ME_URL + "/groups"
+	ME_PERMISSIONS_URL = ME_URL + "/permissions"
 
 	// Group organization API urls
 	GROUP_ORG_ROOT_URL       = API_VERSION_1 + ORG_ROOT + "/groups"
@@ -40,17 +62,71 @@ const (
 	GROUP_ID_USERS_ID_URL    = GROUP_ID_USERS_URL + URI_PATH_PREFIX + USER_ID
 	GROUP_ID_POLICIES_URL    = GROUP_ID_URL + "/policies"
 	GROUP_ID_POLICIES_ID_URL = GROUP_ID_POLICIES_URL + URI_PATH_PREFIX + POLICY_NAME
+	GROUP_ID_TAGS_URL        = GROUP_ID_URL + "/tags"
+	GROUP_ID_TAGS_ID_URL     = GROUP_ID_TAGS_URL + URI_PATH_PREFIX + TAG_KEY
+	GROUP_ID_RULE_URL        = GROUP_ID_URL + "/membership-rule"
+	GROUP_ID_OWNERS_URL      = GROUP_ID_URL + "/owners"
+	GROUP_ID_OWNERS_ID_URL   = GROUP_ID_OWNERS_URL + URI_PATH_PREFIX + USER_ID
 
 	// Policy API urls
-	POLICY_ROOT_URL      = API_VERSION_1 + ORG_ROOT + "/policies"
-	POLICY_ID_URL        = POLICY_ROOT_URL + URI_PATH_PREFIX + POLICY_NAME
-	POLICY_ID_GROUPS_URL = POLICY_ROOT_URL + URI_PATH_PREFIX + POLICY_NAME + "/groups"
+	POLICY_ROOT_URL             = API_VERSION_1 + ORG_ROOT + "/policies"
+	POLICY_ID_URL               = POLICY_ROOT_URL + URI_PATH_PREFIX + POLICY_NAME
+	POLICY_ID_GROUPS_URL        = POLICY_ROOT_URL + URI_PATH_PREFIX + POLICY_NAME + "/groups"
+	POLICY_ID_TAGS_URL          = POLICY_ID_URL + "/tags"
+	POLICY_ID_TAGS_ID_URL       = POLICY_ID_TAGS_URL + URI_PATH_PREFIX + TAG_KEY
+	POLICY_ID_STATEMENTS_URL    = POLICY_ID_URL + "/statements"
+	POLICY_ID_STATEMENTS_ID_URL = POLICY_ID_STATEMENTS_URL + URI_PATH_PREFIX + STATEMENT_ID
+
+	// Access request API urls
+	ACCESS_REQUEST_ROOT_URL    = API_VERSION_1 + ORG_ROOT + "/access-requests"
+	ACCESS_REQUEST_ID_URL      = ACCESS_REQUEST_ROOT_URL + URI_PATH_PREFIX + ACCESS_REQUEST_ID
+	ACCESS_REQUEST_APPROVE_URL = ACCESS_REQUEST_ID_URL + "/approve"
+	ACCESS_REQUEST_REJECT_URL  = ACCESS_REQUEST_ID_URL + "/reject"
+
+	// Action registry API urls
+	NAMESPACE_ROOT_URL = API_VERSION_1 + "/namespaces"
+	NAMESPACE_ID_URL   = NAMESPACE_ROOT_URL + URI_PATH_PREFIX + NAMESPACE_NAME
+	ACTION_ROOT_URL    = NAMESPACE_ID_URL + "/actions"
+	ACTION_ID_URL      = ACTION_ROOT_URL + URI_PATH_PREFIX + ACTION_NAME
+
+	// Resource type registry API urls
+	RESOURCE_TYPE_ROOT_URL = NAMESPACE_ID_URL + "/resource-types"
+	RESOURCE_TYPE_ID_URL   = RESOURCE_TYPE_ROOT_URL + URI_PATH_PREFIX + RESOURCE_TYPE_NAME
+
+	// Organization API urls
+	ORGANIZATION_ROOT_URL   = API_VERSION_1 + "/organizations"
+	ORGANIZATION_ID_URL     = API_VERSION_1 + ORG_ROOT
+	ORGANIZATION_EXPORT_URL = API_VERSION_1 + ORG_ROOT + "/export"
+	ORGANIZATION_IMPORT_URL = API_VERSION_1 + ORG_ROOT + "/import"
+	ORGANIZATION_PLAN_URL   = API_VERSION_1 + ORG_ROOT + "/plan"
+	ORGANIZATION_APPLY_URL  = API_VERSION_1 + ORG_ROOT + "/apply"
+
+	// Trash API urls
+	TRASH_ROOT_URL    = API_VERSION_1 + "/trash"
+	TRASH_ID_URL      = TRASH_ROOT_URL + URI_PATH_PREFIX + DELETED_RESOURCE_ID
+	TRASH_RESTORE_URL = TRASH_ID_URL + "/restore"
+
+	// Batch API urls
+	BATCH_URL = API_VERSION_1 + "/batch"
+
+	// Admin API urls
+	CONSISTENCY_URL = API_VERSION_1 + "/admin/consistency"
+
+	// Audit API urls
+	AUDIT_URL = API_VERSION_1 + "/audit"
+
+	// Webhook API urls
+	WEBHOOK_ROOT_URL       = API_VERSION_1 + "/webhooks"
+	WEBHOOK_ID_URL         = WEBHOOK_ROOT_URL + URI_PATH_PREFIX + WEBHOOK_ID
+	WEBHOOK_DELIVERIES_URL = WEBHOOK_ID_URL + "/deliveries"
 
 	// Authorization URLs
 	RESOURCE_URL = API_VERSION_1 + "/resource"
 
 	// HTTP Header
 	REQUEST_ID_HEADER = "Request-ID"
+	ETAG_HEADER       = "ETag"
+	IF_MATCH_HEADER   = "If-Match"
 )
 
 // WORKER
@@ -89,9 +165,21 @@ func WorkerHandlerRouter(worker *foulkon.Worker) http.Handler {
 	router.GET(USER_ID_URL, workerHandler.HandleGetUserByExternalID)
 	router.PUT(USER_ID_URL, workerHandler.HandleUpdateUser)
 	router.DELETE(USER_ID_URL, workerHandler.HandleRemoveUser)
+	router.POST(USER_SUSPEND_URL, workerHandler.HandleSuspendUser)
+	router.POST(USER_REACTIVATE_URL, workerHandler.HandleReactivateUser)
+	router.POST(USER_RENAME_URL, workerHandler.HandleRenameUser)
 
 	router.GET(USER_ID_GROUPS_URL, workerHandler.HandleListGroupsByUser)
 
+	router.GET(USER_ID_TAGS_URL, workerHandler.HandleListUserTags)
+	router.PUT(USER_ID_TAGS_ID_URL, workerHandler.HandleSetUserTag)
+	router.DELETE(USER_ID_TAGS_ID_URL, workerHandler.HandleRemoveUserTag)
+
+	// Authenticated user api
+	router.GET(ME_URL, workerHandler.HandleGetAuthenticatedUser)
+	router.GET(ME_GROUPS_URL, workerHandler.HandleListAuthenticatedUserGroups)
+	router.GET(ME_PERMISSIONS_URL, workerHandler.HandleListAuthenticatedUserPermissions)
+
 	// Group api
 	router.POST(GROUP_ORG_ROOT_URL, workerHandler.HandleAddGroup)
 	router.GET(GROUP_ORG_ROOT_URL, workerHandler.HandleListGroups)
@@ -110,6 +198,17 @@ func WorkerHandlerRouter(worker *foulkon.Worker) http.Handler {
 	router.POST(GROUP_ID_POLICIES_ID_URL, workerHandler.HandleAttachPolicyToGroup)
 	router.DELETE(GROUP_ID_POLICIES_ID_URL, workerHandler.HandleDetachPolicyToGroup)
 
+	router.GET(GROUP_ID_TAGS_URL, workerHandler.HandleListGroupTags)
+	router.PUT(GROUP_ID_TAGS_ID_URL, workerHandler.HandleSetGroupTag)
+	router.DELETE(GROUP_ID_TAGS_ID_URL, workerHandler.HandleRemoveGroupTag)
+
+	router.PUT(GROUP_ID_RULE_URL, workerHandler.HandleSetGroupMembershipRule)
+	router.DELETE(GROUP_ID_RULE_URL, workerHandler.HandleRemoveGroupMembershipRule)
+
+	router.GET(GROUP_ID_OWNERS_URL, workerHandler.HandleListGroupOwners)
+	router.POST(GROUP_ID_OWNERS_ID_URL, workerHandler.HandleAddGroupOwner)
+	router.DELETE(GROUP_ID_OWNERS_ID_URL, workerHandler.HandleRemoveGroupOwner)
+
 	// Special endpoint without organization URI for groups
 	router.GET(API_VERSION_1+"/groups", workerHandler.HandleListAllGroups)
 
@@ -121,12 +220,83 @@ func WorkerHandlerRouter(worker *foulkon.Worker) http.Handler {
 
 	router.GET(POLICY_ID_URL, workerHandler.HandleGetPolicyByName)
 	router.PUT(POLICY_ID_URL, workerHandler.HandleUpdatePolicy)
+	router.PATCH(POLICY_ID_URL, workerHandler.HandlePatchPolicy)
 
 	router.GET(POLICY_ID_GROUPS_URL, workerHandler.HandleListAttachedGroups)
 
+	router.GET(POLICY_ID_TAGS_URL, workerHandler.HandleListPolicyTags)
+	router.PUT(POLICY_ID_TAGS_ID_URL, workerHandler.HandleSetPolicyTag)
+	router.DELETE(POLICY_ID_TAGS_ID_URL, workerHandler.HandleRemovePolicyTag)
+
+	router.POST(POLICY_ID_STATEMENTS_URL, workerHandler.HandleAddPolicyStatement)
+	router.GET(POLICY_ID_STATEMENTS_ID_URL, workerHandler.HandleGetPolicyStatement)
+	router.PUT(POLICY_ID_STATEMENTS_ID_URL, workerHandler.HandleUpdatePolicyStatement)
+	router.DELETE(POLICY_ID_STATEMENTS_ID_URL, workerHandler.HandleRemovePolicyStatement)
+
 	// Special endpoint without organization URI for policies
 	router.GET(API_VERSION_1+"/policies", workerHandler.HandleListAllPolicies)
 
+	// Access request api
+	router.GET(ACCESS_REQUEST_ROOT_URL, workerHandler.HandleListAccessRequests)
+	router.POST(ACCESS_REQUEST_ROOT_URL, workerHandler.HandleAddAccessRequest)
+
+	router.GET(ACCESS_REQUEST_ID_URL, workerHandler.HandleGetAccessRequestByID)
+
+	router.POST(ACCESS_REQUEST_APPROVE_URL, workerHandler.HandleApproveAccessRequest)
+	router.POST(ACCESS_REQUEST_REJECT_URL, workerHandler.HandleRejectAccessRequest)
+
+	// Action registry api
+	router.GET(NAMESPACE_ROOT_URL, workerHandler.HandleListNamespaces)
+	router.POST(NAMESPACE_ROOT_URL, workerHandler.HandleAddNamespace)
+
+	router.DELETE(NAMESPACE_ID_URL, workerHandler.HandleRemoveNamespace)
+
+	router.GET(ACTION_ROOT_URL, workerHandler.HandleListActions)
+	router.POST(ACTION_ROOT_URL, workerHandler.HandleAddAction)
+
+	router.GET(ACTION_ID_URL, workerHandler.HandleGetAction)
+	router.DELETE(ACTION_ID_URL, workerHandler.HandleRemoveAction)
+
+	// Resource type registry api
+	router.GET(RESOURCE_TYPE_ROOT_URL, workerHandler.HandleListResourceTypes)
+	router.POST(RESOURCE_TYPE_ROOT_URL, workerHandler.HandleAddResourceType)
+
+	router.GET(RESOURCE_TYPE_ID_URL, workerHandler.HandleGetResourceType)
+	router.DELETE(RESOURCE_TYPE_ID_URL, workerHandler.HandleRemoveResourceType)
+
+	// Organization api
+	router.GET(ORGANIZATION_ROOT_URL, workerHandler.HandleListOrganizations)
+	router.POST(ORGANIZATION_ROOT_URL, workerHandler.HandleAddOrganization)
+	router.GET(ORGANIZATION_ID_URL, workerHandler.HandleGetOrganizationByName)
+	router.PUT(ORGANIZATION_ID_URL, workerHandler.HandleUpdateOrganization)
+	router.DELETE(ORGANIZATION_ID_URL, workerHandler.HandleRemoveOrganization)
+	router.GET(ORGANIZATION_EXPORT_URL, workerHandler.HandleExportOrganization)
+	router.POST(ORGANIZATION_IMPORT_URL, workerHandler.HandleImportOrganization)
+	router.POST(ORGANIZATION_PLAN_URL, workerHandler.HandlePlanOrganization)
+	router.POST(ORGANIZATION_APPLY_URL, workerHandler.HandleApplyOrganization)
+
+	// Trash api
+	router.GET(TRASH_ROOT_URL, workerHandler.HandleListDeletedResources)
+	router.GET(TRASH_ID_URL, workerHandler.HandleGetDeletedResource)
+	router.POST(TRASH_RESTORE_URL, workerHandler.HandleRestoreDeletedResource)
+
+	// Batch api
+	router.POST(BATCH_URL, workerHandler.HandleBatch)
+
+	// Consistency api
+	router.GET(CONSISTENCY_URL, workerHandler.HandleCheckConsistency)
+
+	// Audit api
+	router.GET(AUDIT_URL, workerHandler.HandleListAuditEvents)
+
+	// Webhook api
+	router.GET(WEBHOOK_ROOT_URL, workerHandler.HandleListWebhooks)
+	router.POST(WEBHOOK_ROOT_URL, workerHandler.HandleAddWebhook)
+	router.GET(WEBHOOK_ID_URL, workerHandler.HandleGetWebhookByID)
+	router.PUT(WEBHOOK_ID_URL, workerHandler.HandleUpdateWebhook)
+	router.DELETE(WEBHOOK_ID_URL, workerHandler.HandleRemoveWebhook)
+	router.GET(WEBHOOK_DELIVERIES_URL, workerHandler.HandleListWebhookDeliveries)
+
 	// Resources authorized endpoint
 	router.POST(RESOURCE_URL, workerHandler.HandleGetAuthorizedExternalResources)
 
@@ -206,6 +376,22 @@ func (a *WorkerHandler) RespondForbidden(r *http.Request, requestInfo api.Reques
 	}
 }
 
+func (a *WorkerHandler) RespondPreconditionFailed(r *http.Request, requestInfo api.RequestInfo, w http.ResponseWriter, apiError *api.Error) {
+	w, err := writeErrorWithStatus(w, apiError, http.StatusPreconditionFailed)
+	if err != nil {
+		a.RespondInternalServerError(r, requestInfo, w)
+		return
+	}
+}
+
+func (a *WorkerHandler) RespondPreconditionRequired(r *http.Request, requestInfo api.RequestInfo, w http.ResponseWriter, apiError *api.Error) {
+	w, err := writeErrorWithStatus(w, apiError, http.StatusPreconditionRequired)
+	if err != nil {
+		a.RespondInternalServerError(r, requestInfo, w)
+		return
+	}
+}
+
 // 5xx RESPONSES
 
 func (a *WorkerHandler) RespondInternalServerError(r *http.Request, requestInfo api.RequestInfo, w http.ResponseWriter) {
@@ -228,7 +414,42 @@ func (w *WorkerHandler) GetRequestInfo(r *http.Request) api.RequestInfo {
 		Identifier: userID,
 		Admin:      admin,
 		RequestID:  r.Header.Get(REQUEST_ID_HEADER),
+		IfMatch:    r.Header.Get(IF_MATCH_HEADER),
+	}
+}
+
+// Check that update and removal requests are conditional when the worker requires it. Otherwise it
+// responds 428 and returns false
+func (w *WorkerHandler) checkIfMatchRequired(r *http.Request, requestInfo api.RequestInfo, rw http.ResponseWriter) bool {
+	if !w.worker.RequireIfMatch || requestInfo.IfMatch != "" {
+		return true
+	}
+	apiError := &api.Error{
+		Code:    api.PRECONDITION_REQUIRED_ERROR,
+		Message: fmt.Sprintf("Header %v is required to modify resources", IF_MATCH_HEADER),
 	}
+	api.LogErrorMessage(w.worker.Logger, requestInfo, apiError)
+	w.RespondPreconditionRequired(r, requestInfo, rw, apiError)
+	return false
+}
+
+// Read RewriteReferences query param of resource updates into the request info. It responds 400 and
+// returns false if the param isn't a boolean
+func (w *WorkerHandler) readRewriteReferences(r *http.Request, requestInfo *api.RequestInfo, rw http.ResponseWriter) bool {
+	rewrite, err := getBoolQueryParam(r, "RewriteReferences")
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(w.worker.Logger, *requestInfo, apiError)
+		w.RespondBadRequest(r, *requestInfo, rw, apiError)
+		return false
+	}
+	requestInfo.RewriteReferences = rewrite
+	return true
+}
+
+// Set entity tag of resource in response, used by clients in If-Match header of later updates and removals
+func setETagHeader(w http.ResponseWriter, resource api.Resource) {
+	w.Header().Set(ETAG_HEADER, api.ETag(resource))
 }
 
 // PROXY
@@ -329,3 +550,105 @@ func writeErrorWithStatus(w http.ResponseWriter, apiError *api.Error, statusCode
 	w.Write(b)
 	return w, nil
 }
+
+// Retrieve list filter from request query params: PathPrefix, Name, Tag, CreatedAfter, CreatedBefore,
+// Orphan, Sort, Order, Offset and Limit
+func getListFilter(r *http.Request, org string) (*api.Filter, error) {
+	filter, err := getPaginationFilter(r)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := getTagsFilter(r)
+	if err != nil {
+		return nil, err
+	}
+	createdAfter, err := getTimeQueryParam(r, "CreatedAfter")
+	if err != nil {
+		return nil, err
+	}
+	createdBefore, err := getTimeQueryParam(r, "CreatedBefore")
+	if err != nil {
+		return nil, err
+	}
+	orphan, err := getBoolQueryParam(r, "Orphan")
+	if err != nil {
+		return nil, err
+	}
+	filter.Org = org
+	filter.PathPrefix = r.URL.Query().Get("PathPrefix")
+	filter.Name = r.URL.Query().Get("Name")
+	filter.Tags = tags
+	filter.CreatedAfter = createdAfter
+	filter.CreatedBefore = createdBefore
+	filter.Orphan = orphan
+	filter.SortBy = r.URL.Query().Get("Sort")
+	filter.Order = r.URL.Query().Get("Order")
+
+	return filter, nil
+}
+
+// Retrieve Offset and Limit query params, they are 0 if they aren't in the request
+func getPaginationFilter(r *http.Request) (*api.Filter, error) {
+	offset, err := getIntQueryParam(r, "Offset")
+	if err != nil {
+		return nil, err
+	}
+	limit, err := getIntQueryParam(r, "Limit")
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.Filter{
+		Offset: offset,
+		Limit:  limit,
+	}, nil
+}
+
+func getIntQueryParam(r *http.Request, param string) (int, error) {
+	value := r.URL.Query().Get(param)
+	if value == "" {
+		return 0, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: %v %v", param, value),
+		}
+	}
+
+	return number, nil
+}
+
+// Retrieve a RFC 3339 date query param, it is nil if it isn't in the request
+func getTimeQueryParam(r *http.Request, param string) (*time.Time, error) {
+	value := r.URL.Query().Get(param)
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: %v %v", param, value),
+		}
+	}
+
+	return &date, nil
+}
+
+func getBoolQueryParam(r *http.Request, param string) (bool, error) {
+	value := r.URL.Query().Get(param)
+	if value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: %v %v", param, value),
+		}
+	}
+
+	return b, nil
+}
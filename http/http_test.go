@@ -5,6 +5,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"bytes"
 	log "github.com/Sirupsen/logrus"
@@ -19,18 +20,30 @@ const (
 	GetUserByExternalIdMethod = "GetUserByExternalId"
 	ListUsersMethod           = "ListUsers"
 	UpdateUserMethod          = "UpdateUser"
+	SuspendUserMethod         = "SuspendUser"
+	ReactivateUserMethod      = "ReactivateUser"
+	RenameUserMethod          = "RenameUser"
 	RemoveUserMethod          = "RemoveUser"
 	ListGroupsByUserMethod    = "ListGroupsByUser"
 
+	// AUTHENTICATED USER API METHODS
+	GetAuthenticatedUserMethod             = "GetAuthenticatedUser"
+	ListAuthenticatedUserGroupsMethod      = "ListAuthenticatedUserGroups"
+	ListAuthenticatedUserPermissionsMethod = "ListAuthenticatedUserPermissions"
+
 	// GROUP API METHODS
 	AddGroupMethod                  = "AddGroup"
 	GetGroupByNameMethod            = "GetGroupByName"
 	ListGroupsMethod                = "ListGroups"
 	UpdateGroupMethod               = "UpdateGroup"
 	RemoveGroupMethod               = "RemoveGroup"
+	SetGroupMembershipRuleMethod    = "SetGroupMembershipRule"
 	AddMemberMethod                 = "AddMember"
 	RemoveMemberMethod              = "RemoveMember"
 	ListMembersMethod               = "ListMembers"
+	ListGroupOwnersMethod           = "ListGroupOwners"
+	AddGroupOwnerMethod             = "AddGroupOwner"
+	RemoveGroupOwnerMethod          = "RemoveGroupOwner"
 	AttachPolicyToGroupMethod       = "AttachPolicyToGroup"
 	DetachPolicyToGroupMethod       = "DetachPolicyToGroup"
 	ListAttachedGroupPoliciesMethod = "ListAttachedGroupPolicies"
@@ -48,10 +61,86 @@ const (
 	GetAuthorizedGroupsMethod            = "GetAuthorizedGroups"
 	GetAuthorizedPoliciesMethod          = "GetAuthorizedPolicies"
 	GetAuthorizedExternalResourcesMethod = "GetAuthorizedExternalResources"
+
+	// ACCESS REQUEST API
+	AddAccessRequestMethod     = "AddAccessRequest"
+	GetAccessRequestByIDMethod = "GetAccessRequestByID"
+	ListAccessRequestsMethod   = "ListAccessRequests"
+	ApproveAccessRequestMethod = "ApproveAccessRequest"
+	RejectAccessRequestMethod  = "RejectAccessRequest"
+
+	// ACTION API
+	AddNamespaceMethod    = "AddNamespace"
+	ListNamespacesMethod  = "ListNamespaces"
+	RemoveNamespaceMethod = "RemoveNamespace"
+	AddActionMethod       = "AddAction"
+	GetActionMethod       = "GetAction"
+	ListActionsMethod     = "ListActions"
+	RemoveActionMethod    = "RemoveAction"
+
+	// RESOURCE TYPE API
+	AddResourceTypeMethod    = "AddResourceType"
+	GetResourceTypeMethod    = "GetResourceType"
+	ListResourceTypesMethod  = "ListResourceTypes"
+	RemoveResourceTypeMethod = "RemoveResourceType"
+
+	// TAG API
+	ListUserTagsMethod    = "ListUserTags"
+	SetUserTagMethod      = "SetUserTag"
+	RemoveUserTagMethod   = "RemoveUserTag"
+	ListGroupTagsMethod   = "ListGroupTags"
+	SetGroupTagMethod     = "SetGroupTag"
+	RemoveGroupTagMethod  = "RemoveGroupTag"
+	ListPolicyTagsMethod  = "ListPolicyTags"
+	SetPolicyTagMethod    = "SetPolicyTag"
+	RemovePolicyTagMethod = "RemovePolicyTag"
+
+	// BATCH API
+	BatchMethod = "Batch"
+
+	// ORGANIZATION API
+	ExportOrganizationMethod = "ExportOrganization"
+	ImportOrganizationMethod = "ImportOrganization"
+	PlanOrganizationMethod   = "PlanOrganization"
+	ApplyOrganizationMethod  = "ApplyOrganization"
+	AddOrganizationMethod    = "AddOrganization"
+	GetOrganizationMethod    = "GetOrganizationByName"
+	ListOrganizationsMethod  = "ListOrganizations"
+	UpdateOrganizationMethod = "UpdateOrganization"
+	RemoveOrganizationMethod = "RemoveOrganization"
+
+	// STATEMENT API
+	GetPolicyStatementMethod    = "GetPolicyStatement"
+	AddPolicyStatementMethod    = "AddPolicyStatement"
+	UpdatePolicyStatementMethod = "UpdatePolicyStatement"
+	RemovePolicyStatementMethod = "RemovePolicyStatement"
+	PatchPolicyMethod           = "PatchPolicy"
+
+	// TRASH API
+	ListDeletedResourcesMethod   = "ListDeletedResources"
+	GetDeletedResourceMethod     = "GetDeletedResource"
+	RestoreDeletedResourceMethod = "RestoreDeletedResource"
+	PurgeDeletedResourcesMethod  = "PurgeDeletedResources"
+
+	// CONSISTENCY API
+	CheckConsistencyMethod = "CheckConsistency"
+
+	// AUDIT API
+	ListAuditEventsMethod = "ListAuditEvents"
+
+	// WEBHOOK API
+	AddWebhookMethod            = "AddWebhook"
+	GetWebhookByIDMethod        = "GetWebhookByID"
+	ListWebhooksMethod          = "ListWebhooks"
+	UpdateWebhookMethod         = "UpdateWebhook"
+	RemoveWebhookMethod         = "RemoveWebhook"
+	ListWebhookDeliveriesMethod = "ListWebhookDeliveries"
+	DeliverWebhookEventsMethod  = "DeliverWebhookEvents"
 )
 
 // Test server used to test handlers
 var server *httptest.Server
+var worker *foulkon.Worker
 var proxy *httptest.Server
 var testApi *TestAPI
 var authConnector *TestConnector
@@ -110,13 +199,22 @@ func TestMain(m *testing.M) {
 	authenticator := auth.NewAuthenticator(authConnector, adminUser, adminPassword)
 
 	// Return created core
-	worker := &foulkon.Worker{
-		Logger:        logger,
-		Authenticator: authenticator,
-		UserApi:       testApi,
-		GroupApi:      testApi,
-		PolicyApi:     testApi,
-		AuthzApi:      testApi,
+	worker = &foulkon.Worker{
+		Logger:           logger,
+		Authenticator:    authenticator,
+		UserApi:          testApi,
+		GroupApi:         testApi,
+		PolicyApi:        testApi,
+		AuthzApi:         testApi,
+		AccessRequestApi: testApi,
+		ActionApi:        testApi,
+		ResourceTypeApi:  testApi,
+		BatchApi:         testApi,
+		OrganizationApi:  testApi,
+		TrashApi:         testApi,
+		ConsistencyApi:   testApi,
+		AuditApi:         testApi,
+		WebhookApi:       testApi,
 	}
 
 	server = httptest.NewServer(WorkerHandlerRouter(worker))
@@ -193,77 +291,212 @@ func makeTestApi() *TestAPI {
 		SpecialFuncs: make(map[string]interface{}),
 	}
 
-	testApi.ArgsIn[AddUserMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[AddUserMethod] = make([]interface{}, 4)
 	testApi.ArgsIn[GetUserByExternalIdMethod] = make([]interface{}, 2)
 	testApi.ArgsIn[ListUsersMethod] = make([]interface{}, 2)
-	testApi.ArgsIn[UpdateUserMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[UpdateUserMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[SuspendUserMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[ReactivateUserMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[RenameUserMethod] = make([]interface{}, 4)
 	testApi.ArgsIn[RemoveUserMethod] = make([]interface{}, 2)
-	testApi.ArgsIn[ListGroupsByUserMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[ListGroupsByUserMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[GetAuthenticatedUserMethod] = make([]interface{}, 1)
+	testApi.ArgsIn[ListAuthenticatedUserGroupsMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[ListAuthenticatedUserPermissionsMethod] = make([]interface{}, 1)
 
 	testApi.ArgsIn[AddGroupMethod] = make([]interface{}, 4)
 	testApi.ArgsIn[GetGroupByNameMethod] = make([]interface{}, 3)
-	testApi.ArgsIn[ListGroupsMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[ListGroupsMethod] = make([]interface{}, 2)
 	testApi.ArgsIn[UpdateGroupMethod] = make([]interface{}, 5)
 	testApi.ArgsIn[RemoveGroupMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[SetGroupMembershipRuleMethod] = make([]interface{}, 4)
 	testApi.ArgsIn[AddMemberMethod] = make([]interface{}, 4)
 	testApi.ArgsIn[RemoveMemberMethod] = make([]interface{}, 4)
-	testApi.ArgsIn[ListMembersMethod] = make([]interface{}, 3)
-	testApi.ArgsIn[AttachPolicyToGroupMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[ListMembersMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[ListGroupOwnersMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[AddGroupOwnerMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[RemoveGroupOwnerMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[AttachPolicyToGroupMethod] = make([]interface{}, 6)
 	testApi.ArgsIn[DetachPolicyToGroupMethod] = make([]interface{}, 4)
-	testApi.ArgsIn[ListAttachedGroupPoliciesMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[ListAttachedGroupPoliciesMethod] = make([]interface{}, 4)
 
 	testApi.ArgsIn[AddPolicyMethod] = make([]interface{}, 5)
 	testApi.ArgsIn[GetPolicyByNameMethod] = make([]interface{}, 3)
-	testApi.ArgsIn[ListPoliciesMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[ListPoliciesMethod] = make([]interface{}, 2)
 	testApi.ArgsIn[UpdatePolicyMethod] = make([]interface{}, 6)
 	testApi.ArgsIn[RemovePolicyMethod] = make([]interface{}, 3)
-	testApi.ArgsIn[ListAttachedGroupsMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[ListAttachedGroupsMethod] = make([]interface{}, 4)
 
 	testApi.ArgsIn[GetAuthorizedUsersMethod] = make([]interface{}, 4)
 	testApi.ArgsIn[GetAuthorizedGroupsMethod] = make([]interface{}, 4)
 	testApi.ArgsIn[GetAuthorizedPoliciesMethod] = make([]interface{}, 4)
-	testApi.ArgsIn[GetAuthorizedExternalResourcesMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[GetAuthorizedExternalResourcesMethod] = make([]interface{}, 4)
+
+	testApi.ArgsIn[AddAccessRequestMethod] = make([]interface{}, 6)
+	testApi.ArgsIn[GetAccessRequestByIDMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[ListAccessRequestsMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[ApproveAccessRequestMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[RejectAccessRequestMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[AddNamespaceMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[ListNamespacesMethod] = make([]interface{}, 1)
+	testApi.ArgsIn[RemoveNamespaceMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[AddActionMethod] = make([]interface{}, 5)
+	testApi.ArgsIn[GetActionMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[ListActionsMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[RemoveActionMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[AddResourceTypeMethod] = make([]interface{}, 5)
+	testApi.ArgsIn[GetResourceTypeMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[ListResourceTypesMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[RemoveResourceTypeMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[ListUserTagsMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[SetUserTagMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[RemoveUserTagMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[ListGroupTagsMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[SetGroupTagMethod] = make([]interface{}, 5)
+	testApi.ArgsIn[RemoveGroupTagMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[ListPolicyTagsMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[SetPolicyTagMethod] = make([]interface{}, 5)
+	testApi.ArgsIn[RemovePolicyTagMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[BatchMethod] = make([]interface{}, 3)
+
+	testApi.ArgsIn[ExportOrganizationMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[ImportOrganizationMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[PlanOrganizationMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[ApplyOrganizationMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[AddOrganizationMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[GetOrganizationMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[ListOrganizationsMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[UpdateOrganizationMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[RemoveOrganizationMethod] = make([]interface{}, 2)
+
+	testApi.ArgsIn[GetPolicyStatementMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[AddPolicyStatementMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[UpdatePolicyStatementMethod] = make([]interface{}, 5)
+	testApi.ArgsIn[RemovePolicyStatementMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[PatchPolicyMethod] = make([]interface{}, 5)
+
+	testApi.ArgsIn[ListDeletedResourcesMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[GetDeletedResourceMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[RestoreDeletedResourceMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[PurgeDeletedResourcesMethod] = make([]interface{}, 1)
+	testApi.ArgsIn[CheckConsistencyMethod] = make([]interface{}, 1)
+	testApi.ArgsIn[ListAuditEventsMethod] = make([]interface{}, 5)
+	testApi.ArgsIn[AddWebhookMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[GetWebhookByIDMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[ListWebhooksMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[UpdateWebhookMethod] = make([]interface{}, 5)
+	testApi.ArgsIn[RemoveWebhookMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[ListWebhookDeliveriesMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[DeliverWebhookEventsMethod] = make([]interface{}, 1)
 
 	testApi.ArgsOut[AddUserMethod] = make([]interface{}, 2)
 	testApi.ArgsOut[GetUserByExternalIdMethod] = make([]interface{}, 2)
-	testApi.ArgsOut[ListUsersMethod] = make([]interface{}, 2)
-	testApi.ArgsOut[UpdateUserMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ListUsersMethod] = make([]interface{}, 3)
+	testApi.ArgsOut[UpdateUserMethod] = make([]interface{}, 3)
+	testApi.ArgsOut[SuspendUserMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ReactivateUserMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[RenameUserMethod] = make([]interface{}, 2)
 	testApi.ArgsOut[RemoveUserMethod] = make([]interface{}, 1)
-	testApi.ArgsOut[ListGroupsByUserMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ListGroupsByUserMethod] = make([]interface{}, 3)
+	testApi.ArgsOut[GetAuthenticatedUserMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ListAuthenticatedUserGroupsMethod] = make([]interface{}, 3)
+	testApi.ArgsOut[ListAuthenticatedUserPermissionsMethod] = make([]interface{}, 2)
 
 	testApi.ArgsOut[AddGroupMethod] = make([]interface{}, 2)
 	testApi.ArgsOut[GetGroupByNameMethod] = make([]interface{}, 2)
-	testApi.ArgsOut[ListGroupsMethod] = make([]interface{}, 2)
-	testApi.ArgsOut[UpdateGroupMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ListGroupsMethod] = make([]interface{}, 3)
+	testApi.ArgsOut[UpdateGroupMethod] = make([]interface{}, 3)
 	testApi.ArgsOut[RemoveGroupMethod] = make([]interface{}, 1)
+	testApi.ArgsOut[SetGroupMembershipRuleMethod] = make([]interface{}, 2)
 	testApi.ArgsOut[AddMemberMethod] = make([]interface{}, 1)
 	testApi.ArgsOut[RemoveMemberMethod] = make([]interface{}, 1)
-	testApi.ArgsOut[ListMembersMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ListMembersMethod] = make([]interface{}, 3)
+	testApi.ArgsOut[ListGroupOwnersMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[AddGroupOwnerMethod] = make([]interface{}, 1)
+	testApi.ArgsOut[RemoveGroupOwnerMethod] = make([]interface{}, 1)
 	testApi.ArgsOut[AttachPolicyToGroupMethod] = make([]interface{}, 1)
 	testApi.ArgsOut[DetachPolicyToGroupMethod] = make([]interface{}, 1)
-	testApi.ArgsOut[ListAttachedGroupPoliciesMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ListAttachedGroupPoliciesMethod] = make([]interface{}, 3)
 
 	testApi.ArgsOut[AddPolicyMethod] = make([]interface{}, 2)
 	testApi.ArgsOut[GetPolicyByNameMethod] = make([]interface{}, 2)
-	testApi.ArgsOut[ListPoliciesMethod] = make([]interface{}, 2)
-	testApi.ArgsOut[UpdatePolicyMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ListPoliciesMethod] = make([]interface{}, 3)
+	testApi.ArgsOut[UpdatePolicyMethod] = make([]interface{}, 3)
 	testApi.ArgsOut[RemovePolicyMethod] = make([]interface{}, 1)
-	testApi.ArgsOut[ListAttachedGroupsMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ListAttachedGroupsMethod] = make([]interface{}, 3)
 
 	testApi.ArgsOut[GetAuthorizedUsersMethod] = make([]interface{}, 2)
 	testApi.ArgsOut[GetAuthorizedGroupsMethod] = make([]interface{}, 2)
 	testApi.ArgsOut[GetAuthorizedPoliciesMethod] = make([]interface{}, 2)
 	testApi.ArgsOut[GetAuthorizedExternalResourcesMethod] = make([]interface{}, 2)
 
+	testApi.ArgsOut[AddAccessRequestMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[GetAccessRequestByIDMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ListAccessRequestsMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ApproveAccessRequestMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[RejectAccessRequestMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[AddNamespaceMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ListNamespacesMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[RemoveNamespaceMethod] = make([]interface{}, 1)
+	testApi.ArgsOut[AddActionMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[GetActionMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ListActionsMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[RemoveActionMethod] = make([]interface{}, 1)
+	testApi.ArgsOut[AddResourceTypeMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[GetResourceTypeMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ListResourceTypesMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[RemoveResourceTypeMethod] = make([]interface{}, 1)
+	testApi.ArgsOut[ListUserTagsMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[SetUserTagMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[RemoveUserTagMethod] = make([]interface{}, 1)
+	testApi.ArgsOut[ListGroupTagsMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[SetGroupTagMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[RemoveGroupTagMethod] = make([]interface{}, 1)
+	testApi.ArgsOut[ListPolicyTagsMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[SetPolicyTagMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[RemovePolicyTagMethod] = make([]interface{}, 1)
+	testApi.ArgsOut[BatchMethod] = make([]interface{}, 2)
+
+	testApi.ArgsOut[ExportOrganizationMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ImportOrganizationMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[PlanOrganizationMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ApplyOrganizationMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[AddOrganizationMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[GetOrganizationMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ListOrganizationsMethod] = make([]interface{}, 3)
+	testApi.ArgsOut[UpdateOrganizationMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[RemoveOrganizationMethod] = make([]interface{}, 1)
+
+	testApi.ArgsOut[GetPolicyStatementMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[AddPolicyStatementMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[UpdatePolicyStatementMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[RemovePolicyStatementMethod] = make([]interface{}, 1)
+	testApi.ArgsOut[PatchPolicyMethod] = make([]interface{}, 2)
+
+	testApi.ArgsOut[ListDeletedResourcesMethod] = make([]interface{}, 3)
+	testApi.ArgsOut[GetDeletedResourceMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[RestoreDeletedResourceMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[PurgeDeletedResourcesMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[CheckConsistencyMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ListAuditEventsMethod] = make([]interface{}, 3)
+	testApi.ArgsOut[AddWebhookMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[GetWebhookByIDMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ListWebhooksMethod] = make([]interface{}, 3)
+	testApi.ArgsOut[UpdateWebhookMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[RemoveWebhookMethod] = make([]interface{}, 1)
+	testApi.ArgsOut[ListWebhookDeliveriesMethod] = make([]interface{}, 3)
+	testApi.ArgsOut[DeliverWebhookEventsMethod] = make([]interface{}, 2)
+
 	return testApi
 }
 
 // USER API
 
-func (t TestAPI) AddUser(authenticatedUser api.RequestInfo, externalID string, path string) (*api.User, error) {
+func (t TestAPI) AddUser(authenticatedUser api.RequestInfo, externalID string, path string, profile api.UserProfile) (*api.User, error) {
 	t.ArgsIn[AddUserMethod][0] = authenticatedUser
 	t.ArgsIn[AddUserMethod][1] = externalID
 	t.ArgsIn[AddUserMethod][2] = path
+	t.ArgsIn[AddUserMethod][3] = profile
 	var user *api.User
 	if t.ArgsOut[AddUserMethod][0] != nil {
 		user = t.ArgsOut[AddUserMethod][0].(*api.User)
@@ -289,35 +522,88 @@ func (t TestAPI) GetUserByExternalID(authenticatedUser api.RequestInfo, id strin
 	return user, err
 }
 
-func (t TestAPI) ListUsers(authenticatedUser api.RequestInfo, pathPrefix string) ([]string, error) {
+func (t TestAPI) ListUsers(authenticatedUser api.RequestInfo, filter *api.Filter) ([]api.User, int, error) {
 	t.ArgsIn[ListUsersMethod][0] = authenticatedUser
-	t.ArgsIn[ListUsersMethod][1] = pathPrefix
-	var externalIDs []string
+	t.ArgsIn[ListUsersMethod][1] = filter
+	var users []api.User
 	if t.ArgsOut[ListUsersMethod][0] != nil {
-		externalIDs = t.ArgsOut[ListUsersMethod][0].([]string)
+		users = t.ArgsOut[ListUsersMethod][0].([]api.User)
 	}
-	var err error
+	var total int
 	if t.ArgsOut[ListUsersMethod][1] != nil {
-		err = t.ArgsOut[ListUsersMethod][1].(error)
+		total = t.ArgsOut[ListUsersMethod][1].(int)
+	}
+	var err error
+	if t.ArgsOut[ListUsersMethod][2] != nil {
+		err = t.ArgsOut[ListUsersMethod][2].(error)
 	}
-	return externalIDs, err
+	return users, total, err
 }
 
-func (t TestAPI) UpdateUser(authenticatedUser api.RequestInfo, externalID string, newPath string) (*api.User, error) {
+func (t TestAPI) UpdateUser(authenticatedUser api.RequestInfo, externalID string, newPath string, profileUpdate api.UserProfileUpdate) (*api.User, []api.StatementReference, error) {
 	t.ArgsIn[UpdateUserMethod][0] = authenticatedUser
 	t.ArgsIn[UpdateUserMethod][1] = externalID
 	t.ArgsIn[UpdateUserMethod][2] = newPath
+	t.ArgsIn[UpdateUserMethod][3] = profileUpdate
 	var user *api.User
 	if t.ArgsOut[UpdateUserMethod][0] != nil {
 		user = t.ArgsOut[UpdateUserMethod][0].(*api.User)
 	}
-	var err error
+	var references []api.StatementReference
 	if t.ArgsOut[UpdateUserMethod][1] != nil {
-		err = t.ArgsOut[UpdateUserMethod][1].(error)
+		references = t.ArgsOut[UpdateUserMethod][1].([]api.StatementReference)
+	}
+	var err error
+	if t.ArgsOut[UpdateUserMethod][2] != nil {
+		err = t.ArgsOut[UpdateUserMethod][2].(error)
+	}
+	return user, references, err
+}
+
+func (t TestAPI) SuspendUser(authenticatedUser api.RequestInfo, externalID string) (*api.User, error) {
+	t.ArgsIn[SuspendUserMethod][0] = authenticatedUser
+	t.ArgsIn[SuspendUserMethod][1] = externalID
+	var user *api.User
+	if t.ArgsOut[SuspendUserMethod][0] != nil {
+		user = t.ArgsOut[SuspendUserMethod][0].(*api.User)
+	}
+	var err error
+	if t.ArgsOut[SuspendUserMethod][1] != nil {
+		err = t.ArgsOut[SuspendUserMethod][1].(error)
+	}
+	return user, err
+}
+
+func (t TestAPI) ReactivateUser(authenticatedUser api.RequestInfo, externalID string) (*api.User, error) {
+	t.ArgsIn[ReactivateUserMethod][0] = authenticatedUser
+	t.ArgsIn[ReactivateUserMethod][1] = externalID
+	var user *api.User
+	if t.ArgsOut[ReactivateUserMethod][0] != nil {
+		user = t.ArgsOut[ReactivateUserMethod][0].(*api.User)
+	}
+	var err error
+	if t.ArgsOut[ReactivateUserMethod][1] != nil {
+		err = t.ArgsOut[ReactivateUserMethod][1].(error)
 	}
 	return user, err
 }
 
+func (t TestAPI) RenameUser(authenticatedUser api.RequestInfo, externalID string, newExternalID string, rewritePolicies bool) (*api.UserRename, error) {
+	t.ArgsIn[RenameUserMethod][0] = authenticatedUser
+	t.ArgsIn[RenameUserMethod][1] = externalID
+	t.ArgsIn[RenameUserMethod][2] = newExternalID
+	t.ArgsIn[RenameUserMethod][3] = rewritePolicies
+	var rename *api.UserRename
+	if t.ArgsOut[RenameUserMethod][0] != nil {
+		rename = t.ArgsOut[RenameUserMethod][0].(*api.UserRename)
+	}
+	var err error
+	if t.ArgsOut[RenameUserMethod][1] != nil {
+		err = t.ArgsOut[RenameUserMethod][1].(error)
+	}
+	return rename, err
+}
+
 func (t TestAPI) RemoveUser(authenticatedUser api.RequestInfo, id string) error {
 	t.ArgsIn[RemoveUserMethod][0] = authenticatedUser
 	t.ArgsIn[RemoveUserMethod][1] = id
@@ -328,18 +614,67 @@ func (t TestAPI) RemoveUser(authenticatedUser api.RequestInfo, id string) error
 	return err
 }
 
-func (t TestAPI) ListGroupsByUser(authenticatedUser api.RequestInfo, id string) ([]api.GroupIdentity, error) {
+func (t TestAPI) ListGroupsByUser(authenticatedUser api.RequestInfo, id string, filter *api.Filter) ([]api.GroupIdentity, int, error) {
 	t.ArgsIn[ListGroupsByUserMethod][0] = authenticatedUser
 	t.ArgsIn[ListGroupsByUserMethod][1] = id
+	t.ArgsIn[ListGroupsByUserMethod][2] = filter
 	var groups []api.GroupIdentity
 	if t.ArgsOut[ListGroupsByUserMethod][0] != nil {
 		groups = t.ArgsOut[ListGroupsByUserMethod][0].([]api.GroupIdentity)
 	}
-	var err error
+	var total int
 	if t.ArgsOut[ListGroupsByUserMethod][1] != nil {
-		err = t.ArgsOut[ListGroupsByUserMethod][1].(error)
+		total = t.ArgsOut[ListGroupsByUserMethod][1].(int)
+	}
+	var err error
+	if t.ArgsOut[ListGroupsByUserMethod][2] != nil {
+		err = t.ArgsOut[ListGroupsByUserMethod][2].(error)
 	}
-	return groups, err
+	return groups, total, err
+}
+
+func (t TestAPI) GetAuthenticatedUser(authenticatedUser api.RequestInfo) (*api.User, error) {
+	t.ArgsIn[GetAuthenticatedUserMethod][0] = authenticatedUser
+	var user *api.User
+	if t.ArgsOut[GetAuthenticatedUserMethod][0] != nil {
+		user = t.ArgsOut[GetAuthenticatedUserMethod][0].(*api.User)
+	}
+	var err error
+	if t.ArgsOut[GetAuthenticatedUserMethod][1] != nil {
+		err = t.ArgsOut[GetAuthenticatedUserMethod][1].(error)
+	}
+	return user, err
+}
+
+func (t TestAPI) ListAuthenticatedUserGroups(authenticatedUser api.RequestInfo, filter *api.Filter) ([]api.GroupIdentity, int, error) {
+	t.ArgsIn[ListAuthenticatedUserGroupsMethod][0] = authenticatedUser
+	t.ArgsIn[ListAuthenticatedUserGroupsMethod][1] = filter
+	var groups []api.GroupIdentity
+	if t.ArgsOut[ListAuthenticatedUserGroupsMethod][0] != nil {
+		groups = t.ArgsOut[ListAuthenticatedUserGroupsMethod][0].([]api.GroupIdentity)
+	}
+	var total int
+	if t.ArgsOut[ListAuthenticatedUserGroupsMethod][1] != nil {
+		total = t.ArgsOut[ListAuthenticatedUserGroupsMethod][1].(int)
+	}
+	var err error
+	if t.ArgsOut[ListAuthenticatedUserGroupsMethod][2] != nil {
+		err = t.ArgsOut[ListAuthenticatedUserGroupsMethod][2].(error)
+	}
+	return groups, total, err
+}
+
+func (t TestAPI) ListAuthenticatedUserPermissions(authenticatedUser api.RequestInfo) ([]api.Permission, error) {
+	t.ArgsIn[ListAuthenticatedUserPermissionsMethod][0] = authenticatedUser
+	var permissions []api.Permission
+	if t.ArgsOut[ListAuthenticatedUserPermissionsMethod][0] != nil {
+		permissions = t.ArgsOut[ListAuthenticatedUserPermissionsMethod][0].([]api.Permission)
+	}
+	var err error
+	if t.ArgsOut[ListAuthenticatedUserPermissionsMethod][1] != nil {
+		err = t.ArgsOut[ListAuthenticatedUserPermissionsMethod][1].(error)
+	}
+	return permissions, err
 }
 
 // GROUP API
@@ -375,22 +710,25 @@ func (t TestAPI) GetGroupByName(authenticatedUser api.RequestInfo, org string, n
 	return group, err
 }
 
-func (t TestAPI) ListGroups(authenticatedUser api.RequestInfo, org string, pathPrefix string) ([]api.GroupIdentity, error) {
+func (t TestAPI) ListGroups(authenticatedUser api.RequestInfo, filter *api.Filter) ([]api.Group, int, error) {
 	t.ArgsIn[ListGroupsMethod][0] = authenticatedUser
-	t.ArgsIn[ListGroupsMethod][1] = org
-	t.ArgsIn[ListGroupsMethod][2] = pathPrefix
-	var groups []api.GroupIdentity
+	t.ArgsIn[ListGroupsMethod][1] = filter
+	var groups []api.Group
 	if t.ArgsOut[ListGroupsMethod][0] != nil {
-		groups = t.ArgsOut[ListGroupsMethod][0].([]api.GroupIdentity)
+		groups = t.ArgsOut[ListGroupsMethod][0].([]api.Group)
 	}
-	var err error
+	var total int
 	if t.ArgsOut[ListGroupsMethod][1] != nil {
-		err = t.ArgsOut[ListGroupsMethod][1].(error)
+		total = t.ArgsOut[ListGroupsMethod][1].(int)
+	}
+	var err error
+	if t.ArgsOut[ListGroupsMethod][2] != nil {
+		err = t.ArgsOut[ListGroupsMethod][2].(error)
 	}
-	return groups, err
+	return groups, total, err
 }
 
-func (t TestAPI) UpdateGroup(authenticatedUser api.RequestInfo, org string, groupName string, newName string, newPath string) (*api.Group, error) {
+func (t TestAPI) UpdateGroup(authenticatedUser api.RequestInfo, org string, groupName string, newName string, newPath string) (*api.Group, []api.StatementReference, error) {
 	t.ArgsIn[UpdateGroupMethod][0] = authenticatedUser
 	t.ArgsIn[UpdateGroupMethod][1] = org
 	t.ArgsIn[UpdateGroupMethod][2] = groupName
@@ -400,9 +738,29 @@ func (t TestAPI) UpdateGroup(authenticatedUser api.RequestInfo, org string, grou
 	if t.ArgsOut[UpdateGroupMethod][0] != nil {
 		group = t.ArgsOut[UpdateGroupMethod][0].(*api.Group)
 	}
-	var err error
+	var references []api.StatementReference
 	if t.ArgsOut[UpdateGroupMethod][1] != nil {
-		err = t.ArgsOut[UpdateGroupMethod][1].(error)
+		references = t.ArgsOut[UpdateGroupMethod][1].([]api.StatementReference)
+	}
+	var err error
+	if t.ArgsOut[UpdateGroupMethod][2] != nil {
+		err = t.ArgsOut[UpdateGroupMethod][2].(error)
+	}
+	return group, references, err
+}
+
+func (t TestAPI) SetGroupMembershipRule(authenticatedUser api.RequestInfo, org string, groupName string, rule *api.MembershipRule) (*api.Group, error) {
+	t.ArgsIn[SetGroupMembershipRuleMethod][0] = authenticatedUser
+	t.ArgsIn[SetGroupMembershipRuleMethod][1] = org
+	t.ArgsIn[SetGroupMembershipRuleMethod][2] = groupName
+	t.ArgsIn[SetGroupMembershipRuleMethod][3] = rule
+	var group *api.Group
+	if t.ArgsOut[SetGroupMembershipRuleMethod][0] != nil {
+		group = t.ArgsOut[SetGroupMembershipRuleMethod][0].(*api.Group)
+	}
+	var err error
+	if t.ArgsOut[SetGroupMembershipRuleMethod][1] != nil {
+		err = t.ArgsOut[SetGroupMembershipRuleMethod][1].(error)
 	}
 	return group, err
 }
@@ -442,26 +800,73 @@ func (t TestAPI) RemoveMember(authenticatedUser api.RequestInfo, userID string,
 	return err
 }
 
-func (t TestAPI) ListMembers(authenticatedUser api.RequestInfo, org string, groupName string) ([]string, error) {
+func (t TestAPI) ListMembers(authenticatedUser api.RequestInfo, org string, name string, filter *api.Filter) ([]string, int, error) {
 	t.ArgsIn[ListMembersMethod][0] = authenticatedUser
 	t.ArgsIn[ListMembersMethod][1] = org
-	t.ArgsIn[ListMembersMethod][2] = groupName
-	var externalIDs []string
+	t.ArgsIn[ListMembersMethod][2] = name
+	t.ArgsIn[ListMembersMethod][3] = filter
+	var members []string
 	if t.ArgsOut[ListMembersMethod][0] != nil {
-		externalIDs = t.ArgsOut[ListMembersMethod][0].([]string)
+		members = t.ArgsOut[ListMembersMethod][0].([]string)
 	}
-	var err error
+	var total int
 	if t.ArgsOut[ListMembersMethod][1] != nil {
-		err = t.ArgsOut[ListMembersMethod][1].(error)
+		total = t.ArgsOut[ListMembersMethod][1].(int)
+	}
+	var err error
+	if t.ArgsOut[ListMembersMethod][2] != nil {
+		err = t.ArgsOut[ListMembersMethod][2].(error)
+	}
+	return members, total, err
+}
+
+func (t TestAPI) ListGroupOwners(authenticatedUser api.RequestInfo, org string, name string) ([]string, error) {
+	t.ArgsIn[ListGroupOwnersMethod][0] = authenticatedUser
+	t.ArgsIn[ListGroupOwnersMethod][1] = org
+	t.ArgsIn[ListGroupOwnersMethod][2] = name
+	var owners []string
+	if t.ArgsOut[ListGroupOwnersMethod][0] != nil {
+		owners = t.ArgsOut[ListGroupOwnersMethod][0].([]string)
+	}
+	var err error
+	if t.ArgsOut[ListGroupOwnersMethod][1] != nil {
+		err = t.ArgsOut[ListGroupOwnersMethod][1].(error)
 	}
-	return externalIDs, err
+	return owners, err
 }
 
-func (t TestAPI) AttachPolicyToGroup(authenticatedUser api.RequestInfo, org string, groupName string, policyName string) error {
+func (t TestAPI) AddGroupOwner(authenticatedUser api.RequestInfo, org string, name string, externalId string) error {
+	t.ArgsIn[AddGroupOwnerMethod][0] = authenticatedUser
+	t.ArgsIn[AddGroupOwnerMethod][1] = org
+	t.ArgsIn[AddGroupOwnerMethod][2] = name
+	t.ArgsIn[AddGroupOwnerMethod][3] = externalId
+	var err error
+	if t.ArgsOut[AddGroupOwnerMethod][0] != nil {
+		err = t.ArgsOut[AddGroupOwnerMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestAPI) RemoveGroupOwner(authenticatedUser api.RequestInfo, org string, name string, externalId string) error {
+	t.ArgsIn[RemoveGroupOwnerMethod][0] = authenticatedUser
+	t.ArgsIn[RemoveGroupOwnerMethod][1] = org
+	t.ArgsIn[RemoveGroupOwnerMethod][2] = name
+	t.ArgsIn[RemoveGroupOwnerMethod][3] = externalId
+	var err error
+	if t.ArgsOut[RemoveGroupOwnerMethod][0] != nil {
+		err = t.ArgsOut[RemoveGroupOwnerMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestAPI) AttachPolicyToGroup(authenticatedUser api.RequestInfo, org string, groupName string, policyName string,
+	notBefore *time.Time, notAfter *time.Time) error {
 	t.ArgsIn[AttachPolicyToGroupMethod][0] = authenticatedUser
 	t.ArgsIn[AttachPolicyToGroupMethod][1] = org
 	t.ArgsIn[AttachPolicyToGroupMethod][2] = groupName
 	t.ArgsIn[AttachPolicyToGroupMethod][3] = policyName
+	t.ArgsIn[AttachPolicyToGroupMethod][4] = notBefore
+	t.ArgsIn[AttachPolicyToGroupMethod][5] = notAfter
 	var err error
 	if t.ArgsOut[AttachPolicyToGroupMethod][0] != nil {
 		err = t.ArgsOut[AttachPolicyToGroupMethod][0].(error)
@@ -481,19 +886,24 @@ func (t TestAPI) DetachPolicyToGroup(authenticatedUser api.RequestInfo, org stri
 	return err
 }
 
-func (t TestAPI) ListAttachedGroupPolicies(authenticatedUser api.RequestInfo, org string, groupName string) ([]string, error) {
+func (t TestAPI) ListAttachedGroupPolicies(authenticatedUser api.RequestInfo, org string, name string, filter *api.Filter) ([]api.AttachedPolicy, int, error) {
 	t.ArgsIn[ListAttachedGroupPoliciesMethod][0] = authenticatedUser
 	t.ArgsIn[ListAttachedGroupPoliciesMethod][1] = org
-	t.ArgsIn[ListAttachedGroupPoliciesMethod][2] = groupName
-	var policies []string
+	t.ArgsIn[ListAttachedGroupPoliciesMethod][2] = name
+	t.ArgsIn[ListAttachedGroupPoliciesMethod][3] = filter
+	var policies []api.AttachedPolicy
 	if t.ArgsOut[ListAttachedGroupPoliciesMethod][0] != nil {
-		policies = t.ArgsOut[ListAttachedGroupPoliciesMethod][0].([]string)
+		policies = t.ArgsOut[ListAttachedGroupPoliciesMethod][0].([]api.AttachedPolicy)
 	}
-	var err error
+	var total int
 	if t.ArgsOut[ListAttachedGroupPoliciesMethod][1] != nil {
-		err = t.ArgsOut[ListAttachedGroupPoliciesMethod][1].(error)
+		total = t.ArgsOut[ListAttachedGroupPoliciesMethod][1].(int)
 	}
-	return policies, err
+	var err error
+	if t.ArgsOut[ListAttachedGroupPoliciesMethod][2] != nil {
+		err = t.ArgsOut[ListAttachedGroupPoliciesMethod][2].(error)
+	}
+	return policies, total, err
 }
 
 // POLICY API
@@ -530,23 +940,26 @@ func (t TestAPI) GetPolicyByName(authenticatedUser api.RequestInfo, org string,
 	return policy, err
 }
 
-func (t TestAPI) ListPolicies(authenticatedUser api.RequestInfo, org string, pathPrefix string) ([]api.PolicyIdentity, error) {
+func (t TestAPI) ListPolicies(authenticatedUser api.RequestInfo, filter *api.Filter) ([]api.Policy, int, error) {
 	t.ArgsIn[ListPoliciesMethod][0] = authenticatedUser
-	t.ArgsIn[ListPoliciesMethod][1] = org
-	t.ArgsIn[ListPoliciesMethod][2] = pathPrefix
-	var policies []api.PolicyIdentity
+	t.ArgsIn[ListPoliciesMethod][1] = filter
+	var policies []api.Policy
 	if t.ArgsOut[ListPoliciesMethod][0] != nil {
-		policies = t.ArgsOut[ListPoliciesMethod][0].([]api.PolicyIdentity)
+		policies = t.ArgsOut[ListPoliciesMethod][0].([]api.Policy)
 	}
-	var err error
+	var total int
 	if t.ArgsOut[ListPoliciesMethod][1] != nil {
-		err = t.ArgsOut[ListPoliciesMethod][1].(error)
+		total = t.ArgsOut[ListPoliciesMethod][1].(int)
+	}
+	var err error
+	if t.ArgsOut[ListPoliciesMethod][2] != nil {
+		err = t.ArgsOut[ListPoliciesMethod][2].(error)
 	}
-	return policies, err
+	return policies, total, err
 }
 
 func (t TestAPI) UpdatePolicy(authenticatedUser api.RequestInfo, org string, policyName string, newName string, newPath string,
-	newStatements []api.Statement) (*api.Policy, error) {
+	newStatements []api.Statement) (*api.Policy, []api.StatementReference, error) {
 	t.ArgsIn[UpdatePolicyMethod][0] = authenticatedUser
 	t.ArgsIn[UpdatePolicyMethod][1] = org
 	t.ArgsIn[UpdatePolicyMethod][2] = policyName
@@ -558,11 +971,15 @@ func (t TestAPI) UpdatePolicy(authenticatedUser api.RequestInfo, org string, pol
 	if t.ArgsOut[UpdatePolicyMethod][0] != nil {
 		policy = t.ArgsOut[UpdatePolicyMethod][0].(*api.Policy)
 	}
-	var err error
+	var references []api.StatementReference
 	if t.ArgsOut[UpdatePolicyMethod][1] != nil {
-		err = t.ArgsOut[UpdatePolicyMethod][1].(error)
+		references = t.ArgsOut[UpdatePolicyMethod][1].([]api.StatementReference)
 	}
-	return policy, err
+	var err error
+	if t.ArgsOut[UpdatePolicyMethod][2] != nil {
+		err = t.ArgsOut[UpdatePolicyMethod][2].(error)
+	}
+	return policy, references, err
 }
 
 func (t TestAPI) RemovePolicy(authenticatedUser api.RequestInfo, org string, name string) error {
@@ -576,19 +993,24 @@ func (t TestAPI) RemovePolicy(authenticatedUser api.RequestInfo, org string, nam
 	return err
 }
 
-func (t TestAPI) ListAttachedGroups(authenticatedUser api.RequestInfo, org string, policyName string) ([]string, error) {
+func (t TestAPI) ListAttachedGroups(authenticatedUser api.RequestInfo, org string, name string, filter *api.Filter) ([]string, int, error) {
 	t.ArgsIn[ListAttachedGroupsMethod][0] = authenticatedUser
 	t.ArgsIn[ListAttachedGroupsMethod][1] = org
-	t.ArgsIn[ListAttachedGroupsMethod][2] = policyName
+	t.ArgsIn[ListAttachedGroupsMethod][2] = name
+	t.ArgsIn[ListAttachedGroupsMethod][3] = filter
 	var groups []string
 	if t.ArgsOut[ListAttachedGroupsMethod][0] != nil {
 		groups = t.ArgsOut[ListAttachedGroupsMethod][0].([]string)
 	}
-	var err error
+	var total int
 	if t.ArgsOut[ListAttachedGroupsMethod][1] != nil {
-		err = t.ArgsOut[ListAttachedGroupsMethod][1].(error)
+		total = t.ArgsOut[ListAttachedGroupsMethod][1].(int)
+	}
+	var err error
+	if t.ArgsOut[ListAttachedGroupsMethod][2] != nil {
+		err = t.ArgsOut[ListAttachedGroupsMethod][2].(error)
 	}
-	return groups, err
+	return groups, total, err
 }
 
 // AUTHZ API
@@ -605,10 +1027,11 @@ func (t TestAPI) GetAuthorizedPolicies(authenticatedUser api.RequestInfo, resour
 	return nil, nil
 }
 
-func (t TestAPI) GetAuthorizedExternalResources(authenticatedUser api.RequestInfo, action string, resources []string) ([]string, error) {
+func (t TestAPI) GetAuthorizedExternalResources(authenticatedUser api.RequestInfo, action string, resources []string, resourceTags map[string]string) ([]string, error) {
 	t.ArgsIn[GetAuthorizedExternalResourcesMethod][0] = authenticatedUser
 	t.ArgsIn[GetAuthorizedExternalResourcesMethod][1] = action
 	t.ArgsIn[GetAuthorizedExternalResourcesMethod][2] = resources
+	t.ArgsIn[GetAuthorizedExternalResourcesMethod][3] = resourceTags
 	var resourcesToReturn []string
 	if t.ArgsOut[GetAuthorizedExternalResourcesMethod][0] != nil {
 		resourcesToReturn = t.ArgsOut[GetAuthorizedExternalResourcesMethod][0].([]string)
@@ -619,3 +1042,813 @@ func (t TestAPI) GetAuthorizedExternalResources(authenticatedUser api.RequestInf
 	}
 	return resourcesToReturn, err
 }
+
+// ACCESS REQUEST API
+
+func (t TestAPI) AddAccessRequest(authenticatedUser api.RequestInfo, org string, groupName string, policyName string,
+	justification string, duration time.Duration) (*api.AccessRequest, error) {
+	t.ArgsIn[AddAccessRequestMethod][0] = authenticatedUser
+	t.ArgsIn[AddAccessRequestMethod][1] = org
+	t.ArgsIn[AddAccessRequestMethod][2] = groupName
+	t.ArgsIn[AddAccessRequestMethod][3] = policyName
+	t.ArgsIn[AddAccessRequestMethod][4] = justification
+	t.ArgsIn[AddAccessRequestMethod][5] = duration
+	var accessRequest *api.AccessRequest
+	if t.ArgsOut[AddAccessRequestMethod][0] != nil {
+		accessRequest = t.ArgsOut[AddAccessRequestMethod][0].(*api.AccessRequest)
+	}
+	var err error
+	if t.ArgsOut[AddAccessRequestMethod][1] != nil {
+		err = t.ArgsOut[AddAccessRequestMethod][1].(error)
+	}
+	return accessRequest, err
+}
+
+func (t TestAPI) GetAccessRequestByID(authenticatedUser api.RequestInfo, org string, id string) (*api.AccessRequest, error) {
+	t.ArgsIn[GetAccessRequestByIDMethod][0] = authenticatedUser
+	t.ArgsIn[GetAccessRequestByIDMethod][1] = org
+	t.ArgsIn[GetAccessRequestByIDMethod][2] = id
+	var accessRequest *api.AccessRequest
+	if t.ArgsOut[GetAccessRequestByIDMethod][0] != nil {
+		accessRequest = t.ArgsOut[GetAccessRequestByIDMethod][0].(*api.AccessRequest)
+	}
+	var err error
+	if t.ArgsOut[GetAccessRequestByIDMethod][1] != nil {
+		err = t.ArgsOut[GetAccessRequestByIDMethod][1].(error)
+	}
+	return accessRequest, err
+}
+
+func (t TestAPI) ListAccessRequests(authenticatedUser api.RequestInfo, org string, status string) ([]api.AccessRequest, error) {
+	t.ArgsIn[ListAccessRequestsMethod][0] = authenticatedUser
+	t.ArgsIn[ListAccessRequestsMethod][1] = org
+	t.ArgsIn[ListAccessRequestsMethod][2] = status
+	var accessRequests []api.AccessRequest
+	if t.ArgsOut[ListAccessRequestsMethod][0] != nil {
+		accessRequests = t.ArgsOut[ListAccessRequestsMethod][0].([]api.AccessRequest)
+	}
+	var err error
+	if t.ArgsOut[ListAccessRequestsMethod][1] != nil {
+		err = t.ArgsOut[ListAccessRequestsMethod][1].(error)
+	}
+	return accessRequests, err
+}
+
+func (t TestAPI) ApproveAccessRequest(authenticatedUser api.RequestInfo, org string, id string, comment string) (*api.AccessRequest, error) {
+	t.ArgsIn[ApproveAccessRequestMethod][0] = authenticatedUser
+	t.ArgsIn[ApproveAccessRequestMethod][1] = org
+	t.ArgsIn[ApproveAccessRequestMethod][2] = id
+	t.ArgsIn[ApproveAccessRequestMethod][3] = comment
+	var accessRequest *api.AccessRequest
+	if t.ArgsOut[ApproveAccessRequestMethod][0] != nil {
+		accessRequest = t.ArgsOut[ApproveAccessRequestMethod][0].(*api.AccessRequest)
+	}
+	var err error
+	if t.ArgsOut[ApproveAccessRequestMethod][1] != nil {
+		err = t.ArgsOut[ApproveAccessRequestMethod][1].(error)
+	}
+	return accessRequest, err
+}
+
+func (t TestAPI) RejectAccessRequest(authenticatedUser api.RequestInfo, org string, id string, comment string) (*api.AccessRequest, error) {
+	t.ArgsIn[RejectAccessRequestMethod][0] = authenticatedUser
+	t.ArgsIn[RejectAccessRequestMethod][1] = org
+	t.ArgsIn[RejectAccessRequestMethod][2] = id
+	t.ArgsIn[RejectAccessRequestMethod][3] = comment
+	var accessRequest *api.AccessRequest
+	if t.ArgsOut[RejectAccessRequestMethod][0] != nil {
+		accessRequest = t.ArgsOut[RejectAccessRequestMethod][0].(*api.AccessRequest)
+	}
+	var err error
+	if t.ArgsOut[RejectAccessRequestMethod][1] != nil {
+		err = t.ArgsOut[RejectAccessRequestMethod][1].(error)
+	}
+	return accessRequest, err
+}
+
+func (t TestAPI) AddNamespace(authenticatedUser api.RequestInfo, name string, description string) (*api.Namespace, error) {
+	t.ArgsIn[AddNamespaceMethod][0] = authenticatedUser
+	t.ArgsIn[AddNamespaceMethod][1] = name
+	t.ArgsIn[AddNamespaceMethod][2] = description
+	var namespace *api.Namespace
+	if t.ArgsOut[AddNamespaceMethod][0] != nil {
+		namespace = t.ArgsOut[AddNamespaceMethod][0].(*api.Namespace)
+	}
+	var err error
+	if t.ArgsOut[AddNamespaceMethod][1] != nil {
+		err = t.ArgsOut[AddNamespaceMethod][1].(error)
+	}
+	return namespace, err
+}
+
+func (t TestAPI) ListNamespaces(authenticatedUser api.RequestInfo) ([]api.Namespace, error) {
+	t.ArgsIn[ListNamespacesMethod][0] = authenticatedUser
+	var namespaces []api.Namespace
+	if t.ArgsOut[ListNamespacesMethod][0] != nil {
+		namespaces = t.ArgsOut[ListNamespacesMethod][0].([]api.Namespace)
+	}
+	var err error
+	if t.ArgsOut[ListNamespacesMethod][1] != nil {
+		err = t.ArgsOut[ListNamespacesMethod][1].(error)
+	}
+	return namespaces, err
+}
+
+func (t TestAPI) RemoveNamespace(authenticatedUser api.RequestInfo, name string) error {
+	t.ArgsIn[RemoveNamespaceMethod][0] = authenticatedUser
+	t.ArgsIn[RemoveNamespaceMethod][1] = name
+	var err error
+	if t.ArgsOut[RemoveNamespaceMethod][0] != nil {
+		err = t.ArgsOut[RemoveNamespaceMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestAPI) AddAction(authenticatedUser api.RequestInfo, namespace string, name string, description string, resourceUrnPattern string) (*api.Action, error) {
+	t.ArgsIn[AddActionMethod][0] = authenticatedUser
+	t.ArgsIn[AddActionMethod][1] = namespace
+	t.ArgsIn[AddActionMethod][2] = name
+	t.ArgsIn[AddActionMethod][3] = description
+	t.ArgsIn[AddActionMethod][4] = resourceUrnPattern
+	var action *api.Action
+	if t.ArgsOut[AddActionMethod][0] != nil {
+		action = t.ArgsOut[AddActionMethod][0].(*api.Action)
+	}
+	var err error
+	if t.ArgsOut[AddActionMethod][1] != nil {
+		err = t.ArgsOut[AddActionMethod][1].(error)
+	}
+	return action, err
+}
+
+func (t TestAPI) GetAction(authenticatedUser api.RequestInfo, namespace string, name string) (*api.Action, error) {
+	t.ArgsIn[GetActionMethod][0] = authenticatedUser
+	t.ArgsIn[GetActionMethod][1] = namespace
+	t.ArgsIn[GetActionMethod][2] = name
+	var action *api.Action
+	if t.ArgsOut[GetActionMethod][0] != nil {
+		action = t.ArgsOut[GetActionMethod][0].(*api.Action)
+	}
+	var err error
+	if t.ArgsOut[GetActionMethod][1] != nil {
+		err = t.ArgsOut[GetActionMethod][1].(error)
+	}
+	return action, err
+}
+
+func (t TestAPI) ListActions(authenticatedUser api.RequestInfo, namespace string) ([]api.Action, error) {
+	t.ArgsIn[ListActionsMethod][0] = authenticatedUser
+	t.ArgsIn[ListActionsMethod][1] = namespace
+	var actions []api.Action
+	if t.ArgsOut[ListActionsMethod][0] != nil {
+		actions = t.ArgsOut[ListActionsMethod][0].([]api.Action)
+	}
+	var err error
+	if t.ArgsOut[ListActionsMethod][1] != nil {
+		err = t.ArgsOut[ListActionsMethod][1].(error)
+	}
+	return actions, err
+}
+
+func (t TestAPI) RemoveAction(authenticatedUser api.RequestInfo, namespace string, name string) error {
+	t.ArgsIn[RemoveActionMethod][0] = authenticatedUser
+	t.ArgsIn[RemoveActionMethod][1] = namespace
+	t.ArgsIn[RemoveActionMethod][2] = name
+	var err error
+	if t.ArgsOut[RemoveActionMethod][0] != nil {
+		err = t.ArgsOut[RemoveActionMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestAPI) AddResourceType(authenticatedUser api.RequestInfo, namespace string, name string, description string, urnTemplate string) (*api.ResourceType, error) {
+	t.ArgsIn[AddResourceTypeMethod][0] = authenticatedUser
+	t.ArgsIn[AddResourceTypeMethod][1] = namespace
+	t.ArgsIn[AddResourceTypeMethod][2] = name
+	t.ArgsIn[AddResourceTypeMethod][3] = description
+	t.ArgsIn[AddResourceTypeMethod][4] = urnTemplate
+	var resourceType *api.ResourceType
+	if t.ArgsOut[AddResourceTypeMethod][0] != nil {
+		resourceType = t.ArgsOut[AddResourceTypeMethod][0].(*api.ResourceType)
+	}
+	var err error
+	if t.ArgsOut[AddResourceTypeMethod][1] != nil {
+		err = t.ArgsOut[AddResourceTypeMethod][1].(error)
+	}
+	return resourceType, err
+}
+
+func (t TestAPI) GetResourceType(authenticatedUser api.RequestInfo, namespace string, name string) (*api.ResourceType, error) {
+	t.ArgsIn[GetResourceTypeMethod][0] = authenticatedUser
+	t.ArgsIn[GetResourceTypeMethod][1] = namespace
+	t.ArgsIn[GetResourceTypeMethod][2] = name
+	var resourceType *api.ResourceType
+	if t.ArgsOut[GetResourceTypeMethod][0] != nil {
+		resourceType = t.ArgsOut[GetResourceTypeMethod][0].(*api.ResourceType)
+	}
+	var err error
+	if t.ArgsOut[GetResourceTypeMethod][1] != nil {
+		err = t.ArgsOut[GetResourceTypeMethod][1].(error)
+	}
+	return resourceType, err
+}
+
+func (t TestAPI) ListResourceTypes(authenticatedUser api.RequestInfo, namespace string) ([]api.ResourceType, error) {
+	t.ArgsIn[ListResourceTypesMethod][0] = authenticatedUser
+	t.ArgsIn[ListResourceTypesMethod][1] = namespace
+	var resourceTypes []api.ResourceType
+	if t.ArgsOut[ListResourceTypesMethod][0] != nil {
+		resourceTypes = t.ArgsOut[ListResourceTypesMethod][0].([]api.ResourceType)
+	}
+	var err error
+	if t.ArgsOut[ListResourceTypesMethod][1] != nil {
+		err = t.ArgsOut[ListResourceTypesMethod][1].(error)
+	}
+	return resourceTypes, err
+}
+
+func (t TestAPI) RemoveResourceType(authenticatedUser api.RequestInfo, namespace string, name string) error {
+	t.ArgsIn[RemoveResourceTypeMethod][0] = authenticatedUser
+	t.ArgsIn[RemoveResourceTypeMethod][1] = namespace
+	t.ArgsIn[RemoveResourceTypeMethod][2] = name
+	var err error
+	if t.ArgsOut[RemoveResourceTypeMethod][0] != nil {
+		err = t.ArgsOut[RemoveResourceTypeMethod][0].(error)
+	}
+	return err
+}
+
+// TAG API
+
+func (t TestAPI) ListUserTags(authenticatedUser api.RequestInfo, externalID string) (map[string]string, error) {
+	t.ArgsIn[ListUserTagsMethod][0] = authenticatedUser
+	t.ArgsIn[ListUserTagsMethod][1] = externalID
+	var tags map[string]string
+	if t.ArgsOut[ListUserTagsMethod][0] != nil {
+		tags = t.ArgsOut[ListUserTagsMethod][0].(map[string]string)
+	}
+	var err error
+	if t.ArgsOut[ListUserTagsMethod][1] != nil {
+		err = t.ArgsOut[ListUserTagsMethod][1].(error)
+	}
+	return tags, err
+}
+
+func (t TestAPI) SetUserTag(authenticatedUser api.RequestInfo, externalID string, key string, value string) (map[string]string, error) {
+	t.ArgsIn[SetUserTagMethod][0] = authenticatedUser
+	t.ArgsIn[SetUserTagMethod][1] = externalID
+	t.ArgsIn[SetUserTagMethod][2] = key
+	t.ArgsIn[SetUserTagMethod][3] = value
+	var tags map[string]string
+	if t.ArgsOut[SetUserTagMethod][0] != nil {
+		tags = t.ArgsOut[SetUserTagMethod][0].(map[string]string)
+	}
+	var err error
+	if t.ArgsOut[SetUserTagMethod][1] != nil {
+		err = t.ArgsOut[SetUserTagMethod][1].(error)
+	}
+	return tags, err
+}
+
+func (t TestAPI) RemoveUserTag(authenticatedUser api.RequestInfo, externalID string, key string) error {
+	t.ArgsIn[RemoveUserTagMethod][0] = authenticatedUser
+	t.ArgsIn[RemoveUserTagMethod][1] = externalID
+	t.ArgsIn[RemoveUserTagMethod][2] = key
+	var err error
+	if t.ArgsOut[RemoveUserTagMethod][0] != nil {
+		err = t.ArgsOut[RemoveUserTagMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestAPI) ListGroupTags(authenticatedUser api.RequestInfo, org string, name string) (map[string]string, error) {
+	t.ArgsIn[ListGroupTagsMethod][0] = authenticatedUser
+	t.ArgsIn[ListGroupTagsMethod][1] = org
+	t.ArgsIn[ListGroupTagsMethod][2] = name
+	var tags map[string]string
+	if t.ArgsOut[ListGroupTagsMethod][0] != nil {
+		tags = t.ArgsOut[ListGroupTagsMethod][0].(map[string]string)
+	}
+	var err error
+	if t.ArgsOut[ListGroupTagsMethod][1] != nil {
+		err = t.ArgsOut[ListGroupTagsMethod][1].(error)
+	}
+	return tags, err
+}
+
+func (t TestAPI) SetGroupTag(authenticatedUser api.RequestInfo, org string, name string, key string, value string) (map[string]string, error) {
+	t.ArgsIn[SetGroupTagMethod][0] = authenticatedUser
+	t.ArgsIn[SetGroupTagMethod][1] = org
+	t.ArgsIn[SetGroupTagMethod][2] = name
+	t.ArgsIn[SetGroupTagMethod][3] = key
+	t.ArgsIn[SetGroupTagMethod][4] = value
+	var tags map[string]string
+	if t.ArgsOut[SetGroupTagMethod][0] != nil {
+		tags = t.ArgsOut[SetGroupTagMethod][0].(map[string]string)
+	}
+	var err error
+	if t.ArgsOut[SetGroupTagMethod][1] != nil {
+		err = t.ArgsOut[SetGroupTagMethod][1].(error)
+	}
+	return tags, err
+}
+
+func (t TestAPI) RemoveGroupTag(authenticatedUser api.RequestInfo, org string, name string, key string) error {
+	t.ArgsIn[RemoveGroupTagMethod][0] = authenticatedUser
+	t.ArgsIn[RemoveGroupTagMethod][1] = org
+	t.ArgsIn[RemoveGroupTagMethod][2] = name
+	t.ArgsIn[RemoveGroupTagMethod][3] = key
+	var err error
+	if t.ArgsOut[RemoveGroupTagMethod][0] != nil {
+		err = t.ArgsOut[RemoveGroupTagMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestAPI) ListPolicyTags(authenticatedUser api.RequestInfo, org string, name string) (map[string]string, error) {
+	t.ArgsIn[ListPolicyTagsMethod][0] = authenticatedUser
+	t.ArgsIn[ListPolicyTagsMethod][1] = org
+	t.ArgsIn[ListPolicyTagsMethod][2] = name
+	var tags map[string]string
+	if t.ArgsOut[ListPolicyTagsMethod][0] != nil {
+		tags = t.ArgsOut[ListPolicyTagsMethod][0].(map[string]string)
+	}
+	var err error
+	if t.ArgsOut[ListPolicyTagsMethod][1] != nil {
+		err = t.ArgsOut[ListPolicyTagsMethod][1].(error)
+	}
+	return tags, err
+}
+
+func (t TestAPI) SetPolicyTag(authenticatedUser api.RequestInfo, org string, name string, key string, value string) (map[string]string, error) {
+	t.ArgsIn[SetPolicyTagMethod][0] = authenticatedUser
+	t.ArgsIn[SetPolicyTagMethod][1] = org
+	t.ArgsIn[SetPolicyTagMethod][2] = name
+	t.ArgsIn[SetPolicyTagMethod][3] = key
+	t.ArgsIn[SetPolicyTagMethod][4] = value
+	var tags map[string]string
+	if t.ArgsOut[SetPolicyTagMethod][0] != nil {
+		tags = t.ArgsOut[SetPolicyTagMethod][0].(map[string]string)
+	}
+	var err error
+	if t.ArgsOut[SetPolicyTagMethod][1] != nil {
+		err = t.ArgsOut[SetPolicyTagMethod][1].(error)
+	}
+	return tags, err
+}
+
+func (t TestAPI) RemovePolicyTag(authenticatedUser api.RequestInfo, org string, name string, key string) error {
+	t.ArgsIn[RemovePolicyTagMethod][0] = authenticatedUser
+	t.ArgsIn[RemovePolicyTagMethod][1] = org
+	t.ArgsIn[RemovePolicyTagMethod][2] = name
+	t.ArgsIn[RemovePolicyTagMethod][3] = key
+	var err error
+	if t.ArgsOut[RemovePolicyTagMethod][0] != nil {
+		err = t.ArgsOut[RemovePolicyTagMethod][0].(error)
+	}
+	return err
+}
+
+// BATCH API
+
+func (t TestAPI) Batch(authenticatedUser api.RequestInfo, operations []api.BatchOperation, atomic bool) ([]api.BatchResult, error) {
+	t.ArgsIn[BatchMethod][0] = authenticatedUser
+	t.ArgsIn[BatchMethod][1] = operations
+	t.ArgsIn[BatchMethod][2] = atomic
+	var results []api.BatchResult
+	if t.ArgsOut[BatchMethod][0] != nil {
+		results = t.ArgsOut[BatchMethod][0].([]api.BatchResult)
+	}
+	var err error
+	if t.ArgsOut[BatchMethod][1] != nil {
+		err = t.ArgsOut[BatchMethod][1].(error)
+	}
+	return results, err
+}
+
+// ORGANIZATION API
+
+func (t TestAPI) ExportOrganization(authenticatedUser api.RequestInfo, org string) (*api.OrganizationDocument, error) {
+	t.ArgsIn[ExportOrganizationMethod][0] = authenticatedUser
+	t.ArgsIn[ExportOrganizationMethod][1] = org
+	var document *api.OrganizationDocument
+	if t.ArgsOut[ExportOrganizationMethod][0] != nil {
+		document = t.ArgsOut[ExportOrganizationMethod][0].(*api.OrganizationDocument)
+	}
+	var err error
+	if t.ArgsOut[ExportOrganizationMethod][1] != nil {
+		err = t.ArgsOut[ExportOrganizationMethod][1].(error)
+	}
+	return document, err
+}
+
+func (t TestAPI) ImportOrganization(authenticatedUser api.RequestInfo, org string, document *api.OrganizationDocument,
+	conflictMode string) (*api.ImportResult, error) {
+	t.ArgsIn[ImportOrganizationMethod][0] = authenticatedUser
+	t.ArgsIn[ImportOrganizationMethod][1] = org
+	t.ArgsIn[ImportOrganizationMethod][2] = document
+	t.ArgsIn[ImportOrganizationMethod][3] = conflictMode
+	var result *api.ImportResult
+	if t.ArgsOut[ImportOrganizationMethod][0] != nil {
+		result = t.ArgsOut[ImportOrganizationMethod][0].(*api.ImportResult)
+	}
+	var err error
+	if t.ArgsOut[ImportOrganizationMethod][1] != nil {
+		err = t.ArgsOut[ImportOrganizationMethod][1].(error)
+	}
+	return result, err
+}
+
+func (t TestAPI) PlanOrganization(authenticatedUser api.RequestInfo, org string, document *api.OrganizationDocument) (*api.Plan, error) {
+	t.ArgsIn[PlanOrganizationMethod][0] = authenticatedUser
+	t.ArgsIn[PlanOrganizationMethod][1] = org
+	t.ArgsIn[PlanOrganizationMethod][2] = document
+	var plan *api.Plan
+	if t.ArgsOut[PlanOrganizationMethod][0] != nil {
+		plan = t.ArgsOut[PlanOrganizationMethod][0].(*api.Plan)
+	}
+	var err error
+	if t.ArgsOut[PlanOrganizationMethod][1] != nil {
+		err = t.ArgsOut[PlanOrganizationMethod][1].(error)
+	}
+	return plan, err
+}
+
+func (t TestAPI) ApplyOrganization(authenticatedUser api.RequestInfo, org string, document *api.OrganizationDocument,
+	confirmedPlan *api.Plan) (*api.Plan, error) {
+	t.ArgsIn[ApplyOrganizationMethod][0] = authenticatedUser
+	t.ArgsIn[ApplyOrganizationMethod][1] = org
+	t.ArgsIn[ApplyOrganizationMethod][2] = document
+	t.ArgsIn[ApplyOrganizationMethod][3] = confirmedPlan
+	var plan *api.Plan
+	if t.ArgsOut[ApplyOrganizationMethod][0] != nil {
+		plan = t.ArgsOut[ApplyOrganizationMethod][0].(*api.Plan)
+	}
+	var err error
+	if t.ArgsOut[ApplyOrganizationMethod][1] != nil {
+		err = t.ArgsOut[ApplyOrganizationMethod][1].(error)
+	}
+	return plan, err
+}
+
+func (t TestAPI) AddOrganization(authenticatedUser api.RequestInfo, name string, description string) (*api.Organization, error) {
+	t.ArgsIn[AddOrganizationMethod][0] = authenticatedUser
+	t.ArgsIn[AddOrganizationMethod][1] = name
+	t.ArgsIn[AddOrganizationMethod][2] = description
+	var organization *api.Organization
+	if t.ArgsOut[AddOrganizationMethod][0] != nil {
+		organization = t.ArgsOut[AddOrganizationMethod][0].(*api.Organization)
+	}
+	var err error
+	if t.ArgsOut[AddOrganizationMethod][1] != nil {
+		err = t.ArgsOut[AddOrganizationMethod][1].(error)
+	}
+	return organization, err
+}
+
+func (t TestAPI) GetOrganizationByName(authenticatedUser api.RequestInfo, name string) (*api.Organization, error) {
+	t.ArgsIn[GetOrganizationMethod][0] = authenticatedUser
+	t.ArgsIn[GetOrganizationMethod][1] = name
+	var organization *api.Organization
+	if t.ArgsOut[GetOrganizationMethod][0] != nil {
+		organization = t.ArgsOut[GetOrganizationMethod][0].(*api.Organization)
+	}
+	var err error
+	if t.ArgsOut[GetOrganizationMethod][1] != nil {
+		err = t.ArgsOut[GetOrganizationMethod][1].(error)
+	}
+	return organization, err
+}
+
+func (t TestAPI) ListOrganizations(authenticatedUser api.RequestInfo, filter *api.Filter) ([]api.Organization, int, error) {
+	t.ArgsIn[ListOrganizationsMethod][0] = authenticatedUser
+	t.ArgsIn[ListOrganizationsMethod][1] = filter
+	var organizations []api.Organization
+	if t.ArgsOut[ListOrganizationsMethod][0] != nil {
+		organizations = t.ArgsOut[ListOrganizationsMethod][0].([]api.Organization)
+	}
+	var total int
+	if t.ArgsOut[ListOrganizationsMethod][1] != nil {
+		total = t.ArgsOut[ListOrganizationsMethod][1].(int)
+	}
+	var err error
+	if t.ArgsOut[ListOrganizationsMethod][2] != nil {
+		err = t.ArgsOut[ListOrganizationsMethod][2].(error)
+	}
+	return organizations, total, err
+}
+
+func (t TestAPI) UpdateOrganization(authenticatedUser api.RequestInfo, name string, newDescription string) (*api.Organization, error) {
+	t.ArgsIn[UpdateOrganizationMethod][0] = authenticatedUser
+	t.ArgsIn[UpdateOrganizationMethod][1] = name
+	t.ArgsIn[UpdateOrganizationMethod][2] = newDescription
+	var organization *api.Organization
+	if t.ArgsOut[UpdateOrganizationMethod][0] != nil {
+		organization = t.ArgsOut[UpdateOrganizationMethod][0].(*api.Organization)
+	}
+	var err error
+	if t.ArgsOut[UpdateOrganizationMethod][1] != nil {
+		err = t.ArgsOut[UpdateOrganizationMethod][1].(error)
+	}
+	return organization, err
+}
+
+func (t TestAPI) RemoveOrganization(authenticatedUser api.RequestInfo, name string) error {
+	t.ArgsIn[RemoveOrganizationMethod][0] = authenticatedUser
+	t.ArgsIn[RemoveOrganizationMethod][1] = name
+	var err error
+	if t.ArgsOut[RemoveOrganizationMethod][0] != nil {
+		err = t.ArgsOut[RemoveOrganizationMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestAPI) GetPolicyStatement(authenticatedUser api.RequestInfo, org string, policyName string, sid string) (*api.Statement, error) {
+	t.ArgsIn[GetPolicyStatementMethod][0] = authenticatedUser
+	t.ArgsIn[GetPolicyStatementMethod][1] = org
+	t.ArgsIn[GetPolicyStatementMethod][2] = policyName
+	t.ArgsIn[GetPolicyStatementMethod][3] = sid
+	var statement *api.Statement
+	if t.ArgsOut[GetPolicyStatementMethod][0] != nil {
+		statement = t.ArgsOut[GetPolicyStatementMethod][0].(*api.Statement)
+	}
+	var err error
+	if t.ArgsOut[GetPolicyStatementMethod][1] != nil {
+		err = t.ArgsOut[GetPolicyStatementMethod][1].(error)
+	}
+	return statement, err
+}
+
+func (t TestAPI) AddPolicyStatement(authenticatedUser api.RequestInfo, org string, policyName string, statement api.Statement) (*api.Statement, error) {
+	t.ArgsIn[AddPolicyStatementMethod][0] = authenticatedUser
+	t.ArgsIn[AddPolicyStatementMethod][1] = org
+	t.ArgsIn[AddPolicyStatementMethod][2] = policyName
+	t.ArgsIn[AddPolicyStatementMethod][3] = statement
+	var added *api.Statement
+	if t.ArgsOut[AddPolicyStatementMethod][0] != nil {
+		added = t.ArgsOut[AddPolicyStatementMethod][0].(*api.Statement)
+	}
+	var err error
+	if t.ArgsOut[AddPolicyStatementMethod][1] != nil {
+		err = t.ArgsOut[AddPolicyStatementMethod][1].(error)
+	}
+	return added, err
+}
+
+func (t TestAPI) UpdatePolicyStatement(authenticatedUser api.RequestInfo, org string, policyName string, sid string,
+	statement api.Statement) (*api.Statement, error) {
+	t.ArgsIn[UpdatePolicyStatementMethod][0] = authenticatedUser
+	t.ArgsIn[UpdatePolicyStatementMethod][1] = org
+	t.ArgsIn[UpdatePolicyStatementMethod][2] = policyName
+	t.ArgsIn[UpdatePolicyStatementMethod][3] = sid
+	t.ArgsIn[UpdatePolicyStatementMethod][4] = statement
+	var updated *api.Statement
+	if t.ArgsOut[UpdatePolicyStatementMethod][0] != nil {
+		updated = t.ArgsOut[UpdatePolicyStatementMethod][0].(*api.Statement)
+	}
+	var err error
+	if t.ArgsOut[UpdatePolicyStatementMethod][1] != nil {
+		err = t.ArgsOut[UpdatePolicyStatementMethod][1].(error)
+	}
+	return updated, err
+}
+
+func (t TestAPI) RemovePolicyStatement(authenticatedUser api.RequestInfo, org string, policyName string, sid string) error {
+	t.ArgsIn[RemovePolicyStatementMethod][0] = authenticatedUser
+	t.ArgsIn[RemovePolicyStatementMethod][1] = org
+	t.ArgsIn[RemovePolicyStatementMethod][2] = policyName
+	t.ArgsIn[RemovePolicyStatementMethod][3] = sid
+	var err error
+	if t.ArgsOut[RemovePolicyStatementMethod][0] != nil {
+		err = t.ArgsOut[RemovePolicyStatementMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestAPI) PatchPolicy(authenticatedUser api.RequestInfo, org string, policyName string, patchType string,
+	patch []byte) (*api.Policy, error) {
+	t.ArgsIn[PatchPolicyMethod][0] = authenticatedUser
+	t.ArgsIn[PatchPolicyMethod][1] = org
+	t.ArgsIn[PatchPolicyMethod][2] = policyName
+	t.ArgsIn[PatchPolicyMethod][3] = patchType
+	t.ArgsIn[PatchPolicyMethod][4] = patch
+	var policy *api.Policy
+	if t.ArgsOut[PatchPolicyMethod][0] != nil {
+		policy = t.ArgsOut[PatchPolicyMethod][0].(*api.Policy)
+	}
+	var err error
+	if t.ArgsOut[PatchPolicyMethod][1] != nil {
+		err = t.ArgsOut[PatchPolicyMethod][1].(error)
+	}
+	return policy, err
+}
+
+// TRASH API
+
+func (t TestAPI) ListDeletedResources(authenticatedUser api.RequestInfo, resourceType string, filter *api.Filter) ([]api.DeletedResource, int, error) {
+	t.ArgsIn[ListDeletedResourcesMethod][0] = authenticatedUser
+	t.ArgsIn[ListDeletedResourcesMethod][1] = resourceType
+	t.ArgsIn[ListDeletedResourcesMethod][2] = filter
+	var deletedResources []api.DeletedResource
+	if t.ArgsOut[ListDeletedResourcesMethod][0] != nil {
+		deletedResources = t.ArgsOut[ListDeletedResourcesMethod][0].([]api.DeletedResource)
+	}
+	var total int
+	if t.ArgsOut[ListDeletedResourcesMethod][1] != nil {
+		total = t.ArgsOut[ListDeletedResourcesMethod][1].(int)
+	}
+	var err error
+	if t.ArgsOut[ListDeletedResourcesMethod][2] != nil {
+		err = t.ArgsOut[ListDeletedResourcesMethod][2].(error)
+	}
+	return deletedResources, total, err
+}
+
+func (t TestAPI) GetDeletedResource(authenticatedUser api.RequestInfo, id string) (*api.DeletedResource, error) {
+	t.ArgsIn[GetDeletedResourceMethod][0] = authenticatedUser
+	t.ArgsIn[GetDeletedResourceMethod][1] = id
+	var deletedResource *api.DeletedResource
+	if t.ArgsOut[GetDeletedResourceMethod][0] != nil {
+		deletedResource = t.ArgsOut[GetDeletedResourceMethod][0].(*api.DeletedResource)
+	}
+	var err error
+	if t.ArgsOut[GetDeletedResourceMethod][1] != nil {
+		err = t.ArgsOut[GetDeletedResourceMethod][1].(error)
+	}
+	return deletedResource, err
+}
+
+func (t TestAPI) RestoreDeletedResource(authenticatedUser api.RequestInfo, id string) (*api.DeletedResource, error) {
+	t.ArgsIn[RestoreDeletedResourceMethod][0] = authenticatedUser
+	t.ArgsIn[RestoreDeletedResourceMethod][1] = id
+	var deletedResource *api.DeletedResource
+	if t.ArgsOut[RestoreDeletedResourceMethod][0] != nil {
+		deletedResource = t.ArgsOut[RestoreDeletedResourceMethod][0].(*api.DeletedResource)
+	}
+	var err error
+	if t.ArgsOut[RestoreDeletedResourceMethod][1] != nil {
+		err = t.ArgsOut[RestoreDeletedResourceMethod][1].(error)
+	}
+	return deletedResource, err
+}
+
+func (t TestAPI) PurgeDeletedResources(authenticatedUser api.RequestInfo) (int, error) {
+	t.ArgsIn[PurgeDeletedResourcesMethod][0] = authenticatedUser
+	var purged int
+	if t.ArgsOut[PurgeDeletedResourcesMethod][0] != nil {
+		purged = t.ArgsOut[PurgeDeletedResourcesMethod][0].(int)
+	}
+	var err error
+	if t.ArgsOut[PurgeDeletedResourcesMethod][1] != nil {
+		err = t.ArgsOut[PurgeDeletedResourcesMethod][1].(error)
+	}
+	return purged, err
+}
+
+// CONSISTENCY API
+
+func (t TestAPI) CheckConsistency(authenticatedUser api.RequestInfo) (*api.ConsistencyReport, error) {
+	t.ArgsIn[CheckConsistencyMethod][0] = authenticatedUser
+	var report *api.ConsistencyReport
+	if t.ArgsOut[CheckConsistencyMethod][0] != nil {
+		report = t.ArgsOut[CheckConsistencyMethod][0].(*api.ConsistencyReport)
+	}
+	var err error
+	if t.ArgsOut[CheckConsistencyMethod][1] != nil {
+		err = t.ArgsOut[CheckConsistencyMethod][1].(error)
+	}
+	return report, err
+}
+
+// AUDIT API
+
+func (t TestAPI) ListAuditEvents(authenticatedUser api.RequestInfo, actor string, urn string, action string, filter *api.Filter) ([]api.AuditEvent, int, error) {
+	t.ArgsIn[ListAuditEventsMethod][0] = authenticatedUser
+	t.ArgsIn[ListAuditEventsMethod][1] = actor
+	t.ArgsIn[ListAuditEventsMethod][2] = urn
+	t.ArgsIn[ListAuditEventsMethod][3] = action
+	t.ArgsIn[ListAuditEventsMethod][4] = filter
+	var events []api.AuditEvent
+	if t.ArgsOut[ListAuditEventsMethod][0] != nil {
+		events = t.ArgsOut[ListAuditEventsMethod][0].([]api.AuditEvent)
+	}
+	var total int
+	if t.ArgsOut[ListAuditEventsMethod][1] != nil {
+		total = t.ArgsOut[ListAuditEventsMethod][1].(int)
+	}
+	var err error
+	if t.ArgsOut[ListAuditEventsMethod][2] != nil {
+		err = t.ArgsOut[ListAuditEventsMethod][2].(error)
+	}
+	return events, total, err
+}
+
+// WEBHOOK API
+
+func (t TestAPI) AddWebhook(authenticatedUser api.RequestInfo, url string, secret string, events []string) (*api.Webhook, error) {
+	t.ArgsIn[AddWebhookMethod][0] = authenticatedUser
+	t.ArgsIn[AddWebhookMethod][1] = url
+	t.ArgsIn[AddWebhookMethod][2] = secret
+	t.ArgsIn[AddWebhookMethod][3] = events
+	var webhook *api.Webhook
+	if t.ArgsOut[AddWebhookMethod][0] != nil {
+		webhook = t.ArgsOut[AddWebhookMethod][0].(*api.Webhook)
+	}
+	var err error
+	if t.ArgsOut[AddWebhookMethod][1] != nil {
+		err = t.ArgsOut[AddWebhookMethod][1].(error)
+	}
+	return webhook, err
+}
+
+func (t TestAPI) GetWebhookByID(authenticatedUser api.RequestInfo, id string) (*api.Webhook, error) {
+	t.ArgsIn[GetWebhookByIDMethod][0] = authenticatedUser
+	t.ArgsIn[GetWebhookByIDMethod][1] = id
+	var webhook *api.Webhook
+	if t.ArgsOut[GetWebhookByIDMethod][0] != nil {
+		webhook = t.ArgsOut[GetWebhookByIDMethod][0].(*api.Webhook)
+	}
+	var err error
+	if t.ArgsOut[GetWebhookByIDMethod][1] != nil {
+		err = t.ArgsOut[GetWebhookByIDMethod][1].(error)
+	}
+	return webhook, err
+}
+
+func (t TestAPI) ListWebhooks(authenticatedUser api.RequestInfo, filter *api.Filter) ([]api.Webhook, int, error) {
+	t.ArgsIn[ListWebhooksMethod][0] = authenticatedUser
+	t.ArgsIn[ListWebhooksMethod][1] = filter
+	var webhooks []api.Webhook
+	if t.ArgsOut[ListWebhooksMethod][0] != nil {
+		webhooks = t.ArgsOut[ListWebhooksMethod][0].([]api.Webhook)
+	}
+	var total int
+	if t.ArgsOut[ListWebhooksMethod][1] != nil {
+		total = t.ArgsOut[ListWebhooksMethod][1].(int)
+	}
+	var err error
+	if t.ArgsOut[ListWebhooksMethod][2] != nil {
+		err = t.ArgsOut[ListWebhooksMethod][2].(error)
+	}
+	return webhooks, total, err
+}
+
+func (t TestAPI) UpdateWebhook(authenticatedUser api.RequestInfo, id string, newUrl string, newSecret string, newEvents []string) (*api.Webhook, error) {
+	t.ArgsIn[UpdateWebhookMethod][0] = authenticatedUser
+	t.ArgsIn[UpdateWebhookMethod][1] = id
+	t.ArgsIn[UpdateWebhookMethod][2] = newUrl
+	t.ArgsIn[UpdateWebhookMethod][3] = newSecret
+	t.ArgsIn[UpdateWebhookMethod][4] = newEvents
+	var webhook *api.Webhook
+	if t.ArgsOut[UpdateWebhookMethod][0] != nil {
+		webhook = t.ArgsOut[UpdateWebhookMethod][0].(*api.Webhook)
+	}
+	var err error
+	if t.ArgsOut[UpdateWebhookMethod][1] != nil {
+		err = t.ArgsOut[UpdateWebhookMethod][1].(error)
+	}
+	return webhook, err
+}
+
+func (t TestAPI) RemoveWebhook(authenticatedUser api.RequestInfo, id string) error {
+	t.ArgsIn[RemoveWebhookMethod][0] = authenticatedUser
+	t.ArgsIn[RemoveWebhookMethod][1] = id
+	var err error
+	if t.ArgsOut[RemoveWebhookMethod][0] != nil {
+		err = t.ArgsOut[RemoveWebhookMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestAPI) ListWebhookDeliveries(authenticatedUser api.RequestInfo, id string, status string, filter *api.Filter) ([]api.WebhookDelivery, int, error) {
+	t.ArgsIn[ListWebhookDeliveriesMethod][0] = authenticatedUser
+	t.ArgsIn[ListWebhookDeliveriesMethod][1] = id
+	t.ArgsIn[ListWebhookDeliveriesMethod][2] = status
+	t.ArgsIn[ListWebhookDeliveriesMethod][3] = filter
+	var deliveries []api.WebhookDelivery
+	if t.ArgsOut[ListWebhookDeliveriesMethod][0] != nil {
+		deliveries = t.ArgsOut[ListWebhookDeliveriesMethod][0].([]api.WebhookDelivery)
+	}
+	var total int
+	if t.ArgsOut[ListWebhookDeliveriesMethod][1] != nil {
+		total = t.ArgsOut[ListWebhookDeliveriesMethod][1].(int)
+	}
+	var err error
+	if t.ArgsOut[ListWebhookDeliveriesMethod][2] != nil {
+		err = t.ArgsOut[ListWebhookDeliveriesMethod][2].(error)
+	}
+	return deliveries, total, err
+}
+
+func (t TestAPI) DeliverWebhookEvents(authenticatedUser api.RequestInfo) (int, error) {
+	t.ArgsIn[DeliverWebhookEventsMethod][0] = authenticatedUser
+	var delivered int
+	if t.ArgsOut[DeliverWebhookEventsMethod][0] != nil {
+		delivered = t.ArgsOut[DeliverWebhookEventsMethod][0].(int)
+	}
+	var err error
+	if t.ArgsOut[DeliverWebhookEventsMethod][1] != nil {
+		err = t.ArgsOut[DeliverWebhookEventsMethod][1].(error)
+	}
+	return delivered, err
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/tecsisa/foulkon/api"
+)
+
+// RESPONSES
+
+type ListAuthenticatedUserPermissionsResponse struct {
+	Permissions []api.Permission `json:"permissions, omitempty"`
+}
+
+// HANDLERS
+
+func (h *WorkerHandler) HandleGetAuthenticatedUser(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+
+	// Call user API to retrieve the authenticated user
+	response, err := h.worker.UserApi.GetAuthenticatedUser(requestInfo)
+	if err != nil {
+		h.respondAuthenticatedUserError(r, requestInfo, w, err)
+		return
+	}
+
+	// Write user to response
+	setETagHeader(w, response)
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleListAuthenticatedUserGroups(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+
+	// Retrieve pagination from query params
+	filter, err := getPaginationFilter(r)
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	result, total, err := h.worker.UserApi.ListAuthenticatedUserGroups(requestInfo, filter)
+	if err != nil {
+		h.respondAuthenticatedUserError(r, requestInfo, w, err)
+		return
+	}
+
+	response := GetGroupsByUserIdResponse{
+		Groups: result,
+		Offset: filter.Offset,
+		Limit:  filter.Limit,
+		Total:  total,
+	}
+
+	// Write groups to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleListAuthenticatedUserPermissions(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+
+	result, err := h.worker.UserApi.ListAuthenticatedUserPermissions(requestInfo)
+	if err != nil {
+		h.respondAuthenticatedUserError(r, requestInfo, w, err)
+		return
+	}
+
+	response := ListAuthenticatedUserPermissionsResponse{
+		Permissions: result,
+	}
+
+	// Write permissions to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+// PRIVATE HELPER METHODS
+
+func (h *WorkerHandler) respondAuthenticatedUserError(r *http.Request, requestInfo api.RequestInfo, w http.ResponseWriter, err error) {
+	// Transform to API errors
+	apiError := err.(*api.Error)
+	api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+	switch apiError.Code {
+	case api.USER_BY_EXTERNAL_ID_NOT_FOUND:
+		h.RespondNotFound(r, requestInfo, w, apiError)
+	case api.INVALID_PARAMETER_ERROR:
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+	default: // Unexpected API error
+		h.RespondInternalServerError(r, requestInfo, w)
+	}
+}
@@ -0,0 +1,360 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+)
+
+func TestWorkerHandler_HandleGetAuthenticatedUser(t *testing.T) {
+	testcases := map[string]struct {
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.User
+		expectedError      api.Error
+		// Manager Results
+		getAuthenticatedUserResult *api.User
+		// Manager Errors
+		getAuthenticatedUserErr error
+	}{
+		"OkCase": {
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "Path",
+				Urn:        "urn",
+			},
+			getAuthenticatedUserResult: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "Path",
+				Urn:        "urn",
+			},
+		},
+		"ErrorCaseUserNotExist": {
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not exist",
+			},
+			getAuthenticatedUserErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not exist",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			expectedStatusCode: http.StatusInternalServerError,
+			getAuthenticatedUserErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[GetAuthenticatedUserMethod][0] = test.getAuthenticatedUserResult
+		testApi.ArgsOut[GetAuthenticatedUserMethod][1] = test.getAuthenticatedUserErr
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+ME_URL, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			getUserResponse := &api.User{}
+			err = json.NewDecoder(res.Body).Decode(getUserResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(getUserResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleListAuthenticatedUserGroups(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		offset string
+		limit  string
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   GetGroupsByUserIdResponse
+		expectedError      api.Error
+		// Manager Results
+		listGroupsResult []api.GroupIdentity
+		listGroupsTotal  int
+		// Manager Errors
+		listGroupsErr error
+	}{
+		"OkCase": {
+			offset:             "1",
+			limit:              "1",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: GetGroupsByUserIdResponse{
+				Groups: []api.GroupIdentity{
+					{
+						Org:  "org2",
+						Name: "group2",
+					},
+				},
+				Offset: 1,
+				Limit:  1,
+				Total:  2,
+			},
+			listGroupsResult: []api.GroupIdentity{
+				{
+					Org:  "org2",
+					Name: "group2",
+				},
+			},
+			listGroupsTotal: 2,
+		},
+		"ErrorCaseInvalidLimit": {
+			limit:              "a",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Limit a",
+			},
+		},
+		"ErrorCaseUserNotExist": {
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not exist",
+			},
+			listGroupsErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not exist",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			expectedStatusCode: http.StatusInternalServerError,
+			listGroupsErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[ListAuthenticatedUserGroupsMethod][0] = test.listGroupsResult
+		testApi.ArgsOut[ListAuthenticatedUserGroupsMethod][1] = test.listGroupsTotal
+		testApi.ArgsOut[ListAuthenticatedUserGroupsMethod][2] = test.listGroupsErr
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+ME_GROUPS_URL, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		q := req.URL.Query()
+		if test.offset != "" {
+			q.Add("Offset", test.offset)
+		}
+		if test.limit != "" {
+			q.Add("Limit", test.limit)
+		}
+		req.URL.RawQuery = q.Encode()
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			listGroupsResponse := GetGroupsByUserIdResponse{}
+			err = json.NewDecoder(res.Body).Decode(&listGroupsResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(listGroupsResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleListAuthenticatedUserPermissions(t *testing.T) {
+	testcases := map[string]struct {
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   ListAuthenticatedUserPermissionsResponse
+		expectedError      api.Error
+		// Manager Results
+		listPermissionsResult []api.Permission
+		// Manager Errors
+		listPermissionsErr error
+	}{
+		"OkCase": {
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: ListAuthenticatedUserPermissionsResponse{
+				Permissions: []api.Permission{
+					{
+						Org:    "org1",
+						Group:  "group1",
+						Policy: "policy1",
+						Statement: api.Statement{
+							Effect:    "allow",
+							Actions:   []string{"iam:GetGroup"},
+							Resources: []string{"urn:iws:iam:org1:group/*"},
+						},
+					},
+				},
+			},
+			listPermissionsResult: []api.Permission{
+				{
+					Org:    "org1",
+					Group:  "group1",
+					Policy: "policy1",
+					Statement: api.Statement{
+						Effect:    "allow",
+						Actions:   []string{"iam:GetGroup"},
+						Resources: []string{"urn:iws:iam:org1:group/*"},
+					},
+				},
+			},
+		},
+		"ErrorCaseUserNotExist": {
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not exist",
+			},
+			listPermissionsErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not exist",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			expectedStatusCode: http.StatusInternalServerError,
+			listPermissionsErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[ListAuthenticatedUserPermissionsMethod][0] = test.listPermissionsResult
+		testApi.ArgsOut[ListAuthenticatedUserPermissionsMethod][1] = test.listPermissionsErr
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+ME_PERMISSIONS_URL, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			listPermissionsResponse := ListAuthenticatedUserPermissionsResponse{}
+			err = json.NewDecoder(res.Body).Decode(&listPermissionsResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(listPermissionsResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
@@ -0,0 +1,412 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/tecsisa/foulkon/api"
+)
+
+// REQUESTS
+
+type CreateOrganizationRequest struct {
+	Name        string `json:"name, omitempty"`
+	Description string `json:"description, omitempty"`
+}
+
+type UpdateOrganizationRequest struct {
+	Description string `json:"description, omitempty"`
+}
+
+type ApplyOrganizationRequest struct {
+	Document *api.OrganizationDocument `json:"document, omitempty"`
+	Plan     *api.Plan                 `json:"plan, omitempty"`
+}
+
+// RESPONSES
+
+type ListOrganizationsResponse struct {
+	Organizations []string `json:"organizations, omitempty"`
+	Offset        int      `json:"offset, omitempty"`
+	Limit         int      `json:"limit, omitempty"`
+	Total         int      `json:"total, omitempty"`
+}
+
+// HANDLERS
+
+func (h *WorkerHandler) HandleAddOrganization(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Decode request
+	request := CreateOrganizationRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call organization API to create an organization
+	response, err := h.worker.OrganizationApi.AddOrganization(requestInfo, request.Name, request.Description)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.ORGANIZATION_ALREADY_EXIST:
+			h.RespondConflict(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Write organization to response
+	h.RespondCreated(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleGetOrganizationByName(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve organization name from path
+	name := ps.ByName(ORG_NAME)
+
+	// Call organization API to retrieve organization
+	response, err := h.worker.OrganizationApi.GetOrganizationByName(requestInfo, name)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.ORGANIZATION_BY_NAME_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Write organization to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleListOrganizations(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve filter from query params
+	filter, err := getPaginationFilter(r)
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+	filter.Name = r.URL.Query().Get("Name")
+
+	// Call organization API to retrieve organizations
+	result, total, err := h.worker.OrganizationApi.ListOrganizations(requestInfo, filter)
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	organizations := []string{}
+	for _, organization := range result {
+		organizations = append(organizations, organization.Name)
+	}
+
+	// Create response
+	response := &ListOrganizationsResponse{
+		Organizations: organizations,
+		Offset:        filter.Offset,
+		Limit:         filter.Limit,
+		Total:         total,
+	}
+
+	// Return data
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleUpdateOrganization(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Decode request
+	request := UpdateOrganizationRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Retrieve organization name from path
+	name := ps.ByName(ORG_NAME)
+
+	// Call organization API to update organization
+	response, err := h.worker.OrganizationApi.UpdateOrganization(requestInfo, name, request.Description)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.ORGANIZATION_BY_NAME_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Write organization to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleRemoveOrganization(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve organization name from path
+	name := ps.ByName(ORG_NAME)
+
+	// Call organization API to remove organization with its groups and policies
+	err := h.worker.OrganizationApi.RemoveOrganization(requestInfo, name)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.ORGANIZATION_BY_NAME_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	h.RespondNoContent(r, requestInfo, w)
+}
+
+func (h *WorkerHandler) HandleExportOrganization(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Check document format from query
+	if apiError := checkOrganizationDocumentFormat(r); apiError != nil {
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+	// Retrieve org from path
+	org := ps.ByName(ORG_NAME)
+
+	// Call organization API to export it
+	document, err := h.worker.OrganizationApi.ExportOrganization(requestInfo, org)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Return organization document
+	h.RespondOk(r, requestInfo, w, document)
+}
+
+func (h *WorkerHandler) HandleImportOrganization(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Check document format from query
+	if apiError := checkOrganizationDocumentFormat(r); apiError != nil {
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+	// Decode request, it is the document returned by the export
+	document := api.OrganizationDocument{}
+	err := json.NewDecoder(r.Body).Decode(&document)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Retrieve org from path and conflict mode from query, failing by default
+	org := ps.ByName(ORG_NAME)
+	conflictMode := r.URL.Query().Get("Conflict")
+	if conflictMode == "" {
+		conflictMode = api.IMPORT_CONFLICT_FAIL
+	}
+
+	// Call organization API to import document
+	result, err := h.worker.OrganizationApi.ImportOrganization(requestInfo, org, &document, conflictMode)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.USER_BY_EXTERNAL_ID_NOT_FOUND, api.POLICY_BY_ORG_AND_NAME_NOT_FOUND, api.ORGANIZATION_BY_NAME_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.GROUP_ALREADY_EXIST, api.POLICY_ALREADY_EXIST:
+			h.RespondConflict(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Return import result
+	h.RespondOk(r, requestInfo, w, result)
+}
+
+func (h *WorkerHandler) HandlePlanOrganization(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Decode request, it is the document with the desired state
+	document := api.OrganizationDocument{}
+	err := json.NewDecoder(r.Body).Decode(&document)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Retrieve org from path
+	org := ps.ByName(ORG_NAME)
+
+	// Call organization API to plan changes
+	plan, err := h.worker.OrganizationApi.PlanOrganization(requestInfo, org, &document)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Return plan
+	h.RespondOk(r, requestInfo, w, plan)
+}
+
+func (h *WorkerHandler) HandleApplyOrganization(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Decode request
+	request := ApplyOrganizationRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Retrieve org from path
+	org := ps.ByName(ORG_NAME)
+
+	// Call organization API to apply confirmed plan
+	plan, err := h.worker.OrganizationApi.ApplyOrganization(requestInfo, org, request.Document, request.Plan)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.USER_BY_EXTERNAL_ID_NOT_FOUND, api.ORGANIZATION_BY_NAME_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.PLAN_OUTDATED:
+			h.RespondConflict(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Return applied plan
+	h.RespondOk(r, requestInfo, w, plan)
+}
+
+// Check format query parameter of an organization document. It's optional because JSON is the only format supported
+func checkOrganizationDocumentFormat(r *http.Request) *api.Error {
+	format := r.URL.Query().Get("Format")
+	if format != "" && format != api.ORGANIZATION_DOCUMENT_FORMAT {
+		return &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: format %v. Only %v is supported", format, api.ORGANIZATION_DOCUMENT_FORMAT),
+		}
+	}
+	return nil
+}
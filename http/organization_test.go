@@ -0,0 +1,1421 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+)
+
+func TestWorkerHandler_HandleExportOrganization(t *testing.T) {
+	document := &api.OrganizationDocument{
+		Version: api.ORGANIZATION_DOCUMENT_VERSION,
+		Org:     "org1",
+		Policies: []api.DocumentPolicy{
+			{
+				Name: "policy1",
+				Path: "/path/",
+				Statements: []api.Statement{
+					{
+						Effect:    "allow",
+						Actions:   []string{api.USER_ACTION_GET_USER},
+						Resources: []string{api.GetUrnPrefix("", api.RESOURCE_USER, "/path/")},
+					},
+				},
+			},
+		},
+		Groups: []api.DocumentGroup{
+			{
+				Name: "group1",
+				Path: "/path/",
+				Members: []api.DocumentMember{
+					{
+						ExternalID: "user1",
+					},
+				},
+				Policies: []api.DocumentAttachment{
+					{
+						Policy: "policy1",
+					},
+				},
+			},
+		},
+	}
+	testcases := map[string]struct {
+		// API method args
+		org    string
+		format string
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.OrganizationDocument
+		expectedError      api.Error
+		// Manager Results
+		exportOrganizationResult *api.OrganizationDocument
+		// Manager Errors
+		exportOrganizationErr error
+	}{
+		"OkCase": {
+			org:                      "org1",
+			expectedStatusCode:       http.StatusOK,
+			expectedResponse:         document,
+			exportOrganizationResult: document,
+		},
+		"OkCaseJSONFormat": {
+			org:                      "org1",
+			format:                   "json",
+			expectedStatusCode:       http.StatusOK,
+			expectedResponse:         document,
+			exportOrganizationResult: document,
+		},
+		"ErrorCaseUnsupportedFormat": {
+			org:                "org1",
+			format:             "yaml",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: format yaml. Only json is supported",
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			org:                "invalid",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+			exportOrganizationErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			org:                "org1",
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			exportOrganizationErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			org:                "org1",
+			expectedStatusCode: http.StatusInternalServerError,
+			exportOrganizationErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[ExportOrganizationMethod][0] = test.exportOrganizationResult
+		testApi.ArgsOut[ExportOrganizationMethod][1] = test.exportOrganizationErr
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/export", test.org)
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+		if test.format != "" {
+			q := req.URL.Query()
+			q.Add("Format", test.format)
+			req.URL.RawQuery = q.Encode()
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters, API isn't called with an unsupported format
+		if test.format != "yaml" && testApi.ArgsIn[ExportOrganizationMethod][1] != test.org {
+			t.Errorf("Test case %v. Received different org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[ExportOrganizationMethod][1])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			response := &api.OrganizationDocument{}
+			err = json.NewDecoder(res.Body).Decode(response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleImportOrganization(t *testing.T) {
+	document := &api.OrganizationDocument{
+		Version: api.ORGANIZATION_DOCUMENT_VERSION,
+		Org:     "org1",
+		Groups: []api.DocumentGroup{
+			{
+				Name: "group1",
+				Path: "/path/",
+				Members: []api.DocumentMember{
+					{
+						ExternalID: "user1",
+					},
+				},
+			},
+		},
+	}
+	testcases := map[string]struct {
+		// API method args
+		org      string
+		conflict string
+		format   string
+		document *api.OrganizationDocument
+		// Expected result
+		expectedConflictMode string
+		expectedStatusCode   int
+		expectedResponse     *api.ImportResult
+		expectedError        api.Error
+		// Manager Results
+		importOrganizationResult *api.ImportResult
+		// Manager Errors
+		importOrganizationErr error
+	}{
+		"OkCase": {
+			org:                  "org2",
+			document:             document,
+			expectedConflictMode: api.IMPORT_CONFLICT_FAIL,
+			expectedStatusCode:   http.StatusOK,
+			expectedResponse: &api.ImportResult{
+				CreatedGroups: []string{"group1"},
+			},
+			importOrganizationResult: &api.ImportResult{
+				CreatedGroups: []string{"group1"},
+			},
+		},
+		"OkCaseOverwrite": {
+			org:                  "org1",
+			conflict:             api.IMPORT_CONFLICT_OVERWRITE,
+			document:             document,
+			expectedConflictMode: api.IMPORT_CONFLICT_OVERWRITE,
+			expectedStatusCode:   http.StatusOK,
+			expectedResponse: &api.ImportResult{
+				UpdatedGroups: []string{"group1"},
+			},
+			importOrganizationResult: &api.ImportResult{
+				UpdatedGroups: []string{"group1"},
+			},
+		},
+		"ErrorCaseUnsupportedFormat": {
+			org:                "org2",
+			format:             "yaml",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: format yaml. Only json is supported",
+			},
+		},
+		"ErrorCaseMalformedRequest": {
+			org:                "org1",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			org:                  "org1",
+			conflict:             "merge",
+			document:             document,
+			expectedConflictMode: "merge",
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: conflict merge",
+			},
+			importOrganizationErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: conflict merge",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			org:                  "org1",
+			document:             document,
+			expectedConflictMode: api.IMPORT_CONFLICT_FAIL,
+			expectedStatusCode:   http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			importOrganizationErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			org:                  "org1",
+			document:             document,
+			expectedConflictMode: api.IMPORT_CONFLICT_FAIL,
+			expectedStatusCode:   http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "Not found",
+			},
+			importOrganizationErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "Not found",
+			},
+		},
+		"ErrorCaseGroupAlreadyExist": {
+			org:                  "org1",
+			document:             document,
+			expectedConflictMode: api.IMPORT_CONFLICT_FAIL,
+			expectedStatusCode:   http.StatusConflict,
+			expectedError: api.Error{
+				Code:    api.GROUP_ALREADY_EXIST,
+				Message: "Already exist",
+			},
+			importOrganizationErr: &api.Error{
+				Code:    api.GROUP_ALREADY_EXIST,
+				Message: "Already exist",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			org:                  "org1",
+			document:             document,
+			expectedConflictMode: api.IMPORT_CONFLICT_FAIL,
+			expectedStatusCode:   http.StatusInternalServerError,
+			importOrganizationErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[ImportOrganizationMethod][0] = test.importOrganizationResult
+		testApi.ArgsOut[ImportOrganizationMethod][1] = test.importOrganizationErr
+
+		body := bytes.NewBuffer([]byte{})
+		if test.document != nil {
+			jsonObject, err := json.Marshal(test.document)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/import", test.org)
+		req, err := http.NewRequest(http.MethodPost, url, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+		q := req.URL.Query()
+		if test.conflict != "" {
+			q.Add("Conflict", test.conflict)
+		}
+		if test.format != "" {
+			q.Add("Format", test.format)
+		}
+		req.URL.RawQuery = q.Encode()
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		if test.document != nil {
+			// Check received parameters
+			if testApi.ArgsIn[ImportOrganizationMethod][1] != test.org {
+				t.Errorf("Test case %v. Received different org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[ImportOrganizationMethod][1])
+				continue
+			}
+			if diff := pretty.Compare(testApi.ArgsIn[ImportOrganizationMethod][2], test.document); diff != "" {
+				t.Errorf("Test case %v. Received different document (received/wanted) %v", n, diff)
+				continue
+			}
+			if testApi.ArgsIn[ImportOrganizationMethod][3] != test.expectedConflictMode {
+				t.Errorf("Test case %v. Received different conflict mode (wanted:%v / received:%v)", n, test.expectedConflictMode, testApi.ArgsIn[ImportOrganizationMethod][3])
+				continue
+			}
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			response := &api.ImportResult{}
+			err = json.NewDecoder(res.Body).Decode(response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandlePlanOrganization(t *testing.T) {
+	document := &api.OrganizationDocument{
+		Version: api.ORGANIZATION_DOCUMENT_VERSION,
+		Groups: []api.DocumentGroup{
+			{
+				Name: "group1",
+				Path: "/path/",
+			},
+		},
+	}
+	plan := &api.Plan{
+		Org: "org1",
+		Changes: []api.PlanChange{
+			{
+				Action:   api.PLAN_ACTION_CREATE,
+				Resource: api.PLAN_RESOURCE_GROUP,
+				Name:     "group1",
+			},
+		},
+	}
+	testcases := map[string]struct {
+		// API method args
+		org      string
+		document *api.OrganizationDocument
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.Plan
+		expectedError      api.Error
+		// Manager Results
+		planOrganizationResult *api.Plan
+		// Manager Errors
+		planOrganizationErr error
+	}{
+		"OkCase": {
+			org:                    "org1",
+			document:               document,
+			expectedStatusCode:     http.StatusOK,
+			expectedResponse:       plan,
+			planOrganizationResult: plan,
+		},
+		"ErrorCaseMalformedRequest": {
+			org:                "org1",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			org:                "org1",
+			document:           document,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+			planOrganizationErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			org:                "org1",
+			document:           document,
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			planOrganizationErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			org:                "org1",
+			document:           document,
+			expectedStatusCode: http.StatusInternalServerError,
+			planOrganizationErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[PlanOrganizationMethod][0] = test.planOrganizationResult
+		testApi.ArgsOut[PlanOrganizationMethod][1] = test.planOrganizationErr
+
+		body := bytes.NewBuffer([]byte{})
+		if test.document != nil {
+			jsonObject, err := json.Marshal(test.document)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/plan", test.org)
+		req, err := http.NewRequest(http.MethodPost, url, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		if test.document != nil {
+			// Check received parameters
+			if testApi.ArgsIn[PlanOrganizationMethod][1] != test.org {
+				t.Errorf("Test case %v. Received different org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[PlanOrganizationMethod][1])
+				continue
+			}
+			if diff := pretty.Compare(testApi.ArgsIn[PlanOrganizationMethod][2], test.document); diff != "" {
+				t.Errorf("Test case %v. Received different document (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			response := &api.Plan{}
+			err = json.NewDecoder(res.Body).Decode(response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleApplyOrganization(t *testing.T) {
+	plan := &api.Plan{
+		Org: "org1",
+		Changes: []api.PlanChange{
+			{
+				Action:   api.PLAN_ACTION_CREATE,
+				Resource: api.PLAN_RESOURCE_GROUP,
+				Name:     "group1",
+			},
+		},
+	}
+	request := &ApplyOrganizationRequest{
+		Document: &api.OrganizationDocument{
+			Version: api.ORGANIZATION_DOCUMENT_VERSION,
+			Groups: []api.DocumentGroup{
+				{
+					Name: "group1",
+					Path: "/path/",
+				},
+			},
+		},
+		Plan: plan,
+	}
+	testcases := map[string]struct {
+		// API method args
+		org     string
+		request *ApplyOrganizationRequest
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.Plan
+		expectedError      api.Error
+		// Manager Results
+		applyOrganizationResult *api.Plan
+		// Manager Errors
+		applyOrganizationErr error
+	}{
+		"OkCase": {
+			org:                     "org1",
+			request:                 request,
+			expectedStatusCode:      http.StatusOK,
+			expectedResponse:        plan,
+			applyOrganizationResult: plan,
+		},
+		"ErrorCaseMalformedRequest": {
+			org:                "org1",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			org:                "org1",
+			request:            request,
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			applyOrganizationErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			org:                "org1",
+			request:            request,
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "Not found",
+			},
+			applyOrganizationErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "Not found",
+			},
+		},
+		"ErrorCasePlanOutdated": {
+			org:                "org1",
+			request:            request,
+			expectedStatusCode: http.StatusConflict,
+			expectedError: api.Error{
+				Code:    api.PLAN_OUTDATED,
+				Message: "Outdated",
+			},
+			applyOrganizationErr: &api.Error{
+				Code:    api.PLAN_OUTDATED,
+				Message: "Outdated",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			org:                "org1",
+			request:            request,
+			expectedStatusCode: http.StatusInternalServerError,
+			applyOrganizationErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[ApplyOrganizationMethod][0] = test.applyOrganizationResult
+		testApi.ArgsOut[ApplyOrganizationMethod][1] = test.applyOrganizationErr
+
+		body := bytes.NewBuffer([]byte{})
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/apply", test.org)
+		req, err := http.NewRequest(http.MethodPost, url, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		if test.request != nil {
+			// Check received parameters
+			if testApi.ArgsIn[ApplyOrganizationMethod][1] != test.org {
+				t.Errorf("Test case %v. Received different org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[ApplyOrganizationMethod][1])
+				continue
+			}
+			if diff := pretty.Compare(testApi.ArgsIn[ApplyOrganizationMethod][2], test.request.Document); diff != "" {
+				t.Errorf("Test case %v. Received different document (received/wanted) %v", n, diff)
+				continue
+			}
+			if diff := pretty.Compare(testApi.ArgsIn[ApplyOrganizationMethod][3], test.request.Plan); diff != "" {
+				t.Errorf("Test case %v. Received different plan (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			response := &api.Plan{}
+			err = json.NewDecoder(res.Body).Decode(response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleAddOrganization(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		request *CreateOrganizationRequest
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.Organization
+		expectedError      api.Error
+		// Manager Results
+		addOrganizationResult *api.Organization
+		// Manager Errors
+		addOrganizationErr error
+	}{
+		"OkCase": {
+			request: &CreateOrganizationRequest{
+				Name:        "org1",
+				Description: "Organization 1",
+			},
+			expectedStatusCode: http.StatusCreated,
+			expectedResponse: &api.Organization{
+				ID:          "OrgID",
+				Name:        "org1",
+				Description: "Organization 1",
+			},
+			addOrganizationResult: &api.Organization{
+				ID:          "OrgID",
+				Name:        "org1",
+				Description: "Organization 1",
+			},
+		},
+		"ErrorCaseAlreadyExistError": {
+			request: &CreateOrganizationRequest{
+				Name: "org1",
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedError: api.Error{
+				Code:    api.ORGANIZATION_ALREADY_EXIST,
+				Message: "Organization already exist",
+			},
+			addOrganizationErr: &api.Error{
+				Code:    api.ORGANIZATION_ALREADY_EXIST,
+				Message: "Organization already exist",
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			request: &CreateOrganizationRequest{
+				Name: "invalid*",
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+			addOrganizationErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			request: &CreateOrganizationRequest{
+				Name: "org1",
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			addOrganizationErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			request: &CreateOrganizationRequest{
+				Name: "org1",
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			addOrganizationErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[AddOrganizationMethod][0] = test.addOrganizationResult
+		testApi.ArgsOut[AddOrganizationMethod][1] = test.addOrganizationErr
+
+		var body *bytes.Buffer
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+		if body == nil {
+			body = bytes.NewBuffer([]byte{})
+		}
+		req, err := http.NewRequest(http.MethodPost, server.URL+ORGANIZATION_ROOT_URL, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[AddOrganizationMethod][1] != test.request.Name {
+			t.Errorf("Test case %v. Received different name (wanted:%v / received:%v)", n, test.request.Name, testApi.ArgsIn[AddOrganizationMethod][1])
+			continue
+		}
+		if testApi.ArgsIn[AddOrganizationMethod][2] != test.request.Description {
+			t.Errorf("Test case %v. Received different description (wanted:%v / received:%v)", n, test.request.Description, testApi.ArgsIn[AddOrganizationMethod][2])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusCreated:
+			response := &api.Organization{}
+			err = json.NewDecoder(res.Body).Decode(response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleGetOrganizationByName(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		name string
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.Organization
+		expectedError      api.Error
+		// Manager Results
+		getOrganizationResult *api.Organization
+		// Manager Errors
+		getOrganizationErr error
+	}{
+		"OkCase": {
+			name:               "org1",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.Organization{
+				ID:   "OrgID",
+				Name: "org1",
+			},
+			getOrganizationResult: &api.Organization{
+				ID:   "OrgID",
+				Name: "org1",
+			},
+		},
+		"ErrorCaseNotFoundError": {
+			name:               "org1",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.ORGANIZATION_BY_NAME_NOT_FOUND,
+				Message: "Not found",
+			},
+			getOrganizationErr: &api.Error{
+				Code:    api.ORGANIZATION_BY_NAME_NOT_FOUND,
+				Message: "Not found",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			name:               "org1",
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			getOrganizationErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			name:               "org1",
+			expectedStatusCode: http.StatusInternalServerError,
+			getOrganizationErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[GetOrganizationMethod][0] = test.getOrganizationResult
+		testApi.ArgsOut[GetOrganizationMethod][1] = test.getOrganizationErr
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v", test.name)
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[GetOrganizationMethod][1] != test.name {
+			t.Errorf("Test case %v. Received different name (wanted:%v / received:%v)", n, test.name, testApi.ArgsIn[GetOrganizationMethod][1])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			response := &api.Organization{}
+			err = json.NewDecoder(res.Body).Decode(response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleListOrganizations(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		filter *api.Filter
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   ListOrganizationsResponse
+		expectedError      api.Error
+		// Manager Results
+		listOrganizationsResult []api.Organization
+		totalResult             int
+		// Manager Errors
+		listOrganizationsErr error
+	}{
+		"OkCase": {
+			filter: &api.Filter{
+				Name:   "org",
+				Offset: 0,
+				Limit:  20,
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: ListOrganizationsResponse{
+				Organizations: []string{"org1"},
+				Offset:        0,
+				Limit:         20,
+				Total:         1,
+			},
+			listOrganizationsResult: []api.Organization{
+				{
+					ID:   "OrgID",
+					Name: "org1",
+				},
+			},
+			totalResult: 1,
+		},
+		"ErrorCaseUnauthorizedError": {
+			filter:             &api.Filter{},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			listOrganizationsErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			filter:             &api.Filter{},
+			expectedStatusCode: http.StatusInternalServerError,
+			listOrganizationsErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[ListOrganizationsMethod][0] = test.listOrganizationsResult
+		testApi.ArgsOut[ListOrganizationsMethod][1] = test.totalResult
+		testApi.ArgsOut[ListOrganizationsMethod][2] = test.listOrganizationsErr
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+ORGANIZATION_ROOT_URL, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+		q := req.URL.Query()
+		if test.filter.Name != "" {
+			q.Add("Name", test.filter.Name)
+		}
+		if test.filter.Limit > 0 {
+			q.Add("Limit", fmt.Sprintf("%v", test.filter.Limit))
+		}
+		req.URL.RawQuery = q.Encode()
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if diff := pretty.Compare(testApi.ArgsIn[ListOrganizationsMethod][1], test.filter); diff != "" {
+			t.Errorf("Test %v failed. Received different filter (received/wanted) %v", n, diff)
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			response := ListOrganizationsResponse{}
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleUpdateOrganization(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		name    string
+		request *UpdateOrganizationRequest
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.Organization
+		expectedError      api.Error
+		// Manager Results
+		updateOrganizationResult *api.Organization
+		// Manager Errors
+		updateOrganizationErr error
+	}{
+		"OkCase": {
+			name: "org1",
+			request: &UpdateOrganizationRequest{
+				Description: "New description",
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.Organization{
+				ID:          "OrgID",
+				Name:        "org1",
+				Description: "New description",
+			},
+			updateOrganizationResult: &api.Organization{
+				ID:          "OrgID",
+				Name:        "org1",
+				Description: "New description",
+			},
+		},
+		"ErrorCaseNotFoundError": {
+			name: "org1",
+			request: &UpdateOrganizationRequest{
+				Description: "New description",
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.ORGANIZATION_BY_NAME_NOT_FOUND,
+				Message: "Not found",
+			},
+			updateOrganizationErr: &api.Error{
+				Code:    api.ORGANIZATION_BY_NAME_NOT_FOUND,
+				Message: "Not found",
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			name: "org1",
+			request: &UpdateOrganizationRequest{
+				Description: "New description",
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+			updateOrganizationErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			name: "org1",
+			request: &UpdateOrganizationRequest{
+				Description: "New description",
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			updateOrganizationErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[UpdateOrganizationMethod][0] = test.updateOrganizationResult
+		testApi.ArgsOut[UpdateOrganizationMethod][1] = test.updateOrganizationErr
+
+		jsonObject, err := json.Marshal(test.request)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+			continue
+		}
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v", test.name)
+		req, err := http.NewRequest(http.MethodPut, url, bytes.NewBuffer(jsonObject))
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[UpdateOrganizationMethod][1] != test.name {
+			t.Errorf("Test case %v. Received different name (wanted:%v / received:%v)", n, test.name, testApi.ArgsIn[UpdateOrganizationMethod][1])
+			continue
+		}
+		if testApi.ArgsIn[UpdateOrganizationMethod][2] != test.request.Description {
+			t.Errorf("Test case %v. Received different description (wanted:%v / received:%v)", n, test.request.Description, testApi.ArgsIn[UpdateOrganizationMethod][2])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			response := &api.Organization{}
+			err = json.NewDecoder(res.Body).Decode(response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleRemoveOrganization(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		name string
+		// Expected result
+		expectedStatusCode int
+		expectedError      api.Error
+		// Manager Errors
+		removeOrganizationErr error
+	}{
+		"OkCase": {
+			name:               "org1",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		"ErrorCaseNotFoundError": {
+			name:               "org1",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.ORGANIZATION_BY_NAME_NOT_FOUND,
+				Message: "Not found",
+			},
+			removeOrganizationErr: &api.Error{
+				Code:    api.ORGANIZATION_BY_NAME_NOT_FOUND,
+				Message: "Not found",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			name:               "org1",
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			removeOrganizationErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			name:               "org1",
+			expectedStatusCode: http.StatusInternalServerError,
+			removeOrganizationErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[RemoveOrganizationMethod][0] = test.removeOrganizationErr
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v", test.name)
+		req, err := http.NewRequest(http.MethodDelete, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[RemoveOrganizationMethod][1] != test.name {
+			t.Errorf("Test case %v. Received different name (wanted:%v / received:%v)", n, test.name, testApi.ArgsIn[RemoveOrganizationMethod][1])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusNoContent, http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
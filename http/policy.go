@@ -2,6 +2,8 @@ package http
 
 import (
 	"encoding/json"
+	"io/ioutil"
+	"mime"
 	"net/http"
 
 	"github.com/julienschmidt/httprouter"
@@ -24,16 +26,38 @@ type UpdatePolicyRequest struct {
 
 // RESPONSES
 
+// Updated policy with the policy statements that still reference its old URN
+type UpdatePolicyResponse struct {
+	*api.Policy
+	DanglingReferences []api.StatementReference `json:"danglingReferences, omitempty"`
+}
+
 type ListPoliciesResponse struct {
 	Policies []string `json:"policies, omitempty"`
+	Offset   int      `json:"offset, omitempty"`
+	Limit    int      `json:"limit, omitempty"`
+	Total    int      `json:"total, omitempty"`
 }
 
 type ListAllPoliciesResponse struct {
 	Policies []api.PolicyIdentity `json:"policies, omitempty"`
+	Offset   int                  `json:"offset, omitempty"`
+	Limit    int                  `json:"limit, omitempty"`
+	Total    int                  `json:"total, omitempty"`
+}
+
+type ListPoliciesExpandedResponse struct {
+	Policies []api.Policy `json:"policies, omitempty"`
+	Offset   int          `json:"offset, omitempty"`
+	Limit    int          `json:"limit, omitempty"`
+	Total    int          `json:"total, omitempty"`
 }
 
 type ListAttachedGroupsResponse struct {
 	Groups []string `json:"groups, omitempty"`
+	Offset int      `json:"offset, omitempty"`
+	Limit  int      `json:"limit, omitempty"`
+	Total  int      `json:"total, omitempty"`
 }
 
 // HANDLERS
@@ -67,6 +91,8 @@ func (h *WorkerHandler) HandleAddPolicy(w http.ResponseWriter, r *http.Request,
 		switch apiError.Code {
 		case api.POLICY_ALREADY_EXIST:
 			h.RespondConflict(r, requestInfo, w, apiError)
+		case api.ORGANIZATION_BY_NAME_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
 		case api.INVALID_PARAMETER_ERROR:
 			h.RespondBadRequest(r, requestInfo, w, apiError)
 		case api.UNAUTHORIZED_RESOURCES_ERROR:
@@ -109,6 +135,7 @@ func (h *WorkerHandler) HandleGetPolicyByName(w http.ResponseWriter, r *http.Req
 	}
 
 	// Return policy
+	setETagHeader(w, response)
 	h.RespondOk(r, requestInfo, w, response)
 }
 
@@ -117,11 +144,24 @@ func (h *WorkerHandler) HandleListPolicies(w http.ResponseWriter, r *http.Reques
 	// Retrieve org from path
 	org := ps.ByName(ORG_NAME)
 
-	// Retrieve query param if exist
-	pathPrefix := r.URL.Query().Get("PathPrefix")
+	// Retrieve filter from query params
+	filter, err := getListFilter(r, org)
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+	expand, err := getBoolQueryParam(r, "Expand")
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
 
 	// Call policy API to retrieve policies
-	result, err := h.worker.PolicyApi.ListPolicies(requestInfo, org, pathPrefix)
+	result, total, err := h.worker.PolicyApi.ListPolicies(requestInfo, filter)
 	if err != nil {
 		// Transform to API errors
 		apiError := err.(*api.Error)
@@ -137,6 +177,17 @@ func (h *WorkerHandler) HandleListPolicies(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	// Return full policies if requested
+	if expand {
+		h.RespondOk(r, requestInfo, w, &ListPoliciesExpandedResponse{
+			Policies: result,
+			Offset:   filter.Offset,
+			Limit:    filter.Limit,
+			Total:    total,
+		})
+		return
+	}
+
 	// Create response
 	policies := []string{}
 	for _, policy := range result {
@@ -144,6 +195,9 @@ func (h *WorkerHandler) HandleListPolicies(w http.ResponseWriter, r *http.Reques
 	}
 	response := &ListPoliciesResponse{
 		Policies: policies,
+		Offset:   filter.Offset,
+		Limit:    filter.Limit,
+		Total:    total,
 	}
 
 	// Return policies
@@ -152,11 +206,24 @@ func (h *WorkerHandler) HandleListPolicies(w http.ResponseWriter, r *http.Reques
 
 func (h *WorkerHandler) HandleListAllPolicies(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	requestInfo := h.GetRequestInfo(r)
-	// get Org and PathPrefix from request, so the query can be filtered
-	pathPrefix := r.URL.Query().Get("PathPrefix")
+	// Retrieve filter from query params
+	filter, err := getListFilter(r, "")
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+	expand, err := getBoolQueryParam(r, "Expand")
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
 
 	// Call policies API to retrieve policies
-	result, err := h.worker.PolicyApi.ListPolicies(requestInfo, "", pathPrefix)
+	result, total, err := h.worker.PolicyApi.ListPolicies(requestInfo, filter)
 	if err != nil {
 		// Transform to API errors
 		apiError := err.(*api.Error)
@@ -172,9 +239,30 @@ func (h *WorkerHandler) HandleListAllPolicies(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// Return full policies if requested
+	if expand {
+		h.RespondOk(r, requestInfo, w, &ListPoliciesExpandedResponse{
+			Policies: result,
+			Offset:   filter.Offset,
+			Limit:    filter.Limit,
+			Total:    total,
+		})
+		return
+	}
+
 	// Create response
+	policies := []api.PolicyIdentity{}
+	for _, policy := range result {
+		policies = append(policies, api.PolicyIdentity{
+			Org:  policy.Org,
+			Name: policy.Name,
+		})
+	}
 	response := &ListAllPoliciesResponse{
-		Policies: result,
+		Policies: policies,
+		Offset:   filter.Offset,
+		Limit:    filter.Limit,
+		Total:    total,
 	}
 
 	// Return policies
@@ -183,6 +271,9 @@ func (h *WorkerHandler) HandleListAllPolicies(w http.ResponseWriter, r *http.Req
 
 func (h *WorkerHandler) HandleUpdatePolicy(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	requestInfo := h.GetRequestInfo(r)
+	if !h.checkIfMatchRequired(r, requestInfo, w) || !h.readRewriteReferences(r, &requestInfo, w) {
+		return
+	}
 	// Decode request
 	request := UpdatePolicyRequest{}
 	err := json.NewDecoder(r.Body).Decode(&request)
@@ -201,7 +292,7 @@ func (h *WorkerHandler) HandleUpdatePolicy(w http.ResponseWriter, r *http.Reques
 	policyName := ps.ByName(POLICY_NAME)
 
 	// Call policy API to update policy
-	response, err := h.worker.PolicyApi.UpdatePolicy(requestInfo, org, policyName, request.Name, request.Path, request.Statements)
+	policy, references, err := h.worker.PolicyApi.UpdatePolicy(requestInfo, org, policyName, request.Name, request.Path, request.Statements)
 
 	// Check errors
 	if err != nil {
@@ -217,6 +308,73 @@ func (h *WorkerHandler) HandleUpdatePolicy(w http.ResponseWriter, r *http.Reques
 			h.RespondBadRequest(r, requestInfo, w, apiError)
 		case api.UNAUTHORIZED_RESOURCES_ERROR:
 			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.PRECONDITION_FAILED_ERROR:
+			h.RespondPreconditionFailed(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Write policy to response
+	response := &UpdatePolicyResponse{
+		Policy:             policy,
+		DanglingReferences: references,
+	}
+	setETagHeader(w, policy)
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandlePatchPolicy(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	if !h.checkIfMatchRequired(r, requestInfo, w) {
+		return
+	}
+	// Read patch document and its type
+	patch, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+	patchType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Retrieve policy, org from path
+	org := ps.ByName(ORG_NAME)
+	policyName := ps.ByName(POLICY_NAME)
+
+	// Call policy API to patch policy
+	response, err := h.worker.PolicyApi.PatchPolicy(requestInfo, org, policyName, patchType, patch)
+
+	// Check errors
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.POLICY_BY_ORG_AND_NAME_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.POLICY_ALREADY_EXIST, api.PATCH_TEST_FAILED:
+			h.RespondConflict(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.PRECONDITION_FAILED_ERROR:
+			h.RespondPreconditionFailed(r, requestInfo, w, apiError)
 		default: // Unexpected API error
 			h.RespondInternalServerError(r, requestInfo, w)
 		}
@@ -224,11 +382,15 @@ func (h *WorkerHandler) HandleUpdatePolicy(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Write policy to response
+	setETagHeader(w, response)
 	h.RespondOk(r, requestInfo, w, response)
 }
 
 func (h *WorkerHandler) HandleRemovePolicy(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	requestInfo := h.GetRequestInfo(r)
+	if !h.checkIfMatchRequired(r, requestInfo, w) {
+		return
+	}
 	// Retrieve org and policy name from request path
 	orgId := ps.ByName(ORG_NAME)
 	policyName := ps.ByName(POLICY_NAME)
@@ -247,6 +409,8 @@ func (h *WorkerHandler) HandleRemovePolicy(w http.ResponseWriter, r *http.Reques
 			h.RespondBadRequest(r, requestInfo, w, apiError)
 		case api.UNAUTHORIZED_RESOURCES_ERROR:
 			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.PRECONDITION_FAILED_ERROR:
+			h.RespondPreconditionFailed(r, requestInfo, w, apiError)
 		default: // Unexpected API error
 			h.RespondInternalServerError(r, requestInfo, w)
 		}
@@ -262,8 +426,17 @@ func (h *WorkerHandler) HandleListAttachedGroups(w http.ResponseWriter, r *http.
 	orgId := ps.ByName(ORG_NAME)
 	policyName := ps.ByName(POLICY_NAME)
 
+	// Retrieve pagination from query params
+	filter, err := getPaginationFilter(r)
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
 	// Call policies API to retrieve attached groups
-	result, err := h.worker.PolicyApi.ListAttachedGroups(requestInfo, orgId, policyName)
+	result, total, err := h.worker.PolicyApi.ListAttachedGroups(requestInfo, orgId, policyName, filter)
 	if err != nil {
 		// Transform to API errors
 		apiError := err.(*api.Error)
@@ -284,6 +457,9 @@ func (h *WorkerHandler) HandleListAttachedGroups(w http.ResponseWriter, r *http.
 	// Create response
 	response := &ListAttachedGroupsResponse{
 		Groups: result,
+		Offset: filter.Offset,
+		Limit:  filter.Limit,
+		Total:  total,
 	}
 
 	// Return groups
@@ -427,6 +427,11 @@ func TestWorkerHandler_HandleGetPolicy(t *testing.T) {
 
 		switch res.StatusCode {
 		case http.StatusOK:
+			// Check entity tag
+			if etag := res.Header.Get(ETAG_HEADER); etag != api.ETag(*test.getPolicyByNameResult) {
+				t.Errorf("Test case %v. Received different entity tag (wanted:%v / received:%v)", n, api.ETag(*test.getPolicyByNameResult), etag)
+				continue
+			}
 			response := api.Policy{}
 			err = json.NewDecoder(res.Body).Decode(&response)
 			if err != nil {
@@ -461,12 +466,15 @@ func TestWorkerHandler_HandleListPolicies(t *testing.T) {
 		// API method args
 		org        string
 		pathPrefix string
+		offset     string
+		limit      string
 		// Expected result
 		expectedStatusCode int
 		expectedResponse   ListPoliciesResponse
 		expectedError      api.Error
 		// API Results
-		getPolicyListResult []api.PolicyIdentity
+		getPolicyListResult []api.Policy
+		getPolicyListTotal  int
 		// API Errors
 		getPolicyListErr error
 	}{
@@ -475,22 +483,54 @@ func TestWorkerHandler_HandleListPolicies(t *testing.T) {
 			pathPrefix:         "path",
 			expectedStatusCode: http.StatusOK,
 			expectedResponse: ListPoliciesResponse{
-				[]string{"policy1"},
+				Policies: []string{"policy1"},
+				Total:    1,
+			},
+			getPolicyListResult: []api.Policy{
+				{
+					Org:  "org1",
+					Name: "policy1",
+				},
+			},
+			getPolicyListTotal: 1,
+		},
+		"OkCasePaging": {
+			org:                "org1",
+			pathPrefix:         "path",
+			offset:             "1",
+			limit:              "1",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: ListPoliciesResponse{
+				Policies: []string{"policy1"},
+				Offset:   1,
+				Limit:    1,
+				Total:    1,
 			},
-			getPolicyListResult: []api.PolicyIdentity{
+			getPolicyListResult: []api.Policy{
 				{
 					Org:  "org1",
 					Name: "policy1",
 				},
 			},
+			getPolicyListTotal: 1,
+		},
+		"ErrorCaseInvalidLimit": {
+			org:                "org1",
+			pathPrefix:         "path",
+			limit:              "a",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Limit a",
+			},
 		},
 		"OkCaseNoOrg": {
 			pathPrefix:         "path",
 			expectedStatusCode: http.StatusOK,
 			expectedResponse: ListPoliciesResponse{
-				[]string{"policy1", "policy2"},
+				Policies: []string{"policy1", "policy2"},
 			},
-			getPolicyListResult: []api.PolicyIdentity{
+			getPolicyListResult: []api.Policy{
 				{
 					Org:  "org1",
 					Name: "policy1",
@@ -541,7 +581,8 @@ func TestWorkerHandler_HandleListPolicies(t *testing.T) {
 	for n, test := range testcases {
 
 		testApi.ArgsOut[ListPoliciesMethod][0] = test.getPolicyListResult
-		testApi.ArgsOut[ListPoliciesMethod][1] = test.getPolicyListErr
+		testApi.ArgsOut[ListPoliciesMethod][1] = test.getPolicyListTotal
+		testApi.ArgsOut[ListPoliciesMethod][2] = test.getPolicyListErr
 
 		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/policies?PathPrefix=%v", test.org, test.pathPrefix)
 		req, err := http.NewRequest(http.MethodGet, url, nil)
@@ -550,24 +591,33 @@ func TestWorkerHandler_HandleListPolicies(t *testing.T) {
 			continue
 		}
 
+		q := req.URL.Query()
 		if test.pathPrefix != "" {
-			q := req.URL.Query()
 			q.Add("PathPrefix", test.pathPrefix)
-			req.URL.RawQuery = q.Encode()
 		}
+		if test.offset != "" {
+			q.Add("Offset", test.offset)
+		}
+		if test.limit != "" {
+			q.Add("Limit", test.limit)
+		}
+		req.URL.RawQuery = q.Encode()
 
 		res, err := client.Do(req)
 		if err != nil {
 			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
 			continue
 		}
-		if testApi.ArgsIn[ListPoliciesMethod][1] != test.org {
-			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[ListPoliciesMethod][1])
-			continue
-		}
-		if testApi.ArgsIn[ListPoliciesMethod][2] != test.pathPrefix {
-			t.Errorf("Test case %v. Received different PathPrefix (wanted:%v / received:%v)", n, test.pathPrefix, testApi.ArgsIn[ListPoliciesMethod][2])
-			continue
+		if test.expectedStatusCode != http.StatusBadRequest {
+			filter := testApi.ArgsIn[ListPoliciesMethod][1].(*api.Filter)
+			if filter.Org != test.org {
+				t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, filter.Org)
+				continue
+			}
+			if filter.PathPrefix != test.pathPrefix {
+				t.Errorf("Test case %v. Received different PathPrefix (wanted:%v / received:%v)", n, test.pathPrefix, filter.PathPrefix)
+				continue
+			}
 		}
 		if test.expectedStatusCode != res.StatusCode {
 			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
@@ -611,12 +661,17 @@ func TestWorkerHandler_HandleListAllPolicies(t *testing.T) {
 	testcases := map[string]struct {
 		// API method args
 		pathPrefix string
+		expand     string
+		offset     string
+		limit      string
 		// Expected result
 		expectedStatusCode int
 		expectedResponse   ListAllPoliciesResponse
+		expectedExpanded   ListPoliciesExpandedResponse
 		expectedError      api.Error
 		// Manager Results
-		getPolicyListResult []api.PolicyIdentity
+		getPolicyListResult []api.Policy
+		getPolicyListTotal  int
 		// Manager Errors
 		getPolicyListErr error
 	}{
@@ -624,7 +679,37 @@ func TestWorkerHandler_HandleListAllPolicies(t *testing.T) {
 			pathPrefix:         "path",
 			expectedStatusCode: http.StatusOK,
 			expectedResponse: ListAllPoliciesResponse{
-				[]api.PolicyIdentity{
+				Policies: []api.PolicyIdentity{
+					{
+						Org:  "org1",
+						Name: "policy1",
+					},
+					{
+						Org:  "org1",
+						Name: "policy2",
+					},
+				},
+				Total: 2,
+			},
+			getPolicyListResult: []api.Policy{
+				{
+					Org:  "org1",
+					Name: "policy1",
+				},
+				{
+					Org:  "org1",
+					Name: "policy2",
+				},
+			},
+			getPolicyListTotal: 2,
+		},
+		"OkCasePaging": {
+			pathPrefix:         "path",
+			offset:             "1",
+			limit:              "1",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: ListAllPoliciesResponse{
+				Policies: []api.PolicyIdentity{
 					{
 						Org:  "org1",
 						Name: "policy1",
@@ -634,8 +719,11 @@ func TestWorkerHandler_HandleListAllPolicies(t *testing.T) {
 						Name: "policy2",
 					},
 				},
+				Offset: 1,
+				Limit:  1,
+				Total:  2,
 			},
-			getPolicyListResult: []api.PolicyIdentity{
+			getPolicyListResult: []api.Policy{
 				{
 					Org:  "org1",
 					Name: "policy1",
@@ -645,6 +733,53 @@ func TestWorkerHandler_HandleListAllPolicies(t *testing.T) {
 					Name: "policy2",
 				},
 			},
+			getPolicyListTotal: 2,
+		},
+		"OkCaseExpand": {
+			pathPrefix:         "path",
+			expand:             "true",
+			expectedStatusCode: http.StatusOK,
+			expectedExpanded: ListPoliciesExpandedResponse{
+				Policies: []api.Policy{
+					{
+						Org:  "org1",
+						Name: "policy1",
+						Path: "path/",
+						Statements: &[]api.Statement{
+							{
+								Effect:    "allow",
+								Actions:   []string{api.USER_ACTION_GET_USER},
+								Resources: []string{api.GetUrnPrefix("", api.RESOURCE_USER, "/path/")},
+							},
+						},
+					},
+				},
+				Total: 1,
+			},
+			getPolicyListResult: []api.Policy{
+				{
+					Org:  "org1",
+					Name: "policy1",
+					Path: "path/",
+					Statements: &[]api.Statement{
+						{
+							Effect:    "allow",
+							Actions:   []string{api.USER_ACTION_GET_USER},
+							Resources: []string{api.GetUrnPrefix("", api.RESOURCE_USER, "/path/")},
+						},
+					},
+				},
+			},
+			getPolicyListTotal: 1,
+		},
+		"ErrorCaseInvalidLimit": {
+			pathPrefix:         "path",
+			limit:              "a",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Limit a",
+			},
 		},
 		"ErrorCaseInvalidParameterError": {
 			pathPrefix:         "path",
@@ -685,7 +820,8 @@ func TestWorkerHandler_HandleListAllPolicies(t *testing.T) {
 	for n, test := range testcases {
 
 		testApi.ArgsOut[ListPoliciesMethod][0] = test.getPolicyListResult
-		testApi.ArgsOut[ListPoliciesMethod][1] = test.getPolicyListErr
+		testApi.ArgsOut[ListPoliciesMethod][1] = test.getPolicyListTotal
+		testApi.ArgsOut[ListPoliciesMethod][2] = test.getPolicyListErr
 
 		url := fmt.Sprintf(server.URL+API_VERSION_1+"/policies?PathPrefix=%v", test.pathPrefix)
 		req, err := http.NewRequest(http.MethodGet, url, nil)
@@ -694,24 +830,36 @@ func TestWorkerHandler_HandleListAllPolicies(t *testing.T) {
 			continue
 		}
 
+		q := req.URL.Query()
 		if test.pathPrefix != "" {
-			q := req.URL.Query()
 			q.Add("PathPrefix", test.pathPrefix)
-			req.URL.RawQuery = q.Encode()
 		}
+		if test.expand != "" {
+			q.Add("Expand", test.expand)
+		}
+		if test.offset != "" {
+			q.Add("Offset", test.offset)
+		}
+		if test.limit != "" {
+			q.Add("Limit", test.limit)
+		}
+		req.URL.RawQuery = q.Encode()
 
 		res, err := client.Do(req)
 		if err != nil {
 			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
 			continue
 		}
-		if testApi.ArgsIn[ListPoliciesMethod][1] != "" {
-			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, "", testApi.ArgsIn[ListPoliciesMethod][1])
-			continue
-		}
-		if testApi.ArgsIn[ListPoliciesMethod][2] != test.pathPrefix {
-			t.Errorf("Test case %v. Received different PathPrefix (wanted:%v / received:%v)", n, test.pathPrefix, testApi.ArgsIn[ListPoliciesMethod][2])
-			continue
+		if test.expectedStatusCode != http.StatusBadRequest {
+			filter := testApi.ArgsIn[ListPoliciesMethod][1].(*api.Filter)
+			if filter.Org != "" {
+				t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, "", filter.Org)
+				continue
+			}
+			if filter.PathPrefix != test.pathPrefix {
+				t.Errorf("Test case %v. Received different PathPrefix (wanted:%v / received:%v)", n, test.pathPrefix, filter.PathPrefix)
+				continue
+			}
 		}
 		if test.expectedStatusCode != res.StatusCode {
 			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
@@ -720,6 +868,20 @@ func TestWorkerHandler_HandleListAllPolicies(t *testing.T) {
 
 		switch res.StatusCode {
 		case http.StatusOK:
+			if test.expand == "true" {
+				listPoliciesExpandedResponse := ListPoliciesExpandedResponse{}
+				err = json.NewDecoder(res.Body).Decode(&listPoliciesExpandedResponse)
+				if err != nil {
+					t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+					continue
+				}
+				// Check result
+				if diff := pretty.Compare(listPoliciesExpandedResponse, test.expectedExpanded); diff != "" {
+					t.Errorf("Test %v failed. Received different responses (received/wanted) %v",
+						n, diff)
+				}
+				continue
+			}
 			listAllPoliciesResponse := ListAllPoliciesResponse{}
 			err = json.NewDecoder(res.Body).Decode(&listAllPoliciesResponse)
 			if err != nil {
@@ -954,7 +1116,7 @@ func TestWorkerHandler_HandleUpdatePolicy(t *testing.T) {
 	for n, test := range testcases {
 
 		testApi.ArgsOut[UpdatePolicyMethod][0] = test.updatePolicyResult
-		testApi.ArgsOut[UpdatePolicyMethod][1] = test.updatePolicyErr
+		testApi.ArgsOut[UpdatePolicyMethod][2] = test.updatePolicyErr
 
 		var body *bytes.Buffer
 		if test.request != nil {
@@ -1044,11 +1206,267 @@ func TestWorkerHandler_HandleUpdatePolicy(t *testing.T) {
 	}
 }
 
+func TestWorkerHandler_HandlePatchPolicy(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		org         string
+		policyName  string
+		contentType string
+		patch       string
+		// Expected result
+		expectedStatusCode int
+		expectedPatchType  string
+		expectedResponse   *api.Policy
+		expectedError      api.Error
+		// Manager Results
+		patchPolicyResult *api.Policy
+		// Manager Errors
+		patchPolicyErr error
+	}{
+		"OkCaseJSONPatch": {
+			org:                "org1",
+			policyName:         "p1",
+			contentType:        api.PATCH_TYPE_JSON_PATCH,
+			patch:              `[{"op": "replace", "path": "/path", "value": "/path2/"}]`,
+			expectedStatusCode: http.StatusOK,
+			expectedPatchType:  api.PATCH_TYPE_JSON_PATCH,
+			expectedResponse: &api.Policy{
+				ID:   "1234",
+				Name: "p1",
+				Path: "/path2/",
+				Org:  "org1",
+			},
+			patchPolicyResult: &api.Policy{
+				ID:   "1234",
+				Name: "p1",
+				Path: "/path2/",
+				Org:  "org1",
+			},
+		},
+		"OkCaseMergePatchWithCharset": {
+			org:                "org1",
+			policyName:         "p1",
+			contentType:        api.PATCH_TYPE_MERGE_PATCH + "; charset=utf-8",
+			patch:              `{"path": "/path2/"}`,
+			expectedStatusCode: http.StatusOK,
+			expectedPatchType:  api.PATCH_TYPE_MERGE_PATCH,
+			expectedResponse: &api.Policy{
+				ID:   "1234",
+				Name: "p1",
+				Path: "/path2/",
+				Org:  "org1",
+			},
+			patchPolicyResult: &api.Policy{
+				ID:   "1234",
+				Name: "p1",
+				Path: "/path2/",
+				Org:  "org1",
+			},
+		},
+		"ErrorCaseInvalidContentType": {
+			org:                "org1",
+			policyName:         "p1",
+			contentType:        "",
+			patch:              `{"path": "/path2/"}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "mime: no media type",
+			},
+		},
+		"ErrorCasePolicyNotFound": {
+			org:               "org1",
+			policyName:        "p1",
+			contentType:       api.PATCH_TYPE_JSON_PATCH,
+			patch:             `[]`,
+			expectedPatchType: api.PATCH_TYPE_JSON_PATCH,
+			patchPolicyErr: &api.Error{
+				Code: api.POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code: api.POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+			},
+		},
+		"ErrorCaseTestFailed": {
+			org:               "org1",
+			policyName:        "p1",
+			contentType:       api.PATCH_TYPE_JSON_PATCH,
+			patch:             `[{"op": "test", "path": "/path", "value": "/other/"}]`,
+			expectedPatchType: api.PATCH_TYPE_JSON_PATCH,
+			patchPolicyErr: &api.Error{
+				Code: api.PATCH_TEST_FAILED,
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedError: api.Error{
+				Code: api.PATCH_TEST_FAILED,
+			},
+		},
+		"ErrorCasePolicyAlreadyExist": {
+			org:               "org1",
+			policyName:        "p1",
+			contentType:       api.PATCH_TYPE_MERGE_PATCH,
+			patch:             `{"name": "p2"}`,
+			expectedPatchType: api.PATCH_TYPE_MERGE_PATCH,
+			patchPolicyErr: &api.Error{
+				Code: api.POLICY_ALREADY_EXIST,
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedError: api.Error{
+				Code: api.POLICY_ALREADY_EXIST,
+			},
+		},
+		"ErrorCaseInvalidParam": {
+			org:               "org1",
+			policyName:        "p1",
+			contentType:       "application/json",
+			patch:             `{"path": "/path2/"}`,
+			expectedPatchType: "application/json",
+			patchPolicyErr: &api.Error{
+				Code: api.INVALID_PARAMETER_ERROR,
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code: api.INVALID_PARAMETER_ERROR,
+			},
+		},
+		"ErrorCaseUnauthorized": {
+			org:               "org1",
+			policyName:        "p1",
+			contentType:       api.PATCH_TYPE_JSON_PATCH,
+			patch:             `[]`,
+			expectedPatchType: api.PATCH_TYPE_JSON_PATCH,
+			patchPolicyErr: &api.Error{
+				Code: api.UNAUTHORIZED_RESOURCES_ERROR,
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code: api.UNAUTHORIZED_RESOURCES_ERROR,
+			},
+		},
+		"ErrorCasePreconditionFailed": {
+			org:               "org1",
+			policyName:        "p1",
+			contentType:       api.PATCH_TYPE_JSON_PATCH,
+			patch:             `[]`,
+			expectedPatchType: api.PATCH_TYPE_JSON_PATCH,
+			patchPolicyErr: &api.Error{
+				Code: api.PRECONDITION_FAILED_ERROR,
+			},
+			expectedStatusCode: http.StatusPreconditionFailed,
+			expectedError: api.Error{
+				Code: api.PRECONDITION_FAILED_ERROR,
+			},
+		},
+		"ErrorCaseInternalServerError": {
+			org:               "org1",
+			policyName:        "p1",
+			contentType:       api.PATCH_TYPE_JSON_PATCH,
+			patch:             `[]`,
+			expectedPatchType: api.PATCH_TYPE_JSON_PATCH,
+			patchPolicyErr: &api.Error{
+				Code: api.UNKNOWN_API_ERROR,
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsIn[PatchPolicyMethod] = make([]interface{}, 5)
+		testApi.ArgsOut[PatchPolicyMethod][0] = test.patchPolicyResult
+		testApi.ArgsOut[PatchPolicyMethod][1] = test.patchPolicyErr
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/policies/%v", test.org, test.policyName)
+		req, err := http.NewRequest(http.MethodPatch, url, bytes.NewBufferString(test.patch))
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+		req.Header.Set("Content-Type", test.contentType)
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters, API isn't called without a valid content type
+		if test.expectedPatchType == "" {
+			if testApi.ArgsIn[PatchPolicyMethod][0] != nil {
+				t.Errorf("Test case %v. Unexpected call to API with invalid content type", n)
+				continue
+			}
+		} else {
+			if testApi.ArgsIn[PatchPolicyMethod][1] != test.org {
+				t.Errorf("Test case %v. Received different org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[PatchPolicyMethod][1])
+				continue
+			}
+			if testApi.ArgsIn[PatchPolicyMethod][2] != test.policyName {
+				t.Errorf("Test case %v. Received different Name (wanted:%v / received:%v)", n, test.policyName, testApi.ArgsIn[PatchPolicyMethod][2])
+				continue
+			}
+			if testApi.ArgsIn[PatchPolicyMethod][3] != test.expectedPatchType {
+				t.Errorf("Test case %v. Received different patch type (wanted:%v / received:%v)", n, test.expectedPatchType, testApi.ArgsIn[PatchPolicyMethod][3])
+				continue
+			}
+			if patch := string(testApi.ArgsIn[PatchPolicyMethod][4].([]byte)); patch != test.patch {
+				t.Errorf("Test case %v. Received different patch (wanted:%v / received:%v)", n, test.patch, patch)
+				continue
+			}
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			// Check entity tag
+			if etag := res.Header.Get(ETAG_HEADER); etag != api.ETag(*test.patchPolicyResult) {
+				t.Errorf("Test case %v. Received different entity tag (wanted:%v / received:%v)", n, api.ETag(*test.patchPolicyResult), etag)
+				continue
+			}
+			response := api.Policy{}
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
 func TestWorkerHandler_HandleRemovePolicy(t *testing.T) {
 	testcases := map[string]struct {
 		// API method args
 		org        string
 		policyName string
+		ifMatch    string
+		// Worker config
+		requireIfMatch bool
 		// Expected result
 		expectedStatusCode int
 		expectedError      api.Error
@@ -1060,6 +1478,35 @@ func TestWorkerHandler_HandleRemovePolicy(t *testing.T) {
 			policyName:         "p1",
 			expectedStatusCode: http.StatusNoContent,
 		},
+		"OkCaseIfMatch": {
+			org:                "org1",
+			policyName:         "p1",
+			ifMatch:            "\"etag\"",
+			requireIfMatch:     true,
+			expectedStatusCode: http.StatusNoContent,
+		},
+		"ErrorCasePreconditionFailed": {
+			org:        "org1",
+			policyName: "p1",
+			ifMatch:    "\"etag\"",
+			deletePolicyErr: &api.Error{
+				Code: api.PRECONDITION_FAILED_ERROR,
+			},
+			expectedStatusCode: http.StatusPreconditionFailed,
+			expectedError: api.Error{
+				Code: api.PRECONDITION_FAILED_ERROR,
+			},
+		},
+		"ErrorCasePreconditionRequired": {
+			org:                "org1",
+			policyName:         "p1",
+			requireIfMatch:     true,
+			expectedStatusCode: http.StatusPreconditionRequired,
+			expectedError: api.Error{
+				Code:    api.PRECONDITION_REQUIRED_ERROR,
+				Message: "Header If-Match is required to modify resources",
+			},
+		},
 		"ErrorCasePolicyNotFound": {
 			org:        "org1",
 			policyName: "p1",
@@ -1109,8 +1556,9 @@ func TestWorkerHandler_HandleRemovePolicy(t *testing.T) {
 	client := http.DefaultClient
 
 	for n, test := range testcases {
-
+		testApi.ArgsIn[RemovePolicyMethod] = make([]interface{}, 3)
 		testApi.ArgsOut[RemovePolicyMethod][0] = test.deletePolicyErr
+		worker.RequireIfMatch = test.requireIfMatch
 
 		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/policies/%v", test.org, test.policyName)
 		req, err := http.NewRequest(http.MethodDelete, url, nil)
@@ -1118,6 +1566,9 @@ func TestWorkerHandler_HandleRemovePolicy(t *testing.T) {
 			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
 			continue
 		}
+		if test.ifMatch != "" {
+			req.Header.Set(IF_MATCH_HEADER, test.ifMatch)
+		}
 
 		res, err := client.Do(req)
 		if err != nil {
@@ -1125,14 +1576,25 @@ func TestWorkerHandler_HandleRemovePolicy(t *testing.T) {
 			continue
 		}
 
-		// Check received parameters
-		if testApi.ArgsIn[RemovePolicyMethod][1] != test.org {
-			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[RemovePolicyMethod][1])
-			continue
-		}
-		if testApi.ArgsIn[RemovePolicyMethod][2] != test.policyName {
-			t.Errorf("Test case %v. Received different Name (wanted:%v / received:%v)", n, test.policyName, testApi.ArgsIn[RemovePolicyMethod][2])
-			continue
+		// Check received parameters, API isn't called without required If-Match
+		if test.expectedStatusCode == http.StatusPreconditionRequired {
+			if testApi.ArgsIn[RemovePolicyMethod][0] != nil {
+				t.Errorf("Test case %v. Unexpected call to API without If-Match header", n)
+				continue
+			}
+		} else {
+			if testApi.ArgsIn[RemovePolicyMethod][0].(api.RequestInfo).IfMatch != test.ifMatch {
+				t.Errorf("Test case %v. Received different If-Match (wanted:%v / received:%v)", n, test.ifMatch, testApi.ArgsIn[RemovePolicyMethod][0].(api.RequestInfo).IfMatch)
+				continue
+			}
+			if testApi.ArgsIn[RemovePolicyMethod][1] != test.org {
+				t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[RemovePolicyMethod][1])
+				continue
+			}
+			if testApi.ArgsIn[RemovePolicyMethod][2] != test.policyName {
+				t.Errorf("Test case %v. Received different Name (wanted:%v / received:%v)", n, test.policyName, testApi.ArgsIn[RemovePolicyMethod][2])
+				continue
+			}
 		}
 
 		// check status code
@@ -1161,6 +1623,7 @@ func TestWorkerHandler_HandleRemovePolicy(t *testing.T) {
 			}
 		}
 	}
+	worker.RequireIfMatch = false
 }
 
 func TestWorkerHandler_HandleListAttachedGroups(t *testing.T) {
@@ -1168,12 +1631,15 @@ func TestWorkerHandler_HandleListAttachedGroups(t *testing.T) {
 		// API method args
 		org        string
 		policyName string
+		offset     string
+		limit      string
 		// Expected result
 		expectedStatusCode int
 		expectedResponse   ListAttachedGroupsResponse
 		expectedError      *api.Error
 		// API Results
 		getPolicyGroupsResult []string
+		getPolicyGroupsTotal  int
 		// API Errors
 		getPolicyGroupsErr error
 	}{
@@ -1183,8 +1649,35 @@ func TestWorkerHandler_HandleListAttachedGroups(t *testing.T) {
 			expectedStatusCode: http.StatusOK,
 			expectedResponse: ListAttachedGroupsResponse{
 				Groups: []string{"group1", "group2"},
+				Total:  2,
+			},
+			getPolicyGroupsResult: []string{"group1", "group2"},
+			getPolicyGroupsTotal:  2,
+		},
+		"OkCasePaging": {
+			org:                "org1",
+			policyName:         "p1",
+			offset:             "1",
+			limit:              "1",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: ListAttachedGroupsResponse{
+				Groups: []string{"group1", "group2"},
+				Offset: 1,
+				Limit:  1,
+				Total:  2,
 			},
 			getPolicyGroupsResult: []string{"group1", "group2"},
+			getPolicyGroupsTotal:  2,
+		},
+		"ErrorCaseInvalidLimit": {
+			org:                "org1",
+			policyName:         "p1",
+			limit:              "a",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Limit a",
+			},
 		},
 		"ErrorCaseNotFound": {
 			org:                "org1",
@@ -1237,7 +1730,8 @@ func TestWorkerHandler_HandleListAttachedGroups(t *testing.T) {
 	for n, test := range testcases {
 
 		testApi.ArgsOut[ListAttachedGroupsMethod][0] = test.getPolicyGroupsResult
-		testApi.ArgsOut[ListAttachedGroupsMethod][1] = test.getPolicyGroupsErr
+		testApi.ArgsOut[ListAttachedGroupsMethod][1] = test.getPolicyGroupsTotal
+		testApi.ArgsOut[ListAttachedGroupsMethod][2] = test.getPolicyGroupsErr
 
 		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/policies/%v/groups", test.org, test.policyName)
 		req, err := http.NewRequest(http.MethodGet, url, nil)
@@ -1246,6 +1740,15 @@ func TestWorkerHandler_HandleListAttachedGroups(t *testing.T) {
 			continue
 		}
 
+		q := req.URL.Query()
+		if test.offset != "" {
+			q.Add("Offset", test.offset)
+		}
+		if test.limit != "" {
+			q.Add("Limit", test.limit)
+		}
+		req.URL.RawQuery = q.Encode()
+
 		res, err := client.Do(req)
 		if err != nil {
 			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
@@ -1253,13 +1756,15 @@ func TestWorkerHandler_HandleListAttachedGroups(t *testing.T) {
 		}
 
 		// Check received parameters
-		if testApi.ArgsIn[ListAttachedGroupsMethod][1] != test.org {
-			t.Errorf("Test case %v. Received different org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[ListAttachedGroupsMethod][1])
-			continue
-		}
-		if testApi.ArgsIn[ListAttachedGroupsMethod][2] != test.policyName {
-			t.Errorf("Test case %v. Received different Name (wanted:%v / received:%v)", n, test.policyName, testApi.ArgsIn[ListAttachedGroupsMethod][2])
-			continue
+		if test.expectedStatusCode != http.StatusBadRequest {
+			if testApi.ArgsIn[ListAttachedGroupsMethod][1] != test.org {
+				t.Errorf("Test case %v. Received different org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[ListAttachedGroupsMethod][1])
+				continue
+			}
+			if testApi.ArgsIn[ListAttachedGroupsMethod][2] != test.policyName {
+				t.Errorf("Test case %v. Received different Name (wanted:%v / received:%v)", n, test.policyName, testApi.ArgsIn[ListAttachedGroupsMethod][2])
+				continue
+			}
 		}
 
 		// check status code
@@ -129,6 +129,9 @@ func (h *ProxyHandler) checkAuthorization(r *http.Request, urn string, action st
 		return workerRequestID, getErrorMessage(FORBIDDEN_ERROR, "Unauthenticated user")
 	case http.StatusForbidden:
 		return workerRequestID, getErrorMessage(FORBIDDEN_ERROR, fmt.Sprintf("Restricted access to urn %v", urn))
+	case http.StatusBadRequest:
+		// Worker rejects urns that don't conform to any registered resource type
+		return workerRequestID, getErrorMessage(api.INVALID_PARAMETER_ERROR, fmt.Sprintf("Invalid urn %v", urn))
 	case http.StatusOK:
 		authzResponse := AuthorizeResourcesResponse{}
 		err = json.NewDecoder(res.Body).Decode(&authzResponse)
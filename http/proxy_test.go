@@ -110,6 +110,18 @@ func TestProxyHandler_HandleRequest(t *testing.T) {
 				Code: api.UNKNOWN_API_ERROR,
 			},
 		},
+		"ErrorCaseUnregisteredResourceType": {
+			expectedStatusCode: http.StatusBadRequest,
+			resource:           USER_ROOT_URL + "/user",
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Bad request",
+			},
+			getAuthorizedExternalResourcesErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Unknown resource: urn:ews:example:instance1:resource/user - It doesn't conform to any registered resource type",
+			},
+		},
 		"ErrorCaseNotAllowed": {
 			expectedStatusCode: http.StatusForbidden,
 			resource:           USER_ROOT_URL + "/user",
@@ -0,0 +1,159 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/tecsisa/foulkon/api"
+)
+
+// REQUESTS
+
+type CreateResourceTypeRequest struct {
+	Name        string `json:"name, omitempty"`
+	Description string `json:"description, omitempty"`
+	UrnTemplate string `json:"urnTemplate, omitempty"`
+}
+
+// RESPONSES
+
+type ListResourceTypesResponse struct {
+	ResourceTypes []api.ResourceType `json:"resourceTypes, omitempty"`
+}
+
+// HANDLERS
+
+func (h *WorkerHandler) HandleAddResourceType(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Decode request
+	request := CreateResourceTypeRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	namespace := ps.ByName(NAMESPACE_NAME)
+	// Call resource type API to create a resource type
+	response, err := h.worker.ResourceTypeApi.AddResourceType(requestInfo, namespace, request.Name, request.Description,
+		request.UrnTemplate)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.NAMESPACE_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.RESOURCE_TYPE_ALREADY_EXIST:
+			h.RespondConflict(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Write resource type to response
+	h.RespondCreated(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleGetResourceType(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve namespace and resource type name from path
+	namespace := ps.ByName(NAMESPACE_NAME)
+	name := ps.ByName(RESOURCE_TYPE_NAME)
+
+	// Call resource type API to retrieve resource type
+	response, err := h.worker.ResourceTypeApi.GetResourceType(requestInfo, namespace, name)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.RESOURCE_TYPE_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Write resource type to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleListResourceTypes(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve namespace from path
+	namespace := ps.ByName(NAMESPACE_NAME)
+
+	// Call resource type API to retrieve resource types
+	result, err := h.worker.ResourceTypeApi.ListResourceTypes(requestInfo, namespace)
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.NAMESPACE_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Create response
+	response := &ListResourceTypesResponse{
+		ResourceTypes: result,
+	}
+
+	// Return data
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleRemoveResourceType(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve namespace and resource type name from path
+	namespace := ps.ByName(NAMESPACE_NAME)
+	name := ps.ByName(RESOURCE_TYPE_NAME)
+
+	// Call resource type API to delete resource type
+	err := h.worker.ResourceTypeApi.RemoveResourceType(requestInfo, namespace, name)
+
+	// Check if there were errors
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.RESOURCE_TYPE_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	h.RespondNoContent(r, requestInfo, w)
+}
@@ -0,0 +1,202 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+)
+
+func TestWorkerHandler_HandleAddResourceType(t *testing.T) {
+	now := time.Date(2016, 9, 1, 10, 0, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		// API method args
+		namespace string
+		request   *CreateResourceTypeRequest
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.ResourceType
+		expectedError      api.Error
+		// Manager Results
+		addResourceTypeResult *api.ResourceType
+		// Manager Errors
+		addResourceTypeErr error
+	}{
+		"OkCase": {
+			namespace: "billing",
+			request: &CreateResourceTypeRequest{
+				Name:        "invoice",
+				Description: "Billing invoice",
+				UrnTemplate: "urn:billing:{org}:invoice{path}{id}",
+			},
+			expectedStatusCode: http.StatusCreated,
+			expectedResponse: &api.ResourceType{
+				ID:          "RT-ID",
+				Namespace:   "billing",
+				Name:        "invoice",
+				Description: "Billing invoice",
+				UrnTemplate: "urn:billing:{org}:invoice{path}{id}",
+				CreateAt:    now,
+			},
+			addResourceTypeResult: &api.ResourceType{
+				ID:          "RT-ID",
+				Namespace:   "billing",
+				Name:        "invoice",
+				Description: "Billing invoice",
+				UrnTemplate: "urn:billing:{org}:invoice{path}{id}",
+				CreateAt:    now,
+			},
+		},
+		"ErrorCaseMalformedRequest": {
+			namespace:          "billing",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseNamespaceNotFound": {
+			namespace: "billing",
+			request: &CreateResourceTypeRequest{
+				Name: "invoice",
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.NAMESPACE_NOT_FOUND,
+				Message: "Namespace not found",
+			},
+			addResourceTypeErr: &api.Error{
+				Code:    api.NAMESPACE_NOT_FOUND,
+				Message: "Namespace not found",
+			},
+		},
+		"ErrorCaseResourceTypeAlreadyExist": {
+			namespace: "billing",
+			request: &CreateResourceTypeRequest{
+				Name: "invoice",
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedError: api.Error{
+				Code:    api.RESOURCE_TYPE_ALREADY_EXIST,
+				Message: "Resource type already exist",
+			},
+			addResourceTypeErr: &api.Error{
+				Code:    api.RESOURCE_TYPE_ALREADY_EXIST,
+				Message: "Resource type already exist",
+			},
+		},
+		"ErrorCaseNotAdmin": {
+			namespace: "billing",
+			request: &CreateResourceTypeRequest{
+				Name: "invoice",
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			addResourceTypeErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			namespace: "billing",
+			request: &CreateResourceTypeRequest{
+				Name: "invoice",
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			addResourceTypeErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[AddResourceTypeMethod][0] = test.addResourceTypeResult
+		testApi.ArgsOut[AddResourceTypeMethod][1] = test.addResourceTypeErr
+
+		body := bytes.NewBuffer([]byte{})
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/namespaces/%v/resource-types", test.namespace)
+		req, err := http.NewRequest(http.MethodPost, url, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		if test.request != nil {
+			// Check received parameters
+			if testApi.ArgsIn[AddResourceTypeMethod][1] != test.namespace {
+				t.Errorf("Test case %v. Received different Namespace (wanted:%v / received:%v)", n, test.namespace, testApi.ArgsIn[AddResourceTypeMethod][1])
+				continue
+			}
+			if testApi.ArgsIn[AddResourceTypeMethod][2] != test.request.Name {
+				t.Errorf("Test case %v. Received different Name (wanted:%v / received:%v)", n, test.request.Name, testApi.ArgsIn[AddResourceTypeMethod][2])
+				continue
+			}
+			if testApi.ArgsIn[AddResourceTypeMethod][4] != test.request.UrnTemplate {
+				t.Errorf("Test case %v. Received different UrnTemplate (wanted:%v / received:%v)", n, test.request.UrnTemplate, testApi.ArgsIn[AddResourceTypeMethod][4])
+				continue
+			}
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusCreated:
+			response := api.ResourceType{}
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/tecsisa/foulkon/api"
+)
+
+// HANDLERS
+
+func (h *WorkerHandler) HandleGetPolicyStatement(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve org, policy name and statement id from request path
+	org := ps.ByName(ORG_NAME)
+	policyName := ps.ByName(POLICY_NAME)
+	sid := ps.ByName(STATEMENT_ID)
+
+	// Call policies API to retrieve statement
+	response, err := h.worker.PolicyApi.GetPolicyStatement(requestInfo, org, policyName, sid)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.POLICY_BY_ORG_AND_NAME_NOT_FOUND, api.STATEMENT_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Write statement to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleAddPolicyStatement(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	if !h.checkIfMatchRequired(r, requestInfo, w) {
+		return
+	}
+	// Retrieve org and policy name from request path
+	org := ps.ByName(ORG_NAME)
+	policyName := ps.ByName(POLICY_NAME)
+
+	// Decode statement
+	request := api.Statement{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call policies API to add statement
+	response, err := h.worker.PolicyApi.AddPolicyStatement(requestInfo, org, policyName, request)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.POLICY_BY_ORG_AND_NAME_NOT_FOUND, api.STATEMENT_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.STATEMENT_ALREADY_EXIST:
+			h.RespondConflict(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.PRECONDITION_FAILED_ERROR:
+			h.RespondPreconditionFailed(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Write statement to response
+	h.RespondCreated(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleUpdatePolicyStatement(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	if !h.checkIfMatchRequired(r, requestInfo, w) {
+		return
+	}
+	// Retrieve org, policy name and statement id from request path
+	org := ps.ByName(ORG_NAME)
+	policyName := ps.ByName(POLICY_NAME)
+	sid := ps.ByName(STATEMENT_ID)
+
+	// Decode statement
+	request := api.Statement{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call policies API to update statement
+	response, err := h.worker.PolicyApi.UpdatePolicyStatement(requestInfo, org, policyName, sid, request)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.POLICY_BY_ORG_AND_NAME_NOT_FOUND, api.STATEMENT_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.PRECONDITION_FAILED_ERROR:
+			h.RespondPreconditionFailed(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Write statement to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleRemovePolicyStatement(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	if !h.checkIfMatchRequired(r, requestInfo, w) {
+		return
+	}
+	// Retrieve org, policy name and statement id from request path
+	org := ps.ByName(ORG_NAME)
+	policyName := ps.ByName(POLICY_NAME)
+	sid := ps.ByName(STATEMENT_ID)
+
+	// Call policies API to remove statement
+	err := h.worker.PolicyApi.RemovePolicyStatement(requestInfo, org, policyName, sid)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.POLICY_BY_ORG_AND_NAME_NOT_FOUND, api.STATEMENT_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.PRECONDITION_FAILED_ERROR:
+			h.RespondPreconditionFailed(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	h.RespondNoContent(r, requestInfo, w)
+}
@@ -0,0 +1,686 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+)
+
+func TestWorkerHandler_HandleGetPolicyStatement(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		org        string
+		policyName string
+		sid        string
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.Statement
+		expectedError      api.Error
+		// Manager Results
+		getPolicyStatementResult *api.Statement
+		// Manager Errors
+		getPolicyStatementErr error
+	}{
+		"OkCase": {
+			org:                "org1",
+			policyName:         "p1",
+			sid:                "first",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.Statement{
+				Sid:       "first",
+				Effect:    "allow",
+				Actions:   []string{api.USER_ACTION_GET_USER},
+				Resources: []string{api.GetUrnPrefix("", api.RESOURCE_USER, "/path/")},
+			},
+			getPolicyStatementResult: &api.Statement{
+				Sid:       "first",
+				Effect:    "allow",
+				Actions:   []string{api.USER_ACTION_GET_USER},
+				Resources: []string{api.GetUrnPrefix("", api.RESOURCE_USER, "/path/")},
+			},
+		},
+		"ErrorCaseStatementNotFound": {
+			org:        "org1",
+			policyName: "p1",
+			sid:        "first",
+			getPolicyStatementErr: &api.Error{
+				Code: api.STATEMENT_NOT_FOUND,
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code: api.STATEMENT_NOT_FOUND,
+			},
+		},
+		"ErrorCasePolicyNotFound": {
+			org:        "org1",
+			policyName: "p1",
+			sid:        "first",
+			getPolicyStatementErr: &api.Error{
+				Code: api.POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code: api.POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+			},
+		},
+		"ErrorCaseInvalidParam": {
+			org:        "org1",
+			policyName: "p1",
+			sid:        "first",
+			getPolicyStatementErr: &api.Error{
+				Code: api.INVALID_PARAMETER_ERROR,
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code: api.INVALID_PARAMETER_ERROR,
+			},
+		},
+		"ErrorCaseUnauthorized": {
+			org:        "org1",
+			policyName: "p1",
+			sid:        "first",
+			getPolicyStatementErr: &api.Error{
+				Code: api.UNAUTHORIZED_RESOURCES_ERROR,
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code: api.UNAUTHORIZED_RESOURCES_ERROR,
+			},
+		},
+		"ErrorCaseInternalServerError": {
+			org:        "org1",
+			policyName: "p1",
+			sid:        "first",
+			getPolicyStatementErr: &api.Error{
+				Code: api.UNKNOWN_API_ERROR,
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsOut[GetPolicyStatementMethod][0] = test.getPolicyStatementResult
+		testApi.ArgsOut[GetPolicyStatementMethod][1] = test.getPolicyStatementErr
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/policies/%v/statements/%v", test.org, test.policyName, test.sid)
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		received := []interface{}{testApi.ArgsIn[GetPolicyStatementMethod][1], testApi.ArgsIn[GetPolicyStatementMethod][2],
+			testApi.ArgsIn[GetPolicyStatementMethod][3]}
+		if diff := pretty.Compare(received, []interface{}{test.org, test.policyName, test.sid}); diff != "" {
+			t.Errorf("Test case %v. Received different parameters (received/wanted) %v", n, diff)
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			response := api.Statement{}
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleAddPolicyStatement(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		org        string
+		policyName string
+		request    *api.Statement
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.Statement
+		expectedError      api.Error
+		// Manager Results
+		addPolicyStatementResult *api.Statement
+		// Manager Errors
+		addPolicyStatementErr error
+	}{
+		"OkCase": {
+			org:        "org1",
+			policyName: "p1",
+			request: &api.Statement{
+				Effect:    "allow",
+				Actions:   []string{api.USER_ACTION_GET_USER},
+				Resources: []string{api.GetUrnPrefix("", api.RESOURCE_USER, "/path/")},
+			},
+			expectedStatusCode: http.StatusCreated,
+			expectedResponse: &api.Statement{
+				Sid:       "generated",
+				Effect:    "allow",
+				Actions:   []string{api.USER_ACTION_GET_USER},
+				Resources: []string{api.GetUrnPrefix("", api.RESOURCE_USER, "/path/")},
+			},
+			addPolicyStatementResult: &api.Statement{
+				Sid:       "generated",
+				Effect:    "allow",
+				Actions:   []string{api.USER_ACTION_GET_USER},
+				Resources: []string{api.GetUrnPrefix("", api.RESOURCE_USER, "/path/")},
+			},
+		},
+		"ErrorCaseMalformedRequest": {
+			org:                "org1",
+			policyName:         "p1",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseStatementAlreadyExist": {
+			org:        "org1",
+			policyName: "p1",
+			request: &api.Statement{
+				Sid: "first",
+			},
+			addPolicyStatementErr: &api.Error{
+				Code: api.STATEMENT_ALREADY_EXIST,
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedError: api.Error{
+				Code: api.STATEMENT_ALREADY_EXIST,
+			},
+		},
+		"ErrorCasePolicyNotFound": {
+			org:        "org1",
+			policyName: "p1",
+			request:    &api.Statement{},
+			addPolicyStatementErr: &api.Error{
+				Code: api.POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code: api.POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+			},
+		},
+		"ErrorCaseInvalidParam": {
+			org:        "org1",
+			policyName: "p1",
+			request:    &api.Statement{},
+			addPolicyStatementErr: &api.Error{
+				Code: api.INVALID_PARAMETER_ERROR,
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code: api.INVALID_PARAMETER_ERROR,
+			},
+		},
+		"ErrorCaseUnauthorized": {
+			org:        "org1",
+			policyName: "p1",
+			request:    &api.Statement{},
+			addPolicyStatementErr: &api.Error{
+				Code: api.UNAUTHORIZED_RESOURCES_ERROR,
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code: api.UNAUTHORIZED_RESOURCES_ERROR,
+			},
+		},
+		"ErrorCasePreconditionFailed": {
+			org:        "org1",
+			policyName: "p1",
+			request:    &api.Statement{},
+			addPolicyStatementErr: &api.Error{
+				Code: api.PRECONDITION_FAILED_ERROR,
+			},
+			expectedStatusCode: http.StatusPreconditionFailed,
+			expectedError: api.Error{
+				Code: api.PRECONDITION_FAILED_ERROR,
+			},
+		},
+		"ErrorCaseInternalServerError": {
+			org:        "org1",
+			policyName: "p1",
+			request:    &api.Statement{},
+			addPolicyStatementErr: &api.Error{
+				Code: api.UNKNOWN_API_ERROR,
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsIn[AddPolicyStatementMethod] = make([]interface{}, 4)
+		testApi.ArgsOut[AddPolicyStatementMethod][0] = test.addPolicyStatementResult
+		testApi.ArgsOut[AddPolicyStatementMethod][1] = test.addPolicyStatementErr
+
+		var body *bytes.Buffer
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+		if body == nil {
+			body = bytes.NewBuffer([]byte{})
+		}
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/policies/%v/statements", test.org, test.policyName)
+		req, err := http.NewRequest(http.MethodPost, url, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if test.request != nil {
+			received := []interface{}{testApi.ArgsIn[AddPolicyStatementMethod][1], testApi.ArgsIn[AddPolicyStatementMethod][2],
+				testApi.ArgsIn[AddPolicyStatementMethod][3]}
+			if diff := pretty.Compare(received, []interface{}{test.org, test.policyName, *test.request}); diff != "" {
+				t.Errorf("Test case %v. Received different parameters (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusCreated:
+			response := api.Statement{}
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleUpdatePolicyStatement(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		org        string
+		policyName string
+		sid        string
+		request    *api.Statement
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.Statement
+		expectedError      api.Error
+		// Manager Results
+		updatePolicyStatementResult *api.Statement
+		// Manager Errors
+		updatePolicyStatementErr error
+	}{
+		"OkCase": {
+			org:        "org1",
+			policyName: "p1",
+			sid:        "first",
+			request: &api.Statement{
+				Effect:    "deny",
+				Actions:   []string{api.USER_ACTION_GET_USER},
+				Resources: []string{api.GetUrnPrefix("", api.RESOURCE_USER, "/path/")},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.Statement{
+				Sid:       "first",
+				Effect:    "deny",
+				Actions:   []string{api.USER_ACTION_GET_USER},
+				Resources: []string{api.GetUrnPrefix("", api.RESOURCE_USER, "/path/")},
+			},
+			updatePolicyStatementResult: &api.Statement{
+				Sid:       "first",
+				Effect:    "deny",
+				Actions:   []string{api.USER_ACTION_GET_USER},
+				Resources: []string{api.GetUrnPrefix("", api.RESOURCE_USER, "/path/")},
+			},
+		},
+		"ErrorCaseMalformedRequest": {
+			org:                "org1",
+			policyName:         "p1",
+			sid:                "first",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseStatementNotFound": {
+			org:        "org1",
+			policyName: "p1",
+			sid:        "first",
+			request:    &api.Statement{},
+			updatePolicyStatementErr: &api.Error{
+				Code: api.STATEMENT_NOT_FOUND,
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code: api.STATEMENT_NOT_FOUND,
+			},
+		},
+		"ErrorCaseInvalidParam": {
+			org:        "org1",
+			policyName: "p1",
+			sid:        "first",
+			request:    &api.Statement{},
+			updatePolicyStatementErr: &api.Error{
+				Code: api.INVALID_PARAMETER_ERROR,
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code: api.INVALID_PARAMETER_ERROR,
+			},
+		},
+		"ErrorCaseUnauthorized": {
+			org:        "org1",
+			policyName: "p1",
+			sid:        "first",
+			request:    &api.Statement{},
+			updatePolicyStatementErr: &api.Error{
+				Code: api.UNAUTHORIZED_RESOURCES_ERROR,
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code: api.UNAUTHORIZED_RESOURCES_ERROR,
+			},
+		},
+		"ErrorCasePreconditionFailed": {
+			org:        "org1",
+			policyName: "p1",
+			sid:        "first",
+			request:    &api.Statement{},
+			updatePolicyStatementErr: &api.Error{
+				Code: api.PRECONDITION_FAILED_ERROR,
+			},
+			expectedStatusCode: http.StatusPreconditionFailed,
+			expectedError: api.Error{
+				Code: api.PRECONDITION_FAILED_ERROR,
+			},
+		},
+		"ErrorCaseInternalServerError": {
+			org:        "org1",
+			policyName: "p1",
+			sid:        "first",
+			request:    &api.Statement{},
+			updatePolicyStatementErr: &api.Error{
+				Code: api.UNKNOWN_API_ERROR,
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsIn[UpdatePolicyStatementMethod] = make([]interface{}, 5)
+		testApi.ArgsOut[UpdatePolicyStatementMethod][0] = test.updatePolicyStatementResult
+		testApi.ArgsOut[UpdatePolicyStatementMethod][1] = test.updatePolicyStatementErr
+
+		var body *bytes.Buffer
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+		if body == nil {
+			body = bytes.NewBuffer([]byte{})
+		}
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/policies/%v/statements/%v", test.org, test.policyName, test.sid)
+		req, err := http.NewRequest(http.MethodPut, url, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if test.request != nil {
+			received := []interface{}{testApi.ArgsIn[UpdatePolicyStatementMethod][1], testApi.ArgsIn[UpdatePolicyStatementMethod][2],
+				testApi.ArgsIn[UpdatePolicyStatementMethod][3], testApi.ArgsIn[UpdatePolicyStatementMethod][4]}
+			if diff := pretty.Compare(received, []interface{}{test.org, test.policyName, test.sid, *test.request}); diff != "" {
+				t.Errorf("Test case %v. Received different parameters (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			response := api.Statement{}
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleRemovePolicyStatement(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		org        string
+		policyName string
+		sid        string
+		// Expected result
+		expectedStatusCode int
+		expectedError      api.Error
+		// Manager Errors
+		removePolicyStatementErr error
+	}{
+		"OkCase": {
+			org:                "org1",
+			policyName:         "p1",
+			sid:                "first",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		"ErrorCaseStatementNotFound": {
+			org:        "org1",
+			policyName: "p1",
+			sid:        "first",
+			removePolicyStatementErr: &api.Error{
+				Code: api.STATEMENT_NOT_FOUND,
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code: api.STATEMENT_NOT_FOUND,
+			},
+		},
+		"ErrorCaseInvalidParam": {
+			org:        "org1",
+			policyName: "p1",
+			sid:        "first",
+			removePolicyStatementErr: &api.Error{
+				Code: api.INVALID_PARAMETER_ERROR,
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code: api.INVALID_PARAMETER_ERROR,
+			},
+		},
+		"ErrorCaseUnauthorized": {
+			org:        "org1",
+			policyName: "p1",
+			sid:        "first",
+			removePolicyStatementErr: &api.Error{
+				Code: api.UNAUTHORIZED_RESOURCES_ERROR,
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code: api.UNAUTHORIZED_RESOURCES_ERROR,
+			},
+		},
+		"ErrorCasePreconditionFailed": {
+			org:        "org1",
+			policyName: "p1",
+			sid:        "first",
+			removePolicyStatementErr: &api.Error{
+				Code: api.PRECONDITION_FAILED_ERROR,
+			},
+			expectedStatusCode: http.StatusPreconditionFailed,
+			expectedError: api.Error{
+				Code: api.PRECONDITION_FAILED_ERROR,
+			},
+		},
+		"ErrorCaseInternalServerError": {
+			org:        "org1",
+			policyName: "p1",
+			sid:        "first",
+			removePolicyStatementErr: &api.Error{
+				Code: api.UNKNOWN_API_ERROR,
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsOut[RemovePolicyStatementMethod][0] = test.removePolicyStatementErr
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/policies/%v/statements/%v", test.org, test.policyName, test.sid)
+		req, err := http.NewRequest(http.MethodDelete, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		received := []interface{}{testApi.ArgsIn[RemovePolicyStatementMethod][1], testApi.ArgsIn[RemovePolicyStatementMethod][2],
+			testApi.ArgsIn[RemovePolicyStatementMethod][3]}
+		if diff := pretty.Compare(received, []interface{}{test.org, test.policyName, test.sid}); diff != "" {
+			t.Errorf("Test case %v. Received different parameters (received/wanted) %v", n, diff)
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusNoContent, http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
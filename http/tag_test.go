@@ -0,0 +1,579 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+)
+
+func TestWorkerHandler_HandleListUserTags(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		externalID string
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   TagsResponse
+		expectedError      api.Error
+		// Manager Results
+		listUserTagsResult map[string]string
+		// Manager Errors
+		listUserTagsErr error
+	}{
+		"OkCase": {
+			externalID:         "UserID",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: TagsResponse{
+				Tags: map[string]string{"team": "payments"},
+			},
+			listUserTagsResult: map[string]string{"team": "payments"},
+		},
+		"ErrorCaseUserNotFound": {
+			externalID:         "UserID",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not found",
+			},
+			listUserTagsErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not found",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			externalID:         "UserID",
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			listUserTagsErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			externalID:         "UserID",
+			expectedStatusCode: http.StatusInternalServerError,
+			listUserTagsErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[ListUserTagsMethod][0] = test.listUserTagsResult
+		testApi.ArgsOut[ListUserTagsMethod][1] = test.listUserTagsErr
+
+		url := fmt.Sprintf(server.URL+USER_ROOT_URL+"/%v/tags", test.externalID)
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameter
+		if testApi.ArgsIn[ListUserTagsMethod][1] != test.externalID {
+			t.Errorf("Test case %v. Received different ExternalID (wanted:%v / received:%v)", n, test.externalID, testApi.ArgsIn[ListUserTagsMethod][1])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			response := TagsResponse{}
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleSetUserTag(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		externalID string
+		key        string
+		request    *SetTagRequest
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   TagsResponse
+		expectedError      api.Error
+		// Manager Results
+		setUserTagResult map[string]string
+		// Manager Errors
+		setUserTagErr error
+	}{
+		"OkCase": {
+			externalID: "UserID",
+			key:        "team",
+			request: &SetTagRequest{
+				Value: "payments",
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: TagsResponse{
+				Tags: map[string]string{"team": "payments", "env": "prod"},
+			},
+			setUserTagResult: map[string]string{"team": "payments", "env": "prod"},
+		},
+		"ErrorCaseMalformedRequest": {
+			externalID:         "UserID",
+			key:                "team",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			externalID: "UserID",
+			key:        "team",
+			request: &SetTagRequest{
+				Value: "pay ments",
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: tag value pay ments",
+			},
+			setUserTagErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: tag value pay ments",
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			externalID: "UserID",
+			key:        "team",
+			request: &SetTagRequest{
+				Value: "payments",
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not found",
+			},
+			setUserTagErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not found",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			externalID: "UserID",
+			key:        "team",
+			request: &SetTagRequest{
+				Value: "payments",
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			setUserTagErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			externalID: "UserID",
+			key:        "team",
+			request: &SetTagRequest{
+				Value: "payments",
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			setUserTagErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[SetUserTagMethod][0] = test.setUserTagResult
+		testApi.ArgsOut[SetUserTagMethod][1] = test.setUserTagErr
+
+		body := bytes.NewBuffer([]byte{})
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+
+		url := fmt.Sprintf(server.URL+USER_ROOT_URL+"/%v/tags/%v", test.externalID, test.key)
+		req, err := http.NewRequest(http.MethodPut, url, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		if test.request != nil {
+			// Check received parameters
+			if testApi.ArgsIn[SetUserTagMethod][1] != test.externalID {
+				t.Errorf("Test case %v. Received different ExternalID (wanted:%v / received:%v)", n, test.externalID, testApi.ArgsIn[SetUserTagMethod][1])
+				continue
+			}
+			if testApi.ArgsIn[SetUserTagMethod][2] != test.key {
+				t.Errorf("Test case %v. Received different Key (wanted:%v / received:%v)", n, test.key, testApi.ArgsIn[SetUserTagMethod][2])
+				continue
+			}
+			if testApi.ArgsIn[SetUserTagMethod][3] != test.request.Value {
+				t.Errorf("Test case %v. Received different Value (wanted:%v / received:%v)", n, test.request.Value, testApi.ArgsIn[SetUserTagMethod][3])
+				continue
+			}
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			response := TagsResponse{}
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleRemoveGroupTag(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		org  string
+		name string
+		key  string
+		// Expected result
+		expectedStatusCode int
+		expectedError      api.Error
+		// Manager Errors
+		removeGroupTagErr error
+	}{
+		"OkCase": {
+			org:                "org1",
+			name:               "group1",
+			key:                "team",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		"ErrorCaseGroupNotFound": {
+			org:                "org1",
+			name:               "group1",
+			key:                "team",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group not found",
+			},
+			removeGroupTagErr: &api.Error{
+				Code:    api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Group not found",
+			},
+		},
+		"ErrorCaseTagNotFound": {
+			org:                "org1",
+			name:               "group1",
+			key:                "team",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.TAG_NOT_FOUND,
+				Message: "Tag not found",
+			},
+			removeGroupTagErr: &api.Error{
+				Code:    api.TAG_NOT_FOUND,
+				Message: "Tag not found",
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			org:                "org1",
+			name:               "group1",
+			key:                "team",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+			removeGroupTagErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			org:                "org1",
+			name:               "group1",
+			key:                "team",
+			expectedStatusCode: http.StatusInternalServerError,
+			removeGroupTagErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[RemoveGroupTagMethod][0] = test.removeGroupTagErr
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/groups/%v/tags/%v", test.org, test.name, test.key)
+		req, err := http.NewRequest(http.MethodDelete, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[RemoveGroupTagMethod][1] != test.org {
+			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[RemoveGroupTagMethod][1])
+			continue
+		}
+		if testApi.ArgsIn[RemoveGroupTagMethod][2] != test.name {
+			t.Errorf("Test case %v. Received different Name (wanted:%v / received:%v)", n, test.name, testApi.ArgsIn[RemoveGroupTagMethod][2])
+			continue
+		}
+		if testApi.ArgsIn[RemoveGroupTagMethod][3] != test.key {
+			t.Errorf("Test case %v. Received different Key (wanted:%v / received:%v)", n, test.key, testApi.ArgsIn[RemoveGroupTagMethod][3])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusNoContent, http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleSetPolicyTag(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		org     string
+		name    string
+		key     string
+		request *SetTagRequest
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   TagsResponse
+		expectedError      api.Error
+		// Manager Results
+		setPolicyTagResult map[string]string
+		// Manager Errors
+		setPolicyTagErr error
+	}{
+		"OkCase": {
+			org:  "org1",
+			name: "policy1",
+			key:  "env",
+			request: &SetTagRequest{
+				Value: "prod",
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: TagsResponse{
+				Tags: map[string]string{"env": "prod"},
+			},
+			setPolicyTagResult: map[string]string{"env": "prod"},
+		},
+		"ErrorCasePolicyNotFound": {
+			org:  "org1",
+			name: "policy1",
+			key:  "env",
+			request: &SetTagRequest{
+				Value: "prod",
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Policy not found",
+			},
+			setPolicyTagErr: &api.Error{
+				Code:    api.POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Policy not found",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			org:  "org1",
+			name: "policy1",
+			key:  "env",
+			request: &SetTagRequest{
+				Value: "prod",
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			setPolicyTagErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[SetPolicyTagMethod][0] = test.setPolicyTagResult
+		testApi.ArgsOut[SetPolicyTagMethod][1] = test.setPolicyTagErr
+
+		jsonObject, err := json.Marshal(test.request)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+			continue
+		}
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/policies/%v/tags/%v", test.org, test.name, test.key)
+		req, err := http.NewRequest(http.MethodPut, url, bytes.NewBuffer(jsonObject))
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[SetPolicyTagMethod][1] != test.org {
+			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[SetPolicyTagMethod][1])
+			continue
+		}
+		if testApi.ArgsIn[SetPolicyTagMethod][2] != test.name {
+			t.Errorf("Test case %v. Received different Name (wanted:%v / received:%v)", n, test.name, testApi.ArgsIn[SetPolicyTagMethod][2])
+			continue
+		}
+		if testApi.ArgsIn[SetPolicyTagMethod][4] != test.request.Value {
+			t.Errorf("Test case %v. Received different Value (wanted:%v / received:%v)", n, test.request.Value, testApi.ArgsIn[SetPolicyTagMethod][4])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			response := TagsResponse{}
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
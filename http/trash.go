@@ -0,0 +1,120 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/tecsisa/foulkon/api"
+)
+
+// RESPONSES
+
+type ListDeletedResourcesResponse struct {
+	DeletedResources []api.DeletedResource `json:"deletedResources, omitempty"`
+	Offset           int                   `json:"offset, omitempty"`
+	Limit            int                   `json:"limit, omitempty"`
+	Total            int                   `json:"total, omitempty"`
+}
+
+// HANDLERS
+
+func (h *WorkerHandler) HandleListDeletedResources(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve filter from query params
+	filter, err := getPaginationFilter(r)
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+	filter.Org = r.URL.Query().Get("Org")
+	filter.Name = r.URL.Query().Get("Name")
+	resourceType := r.URL.Query().Get("Type")
+
+	// Call trash API to retrieve deleted resources
+	result, total, err := h.worker.TrashApi.ListDeletedResources(requestInfo, resourceType, filter)
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Create response
+	response := &ListDeletedResourcesResponse{
+		DeletedResources: result,
+		Offset:           filter.Offset,
+		Limit:            filter.Limit,
+		Total:            total,
+	}
+
+	// Return data
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleGetDeletedResource(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve deleted resource id from path
+	id := ps.ByName(DELETED_RESOURCE_ID)
+
+	// Call trash API to retrieve deleted resource
+	response, err := h.worker.TrashApi.GetDeletedResource(requestInfo, id)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.DELETED_RESOURCE_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Write deleted resource to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleRestoreDeletedResource(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve deleted resource id from path
+	id := ps.ByName(DELETED_RESOURCE_ID)
+
+	// Call trash API to restore deleted resource
+	response, err := h.worker.TrashApi.RestoreDeletedResource(requestInfo, id)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.DELETED_RESOURCE_NOT_FOUND, api.ORGANIZATION_BY_NAME_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.USER_ALREADY_EXIST, api.GROUP_ALREADY_EXIST, api.POLICY_ALREADY_EXIST:
+			h.RespondConflict(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Write restored resource to response
+	h.RespondOk(r, requestInfo, w, response)
+}
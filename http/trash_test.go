@@ -0,0 +1,426 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+)
+
+func TestWorkerHandler_HandleListDeletedResources(t *testing.T) {
+	now := time.Date(2016, time.November, 21, 10, 0, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		// API method args
+		resourceType string
+		filter       *api.Filter
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   ListDeletedResourcesResponse
+		expectedError      api.Error
+		// Manager Results
+		listDeletedResourcesResult []api.DeletedResource
+		totalResult                int
+		// Manager Errors
+		listDeletedResourcesErr error
+	}{
+		"OkCase": {
+			resourceType: api.RESOURCE_GROUP,
+			filter: &api.Filter{
+				Org:    "org1",
+				Name:   "group",
+				Offset: 0,
+				Limit:  10,
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: ListDeletedResourcesResponse{
+				DeletedResources: []api.DeletedResource{
+					{
+						ID:        "GROUP-ID",
+						Type:      api.RESOURCE_GROUP,
+						Org:       "org1",
+						Name:      "group",
+						Urn:       api.CreateUrn("org1", api.RESOURCE_GROUP, "/path/", "group"),
+						DeletedAt: now,
+						PurgeAt:   now.Add(time.Hour),
+					},
+				},
+				Offset: 0,
+				Limit:  10,
+				Total:  1,
+			},
+			listDeletedResourcesResult: []api.DeletedResource{
+				{
+					ID:        "GROUP-ID",
+					Type:      api.RESOURCE_GROUP,
+					Org:       "org1",
+					Name:      "group",
+					Urn:       api.CreateUrn("org1", api.RESOURCE_GROUP, "/path/", "group"),
+					DeletedAt: now,
+					PurgeAt:   now.Add(time.Hour),
+				},
+			},
+			totalResult: 1,
+		},
+		"ErrorCaseInvalidParameter": {
+			resourceType: "invalid",
+			filter:       &api.Filter{},
+			listDeletedResourcesErr: &api.Error{
+				Code: api.INVALID_PARAMETER_ERROR,
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code: api.INVALID_PARAMETER_ERROR,
+			},
+		},
+		"ErrorCaseUnauthorized": {
+			filter: &api.Filter{},
+			listDeletedResourcesErr: &api.Error{
+				Code: api.UNAUTHORIZED_RESOURCES_ERROR,
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code: api.UNAUTHORIZED_RESOURCES_ERROR,
+			},
+		},
+		"ErrorCaseInternalServerError": {
+			filter: &api.Filter{},
+			listDeletedResourcesErr: &api.Error{
+				Code: api.UNKNOWN_API_ERROR,
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsOut[ListDeletedResourcesMethod][0] = test.listDeletedResourcesResult
+		testApi.ArgsOut[ListDeletedResourcesMethod][1] = test.totalResult
+		testApi.ArgsOut[ListDeletedResourcesMethod][2] = test.listDeletedResourcesErr
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+TRASH_ROOT_URL, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+		q := req.URL.Query()
+		q.Add("Type", test.resourceType)
+		q.Add("Org", test.filter.Org)
+		q.Add("Name", test.filter.Name)
+		q.Add("Offset", fmt.Sprintf("%v", test.filter.Offset))
+		q.Add("Limit", fmt.Sprintf("%v", test.filter.Limit))
+		req.URL.RawQuery = q.Encode()
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		received := []interface{}{testApi.ArgsIn[ListDeletedResourcesMethod][1], testApi.ArgsIn[ListDeletedResourcesMethod][2]}
+		if diff := pretty.Compare(received, []interface{}{test.resourceType, test.filter}); diff != "" {
+			t.Errorf("Test case %v. Received different parameters (received/wanted) %v", n, diff)
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			response := ListDeletedResourcesResponse{}
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleGetDeletedResource(t *testing.T) {
+	now := time.Date(2016, time.November, 21, 10, 0, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		// API method args
+		id string
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.DeletedResource
+		expectedError      api.Error
+		// Manager Results
+		getDeletedResourceResult *api.DeletedResource
+		// Manager Errors
+		getDeletedResourceErr error
+	}{
+		"OkCase": {
+			id:                 "USER-ID",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.DeletedResource{
+				ID:        "USER-ID",
+				Type:      api.RESOURCE_USER,
+				Name:      "user",
+				Urn:       api.CreateUrn("", api.RESOURCE_USER, "/path/", "user"),
+				DeletedAt: now,
+				PurgeAt:   now.Add(time.Hour),
+			},
+			getDeletedResourceResult: &api.DeletedResource{
+				ID:        "USER-ID",
+				Type:      api.RESOURCE_USER,
+				Name:      "user",
+				Urn:       api.CreateUrn("", api.RESOURCE_USER, "/path/", "user"),
+				DeletedAt: now,
+				PurgeAt:   now.Add(time.Hour),
+			},
+		},
+		"ErrorCaseDeletedResourceNotFound": {
+			id: "USER-ID",
+			getDeletedResourceErr: &api.Error{
+				Code: api.DELETED_RESOURCE_NOT_FOUND,
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code: api.DELETED_RESOURCE_NOT_FOUND,
+			},
+		},
+		"ErrorCaseUnauthorized": {
+			id: "USER-ID",
+			getDeletedResourceErr: &api.Error{
+				Code: api.UNAUTHORIZED_RESOURCES_ERROR,
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code: api.UNAUTHORIZED_RESOURCES_ERROR,
+			},
+		},
+		"ErrorCaseInternalServerError": {
+			id: "USER-ID",
+			getDeletedResourceErr: &api.Error{
+				Code: api.UNKNOWN_API_ERROR,
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsOut[GetDeletedResourceMethod][0] = test.getDeletedResourceResult
+		testApi.ArgsOut[GetDeletedResourceMethod][1] = test.getDeletedResourceErr
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+TRASH_ROOT_URL+"/"+test.id, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[GetDeletedResourceMethod][1] != test.id {
+			t.Errorf("Test case %v. Received different id (wanted:%v / received:%v)", n, test.id, testApi.ArgsIn[GetDeletedResourceMethod][1])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			response := &api.DeletedResource{}
+			err = json.NewDecoder(res.Body).Decode(response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleRestoreDeletedResource(t *testing.T) {
+	now := time.Date(2016, time.November, 21, 10, 0, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		// API method args
+		id string
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.DeletedResource
+		expectedError      api.Error
+		// Manager Results
+		restoreDeletedResourceResult *api.DeletedResource
+		// Manager Errors
+		restoreDeletedResourceErr error
+	}{
+		"OkCase": {
+			id:                 "POLICY-ID",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.DeletedResource{
+				ID:        "POLICY-ID",
+				Type:      api.RESOURCE_POLICY,
+				Org:       "org1",
+				Name:      "policy",
+				Urn:       api.CreateUrn("org1", api.RESOURCE_POLICY, "/path/", "policy"),
+				DeletedAt: now,
+				PurgeAt:   now.Add(time.Hour),
+			},
+			restoreDeletedResourceResult: &api.DeletedResource{
+				ID:        "POLICY-ID",
+				Type:      api.RESOURCE_POLICY,
+				Org:       "org1",
+				Name:      "policy",
+				Urn:       api.CreateUrn("org1", api.RESOURCE_POLICY, "/path/", "policy"),
+				DeletedAt: now,
+				PurgeAt:   now.Add(time.Hour),
+			},
+		},
+		"ErrorCaseDeletedResourceNotFound": {
+			id: "POLICY-ID",
+			restoreDeletedResourceErr: &api.Error{
+				Code: api.DELETED_RESOURCE_NOT_FOUND,
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code: api.DELETED_RESOURCE_NOT_FOUND,
+			},
+		},
+		"ErrorCasePolicyAlreadyExist": {
+			id: "POLICY-ID",
+			restoreDeletedResourceErr: &api.Error{
+				Code: api.POLICY_ALREADY_EXIST,
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedError: api.Error{
+				Code: api.POLICY_ALREADY_EXIST,
+			},
+		},
+		"ErrorCaseUnauthorized": {
+			id: "POLICY-ID",
+			restoreDeletedResourceErr: &api.Error{
+				Code: api.UNAUTHORIZED_RESOURCES_ERROR,
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code: api.UNAUTHORIZED_RESOURCES_ERROR,
+			},
+		},
+		"ErrorCaseInternalServerError": {
+			id: "POLICY-ID",
+			restoreDeletedResourceErr: &api.Error{
+				Code: api.UNKNOWN_API_ERROR,
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsOut[RestoreDeletedResourceMethod][0] = test.restoreDeletedResourceResult
+		testApi.ArgsOut[RestoreDeletedResourceMethod][1] = test.restoreDeletedResourceErr
+
+		req, err := http.NewRequest(http.MethodPost, server.URL+TRASH_ROOT_URL+"/"+test.id+"/restore", nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[RestoreDeletedResourceMethod][1] != test.id {
+			t.Errorf("Test case %v. Received different id (wanted:%v / received:%v)", n, test.id, testApi.ArgsIn[RestoreDeletedResourceMethod][1])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			response := &api.DeletedResource{}
+			err = json.NewDecoder(res.Body).Decode(response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
@@ -11,22 +11,53 @@ import (
 // REQUESTS
 
 type CreateUserRequest struct {
-	ExternalID string `json:"externalId, omitempty"`
-	Path       string `json:"path, omitempty"`
+	ExternalID  string            `json:"externalId, omitempty"`
+	Path        string            `json:"path, omitempty"`
+	DisplayName string            `json:"displayName, omitempty"`
+	Email       string            `json:"email, omitempty"`
+	Attributes  map[string]string `json:"attributes, omitempty"`
 }
 
+// Profile fields that aren't sent keep their current value
 type UpdateUserRequest struct {
-	Path string `json:"path, omitempty"`
+	Path        string            `json:"path, omitempty"`
+	DisplayName *string           `json:"displayName, omitempty"`
+	Email       *string           `json:"email, omitempty"`
+	Attributes  map[string]string `json:"attributes, omitempty"`
+}
+
+type RenameUserRequest struct {
+	ExternalID      string `json:"externalId, omitempty"`
+	RewritePolicies bool   `json:"rewritePolicies, omitempty"`
 }
 
 // RESPONSES
 
+// Updated user with the policy statements that still reference its old URN
+type UpdateUserResponse struct {
+	*api.User
+	DanglingReferences []api.StatementReference `json:"danglingReferences, omitempty"`
+}
+
 type GetUserExternalIDsResponse struct {
 	ExternalIDs []string `json:"users, omitempty"`
+	Offset      int      `json:"offset, omitempty"`
+	Limit       int      `json:"limit, omitempty"`
+	Total       int      `json:"total, omitempty"`
+}
+
+type ListUsersExpandedResponse struct {
+	Users  []api.User `json:"users, omitempty"`
+	Offset int        `json:"offset, omitempty"`
+	Limit  int        `json:"limit, omitempty"`
+	Total  int        `json:"total, omitempty"`
 }
 
 type GetGroupsByUserIdResponse struct {
 	Groups []api.GroupIdentity `json:"groups, omitempty"`
+	Offset int                 `json:"offset, omitempty"`
+	Limit  int                 `json:"limit, omitempty"`
+	Total  int                 `json:"total, omitempty"`
 }
 
 // HANDLERS
@@ -47,7 +78,11 @@ func (h *WorkerHandler) HandleAddUser(w http.ResponseWriter, r *http.Request, _
 	}
 
 	// Call user API to create an user
-	response, err := h.worker.UserApi.AddUser(requestInfo, request.ExternalID, request.Path)
+	response, err := h.worker.UserApi.AddUser(requestInfo, request.ExternalID, request.Path, api.UserProfile{
+		DisplayName: request.DisplayName,
+		Email:       request.Email,
+		Attributes:  request.Attributes,
+	})
 
 	// Error handling
 	if err != nil {
@@ -91,6 +126,8 @@ func (h *WorkerHandler) HandleGetUserByExternalID(w http.ResponseWriter, r *http
 			h.RespondForbidden(r, requestInfo, w, apiError)
 		case api.INVALID_PARAMETER_ERROR:
 			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.PRECONDITION_FAILED_ERROR:
+			h.RespondPreconditionFailed(r, requestInfo, w, apiError)
 		default: // Unexpected API error
 			h.RespondInternalServerError(r, requestInfo, w)
 		}
@@ -98,15 +135,29 @@ func (h *WorkerHandler) HandleGetUserByExternalID(w http.ResponseWriter, r *http
 	}
 
 	// Write user to response
+	setETagHeader(w, response)
 	h.RespondOk(r, requestInfo, w, response)
 }
 
 func (h *WorkerHandler) HandleListUsers(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	requestInfo := h.GetRequestInfo(r)
-	// Retrieve PathPrefix
-	pathPrefix := r.URL.Query().Get("PathPrefix")
+	// Retrieve filter from query params
+	filter, err := getListFilter(r, "")
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+	expand, err := getBoolQueryParam(r, "Expand")
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
 	// Call user API
-	result, err := h.worker.UserApi.ListUsers(requestInfo, pathPrefix)
+	result, total, err := h.worker.UserApi.ListUsers(requestInfo, filter)
 	if err != nil {
 		// Transform to API errors
 		apiError := err.(*api.Error)
@@ -114,15 +165,35 @@ func (h *WorkerHandler) HandleListUsers(w http.ResponseWriter, r *http.Request,
 		switch apiError.Code {
 		case api.UNAUTHORIZED_RESOURCES_ERROR:
 			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
 		default: // Unexpected API error
 			h.RespondInternalServerError(r, requestInfo, w)
 		}
 		return
 	}
 
+	// Return full users if requested
+	if expand {
+		h.RespondOk(r, requestInfo, w, &ListUsersExpandedResponse{
+			Users:  result,
+			Offset: filter.Offset,
+			Limit:  filter.Limit,
+			Total:  total,
+		})
+		return
+	}
+
 	// Create response
+	externalIDs := []string{}
+	for _, user := range result {
+		externalIDs = append(externalIDs, user.ExternalID)
+	}
 	response := &GetUserExternalIDsResponse{
-		ExternalIDs: result,
+		ExternalIDs: externalIDs,
+		Offset:      filter.Offset,
+		Limit:       filter.Limit,
+		Total:       total,
 	}
 
 	// Return users
@@ -131,6 +202,9 @@ func (h *WorkerHandler) HandleListUsers(w http.ResponseWriter, r *http.Request,
 
 func (h *WorkerHandler) HandleUpdateUser(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	requestInfo := h.GetRequestInfo(r)
+	if !h.checkIfMatchRequired(r, requestInfo, w) || !h.readRewriteReferences(r, &requestInfo, w) {
+		return
+	}
 	// Decode request
 	request := UpdateUserRequest{}
 	err := json.NewDecoder(r.Body).Decode(&request)
@@ -148,7 +222,11 @@ func (h *WorkerHandler) HandleUpdateUser(w http.ResponseWriter, r *http.Request,
 	id := ps.ByName(USER_ID)
 
 	// Call user API to update user
-	response, err := h.worker.UserApi.UpdateUser(requestInfo, id, request.Path)
+	user, references, err := h.worker.UserApi.UpdateUser(requestInfo, id, request.Path, api.UserProfileUpdate{
+		DisplayName: request.DisplayName,
+		Email:       request.Email,
+		Attributes:  request.Attributes,
+	})
 
 	// Error handling
 	if err != nil {
@@ -160,6 +238,8 @@ func (h *WorkerHandler) HandleUpdateUser(w http.ResponseWriter, r *http.Request,
 			h.RespondNotFound(r, requestInfo, w, apiError)
 		case api.UNAUTHORIZED_RESOURCES_ERROR:
 			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.PRECONDITION_FAILED_ERROR:
+			h.RespondPreconditionFailed(r, requestInfo, w, apiError)
 		case api.INVALID_PARAMETER_ERROR:
 			h.RespondBadRequest(r, requestInfo, w, apiError)
 		default: // Unexpected API error
@@ -169,11 +249,90 @@ func (h *WorkerHandler) HandleUpdateUser(w http.ResponseWriter, r *http.Request,
 	}
 
 	// Write user to response
+	response := &UpdateUserResponse{
+		User:               user,
+		DanglingReferences: references,
+	}
+	setETagHeader(w, user)
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleSuspendUser(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve user id from path
+	id := ps.ByName(USER_ID)
+
+	// Call user API to suspend user
+	response, err := h.worker.UserApi.SuspendUser(requestInfo, id)
+	h.respondUserStatusUpdated(w, r, requestInfo, response, err)
+}
+
+func (h *WorkerHandler) HandleReactivateUser(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve user id from path
+	id := ps.ByName(USER_ID)
+
+	// Call user API to reactivate user
+	response, err := h.worker.UserApi.ReactivateUser(requestInfo, id)
+	h.respondUserStatusUpdated(w, r, requestInfo, response, err)
+}
+
+func (h *WorkerHandler) HandleRenameUser(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	if !h.checkIfMatchRequired(r, requestInfo, w) {
+		return
+	}
+	// Decode request
+	request := RenameUserRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Retrieve user id from path
+	id := ps.ByName(USER_ID)
+
+	// Call user API to change user externalId
+	response, err := h.worker.UserApi.RenameUser(requestInfo, id, request.ExternalID, request.RewritePolicies)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.USER_BY_EXTERNAL_ID_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.USER_ALREADY_EXIST:
+			h.RespondConflict(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.PRECONDITION_FAILED_ERROR:
+			h.RespondPreconditionFailed(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Write renamed user and policy references to response
+	setETagHeader(w, response.User)
 	h.RespondOk(r, requestInfo, w, response)
 }
 
 func (h *WorkerHandler) HandleRemoveUser(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	requestInfo := h.GetRequestInfo(r)
+	if !h.checkIfMatchRequired(r, requestInfo, w) {
+		return
+	}
 	// Retrieve user id from path
 	id := ps.ByName(USER_ID)
 
@@ -189,6 +348,8 @@ func (h *WorkerHandler) HandleRemoveUser(w http.ResponseWriter, r *http.Request,
 			h.RespondNotFound(r, requestInfo, w, apiError)
 		case api.UNAUTHORIZED_RESOURCES_ERROR:
 			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.PRECONDITION_FAILED_ERROR:
+			h.RespondPreconditionFailed(r, requestInfo, w, apiError)
 		case api.INVALID_PARAMETER_ERROR:
 			h.RespondBadRequest(r, requestInfo, w, apiError)
 		default: // Unexpected API error
@@ -205,7 +366,16 @@ func (h *WorkerHandler) HandleListGroupsByUser(w http.ResponseWriter, r *http.Re
 	// Retrieve users using path
 	id := ps.ByName(USER_ID)
 
-	result, err := h.worker.UserApi.ListGroupsByUser(requestInfo, id)
+	// Retrieve pagination from query params
+	filter, err := getPaginationFilter(r)
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	result, total, err := h.worker.UserApi.ListGroupsByUser(requestInfo, id, filter)
 
 	if err != nil {
 		// Transform to API errors
@@ -226,8 +396,38 @@ func (h *WorkerHandler) HandleListGroupsByUser(w http.ResponseWriter, r *http.Re
 
 	response := GetGroupsByUserIdResponse{
 		Groups: result,
+		Offset: filter.Offset,
+		Limit:  filter.Limit,
+		Total:  total,
+	}
+
+	// Write user to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+// Write the user returned by a status update or its error
+func (h *WorkerHandler) respondUserStatusUpdated(w http.ResponseWriter, r *http.Request, requestInfo api.RequestInfo, response *api.User, err error) {
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.USER_BY_EXTERNAL_ID_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.PRECONDITION_FAILED_ERROR:
+			h.RespondPreconditionFailed(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
 	}
 
 	// Write user to response
+	setETagHeader(w, response)
 	h.RespondOk(r, requestInfo, w, response)
 }
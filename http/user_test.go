@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"net/http"
+	"strings"
 	"testing"
 
 	"time"
@@ -30,8 +31,13 @@ func TestWorkerHandler_HandleAddUser(t *testing.T) {
 	}{
 		"OkCase": {
 			request: &CreateUserRequest{
-				ExternalID: "UserID",
-				Path:       "Path",
+				ExternalID:  "UserID",
+				Path:        "Path",
+				DisplayName: "User Name",
+				Email:       "user@example.com",
+				Attributes: map[string]string{
+					"department": "sales",
+				},
 			},
 			expectedStatusCode: http.StatusCreated,
 			expectedResponse: &api.User{
@@ -157,6 +163,15 @@ func TestWorkerHandler_HandleAddUser(t *testing.T) {
 				t.Errorf("Test case %v. Received different Path (wanted:%v / received:%v)", n, test.request.Path, testApi.ArgsIn[AddUserMethod][2])
 				continue
 			}
+			profile := api.UserProfile{
+				DisplayName: test.request.DisplayName,
+				Email:       test.request.Email,
+				Attributes:  test.request.Attributes,
+			}
+			if diff := pretty.Compare(testApi.ArgsIn[AddUserMethod][3], profile); diff != "" {
+				t.Errorf("Test %v failed. Received different profile (received/wanted) %v", n, diff)
+				continue
+			}
 		}
 
 		// check status code
@@ -345,12 +360,23 @@ func TestWorkerHandler_HandleListUsers(t *testing.T) {
 	testcases := map[string]struct {
 		// API method args
 		pathPrefix string
+		tags       []string
+		name       string
+		after      string
+		orphan     string
+		sort       string
+		order      string
+		expand     string
+		offset     string
+		limit      string
 		// Expected result
 		expectedStatusCode int
 		expectedResponse   GetUserExternalIDsResponse
+		expectedExpanded   ListUsersExpandedResponse
 		expectedError      api.Error
 		// Manager Results
-		getUserListResult []string
+		getUserListResult []api.User
+		getUserListTotal  int
 		// Manager Errors
 		getUserListErr error
 	}{
@@ -359,8 +385,108 @@ func TestWorkerHandler_HandleListUsers(t *testing.T) {
 			expectedStatusCode: http.StatusOK,
 			expectedResponse: GetUserExternalIDsResponse{
 				ExternalIDs: []string{"userId1", "userId2"},
+				Total:       2,
+			},
+			getUserListResult: []api.User{{ExternalID: "userId1"}, {ExternalID: "userId2"}},
+			getUserListTotal:  2,
+		},
+		"OkCasePaging": {
+			pathPrefix:         "myPath",
+			offset:             "1",
+			limit:              "1",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: GetUserExternalIDsResponse{
+				ExternalIDs: []string{"userId1", "userId2"},
+				Offset:      1,
+				Limit:       1,
+				Total:       2,
+			},
+			getUserListResult: []api.User{{ExternalID: "userId1"}, {ExternalID: "userId2"}},
+			getUserListTotal:  2,
+		},
+		"ErrorCaseInvalidLimit": {
+			pathPrefix:         "myPath",
+			limit:              "a",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Limit a",
+			},
+		},
+		"OkCaseFilterByTags": {
+			pathPrefix:         "myPath",
+			tags:               []string{"team:payments", "env:prod"},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: GetUserExternalIDsResponse{
+				ExternalIDs: []string{"userId1"},
+			},
+			getUserListResult: []api.User{{ExternalID: "userId1"}},
+		},
+		"ErrorCaseInvalidTagFilter": {
+			pathPrefix:         "myPath",
+			tags:               []string{"team"},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Tag team",
+			},
+		},
+		"OkCaseFilterAndSort": {
+			pathPrefix:         "myPath",
+			name:               "user",
+			after:              "2016-01-02T15:04:05Z",
+			orphan:             "true",
+			sort:               api.SORT_BY_CREATE_AT,
+			order:              api.ORDER_DESC,
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: GetUserExternalIDsResponse{
+				ExternalIDs: []string{"userId1"},
+				Total:       1,
+			},
+			getUserListResult: []api.User{{ExternalID: "userId1"}},
+			getUserListTotal:  1,
+		},
+		"OkCaseExpand": {
+			pathPrefix:         "myPath",
+			expand:             "true",
+			expectedStatusCode: http.StatusOK,
+			expectedExpanded: ListUsersExpandedResponse{
+				Users: []api.User{
+					{ExternalID: "userId1", Path: "myPath/", Urn: "urn:iws:iam::user/myPath/userId1"},
+				},
+				Total: 1,
+			},
+			getUserListResult: []api.User{
+				{ExternalID: "userId1", Path: "myPath/", Urn: "urn:iws:iam::user/myPath/userId1"},
+			},
+			getUserListTotal: 1,
+		},
+		"ErrorCaseInvalidExpand": {
+			pathPrefix:         "myPath",
+			expand:             "all",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Expand all",
+			},
+		},
+		"ErrorCaseInvalidCreatedAfter": {
+			pathPrefix:         "myPath",
+			after:              "yesterday",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: CreatedAfter yesterday",
+			},
+		},
+		"ErrorCaseInvalidOrphan": {
+			pathPrefix:         "myPath",
+			orphan:             "maybe",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Orphan maybe",
 			},
-			getUserListResult: []string{"userId1", "userId2"},
 		},
 		"ErrorCaseUnauthorizedError": {
 			pathPrefix:         "myPath",
@@ -388,7 +514,8 @@ func TestWorkerHandler_HandleListUsers(t *testing.T) {
 	for n, test := range testcases {
 
 		testApi.ArgsOut[ListUsersMethod][0] = test.getUserListResult
-		testApi.ArgsOut[ListUsersMethod][1] = test.getUserListErr
+		testApi.ArgsOut[ListUsersMethod][1] = test.getUserListTotal
+		testApi.ArgsOut[ListUsersMethod][2] = test.getUserListErr
 
 		url := fmt.Sprintf(server.URL + USER_ROOT_URL)
 		req, err := http.NewRequest(http.MethodGet, url, nil)
@@ -397,11 +524,38 @@ func TestWorkerHandler_HandleListUsers(t *testing.T) {
 			continue
 		}
 
+		q := req.URL.Query()
 		if test.pathPrefix != "" {
-			q := req.URL.Query()
 			q.Add("PathPrefix", test.pathPrefix)
-			req.URL.RawQuery = q.Encode()
 		}
+		for _, tag := range test.tags {
+			q.Add("Tag", tag)
+		}
+		if test.name != "" {
+			q.Add("Name", test.name)
+		}
+		if test.after != "" {
+			q.Add("CreatedAfter", test.after)
+		}
+		if test.orphan != "" {
+			q.Add("Orphan", test.orphan)
+		}
+		if test.sort != "" {
+			q.Add("Sort", test.sort)
+		}
+		if test.order != "" {
+			q.Add("Order", test.order)
+		}
+		if test.expand != "" {
+			q.Add("Expand", test.expand)
+		}
+		if test.offset != "" {
+			q.Add("Offset", test.offset)
+		}
+		if test.limit != "" {
+			q.Add("Limit", test.limit)
+		}
+		req.URL.RawQuery = q.Encode()
 
 		res, err := client.Do(req)
 		if err != nil {
@@ -409,10 +563,38 @@ func TestWorkerHandler_HandleListUsers(t *testing.T) {
 			continue
 		}
 
-		// Check received parameter
-		if testApi.ArgsIn[ListUsersMethod][1] != test.pathPrefix {
-			t.Errorf("Test case %v. Received different PathPrefix (wanted:%v / received:%v)", n, test.pathPrefix, testApi.ArgsIn[ListUsersMethod][1])
-			continue
+		// Check received parameters
+		if test.expectedStatusCode != http.StatusBadRequest {
+			filter := testApi.ArgsIn[ListUsersMethod][1].(*api.Filter)
+			if filter.PathPrefix != test.pathPrefix {
+				t.Errorf("Test case %v. Received different PathPrefix (wanted:%v / received:%v)", n, test.pathPrefix, filter.PathPrefix)
+				continue
+			}
+			expectedTags := map[string]string{}
+			for _, tag := range test.tags {
+				keyValue := strings.SplitN(tag, ":", 2)
+				expectedTags[keyValue[0]] = keyValue[1]
+			}
+			if diff := pretty.Compare(filter.Tags, expectedTags); diff != "" {
+				t.Errorf("Test case %v. Received different tags (received/wanted) %v", n, diff)
+				continue
+			}
+			if filter.Name != test.name {
+				t.Errorf("Test case %v. Received different Name (wanted:%v / received:%v)", n, test.name, filter.Name)
+				continue
+			}
+			if test.after != "" && (filter.CreatedAfter == nil || filter.CreatedAfter.Format(time.RFC3339) != test.after) {
+				t.Errorf("Test case %v. Received different CreatedAfter (wanted:%v / received:%v)", n, test.after, filter.CreatedAfter)
+				continue
+			}
+			if filter.Orphan != (test.orphan == "true") {
+				t.Errorf("Test case %v. Received different Orphan (wanted:%v / received:%v)", n, test.orphan, filter.Orphan)
+				continue
+			}
+			if filter.SortBy != test.sort || filter.Order != test.order {
+				t.Errorf("Test case %v. Received different sorting (wanted:%v %v / received:%v %v)", n, test.sort, test.order, filter.SortBy, filter.Order)
+				continue
+			}
 		}
 
 		// check status code
@@ -423,6 +605,20 @@ func TestWorkerHandler_HandleListUsers(t *testing.T) {
 
 		switch res.StatusCode {
 		case http.StatusOK:
+			if test.expand == "true" {
+				listUsersExpandedResponse := ListUsersExpandedResponse{}
+				err = json.NewDecoder(res.Body).Decode(&listUsersExpandedResponse)
+				if err != nil {
+					t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+					continue
+				}
+				// Check result
+				if diff := pretty.Compare(listUsersExpandedResponse, test.expectedExpanded); diff != "" {
+					t.Errorf("Test %v failed. Received different responses (received/wanted) %v",
+						n, diff)
+				}
+				continue
+			}
 			getUserExternalIDsResponse := GetUserExternalIDsResponse{}
 			err = json.NewDecoder(res.Body).Decode(&getUserExternalIDsResponse)
 			if err != nil {
@@ -537,6 +733,20 @@ func TestWorkerHandler_HandleUpdateUser(t *testing.T) {
 				Message: "Unauthorized",
 			},
 		},
+		"ErrorCasePreconditionFailed": {
+			request: &UpdateUserRequest{
+				Path: "NewPath",
+			},
+			expectedStatusCode: http.StatusPreconditionFailed,
+			expectedError: api.Error{
+				Code:    api.PRECONDITION_FAILED_ERROR,
+				Message: "Resource urn has been modified since it was retrieved",
+			},
+			updateUserErr: &api.Error{
+				Code:    api.PRECONDITION_FAILED_ERROR,
+				Message: "Resource urn has been modified since it was retrieved",
+			},
+		},
 		"ErrorCaseUnknownApiError": {
 			request: &UpdateUserRequest{
 				Path: "NewPath",
@@ -554,7 +764,7 @@ func TestWorkerHandler_HandleUpdateUser(t *testing.T) {
 	for n, test := range testcases {
 
 		testApi.ArgsOut[UpdateUserMethod][0] = test.updateUserResult
-		testApi.ArgsOut[UpdateUserMethod][1] = test.updateUserErr
+		testApi.ArgsOut[UpdateUserMethod][2] = test.updateUserErr
 
 		var body *bytes.Buffer
 		if test.request != nil {
@@ -592,6 +802,15 @@ func TestWorkerHandler_HandleUpdateUser(t *testing.T) {
 				t.Errorf("Test case %v. Received different Path (wanted:%v / received:%v)", n, test.request.Path, testApi.ArgsIn[UpdateUserMethod][2])
 				continue
 			}
+			profileUpdate := api.UserProfileUpdate{
+				DisplayName: test.request.DisplayName,
+				Email:       test.request.Email,
+				Attributes:  test.request.Attributes,
+			}
+			if diff := pretty.Compare(testApi.ArgsIn[UpdateUserMethod][3], profileUpdate); diff != "" {
+				t.Errorf("Test case %v. Received different profile update (received/wanted) %v", n, diff)
+				continue
+			}
 		}
 
 		// check status code
@@ -633,60 +852,127 @@ func TestWorkerHandler_HandleUpdateUser(t *testing.T) {
 	}
 }
 
-func TestWorkerHandler_HandleRemoveUser(t *testing.T) {
+func TestWorkerHandler_HandleRenameUser(t *testing.T) {
 	testcases := map[string]struct {
 		// API method args
-		externalID string
+		request *RenameUserRequest
 		// Expected result
 		expectedStatusCode int
+		expectedResponse   *api.UserRename
 		expectedError      api.Error
+		// Manager Results
+		renameUserResult *api.UserRename
 		// Manager Errors
-		removeUserByIdErr error
+		renameUserErr error
 	}{
 		"OkCase": {
-			externalID:         "UserID",
-			expectedStatusCode: http.StatusNoContent,
+			request: &RenameUserRequest{
+				ExternalID:      "NewExternalID",
+				RewritePolicies: true,
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.UserRename{
+				User: &api.User{
+					ID:         "UserID",
+					ExternalID: "NewExternalID",
+					Path:       "Path",
+					Urn:        "urn",
+				},
+				References: []api.StatementReference{
+					{
+						Org:        "org",
+						PolicyName: "policy",
+						Sid:        "sid",
+					},
+				},
+				Rewritten: true,
+			},
+			renameUserResult: &api.UserRename{
+				User: &api.User{
+					ID:         "UserID",
+					ExternalID: "NewExternalID",
+					Path:       "Path",
+					Urn:        "urn",
+				},
+				References: []api.StatementReference{
+					{
+						Org:        "org",
+						PolicyName: "policy",
+						Sid:        "sid",
+					},
+				},
+				Rewritten: true,
+			},
 		},
-		"ErrorCaseUserNotExist": {
-			externalID:         "UserID",
+		"ErrorCaseMalformedRequest": {
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			request: &RenameUserRequest{
+				ExternalID: "NewExternalID",
+			},
 			expectedStatusCode: http.StatusNotFound,
 			expectedError: api.Error{
 				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
-				Message: "User not exist",
+				Message: "User not found",
 			},
-			removeUserByIdErr: &api.Error{
+			renameUserErr: &api.Error{
 				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
-				Message: "User not exist",
+				Message: "User not found",
 			},
 		},
-		"ErrorCaseInvalidParameterError": {
-			externalID:         "InvalidID",
-			expectedStatusCode: http.StatusBadRequest,
+		"ErrorCaseUserAlreadyExist": {
+			request: &RenameUserRequest{
+				ExternalID: "NewExternalID",
+			},
+			expectedStatusCode: http.StatusConflict,
 			expectedError: api.Error{
-				Code:    api.INVALID_PARAMETER_ERROR,
-				Message: "Invalid parameter",
+				Code:    api.USER_ALREADY_EXIST,
+				Message: "User already exist",
 			},
-			removeUserByIdErr: &api.Error{
-				Code:    api.INVALID_PARAMETER_ERROR,
-				Message: "Invalid parameter",
+			renameUserErr: &api.Error{
+				Code:    api.USER_ALREADY_EXIST,
+				Message: "User already exist",
 			},
 		},
 		"ErrorCaseUnauthorizedResourcesError": {
-			externalID:         "UnauthorizedID",
+			request: &RenameUserRequest{
+				ExternalID: "NewExternalID",
+			},
 			expectedStatusCode: http.StatusForbidden,
 			expectedError: api.Error{
 				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
 				Message: "Unauthorized",
 			},
-			removeUserByIdErr: &api.Error{
+			renameUserErr: &api.Error{
 				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
 				Message: "Unauthorized",
 			},
 		},
+		"ErrorCaseInvalidParameterError": {
+			request: &RenameUserRequest{
+				ExternalID: "*%~#@|",
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+			renameUserErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+		},
 		"ErrorCaseUnknownApiError": {
-			externalID:         "ExceptionID",
+			request: &RenameUserRequest{
+				ExternalID: "NewExternalID",
+			},
 			expectedStatusCode: http.StatusInternalServerError,
-			removeUserByIdErr: &api.Error{
+			renameUserErr: &api.Error{
 				Code:    api.UNKNOWN_API_ERROR,
 				Message: "Error",
 			},
@@ -697,10 +983,24 @@ func TestWorkerHandler_HandleRemoveUser(t *testing.T) {
 
 	for n, test := range testcases {
 
-		testApi.ArgsOut[RemoveUserMethod][0] = test.removeUserByIdErr
+		testApi.ArgsOut[RenameUserMethod][0] = test.renameUserResult
+		testApi.ArgsOut[RenameUserMethod][1] = test.renameUserErr
 
-		url := fmt.Sprintf(server.URL+USER_ROOT_URL+"/%v", test.externalID)
-		req, err := http.NewRequest(http.MethodDelete, url, nil)
+		var body *bytes.Buffer
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+		if body == nil {
+			body = bytes.NewBuffer([]byte{})
+		}
+
+		url := fmt.Sprintf(server.URL + USER_ROOT_URL + "/userid/rename")
+		req, err := http.NewRequest(http.MethodPost, url, body)
 		if err != nil {
 			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
 			continue
@@ -712,10 +1012,20 @@ func TestWorkerHandler_HandleRemoveUser(t *testing.T) {
 			continue
 		}
 
-		// Check received parameters
-		if testApi.ArgsIn[RemoveUserMethod][1] != test.externalID {
-			t.Errorf("Test case %v. Received different ExternalID (wanted:%v / received:%v)", n, test.externalID, testApi.ArgsIn[RemoveUserMethod][1])
-			continue
+		if test.request != nil {
+			// Check received parameters
+			if testApi.ArgsIn[RenameUserMethod][1] != "userid" {
+				t.Errorf("Test case %v. Received different ExternalID (wanted:%v / received:%v)", n, "userid", testApi.ArgsIn[RenameUserMethod][1])
+				continue
+			}
+			if testApi.ArgsIn[RenameUserMethod][2] != test.request.ExternalID {
+				t.Errorf("Test case %v. Received different new ExternalID (wanted:%v / received:%v)", n, test.request.ExternalID, testApi.ArgsIn[RenameUserMethod][2])
+				continue
+			}
+			if testApi.ArgsIn[RenameUserMethod][3] != test.request.RewritePolicies {
+				t.Errorf("Test case %v. Received different RewritePolicies (wanted:%v / received:%v)", n, test.request.RewritePolicies, testApi.ArgsIn[RenameUserMethod][3])
+				continue
+			}
 		}
 
 		// check status code
@@ -725,9 +1035,19 @@ func TestWorkerHandler_HandleRemoveUser(t *testing.T) {
 		}
 
 		switch res.StatusCode {
-		case http.StatusNoContent:
-			// No message expected
-			continue
+		case http.StatusOK:
+			response := api.UserRename{}
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v",
+					n, diff)
+				continue
+			}
 		case http.StatusInternalServerError: // Empty message so continue
 			continue
 		default:
@@ -747,43 +1067,47 @@ func TestWorkerHandler_HandleRemoveUser(t *testing.T) {
 	}
 }
 
-func TestWorkerHandler_HandleListGroupsByUser(t *testing.T) {
+func TestWorkerHandler_HandleRemoveUser(t *testing.T) {
 	testcases := map[string]struct {
 		// API method args
 		externalID string
+		ifMatch    string
+		// Worker config
+		requireIfMatch bool
 		// Expected result
 		expectedStatusCode int
-		expectedResponse   GetGroupsByUserIdResponse
 		expectedError      api.Error
-		// Manager Results
-		getGroupsByUserIdResult []api.GroupIdentity
 		// Manager Errors
-		getGroupsByUserIdErr error
+		removeUserByIdErr error
 	}{
 		"OkCase": {
 			externalID:         "UserID",
-			expectedStatusCode: http.StatusOK,
-			expectedResponse: GetGroupsByUserIdResponse{
-				Groups: []api.GroupIdentity{
-					{
-						Org:  "org1",
-						Name: "group1",
-					},
-					{
-						Org:  "org2",
-						Name: "group2",
-					},
-				},
+			expectedStatusCode: http.StatusNoContent,
+		},
+		"OkCaseIfMatch": {
+			externalID:         "UserID",
+			ifMatch:            "\"etag\"",
+			requireIfMatch:     true,
+			expectedStatusCode: http.StatusNoContent,
+		},
+		"ErrorCasePreconditionFailed": {
+			externalID: "UserID",
+			ifMatch:    "\"etag\"",
+			removeUserByIdErr: &api.Error{
+				Code: api.PRECONDITION_FAILED_ERROR,
 			},
-			getGroupsByUserIdResult: []api.GroupIdentity{
-				{
-					Org:  "org1",
-					Name: "group1",
-				},
-				{
-					Org:  "org2",
-					Name: "group2",
-				},
+			expectedStatusCode: http.StatusPreconditionFailed,
+			expectedError: api.Error{
+				Code: api.PRECONDITION_FAILED_ERROR,
+			},
+		},
+		"ErrorCasePreconditionRequired": {
+			externalID:         "UserID",
+			requireIfMatch:     true,
+			expectedStatusCode: http.StatusPreconditionRequired,
+			expectedError: api.Error{
+				Code:    api.PRECONDITION_REQUIRED_ERROR,
+				Message: "Header If-Match is required to modify resources",
 			},
 		},
 		"ErrorCaseUserNotExist": {
@@ -793,7 +1117,7 @@ func TestWorkerHandler_HandleListGroupsByUser(t *testing.T) {
 				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
 				Message: "User not exist",
 			},
-			getGroupsByUserIdErr: &api.Error{
+			removeUserByIdErr: &api.Error{
 				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
 				Message: "User not exist",
 			},
@@ -805,7 +1129,7 @@ func TestWorkerHandler_HandleListGroupsByUser(t *testing.T) {
 				Code:    api.INVALID_PARAMETER_ERROR,
 				Message: "Invalid parameter",
 			},
-			getGroupsByUserIdErr: &api.Error{
+			removeUserByIdErr: &api.Error{
 				Code:    api.INVALID_PARAMETER_ERROR,
 				Message: "Invalid parameter",
 			},
@@ -817,7 +1141,7 @@ func TestWorkerHandler_HandleListGroupsByUser(t *testing.T) {
 				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
 				Message: "Unauthorized",
 			},
-			getGroupsByUserIdErr: &api.Error{
+			removeUserByIdErr: &api.Error{
 				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
 				Message: "Unauthorized",
 			},
@@ -825,7 +1149,7 @@ func TestWorkerHandler_HandleListGroupsByUser(t *testing.T) {
 		"ErrorCaseUnknownApiError": {
 			externalID:         "ExceptionID",
 			expectedStatusCode: http.StatusInternalServerError,
-			getGroupsByUserIdErr: &api.Error{
+			removeUserByIdErr: &api.Error{
 				Code:    api.UNKNOWN_API_ERROR,
 				Message: "Error",
 			},
@@ -835,16 +1159,19 @@ func TestWorkerHandler_HandleListGroupsByUser(t *testing.T) {
 	client := http.DefaultClient
 
 	for n, test := range testcases {
+		testApi.ArgsIn[RemoveUserMethod] = make([]interface{}, 2)
+		testApi.ArgsOut[RemoveUserMethod][0] = test.removeUserByIdErr
+		worker.RequireIfMatch = test.requireIfMatch
 
-		testApi.ArgsOut[ListGroupsByUserMethod][0] = test.getGroupsByUserIdResult
-		testApi.ArgsOut[ListGroupsByUserMethod][1] = test.getGroupsByUserIdErr
-
-		url := fmt.Sprintf(server.URL+USER_ROOT_URL+"/%v/groups", test.externalID)
-		req, err := http.NewRequest(http.MethodGet, url, nil)
+		url := fmt.Sprintf(server.URL+USER_ROOT_URL+"/%v", test.externalID)
+		req, err := http.NewRequest(http.MethodDelete, url, nil)
 		if err != nil {
 			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
 			continue
 		}
+		if test.ifMatch != "" {
+			req.Header.Set(IF_MATCH_HEADER, test.ifMatch)
+		}
 
 		res, err := client.Do(req)
 		if err != nil {
@@ -852,12 +1179,506 @@ func TestWorkerHandler_HandleListGroupsByUser(t *testing.T) {
 			continue
 		}
 
-		// Check received parameters
-		if testApi.ArgsIn[ListGroupsByUserMethod][1] != test.externalID {
-			t.Errorf("Test case %v. Received different ExternalID (wanted:%v / received:%v)", n, test.externalID, testApi.ArgsIn[ListGroupsByUserMethod][1])
+		// Check received parameters, API isn't called without required If-Match
+		if test.expectedStatusCode == http.StatusPreconditionRequired {
+			if testApi.ArgsIn[RemoveUserMethod][0] != nil {
+				t.Errorf("Test case %v. Unexpected call to API without If-Match header", n)
+				continue
+			}
+		} else {
+			if testApi.ArgsIn[RemoveUserMethod][0].(api.RequestInfo).IfMatch != test.ifMatch {
+				t.Errorf("Test case %v. Received different If-Match (wanted:%v / received:%v)", n, test.ifMatch, testApi.ArgsIn[RemoveUserMethod][0].(api.RequestInfo).IfMatch)
+				continue
+			}
+			if testApi.ArgsIn[RemoveUserMethod][1] != test.externalID {
+				t.Errorf("Test case %v. Received different ExternalID (wanted:%v / received:%v)", n, test.externalID, testApi.ArgsIn[RemoveUserMethod][1])
+				continue
+			}
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusNoContent:
+			// No message expected
+			continue
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v",
+					n, diff)
+				continue
+			}
+		}
+	}
+	worker.RequireIfMatch = false
+}
+
+func TestWorkerHandler_HandleSuspendUser(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		externalID string
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.User
+		expectedError      api.Error
+		// Manager Results
+		suspendUserResult *api.User
+		// Manager Errors
+		suspendUserErr error
+	}{
+		"OkCase": {
+			externalID:         "UserID",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.User{
+				ID:         "UserID",
+				ExternalID: "UserID",
+				Path:       "Path",
+				Urn:        "urn",
+				Status:     api.USER_STATUS_SUSPENDED,
+			},
+			suspendUserResult: &api.User{
+				ID:         "UserID",
+				ExternalID: "UserID",
+				Path:       "Path",
+				Urn:        "urn",
+				Status:     api.USER_STATUS_SUSPENDED,
+			},
+		},
+		"ErrorCaseUserNotExist": {
+			externalID:         "UserID",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not exist",
+			},
+			suspendUserErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not exist",
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			externalID:         "InvalidID",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+			suspendUserErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+		},
+		"ErrorCaseUnauthorizedResourcesError": {
+			externalID:         "UnauthorizedID",
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			suspendUserErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			externalID:         "ExceptionID",
+			expectedStatusCode: http.StatusInternalServerError,
+			suspendUserErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[SuspendUserMethod][0] = test.suspendUserResult
+		testApi.ArgsOut[SuspendUserMethod][1] = test.suspendUserErr
+
+		url := fmt.Sprintf(server.URL+USER_ROOT_URL+"/%v/suspend", test.externalID)
+		req, err := http.NewRequest(http.MethodPost, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
 			continue
 		}
 
+		// Check received parameters
+		if testApi.ArgsIn[SuspendUserMethod][1] != test.externalID {
+			t.Errorf("Test case %v. Received different ExternalID (wanted:%v / received:%v)", n, test.externalID, testApi.ArgsIn[SuspendUserMethod][1])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			userResponse := api.User{}
+			err = json.NewDecoder(res.Body).Decode(&userResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(userResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v",
+					n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v",
+					n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleReactivateUser(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		externalID string
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.User
+		expectedError      api.Error
+		// Manager Results
+		reactivateUserResult *api.User
+		// Manager Errors
+		reactivateUserErr error
+	}{
+		"OkCase": {
+			externalID:         "UserID",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.User{
+				ID:         "UserID",
+				ExternalID: "UserID",
+				Path:       "Path",
+				Urn:        "urn",
+				Status:     api.USER_STATUS_ACTIVE,
+			},
+			reactivateUserResult: &api.User{
+				ID:         "UserID",
+				ExternalID: "UserID",
+				Path:       "Path",
+				Urn:        "urn",
+				Status:     api.USER_STATUS_ACTIVE,
+			},
+		},
+		"ErrorCaseUserNotExist": {
+			externalID:         "UserID",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not exist",
+			},
+			reactivateUserErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not exist",
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			externalID:         "InvalidID",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+			reactivateUserErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+		},
+		"ErrorCaseUnauthorizedResourcesError": {
+			externalID:         "UnauthorizedID",
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			reactivateUserErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			externalID:         "ExceptionID",
+			expectedStatusCode: http.StatusInternalServerError,
+			reactivateUserErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[ReactivateUserMethod][0] = test.reactivateUserResult
+		testApi.ArgsOut[ReactivateUserMethod][1] = test.reactivateUserErr
+
+		url := fmt.Sprintf(server.URL+USER_ROOT_URL+"/%v/reactivate", test.externalID)
+		req, err := http.NewRequest(http.MethodPost, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[ReactivateUserMethod][1] != test.externalID {
+			t.Errorf("Test case %v. Received different ExternalID (wanted:%v / received:%v)", n, test.externalID, testApi.ArgsIn[ReactivateUserMethod][1])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			userResponse := api.User{}
+			err = json.NewDecoder(res.Body).Decode(&userResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(userResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v",
+					n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v",
+					n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleListGroupsByUser(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		externalID string
+		offset     string
+		limit      string
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   GetGroupsByUserIdResponse
+		expectedError      api.Error
+		// Manager Results
+		getGroupsByUserIdResult []api.GroupIdentity
+		getGroupsByUserIdTotal  int
+		// Manager Errors
+		getGroupsByUserIdErr error
+	}{
+		"OkCase": {
+			externalID:         "UserID",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: GetGroupsByUserIdResponse{
+				Groups: []api.GroupIdentity{
+					{
+						Org:  "org1",
+						Name: "group1",
+					},
+					{
+						Org:  "org2",
+						Name: "group2",
+					},
+				},
+				Total: 2,
+			},
+			getGroupsByUserIdResult: []api.GroupIdentity{
+				{
+					Org:  "org1",
+					Name: "group1",
+				},
+				{
+					Org:  "org2",
+					Name: "group2",
+				},
+			},
+			getGroupsByUserIdTotal: 2,
+		},
+		"OkCasePaging": {
+			externalID:         "UserID",
+			offset:             "1",
+			limit:              "1",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: GetGroupsByUserIdResponse{
+				Groups: []api.GroupIdentity{
+					{
+						Org:  "org1",
+						Name: "group1",
+					},
+					{
+						Org:  "org2",
+						Name: "group2",
+					},
+				},
+				Offset: 1,
+				Limit:  1,
+				Total:  2,
+			},
+			getGroupsByUserIdResult: []api.GroupIdentity{
+				{
+					Org:  "org1",
+					Name: "group1",
+				},
+				{
+					Org:  "org2",
+					Name: "group2",
+				},
+			},
+			getGroupsByUserIdTotal: 2,
+		},
+		"ErrorCaseInvalidLimit": {
+			externalID:         "UserID",
+			limit:              "a",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Limit a",
+			},
+		},
+		"ErrorCaseUserNotExist": {
+			externalID:         "UserID",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not exist",
+			},
+			getGroupsByUserIdErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not exist",
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			externalID:         "InvalidID",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+			getGroupsByUserIdErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+		},
+		"ErrorCaseUnauthorizedResourcesError": {
+			externalID:         "UnauthorizedID",
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			getGroupsByUserIdErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			externalID:         "ExceptionID",
+			expectedStatusCode: http.StatusInternalServerError,
+			getGroupsByUserIdErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[ListGroupsByUserMethod][0] = test.getGroupsByUserIdResult
+		testApi.ArgsOut[ListGroupsByUserMethod][1] = test.getGroupsByUserIdTotal
+		testApi.ArgsOut[ListGroupsByUserMethod][2] = test.getGroupsByUserIdErr
+
+		url := fmt.Sprintf(server.URL+USER_ROOT_URL+"/%v/groups", test.externalID)
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		q := req.URL.Query()
+		if test.offset != "" {
+			q.Add("Offset", test.offset)
+		}
+		if test.limit != "" {
+			q.Add("Limit", test.limit)
+		}
+		req.URL.RawQuery = q.Encode()
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if test.expectedStatusCode != http.StatusBadRequest {
+			if testApi.ArgsIn[ListGroupsByUserMethod][1] != test.externalID {
+				t.Errorf("Test case %v. Received different ExternalID (wanted:%v / received:%v)", n, test.externalID, testApi.ArgsIn[ListGroupsByUserMethod][1])
+				continue
+			}
+		}
+
 		// check status code
 		if test.expectedStatusCode != res.StatusCode {
 			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
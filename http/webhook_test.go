@@ -0,0 +1,837 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/tecsisa/foulkon/api"
+)
+
+func TestWorkerHandler_HandleAddWebhook(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		request *CreateWebhookRequest
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.Webhook
+		expectedError      api.Error
+		// Manager Results
+		addWebhookResult *api.Webhook
+		// Manager Errors
+		addWebhookErr error
+	}{
+		"OkCase": {
+			request: &CreateWebhookRequest{
+				Url:    "https://example.com/hook",
+				Secret: "secret",
+				Events: []string{"iam:*"},
+			},
+			expectedStatusCode: http.StatusCreated,
+			// Secret isn't returned
+			expectedResponse: &api.Webhook{
+				ID:     "WEBHOOK-ID",
+				Url:    "https://example.com/hook",
+				Events: []string{"iam:*"},
+			},
+			addWebhookResult: &api.Webhook{
+				ID:     "WEBHOOK-ID",
+				Url:    "https://example.com/hook",
+				Secret: "secret",
+				Events: []string{"iam:*"},
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			request: &CreateWebhookRequest{
+				Url: "invalid",
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+			addWebhookErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			request: &CreateWebhookRequest{
+				Url: "https://example.com/hook",
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			addWebhookErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			request: &CreateWebhookRequest{
+				Url: "https://example.com/hook",
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			addWebhookErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsOut[AddWebhookMethod][0] = test.addWebhookResult
+		testApi.ArgsOut[AddWebhookMethod][1] = test.addWebhookErr
+
+		jsonObject, err := json.Marshal(test.request)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+			continue
+		}
+		req, err := http.NewRequest(http.MethodPost, server.URL+WEBHOOK_ROOT_URL, bytes.NewBuffer(jsonObject))
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		received := []interface{}{
+			testApi.ArgsIn[AddWebhookMethod][1],
+			testApi.ArgsIn[AddWebhookMethod][2],
+			testApi.ArgsIn[AddWebhookMethod][3],
+		}
+		if diff := pretty.Compare(received, []interface{}{test.request.Url, test.request.Secret, test.request.Events}); diff != "" {
+			t.Errorf("Test case %v. Received different parameters (received/wanted) %v", n, diff)
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusCreated:
+			response := &api.Webhook{}
+			err = json.NewDecoder(res.Body).Decode(response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleGetWebhookByID(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		id string
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.Webhook
+		expectedError      api.Error
+		// Manager Results
+		getWebhookByIDResult *api.Webhook
+		// Manager Errors
+		getWebhookByIDErr error
+	}{
+		"OkCase": {
+			id:                 "WEBHOOK-ID",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.Webhook{
+				ID:     "WEBHOOK-ID",
+				Url:    "https://example.com/hook",
+				Events: []string{"iam:*"},
+			},
+			getWebhookByIDResult: &api.Webhook{
+				ID:     "WEBHOOK-ID",
+				Url:    "https://example.com/hook",
+				Secret: "secret",
+				Events: []string{"iam:*"},
+			},
+		},
+		"ErrorCaseWebhookNotFound": {
+			id:                 "WEBHOOK-ID",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.WEBHOOK_NOT_FOUND,
+				Message: "Not found",
+			},
+			getWebhookByIDErr: &api.Error{
+				Code:    api.WEBHOOK_NOT_FOUND,
+				Message: "Not found",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			id:                 "WEBHOOK-ID",
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			getWebhookByIDErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			id:                 "WEBHOOK-ID",
+			expectedStatusCode: http.StatusInternalServerError,
+			getWebhookByIDErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsOut[GetWebhookByIDMethod][0] = test.getWebhookByIDResult
+		testApi.ArgsOut[GetWebhookByIDMethod][1] = test.getWebhookByIDErr
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+WEBHOOK_ROOT_URL+"/"+test.id, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[GetWebhookByIDMethod][1] != test.id {
+			t.Errorf("Test case %v. Received different id (wanted:%v / received:%v)", n, test.id, testApi.ArgsIn[GetWebhookByIDMethod][1])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			response := &api.Webhook{}
+			err = json.NewDecoder(res.Body).Decode(response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleListWebhooks(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		filter *api.Filter
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   ListWebhooksResponse
+		expectedError      api.Error
+		// Manager Results
+		listWebhooksResult []api.Webhook
+		totalResult        int
+		// Manager Errors
+		listWebhooksErr error
+	}{
+		"OkCase": {
+			filter: &api.Filter{
+				Offset: 0,
+				Limit:  10,
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: ListWebhooksResponse{
+				Webhooks: []api.Webhook{
+					{
+						ID:     "WEBHOOK-ID",
+						Url:    "https://example.com/hook",
+						Events: []string{"iam:*"},
+					},
+				},
+				Offset: 0,
+				Limit:  10,
+				Total:  1,
+			},
+			listWebhooksResult: []api.Webhook{
+				{
+					ID:     "WEBHOOK-ID",
+					Url:    "https://example.com/hook",
+					Secret: "secret",
+					Events: []string{"iam:*"},
+				},
+			},
+			totalResult: 1,
+		},
+		"ErrorCaseUnauthorizedError": {
+			filter:             &api.Filter{},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			listWebhooksErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			filter:             &api.Filter{},
+			expectedStatusCode: http.StatusInternalServerError,
+			listWebhooksErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsOut[ListWebhooksMethod][0] = test.listWebhooksResult
+		testApi.ArgsOut[ListWebhooksMethod][1] = test.totalResult
+		testApi.ArgsOut[ListWebhooksMethod][2] = test.listWebhooksErr
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+WEBHOOK_ROOT_URL, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+		q := req.URL.Query()
+		q.Add("Offset", fmt.Sprintf("%v", test.filter.Offset))
+		q.Add("Limit", fmt.Sprintf("%v", test.filter.Limit))
+		req.URL.RawQuery = q.Encode()
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			response := ListWebhooksResponse{}
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleUpdateWebhook(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		id      string
+		request *UpdateWebhookRequest
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.Webhook
+		expectedError      api.Error
+		// Manager Results
+		updateWebhookResult *api.Webhook
+		// Manager Errors
+		updateWebhookErr error
+	}{
+		"OkCase": {
+			id: "WEBHOOK-ID",
+			request: &UpdateWebhookRequest{
+				Url:    "https://example.com/newhook",
+				Events: []string{"iam:*"},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.Webhook{
+				ID:     "WEBHOOK-ID",
+				Url:    "https://example.com/newhook",
+				Events: []string{"iam:*"},
+			},
+			updateWebhookResult: &api.Webhook{
+				ID:     "WEBHOOK-ID",
+				Url:    "https://example.com/newhook",
+				Secret: "secret",
+				Events: []string{"iam:*"},
+			},
+		},
+		"ErrorCaseWebhookNotFound": {
+			id: "WEBHOOK-ID",
+			request: &UpdateWebhookRequest{
+				Url: "https://example.com/newhook",
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.WEBHOOK_NOT_FOUND,
+				Message: "Not found",
+			},
+			updateWebhookErr: &api.Error{
+				Code:    api.WEBHOOK_NOT_FOUND,
+				Message: "Not found",
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			id: "WEBHOOK-ID",
+			request: &UpdateWebhookRequest{
+				Url: "invalid",
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+			updateWebhookErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			id: "WEBHOOK-ID",
+			request: &UpdateWebhookRequest{
+				Url: "https://example.com/newhook",
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			updateWebhookErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			id: "WEBHOOK-ID",
+			request: &UpdateWebhookRequest{
+				Url: "https://example.com/newhook",
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			updateWebhookErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsOut[UpdateWebhookMethod][0] = test.updateWebhookResult
+		testApi.ArgsOut[UpdateWebhookMethod][1] = test.updateWebhookErr
+
+		jsonObject, err := json.Marshal(test.request)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+			continue
+		}
+		req, err := http.NewRequest(http.MethodPut, server.URL+WEBHOOK_ROOT_URL+"/"+test.id, bytes.NewBuffer(jsonObject))
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		received := []interface{}{
+			testApi.ArgsIn[UpdateWebhookMethod][1],
+			testApi.ArgsIn[UpdateWebhookMethod][2],
+			testApi.ArgsIn[UpdateWebhookMethod][3],
+			testApi.ArgsIn[UpdateWebhookMethod][4],
+		}
+		if diff := pretty.Compare(received, []interface{}{test.id, test.request.Url, test.request.Secret, test.request.Events}); diff != "" {
+			t.Errorf("Test case %v. Received different parameters (received/wanted) %v", n, diff)
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			response := &api.Webhook{}
+			err = json.NewDecoder(res.Body).Decode(response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleRemoveWebhook(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		id string
+		// Expected result
+		expectedStatusCode int
+		expectedError      api.Error
+		// Manager Errors
+		removeWebhookErr error
+	}{
+		"OkCase": {
+			id:                 "WEBHOOK-ID",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		"ErrorCaseWebhookNotFound": {
+			id:                 "WEBHOOK-ID",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.WEBHOOK_NOT_FOUND,
+				Message: "Not found",
+			},
+			removeWebhookErr: &api.Error{
+				Code:    api.WEBHOOK_NOT_FOUND,
+				Message: "Not found",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			id:                 "WEBHOOK-ID",
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			removeWebhookErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			id:                 "WEBHOOK-ID",
+			expectedStatusCode: http.StatusInternalServerError,
+			removeWebhookErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsOut[RemoveWebhookMethod][0] = test.removeWebhookErr
+
+		req, err := http.NewRequest(http.MethodDelete, server.URL+WEBHOOK_ROOT_URL+"/"+test.id, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[RemoveWebhookMethod][1] != test.id {
+			t.Errorf("Test case %v. Received different id (wanted:%v / received:%v)", n, test.id, testApi.ArgsIn[RemoveWebhookMethod][1])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusNoContent, http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleListWebhookDeliveries(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		id     string
+		status string
+		filter *api.Filter
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   ListWebhookDeliveriesResponse
+		expectedError      api.Error
+		// Manager Results
+		listWebhookDeliveriesResult []api.WebhookDelivery
+		totalResult                 int
+		// Manager Errors
+		listWebhookDeliveriesErr error
+	}{
+		"OkCase": {
+			id:     "WEBHOOK-ID",
+			status: api.WEBHOOK_DELIVERY_STATUS_FAILED,
+			filter: &api.Filter{
+				Offset: 0,
+				Limit:  10,
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: ListWebhookDeliveriesResponse{
+				Deliveries: []api.WebhookDelivery{
+					{
+						ID:         "DELIVERY-ID",
+						WebhookID:  "WEBHOOK-ID",
+						Action:     api.GROUP_ACTION_ADD_MEMBER,
+						Payload:    json.RawMessage(`{"action":"iam:AddMember"}`),
+						Status:     api.WEBHOOK_DELIVERY_STATUS_FAILED,
+						Attempts:   api.WEBHOOK_MAX_DELIVERY_ATTEMPTS,
+						StatusCode: http.StatusInternalServerError,
+					},
+				},
+				Offset: 0,
+				Limit:  10,
+				Total:  1,
+			},
+			listWebhookDeliveriesResult: []api.WebhookDelivery{
+				{
+					ID:         "DELIVERY-ID",
+					WebhookID:  "WEBHOOK-ID",
+					Action:     api.GROUP_ACTION_ADD_MEMBER,
+					Payload:    json.RawMessage(`{"action":"iam:AddMember"}`),
+					Status:     api.WEBHOOK_DELIVERY_STATUS_FAILED,
+					Attempts:   api.WEBHOOK_MAX_DELIVERY_ATTEMPTS,
+					StatusCode: http.StatusInternalServerError,
+				},
+			},
+			totalResult: 1,
+		},
+		"ErrorCaseWebhookNotFound": {
+			id:                 "WEBHOOK-ID",
+			filter:             &api.Filter{},
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.WEBHOOK_NOT_FOUND,
+				Message: "Not found",
+			},
+			listWebhookDeliveriesErr: &api.Error{
+				Code:    api.WEBHOOK_NOT_FOUND,
+				Message: "Not found",
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			id:                 "WEBHOOK-ID",
+			status:             "lost",
+			filter:             &api.Filter{},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+			listWebhookDeliveriesErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			id:                 "WEBHOOK-ID",
+			filter:             &api.Filter{},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			listWebhookDeliveriesErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			id:                 "WEBHOOK-ID",
+			filter:             &api.Filter{},
+			expectedStatusCode: http.StatusInternalServerError,
+			listWebhookDeliveriesErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsOut[ListWebhookDeliveriesMethod][0] = test.listWebhookDeliveriesResult
+		testApi.ArgsOut[ListWebhookDeliveriesMethod][1] = test.totalResult
+		testApi.ArgsOut[ListWebhookDeliveriesMethod][2] = test.listWebhookDeliveriesErr
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+WEBHOOK_ROOT_URL+"/"+test.id+"/deliveries", nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+		q := req.URL.Query()
+		q.Add("Status", test.status)
+		q.Add("Offset", fmt.Sprintf("%v", test.filter.Offset))
+		q.Add("Limit", fmt.Sprintf("%v", test.filter.Limit))
+		req.URL.RawQuery = q.Encode()
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		received := []interface{}{
+			testApi.ArgsIn[ListWebhookDeliveriesMethod][1],
+			testApi.ArgsIn[ListWebhookDeliveriesMethod][2],
+		}
+		if diff := pretty.Compare(received, []interface{}{test.id, test.status}); diff != "" {
+			t.Errorf("Test case %v. Received different parameters (received/wanted) %v", n, diff)
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			response := ListWebhookDeliveriesResponse{}
+			err = json.NewDecoder(res.Body).Decode(&response)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
@@ -2,4 +2,15 @@
 prmd doc group.json > ../doc/api/group.md
 prmd doc user.json > ../doc/api/user.md
 prmd doc policy.json > ../doc/api/policy.md
-prmd doc resource.json > ../doc/api/resource.md
\ No newline at end of file
+prmd doc resource.json > ../doc/api/resource.md
+prmd doc access_request.json > ../doc/api/access_request.md
+prmd doc action.json > ../doc/api/action.md
+prmd doc resource_type.json > ../doc/api/resource_type.md
+prmd doc tag.json > ../doc/api/tag.md
+prmd doc batch.json > ../doc/api/batch.md
+prmd doc organization.json > ../doc/api/organization.md
+prmd doc trash.json > ../doc/api/trash.md
+prmd doc consistency.json > ../doc/api/consistency.md
+prmd doc me.json > ../doc/api/me.md
+prmd doc audit.json > ../doc/api/audit.md
+prmd doc webhook.json > ../doc/api/webhook.md
\ No newline at end of file
@@ -3,10 +3,14 @@ echo "--> Building..."
 #Make sure $GOPATH is set
 CGO_ENABLED=0 go install github.com/tecsisa/foulkon/cmd/worker
 CGO_ENABLED=0 go install github.com/tecsisa/foulkon/cmd/proxy
+CGO_ENABLED=0 go install github.com/tecsisa/foulkon/cmd/apply
+CGO_ENABLED=0 go install github.com/tecsisa/foulkon/cmd/consistency
 
 mkdir bin/ 2>/dev/null
 cp $GOPATH/bin/worker ./bin
 cp $GOPATH/bin/proxy ./bin
+cp $GOPATH/bin/apply ./bin
+cp $GOPATH/bin/consistency ./bin
 
 echo "==> Building Docker images..."
 docker build -t tecsisa/foulkon-proxy -f scripts/docker/Dockerfile_proxy .